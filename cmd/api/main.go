@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,13 +12,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/go-api-nosql/internal/app"
 	"github.com/go-api-nosql/internal/config"
-	"github.com/go-api-nosql/internal/infrastructure/dynamo"
-	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
-	s3infra "github.com/go-api-nosql/internal/infrastructure/s3"
-	"github.com/go-api-nosql/internal/infrastructure/smtp"
-	"github.com/go-api-nosql/internal/infrastructure/sns"
-	transporthttp "github.com/go-api-nosql/internal/transport/http"
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
 	"github.com/joho/godotenv"
 )
 
@@ -26,52 +24,21 @@ func main() {
 	}
 
 	cfg := config.Load()
-
-	// Bootstrap DynamoDB tables (creates them if they don't exist).
-	dynamoClient := dynamo.NewClient(cfg)
-	dynamo.Bootstrap(context.Background(), dynamoClient, cfg.DynamoTables)
-
-	// JWT provider (optional — graceful fallback if keys are missing).
-	var jwtProvider *jwtinfra.Provider
-	if p, err := jwtinfra.NewProvider(cfg); err == nil {
-		jwtProvider = p
-	} else {
-		log.Printf("WARN: JWT provider not available: %v", err)
+	if err := app.SetupLogging(cfg); err != nil {
+		log.Fatal(err)
 	}
 
-	// S3 store.
-	s3Client := s3infra.NewClient(cfg)
-	s3Store := s3infra.NewStore(s3Client, cfg.S3BucketName)
-
-	// SMTP mailer.
-	mailer := smtp.NewMailer(cfg)
-
-	// SNS SMS sender (optional — graceful fallback).
-	var smsSender sns.SMSSender
-	if sender, err := sns.NewSender(cfg); err == nil {
-		smsSender = sender
-	} else {
-		log.Printf("WARN: SNS sender not available: %v", err)
+	background := lifecycle.New(context.Background())
+	deps, err := app.NewDeps(background.Context(), cfg)
+	if err != nil {
+		log.Fatalf("infrastructure: %v", err)
 	}
-
-	deps := &transporthttp.Deps{
-		UserRepo:         dynamo.NewUserRepo(dynamoClient, cfg.DynamoTables.Users),
-		SessionRepo:      dynamo.NewSessionRepo(dynamoClient, cfg.DynamoTables.Sessions),
-		StatusRepo:       dynamo.NewStatusRepo(dynamoClient, cfg.DynamoTables.Statuses),
-		DeviceRepo:       dynamo.NewDeviceRepo(dynamoClient, cfg.DynamoTables.Devices),
-		NotificationRepo: dynamo.NewNotificationRepo(dynamoClient, cfg.DynamoTables.Notifications),
-		FileRepo:         dynamo.NewFileRepo(dynamoClient, cfg.DynamoTables.Files),
-		VerificationRepo: dynamo.NewVerificationRepo(dynamoClient, cfg.DynamoTables.UserVerifications),
-		AppVersionRepo:   dynamo.NewAppVersionRepo(dynamoClient, cfg.DynamoTables.AppVersions),
-		DynamoClient:     dynamoClient,
-		S3Store:          s3Store,
-		Mailer:           mailer,
-		SMSSender:        smsSender,
-		JWTProvider:      jwtProvider,
+	// Deployment hooks and router extensions (deps.Extensions, deps.*Hooks) go here.
+	api, err := app.New(background.Context(), cfg, deps)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	routerCtx, routerCancel := context.WithCancel(context.Background())
-	router := transporthttp.NewRouter(routerCtx, cfg, deps)
+	router := api.Handler
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.AppPort),
@@ -88,16 +55,65 @@ func main() {
 		}
 	}()
 
+	// Optional mutual-TLS listener for B2B clients; serves the same routes.
+	var mtlsSrv *http.Server
+	if cfg.MTLSPort != "" {
+		if mtlsSrv, err = newMTLSServer(cfg, router); err != nil {
+			log.Fatalf("mTLS listener: %v", err)
+		}
+		go func() {
+			log.Printf("mTLS listener starting on :%s", cfg.MTLSPort)
+			if err := mtlsSrv.ListenAndServeTLS(cfg.MTLSCertFile, cfg.MTLSKeyFile); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("mTLS server error: %v", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+	// In-flight requests drain first, then background jobs get what is left
+	// of the same 10 seconds to finish their current run.
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("forced shutdown: %v", err)
 	}
-	routerCancel()
+	if mtlsSrv != nil {
+		if err := mtlsSrv.Shutdown(ctx); err != nil {
+			log.Fatalf("forced mTLS shutdown: %v", err)
+		}
+	}
+	if err := background.Shutdown(ctx); err != nil {
+		log.Fatalf("forced background shutdown: %v", err)
+	}
 	log.Println("Server stopped")
 }
+
+// newMTLSServer builds the mutual-TLS listener. Client certificates are
+// verified against MTLS_CLIENT_CA_FILE when presented; requests without one
+// still authenticate with a bearer token.
+func newMTLSServer(cfg *config.Config, handler http.Handler) (*http.Server, error) {
+	pem, err := os.ReadFile(cfg.MTLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %s", cfg.MTLSClientCAFile)
+	}
+	return &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.MTLSPort),
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			ClientAuth: tls.VerifyClientCertIfGiven,
+			ClientCAs:  pool,
+			MinVersion: tls.VersionTLS12,
+		},
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}, nil
+}
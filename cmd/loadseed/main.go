@@ -0,0 +1,130 @@
+// Command loadseed creates the accounts and file the k6 scenarios in
+// loadtest/ run against, and writes them as JSON for the script to read.
+//
+//	go run ./cmd/loadseed -users 50 -out loadtest/seed.json
+//
+// It is idempotent: existing loadtest-NNNN users get their password reset.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-api-nosql/internal/app"
+	fileapp "github.com/go-api-nosql/internal/application/file"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// downloadSize is the size of the seeded download file.
+const downloadSize = 64 << 10
+
+type seedUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type seedFile struct {
+	Password string     `json:"password"`
+	Users    []seedUser `json:"users"`
+	FileID   string     `json:"file_id,omitempty"`
+}
+
+func main() {
+	users := flag.Int("users", 50, "number of loadtest-NNNN accounts")
+	password := flag.String("password", "load-test-password", "password set on every account")
+	out := flag.String("out", "loadtest/seed.json", "where to write the seed data")
+	flag.Parse()
+	if *users < 1 {
+		log.Fatal("-users must be at least 1")
+	}
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, reading from environment")
+	}
+
+	cfg := config.Load()
+	if err := app.SetupLogging(cfg); err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+	deps, err := app.NewDeps(ctx, cfg)
+	if err != nil {
+		log.Fatalf("infrastructure: %v", err)
+	}
+	seed := seedFile{Password: *password}
+	if seed.Users, err = seedUsers(ctx, deps.UserRepo, *users, *password); err != nil {
+		log.Fatalf("seed users: %v", err)
+	}
+	if seed.FileID, err = seedDownload(ctx, deps, seed.Users[0].ID); err != nil {
+		log.Fatalf("seed file: %v", err)
+	}
+	data, err := json.MarshalIndent(seed, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("seeded %d users and file %q into %s", len(seed.Users), seed.FileID, *out)
+}
+
+// seedUsers creates or resets n accounts sharing one bcrypt hash.
+func seedUsers(ctx context.Context, repo transporthttp.UserRepository, n int, password string) ([]seedUser, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]seedUser, 0, n)
+	for i := range n {
+		username := fmt.Sprintf("loadtest-%04d", i)
+		u, err := repo.GetByUsername(ctx, username)
+		switch {
+		case err == nil:
+			err = repo.Update(ctx, u.UserID, map[string]interface{}{"password_hash": string(hash), "enable": 1})
+		case errors.Is(err, domain.ErrNotFound):
+			now := time.Now().UTC()
+			u = &domain.User{
+				UserID: id.New(), Username: username, Email: username + "@example.com",
+				PasswordHash: string(hash), Role: domain.RoleUser, FirstName: "Load", LastName: "Test",
+				Enable: 1, EmailConfirmed: true, CreatedAt: now, UpdatedAt: now,
+			}
+			err = repo.Put(ctx, u)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", username, err)
+		}
+		users = append(users, seedUser{ID: u.UserID, Username: username})
+	}
+	return users, nil
+}
+
+// seedDownload uploads a public file owned by ownerID, or returns "" when
+// file storage is disabled.
+func seedDownload(ctx context.Context, deps *transporthttp.Deps, ownerID string) (string, error) {
+	if deps.S3Store == nil {
+		log.Println("FEATURE_FILES is off; skipping the download scenario's file")
+		return "", nil
+	}
+	f, err := fileapp.NewService(deps.S3Store, deps.FileRepo, nil, nil).Upload(ctx, fileapp.UploadInput{
+		Reader:      bytes.NewReader(make([]byte, downloadSize)),
+		Filename:    "loadtest.bin",
+		ContentType: "application/octet-stream",
+		Size:        downloadSize,
+		UploaderID:  ownerID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return f.FileID, nil
+}
@@ -0,0 +1,127 @@
+// Command migrate runs one-off data migrations against the DynamoDB tables.
+//
+//	go run ./cmd/migrate backfill-user-keys
+//	go run ./cmd/migrate backfill-list-keys
+//	go run ./cmd/migrate reindex-search
+//	go run ./cmd/migrate reencrypt-pii
+//	go run ./cmd/migrate add-replicas
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/go-api-nosql/internal/app"
+	"github.com/go-api-nosql/internal/application/search"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/infrastructure/dynamo"
+	"github.com/go-api-nosql/internal/infrastructure/envelope"
+	"github.com/go-api-nosql/internal/infrastructure/opensearch"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, reading from environment")
+	}
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: migrate backfill-user-keys|backfill-list-keys|reindex-search|reencrypt-pii|add-replicas")
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	if err := app.SetupLogging(cfg); err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+	client, err := dynamo.NewClient(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	capacity, err := dynamo.NewCapacity(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Bootstrap adds any missing indexes before data is migrated into them.
+	dynamo.Bootstrap(ctx, client, cfg.DynamoTables, capacity)
+
+	users, err := userRepo(ctx, cfg, client)
+	if err != nil {
+		log.Fatalf("PII encryption: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "backfill-user-keys":
+		n, err := users.BackfillKeys(ctx)
+		if err != nil {
+			log.Fatalf("backfill-user-keys: %v (after %d users)", err, n)
+		}
+		log.Printf("backfill-user-keys: updated %d users", n)
+	case "backfill-list-keys":
+		t := cfg.DynamoTables
+		n, err := dynamo.BackfillListKeys(ctx, client, t.Statuses, t.Roles, t.Templates, t.AppVersions)
+		if err != nil {
+			log.Fatalf("backfill-list-keys: %v (after %d items)", err, n)
+		}
+		log.Printf("backfill-list-keys: updated %d items", n)
+	case "reindex-search":
+		n, err := reindexSearch(ctx, cfg, client, users)
+		if err != nil {
+			log.Fatalf("reindex-search: %v (after %d items)", err, n)
+		}
+		log.Printf("reindex-search: projected %d users and files", n)
+	case "reencrypt-pii":
+		n, err := users.ReencryptPII(ctx)
+		if err != nil {
+			log.Fatalf("reencrypt-pii: %v (after %d users)", err, n)
+		}
+		log.Printf("reencrypt-pii: sealed %d users under the current key", n)
+	case "add-replicas":
+		if len(cfg.DynamoReplicaRegions) == 0 {
+			log.Fatal("add-replicas: DYNAMO_REPLICA_REGIONS is not set")
+		}
+		if err := dynamo.EnsureReplicas(ctx, client, cfg.DynamoTables.Names(), cfg.DynamoReplicaRegions); err != nil {
+			log.Fatalf("add-replicas: %v", err)
+		}
+		log.Printf("add-replicas: every table has replicas in %v", cfg.DynamoReplicaRegions)
+	default:
+		log.Fatalf("unknown migration %q", os.Args[1])
+	}
+}
+
+// reindexSearch projects every user and file into OpenSearch, seeding a new
+// cluster or repairing writes the stream sync missed.
+func reindexSearch(ctx context.Context, cfg *config.Config, client *dynamodb.Client, users *dynamo.UserRepo) (int, error) {
+	if cfg.OpenSearchURL == "" {
+		return 0, fmt.Errorf("OPENSEARCH_URL is not set")
+	}
+	svc := search.NewService(opensearch.NewClient(cfg.OpenSearchURL), nil, nil)
+	n := 0
+	err := users.Each(ctx, func(u domain.User) error {
+		n++
+		return svc.ProjectUser(ctx, &u)
+	})
+	if err != nil {
+		return n, err
+	}
+	err = dynamo.NewFileRepo(client, cfg.DynamoTables.Files).WithParallelScan(dynamo.NewParallelScan(cfg)).Each(ctx, func(f domain.File) error {
+		n++
+		return svc.ProjectFile(ctx, &f)
+	})
+	return n, err
+}
+
+// userRepo opens the users table with the same PII key as the API, so
+// migrations can read sealed attributes.
+func userRepo(ctx context.Context, cfg *config.Config, client *dynamodb.Client) (*dynamo.UserRepo, error) {
+	repo := dynamo.NewUserRepo(client, cfg.DynamoTables.Users, cfg.EmailFoldGmail, nil).WithParallelScan(dynamo.NewParallelScan(cfg))
+	pii, err := envelope.New(ctx, cfg)
+	if err != nil || pii == nil {
+		return repo, err
+	}
+	return repo.WithPII(pii), nil
+}
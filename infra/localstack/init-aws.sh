@@ -6,25 +6,35 @@ set -e
 
 ENDPOINT="http://localhost:4566"
 REGION="${AWS_DEFAULT_REGION:-us-east-1}"
+# Matches the API's DYNAMO_TABLE_PREFIX.
+PREFIX="${DYNAMO_TABLE_PREFIX:-}"
 
 echo ">>> Creating DynamoDB tables..."
 
 awslocal dynamodb create-table \
-  --table-name users \
+  --table-name "${PREFIX}users" \
   --attribute-definitions \
     AttributeName=user_id,AttributeType=S \
     AttributeName=username,AttributeType=S \
     AttributeName=email,AttributeType=S \
     AttributeName=enable,AttributeType=N \
+    AttributeName=created_at,AttributeType=S \
+    AttributeName=role,AttributeType=S \
+    AttributeName=username_key,AttributeType=S \
+    AttributeName=email_key,AttributeType=S \
   --key-schema AttributeName=user_id,KeyType=HASH \
   --billing-mode PAY_PER_REQUEST \
+  --stream-specification StreamEnabled=true,StreamViewType=NEW_AND_OLD_IMAGES \
   --global-secondary-indexes \
     '[{"IndexName":"username-index","KeySchema":[{"AttributeName":"username","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}},
       {"IndexName":"email-index","KeySchema":[{"AttributeName":"email","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}},
-      {"IndexName":"enable-index","KeySchema":[{"AttributeName":"enable","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
+      {"IndexName":"enable-created_at-index","KeySchema":[{"AttributeName":"enable","KeyType":"HASH"},{"AttributeName":"created_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}},
+      {"IndexName":"role-created_at-index","KeySchema":[{"AttributeName":"role","KeyType":"HASH"},{"AttributeName":"created_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}},
+      {"IndexName":"username_key-index","KeySchema":[{"AttributeName":"username_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}},
+      {"IndexName":"email_key-index","KeySchema":[{"AttributeName":"email_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
 
 awslocal dynamodb create-table \
-  --table-name sessions \
+  --table-name "${PREFIX}sessions" \
   --attribute-definitions \
     AttributeName=session_id,AttributeType=S \
     AttributeName=user_id,AttributeType=S \
@@ -36,13 +46,17 @@ awslocal dynamodb create-table \
       {"IndexName":"refresh_token-index","KeySchema":[{"AttributeName":"refresh_token","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
 
 awslocal dynamodb create-table \
-  --table-name statuses \
-  --attribute-definitions AttributeName=status_id,AttributeType=S \
+  --table-name "${PREFIX}statuses" \
+  --attribute-definitions \
+    AttributeName=status_id,AttributeType=S \
+    AttributeName=list_key,AttributeType=S \
   --key-schema AttributeName=status_id,KeyType=HASH \
-  --billing-mode PAY_PER_REQUEST
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"list_key-index","KeySchema":[{"AttributeName":"list_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
 
 awslocal dynamodb create-table \
-  --table-name devices \
+  --table-name "${PREFIX}devices" \
   --attribute-definitions \
     AttributeName=device_id,AttributeType=S \
     AttributeName=user_id,AttributeType=S \
@@ -54,28 +68,36 @@ awslocal dynamodb create-table \
       {"IndexName":"device_uuid-index","KeySchema":[{"AttributeName":"device_uuid","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
 
 awslocal dynamodb create-table \
-  --table-name notifications \
+  --table-name "${PREFIX}notifications" \
   --attribute-definitions \
     AttributeName=notification_id,AttributeType=S \
     AttributeName=user_id,AttributeType=S \
     AttributeName=created_at,AttributeType=S \
+    AttributeName=status,AttributeType=S \
+    AttributeName=due_at,AttributeType=N \
   --key-schema AttributeName=notification_id,KeyType=HASH \
   --billing-mode PAY_PER_REQUEST \
   --global-secondary-indexes \
-    '[{"IndexName":"user_id-created_at-index","KeySchema":[{"AttributeName":"user_id","KeyType":"HASH"},{"AttributeName":"created_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}}]'
+    '[{"IndexName":"user_id-created_at-index","KeySchema":[{"AttributeName":"user_id","KeyType":"HASH"},{"AttributeName":"created_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}},{"IndexName":"status-due_at-index","KeySchema":[{"AttributeName":"status","KeyType":"HASH"},{"AttributeName":"due_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+# Dismissed notifications are purged through TTL
+awslocal dynamodb update-time-to-live \
+  --table-name "${PREFIX}notifications" \
+  --time-to-live-specification "Enabled=true,AttributeName=expires_at"
 
 awslocal dynamodb create-table \
-  --table-name files \
+  --table-name "${PREFIX}files" \
   --attribute-definitions \
     AttributeName=file_id,AttributeType=S \
     AttributeName=uploaded_by_user_id,AttributeType=S \
   --key-schema AttributeName=file_id,KeyType=HASH \
   --billing-mode PAY_PER_REQUEST \
+  --stream-specification StreamEnabled=true,StreamViewType=NEW_AND_OLD_IMAGES \
   --global-secondary-indexes \
     '[{"IndexName":"uploaded_by_user_id-index","KeySchema":[{"AttributeName":"uploaded_by_user_id","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
 
 awslocal dynamodb create-table \
-  --table-name user_verifications \
+  --table-name "${PREFIX}user_verifications" \
   --attribute-definitions \
     AttributeName=user_id,AttributeType=S \
     AttributeName=type,AttributeType=S \
@@ -86,15 +108,164 @@ awslocal dynamodb create-table \
 
 # Enable TTL on user_verifications for automatic OTP/token expiry
 awslocal dynamodb update-time-to-live \
-  --table-name user_verifications \
+  --table-name "${PREFIX}user_verifications" \
+  --time-to-live-specification "Enabled=true,AttributeName=expires_at"
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}notification_templates" \
+  --attribute-definitions \
+    AttributeName=name,AttributeType=S \
+    AttributeName=list_key,AttributeType=S \
+  --key-schema AttributeName=name,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"list_key-index","KeySchema":[{"AttributeName":"list_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}messages" \
+  --attribute-definitions \
+    AttributeName=conversation_id,AttributeType=S \
+    AttributeName=sent_at,AttributeType=N \
+    AttributeName=unread_for,AttributeType=S \
+  --key-schema \
+    AttributeName=conversation_id,KeyType=HASH \
+    AttributeName=sent_at,KeyType=RANGE \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"unread_for-index","KeySchema":[{"AttributeName":"unread_for","KeyType":"HASH"},{"AttributeName":"sent_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}activities" \
+  --attribute-definitions \
+    AttributeName=user_id,AttributeType=S \
+    AttributeName=created_at,AttributeType=S \
+  --key-schema \
+    AttributeName=user_id,KeyType=HASH \
+    AttributeName=created_at,KeyType=RANGE \
+  --billing-mode PAY_PER_REQUEST
+
+# Expire activity feed entries after ACTIVITY_RETENTION_DAYS
+awslocal dynamodb update-time-to-live \
+  --table-name "${PREFIX}activities" \
+  --time-to-live-specification "Enabled=true,AttributeName=expires_at"
+
+# Built-in Admin/User roles are seeded by the API on startup
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}roles" \
+  --attribute-definitions \
+    AttributeName=name,AttributeType=S \
+    AttributeName=list_key,AttributeType=S \
+  --key-schema AttributeName=name,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"list_key-index","KeySchema":[{"AttributeName":"list_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}audit_logs" \
+  --attribute-definitions \
+    AttributeName=day,AttributeType=S \
+    AttributeName=audit_id,AttributeType=S \
+  --key-schema \
+    AttributeName=day,KeyType=HASH \
+    AttributeName=audit_id,KeyType=RANGE \
+  --billing-mode PAY_PER_REQUEST
+
+awslocal dynamodb update-time-to-live \
+  --table-name "${PREFIX}audit_logs" \
   --time-to-live-specification "Enabled=true,AttributeName=expires_at"
 
 awslocal dynamodb create-table \
-  --table-name app_versions \
-  --attribute-definitions AttributeName=version_id,AttributeType=S \
+  --table-name "${PREFIX}approvals" \
+  --attribute-definitions \
+    AttributeName=approval_id,AttributeType=S \
+    AttributeName=status,AttributeType=S \
+    AttributeName=requested_at,AttributeType=S \
+  --key-schema AttributeName=approval_id,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"status-requested_at-index","KeySchema":[{"AttributeName":"status","KeyType":"HASH"},{"AttributeName":"requested_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}broadcasts" \
+  --attribute-definitions \
+    AttributeName=broadcast_id,AttributeType=S \
+    AttributeName=status,AttributeType=S \
+    AttributeName=created_at,AttributeType=S \
+  --key-schema AttributeName=broadcast_id,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"status-created_at-index","KeySchema":[{"AttributeName":"status","KeyType":"HASH"},{"AttributeName":"created_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}settings" \
+  --attribute-definitions AttributeName=name,AttributeType=S \
+  --key-schema AttributeName=name,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}launch_allowlist" \
+  --attribute-definitions \
+    AttributeName=entry_key,AttributeType=S \
+    AttributeName=list_key,AttributeType=S \
+  --key-schema AttributeName=entry_key,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"list_key-index","KeySchema":[{"AttributeName":"list_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}passkeys" \
+  --attribute-definitions \
+    AttributeName=credential_id,AttributeType=S \
+    AttributeName=user_id,AttributeType=S \
+  --key-schema AttributeName=credential_id,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"user_id-index","KeySchema":[{"AttributeName":"user_id","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}oauth_clients" \
+  --attribute-definitions \
+    AttributeName=client_id,AttributeType=S \
+    AttributeName=list_key,AttributeType=S \
+  --key-schema AttributeName=client_id,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"list_key-index","KeySchema":[{"AttributeName":"list_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}api_keys" \
+  --attribute-definitions \
+    AttributeName=key_id,AttributeType=S \
+    AttributeName=list_key,AttributeType=S \
+  --key-schema AttributeName=key_id,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"list_key-index","KeySchema":[{"AttributeName":"list_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}app_versions" \
+  --attribute-definitions \
+    AttributeName=version_id,AttributeType=S \
+    AttributeName=list_key,AttributeType=S \
+    AttributeName=platform,AttributeType=S \
+    AttributeName=released_at,AttributeType=S \
   --key-schema AttributeName=version_id,KeyType=HASH \
+  --billing-mode PAY_PER_REQUEST \
+  --global-secondary-indexes \
+    '[{"IndexName":"list_key-index","KeySchema":[{"AttributeName":"list_key","KeyType":"HASH"}],"Projection":{"ProjectionType":"ALL"}},
+      {"IndexName":"platform-released_at-index","KeySchema":[{"AttributeName":"platform","KeyType":"HASH"},{"AttributeName":"released_at","KeyType":"RANGE"}],"Projection":{"ProjectionType":"ALL"}}]'
+
+awslocal dynamodb create-table \
+  --table-name "${PREFIX}rate_limits" \
+  --attribute-definitions AttributeName=limit_key,AttributeType=S \
+  --key-schema AttributeName=limit_key,KeyType=HASH \
   --billing-mode PAY_PER_REQUEST
 
+# Enable TTL on rate_limits so expired window counters are purged automatically
+awslocal dynamodb update-time-to-live \
+  --table-name "${PREFIX}rate_limits" \
+  --time-to-live-specification "Enabled=true,AttributeName=expires_at"
+
 echo ">>> Creating S3 bucket..."
 awslocal s3 mb s3://${S3_BUCKET_NAME:-go-api-files}
 
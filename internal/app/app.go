@@ -0,0 +1,42 @@
+// Package app is the composition root: it builds config → infrastructure →
+// services → HTTP handler once, so every entry point and test wires the API
+// the same way.
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/config"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// App is a fully wired API.
+type App struct {
+	Config   *config.Config
+	Deps     *transporthttp.Deps
+	Services *transporthttp.Services
+	Handler  http.Handler
+}
+
+// New wires the services and the HTTP handler on top of deps, which usually
+// come from NewDeps; tests can pass fakes instead. Set deps.Extensions and the
+// hook fields before calling New. Background jobs and cache cleanups run until
+// ctx is cancelled; pass a lifecycle.Manager's context to wait for them on
+// shutdown.
+func New(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps) (*App, error) {
+	svc, err := NewServices(ctx, cfg, deps)
+	if err != nil {
+		return nil, err
+	}
+	handler, err := transporthttp.NewRouter(ctx, cfg, deps, svc)
+	if err != nil {
+		return nil, err
+	}
+	return &App{
+		Config:   cfg,
+		Deps:     deps,
+		Services: svc,
+		Handler:  handler,
+	}, nil
+}
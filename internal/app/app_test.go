@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/infrastructure/dynamo"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-api-nosql/internal/pkg/chaos"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServices_RequiresJWTProvider(t *testing.T) {
+	_, err := NewServices(context.Background(), &config.Config{}, &transporthttp.Deps{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JWT provider")
+}
+
+func TestNewServices_GoogleAuthRequiresClientID(t *testing.T) {
+	cfg := &config.Config{Features: config.Features{GoogleAuth: true}}
+
+	_, err := NewServices(context.Background(), cfg, &transporthttp.Deps{JWTProvider: &jwtinfra.Provider{}})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GOOGLE_CLIENT_ID")
+}
+
+func TestOverride(t *testing.T) {
+	assert.Equal(t, 1, override(1, nil))
+	assert.Equal(t, 2, override(1, func(n int) int { return n + 1 }))
+}
+
+func TestNewMailer_Capture(t *testing.T) {
+	mailer, outbox := newMailer(&config.Config{MailProvider: "capture", AppEnv: "staging"})
+
+	require.NotNil(t, outbox)
+	require.NoError(t, mailer.SendEmail("jane@example.com", "code", "123456"))
+	assert.Len(t, outbox.Messages(), 1)
+}
+
+func TestNewMailer_SMTPHasNoOutbox(t *testing.T) {
+	_, outbox := newMailer(&config.Config{MailProvider: "smtp", DevConsole: true, AppEnv: "production"})
+
+	assert.Nil(t, outbox)
+}
+
+func TestChaosAPIOptions_FailsMatchingOperations(t *testing.T) {
+	c := chaos.NewController()
+	c.Set([]chaos.Rule{{Target: chaos.TargetDynamoDB, Match: "GetItem", Percent: 100, Fail: true}})
+	cfg := &config.Config{AWSRegion: "us-east-1", AWSEndpointURL: "http://127.0.0.1:1", AWSAccessKeyID: "x", AWSSecretKey: "y"}
+	client, err := dynamo.NewClient(cfg, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, chaosAPIOptions(c, chaos.TargetDynamoDB)...)
+	})
+	require.NoError(t, err)
+
+	_, err = client.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key:       map[string]types.AttributeValue{"user_id": &types.AttributeValueMemberS{Value: "u1"}},
+	})
+
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+}
+
+func TestNewChaosController_NeverInProduction(t *testing.T) {
+	assert.Nil(t, newChaosController(&config.Config{Chaos: true, AppEnv: "production"}))
+	assert.NotNil(t, newChaosController(&config.Config{Chaos: true, AppEnv: "staging"}))
+	assert.Nil(t, chaosAPIOptions(nil, chaos.TargetS3))
+}
+
+func TestNewUsageService_DefaultQuotas(t *testing.T) {
+	cfg := &config.Config{Features: config.Features{UsageMetering: true}}
+	deps := &transporthttp.Deps{UsageRepo: &dynamo.UsageRepo{}}
+
+	svc, err := newUsageService(cfg, deps, nil)
+
+	require.NoError(t, err)
+	assert.NotNil(t, svc)
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"log"
+
+	"github.com/go-api-nosql/internal/application/birthday"
+	"github.com/go-api-nosql/internal/application/notification"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/pkg/jobs"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// startBirthdays starts the job that sends birthday notifications when
+// FEATURE_BIRTHDAY_NOTIFICATIONS is on. Each year's notification is claimed on
+// the user row, so every replica may run the job.
+func startBirthdays(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps, notifications notification.Service) {
+	if !cfg.Features.Birthdays {
+		return
+	}
+	svc := birthday.NewService(birthday.ServiceDeps{Users: deps.UserRepo, Notifications: notifications})
+	jobs.Start(ctx, jobs.Job{
+		Name:     "send-birthday-notifications",
+		Interval: cfg.BirthdayInterval,
+		Run: func(ctx context.Context) error {
+			sent, err := svc.Run(ctx)
+			if sent > 0 {
+				log.Printf("birthdays: sent %d notifications", sent)
+			}
+			return err
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/broadcast"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/pkg/jobs"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// newBroadcastService builds the broadcast service and starts the job that
+// fans broadcasts out, or returns nil when notifications are disabled.
+// Broadcasts are leased per run, so every replica may run the job.
+func newBroadcastService(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps, auditSvc audit.Service) broadcast.Service {
+	if !cfg.Features.Notifications {
+		return nil
+	}
+	svc := broadcast.NewService(broadcast.ServiceDeps{
+		BroadcastRepo: deps.BroadcastRepo,
+		Users:         deps.UserRepo,
+		Devices:       deps.DeviceRepo,
+		AppVersions:   deps.AppVersionRepo,
+		Notifications: deps.NotificationRepo,
+		Mailer:        deps.Mailer,
+		Audit:         auditSvc,
+	})
+	jobs.Start(ctx, jobs.Job{Name: "fan-out-broadcasts", Interval: cfg.SchedulerInterval, Run: svc.Run})
+	return svc
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"log"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/pkg/chaos"
+)
+
+// newChaosController returns nil unless CHAOS_INJECTION is on outside
+// production; nothing is injected until rules are set via /v1/admin/chaos.
+func newChaosController(cfg *config.Config) *chaos.Controller {
+	if cfg.Chaos && !cfg.ChaosEnabled() {
+		log.Printf("WARN: CHAOS_INJECTION ignored because APP_ENV=%s", cfg.AppEnv)
+	}
+	if !cfg.ChaosEnabled() {
+		return nil
+	}
+	log.Printf("WARN: chaos injection enabled; rules are managed at /v1/admin/chaos")
+	return chaos.NewController()
+}
+
+// chaosAPIOptions applies c's rules for target to every AWS SDK call, matched
+// by operation name (e.g. "GetItem"). It runs once per call, after the SDK has
+// registered the operation name and before its retry loop. A nil c adds
+// nothing.
+func chaosAPIOptions(c *chaos.Controller, target string) []func(*middleware.Stack) error {
+	if c == nil {
+		return nil
+	}
+	inject := middleware.InitializeMiddlewareFunc("ChaosInjection", func(
+		ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		if rule, hit := c.Pick(target, awsmiddleware.GetOperationName(ctx)); hit {
+			if err := rule.Apply(ctx); err != nil {
+				return middleware.InitializeOutput{}, middleware.Metadata{}, err
+			}
+		}
+		return next.HandleInitialize(ctx, in)
+	})
+	return []func(*middleware.Stack) error{func(s *middleware.Stack) error {
+		return s.Initialize.Add(inject, middleware.After)
+	}}
+}
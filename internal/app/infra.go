@@ -0,0 +1,211 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/infrastructure/dynamo"
+	"github.com/go-api-nosql/internal/infrastructure/envelope"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-api-nosql/internal/infrastructure/opensearch"
+	s3infra "github.com/go-api-nosql/internal/infrastructure/s3"
+	"github.com/go-api-nosql/internal/infrastructure/smtp"
+	"github.com/go-api-nosql/internal/infrastructure/sns"
+	"github.com/go-api-nosql/internal/pkg/chaos"
+	"github.com/go-api-nosql/internal/pkg/dbcost"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// NewDeps creates the AWS clients, repositories and senders described by cfg,
+// bootstrapping the DynamoDB tables first. Optional backends that cannot be
+// set up are logged and left nil. Sinks that flush in the background, such as
+// the CloudWatch access log, run until ctx is cancelled; pass a
+// lifecycle.Manager's context to flush them on shutdown.
+func NewDeps(ctx context.Context, cfg *config.Config) (*transporthttp.Deps, error) {
+	chaosCtl := newChaosController(cfg)
+	capacity, err := dynamo.NewCapacity(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tenantOpts, err := tenantAPIOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var costs *dbcost.Ledger
+	if cfg.DynamoCostTracking {
+		costs = dbcost.NewLedger()
+	}
+	// Bootstrap DynamoDB tables (creates them if they don't exist).
+	dynamoClient, err := dynamo.NewClient(cfg, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, chaosAPIOptions(chaosCtl, chaos.TargetDynamoDB)...)
+		if costs != nil {
+			o.APIOptions = append(o.APIOptions, dynamo.ConsumedCapacityAPIOptions()...)
+		}
+		if cfg.ScansForbidden() {
+			o.APIOptions = append(o.APIOptions, dynamo.ScanGuardAPIOptions()...)
+		}
+		o.APIOptions = append(o.APIOptions, tenantOpts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.StartupWait > 0 {
+		if err := dynamo.WaitReady(ctx, dynamoClient, cfg.StartupWait); err != nil {
+			return nil, err
+		}
+	}
+	dynamo.Bootstrap(ctx, dynamoClient, cfg.DynamoTables, capacity)
+	if len(cfg.DynamoReplicaRegions) > 0 {
+		dynamo.CheckReplicas(ctx, dynamoClient, cfg.DynamoTables.Names(), cfg.DynamoReplicaRegions)
+	}
+	if cfg.DynamoPITR {
+		dynamo.EnablePITR(ctx, dynamoClient, cfg.DynamoTables.Names())
+	}
+
+	// JWT provider (optional — graceful fallback if keys are missing).
+	var jwtProvider *jwtinfra.Provider
+	if p, err := jwtinfra.NewProvider(cfg); err == nil {
+		jwtProvider = p
+	} else {
+		log.Printf("WARN: JWT provider not available: %v", err)
+	}
+
+	if cfg.MailProvider != "smtp" && cfg.MailProvider != "capture" {
+		return nil, fmt.Errorf("MAIL_PROVIDER must be smtp or capture, got %q", cfg.MailProvider)
+	}
+	cursors, err := newCursorCodec(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pii, err := envelope.New(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("PII encryption: %w", err)
+	}
+	streamsClient, err := dynamo.NewStreamsClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tables := cfg.DynamoTables
+	scan := dynamo.NewParallelScan(cfg)
+	userRepo := dynamo.NewUserRepo(dynamoClient, tables.Users, cfg.EmailFoldGmail, cursors).WithParallelScan(scan)
+	if pii != nil {
+		userRepo.WithPII(pii)
+	}
+	deps := &transporthttp.Deps{
+		UserRepo:         userRepo,
+		SessionRepo:      dynamo.NewSessionRepo(dynamoClient, tables.Sessions),
+		StatusRepo:       dynamo.NewStatusRepo(dynamoClient, tables.Statuses),
+		DeviceRepo:       dynamo.NewDeviceRepo(dynamoClient, tables.Devices),
+		NotificationRepo: dynamo.NewNotificationRepo(dynamoClient, tables.Notifications).WithParallelScan(scan),
+		TemplateRepo:     dynamo.NewNotificationTemplateRepo(dynamoClient, tables.Templates),
+		MessageRepo:      dynamo.NewMessageRepo(dynamoClient, tables.Messages, cursors),
+		ActivityRepo:     dynamo.NewActivityRepo(dynamoClient, tables.Activities, cursors),
+		RoleRepo:         dynamo.NewRoleRepo(dynamoClient, tables.Roles),
+		PlanRepo:         dynamo.NewPlanRepo(dynamoClient, tables.Plans),
+		AuditRepo:        dynamo.NewAuditRepo(dynamoClient, tables.AuditLogs).WithParallelScan(scan),
+		FileRepo:         dynamo.NewFileRepo(dynamoClient, tables.Files).WithParallelScan(scan),
+		VerificationRepo: dynamo.NewVerificationRepo(dynamoClient, tables.UserVerifications),
+		AppVersionRepo:   dynamo.NewAppVersionRepo(dynamoClient, tables.AppVersions),
+		RateLimitRepo:    dynamo.NewRateLimitRepo(dynamoClient, tables.RateLimits),
+		ApprovalRepo:     dynamo.NewApprovalRepo(dynamoClient, tables.Approvals),
+		BroadcastRepo:    dynamo.NewBroadcastRepo(dynamoClient, tables.Broadcasts),
+		SettingsRepo:     dynamo.NewSettingsRepo(dynamoClient, tables.Settings),
+		LaunchRepo:       dynamo.NewLaunchRepo(dynamoClient, tables.LaunchAllowlist),
+		PasskeyRepo:      dynamo.NewPasskeyRepo(dynamoClient, tables.Passkeys),
+		OAuthClientRepo:  dynamo.NewOAuthClientRepo(dynamoClient, tables.OAuthClients),
+		APIKeyRepo:       dynamo.NewAPIKeyRepo(dynamoClient, tables.APIKeys),
+		UserStream:       dynamo.NewStreamReader[domain.User](dynamoClient, streamsClient, tables.Users),
+		FileStream:       dynamo.NewStreamReader[domain.File](dynamoClient, streamsClient, tables.Files),
+		BackupStatus:     dynamo.NewBackupStatus(dynamoClient),
+		DynamoClient:     dynamoClient,
+		DynamoCosts:      costs,
+		JWTProvider:      jwtProvider,
+		Chaos:            chaosCtl,
+	}
+	deps.Mailer, deps.Outbox = newMailer(cfg)
+	if deps.AccessLog, err = newAccessLog(ctx, cfg); err != nil {
+		return nil, err
+	}
+	if err := addOptionalBackends(cfg, deps); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// newMailer returns the mailer for MAIL_PROVIDER and, when mail is captured,
+// the outbox holding it. The dev console captures mail too, but still
+// forwards it to SMTP.
+func newMailer(cfg *config.Config) (smtp.Mailer, *smtp.Outbox) {
+	if cfg.DevConsole && !cfg.DevConsoleEnabled() {
+		log.Printf("WARN: DEV_CONSOLE ignored because APP_ENV=%s", cfg.AppEnv)
+	}
+	switch {
+	case cfg.MailProvider == "capture":
+		outbox := smtp.NewOutbox(nil)
+		return outbox, outbox
+	case cfg.DevConsoleEnabled():
+		outbox := smtp.NewOutbox(smtp.NewMailer(cfg))
+		return outbox, outbox
+	default:
+		return smtp.NewMailer(cfg), nil
+	}
+}
+
+// addOptionalBackends sets the backends that depend on a feature flag or on
+// optional configuration; each stays nil when off or unavailable. Only S3 is
+// required once FEATURE_FILES is on, since the file routes cannot work
+// without it.
+func addOptionalBackends(cfg *config.Config, deps *transporthttp.Deps) error {
+	if cfg.Features.Files {
+		client, err := s3infra.NewClient(cfg, func(o *s3.Options) {
+			o.APIOptions = append(o.APIOptions, chaosAPIOptions(deps.Chaos, chaos.TargetS3)...)
+		})
+		if err != nil {
+			return err
+		}
+		deps.S3Store = s3infra.NewStore(client, cfg.S3BucketName, cfg.S3KeyPrefix)
+	}
+	if cfg.Features.PhoneConfirmation {
+		if sender, err := sns.NewSender(cfg); err == nil {
+			deps.SMSSender = sender
+		} else {
+			log.Printf("WARN: SNS sender not available: %v", err)
+		}
+	}
+	// Push is also disabled without a platform application ARN.
+	if cfg.Features.Notifications {
+		if sender, err := sns.NewPushSender(cfg); err == nil {
+			deps.PushSender = sender
+		} else {
+			log.Printf("WARN: push sender not available: %v", err)
+		}
+	}
+	if cfg.OpenSearchURL != "" {
+		deps.SearchIndex = opensearch.NewClient(cfg.OpenSearchURL)
+	}
+	if cfg.Features.UsageMetering {
+		deps.UsageRepo = dynamo.NewUsageRepo(deps.DynamoClient, cfg.DynamoTables.Usage)
+	}
+	return nil
+}
+
+// newCursorCodec signs pagination cursors with CURSOR_SECRET. Without a shared
+// secret each process signs with its own key, so cursors break across restarts
+// and replicas.
+func newCursorCodec(cfg *config.Config) (*dynamo.CursorCodec, error) {
+	secret := []byte(cfg.CursorSecret)
+	if len(secret) == 0 {
+		log.Printf("WARN: CURSOR_SECRET not set; pagination cursors are only valid on this instance until restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("cursor secret: %w", err)
+		}
+	}
+	return dynamo.NewCursorCodec(secret), nil
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"log"
+
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/launch"
+	"github.com/go-api-nosql/internal/config"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// newLaunchService builds the soft-launch allowlist. Its registration hooks
+// run ahead of deps.PreRegisterHooks and deps.PostRegisterHooks whether or
+// not LAUNCH_GATE is on, since they admit everyone while it is off. The
+// bootstrap admin is exempt so a fresh deployment can still seed it.
+func newLaunchService(cfg *config.Config, deps *transporthttp.Deps, auditSvc audit.Service) launch.Service {
+	if cfg.LaunchGate {
+		log.Printf("launch gate is on; registration requires an allowlisted email or an invite code")
+	}
+	return launch.NewService(launch.ServiceDeps{
+		LaunchRepo: deps.LaunchRepo,
+		Audit:      auditSvc,
+		Enabled:    cfg.LaunchGate,
+		Exempt:     []string{cfg.AdminEmail},
+	})
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/infrastructure/cloudwatch"
+	"github.com/go-api-nosql/internal/pkg/accesslog"
+	"github.com/go-api-nosql/internal/pkg/logging"
+)
+
+// SetupLogging installs the LOG_* handler as the slog default. Output of the
+// standard log package goes through it too, so every line of the process
+// shares one format, level and redaction. Entry points call it right after
+// loading the config.
+func SetupLogging(cfg *config.Config) error {
+	level, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	logger, err := logging.New(os.Stderr, logging.Options{
+		Level:       level,
+		Format:      cfg.LogFormat,
+		DebugSample: cfg.LogDebugSample,
+		Redact:      cfg.LogRedact,
+	})
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// newAccessLog opens the ACCESS_LOG_SINK; it is nil when the sink is "off".
+// The cloudwatch sink sends its buffer in a background task until ctx ends.
+func newAccessLog(ctx context.Context, cfg *config.Config) (*accesslog.Logger, error) {
+	switch cfg.AccessLogSink {
+	case "off":
+		return nil, nil
+	case "stdout":
+		return accesslog.New(os.Stdout), nil
+	case "file":
+		f, err := accesslog.OpenRotatingFile(cfg.AccessLogFile, int64(cfg.AccessLogMaxSizeMB)<<20, cfg.AccessLogMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		return accesslog.New(f), nil
+	case "cloudwatch":
+		if cfg.AccessLogFlush <= 0 {
+			return nil, fmt.Errorf("ACCESS_LOG_FLUSH must be positive, got %s", cfg.AccessLogFlush)
+		}
+		w, err := cloudwatch.NewLogWriter(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return accesslog.New(w), nil
+	}
+	return nil, fmt.Errorf("ACCESS_LOG_SINK must be stdout, file, cloudwatch or off, got %q", cfg.AccessLogSink)
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/oauth"
+	"github.com/go-api-nosql/internal/config"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// oauthTokenAdapter adapts *jwtinfra.Provider to oauth.tokenIssuer.
+type oauthTokenAdapter struct{ p *jwtinfra.Provider }
+
+func (a *oauthTokenAdapter) Issue(ctx context.Context, g oauth.Grant) (string, error) {
+	return a.p.SignDelegated(ctx, jwtinfra.Delegation{
+		UserID:    g.UserID,
+		Role:      g.Role,
+		SessionID: g.SessionID,
+		ClientID:  g.ClientID,
+		Scopes:    g.Scopes,
+	})
+}
+
+func (a *oauthTokenAdapter) Inspect(token string) (*oauth.AccessToken, error) {
+	c, err := a.p.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	at := &oauth.AccessToken{Grant: oauth.Grant{
+		UserID:    c.UserID,
+		Role:      c.Role,
+		SessionID: c.SessionID,
+		ClientID:  c.ClientID,
+		Scopes:    c.Scopes,
+	}}
+	if c.ExpiresAt != nil {
+		at.ExpiresAt = c.ExpiresAt.Time
+	}
+	if c.IssuedAt != nil {
+		at.IssuedAt = c.IssuedAt.Time
+	}
+	return at, nil
+}
+
+// newOAuthService builds the OAuth2 authorization server, or returns nil
+// when FEATURE_OAUTH_SERVER is off.
+func newOAuthService(cfg *config.Config, deps *transporthttp.Deps, auditSvc audit.Service) oauth.Service {
+	if !cfg.Features.OAuthServer {
+		return nil
+	}
+	return oauth.NewService(oauth.ServiceDeps{
+		ClientRepo:  deps.OAuthClientRepo,
+		SessionRepo: deps.SessionRepo,
+		UserRepo:    deps.UserRepo,
+		Tokens:      &oauthTokenAdapter{p: deps.JWTProvider},
+		Audit:       auditSvc,
+		AccessTTL:   cfg.JWTExpiry,
+		RefreshTTL:  days(cfg.RefreshTokenExpiryDays),
+	})
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"log"
+
+	"github.com/go-api-nosql/internal/application/onboarding"
+	"github.com/go-api-nosql/internal/application/user"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/pkg/jobs"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// newOnboardingHooks builds the onboarding email service when
+// FEATURE_ONBOARDING_EMAILS is on and starts the job that sends the reminder
+// and nudge emails. It returns deps.PostRegisterHooks followed by the hook
+// that sends the welcome email. Sends are claimed on the user row, so every
+// replica may run the job.
+func newOnboardingHooks(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps, svc *transporthttp.Services) []user.PostRegisterHook {
+	if !cfg.Features.Onboarding {
+		return deps.PostRegisterHooks
+	}
+	onboard := onboarding.NewService(onboarding.ServiceDeps{
+		Users:             deps.UserRepo,
+		Templates:         svc.Template,
+		Mailer:            deps.Mailer,
+		ReminderAfter:     days(cfg.OnboardingReminderDays),
+		ProfileNudgeAfter: days(cfg.OnboardingNudgeDays),
+		Window:            days(cfg.OnboardingWindowDays),
+	})
+	jobs.Start(ctx, jobs.Job{
+		Name:     "send-onboarding-emails",
+		Interval: cfg.OnboardingInterval,
+		Run: func(ctx context.Context) error {
+			sent, err := onboard.Run(ctx)
+			if sent > 0 {
+				log.Printf("onboarding: sent %d emails", sent)
+			}
+			return err
+		},
+	})
+	hooks := make([]user.PostRegisterHook, 0, len(deps.PostRegisterHooks)+1)
+	return append(append(hooks, deps.PostRegisterHooks...), onboard)
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/passkey"
+	"github.com/go-api-nosql/internal/config"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// newPasskeyService builds passkey registration and login, or returns nil
+// when FEATURE_PASSKEYS is off.
+func newPasskeyService(cfg *config.Config, deps *transporthttp.Deps, auditSvc audit.Service) passkey.Service {
+	if !cfg.Features.Passkeys {
+		return nil
+	}
+	return passkey.NewService(passkey.ServiceDeps{
+		PasskeyRepo:      deps.PasskeyRepo,
+		VerificationRepo: deps.VerificationRepo,
+		UserRepo:         deps.UserRepo,
+		Audit:            auditSvc,
+		RPID:             cfg.WebAuthnRPID,
+		RPName:           cfg.WebAuthnRPName,
+		Origins:          cfg.WebAuthnOrigins,
+	})
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/billing"
+	"github.com/go-api-nosql/internal/application/plan"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/infrastructure/stripe"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// newPlanService builds the plan service and wraps deps.DeviceRepo so every
+// service that registers a device is held to the owner's plan device limit.
+func newPlanService(deps *transporthttp.Deps, auditSvc audit.Service) plan.Service {
+	svc := plan.NewService(plan.ServiceDeps{
+		PlanRepo: deps.PlanRepo,
+		UserRepo: deps.UserRepo,
+		FileRepo: deps.FileRepo,
+		Devices:  deps.DeviceRepo,
+		Audit:    auditSvc,
+	})
+	deps.DeviceRepo = planDevices{DeviceRepository: deps.DeviceRepo, plans: svc}
+	return svc
+}
+
+// planDevices rejects new devices past the owner's plan limit. Put is only
+// used to register devices; updates go through Update.
+type planDevices struct {
+	transporthttp.DeviceRepository
+	plans plan.Service
+}
+
+func (d planDevices) Put(ctx context.Context, dev *domain.Device) error {
+	if err := d.plans.CheckNewDevice(ctx, dev.UserID); err != nil {
+		return err
+	}
+	return d.DeviceRepository.Put(ctx, dev)
+}
+
+// newBillingService syncs plans from Stripe subscriptions when
+// FEATURE_STRIPE_BILLING is on, and returns nil otherwise.
+func newBillingService(cfg *config.Config, deps *transporthttp.Deps, plans plan.Service) (billing.Service, error) {
+	if !cfg.Features.StripeBilling {
+		return nil, nil
+	}
+	if cfg.StripeSecretKey == "" || cfg.StripeWebhookSecret == "" {
+		return nil, errors.New("FEATURE_STRIPE_BILLING requires STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET")
+	}
+	prices, err := billing.ParsePricePlans(cfg.StripePricePlans)
+	if err != nil {
+		return nil, fmt.Errorf("STRIPE_PRICE_PLANS: %w", err)
+	}
+	client := stripe.NewClient(cfg.StripeSecretKey, cfg.StripeWebhookSecret)
+	return billing.NewService(billing.ServiceDeps{
+		Events:     client,
+		Portal:     client,
+		Plans:      plans,
+		UserRepo:   deps.UserRepo,
+		PricePlans: prices,
+		ReturnURL:  cfg.StripePortalReturnURL,
+	}), nil
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"log"
+
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/readonly"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/pkg/jobs"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// newReadOnlyService loads the stored read-only switch and starts the job
+// that keeps this replica's copy of it current. If the first load fails the
+// API starts writable, unless READ_ONLY is set, until a refresh succeeds.
+func newReadOnlyService(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps, auditSvc audit.Service) readonly.Service {
+	svc := readonly.NewService(readonly.ServiceDeps{
+		Settings: deps.SettingsRepo,
+		Audit:    auditSvc,
+		Forced:   cfg.ReadOnly,
+	})
+	if err := svc.Refresh(ctx); err != nil {
+		log.Printf("WARN: %v; assuming writable until the next refresh", err)
+	}
+	if svc.Mode().Enabled {
+		log.Printf("WARN: read-only mode is on; writes are refused with 503")
+	}
+	jobs.Start(ctx, jobs.Job{Name: "refresh-read-only", Interval: cfg.ReadOnlyRefresh, Run: svc.Refresh})
+	return svc
+}
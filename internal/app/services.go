@@ -0,0 +1,461 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-api-nosql/internal/application/activity"
+	"github.com/go-api-nosql/internal/application/apikey"
+	"github.com/go-api-nosql/internal/application/approval"
+	"github.com/go-api-nosql/internal/application/appversion"
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/auth"
+	"github.com/go-api-nosql/internal/application/backup"
+	"github.com/go-api-nosql/internal/application/devconsole"
+	"github.com/go-api-nosql/internal/application/device"
+	fileapp "github.com/go-api-nosql/internal/application/file"
+	"github.com/go-api-nosql/internal/application/message"
+	"github.com/go-api-nosql/internal/application/notification"
+	"github.com/go-api-nosql/internal/application/overview"
+	"github.com/go-api-nosql/internal/application/plan"
+	"github.com/go-api-nosql/internal/application/retention"
+	"github.com/go-api-nosql/internal/application/role"
+	"github.com/go-api-nosql/internal/application/search"
+	"github.com/go-api-nosql/internal/application/session"
+	"github.com/go-api-nosql/internal/application/status"
+	"github.com/go-api-nosql/internal/application/template"
+	"github.com/go-api-nosql/internal/application/usage"
+	"github.com/go-api-nosql/internal/application/user"
+	"github.com/go-api-nosql/internal/config"
+	googleinfra "github.com/go-api-nosql/internal/infrastructure/google"
+	"github.com/go-api-nosql/internal/infrastructure/hibp"
+	"github.com/go-api-nosql/internal/pkg/jobs"
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
+	"github.com/go-api-nosql/internal/pkg/password"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+// googleVerifierAdapter adapts *googleinfra.Verifier to session.googleVerifier.
+type googleVerifierAdapter struct{ v *googleinfra.Verifier }
+
+func (a *googleVerifierAdapter) Verify(ctx context.Context, token string) (*session.GooglePayload, error) {
+	p, err := a.v.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GooglePayload{
+		Sub:           p.Sub,
+		Email:         p.Email,
+		EmailVerified: p.EmailVerified,
+		FirstName:     p.FirstName,
+		LastName:      p.LastName,
+	}, nil
+}
+
+// NewServices builds every application service on top of deps, applies the
+// service overrides in deps.Extensions and starts the background jobs, which
+// run until ctx is cancelled.
+func NewServices(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps) (*transporthttp.Services, error) {
+	if deps.JWTProvider == nil {
+		return nil, errors.New("JWT provider is required but was not initialized; check RSA key files")
+	}
+	overrides := deps.Extensions.Services
+	svc := &transporthttp.Services{
+		Activity:   activity.NewService(deps.ActivityRepo, days(cfg.ActivityRetentionDays)),
+		Audit:      audit.NewService(deps.AuditRepo, auditRetention(cfg)),
+		Status:     status.NewService(deps.StatusRepo),
+		Role:       role.NewService(deps.RoleRepo),
+		Template:   template.NewService(deps.TemplateRepo),
+		AppVersion: appversion.NewService(deps.AppVersionRepo),
+	}
+	svc.Plan = newPlanService(deps, svc.Audit)
+	var err error
+	svc.Launch = newLaunchService(cfg, deps, svc.Audit)
+	svc.Passkey = newPasskeyService(cfg, deps, svc.Audit)
+	svc.OAuth = newOAuthService(cfg, deps, svc.Audit)
+	if cfg.Features.APIKeys {
+		svc.APIKey = apikey.NewService(deps.APIKeyRepo, deps.UserRepo, svc.Audit)
+	}
+	if svc.Session, err = newSessionService(cfg, deps, svc); err != nil {
+		return nil, err
+	}
+	svc.User = override(newUserService(cfg, deps, svc, newOnboardingHooks(ctx, cfg, deps, svc)), overrides.User)
+	svc.Device = override(device.NewService(deps.DeviceRepo, deps.AppVersionRepo, deps.SessionRepo, deps.PushSender),
+		overrides.Device)
+	svc.Notification = newNotificationService(ctx, cfg, deps, svc)
+	svc.Broadcast = newBroadcastService(ctx, cfg, deps, svc.Audit)
+	svc.Message = override(message.NewService(deps.MessageRepo, deps.UserRepo, svc.Notification), overrides.Message)
+	if cfg.Features.Files {
+		svc.File = override(fileapp.NewService(deps.S3Store, deps.FileRepo, svc.Activity, svc.Plan), overrides.File)
+	}
+	svc.Overview = overview.NewService(overview.ServiceDeps{
+		UserRepo:         deps.UserRepo,
+		DeviceRepo:       deps.DeviceRepo,
+		SessionRepo:      deps.SessionRepo,
+		FileRepo:         deps.FileRepo,
+		NotificationRepo: deps.NotificationRepo,
+		ActivityRepo:     deps.ActivityRepo,
+	})
+	authSvc, err := newAuthService(ctx, cfg, deps)
+	if err != nil {
+		return nil, err
+	}
+	svc.Auth = override(authSvc, overrides.Auth)
+	svc.Search = newSearchService(ctx, cfg, deps)
+	svc.DevConsole = newDevConsoleService(cfg, deps, svc.User)
+	if cfg.ApprovalsRequired {
+		svc.Approval = approval.NewService(approval.ServiceDeps{
+			ApprovalRepo: deps.ApprovalRepo,
+			Users:        svc.User,
+			Audit:        svc.Audit,
+			Window:       cfg.ApprovalWindow,
+		})
+	}
+	svc.Retention = newRetentionService(ctx, cfg, deps)
+	svc.Backup = newBackupService(ctx, cfg, deps)
+	if svc.Usage, err = newUsageService(cfg, deps, svc.Plan); err != nil {
+		return nil, err
+	}
+	if svc.Billing, err = newBillingService(cfg, deps, svc.Plan); err != nil {
+		return nil, err
+	}
+	svc.ReadOnly = newReadOnlyService(ctx, cfg, deps, svc.Audit)
+	seed(ctx, cfg, svc)
+	return svc, nil
+}
+
+// seed creates the bootstrap admin, in ADMIN_TENANT when TENANT_MODE is set,
+// and the built-in roles and plans. Failures are logged so a transient DynamoDB error
+// does not stop the process from starting.
+func seed(ctx context.Context, cfg *config.Config, svc *transporthttp.Services) {
+	adminCtx := ctx
+	if cfg.TenantMode != "" {
+		adminCtx = tenant.WithID(ctx, cfg.AdminTenant)
+	}
+	if err := svc.User.EnsureAdmin(adminCtx, cfg.AdminEmail, cfg.AdminPassword); err != nil {
+		log.Printf("WARN: could not bootstrap admin account: %v", err)
+	}
+	if err := svc.Role.EnsureBuiltins(ctx); err != nil {
+		log.Printf("WARN: could not seed built-in roles: %v", err)
+	}
+	if err := svc.Plan.EnsureBuiltins(ctx); err != nil {
+		log.Printf("WARN: could not seed built-in plans: %v", err)
+	}
+}
+
+func newSessionService(cfg *config.Config, deps *transporthttp.Deps, svc *transporthttp.Services) (session.Service, error) {
+	sessionDeps := session.ServiceDeps{
+		SessionRepo:     deps.SessionRepo,
+		UserRepo:        deps.UserRepo,
+		DeviceRepo:      deps.DeviceRepo,
+		JWTProvider:     deps.JWTProvider,
+		RefreshTokenDur: days(cfg.RefreshTokenExpiryDays),
+		UntrustedDur:    days(cfg.UntrustedRefreshDays),
+		RememberDur:     days(cfg.RememberMeDays),
+		MaxSessions:     cfg.MaxSessions,
+		StrictLimit:     cfg.SessionLimitStrict,
+		Activity:        svc.Activity,
+		Audit:           svc.Audit,
+		PreLogin:        deps.PreLoginHooks,
+		PostLogin:       deps.PostLoginHooks,
+		SignupGate:      svc.Launch,
+		ClosedSignup:    !cfg.Features.SelfRegistration,
+		PasswordPolicy:  passwordPolicy(cfg),
+
+		HighRiskCountries: cfg.HighRiskCountries,
+		ApprovalTTL:       cfg.LoginApprovalTTL,
+		ApprovalURL:       cfg.LoginApprovalURL,
+		Mailer:            deps.Mailer,
+	}
+	if cfg.Features.GoogleAuth {
+		if cfg.GoogleClientID == "" {
+			return nil, errors.New("GOOGLE_CLIENT_ID is required but not set; add it to your environment or set FEATURE_GOOGLE_AUTH=false")
+		}
+		sessionDeps.GoogleVerifier = &googleVerifierAdapter{v: googleinfra.NewVerifier(cfg.GoogleClientID)}
+	}
+	if svc.Passkey != nil {
+		sessionDeps.Passkeys = svc.Passkey
+	}
+	return override(session.NewService(sessionDeps), deps.Extensions.Services.Session), nil
+}
+
+func newUserService(cfg *config.Config, deps *transporthttp.Deps, svc *transporthttp.Services, postRegister []user.PostRegisterHook) user.Service {
+	return user.NewService(user.ServiceDeps{
+		UserRepo:        deps.UserRepo,
+		SessionRepo:     deps.SessionRepo,
+		DeviceRepo:      deps.DeviceRepo,
+		JWTProvider:     deps.JWTProvider,
+		RefreshTokenDur: days(cfg.RefreshTokenExpiryDays),
+		UntrustedDur:    days(cfg.UntrustedRefreshDays),
+		Activity:        svc.Activity,
+		RoleRepo:        deps.RoleRepo,
+		Audit:           svc.Audit,
+		AntiEnumeration: cfg.AntiEnumeration,
+		PreRegister:     append([]user.PreRegisterHook{svc.Launch}, deps.PreRegisterHooks...),
+		PostRegister:    append([]user.PostRegisterHook{svc.Launch}, postRegister...),
+		Mailer:          deps.Mailer,
+		// Users may only set the allowlisted metadata keys on themselves.
+		SelfMetadataKeys: cfg.UserMetadataSelfKeys,
+		MinimumAge:       cfg.MinimumAge,
+		PasswordPolicy:   passwordPolicy(cfg),
+		// Password and email changes need a device verified by OTP.
+		TrustedDeviceRequired: cfg.TrustedDeviceRequired,
+	})
+}
+
+// passwordPolicy is the strength and breach policy for new passwords.
+func passwordPolicy(cfg *config.Config) password.Policy {
+	return password.Policy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+		BanCommon:     cfg.PasswordBanCommon,
+		Breaches:      passwordBreaches(cfg),
+	}
+}
+
+// passwordBreaches is the Have I Been Pwned check for new passwords, or nil
+// when PASSWORD_BREACH_CHECK is off.
+func passwordBreaches(cfg *config.Config) password.Breaches {
+	if !cfg.PasswordBreachCheck {
+		return nil
+	}
+	return hibp.NewClient(cfg.HIBPURL)
+}
+
+func newAuthService(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps) (auth.Service, error) {
+	secret, err := verificationCodeSecret(cfg)
+	if err != nil {
+		return nil, err
+	}
+	codes := codePolicies(cfg)
+	if err := codes.Validate(); err != nil {
+		return nil, err
+	}
+	return auth.NewService(auth.ServiceDeps{
+		VerificationRepo: deps.VerificationRepo,
+		UserRepo:         deps.UserRepo,
+		SessionRepo:      deps.SessionRepo,
+		DeviceRepo:       deps.DeviceRepo,
+		Mailer:           deps.Mailer,
+		SMSSender:        deps.SMSSender,
+		JWTProvider:      deps.JWTProvider,
+		RefreshTokenDur:  days(cfg.RefreshTokenExpiryDays),
+		UntrustedDur:     days(cfg.UntrustedRefreshDays),
+		Attempts:         deps.RateLimitRepo,
+		AntiEnumeration:  cfg.AntiEnumeration,
+		CodeSecret:       secret,
+		Codes:            codes,
+		ResendCooldown:   cfg.CodeResendCooldown,
+		PasswordPolicy:   passwordPolicy(cfg),
+		Background:       ctx,
+	}), nil
+}
+
+func codePolicies(cfg *config.Config) auth.CodePolicies {
+	return auth.CodePolicies{
+		Recovery: auth.CodePolicy{
+			Length: cfg.RecoveryCodeLength, Charset: auth.Charset(cfg.RecoveryCodeCharset), TTL: cfg.RecoveryCodeTTL,
+		},
+		Email: auth.CodePolicy{
+			Length: cfg.EmailCodeLength, Charset: auth.Charset(cfg.EmailCodeCharset), TTL: cfg.EmailCodeTTL,
+		},
+		Phone: auth.CodePolicy{
+			Length: cfg.PhoneCodeLength, Charset: auth.Charset(cfg.PhoneCodeCharset), TTL: cfg.PhoneCodeTTL,
+		},
+	}
+}
+
+// verificationCodeSecret returns VERIFICATION_CODE_SECRET, the HMAC key codes
+// are hashed with. Outside production a missing secret is replaced by a random
+// one, so codes only verify on the replica that issued them until it restarts.
+func verificationCodeSecret(cfg *config.Config) ([]byte, error) {
+	if cfg.VerificationCodeSecret != "" {
+		return []byte(cfg.VerificationCodeSecret), nil
+	}
+	if cfg.AppEnv == "production" {
+		return nil, errors.New("VERIFICATION_CODE_SECRET is required in production")
+	}
+	log.Printf("WARN: VERIFICATION_CODE_SECRET not set; verification codes only verify on this instance until restart")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("verification code secret: %w", err)
+	}
+	return secret, nil
+}
+
+// newNotificationService builds the notification service and starts scheduled
+// delivery, or returns nil when notifications are disabled. The message service
+// treats a nil notifier as "no new-message alerts".
+func newNotificationService(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps, svc *transporthttp.Services) notification.Service {
+	if !cfg.Features.Notifications {
+		return nil
+	}
+	notifSvc := override(notification.NewService(notification.ServiceDeps{
+		Repo:      deps.NotificationRepo,
+		Push:      svc.Device,
+		Templates: svc.Template,
+		Users:     deps.UserRepo,
+		Retention: days(cfg.NotificationRetentionDays),
+	}), deps.Extensions.Services.Notification)
+	jobs.Start(ctx, jobs.Job{
+		Name:     "deliver-scheduled-notifications",
+		Interval: cfg.SchedulerInterval,
+		Run: func(ctx context.Context) error {
+			_, err := notifSvc.DeliverDue(ctx)
+			return err
+		},
+	})
+	startBirthdays(ctx, cfg, deps, notifSvc)
+	return notifSvc
+}
+
+// newSearchService starts the job that keeps the search projection in sync,
+// or returns nil when no search backend is configured.
+func newSearchService(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps) search.Service {
+	if deps.SearchIndex == nil {
+		log.Printf("WARN: OPENSEARCH_URL not set; /v1/search is disabled")
+		return nil
+	}
+	if cfg.TenantMode != "" {
+		// The index is fed from the table streams and holds every tenant.
+		log.Printf("WARN: /v1/search is disabled because TENANT_MODE is set")
+		return nil
+	}
+	svc := search.NewService(deps.SearchIndex, deps.UserStream, deps.FileStream)
+	jobs.Start(ctx, jobs.Job{Name: "sync-search-index", Interval: cfg.SearchSyncInterval, Run: svc.Sync})
+	return svc
+}
+
+// newRetentionService builds the retention rules and, when RETENTION_ENFORCE
+// is on, starts the job that purges what they expire. Sweeps are idempotent,
+// so every replica may run the job.
+func newRetentionService(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps) retention.Service {
+	rules := []retention.Rule{
+		{Name: "audit_logs", Days: cfg.AuditRetentionDays, Sweep: deps.AuditRepo.SweepBefore},
+		{Name: "anonymize_deleted_users", Days: cfg.DeletionGraceDays, Sweep: deps.UserRepo.AnonymizeBefore},
+		{Name: "deleted_users", Days: cfg.DeletedUserRetentionDays, Sweep: deps.UserRepo.SweepBefore},
+	}
+	if cfg.Features.Notifications {
+		rules = append(rules, retention.Rule{
+			Name: "dismissed_notifications", Days: cfg.NotificationRetentionDays, Sweep: deps.NotificationRepo.SweepBefore,
+		})
+	}
+	svc := retention.NewService(rules, cfg.RetentionEnforce)
+	if !cfg.RetentionEnforce {
+		return svc
+	}
+	jobs.Start(ctx, jobs.Job{
+		Name:     "purge-expired-data",
+		Interval: cfg.RetentionInterval,
+		Run: func(ctx context.Context) error {
+			report, err := svc.Purge(ctx)
+			if err != nil {
+				return err
+			}
+			for _, rule := range report.Rules {
+				if rule.Expired > 0 {
+					log.Printf("retention: purged %d from %s", rule.Expired, rule.Name)
+				}
+			}
+			return nil
+		},
+	})
+	return svc
+}
+
+// newBackupService checks table backups once at startup, in the background so
+// a slow DescribeContinuousBackups does not delay readiness, and then every
+// BACKUP_CHECK_INTERVAL. It returns nil when deps has no backup status reader.
+func newBackupService(ctx context.Context, cfg *config.Config, deps *transporthttp.Deps) backup.Service {
+	if deps.BackupStatus == nil {
+		return nil
+	}
+	svc := backup.NewService(deps.BackupStatus, cfg.DynamoTables.Names())
+	check := func(ctx context.Context) error {
+		if report := svc.Check(ctx); !report.Healthy {
+			for _, t := range report.Tables {
+				if t.Problem != "" {
+					log.Printf("WARN: backups: %s: %s", t.Table, t.Problem)
+				}
+			}
+		}
+		return nil
+	}
+	lifecycle.Go(ctx, "initial backup check", func(ctx context.Context) { _ = check(ctx) })
+	jobs.Start(ctx, jobs.Job{Name: "verify-backups", Interval: cfg.BackupCheckInterval, Run: check})
+	return svc
+}
+
+// auditRetention is the TTL written on new audit entries: none until
+// retention is enforced, so turning enforcement on stays a deliberate step.
+func auditRetention(cfg *config.Config) time.Duration {
+	if !cfg.RetentionEnforce {
+		return 0
+	}
+	return days(cfg.AuditRetentionDays)
+}
+
+// newDevConsoleService returns nil unless DEV_CONSOLE is on in development mode.
+func newDevConsoleService(cfg *config.Config, deps *transporthttp.Deps, users user.Service) devconsole.Service {
+	if !cfg.DevConsoleEnabled() {
+		return nil
+	}
+	return devconsole.NewService(devconsole.ServiceDeps{
+		Users:           users,
+		UserRepo:        deps.UserRepo,
+		SessionRepo:     deps.SessionRepo,
+		DeviceRepo:      deps.DeviceRepo,
+		JWTProvider:     deps.JWTProvider,
+		RefreshTokenDur: days(cfg.RefreshTokenExpiryDays),
+	})
+}
+
+// override applies fn to svc when a decorator is set.
+func override[T any](svc T, fn func(T) T) T {
+	if fn == nil {
+		return svc
+	}
+	return fn(svc)
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+// defaultUsageQuotas are the daily quotas used without USAGE_QUOTAS_FILE.
+//
+//go:embed usage_quotas.json
+var defaultUsageQuotas []byte
+
+// newUsageService meters requests against the USAGE_QUOTAS_FILE policy, or
+// returns nil when usage metering is off.
+func newUsageService(cfg *config.Config, deps *transporthttp.Deps, plans plan.Service) (usage.Service, error) {
+	if !cfg.Features.UsageMetering {
+		return nil, nil
+	}
+	if deps.UsageRepo == nil {
+		return nil, errors.New("FEATURE_USAGE_METERING requires a usage repository")
+	}
+	var (
+		policy *usage.Policy
+		err    error
+	)
+	if cfg.UsageQuotasFile != "" {
+		policy, err = usage.LoadPolicy(cfg.UsageQuotasFile)
+	} else {
+		policy, err = usage.ParsePolicy(defaultUsageQuotas)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return usage.NewService(deps.UsageRepo, policy, plans, days(cfg.UsageRetentionDays)), nil
+}
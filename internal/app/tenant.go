@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/infrastructure/dynamo"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+)
+
+// tenantAPIOptions validates the TENANT_MODE settings and returns the
+// DynamoDB client options that keep each request inside its tenant, or none
+// when the API is single-tenant. Rate-limit and usage counters, keyed by IP or
+// globally unique user ID, the catalog tables
+// managed by admins (roles, plans, statuses, app versions and notification
+// templates), the runtime settings and the launch allowlist are shared by
+// every tenant.
+func tenantAPIOptions(cfg *config.Config) ([]func(*middleware.Stack) error, error) {
+	switch cfg.TenantMode {
+	case "":
+		return nil, nil
+	case tenant.ModeJWT:
+	case tenant.ModeSubdomain:
+		if cfg.TenantBaseDomain == "" {
+			return nil, errors.New("TENANT_MODE=subdomain requires TENANT_BASE_DOMAIN")
+		}
+	default:
+		return nil, fmt.Errorf("TENANT_MODE must be jwt or subdomain, got %q", cfg.TenantMode)
+	}
+	if cfg.AdminEmail != "" && !tenant.Valid(cfg.AdminTenant) {
+		return nil, fmt.Errorf("ADMIN_TENANT must name the bootstrap admin's tenant, got %q", cfg.AdminTenant)
+	}
+	t := cfg.DynamoTables
+	return dynamo.TenantAPIOptions([]string{t.RateLimits, t.Usage, t.Roles, t.Plans, t.Statuses, t.AppVersions, t.Templates, t.Settings, t.LaunchAllowlist}), nil
+}
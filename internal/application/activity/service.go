@@ -0,0 +1,52 @@
+package activity
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+type Service interface {
+	// Record appends an entry to userID's feed. It is best effort: failures are
+	// logged, never returned, so callers don't fail user actions over the feed.
+	Record(ctx context.Context, userID, kind, subject string)
+	List(ctx context.Context, userID string, limit int, cursor string) ([]domain.Activity, string, error)
+}
+
+type activityStore interface {
+	Put(ctx context.Context, a *domain.Activity) error
+	ListByUser(ctx context.Context, userID string, limit int32, cursor string) ([]domain.Activity, string, error)
+}
+
+type service struct {
+	repo      activityStore
+	retention time.Duration
+}
+
+// NewService builds the activity service. Entries expire after retention via
+// DynamoDB TTL; a zero retention keeps them forever.
+func NewService(repo activityStore, retention time.Duration) Service {
+	return &service{repo: repo, retention: retention}
+}
+
+func (s *service) Record(ctx context.Context, userID, kind, subject string) {
+	now := time.Now().UTC()
+	a := &domain.Activity{
+		UserID:    userID,
+		CreatedAt: now.Format(domain.ActivityTimeLayout),
+		Kind:      kind,
+		Subject:   subject,
+	}
+	if s.retention > 0 {
+		a.ExpiresAt = now.Add(s.retention).Unix()
+	}
+	if err := s.repo.Put(ctx, a); err != nil {
+		slog.Warn("failed to record activity", "user_id", userID, "kind", kind, "err", err)
+	}
+}
+
+func (s *service) List(ctx context.Context, userID string, limit int, cursor string) ([]domain.Activity, string, error) {
+	return s.repo.ListByUser(ctx, userID, int32(limit), cursor)
+}
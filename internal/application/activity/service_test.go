@@ -0,0 +1,87 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockActivityStore struct{ mock.Mock }
+
+func (m *mockActivityStore) Put(ctx context.Context, a *domain.Activity) error {
+	return m.Called(ctx, a).Error(0)
+}
+
+func (m *mockActivityStore) ListByUser(ctx context.Context, userID string, limit int32, cursor string) ([]domain.Activity, string, error) {
+	args := m.Called(ctx, userID, limit, cursor)
+	as, _ := args.Get(0).([]domain.Activity)
+	return as, args.String(1), args.Error(2)
+}
+
+// --- Record tests ---
+
+func TestRecord_SetsExpiryFromRetention(t *testing.T) {
+	repo := &mockActivityStore{}
+	var saved *domain.Activity
+	repo.On("Put", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*domain.Activity)
+	}).Return(nil)
+
+	NewService(repo, 24*time.Hour).Record(context.Background(), "u1", domain.ActivityFileUpload, "f1")
+
+	require.NotNil(t, saved)
+	assert.Equal(t, "u1", saved.UserID)
+	assert.Equal(t, domain.ActivityFileUpload, saved.Kind)
+	assert.Equal(t, "f1", saved.Subject)
+	created, err := time.Parse(domain.ActivityTimeLayout, saved.CreatedAt)
+	require.NoError(t, err)
+	assert.Equal(t, created.Add(24*time.Hour).Unix(), saved.ExpiresAt)
+}
+
+func TestRecord_ZeroRetentionNeverExpires(t *testing.T) {
+	repo := &mockActivityStore{}
+	repo.On("Put", mock.Anything, mock.MatchedBy(func(a *domain.Activity) bool {
+		return a.ExpiresAt == 0
+	})).Return(nil)
+
+	NewService(repo, 0).Record(context.Background(), "u1", domain.ActivityLogin, "d1")
+
+	repo.AssertExpectations(t)
+}
+
+func TestRecord_StoreErrorIsSwallowed(t *testing.T) {
+	repo := &mockActivityStore{}
+	repo.On("Put", mock.Anything, mock.Anything).Return(errors.New("dynamo down"))
+
+	assert.NotPanics(t, func() {
+		NewService(repo, time.Hour).Record(context.Background(), "u1", domain.ActivityLogin, "d1")
+	})
+}
+
+func TestActivityTimeLayout_SortsChronologically(t *testing.T) {
+	earlier := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Format(domain.ActivityTimeLayout)
+	later := time.Date(2026, 1, 2, 3, 4, 5, 100, time.UTC).Format(domain.ActivityTimeLayout)
+	assert.Less(t, earlier, later)
+}
+
+// --- List tests ---
+
+func TestList_PassesThroughPagination(t *testing.T) {
+	repo := &mockActivityStore{}
+	feed := []domain.Activity{{UserID: "u1", Kind: domain.ActivityLogin}}
+	repo.On("ListByUser", mock.Anything, "u1", int32(20), "cur").Return(feed, "next", nil)
+
+	got, next, err := NewService(repo, time.Hour).List(context.Background(), "u1", 20, "cur")
+
+	require.NoError(t, err)
+	assert.Equal(t, feed, got)
+	assert.Equal(t, "next", next)
+}
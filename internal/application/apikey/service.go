@@ -0,0 +1,164 @@
+// Package apikey mints and verifies API keys, which let internal services
+// call the API as a service account without a user's JWT.
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
+)
+
+var errInvalidKey = fmt.Errorf("invalid or expired API key: %w", domain.ErrUnauthorized)
+
+// Service manages API keys.
+type Service interface {
+	// Create mints a key acting as req.UserID. Scopes must be OAuth scopes
+	// or domain.ScopeAdmin, and held by the user's role.
+	Create(ctx context.Context, actorID string, req domain.CreateAPIKeyRequest) (*domain.APIKeyCreated, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	// Revoke deletes a key; requests carrying it fail at once.
+	Revoke(ctx context.Context, actorID, keyID string) error
+	// Verify checks key and returns who it acts as. Unknown, expired and
+	// malformed keys, and keys of disabled or deleted users, are
+	// domain.ErrUnauthorized.
+	Verify(ctx context.Context, key string) (*domain.APIKeyPrincipal, error)
+}
+
+type keyStore interface {
+	Put(ctx context.Context, k *domain.APIKey) error
+	Get(ctx context.Context, keyID string) (*domain.APIKey, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	Delete(ctx context.Context, keyID string) error
+}
+
+type userGetter interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type service struct {
+	keys  keyStore
+	users userGetter
+	audit auditRecorder
+	now   func() time.Time
+}
+
+func NewService(keys keyStore, users userGetter, audit auditRecorder) Service {
+	return &service{keys: keys, users: users, audit: audit, now: time.Now}
+}
+
+func (s *service) Create(ctx context.Context, actorID string, req domain.CreateAPIKeyRequest) (*domain.APIKeyCreated, error) {
+	u, err := s.users.Get(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	held := domain.RoleScopes(u.Role)
+	for _, scope := range req.Scopes {
+		if !slices.Contains(held, scope) {
+			return nil, fmt.Errorf("scope %q is unknown or not held by the user's role: %w", scope, domain.ErrBadRequest)
+		}
+	}
+	now := s.now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("expires_at is in the past: %w", domain.ErrBadRequest)
+	}
+	secret, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	k := &domain.APIKey{
+		KeyID:      id.New(),
+		Name:       req.Name,
+		SecretHash: hashSecret(secret),
+		UserID:     req.UserID,
+		Scopes:     req.Scopes,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedBy:  actorID,
+		CreatedAt:  now,
+	}
+	if err := s.keys.Put(ctx, k); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditAPIKeyCreate,
+		ActorID:  actorID,
+		TargetID: k.KeyID,
+		Details:  map[string]string{"name": k.Name, "user_id": k.UserID},
+	})
+	return &domain.APIKeyCreated{APIKey: k, Key: k.KeyID + "." + secret}, nil
+}
+
+func (s *service) List(ctx context.Context) ([]domain.APIKey, error) {
+	keys, err := s.keys.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys, nil
+}
+
+func (s *service) Revoke(ctx context.Context, actorID, keyID string) error {
+	if err := s.keys.Delete(ctx, keyID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditAPIKeyRevoke,
+		ActorID:  actorID,
+		TargetID: keyID,
+	})
+	return nil
+}
+
+func (s *service) Verify(ctx context.Context, key string) (*domain.APIKeyPrincipal, error) {
+	keyID, secret, ok := strings.Cut(key, ".")
+	if !ok || keyID == "" || secret == "" {
+		return nil, errInvalidKey
+	}
+	k, err := s.keys.Get(ctx, keyID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, errInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(k.SecretHash), []byte(hashSecret(secret))) != 1 ||
+		(k.ExpiresAt != nil && !s.now().Before(*k.ExpiresAt)) {
+		return nil, errInvalidKey
+	}
+	u, err := s.users.Get(ctx, k.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, errInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.Enable != 1 || u.DeletedAt != nil {
+		return nil, errInvalidKey
+	}
+	return &domain.APIKeyPrincipal{
+		KeyID:    k.KeyID,
+		UserID:   u.UserID,
+		Role:     u.Role,
+		Scopes:   domain.FilterScopes(k.Scopes, u.Role),
+		TenantID: k.TenantID,
+	}, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
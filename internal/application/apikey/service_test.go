@@ -0,0 +1,146 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubKeys struct {
+	items map[string]*domain.APIKey
+}
+
+func (s *stubKeys) Put(_ context.Context, k *domain.APIKey) error {
+	s.items[k.KeyID] = k
+	return nil
+}
+
+func (s *stubKeys) Get(_ context.Context, keyID string) (*domain.APIKey, error) {
+	k, ok := s.items[keyID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return k, nil
+}
+
+func (s *stubKeys) List(context.Context) ([]domain.APIKey, error) {
+	var out []domain.APIKey
+	for _, k := range s.items {
+		out = append(out, *k)
+	}
+	return out, nil
+}
+
+func (s *stubKeys) Delete(_ context.Context, keyID string) error {
+	if _, ok := s.items[keyID]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(s.items, keyID)
+	return nil
+}
+
+type stubUsers struct {
+	users map[string]*domain.User
+}
+
+func (s *stubUsers) Get(_ context.Context, userID string) (*domain.User, error) {
+	u, ok := s.users[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return u, nil
+}
+
+type stubAudit struct{ actions []string }
+
+func (a *stubAudit) Record(_ context.Context, e domain.AuditEntry) {
+	a.actions = append(a.actions, e.Action)
+}
+
+func newTestService() (*service, *stubUsers, *stubAudit) {
+	users := &stubUsers{users: map[string]*domain.User{
+		"svc":   {UserID: "svc", Role: domain.RoleAdmin, Enable: 1},
+		"plain": {UserID: "plain", Role: domain.RoleUser, Enable: 1},
+	}}
+	audit := &stubAudit{}
+	s := NewService(&stubKeys{items: map[string]*domain.APIKey{}}, users, audit).(*service)
+	return s, users, audit
+}
+
+func TestCreateAndVerify(t *testing.T) {
+	s, _, audit := newTestService()
+	ctx := context.Background()
+
+	created, err := s.Create(ctx, "admin", domain.CreateAPIKeyRequest{Name: "billing", UserID: "svc", Scopes: []string{domain.ScopeAdmin}})
+	require.NoError(t, err)
+	assert.Contains(t, created.Key, created.KeyID+".")
+
+	p, err := s.Verify(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.APIKeyPrincipal{KeyID: created.KeyID, UserID: "svc", Role: domain.RoleAdmin, Scopes: []string{domain.ScopeAdmin}}, p)
+	assert.Equal(t, []string{domain.AuditAPIKeyCreate}, audit.actions)
+}
+
+func TestCreate_RejectsScopesBeyondTheRole(t *testing.T) {
+	s, _, _ := newTestService()
+	ctx := context.Background()
+
+	_, err := s.Create(ctx, "admin", domain.CreateAPIKeyRequest{Name: "k", UserID: "plain", Scopes: []string{domain.ScopeAdmin}})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	_, err = s.Create(ctx, "admin", domain.CreateAPIKeyRequest{Name: "k", UserID: "svc", Scopes: []string{"everything"}})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	_, err = s.Create(ctx, "admin", domain.CreateAPIKeyRequest{Name: "k", UserID: "nobody", Scopes: []string{domain.ScopeAdmin}})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestVerify_RejectsBadKeys(t *testing.T) {
+	s, users, _ := newTestService()
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+	created, err := s.Create(ctx, "admin", domain.CreateAPIKeyRequest{Name: "k", UserID: "svc", Scopes: []string{domain.ScopeAdmin}, ExpiresAt: &expires})
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "garbage", created.KeyID + ".wrong", "unknown." + created.Key} {
+		_, err := s.Verify(ctx, key)
+		assert.ErrorIs(t, err, domain.ErrUnauthorized, key)
+	}
+
+	s.now = func() time.Time { return expires }
+	_, err = s.Verify(ctx, created.Key)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized, "expired")
+
+	s.now = time.Now
+	users.users["svc"].Enable = 0
+	_, err = s.Verify(ctx, created.Key)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized, "disabled user")
+}
+
+func TestVerify_FollowsTheUsersCurrentRole(t *testing.T) {
+	s, users, _ := newTestService()
+	ctx := context.Background()
+	created, err := s.Create(ctx, "admin", domain.CreateAPIKeyRequest{Name: "k", UserID: "svc", Scopes: []string{domain.ScopeAdmin, "profile:read"}})
+	require.NoError(t, err)
+
+	users.users["svc"].Role = domain.RoleUser
+	p, err := s.Verify(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleUser, p.Role)
+	assert.Equal(t, []string{"profile:read"}, p.Scopes)
+}
+
+func TestRevoke(t *testing.T) {
+	s, _, audit := newTestService()
+	ctx := context.Background()
+	created, err := s.Create(ctx, "admin", domain.CreateAPIKeyRequest{Name: "k", UserID: "svc", Scopes: []string{domain.ScopeAdmin}})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Revoke(ctx, "admin", created.KeyID))
+	_, err = s.Verify(ctx, created.Key)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	assert.ErrorIs(t, s.Revoke(ctx, "admin", created.KeyID), domain.ErrNotFound)
+	assert.Equal(t, []string{domain.AuditAPIKeyCreate, domain.AuditAPIKeyRevoke}, audit.actions)
+}
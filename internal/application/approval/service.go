@@ -0,0 +1,235 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+)
+
+// Service holds destructive admin actions until a second admin approves them.
+type Service interface {
+	// Request records a as pending on behalf of requesterID. Only the action
+	// fields of a (Action, TargetID, ChangeRole, Bulk) are used.
+	Request(ctx context.Context, requesterID string, a domain.Approval) (*domain.Approval, error)
+	// List returns the approvals in status (every approval when empty),
+	// newest first. Pending approvals past their deadline are listed as expired.
+	List(ctx context.Context, status string) ([]domain.Approval, error)
+	// Approve executes a pending approval on behalf of its requester. The
+	// approver must be a different admin; a failed action is recorded on the
+	// approval rather than returned.
+	Approve(ctx context.Context, approverID, approvalID string) (*domain.Approval, error)
+	// Reject closes a pending approval without executing it. Requesters may
+	// reject their own approvals to withdraw them.
+	Reject(ctx context.Context, deciderID, approvalID, reason string) (*domain.Approval, error)
+}
+
+type approvalStore interface {
+	Put(ctx context.Context, a *domain.Approval) error
+	Get(ctx context.Context, approvalID string) (*domain.Approval, error)
+	List(ctx context.Context, status string) ([]domain.Approval, error)
+	Decide(ctx context.Context, approvalID string, updates map[string]interface{}) error
+	Update(ctx context.Context, approvalID string, updates map[string]interface{}) error
+}
+
+// userActions runs the actions held for approval; user.Service satisfies it.
+type userActions interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+	Delete(ctx context.Context, userID string) error
+	ChangeRole(ctx context.Context, actorID, targetID string, req domain.ChangeRoleRequest) (*domain.User, error)
+	Bulk(ctx context.Context, actorID string, req domain.BulkUserRequest) ([]domain.BulkUserResult, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type service struct {
+	repo   approvalStore
+	users  userActions
+	audit  auditRecorder
+	window time.Duration
+	now    func() time.Time
+}
+
+type ServiceDeps struct {
+	ApprovalRepo approvalStore
+	Users        userActions
+	Audit        auditRecorder
+	// Window is how long a request waits for a second admin before it expires.
+	Window time.Duration
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{
+		repo:   deps.ApprovalRepo,
+		users:  deps.Users,
+		audit:  deps.Audit,
+		window: deps.Window,
+		now:    time.Now,
+	}
+}
+
+func (s *service) Request(ctx context.Context, requesterID string, a domain.Approval) (*domain.Approval, error) {
+	if err := s.checkAction(ctx, a); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	out := &domain.Approval{
+		ApprovalID:  id.New(),
+		Action:      a.Action,
+		TargetID:    a.TargetID,
+		ChangeRole:  a.ChangeRole,
+		Bulk:        a.Bulk,
+		Status:      domain.ApprovalPending,
+		RequestedBy: requesterID,
+		RequestedAt: now,
+		ExpiresAt:   now.Add(s.window),
+	}
+	if err := s.repo.Put(ctx, out); err != nil {
+		return nil, err
+	}
+	s.record(ctx, domain.AuditApprovalRequest, requesterID, out)
+	return out, nil
+}
+
+// checkAction rejects actions the service cannot execute later, and targets
+// that do not exist.
+func (s *service) checkAction(ctx context.Context, a domain.Approval) error {
+	switch {
+	case a.Action == domain.ApprovalUserDelete && a.TargetID != "":
+	case a.Action == domain.ApprovalGrantAdmin && a.TargetID != "" && a.ChangeRole != nil:
+	case a.Action == domain.ApprovalBulk && a.Bulk != nil:
+		return nil
+	default:
+		return fmt.Errorf("invalid approval action %q: %w", a.Action, domain.ErrBadRequest)
+	}
+	_, err := s.users.Get(ctx, a.TargetID)
+	return err
+}
+
+func (s *service) List(ctx context.Context, status string) ([]domain.Approval, error) {
+	stored := status
+	if status == domain.ApprovalExpired {
+		stored = domain.ApprovalPending
+	}
+	approvals, err := s.repo.List(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	out := approvals[:0]
+	for _, a := range approvals {
+		markExpired(&a, now)
+		if status == "" || a.Status == status {
+			out = append(out, a)
+		}
+	}
+	// Approval IDs are ULIDs, so they sort by creation time.
+	sort.Slice(out, func(i, j int) bool { return out[i].ApprovalID > out[j].ApprovalID })
+	return out, nil
+}
+
+func (s *service) Approve(ctx context.Context, approverID, approvalID string) (*domain.Approval, error) {
+	a, err := s.pending(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+	if a.RequestedBy == approverID {
+		return nil, fmt.Errorf("a second admin must approve this request: %w", domain.ErrForbidden)
+	}
+	if err := s.decide(ctx, a, approverID, domain.ApprovalApproved); err != nil {
+		return nil, err
+	}
+	s.record(ctx, domain.AuditApprovalApprove, approverID, a)
+	updates := map[string]interface{}{}
+	if a.Results, err = s.execute(ctx, a); err != nil {
+		a.Status, a.Error = domain.ApprovalFailed, err.Error()
+		updates["status"], updates["error"] = a.Status, a.Error
+	}
+	if a.Results != nil {
+		updates["results"] = a.Results
+	}
+	if len(updates) > 0 {
+		if err := s.repo.Update(ctx, a.ApprovalID, updates); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// execute runs the approved action as its requester, so the audit entries
+// written by the user service name who asked for it.
+func (s *service) execute(ctx context.Context, a *domain.Approval) ([]domain.BulkUserResult, error) {
+	switch a.Action {
+	case domain.ApprovalUserDelete:
+		return nil, s.users.Delete(ctx, a.TargetID)
+	case domain.ApprovalGrantAdmin:
+		_, err := s.users.ChangeRole(ctx, a.RequestedBy, a.TargetID, *a.ChangeRole)
+		return nil, err
+	case domain.ApprovalBulk:
+		return s.users.Bulk(ctx, a.RequestedBy, *a.Bulk)
+	}
+	return nil, fmt.Errorf("unknown approval action %q", a.Action)
+}
+
+func (s *service) Reject(ctx context.Context, deciderID, approvalID, reason string) (*domain.Approval, error) {
+	a, err := s.pending(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+	a.Reason = reason
+	if err := s.decide(ctx, a, deciderID, domain.ApprovalRejected); err != nil {
+		return nil, err
+	}
+	s.record(ctx, domain.AuditApprovalReject, deciderID, a)
+	return a, nil
+}
+
+// pending loads an approval that can still be decided.
+func (s *service) pending(ctx context.Context, approvalID string) (*domain.Approval, error) {
+	a, err := s.repo.Get(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+	markExpired(a, s.now())
+	if a.Status != domain.ApprovalPending {
+		return nil, fmt.Errorf("approval is %s: %w", a.Status, domain.ErrConflict)
+	}
+	return a, nil
+}
+
+// decide moves a from pending to status, failing with ErrConflict if another
+// admin decided it first.
+func (s *service) decide(ctx context.Context, a *domain.Approval, deciderID, status string) error {
+	now := s.now().UTC()
+	updates := map[string]interface{}{"status": status, "decided_by": deciderID, "decided_at": now}
+	if a.Reason != "" {
+		updates["reason"] = a.Reason
+	}
+	if err := s.repo.Decide(ctx, a.ApprovalID, updates); err != nil {
+		return err
+	}
+	a.Status, a.DecidedBy, a.DecidedAt = status, deciderID, &now
+	return nil
+}
+
+func (s *service) record(ctx context.Context, action, actorID string, a *domain.Approval) {
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   action,
+		ActorID:  actorID,
+		TargetID: a.TargetID,
+		Details:  map[string]string{"approval_id": a.ApprovalID, "action": a.Action, "requested_by": a.RequestedBy},
+	})
+}
+
+// markExpired reports a pending approval past its deadline as expired. The
+// stored item keeps status pending; expiry is derived on read.
+func markExpired(a *domain.Approval, now time.Time) {
+	if a.Status == domain.ApprovalPending && now.After(a.ExpiresAt) {
+		a.Status = domain.ApprovalExpired
+	}
+}
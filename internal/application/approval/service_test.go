@@ -0,0 +1,221 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- stubs ---
+
+// memStore keeps approvals in a map and applies Decide's pending condition.
+type memStore struct{ items map[string]domain.Approval }
+
+func (m *memStore) Put(_ context.Context, a *domain.Approval) error {
+	m.items[a.ApprovalID] = *a
+	return nil
+}
+
+func (m *memStore) Get(_ context.Context, approvalID string) (*domain.Approval, error) {
+	a, ok := m.items[approvalID]
+	if !ok {
+		return nil, fmt.Errorf("approval not found: %w", domain.ErrNotFound)
+	}
+	return &a, nil
+}
+
+func (m *memStore) List(_ context.Context, status string) ([]domain.Approval, error) {
+	var out []domain.Approval
+	for _, a := range m.items {
+		if status == "" || a.Status == status {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) Decide(ctx context.Context, approvalID string, updates map[string]interface{}) error {
+	if m.items[approvalID].Status != domain.ApprovalPending {
+		return fmt.Errorf("approval was already decided: %w", domain.ErrConflict)
+	}
+	return m.Update(ctx, approvalID, updates)
+}
+
+func (m *memStore) Update(_ context.Context, approvalID string, updates map[string]interface{}) error {
+	a := m.items[approvalID]
+	if v, ok := updates["status"].(string); ok {
+		a.Status = v
+	}
+	if v, ok := updates["error"].(string); ok {
+		a.Error = v
+	}
+	m.items[approvalID] = a
+	return nil
+}
+
+type stubUsers struct {
+	deleted  []string
+	bulkErr  error
+	bulkBy   string
+	roleSets []domain.ChangeRoleRequest
+}
+
+func (u *stubUsers) Get(_ context.Context, userID string) (*domain.User, error) {
+	if userID == "missing" {
+		return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
+	}
+	return &domain.User{UserID: userID}, nil
+}
+
+func (u *stubUsers) Delete(_ context.Context, userID string) error {
+	u.deleted = append(u.deleted, userID)
+	return nil
+}
+
+func (u *stubUsers) ChangeRole(_ context.Context, _, targetID string, req domain.ChangeRoleRequest) (*domain.User, error) {
+	u.roleSets = append(u.roleSets, req)
+	return &domain.User{UserID: targetID, Role: req.Role}, nil
+}
+
+func (u *stubUsers) Bulk(_ context.Context, actorID string, req domain.BulkUserRequest) ([]domain.BulkUserResult, error) {
+	u.bulkBy = actorID
+	if u.bulkErr != nil {
+		return nil, u.bulkErr
+	}
+	results := make([]domain.BulkUserResult, len(req.UserIDs))
+	for i, id := range req.UserIDs {
+		results[i] = domain.BulkUserResult{UserID: id, OK: true}
+	}
+	return results, nil
+}
+
+type stubAudit struct{ actions []string }
+
+func (a *stubAudit) Record(_ context.Context, e domain.AuditEntry) {
+	a.actions = append(a.actions, e.Action)
+}
+
+func newTestService() (*service, *memStore, *stubUsers, *stubAudit) {
+	store := &memStore{items: map[string]domain.Approval{}}
+	users := &stubUsers{}
+	audit := &stubAudit{}
+	svc := NewService(ServiceDeps{ApprovalRepo: store, Users: users, Audit: audit, Window: time.Hour}).(*service)
+	return svc, store, users, audit
+}
+
+var deleteBob = domain.Approval{Action: domain.ApprovalUserDelete, TargetID: "bob"}
+
+// --- tests ---
+
+func TestApprove_RequiresSecondAdmin(t *testing.T) {
+	svc, _, users, _ := newTestService()
+	a, err := svc.Request(context.Background(), "alice", deleteBob)
+	require.NoError(t, err)
+
+	_, err = svc.Approve(context.Background(), "alice", a.ApprovalID)
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Empty(t, users.deleted)
+}
+
+func TestApprove_ExecutesOnce(t *testing.T) {
+	svc, store, users, audit := newTestService()
+	a, err := svc.Request(context.Background(), "alice", deleteBob)
+	require.NoError(t, err)
+
+	got, err := svc.Approve(context.Background(), "carol", a.ApprovalID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ApprovalApproved, got.Status)
+	assert.Equal(t, "carol", got.DecidedBy)
+	assert.Equal(t, []string{"bob"}, users.deleted)
+	assert.Equal(t, domain.ApprovalApproved, store.items[a.ApprovalID].Status)
+	assert.Equal(t, []string{domain.AuditApprovalRequest, domain.AuditApprovalApprove}, audit.actions)
+
+	_, err = svc.Approve(context.Background(), "dave", a.ApprovalID)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.Len(t, users.deleted, 1)
+}
+
+func TestApprove_BulkRunsAsRequester(t *testing.T) {
+	svc, _, users, _ := newTestService()
+	bulk := &domain.BulkUserRequest{Action: domain.BulkDisable, UserIDs: []string{"u1", "u2"}}
+	a, err := svc.Request(context.Background(), "alice", domain.Approval{Action: domain.ApprovalBulk, Bulk: bulk})
+	require.NoError(t, err)
+
+	got, err := svc.Approve(context.Background(), "carol", a.ApprovalID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", users.bulkBy)
+	assert.Len(t, got.Results, 2)
+}
+
+func TestApprove_FailedActionIsRecorded(t *testing.T) {
+	svc, store, users, _ := newTestService()
+	users.bulkErr = fmt.Errorf("unknown role: %w", domain.ErrBadRequest)
+	bulk := &domain.BulkUserRequest{Action: domain.BulkSetRole, Role: domain.RoleAdmin, UserIDs: []string{"u1"}}
+	a, err := svc.Request(context.Background(), "alice", domain.Approval{Action: domain.ApprovalBulk, Bulk: bulk})
+	require.NoError(t, err)
+
+	got, err := svc.Approve(context.Background(), "carol", a.ApprovalID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ApprovalFailed, got.Status)
+	assert.Contains(t, got.Error, "unknown role")
+	assert.Equal(t, domain.ApprovalFailed, store.items[a.ApprovalID].Status)
+}
+
+func TestApprove_ExpiredIsConflict(t *testing.T) {
+	svc, _, users, _ := newTestService()
+	a, err := svc.Request(context.Background(), "alice", deleteBob)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	_, err = svc.Approve(context.Background(), "carol", a.ApprovalID)
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.Empty(t, users.deleted)
+
+	expired, err := svc.List(context.Background(), domain.ApprovalExpired)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	pending, err := svc.List(context.Background(), domain.ApprovalPending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestReject_RequesterCanWithdraw(t *testing.T) {
+	svc, _, users, audit := newTestService()
+	grant := domain.Approval{Action: domain.ApprovalGrantAdmin, TargetID: "bob", ChangeRole: &domain.ChangeRoleRequest{Role: domain.RoleAdmin}}
+	a, err := svc.Request(context.Background(), "alice", grant)
+	require.NoError(t, err)
+
+	got, err := svc.Reject(context.Background(), "alice", a.ApprovalID, "wrong user")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ApprovalRejected, got.Status)
+	assert.Equal(t, "wrong user", got.Reason)
+	assert.Contains(t, audit.actions, domain.AuditApprovalReject)
+	_, err = svc.Approve(context.Background(), "carol", a.ApprovalID)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.Empty(t, users.roleSets)
+}
+
+func TestRequest_Validation(t *testing.T) {
+	svc, store, _, _ := newTestService()
+
+	_, err := svc.Request(context.Background(), "alice", domain.Approval{Action: domain.ApprovalUserDelete, TargetID: "missing"})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = svc.Request(context.Background(), "alice", domain.Approval{Action: domain.ApprovalGrantAdmin, TargetID: "bob"})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+
+	_, err = svc.Request(context.Background(), "alice", domain.Approval{Action: "user.purge_everything"})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	assert.Empty(t, store.items)
+}
@@ -0,0 +1,111 @@
+package appversion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+)
+
+// Service manages the app versions that devices report in PUT /v1/devices/version.
+// Each platform has its own versions; the newest enabled one is the version
+// devices of that platform must run.
+type Service interface {
+	// List returns every app version ordered by platform, then version string.
+	List(ctx context.Context) ([]domain.AppVersion, error)
+	Create(ctx context.Context, input domain.AppVersionInput) (*domain.AppVersion, error)
+	Update(ctx context.Context, versionID string, input domain.AppVersionInput) (*domain.AppVersion, error)
+}
+
+type appVersionStore interface {
+	List(ctx context.Context) ([]domain.AppVersion, error)
+	Get(ctx context.Context, versionID string) (*domain.AppVersion, error)
+	Put(ctx context.Context, v *domain.AppVersion) error
+}
+
+type service struct {
+	repo appVersionStore
+}
+
+func NewService(repo appVersionStore) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) List(ctx context.Context) ([]domain.AppVersion, error) {
+	versions, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Platform != versions[j].Platform {
+			return versions[i].Platform < versions[j].Platform
+		}
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+func (s *service) Create(ctx context.Context, input domain.AppVersionInput) (*domain.AppVersion, error) {
+	if input.Platform == "" {
+		return nil, fmt.Errorf("platform is required: %w", domain.ErrBadRequest)
+	}
+	if err := s.checkVersionUnique(ctx, input.Platform, input.Version, ""); err != nil {
+		return nil, err
+	}
+	v := &domain.AppVersion{
+		VersionID:  id.New(),
+		Platform:   input.Platform,
+		Version:    input.Version,
+		Enable:     true,
+		ReleasedAt: time.Now().UTC(),
+	}
+	if input.Enable != nil {
+		v.Enable = *input.Enable
+	}
+	if err := s.repo.Put(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *service) Update(ctx context.Context, versionID string, input domain.AppVersionInput) (*domain.AppVersion, error) {
+	v, err := s.repo.Get(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Platform != "" {
+		v.Platform = input.Platform
+	}
+	if err := s.checkVersionUnique(ctx, v.Platform, input.Version, versionID); err != nil {
+		return nil, err
+	}
+	if v.ReleasedAt.IsZero() { // saved before versions had a release time
+		v.ReleasedAt = time.Now().UTC()
+	}
+	v.Version = input.Version
+	if input.Enable != nil {
+		v.Enable = *input.Enable
+	}
+	if err := s.repo.Put(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// checkVersionUnique returns ErrConflict if an app version of platform other
+// than selfID already uses version.
+func (s *service) checkVersionUnique(ctx context.Context, platform, version, selfID string) error {
+	versions, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v.Platform == platform && v.Version == version && v.VersionID != selfID {
+			return fmt.Errorf("%s app version %q already exists: %w", platform, version, domain.ErrConflict)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package appversion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockAppVersionStore struct{ mock.Mock }
+
+func (m *mockAppVersionStore) List(ctx context.Context) ([]domain.AppVersion, error) {
+	args := m.Called(ctx)
+	vs, _ := args.Get(0).([]domain.AppVersion)
+	return vs, args.Error(1)
+}
+
+func (m *mockAppVersionStore) Get(ctx context.Context, versionID string) (*domain.AppVersion, error) {
+	args := m.Called(ctx, versionID)
+	if v, _ := args.Get(0).(*domain.AppVersion); v != nil {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockAppVersionStore) Put(ctx context.Context, v *domain.AppVersion) error {
+	return m.Called(ctx, v).Error(0)
+}
+
+func versions() []domain.AppVersion {
+	return []domain.AppVersion{
+		{VersionID: "v2", Platform: domain.PlatformIOS, Version: "2.0.0", Enable: true},
+		{VersionID: "v1", Platform: domain.PlatformIOS, Version: "1.0.0", Enable: false},
+		{VersionID: "a1", Platform: domain.PlatformAndroid, Version: "3.0.0", Enable: true},
+	}
+}
+
+func TestList_SortsByPlatformThenVersion(t *testing.T) {
+	repo := &mockAppVersionStore{}
+	repo.On("List", mock.Anything).Return(versions(), nil)
+
+	got, err := NewService(repo).List(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, "a1", got[0].VersionID, "platforms are grouped")
+	assert.Equal(t, "1.0.0", got[1].Version)
+}
+
+func TestCreate_DuplicateVersionIsConflict(t *testing.T) {
+	repo := &mockAppVersionStore{}
+	repo.On("List", mock.Anything).Return(versions(), nil)
+
+	_, err := NewService(repo).Create(context.Background(), domain.AppVersionInput{Platform: domain.PlatformIOS, Version: "2.0.0"})
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	repo.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestCreate_SameVersionOnAnotherPlatform(t *testing.T) {
+	repo := &mockAppVersionStore{}
+	repo.On("List", mock.Anything).Return(versions(), nil)
+	repo.On("Put", mock.Anything, mock.AnythingOfType("*domain.AppVersion")).Return(nil)
+
+	v, err := NewService(repo).Create(context.Background(), domain.AppVersionInput{Platform: domain.PlatformAndroid, Version: "2.0.0"})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlatformAndroid, v.Platform)
+	assert.False(t, v.ReleasedAt.IsZero())
+}
+
+func TestCreate_PlatformRequired(t *testing.T) {
+	repo := &mockAppVersionStore{}
+
+	_, err := NewService(repo).Create(context.Background(), domain.AppVersionInput{Version: "2.0.0"})
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestCreate_EnabledByDefault(t *testing.T) {
+	repo := &mockAppVersionStore{}
+	repo.On("List", mock.Anything).Return(versions(), nil)
+	repo.On("Put", mock.Anything, mock.AnythingOfType("*domain.AppVersion")).Return(nil)
+
+	v, err := NewService(repo).Create(context.Background(), domain.AppVersionInput{Platform: domain.PlatformIOS, Version: "3.0.0"})
+
+	require.NoError(t, err)
+	assert.True(t, v.Enable)
+	assert.NotEmpty(t, v.VersionID)
+}
+
+func TestUpdate_TogglesEnableAndKeepsOwnVersion(t *testing.T) {
+	repo := &mockAppVersionStore{}
+	repo.On("Get", mock.Anything, "v2").Return(&domain.AppVersion{VersionID: "v2", Platform: domain.PlatformIOS, Version: "2.0.0", Enable: true}, nil)
+	repo.On("List", mock.Anything).Return(versions(), nil)
+	repo.On("Put", mock.Anything, mock.AnythingOfType("*domain.AppVersion")).Return(nil)
+	disable := false
+
+	v, err := NewService(repo).Update(context.Background(), "v2", domain.AppVersionInput{Version: "2.0.0", Enable: &disable})
+
+	require.NoError(t, err)
+	assert.False(t, v.Enable)
+}
+
+func TestUpdate_UnknownVersionIsNotFound(t *testing.T) {
+	repo := &mockAppVersionStore{}
+	repo.On("Get", mock.Anything, "nope").Return(nil, domain.ErrNotFound)
+
+	_, err := NewService(repo).Update(context.Background(), "nope", domain.AppVersionInput{Version: "9.9.9"})
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
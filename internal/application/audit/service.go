@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+)
+
+type Service interface {
+	// Record stores e after filling its ID, day and timestamp. The audited
+	// action has already happened, so failures are logged rather than returned.
+	Record(ctx context.Context, e domain.AuditEntry)
+	// Export calls fn for every entry in f's day range, oldest first. An empty
+	// range covers the last 30 days; ranges longer than a year are ErrBadRequest.
+	Export(ctx context.Context, f domain.AuditFilter, fn func(domain.AuditEntry) error) error
+}
+
+type auditStore interface {
+	Put(ctx context.Context, e *domain.AuditEntry) error
+	EachInDay(ctx context.Context, day string, fn func(domain.AuditEntry) error) error
+}
+
+const (
+	dayLayout         = "2006-01-02"
+	defaultExportDays = 30
+	maxExportDays     = 366
+)
+
+type service struct {
+	repo      auditStore
+	retention time.Duration
+}
+
+// NewService builds the audit service. Entries expire after retention via
+// DynamoDB TTL; a zero retention keeps them forever.
+func NewService(repo auditStore, retention time.Duration) Service {
+	return &service{repo: repo, retention: retention}
+}
+
+func (s *service) Record(ctx context.Context, e domain.AuditEntry) {
+	now := time.Now().UTC()
+	e.AuditID = id.New()
+	e.Day = now.Format(dayLayout)
+	e.CreatedAt = now
+	if s.retention > 0 {
+		e.ExpiresAt = now.Add(s.retention).Unix()
+	}
+	if err := s.repo.Put(ctx, &e); err != nil {
+		slog.Error("failed to record audit entry", "action", e.Action, "actor_id", e.ActorID, "target_id", e.TargetID, "err", err)
+	}
+}
+
+func (s *service) Export(ctx context.Context, f domain.AuditFilter, fn func(domain.AuditEntry) error) error {
+	days, err := exportRange(f, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for day := days.from; !day.After(days.to); day = day.AddDate(0, 0, 1) {
+		err := s.repo.EachInDay(ctx, day.Format(dayLayout), func(e domain.AuditEntry) error {
+			if f.Action != "" && e.Action != f.Action {
+				return nil
+			}
+			return fn(e)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dayRange is an inclusive range of UTC days.
+type dayRange struct{ from, to time.Time }
+
+// exportRange resolves f's day bounds, defaulting To to today and From to
+// defaultExportDays before To.
+func exportRange(f domain.AuditFilter, now time.Time) (dayRange, error) {
+	r := dayRange{to: now.Truncate(24 * time.Hour)}
+	if f.To != "" {
+		t, err := time.Parse(dayLayout, f.To)
+		if err != nil {
+			return r, fmt.Errorf("to must be in YYYY-MM-DD format: %w", domain.ErrBadRequest)
+		}
+		r.to = t
+	}
+	r.from = r.to.AddDate(0, 0, 1-defaultExportDays)
+	if f.From != "" {
+		t, err := time.Parse(dayLayout, f.From)
+		if err != nil {
+			return r, fmt.Errorf("from must be in YYYY-MM-DD format: %w", domain.ErrBadRequest)
+		}
+		r.from = t
+	}
+	if r.from.After(r.to) {
+		return r, fmt.Errorf("from must not be after to: %w", domain.ErrBadRequest)
+	}
+	if r.to.Sub(r.from) >= maxExportDays*24*time.Hour {
+		return r, fmt.Errorf("range must not exceed %d days: %w", maxExportDays, domain.ErrBadRequest)
+	}
+	return r, nil
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuditStore struct{ mock.Mock }
+
+func (m *mockAuditStore) Put(ctx context.Context, e *domain.AuditEntry) error {
+	return m.Called(ctx, e).Error(0)
+}
+
+func (m *mockAuditStore) EachInDay(ctx context.Context, day string, fn func(domain.AuditEntry) error) error {
+	args := m.Called(ctx, day, fn)
+	if entries, _ := args.Get(0).([]domain.AuditEntry); entries != nil {
+		for _, e := range entries {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func TestRecord_FillsIDDayAndTimestamp(t *testing.T) {
+	repo := &mockAuditStore{}
+	var saved *domain.AuditEntry
+	repo.On("Put", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*domain.AuditEntry)
+	}).Return(nil)
+
+	NewService(repo, 0).Record(context.Background(), domain.AuditEntry{
+		Action: domain.AuditUserRoleChange, ActorID: "admin1", TargetID: "u1",
+	})
+
+	require.NotNil(t, saved)
+	assert.NotEmpty(t, saved.AuditID)
+	assert.WithinDuration(t, time.Now(), saved.CreatedAt, time.Minute)
+	assert.Equal(t, saved.CreatedAt.Format("2006-01-02"), saved.Day)
+	assert.Equal(t, "u1", saved.TargetID)
+}
+
+func TestRecord_SetsTTLFromRetention(t *testing.T) {
+	repo := &mockAuditStore{}
+	var saved *domain.AuditEntry
+	repo.On("Put", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*domain.AuditEntry)
+	}).Return(nil)
+
+	NewService(repo, 24*time.Hour).Record(context.Background(), domain.AuditEntry{Action: domain.AuditUserDelete})
+
+	require.NotNil(t, saved)
+	assert.Equal(t, saved.CreatedAt.Add(24*time.Hour).Unix(), saved.ExpiresAt)
+}
+
+func TestRecord_StoreErrorIsSwallowed(t *testing.T) {
+	repo := &mockAuditStore{}
+	repo.On("Put", mock.Anything, mock.Anything).Return(errors.New("dynamo down"))
+
+	assert.NotPanics(t, func() {
+		NewService(repo, 0).Record(context.Background(), domain.AuditEntry{Action: domain.AuditUserRoleChange})
+	})
+}
+
+func TestExport_QueriesEachDayAndFiltersByAction(t *testing.T) {
+	repo := &mockAuditStore{}
+	repo.On("EachInDay", mock.Anything, "2024-02-28", mock.Anything).Return([]domain.AuditEntry{
+		{AuditID: "a1", Action: domain.AuditUserRoleChange},
+		{AuditID: "a2", Action: "other"},
+	}, nil)
+	repo.On("EachInDay", mock.Anything, "2024-02-29", mock.Anything).Return(nil, nil)
+	repo.On("EachInDay", mock.Anything, "2024-03-01", mock.Anything).Return([]domain.AuditEntry{
+		{AuditID: "a3", Action: domain.AuditUserRoleChange},
+	}, nil)
+
+	var got []string
+	err := NewService(repo, 0).Export(context.Background(), domain.AuditFilter{
+		From: "2024-02-28", To: "2024-03-01", Action: domain.AuditUserRoleChange,
+	}, func(e domain.AuditEntry) error {
+		got = append(got, e.AuditID)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a3"}, got)
+	repo.AssertExpectations(t)
+}
+
+func TestExport_RejectsInvalidRanges(t *testing.T) {
+	cases := map[string]domain.AuditFilter{
+		"bad date":      {From: "yesterday"},
+		"from after to": {From: "2024-03-02", To: "2024-03-01"},
+		"too long":      {From: "2023-01-01", To: "2024-12-31"},
+	}
+	for name, f := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo := &mockAuditStore{}
+
+			err := NewService(repo, 0).Export(context.Background(), f, func(domain.AuditEntry) error { return nil })
+
+			assert.ErrorIs(t, err, domain.ErrBadRequest)
+			repo.AssertNotCalled(t, "EachInDay", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
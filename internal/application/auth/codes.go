@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// Named code charsets. Any other charset value is taken as the literal
+// characters to draw from.
+const (
+	// CharsetUnambiguous is uppercase letters and digits without the easily
+	// confused 0, 1, I, L and O, for codes typed in by hand.
+	CharsetUnambiguous  = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+	CharsetDigits       = "0123456789"
+	CharsetAlphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+var namedCharsets = map[string]string{
+	"unambiguous":  CharsetUnambiguous,
+	"digits":       CharsetDigits,
+	"alphanumeric": CharsetAlphanumeric,
+}
+
+// Bounds enforced by CodePolicy.Validate.
+const (
+	minCodeLength = 4
+	maxCodeLength = 128
+	minCodeTTL    = time.Minute
+	maxCodeTTL    = 7 * 24 * time.Hour
+	// minCodeBits keeps codes at least as hard to guess as six digits.
+	minCodeBits = 19.9
+)
+
+// CodePolicy shapes the codes of one verification type.
+type CodePolicy struct {
+	Length  int
+	Charset string // characters codes are drawn from
+	TTL     time.Duration
+}
+
+// CodePolicies holds the policy of each verification type.
+type CodePolicies struct {
+	Recovery CodePolicy // password-recovery OTPs, sent by email
+	Email    CodePolicy // email confirmation tokens
+	Phone    CodePolicy // phone confirmation OTPs, sent by SMS
+}
+
+// DefaultCodePolicies are six-character hand-typed OTPs valid for 15 minutes
+// and 32-character email tokens valid for a day.
+var DefaultCodePolicies = CodePolicies{
+	Recovery: CodePolicy{Length: 6, Charset: CharsetUnambiguous, TTL: 15 * time.Minute},
+	Email:    CodePolicy{Length: 32, Charset: CharsetAlphanumeric, TTL: 24 * time.Hour},
+	Phone:    CodePolicy{Length: 6, Charset: CharsetUnambiguous, TTL: 15 * time.Minute},
+}
+
+// Charset returns the characters of a named charset ("unambiguous",
+// "digits" or "alphanumeric"), or name itself when it names none.
+func Charset(name string) string {
+	if chars, ok := namedCharsets[name]; ok {
+		return chars
+	}
+	return name
+}
+
+// Validate reports a policy that is out of bounds or whose codes would be
+// easier to guess than six digits.
+func (p CodePolicy) Validate() error {
+	if p.Length < minCodeLength || p.Length > maxCodeLength {
+		return fmt.Errorf("length must be between %d and %d, got %d", minCodeLength, maxCodeLength, p.Length)
+	}
+	if p.TTL < minCodeTTL || p.TTL > maxCodeTTL {
+		return fmt.Errorf("TTL must be between %s and %s, got %s", minCodeTTL, maxCodeTTL, p.TTL)
+	}
+	seen := make(map[rune]bool, len(p.Charset))
+	for _, c := range p.Charset {
+		if c <= ' ' || c > '~' {
+			return fmt.Errorf("charset must be printable ASCII without spaces")
+		}
+		if seen[c] {
+			return fmt.Errorf("charset repeats %q", c)
+		}
+		seen[c] = true
+	}
+	if len(seen) < 2 {
+		return fmt.Errorf("charset needs at least 2 characters")
+	}
+	if bits := float64(p.Length) * math.Log2(float64(len(seen))); bits < minCodeBits {
+		return fmt.Errorf("%d characters from a set of %d are too easy to guess", p.Length, len(seen))
+	}
+	return nil
+}
+
+// Validate checks every policy, naming the one at fault.
+func (c CodePolicies) Validate() error {
+	if err := c.Recovery.Validate(); err != nil {
+		return fmt.Errorf("recovery code policy: %w", err)
+	}
+	if err := c.Email.Validate(); err != nil {
+		return fmt.Errorf("email code policy: %w", err)
+	}
+	if err := c.Phone.Validate(); err != nil {
+		return fmt.Errorf("phone code policy: %w", err)
+	}
+	return nil
+}
+
+// withDefaults fills zero fields from DefaultCodePolicies.
+func (c CodePolicies) withDefaults() CodePolicies {
+	c.Recovery = c.Recovery.or(DefaultCodePolicies.Recovery)
+	c.Email = c.Email.or(DefaultCodePolicies.Email)
+	c.Phone = c.Phone.or(DefaultCodePolicies.Phone)
+	return c
+}
+
+func (p CodePolicy) or(def CodePolicy) CodePolicy {
+	if p.Length == 0 {
+		p.Length = def.Length
+	}
+	if p.Charset == "" {
+		p.Charset = def.Charset
+	}
+	if p.TTL == 0 {
+		p.TTL = def.TTL
+	}
+	return p
+}
+
+// generate returns a cryptographically random code.
+func (p CodePolicy) generate() (string, error) {
+	max := big.NewInt(int64(len(p.Charset)))
+	b := make([]byte, p.Length)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = p.Charset[idx.Int64()]
+	}
+	return string(b), nil
+}
+
+// humanDuration renders d for messages: "15 minutes", "1 hour", "24 hours".
+func humanDuration(d time.Duration) string {
+	n, unit := int64(d/time.Minute), "minute"
+	if d%time.Hour == 0 {
+		n, unit = int64(d/time.Hour), "hour"
+	}
+	if d%time.Minute != 0 {
+		return d.String()
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
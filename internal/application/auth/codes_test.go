@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodePolicy_Validate(t *testing.T) {
+	require.NoError(t, DefaultCodePolicies.Validate())
+	assert.NoError(t, CodePolicy{Length: 6, Charset: CharsetDigits, TTL: 10 * time.Minute}.Validate())
+
+	for name, p := range map[string]CodePolicy{
+		"too short":     {Length: 3, Charset: CharsetAlphanumeric, TTL: time.Hour},
+		"too guessable": {Length: 5, Charset: CharsetDigits, TTL: time.Hour},
+		"repeats":       {Length: 8, Charset: "aab", TTL: time.Hour},
+		"whitespace":    {Length: 8, Charset: "ab cd", TTL: time.Hour},
+		"ttl too short": {Length: 8, Charset: CharsetDigits, TTL: time.Second},
+		"ttl too long":  {Length: 8, Charset: CharsetDigits, TTL: 30 * 24 * time.Hour},
+	} {
+		assert.Error(t, p.Validate(), name)
+	}
+}
+
+func TestCodePolicy_GenerateUsesLengthAndCharset(t *testing.T) {
+	p := CodePolicy{Length: 8, Charset: Charset("digits"), TTL: time.Minute}
+
+	code, err := p.generate()
+
+	require.NoError(t, err)
+	assert.Len(t, code, 8)
+	assert.Empty(t, strings.Trim(code, CharsetDigits))
+}
+
+func TestHumanDuration(t *testing.T) {
+	assert.Equal(t, "15 minutes", humanDuration(15*time.Minute))
+	assert.Equal(t, "1 hour", humanDuration(time.Hour))
+	assert.Equal(t, "24 hours", humanDuration(24*time.Hour))
+	assert.Equal(t, "90 minutes", humanDuration(90*time.Minute))
+	assert.Equal(t, "1m30s", humanDuration(90*time.Second))
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// DeviceVerificationService lets a signed-in user trust the device they are
+// on, which password and email changes require, with an OTP sent by email.
+// It works for accounts without a phone number and for devices trusted
+// before sign-in.
+type DeviceVerificationService interface {
+	// RequestDeviceVerification emails userID a one-time code, unless one is
+	// still pending.
+	RequestDeviceVerification(ctx context.Context, userID string) error
+	// ValidateDeviceOTP checks otp and marks deviceID as trusted.
+	ValidateDeviceOTP(ctx context.Context, userID, deviceID, otp string) error
+}
+
+func (s *service) RequestDeviceVerification(ctx context.Context, userID string) error {
+	if existing, err := s.verificationRepo.Get(ctx, userID, "device"); err == nil && existing.ExpiresAt > time.Now().Unix() {
+		return fmt.Errorf("OTP already sent, please wait before requesting a new one: %w", domain.ErrBadRequest)
+	}
+	u, err := s.userRepo.Get(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", domain.ErrNotFound)
+	}
+	policy := s.codes.Recovery
+	otp, err := policy.generate()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	v := &domain.UserVerification{
+		UserID:    userID,
+		Type:      "device",
+		CodeHash:  s.hashCode(userID, "device", otp),
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(policy.TTL).Unix(),
+	}
+	if err := s.verificationRepo.Put(ctx, v); err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Your device verification code is: %s\n\nThis code expires in %s.\nIf you did not request this, change your password.",
+		otp, humanDuration(policy.TTL))
+	return s.mailer.SendEmail(u.Email, "Verify your device", body)
+}
+
+func (s *service) ValidateDeviceOTP(ctx context.Context, userID, deviceID, otp string) error {
+	if deviceID == "" {
+		return fmt.Errorf("session has no device to verify: %w", domain.ErrBadRequest)
+	}
+	if err := s.checkCode(ctx, userID, "device", otp); err != nil {
+		return err
+	}
+	s.trustDevice(ctx, &domain.Device{DeviceID: deviceID})
+	return nil
+}
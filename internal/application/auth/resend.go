@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// defaultResendCooldown applies when ServiceDeps.ResendCooldown is unset.
+const defaultResendCooldown = time.Minute
+
+// CooldownError reports a resend attempted before the cooldown ran out. It
+// wraps domain.ErrTooMany.
+type CooldownError struct {
+	Wait time.Duration // time left until a resend is accepted
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("a code was sent recently, retry in %d seconds: %v", e.Seconds(), domain.ErrTooMany)
+}
+
+func (e *CooldownError) Unwrap() error { return domain.ErrTooMany }
+
+// Seconds is Wait rounded up to whole seconds, as sent in Retry-After.
+func (e *CooldownError) Seconds() int {
+	return int(math.Ceil(e.Wait.Seconds()))
+}
+
+func (s *service) ResendEmailConfirmation(ctx context.Context, userID string) error {
+	if err := s.checkResendCooldown(ctx, userID, "email"); err != nil {
+		return err
+	}
+	return s.sendEmailConfirmation(ctx, userID)
+}
+
+func (s *service) ResendPhoneConfirmation(ctx context.Context, userID string) error {
+	u, err := s.phoneUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkResendCooldown(ctx, userID, "phone"); err != nil {
+		return err
+	}
+	return s.sendPhoneConfirmation(ctx, u)
+}
+
+// checkResendCooldown returns a *CooldownError while userID's pending verType
+// code is younger than the cooldown. Expired codes, and rows written before
+// creation times were recorded, never hold a resend back.
+func (s *service) checkResendCooldown(ctx context.Context, userID, verType string) error {
+	v, err := s.verificationRepo.Get(ctx, userID, verType)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if v.CreatedAt == 0 || v.ExpiresAt <= now.Unix() {
+		return nil
+	}
+	if wait := time.Unix(v.CreatedAt, 0).Add(s.resendCooldown).Sub(now); wait > 0 {
+		return &CooldownError{Wait: wait}
+	}
+	return nil
+}
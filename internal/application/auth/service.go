@@ -2,11 +2,12 @@ package auth
 
 import (
 	"context"
-	"crypto/rand"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
-	"math/big"
 	"time"
 
 	"github.com/go-api-nosql/internal/domain"
@@ -14,6 +15,8 @@ import (
 	"github.com/go-api-nosql/internal/infrastructure/sns"
 	pkgdevice "github.com/go-api-nosql/internal/pkg/device"
 	"github.com/go-api-nosql/internal/pkg/id"
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
+	"github.com/go-api-nosql/internal/pkg/password"
 	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -23,8 +26,23 @@ const (
 	fieldPasswordHash   = "password_hash"
 	fieldEmailConfirmed = "email_confirmed"
 	fieldPhoneConfirmed = "phone_confirmed"
+	fieldTrusted        = "trusted"
+	fieldPasswordChange = "password_change"
 )
 
+// Failed code checks are throttled per user and verification type with
+// exponential backoff. State lives in the shared attempt store, so the limit
+// holds across replicas.
+const (
+	freeCodeAttempts = 5                // failures allowed before lockouts start
+	codeBackoffBase  = 30 * time.Second // first lockout; doubles with each further failure
+	codeBackoffMax   = time.Hour
+	codeAttemptsTTL  = 24 * time.Hour // failures are forgotten after a quiet day
+)
+
+// codeLabel names each verification type's code in error messages.
+var codeLabel = map[string]string{"otp": "OTP", "email": "token", "phone": "OTP", "device": "OTP"}
+
 type PasswordRecoveryRequest struct {
 	Email       *string `json:"email"`
 	PhoneNumber *string `json:"phone_number"`
@@ -32,7 +50,7 @@ type PasswordRecoveryRequest struct {
 
 type ValidateOTPRequest struct {
 	OTP         string  `json:"otp"          validate:"required"`
-	NewPassword string  `json:"new_password" validate:"required,min=8,max=72"`
+	NewPassword string  `json:"new_password" validate:"required,max=72"`
 	DeviceUUID  *string `json:"device_uuid"`
 	Email       *string `json:"email"`
 }
@@ -50,19 +68,27 @@ type PasswordRecoveryService interface {
 
 type EmailConfirmationService interface {
 	RequestEmailConfirmation(ctx context.Context, userID string) error
+	// ResendEmailConfirmation replaces the pending token once the resend
+	// cooldown has passed; sooner it returns a *CooldownError.
+	ResendEmailConfirmation(ctx context.Context, userID string) error
 	ValidateEmailToken(ctx context.Context, userID, token string) error
 }
 
 type PhoneConfirmationService interface {
 	RequestPhoneConfirmation(ctx context.Context, userID string) error
-	ValidatePhoneOTP(ctx context.Context, userID, otp string) error
+	// ResendPhoneConfirmation replaces the pending OTP once the resend
+	// cooldown has passed; sooner it returns a *CooldownError.
+	ResendPhoneConfirmation(ctx context.Context, userID string) error
+	// ValidatePhoneOTP confirms the phone number and marks deviceID as trusted.
+	ValidatePhoneOTP(ctx context.Context, userID, deviceID, otp string) error
 }
 
-// Service composes the three focused auth sub-services.
+// Service composes the focused auth sub-services.
 type Service interface {
 	PasswordRecoveryService
 	EmailConfirmationService
 	PhoneConfirmationService
+	DeviceVerificationService
 }
 
 type verificationStore interface {
@@ -83,12 +109,20 @@ type sessionStore interface {
 }
 
 type deviceStore interface {
-	GetByUUID(ctx context.Context, uuid string) (*domain.Device, error)
+	GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error)
 	Put(ctx context.Context, d *domain.Device) error
+	Update(ctx context.Context, deviceID string, updates map[string]interface{}) error
 }
 
 type jwtSigner interface {
-	Sign(userID, deviceID, role, sessionID string) (string, error)
+	Sign(ctx context.Context, userID, deviceID, role, sessionID string) (string, error)
+}
+
+type attemptStore interface {
+	Increment(ctx context.Context, key string, expiresAt int64) (int64, error)
+	Delete(ctx context.Context, key string) error
+	Lock(ctx context.Context, key string, until int64) error
+	LockedUntil(ctx context.Context, key string) (int64, error)
 }
 
 type service struct {
@@ -100,6 +134,14 @@ type service struct {
 	smsSender        sns.SMSSender
 	jwtProvider      jwtSigner
 	refreshTokenDur  time.Duration
+	untrustedDur     time.Duration
+	attempts         attemptStore
+	antiEnumeration  bool
+	codeSecret       []byte
+	codes            CodePolicies
+	resendCooldown   time.Duration
+	passwordPolicy   password.Policy
+	background       context.Context
 }
 
 type ServiceDeps struct {
@@ -111,9 +153,39 @@ type ServiceDeps struct {
 	SMSSender        sns.SMSSender
 	JWTProvider      jwtSigner
 	RefreshTokenDur  time.Duration
+	// UntrustedDur is the refresh-token lifetime on untrusted devices (0 = RefreshTokenDur).
+	UntrustedDur time.Duration
+	Attempts     attemptStore // optional; throttles failed code checks per user
+	// AntiEnumeration makes password recovery answer the same way whether or
+	// not the email is registered.
+	AntiEnumeration bool
+	// CodeSecret is the HMAC key verification codes are stored under. Every
+	// replica must share it.
+	CodeSecret []byte
+	// Codes shapes each verification type's codes; zero fields take the
+	// DefaultCodePolicies value.
+	Codes CodePolicies
+	// ResendCooldown is the minimum wait between confirmation codes sent
+	// through the resend actions (0 = defaultResendCooldown).
+	ResendCooldown time.Duration
+	// PasswordPolicy, breach check included, is checked on the password set
+	// by recovery.
+	PasswordPolicy password.Policy
+	// Background runs the recovery emails sent after the response in
+	// anti-enumeration mode. Pass a lifecycle.Manager's context so shutdown
+	// waits for them; nil sends them untracked.
+	Background context.Context
 }
 
 func NewService(deps ServiceDeps) Service {
+	cooldown := deps.ResendCooldown
+	if cooldown <= 0 {
+		cooldown = defaultResendCooldown
+	}
+	background := deps.Background
+	if background == nil {
+		background = context.Background()
+	}
 	return &service{
 		verificationRepo: deps.VerificationRepo,
 		userRepo:         deps.UserRepo,
@@ -123,9 +195,20 @@ func NewService(deps ServiceDeps) Service {
 		smsSender:        deps.SMSSender,
 		jwtProvider:      deps.JWTProvider,
 		refreshTokenDur:  deps.RefreshTokenDur,
+		untrustedDur:     deps.UntrustedDur,
+		attempts:         deps.Attempts,
+		antiEnumeration:  deps.AntiEnumeration,
+		codeSecret:       deps.CodeSecret,
+		codes:            deps.Codes.withDefaults(),
+		resendCooldown:   cooldown,
+		passwordPolicy:   deps.PasswordPolicy,
+		background:       background,
 	}
 }
 
+// In anti-enumeration mode an unknown email, or one with an OTP already
+// pending, reports success, and the email is sent in the background so the
+// response time does not depend on whether the account exists.
 func (s *service) RequestPasswordRecovery(ctx context.Context, req PasswordRecoveryRequest) error {
 	var u *domain.User
 	var err error
@@ -133,7 +216,7 @@ func (s *service) RequestPasswordRecovery(ctx context.Context, req PasswordRecov
 	case req.Email != nil:
 		u, err = s.userRepo.GetByEmail(ctx, *req.Email)
 		if err != nil {
-			return fmt.Errorf("user not found: %w", domain.ErrNotFound)
+			return s.hideExistence(fmt.Errorf("user not found: %w", domain.ErrNotFound))
 		}
 	case req.PhoneNumber != nil:
 		return fmt.Errorf("phone recovery not supported; provide email: %w", domain.ErrBadRequest)
@@ -142,10 +225,11 @@ func (s *service) RequestPasswordRecovery(ctx context.Context, req PasswordRecov
 	}
 
 	if existing, err := s.verificationRepo.Get(ctx, u.UserID, "otp"); err == nil && existing.ExpiresAt > time.Now().Unix() {
-		return fmt.Errorf("OTP request rate limit exceeded. Please try again later: %w", domain.ErrBadRequest)
+		return s.hideExistence(fmt.Errorf("OTP request rate limit exceeded. Please try again later: %w", domain.ErrBadRequest))
 	}
 
-	otp, err := generateOTP()
+	policy := s.codes.Recovery
+	otp, err := policy.generate()
 	if err != nil {
 		return err
 	}
@@ -153,44 +237,64 @@ func (s *service) RequestPasswordRecovery(ctx context.Context, req PasswordRecov
 	v := &domain.UserVerification{
 		UserID:    u.UserID,
 		Type:      "otp",
-		Code:      otp,
-		ExpiresAt: time.Now().Add(15 * time.Minute).Unix(),
+		CodeHash:  s.hashCode(u.UserID, "otp", otp),
+		CreatedAt: time.Now().Unix(),
+		ExpiresAt: time.Now().Add(policy.TTL).Unix(),
 	}
 	if err := s.verificationRepo.Put(ctx, v); err != nil {
 		return err
 	}
 
-	body := fmt.Sprintf("Your password recovery OTP is: %s\n\nThis code expires in 15 minutes.\nIf you did not request this, please ignore this email.", otp)
-	return s.mailer.SendEmail(u.Email, "Password Recovery OTP", body)
+	body := fmt.Sprintf("Your password recovery OTP is: %s\n\nThis code expires in %s.\nIf you did not request this, please ignore this email.",
+		otp, humanDuration(policy.TTL))
+	if !s.antiEnumeration {
+		return s.mailer.SendEmail(u.Email, "Password Recovery OTP", body)
+	}
+	lifecycle.Go(s.background, "password recovery email", func(context.Context) {
+		if err := s.mailer.SendEmail(u.Email, "Password Recovery OTP", body); err != nil {
+			slog.Error("failed to send password recovery email", "user_id", u.UserID, "err", err)
+		}
+	})
+	return nil
+}
+
+// hideExistence swallows err in anti-enumeration mode, where a recovery
+// request must look the same whether or not the account exists.
+func (s *service) hideExistence(err error) error {
+	if s.antiEnumeration {
+		return nil
+	}
+	return err
 }
 
 func (s *service) ValidateOTP(ctx context.Context, req ValidateOTPRequest) (*ValidateOTPResult, error) {
 	if req.Email == nil {
 		return nil, fmt.Errorf("email required to validate OTP: %w", domain.ErrBadRequest)
 	}
+	// Checked before the OTP so a refused password does not use up an attempt.
+	if err := s.passwordPolicy.CheckNew(ctx, req.NewPassword); err != nil {
+		return nil, err
+	}
 	u, err := s.userRepo.GetByEmail(ctx, *req.Email)
 	if err != nil {
+		if s.antiEnumeration {
+			return nil, fmt.Errorf("invalid OTP: %w", domain.ErrUnauthorized)
+		}
 		return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
 	}
-	v, err := s.verificationRepo.Get(ctx, u.UserID, "otp")
-	if err != nil {
-		return nil, fmt.Errorf("OTP not found: %w", domain.ErrNotFound)
-	}
-	if subtle.ConstantTimeCompare([]byte(v.Code), []byte(req.OTP)) != 1 {
-		return nil, fmt.Errorf("invalid OTP: %w", domain.ErrUnauthorized)
-	}
-	if v.ExpiresAt < time.Now().Unix() {
-		return nil, fmt.Errorf("OTP expired: %w", domain.ErrUnauthorized)
-	}
-	if err := s.verificationRepo.Delete(ctx, u.UserID, "otp"); err != nil {
-		slog.Warn("failed to delete OTP verification record", "user_id", u.UserID, "err", err)
+	if err := s.checkCode(ctx, u.UserID, "otp", req.OTP); err != nil {
+		return nil, err
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
-	if err := s.userRepo.Update(ctx, u.UserID, map[string]interface{}{fieldPasswordHash: string(hash)}); err != nil {
+	// A recovered password also replaces the temporary one of a provisioned account.
+	if err := s.userRepo.Update(ctx, u.UserID, map[string]interface{}{
+		fieldPasswordHash:   string(hash),
+		fieldPasswordChange: false,
+	}); err != nil {
 		return nil, err
 	}
 
@@ -203,6 +307,8 @@ func (s *service) ValidateOTP(ctx context.Context, req ValidateOTPRequest) (*Val
 	if err != nil {
 		return nil, err
 	}
+	// The OTP was delivered out of band, so completing it proves control of this device.
+	s.trustDevice(ctx, dev)
 	refreshToken, err := pkgtoken.NewRefreshToken()
 	if err != nil {
 		return nil, err
@@ -214,14 +320,14 @@ func (s *service) ValidateOTP(ctx context.Context, req ValidateOTPRequest) (*Val
 		DeviceID:         dev.DeviceID,
 		Enable:           true,
 		RefreshToken:     refreshToken,
-		RefreshExpiresAt: now.Add(s.refreshTokenDur).Unix(),
+		RefreshExpiresAt: now.Add(pkgdevice.RefreshLifetime(dev, s.refreshTokenDur, s.untrustedDur)).Unix(),
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
 	if err := s.sessionRepo.Put(ctx, sess); err != nil {
 		return nil, err
 	}
-	bearer, err := s.jwtProvider.Sign(u.UserID, dev.DeviceID, u.Role, sess.SessionID)
+	bearer, err := s.jwtProvider.Sign(ctx, u.UserID, dev.DeviceID, u.Role, sess.SessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -233,16 +339,23 @@ func (s *service) RequestEmailConfirmation(ctx context.Context, userID string) e
 	if existing, err := s.verificationRepo.Get(ctx, userID, "email"); err == nil && existing.ExpiresAt > time.Now().Unix() {
 		return fmt.Errorf("confirmation email already sent, please wait before requesting a new one: %w", domain.ErrBadRequest)
 	}
+	return s.sendEmailConfirmation(ctx, userID)
+}
 
-	token, err := generateToken(32)
+// sendEmailConfirmation issues a new email token, replacing any pending one.
+func (s *service) sendEmailConfirmation(ctx context.Context, userID string) error {
+	policy := s.codes.Email
+	token, err := policy.generate()
 	if err != nil {
 		return err
 	}
+	now := time.Now()
 	v := &domain.UserVerification{
 		UserID:    userID,
 		Type:      "email",
-		Code:      token,
-		ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
+		CodeHash:  s.hashCode(userID, "email", token),
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(policy.TTL).Unix(),
 	}
 	if err := s.verificationRepo.Put(ctx, v); err != nil {
 		return err
@@ -251,97 +364,177 @@ func (s *service) RequestEmailConfirmation(ctx context.Context, userID string) e
 	if err != nil {
 		return err
 	}
-	body := fmt.Sprintf("Your email confirmation token is: %s\n\nThis token expires in 24 hours.\nIf you did not request this, please ignore this email.", token)
+	body := fmt.Sprintf("Your email confirmation token is: %s\n\nThis token expires in %s.\nIf you did not request this, please ignore this email.",
+		token, humanDuration(policy.TTL))
 	return s.mailer.SendEmail(u.Email, "Confirm your email", body)
 }
 
 func (s *service) ValidateEmailToken(ctx context.Context, userID, token string) error {
-	v, err := s.verificationRepo.Get(ctx, userID, "email")
-	if err != nil {
-		return fmt.Errorf("token not found: %w", domain.ErrNotFound)
-	}
-	if subtle.ConstantTimeCompare([]byte(v.Code), []byte(token)) != 1 {
-		return fmt.Errorf("invalid token: %w", domain.ErrUnauthorized)
-	}
-	if v.ExpiresAt < time.Now().Unix() {
-		return fmt.Errorf("token expired: %w", domain.ErrUnauthorized)
-	}
-	if err := s.verificationRepo.Delete(ctx, userID, "email"); err != nil {
-		slog.Warn("failed to delete email verification record", "user_id", userID, "err", err)
+	if err := s.checkCode(ctx, userID, "email", token); err != nil {
+		return err
 	}
 	return s.userRepo.Update(ctx, userID, map[string]interface{}{fieldEmailConfirmed: true})
 }
 
 func (s *service) RequestPhoneConfirmation(ctx context.Context, userID string) error {
-	u, err := s.userRepo.Get(ctx, userID)
+	u, err := s.phoneUser(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("user not found: %w", domain.ErrNotFound)
-	}
-	if u.Phone == nil {
-		return fmt.Errorf("no phone number on account: %w", domain.ErrBadRequest)
+		return err
 	}
 	if existing, err := s.verificationRepo.Get(ctx, userID, "phone"); err == nil && existing.ExpiresAt > time.Now().Unix() {
 		return fmt.Errorf("OTP already sent, please wait before requesting a new one: %w", domain.ErrBadRequest)
 	}
+	return s.sendPhoneConfirmation(ctx, u)
+}
+
+// phoneUser loads userID, who must have a phone number on file.
+func (s *service) phoneUser(ctx context.Context, userID string) (*domain.User, error) {
+	u, err := s.userRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
+	}
+	if u.Phone == nil {
+		return nil, fmt.Errorf("no phone number on account: %w", domain.ErrBadRequest)
+	}
+	return u, nil
+}
 
-	otp, err := generateOTP()
+// sendPhoneConfirmation texts u a new OTP, replacing any pending one.
+func (s *service) sendPhoneConfirmation(ctx context.Context, u *domain.User) error {
+	policy := s.codes.Phone
+	otp, err := policy.generate()
 	if err != nil {
 		return err
 	}
+	now := time.Now()
 	v := &domain.UserVerification{
-		UserID:    userID,
+		UserID:    u.UserID,
 		Type:      "phone",
-		Code:      otp,
-		ExpiresAt: time.Now().Add(15 * time.Minute).Unix(),
+		CodeHash:  s.hashCode(u.UserID, "phone", otp),
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(policy.TTL).Unix(),
 	}
 	if err := s.verificationRepo.Put(ctx, v); err != nil {
 		return err
 	}
-	msg := fmt.Sprintf("Your verification code: %s (expires in 15 min). If you did not request this, ignore this message.", otp)
+	msg := fmt.Sprintf("Your verification code: %s (expires in %s). If you did not request this, ignore this message.",
+		otp, humanDuration(policy.TTL))
 	return s.smsSender.SendSMS(ctx, *u.Phone, msg)
 }
 
-func (s *service) ValidatePhoneOTP(ctx context.Context, userID, otp string) error {
-	v, err := s.verificationRepo.Get(ctx, userID, "phone")
+func (s *service) ValidatePhoneOTP(ctx context.Context, userID, deviceID, otp string) error {
+	if err := s.checkCode(ctx, userID, "phone", otp); err != nil {
+		return err
+	}
+	if err := s.userRepo.Update(ctx, userID, map[string]interface{}{fieldPhoneConfirmed: true}); err != nil {
+		return err
+	}
+	if deviceID != "" {
+		s.trustDevice(ctx, &domain.Device{DeviceID: deviceID})
+	}
+	return nil
+}
+
+// checkCode verifies code against userID's pending verType verification and
+// consumes it on success. Wrong codes count toward the per-user throttle.
+func (s *service) checkCode(ctx context.Context, userID, verType, code string) error {
+	key := "code:" + verType + ":" + userID
+	if err := s.checkLockout(ctx, key); err != nil {
+		return err
+	}
+	label := codeLabel[verType]
+	v, err := s.verificationRepo.Get(ctx, userID, verType)
 	if err != nil {
-		return fmt.Errorf("OTP not found: %w", domain.ErrNotFound)
+		if s.antiEnumeration {
+			// Same answer as a wrong code, so no pending request is revealed.
+			s.recordFailure(ctx, key)
+			return fmt.Errorf("invalid %s: %w", label, domain.ErrUnauthorized)
+		}
+		return fmt.Errorf("%s not found: %w", label, domain.ErrNotFound)
 	}
-	if subtle.ConstantTimeCompare([]byte(v.Code), []byte(otp)) != 1 {
-		return fmt.Errorf("invalid OTP: %w", domain.ErrUnauthorized)
+	if !s.codeMatches(v, userID, verType, code) {
+		s.recordFailure(ctx, key)
+		return fmt.Errorf("invalid %s: %w", label, domain.ErrUnauthorized)
 	}
 	if v.ExpiresAt < time.Now().Unix() {
-		return fmt.Errorf("OTP expired: %w", domain.ErrUnauthorized)
+		return fmt.Errorf("%s expired: %w", label, domain.ErrUnauthorized)
 	}
-	if err := s.verificationRepo.Delete(ctx, userID, "phone"); err != nil {
-		slog.Warn("failed to delete phone verification record", "user_id", userID, "err", err)
+	if err := s.verificationRepo.Delete(ctx, userID, verType); err != nil {
+		slog.Warn("failed to delete verification record", "user_id", userID, "type", verType, "err", err)
 	}
-	return s.userRepo.Update(ctx, userID, map[string]interface{}{fieldPhoneConfirmed: true})
+	if s.attempts != nil {
+		if err := s.attempts.Delete(ctx, key); err != nil {
+			slog.Warn("failed to reset code attempts", "key", key, "err", err)
+		}
+	}
+	return nil
 }
 
-// generateOTP returns a 6-character cryptographically random uppercase alphanumeric code,
-// excluding visually ambiguous characters (0, 1, I, L, O) for easier manual entry.
-func generateOTP() (string, error) {
-	const chars = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
-	b := make([]byte, 6)
-	for i := range b {
-		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
-		if err != nil {
-			return "", err
-		}
-		b[i] = chars[idx.Int64()]
+// hashCode is the stored form of code. The user and type are bound in, so a
+// hash copied onto another user's row does not verify there.
+func (s *service) hashCode(userID, verType, code string) string {
+	mac := hmac.New(sha256.New, s.codeSecret)
+	mac.Write([]byte(userID + "\x00" + verType + "\x00" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// codeMatches compares code with v in constant time. Rows written before
+// codes were hashed still hold plaintext and verify until they expire.
+func (s *service) codeMatches(v *domain.UserVerification, userID, verType, code string) bool {
+	if v.CodeHash == "" {
+		return v.Code != "" && subtle.ConstantTimeCompare([]byte(v.Code), []byte(code)) == 1
 	}
-	return string(b), nil
+	return subtle.ConstantTimeCompare([]byte(v.CodeHash), []byte(s.hashCode(userID, verType, code))) == 1
 }
 
-func generateToken(n int) (string, error) {
-	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, n)
-	for i := range b {
-		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-		if err != nil {
-			return "", err
-		}
-		b[i] = letters[idx.Int64()]
+// checkLockout returns domain.ErrTooMany while key is locked out.
+func (s *service) checkLockout(ctx context.Context, key string) error {
+	if s.attempts == nil {
+		return nil
+	}
+	until, err := s.attempts.LockedUntil(ctx, key)
+	if err != nil {
+		return err
+	}
+	if wait := time.Until(time.Unix(until, 0)); wait > 0 {
+		return fmt.Errorf("too many failed attempts, try again in %s: %w", wait.Round(time.Second), domain.ErrTooMany)
+	}
+	return nil
+}
+
+// recordFailure counts a wrong code and, past freeCodeAttempts, locks key for
+// a backoff that doubles with every further failure.
+func (s *service) recordFailure(ctx context.Context, key string) {
+	if s.attempts == nil {
+		return
+	}
+	now := time.Now()
+	n, err := s.attempts.Increment(ctx, key, now.Add(codeAttemptsTTL).Unix())
+	if err != nil {
+		slog.Warn("failed to record code attempt", "key", key, "err", err)
+		return
+	}
+	if n <= freeCodeAttempts {
+		return
+	}
+	backoff := codeBackoffMax
+	if shift := n - freeCodeAttempts - 1; shift < 8 {
+		backoff = min(codeBackoffBase<<shift, codeBackoffMax)
+	}
+	if err := s.attempts.Lock(ctx, key, now.Add(backoff).Unix()); err != nil {
+		slog.Warn("failed to lock code attempts", "key", key, "err", err)
+	}
+}
+
+// trustDevice marks d as trusted. Failures are logged rather than returned:
+// the device simply stays untrusted and gets the shorter session lifetime.
+func (s *service) trustDevice(ctx context.Context, d *domain.Device) {
+	if d.Trusted {
+		return
+	}
+	if err := s.deviceRepo.Update(ctx, d.DeviceID, map[string]interface{}{fieldTrusted: true}); err != nil {
+		slog.Warn("failed to mark device as trusted", "device_id", d.DeviceID, "err", err)
+		return
 	}
-	return string(b), nil
+	d.Trusted = true
 }
@@ -3,10 +3,13 @@ package auth
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
+	"github.com/go-api-nosql/internal/pkg/password"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -61,8 +64,8 @@ func (m *mockSessionStore) SoftDeleteByUser(ctx context.Context, userID string)
 
 type mockDeviceStore struct{ mock.Mock }
 
-func (m *mockDeviceStore) GetByUUID(ctx context.Context, uuid string) (*domain.Device, error) {
-	args := m.Called(ctx, uuid)
+func (m *mockDeviceStore) GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error) {
+	args := m.Called(ctx, uuid, userID)
 	if d, _ := args.Get(0).(*domain.Device); d != nil {
 		return d, args.Error(1)
 	}
@@ -72,6 +75,10 @@ func (m *mockDeviceStore) Put(ctx context.Context, d *domain.Device) error {
 	return m.Called(ctx, d).Error(0)
 }
 
+func (m *mockDeviceStore) Update(ctx context.Context, deviceID string, updates map[string]interface{}) error {
+	return m.Called(ctx, deviceID, updates).Error(0)
+}
+
 type mockMailer struct{ mock.Mock }
 
 func (m *mockMailer) SendEmail(to, subject, body string) error {
@@ -86,7 +93,7 @@ func (m *mockSMSSender) SendSMS(ctx context.Context, phone, msg string) error {
 
 type mockJWTSigner struct{ mock.Mock }
 
-func (m *mockJWTSigner) Sign(userID, deviceID, role, sessionID string) (string, error) {
+func (m *mockJWTSigner) Sign(_ context.Context, userID, deviceID, role, sessionID string) (string, error) {
 	args := m.Called(userID, deviceID, role, sessionID)
 	return args.String(0), args.Error(1)
 }
@@ -121,6 +128,45 @@ func TestRequestPasswordRecovery_EmailNotFound(t *testing.T) {
 	assert.True(t, errors.Is(err, domain.ErrNotFound))
 }
 
+func TestRequestPasswordRecovery_AntiEnumerationUnknownEmailSucceeds(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("GetByEmail", mock.Anything, "x@x.com").Return(nil, domain.ErrNotFound)
+
+	svc := NewService(ServiceDeps{UserRepo: us, AntiEnumeration: true})
+	err := svc.RequestPasswordRecovery(context.Background(), PasswordRecoveryRequest{
+		Email: strPtr("x@x.com"),
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestRequestPasswordRecovery_AntiEnumerationEmailOutlivesTheRequest(t *testing.T) {
+	vs := &mockVerificationStore{}
+	us := &mockUserStore{}
+	ml := &mockMailer{}
+	us.On("GetByEmail", mock.Anything, "a@b.com").Return(&domain.User{UserID: "u1", Email: "a@b.com"}, nil)
+	vs.On("Get", mock.Anything, "u1", "otp").Return(nil, domain.ErrNotFound)
+	vs.On("Put", mock.Anything, mock.Anything).Return(nil)
+	release := make(chan struct{})
+	ml.On("SendEmail", "a@b.com", "Password Recovery OTP", mock.Anything).
+		Run(func(mock.Arguments) { <-release }).Return(nil)
+	background := lifecycle.New(context.Background())
+	svc := NewService(ServiceDeps{VerificationRepo: vs, UserRepo: us, Mailer: ml, AntiEnumeration: true, Background: background.Context()})
+
+	require.NoError(t, svc.RequestPasswordRecovery(context.Background(), PasswordRecoveryRequest{Email: strPtr("a@b.com")}))
+
+	stopped := make(chan error)
+	go func() { stopped <- background.Shutdown(context.Background()) }()
+	select {
+	case <-stopped:
+		t.Fatal("shutdown finished while the recovery email was still being sent")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	require.NoError(t, <-stopped)
+	ml.AssertExpectations(t)
+}
+
 func TestRequestPasswordRecovery_PhoneBranch_ReturnsBadRequest(t *testing.T) {
 	svc := newService(nil, nil, nil, nil, nil, nil, nil)
 	phone := "5551234"
@@ -169,6 +215,36 @@ func TestValidateOTP_NoEmail_ReturnsBadRequest(t *testing.T) {
 	assert.True(t, errors.Is(err, domain.ErrBadRequest))
 }
 
+func TestValidateOTP_WeakPasswordKeepsTheOTP(t *testing.T) {
+	us := &mockUserStore{}
+	svc := NewService(ServiceDeps{UserRepo: us, PasswordPolicy: password.Policy{MinLength: 12}})
+	_, err := svc.ValidateOTP(context.Background(), ValidateOTPRequest{
+		OTP:         "123456",
+		Email:       strPtr("a@b.com"),
+		NewPassword: "short",
+	})
+	assert.ErrorIs(t, err, domain.ErrWeakPassword)
+	us.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+}
+
+// breachedPasswords reports every password in it as breached.
+type breachedPasswords map[string]bool
+
+func (b breachedPasswords) Breached(_ context.Context, pw string) (bool, error) { return b[pw], nil }
+
+func TestValidateOTP_BreachedPasswordKeepsTheOTP(t *testing.T) {
+	us := &mockUserStore{}
+	policy := password.Policy{MinLength: 8, Breaches: breachedPasswords{"Summer2024!": true}}
+	svc := NewService(ServiceDeps{UserRepo: us, PasswordPolicy: policy})
+	_, err := svc.ValidateOTP(context.Background(), ValidateOTPRequest{
+		OTP:         "123456",
+		Email:       strPtr("a@b.com"),
+		NewPassword: "Summer2024!",
+	})
+	assert.ErrorIs(t, err, domain.ErrBreachedPassword)
+	us.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+}
+
 func TestValidateOTP_UserNotFound(t *testing.T) {
 	us := &mockUserStore{}
 	us.On("GetByEmail", mock.Anything, "a@b.com").Return(nil, domain.ErrNotFound)
@@ -256,8 +332,9 @@ func TestValidateOTP_HappyPath(t *testing.T) {
 		_, ok := m[fieldPasswordHash]
 		return ok
 	})).Return(nil)
-	ds.On("GetByUUID", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+	ds.On("GetByUUID", mock.Anything, mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
 	ds.On("Put", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+	ds.On("Update", mock.Anything, mock.Anything, map[string]interface{}{fieldTrusted: true}).Return(nil)
 	ss.On("SoftDeleteByUser", mock.Anything, "u1").Return(nil)
 	ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
 	jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer-token", nil)
@@ -272,6 +349,212 @@ func TestValidateOTP_HappyPath(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "bearer-token", result.Bearer)
 	assert.NotEmpty(t, result.RefreshToken)
+	ds.AssertCalled(t, "Update", mock.Anything, mock.Anything, map[string]interface{}{fieldTrusted: true})
 }
 
 func strPtr(s string) *string { return &s }
+
+// --- code attempt throttling ---
+
+type memAttempts struct {
+	counts map[string]int64
+	locks  map[string]int64
+}
+
+func newMemAttempts() *memAttempts {
+	return &memAttempts{counts: map[string]int64{}, locks: map[string]int64{}}
+}
+
+func (m *memAttempts) Increment(_ context.Context, key string, _ int64) (int64, error) {
+	m.counts[key]++
+	return m.counts[key], nil
+}
+func (m *memAttempts) Delete(_ context.Context, key string) error {
+	delete(m.counts, key)
+	delete(m.locks, key)
+	return nil
+}
+func (m *memAttempts) Lock(_ context.Context, key string, until int64) error {
+	m.locks[key] = until
+	return nil
+}
+func (m *memAttempts) LockedUntil(_ context.Context, key string) (int64, error) {
+	return m.locks[key], nil
+}
+
+func newThrottledService(vs *mockVerificationStore, us *mockUserStore, attempts *memAttempts) Service {
+	return NewService(ServiceDeps{VerificationRepo: vs, UserRepo: us, Attempts: attempts})
+}
+
+func TestValidateEmailToken_LocksOutAfterRepeatedFailures(t *testing.T) {
+	vs := &mockVerificationStore{}
+	vs.On("Get", mock.Anything, "u1", "email").Return(&domain.UserVerification{
+		Code:      "right",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}, nil)
+	attempts := newMemAttempts()
+	svc := newThrottledService(vs, &mockUserStore{}, attempts)
+
+	for range freeCodeAttempts {
+		err := svc.ValidateEmailToken(context.Background(), "u1", "wrong")
+		require.ErrorIs(t, err, domain.ErrUnauthorized)
+	}
+	assert.Empty(t, attempts.locks, "no lockout within the free attempts")
+
+	err := svc.ValidateEmailToken(context.Background(), "u1", "wrong")
+	require.ErrorIs(t, err, domain.ErrUnauthorized)
+	until := attempts.locks["code:email:u1"]
+	assert.InDelta(t, time.Now().Add(codeBackoffBase).Unix(), until, 2)
+
+	// Even the right token is refused while locked out.
+	err = svc.ValidateEmailToken(context.Background(), "u1", "right")
+	assert.ErrorIs(t, err, domain.ErrTooMany)
+}
+
+func TestValidatePhoneOTP_SuccessResetsAttempts(t *testing.T) {
+	vs := &mockVerificationStore{}
+	us := &mockUserStore{}
+	vs.On("Get", mock.Anything, "u1", "phone").Return(&domain.UserVerification{
+		Code:      "123456",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}, nil)
+	vs.On("Delete", mock.Anything, "u1", "phone").Return(nil)
+	us.On("Update", mock.Anything, "u1", map[string]interface{}{fieldPhoneConfirmed: true}).Return(nil)
+	attempts := newMemAttempts()
+	attempts.counts["code:phone:u1"] = 3
+
+	err := newThrottledService(vs, us, attempts).ValidatePhoneOTP(context.Background(), "u1", "", "123456")
+
+	require.NoError(t, err)
+	assert.NotContains(t, attempts.counts, "code:phone:u1")
+}
+
+// --- code hashing ---
+
+func TestRequestEmailConfirmation_StoresOnlyTheHash(t *testing.T) {
+	vs := &mockVerificationStore{}
+	us := &mockUserStore{}
+	ml := &mockMailer{}
+	var stored *domain.UserVerification
+	vs.On("Get", mock.Anything, "u1", "email").Return(nil, domain.ErrNotFound).Once()
+	vs.On("Put", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*domain.UserVerification)
+	}).Return(nil)
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Email: "a@b.com"}, nil)
+	var body string
+	ml.On("SendEmail", "a@b.com", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		body = args.String(2)
+	}).Return(nil)
+	svc := NewService(ServiceDeps{VerificationRepo: vs, UserRepo: us, Mailer: ml, CodeSecret: []byte("k1")})
+
+	require.NoError(t, svc.RequestEmailConfirmation(context.Background(), "u1"))
+
+	require.NotNil(t, stored)
+	assert.Empty(t, stored.Code)
+	assert.NotContains(t, body, stored.CodeHash)
+	token := strings.TrimPrefix(strings.SplitN(body, "\n", 2)[0], "Your email confirmation token is: ")
+	vs.On("Get", mock.Anything, "u1", "email").Return(stored, nil)
+	vs.On("Delete", mock.Anything, "u1", "email").Return(nil)
+	us.On("Update", mock.Anything, "u1", map[string]interface{}{fieldEmailConfirmed: true}).Return(nil)
+
+	other := NewService(ServiceDeps{VerificationRepo: vs, UserRepo: us, CodeSecret: []byte("k2")})
+	assert.ErrorIs(t, other.ValidateEmailToken(context.Background(), "u1", token), domain.ErrUnauthorized,
+		"a different secret does not verify")
+	assert.NoError(t, svc.ValidateEmailToken(context.Background(), "u1", token))
+}
+
+// --- resend cooldown ---
+
+func TestResendEmailConfirmation_TooSoonReportsWait(t *testing.T) {
+	vs := &mockVerificationStore{}
+	now := time.Now()
+	vs.On("Get", mock.Anything, "u1", "email").Return(&domain.UserVerification{
+		CreatedAt: now.Add(-20 * time.Second).Unix(), ExpiresAt: now.Add(time.Hour).Unix(),
+	}, nil)
+	svc := NewService(ServiceDeps{VerificationRepo: vs, ResendCooldown: time.Minute})
+
+	err := svc.ResendEmailConfirmation(context.Background(), "u1")
+
+	var cd *CooldownError
+	require.ErrorAs(t, err, &cd)
+	assert.ErrorIs(t, err, domain.ErrTooMany)
+	assert.InDelta(t, 40, cd.Seconds(), 1)
+	vs.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestResendPhoneConfirmation_AfterCooldownReplacesCode(t *testing.T) {
+	vs := &mockVerificationStore{}
+	us := &mockUserStore{}
+	sms := &mockSMSSender{}
+	phone := "+15550100"
+	now := time.Now()
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Phone: &phone}, nil)
+	vs.On("Get", mock.Anything, "u1", "phone").Return(&domain.UserVerification{
+		CreatedAt: now.Add(-2 * time.Minute).Unix(), ExpiresAt: now.Add(time.Hour).Unix(),
+	}, nil)
+	var stored *domain.UserVerification
+	vs.On("Put", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*domain.UserVerification)
+	}).Return(nil)
+	sms.On("SendSMS", mock.Anything, phone, mock.Anything).Return(nil)
+	svc := NewService(ServiceDeps{VerificationRepo: vs, UserRepo: us, SMSSender: sms, ResendCooldown: time.Minute})
+
+	require.NoError(t, svc.ResendPhoneConfirmation(context.Background(), "u1"))
+	require.NotNil(t, stored)
+	assert.GreaterOrEqual(t, stored.CreatedAt, now.Unix())
+	sms.AssertExpectations(t)
+}
+
+// --- device verification ---
+
+func TestDeviceVerification_EmailedOTPTrustsTheDevice(t *testing.T) {
+	vs := &mockVerificationStore{}
+	us := &mockUserStore{}
+	ds := &mockDeviceStore{}
+	ml := &mockMailer{}
+	var stored *domain.UserVerification
+	vs.On("Get", mock.Anything, "u1", "device").Return(nil, domain.ErrNotFound).Once()
+	vs.On("Put", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*domain.UserVerification)
+	}).Return(nil)
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Email: "a@b.com"}, nil)
+	var body string
+	ml.On("SendEmail", "a@b.com", "Verify your device", mock.Anything).Run(func(args mock.Arguments) {
+		body = args.String(2)
+	}).Return(nil)
+	ds.On("Update", mock.Anything, "dev1", map[string]interface{}{fieldTrusted: true}).Return(nil)
+	svc := NewService(ServiceDeps{VerificationRepo: vs, UserRepo: us, DeviceRepo: ds, Mailer: ml, CodeSecret: []byte("k1")})
+
+	require.NoError(t, svc.RequestDeviceVerification(context.Background(), "u1"))
+	require.NotNil(t, stored)
+	assert.Equal(t, "device", stored.Type)
+	otp := strings.TrimPrefix(strings.SplitN(body, "\n", 2)[0], "Your device verification code is: ")
+	vs.On("Get", mock.Anything, "u1", "device").Return(stored, nil)
+	vs.On("Delete", mock.Anything, "u1", "device").Return(nil)
+
+	assert.ErrorIs(t, svc.ValidateDeviceOTP(context.Background(), "u1", "dev1", "WRONG1"), domain.ErrUnauthorized)
+	ds.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	require.NoError(t, svc.ValidateDeviceOTP(context.Background(), "u1", "dev1", otp))
+	ds.AssertExpectations(t)
+}
+
+func TestRequestDeviceVerification_PendingCodeIsNotReplaced(t *testing.T) {
+	vs := &mockVerificationStore{}
+	vs.On("Get", mock.Anything, "u1", "device").Return(&domain.UserVerification{
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}, nil)
+	svc := NewService(ServiceDeps{VerificationRepo: vs})
+
+	err := svc.RequestDeviceVerification(context.Background(), "u1")
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	vs.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestValidateDeviceOTP_SessionWithoutDevice(t *testing.T) {
+	svc := NewService(ServiceDeps{})
+
+	err := svc.ValidateDeviceOTP(context.Background(), "u1", "", "ABC234")
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
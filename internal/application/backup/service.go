@@ -0,0 +1,72 @@
+package backup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// maxRestoreLag is how far the latest restorable time may trail the check
+// before a table counts as unhealthy. DynamoDB keeps it about five minutes
+// behind while PITR works.
+const maxRestoreLag = time.Hour
+
+// Service verifies that every table can be restored to a recent point in time.
+type Service interface {
+	// Check reads every table's backup status and remembers the report.
+	Check(ctx context.Context) *domain.BackupReport
+	// Last returns the most recent report, or nil before the first Check.
+	Last() *domain.BackupReport
+}
+
+// StatusReader reads one table's PITR status.
+type StatusReader interface {
+	TableBackup(ctx context.Context, table string) (domain.TableBackup, error)
+}
+
+type service struct {
+	reader StatusReader
+	tables []string
+	now    func() time.Time
+
+	mu   sync.Mutex
+	last *domain.BackupReport
+}
+
+func NewService(reader StatusReader, tables []string) Service {
+	return &service{reader: reader, tables: tables, now: time.Now}
+}
+
+// Check never fails: a table that cannot be read is reported as a problem,
+// since an unreadable backup status is as worrying as a disabled one.
+func (s *service) Check(ctx context.Context) *domain.BackupReport {
+	now := s.now().UTC()
+	report := &domain.BackupReport{Healthy: true, CheckedAt: now, Tables: make([]domain.TableBackup, 0, len(s.tables))}
+	for _, table := range s.tables {
+		tb, err := s.reader.TableBackup(ctx, table)
+		switch {
+		case err != nil:
+			tb.Problem = "status unavailable: " + err.Error()
+		case !tb.PITREnabled:
+			tb.Problem = "point-in-time recovery is disabled"
+		case tb.LatestRestorableAt == nil || now.Sub(*tb.LatestRestorableAt) > maxRestoreLag:
+			tb.Problem = "latest restorable time is more than " + maxRestoreLag.String() + " old"
+		}
+		if tb.Problem != "" {
+			report.Healthy = false
+		}
+		report.Tables = append(report.Tables, tb)
+	}
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+	return report
+}
+
+func (s *service) Last() *domain.BackupReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
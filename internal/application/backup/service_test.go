@@ -0,0 +1,55 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubReader map[string]domain.TableBackup
+
+func (s stubReader) TableBackup(_ context.Context, table string) (domain.TableBackup, error) {
+	tb, ok := s[table]
+	if !ok {
+		return domain.TableBackup{Table: table}, errors.New("ResourceNotFoundException")
+	}
+	return tb, nil
+}
+
+func TestCheck_Healthy(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-5 * time.Minute)
+	svc := NewService(stubReader{"users": {Table: "users", PITREnabled: true, LatestRestorableAt: &recent}}, []string{"users"}).(*service)
+	svc.now = func() time.Time { return now }
+	assert.Nil(t, svc.Last())
+
+	report := svc.Check(context.Background())
+
+	assert.True(t, report.Healthy)
+	require.Len(t, report.Tables, 1)
+	assert.Empty(t, report.Tables[0].Problem)
+	assert.Same(t, report, svc.Last())
+}
+
+func TestCheck_ReportsProblems(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stale := now.Add(-2 * time.Hour)
+	svc := NewService(stubReader{
+		"users":    {Table: "users", PITREnabled: true, LatestRestorableAt: &stale},
+		"sessions": {Table: "sessions"},
+	}, []string{"users", "sessions", "missing"}).(*service)
+	svc.now = func() time.Time { return now }
+
+	report := svc.Check(context.Background())
+
+	assert.False(t, report.Healthy)
+	require.Len(t, report.Tables, 3)
+	assert.Contains(t, report.Tables[0].Problem, "latest restorable time")
+	assert.Equal(t, "point-in-time recovery is disabled", report.Tables[1].Problem)
+	assert.Contains(t, report.Tables[2].Problem, "status unavailable")
+}
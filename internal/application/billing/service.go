@@ -0,0 +1,169 @@
+// Package billing keeps users' plans in step with their paid subscriptions
+// and opens the payment provider's customer portal.
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+)
+
+// ActorStripe is the audit actor of plan changes made by subscription events.
+const ActorStripe = "stripe"
+
+// DynamoDB attribute names used in partial update maps.
+const (
+	fieldStripeCustomer = "stripe_customer_id"
+	fieldBillingEventAt = "billing_event_at"
+)
+
+// paidStatuses keep the subscription's plan. past_due is included so a
+// failed renewal is not downgraded while the provider retries the payment.
+var paidStatuses = map[string]bool{"active": true, "trialing": true, "past_due": true}
+
+type Service interface {
+	// HandleWebhook verifies and applies a webhook delivery. Events for
+	// unknown users are logged and acknowledged; an error asks the provider
+	// to deliver again later.
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+	// PortalURL returns a customer-portal link for userID. Users who never
+	// subscribed get a domain.ErrNotFound error.
+	PortalURL(ctx context.Context, userID string) (string, error)
+}
+
+type eventParser interface {
+	ParseSubscriptionEvent(payload []byte, header string) (*domain.SubscriptionEvent, error)
+}
+
+type portalOpener interface {
+	PortalURL(ctx context.Context, customerID, returnURL string) (string, error)
+}
+
+type planAssigner interface {
+	Assign(ctx context.Context, actorID, userID, name string) (*domain.User, error)
+}
+
+type userStore interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+	Update(ctx context.Context, userID string, updates map[string]interface{}) error
+}
+
+type ServiceDeps struct {
+	Events     eventParser
+	Portal     portalOpener
+	Plans      planAssigner
+	UserRepo   userStore
+	PricePlans map[string]string // price ID -> plan name
+	ReturnURL  string
+}
+
+type service struct {
+	events     eventParser
+	portal     portalOpener
+	plans      planAssigner
+	users      userStore
+	pricePlans map[string]string
+	returnURL  string
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{
+		events:     deps.Events,
+		portal:     deps.Portal,
+		plans:      deps.Plans,
+		users:      deps.UserRepo,
+		pricePlans: deps.PricePlans,
+		returnURL:  deps.ReturnURL,
+	}
+}
+
+// ParsePricePlans reads "price_id=plan" pairs into a map.
+func ParsePricePlans(pairs []string) (map[string]string, error) {
+	out := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		price, plan, ok := strings.Cut(p, "=")
+		if !ok || price == "" || plan == "" {
+			return nil, fmt.Errorf("price plan %q must be price_id=plan", p)
+		}
+		out[strings.TrimSpace(price)] = strings.TrimSpace(plan)
+	}
+	return out, nil
+}
+
+func (s *service) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	ev, err := s.events.ParseSubscriptionEvent(payload, signature)
+	if err != nil || ev == nil {
+		return err
+	}
+	if ev.UserID == "" {
+		slog.Warn("subscription event without user_id metadata", "event_id", ev.EventID, "customer", ev.CustomerID)
+		return nil
+	}
+	if ev.TenantID != "" {
+		if !tenant.Valid(ev.TenantID) {
+			slog.Warn("subscription event with invalid tenant_id metadata", "event_id", ev.EventID, "tenant_id", ev.TenantID)
+			return nil
+		}
+		ctx = tenant.WithID(ctx, ev.TenantID)
+	}
+	u, err := s.users.Get(ctx, ev.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		slog.Warn("subscription event for unknown user", "event_id", ev.EventID, "user_id", ev.UserID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.BillingEventAt > ev.Created.Unix() {
+		slog.Info("ignoring out-of-order subscription event", "event_id", ev.EventID, "user_id", ev.UserID)
+		return nil
+	}
+	return s.apply(ctx, u, ev)
+}
+
+// apply moves u to the plan ev maps to and records the customer and event time.
+func (s *service) apply(ctx context.Context, u *domain.User, ev *domain.SubscriptionEvent) error {
+	plan, err := s.planFor(ev)
+	if err != nil {
+		return err
+	}
+	if plan != u.Plan && !(plan == domain.PlanFree && u.Plan == "") {
+		if _, err := s.plans.Assign(ctx, ActorStripe, u.UserID, plan); err != nil {
+			return err
+		}
+	}
+	return s.users.Update(ctx, u.UserID, map[string]interface{}{
+		fieldStripeCustomer: ev.CustomerID,
+		fieldBillingEventAt: ev.Created.Unix(),
+	})
+}
+
+// planFor maps a live subscription's price to its plan. Ended and lapsed
+// subscriptions fall back to domain.PlanFree. An unmapped price is an error,
+// so the provider keeps redelivering until the mapping is configured.
+func (s *service) planFor(ev *domain.SubscriptionEvent) (string, error) {
+	if ev.Ended || !paidStatuses[ev.Status] {
+		return domain.PlanFree, nil
+	}
+	plan, ok := s.pricePlans[ev.PriceID]
+	if !ok {
+		return "", fmt.Errorf("no plan configured for price %q", ev.PriceID)
+	}
+	return plan, nil
+}
+
+func (s *service) PortalURL(ctx context.Context, userID string) (string, error) {
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if u.StripeCustomer == "" {
+		return "", fmt.Errorf("no billing account: %w", domain.ErrNotFound)
+	}
+	return s.portal.PortalURL(ctx, u.StripeCustomer, s.returnURL)
+}
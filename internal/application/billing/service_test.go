@@ -0,0 +1,142 @@
+package billing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- stubs ---
+
+type fixedEvent struct{ ev *domain.SubscriptionEvent }
+
+func (f fixedEvent) ParseSubscriptionEvent([]byte, string) (*domain.SubscriptionEvent, error) {
+	return f.ev, nil
+}
+
+type memUsers map[string]*domain.User
+
+func (m memUsers) Get(_ context.Context, userID string) (*domain.User, error) {
+	u, ok := m[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *u
+	return &c, nil
+}
+
+func (m memUsers) Update(_ context.Context, userID string, updates map[string]interface{}) error {
+	u := m[userID]
+	u.StripeCustomer, _ = updates[fieldStripeCustomer].(string)
+	u.BillingEventAt, _ = updates[fieldBillingEventAt].(int64)
+	return nil
+}
+
+// userPlans assigns plans straight onto memUsers.
+type userPlans struct {
+	users    memUsers
+	assigned []string
+}
+
+func (p *userPlans) Assign(_ context.Context, actorID, userID, name string) (*domain.User, error) {
+	p.users[userID].Plan = name
+	p.assigned = append(p.assigned, actorID+":"+name)
+	return p.users[userID], nil
+}
+
+type recordingPortal struct{ customer string }
+
+func (p *recordingPortal) PortalURL(_ context.Context, customerID, _ string) (string, error) {
+	p.customer = customerID
+	return "https://portal/" + customerID, nil
+}
+
+var created = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(ev *domain.SubscriptionEvent, users memUsers) (*service, *userPlans) {
+	plans := &userPlans{users: users}
+	svc := NewService(ServiceDeps{
+		Events:     fixedEvent{ev},
+		Portal:     &recordingPortal{},
+		Plans:      plans,
+		UserRepo:   users,
+		PricePlans: map[string]string{"price_pro": "pro"},
+	})
+	return svc.(*service), plans
+}
+
+// --- tests ---
+
+func TestHandleWebhook_ActiveSubscriptionAssignsPlan(t *testing.T) {
+	users := memUsers{"u1": {UserID: "u1"}}
+	ev := &domain.SubscriptionEvent{UserID: "u1", CustomerID: "cus_1", Status: "active", PriceID: "price_pro", Created: created}
+	svc, plans := newTestService(ev, users)
+
+	require.NoError(t, svc.HandleWebhook(context.Background(), nil, ""))
+
+	assert.Equal(t, []string{"stripe:pro"}, plans.assigned)
+	assert.Equal(t, "cus_1", users["u1"].StripeCustomer)
+	assert.Equal(t, created.Unix(), users["u1"].BillingEventAt)
+}
+
+func TestHandleWebhook_EndedSubscriptionFallsBackToFree(t *testing.T) {
+	users := memUsers{"u1": {UserID: "u1", Plan: "pro"}}
+	ev := &domain.SubscriptionEvent{UserID: "u1", Status: "canceled", PriceID: "price_pro", Ended: true, Created: created}
+	svc, plans := newTestService(ev, users)
+
+	require.NoError(t, svc.HandleWebhook(context.Background(), nil, ""))
+
+	assert.Equal(t, []string{"stripe:" + domain.PlanFree}, plans.assigned)
+}
+
+func TestHandleWebhook_IgnoresOutOfOrderEvents(t *testing.T) {
+	users := memUsers{"u1": {UserID: "u1", Plan: "pro", BillingEventAt: created.Add(time.Minute).Unix()}}
+	ev := &domain.SubscriptionEvent{UserID: "u1", Status: "canceled", Ended: true, Created: created}
+	svc, plans := newTestService(ev, users)
+
+	require.NoError(t, svc.HandleWebhook(context.Background(), nil, ""))
+
+	assert.Empty(t, plans.assigned)
+	assert.Equal(t, "pro", users["u1"].Plan)
+}
+
+func TestHandleWebhook_UnmappedPriceIsRetried(t *testing.T) {
+	users := memUsers{"u1": {UserID: "u1"}}
+	ev := &domain.SubscriptionEvent{UserID: "u1", Status: "active", PriceID: "price_new", Created: created}
+	svc, plans := newTestService(ev, users)
+
+	assert.Error(t, svc.HandleWebhook(context.Background(), nil, ""))
+	assert.Empty(t, plans.assigned)
+}
+
+func TestHandleWebhook_UnknownUserIsAcknowledged(t *testing.T) {
+	ev := &domain.SubscriptionEvent{UserID: "ghost", Status: "active", PriceID: "price_pro", Created: created}
+	svc, _ := newTestService(ev, memUsers{})
+
+	assert.NoError(t, svc.HandleWebhook(context.Background(), nil, ""))
+}
+
+func TestPortalURL_RequiresBillingAccount(t *testing.T) {
+	users := memUsers{"u1": {UserID: "u1"}, "u2": {UserID: "u2", StripeCustomer: "cus_2"}}
+	svc, _ := newTestService(nil, users)
+	ctx := context.Background()
+
+	_, err := svc.PortalURL(ctx, "u1")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	url, err := svc.PortalURL(ctx, "u2")
+	require.NoError(t, err)
+	assert.Equal(t, "https://portal/cus_2", url)
+}
+
+func TestParsePricePlans_RejectsMalformedPairs(t *testing.T) {
+	got, err := ParsePricePlans([]string{"price_a=pro", "price_b = team"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"price_a": "pro", "price_b": "team"}, got)
+
+	_, err = ParsePricePlans([]string{"price_a"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package birthday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// sendHour is the local hour from which birthday notifications go out.
+const sendHour = 9
+
+// Service sends users a notification on their birthday, a sample of
+// date-based automation run by the job scheduler.
+type Service interface {
+	// Run notifies every enabled user whose birthday it is where they live,
+	// once that day has reached sendHour, and returns how many it notified.
+	// Each user is notified at most once a year.
+	Run(ctx context.Context) (int, error)
+}
+
+type userStore interface {
+	EachMatching(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error
+	ClaimBirthday(ctx context.Context, userID, year string) (bool, error)
+}
+
+// notifier is notification.Service's Create.
+type notifier interface {
+	Create(ctx context.Context, req domain.CreateNotificationRequest) (*domain.Notification, error)
+}
+
+type service struct {
+	users         userStore
+	notifications notifier
+	now           func() time.Time
+}
+
+type ServiceDeps struct {
+	Users         userStore
+	Notifications notifier
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{users: deps.Users, notifications: deps.Notifications, now: time.Now}
+}
+
+func (s *service) Run(ctx context.Context) (int, error) {
+	enabled := 1
+	sent := 0
+	var errs []error
+	err := s.users.EachMatching(ctx, domain.UserFilter{Enable: &enabled}, func(u domain.User) error {
+		local := s.now().In(u.Location())
+		if u.DeletedAt != nil || local.Hour() < sendHour || !u.IsBirthday(local) {
+			return nil
+		}
+		year := strconv.Itoa(local.Year())
+		if u.BirthdayGreeted == year {
+			return nil
+		}
+		ok, err := s.greet(ctx, &u, year)
+		if ok {
+			sent++
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("birthday of %s: %w", u.UserID, err))
+		}
+		return ctx.Err()
+	})
+	return sent, errors.Join(append(errs, err)...)
+}
+
+// greet claims u's birthday for year and sends the notification, from the
+// birthday template when there is one. A failed send is not retried.
+func (s *service) greet(ctx context.Context, u *domain.User, year string) (bool, error) {
+	won, err := s.users.ClaimBirthday(ctx, u.UserID, year)
+	if err != nil || !won {
+		return false, err
+	}
+	req := domain.CreateNotificationRequest{
+		UserID:   u.UserID,
+		Template: domain.BirthdayTemplate,
+		Params:   map[string]string{"first_name": u.FirstName, "username": u.Username},
+	}
+	_, err = s.notifications.Create(ctx, req)
+	if errors.Is(err, domain.ErrNotFound) {
+		_, err = s.notifications.Create(ctx, domain.CreateNotificationRequest{
+			UserID:  u.UserID,
+			Message: "Happy birthday, " + greeting(u) + "!",
+		})
+	}
+	return err == nil, err
+}
+
+func greeting(u *domain.User) string {
+	if u.FirstName != "" {
+		return u.FirstName
+	}
+	return u.Username
+}
@@ -0,0 +1,92 @@
+package birthday
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUsers struct {
+	users   []domain.User
+	greeted map[string]string // userID -> year
+}
+
+func (s *stubUsers) EachMatching(_ context.Context, _ domain.UserFilter, fn func(domain.User) error) error {
+	for _, u := range s.users {
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubUsers) ClaimBirthday(_ context.Context, userID, year string) (bool, error) {
+	if s.greeted[userID] == year {
+		return false, nil
+	}
+	s.greeted[userID] = year
+	return true, nil
+}
+
+type stubNotifier struct {
+	templates map[string]bool
+	sent      []domain.CreateNotificationRequest
+}
+
+func (n *stubNotifier) Create(_ context.Context, req domain.CreateNotificationRequest) (*domain.Notification, error) {
+	if req.Template != "" && !n.templates[req.Template] {
+		return nil, domain.ErrNotFound
+	}
+	n.sent = append(n.sent, req)
+	return &domain.Notification{UserID: req.UserID}, nil
+}
+
+// newTestService returns a service whose clock reads 2026-03-14 12:00 UTC.
+func newTestService(users *stubUsers, n *stubNotifier) *service {
+	svc := NewService(ServiceDeps{Users: users, Notifications: n}).(*service)
+	svc.now = func() time.Time { return time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func born(id string, month time.Month, day int) domain.User {
+	return domain.User{UserID: id, Username: id, FirstName: "Ada", Enable: 1, Birthday: time.Date(1990, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestRun_NotifiesOnLocalBirthdayOncePerYear(t *testing.T) {
+	today := born("today", time.March, 14)
+	tomorrow := born("tomorrow", time.March, 15)
+	early := born("early", time.March, 14)
+	early.Timezone = "America/Los_Angeles" // 05:00 there
+	ahead := born("ahead", time.March, 15)
+	ahead.Timezone = "Pacific/Kiritimati" // already March 15, 02:00
+	users := &stubUsers{users: []domain.User{today, tomorrow, early, ahead}, greeted: map[string]string{}}
+	n := &stubNotifier{}
+	svc := newTestService(users, n)
+
+	sent, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	again, err := svc.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, sent)
+	assert.Zero(t, again)
+	require.Len(t, n.sent, 1)
+	assert.Equal(t, "today", n.sent[0].UserID)
+	assert.Equal(t, "Happy birthday, Ada!", n.sent[0].Message)
+}
+
+func TestRun_UsesBirthdayTemplate(t *testing.T) {
+	users := &stubUsers{users: []domain.User{born("u1", time.March, 14)}, greeted: map[string]string{}}
+	n := &stubNotifier{templates: map[string]bool{domain.BirthdayTemplate: true}}
+
+	_, err := newTestService(users, n).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, n.sent, 1)
+	assert.Equal(t, domain.BirthdayTemplate, n.sent[0].Template)
+	assert.Equal(t, "Ada", n.sent[0].Params["first_name"])
+}
@@ -0,0 +1,382 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+)
+
+const (
+	defaultPageSize = 100
+	defaultLease    = 2 * time.Minute
+	// previewSample is how many users of a segment Preview checks for a
+	// device platform before extrapolating.
+	previewSample = 200
+)
+
+// Service creates announcements and fans them out to the enabled users of
+// their segment.
+type Service interface {
+	Create(ctx context.Context, actorID string, req domain.CreateBroadcastRequest) (*domain.Broadcast, error)
+	// Preview estimates how many users a broadcast to seg would reach.
+	Preview(ctx context.Context, seg domain.BroadcastSegment) (*domain.BroadcastPreview, error)
+	// List returns the broadcasts with the given status, or all of them when
+	// status is empty, newest first.
+	List(ctx context.Context, status string) ([]domain.Broadcast, error)
+	Get(ctx context.Context, broadcastID string) (*domain.Broadcast, error)
+	// Cancel stops a pending or running broadcast. Users already reached keep
+	// their notification; a broadcast that has ended is domain.ErrConflict.
+	Cancel(ctx context.Context, actorID, broadcastID string) (*domain.Broadcast, error)
+	// Run advances every pending or running broadcast that no other replica
+	// holds, resuming each from its stored cursor.
+	Run(ctx context.Context) error
+}
+
+type broadcastStore interface {
+	Put(ctx context.Context, b *domain.Broadcast) error
+	Get(ctx context.Context, broadcastID string) (*domain.Broadcast, error)
+	List(ctx context.Context, status string) ([]domain.Broadcast, error)
+	Claim(ctx context.Context, broadcastID, leaseID string, until int64) (*domain.Broadcast, error)
+	Advance(ctx context.Context, broadcastID, leaseID string, updates map[string]interface{}) (*domain.Broadcast, error)
+	Close(ctx context.Context, broadcastID string, from []string, updates map[string]interface{}) error
+}
+
+type userPager interface {
+	QueryPage(ctx context.Context, f domain.UserFilter, limit int32, cursor string) ([]domain.User, string, error)
+	CountMatching(ctx context.Context, f domain.UserFilter) (int, error)
+}
+
+type deviceLister interface {
+	ListByUser(ctx context.Context, userID string) ([]domain.Device, error)
+}
+
+type versionLister interface {
+	List(ctx context.Context) ([]domain.AppVersion, error)
+}
+
+type notificationWriter interface {
+	BatchPut(ctx context.Context, notifications []domain.Notification) error
+}
+
+type mailer interface {
+	SendEmail(to, subject, body string) error
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type service struct {
+	repo          broadcastStore
+	users         userPager
+	devices       deviceLister
+	versions      versionLister
+	notifications notificationWriter
+	mailer        mailer
+	audit         auditRecorder
+	pageSize      int32
+	lease         time.Duration
+	now           func() time.Time
+}
+
+type ServiceDeps struct {
+	BroadcastRepo broadcastStore
+	Users         userPager
+	Devices       deviceLister  // resolves device platform segments
+	AppVersions   versionLister // maps devices' app versions to platforms
+	Notifications notificationWriter
+	Mailer        mailer
+	Audit         auditRecorder
+	PageSize      int32         // users per page; 0 means 100
+	Lease         time.Duration // how long one run holds a broadcast; 0 means 2 minutes
+}
+
+func NewService(deps ServiceDeps) Service {
+	s := &service{
+		repo:          deps.BroadcastRepo,
+		users:         deps.Users,
+		devices:       deps.Devices,
+		versions:      deps.AppVersions,
+		notifications: deps.Notifications,
+		mailer:        deps.Mailer,
+		audit:         deps.Audit,
+		pageSize:      deps.PageSize,
+		lease:         deps.Lease,
+		now:           time.Now,
+	}
+	if s.pageSize <= 0 {
+		s.pageSize = defaultPageSize
+	}
+	if s.lease <= 0 {
+		s.lease = defaultLease
+	}
+	return s
+}
+
+func (s *service) Create(ctx context.Context, actorID string, req domain.CreateBroadcastRequest) (*domain.Broadcast, error) {
+	b := &domain.Broadcast{
+		BroadcastID:  id.New(),
+		Message:      req.Message,
+		EmailSubject: req.EmailSubject,
+		Segment:      req.Segment,
+		Status:       domain.BroadcastPending,
+		CreatedBy:    actorID,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err := s.repo.Put(ctx, b); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditBroadcastCreate,
+		ActorID:  actorID,
+		TargetID: b.BroadcastID,
+		Details:  segmentDetails(b.Segment, map[string]string{"email": strconv.FormatBool(b.EmailSubject != "")}),
+	})
+	return b, nil
+}
+
+// segmentDetails adds the filters set in seg to the audit details.
+func segmentDetails(seg domain.BroadcastSegment, details map[string]string) map[string]string {
+	for k, v := range map[string]string{
+		"role":            seg.Role,
+		"created_after":   seg.CreatedAfter,
+		"created_before":  seg.CreatedBefore,
+		"device_platform": seg.DevicePlatform,
+	} {
+		if v != "" {
+			details[k] = v
+		}
+	}
+	if seg.EmailConfirmed != nil {
+		details["email_confirmed"] = strconv.FormatBool(*seg.EmailConfirmed)
+	}
+	return details
+}
+
+// Preview counts the segment's users through the same index query the
+// fan-out pages through. A device platform cannot be counted that way, so
+// the share of the first previewSample users on it is extrapolated.
+func (s *service) Preview(ctx context.Context, seg domain.BroadcastSegment) (*domain.BroadcastPreview, error) {
+	f := seg.UserFilter()
+	total, err := s.users.CountMatching(ctx, f)
+	if err != nil || seg.DevicePlatform == "" {
+		return &domain.BroadcastPreview{EstimatedRecipients: total, Exact: true}, err
+	}
+	sampled, hits, cursor := 0, 0, ""
+	for sampled < previewSample {
+		users, next, err := s.users.QueryPage(ctx, f, s.pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		matched, err := s.onPlatform(ctx, users, seg.DevicePlatform)
+		if err != nil {
+			return nil, err
+		}
+		sampled, hits, cursor = sampled+len(users), hits+len(matched), next
+		if next == "" {
+			return &domain.BroadcastPreview{EstimatedRecipients: hits, Exact: true, Sampled: sampled}, nil
+		}
+	}
+	estimate := int(math.Round(float64(total) * float64(hits) / float64(sampled)))
+	return &domain.BroadcastPreview{EstimatedRecipients: estimate, Sampled: sampled}, nil
+}
+
+// onPlatform keeps the users with an enabled device whose app version is on
+// platform, looking each user's devices up through the user_id index.
+func (s *service) onPlatform(ctx context.Context, users []domain.User, platform string) ([]domain.User, error) {
+	versions, err := s.versions.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	onPlatform := map[string]bool{}
+	for _, v := range versions {
+		onPlatform[v.VersionID] = v.Platform == platform
+	}
+	var kept []domain.User
+	for _, u := range users {
+		devices, err := s.devices.ListByUser(ctx, u.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(devices, func(d domain.Device) bool { return onPlatform[d.AppVersionID] }) {
+			kept = append(kept, u)
+		}
+	}
+	return kept, nil
+}
+
+func (s *service) List(ctx context.Context, status string) ([]domain.Broadcast, error) {
+	broadcasts, err := s.repo.List(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(broadcasts, func(i, j int) bool {
+		return broadcasts[i].CreatedAt.After(broadcasts[j].CreatedAt)
+	})
+	return broadcasts, nil
+}
+
+func (s *service) Get(ctx context.Context, broadcastID string) (*domain.Broadcast, error) {
+	return s.repo.Get(ctx, broadcastID)
+}
+
+func (s *service) Cancel(ctx context.Context, actorID, broadcastID string) (*domain.Broadcast, error) {
+	if _, err := s.repo.Get(ctx, broadcastID); err != nil {
+		return nil, err
+	}
+	err := s.repo.Close(ctx, broadcastID, []string{domain.BroadcastPending, domain.BroadcastRunning}, map[string]interface{}{
+		"status":      domain.BroadcastCanceled,
+		"canceled_by": actorID,
+		"finished_at": s.now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditBroadcastCancel,
+		ActorID:  actorID,
+		TargetID: broadcastID,
+	})
+	return s.repo.Get(ctx, broadcastID)
+}
+
+func (s *service) Run(ctx context.Context) error {
+	var errs []error
+	for _, status := range []string{domain.BroadcastPending, domain.BroadcastRunning} {
+		broadcasts, err := s.repo.List(ctx, status)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		for _, b := range broadcasts {
+			if err := s.advance(ctx, b); err != nil {
+				errs = append(errs, fmt.Errorf("broadcast %s: %w", b.BroadcastID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// advance leases b and fans it out page by page until it ends or half the
+// lease has passed, leaving the rest to a later run. The lease keeps
+// replicas from delivering the same page twice.
+func (s *service) advance(ctx context.Context, b domain.Broadcast) error {
+	if b.TenantID != "" {
+		ctx = tenant.WithID(ctx, b.TenantID)
+	}
+	leaseID := id.New()
+	deadline := s.now().Add(s.lease / 2)
+	cur, err := s.repo.Claim(ctx, b.BroadcastID, leaseID, s.now().Add(s.lease).Unix())
+	if errors.Is(err, domain.ErrConflict) {
+		return nil
+	}
+	for err == nil && cur != nil && cur.Status == domain.BroadcastRunning && s.now().Before(deadline) {
+		if err = ctx.Err(); err == nil {
+			cur, err = s.page(ctx, cur, leaseID)
+		}
+	}
+	if errors.Is(err, domain.ErrConflict) {
+		return nil // another replica took the lease over
+	}
+	if err != nil || cur == nil || cur.Status != domain.BroadcastRunning {
+		return err
+	}
+	// Hand the broadcast to the next run straight away.
+	_, err = s.repo.Advance(ctx, b.BroadcastID, leaseID, map[string]interface{}{"lease_until": 0})
+	return err
+}
+
+// page delivers the next page of the segment's users and stores the cursor
+// and report, closing the broadcast once no users are left. It returns the
+// broadcast as stored, or nil once it is closed.
+func (s *service) page(ctx context.Context, b *domain.Broadcast, leaseID string) (*domain.Broadcast, error) {
+	users, next, err := s.users.QueryPage(ctx, b.Segment.UserFilter(), s.pageSize, b.Cursor)
+	if errors.Is(err, domain.ErrBadRequest) {
+		return nil, s.close(ctx, b.BroadcastID, domain.BroadcastFailed,
+			"stored cursor no longer decodes; set the same CURSOR_SECRET on every replica")
+	}
+	if err == nil && b.Segment.DevicePlatform != "" {
+		users, err = s.onPlatform(ctx, users, b.Segment.DevicePlatform)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.deliver(ctx, b, users)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.repo.Advance(ctx, b.BroadcastID, leaseID, map[string]interface{}{
+		"cursor":      next,
+		"report":      report,
+		"lease_until": s.now().Add(s.lease).Unix(),
+	})
+	if err != nil || next != "" {
+		return stored, err
+	}
+	return nil, s.close(ctx, b.BroadcastID, domain.BroadcastDone, "")
+}
+
+// deliver writes one announcement per user, emails them when the broadcast
+// has an email subject, and returns b's report with the page counted.
+// Notification IDs derive from the broadcast and user, so a page redone
+// after a lost lease overwrites rather than duplicates them.
+func (s *service) deliver(ctx context.Context, b *domain.Broadcast, users []domain.User) (domain.BroadcastReport, error) {
+	report := b.Report
+	report.Pages++
+	if len(users) == 0 {
+		return report, nil
+	}
+	now := s.now().UTC()
+	notes := make([]domain.Notification, len(users))
+	for i, u := range users {
+		notes[i] = domain.Notification{
+			NotificationID: b.BroadcastID + "-" + u.UserID,
+			UserID:         u.UserID,
+			Message:        b.Message,
+			Category:       domain.CategoryAnnouncement,
+			Status:         domain.NotificationSent,
+			SendAt:         now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+	}
+	if err := s.notifications.BatchPut(ctx, notes); err != nil {
+		return report, err
+	}
+	report.Recipients += len(users)
+	report.Notified += len(notes)
+	if b.EmailSubject == "" {
+		return report, nil
+	}
+	for _, u := range users {
+		if err := s.mailer.SendEmail(u.Email, b.EmailSubject, b.Message); err != nil {
+			slog.Warn("broadcast: email failed", "broadcast_id", b.BroadcastID, "user_id", u.UserID, "error", err)
+			report.EmailFailed++
+			continue
+		}
+		report.Emailed++
+	}
+	return report, nil
+}
+
+// close ends a running broadcast. Losing to a cancellation is not an error.
+func (s *service) close(ctx context.Context, broadcastID, status, reason string) error {
+	updates := map[string]interface{}{"status": status, "finished_at": s.now().UTC()}
+	if reason != "" {
+		updates["error"] = reason
+	}
+	err := s.repo.Close(ctx, broadcastID, []string{domain.BroadcastRunning}, updates)
+	if errors.Is(err, domain.ErrConflict) {
+		return nil
+	}
+	return err
+}
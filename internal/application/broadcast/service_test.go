@@ -0,0 +1,358 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRepo keeps broadcasts in memory and enforces the lease and status
+// conditions of the DynamoDB repo.
+type stubRepo struct {
+	items map[string]*domain.Broadcast
+}
+
+func (r *stubRepo) Put(_ context.Context, b *domain.Broadcast) error {
+	cp := *b
+	r.items[b.BroadcastID] = &cp
+	return nil
+}
+
+func (r *stubRepo) Get(_ context.Context, broadcastID string) (*domain.Broadcast, error) {
+	b, ok := r.items[broadcastID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *b
+	return &cp, nil
+}
+
+func (r *stubRepo) List(_ context.Context, status string) ([]domain.Broadcast, error) {
+	var out []domain.Broadcast
+	for _, b := range r.items {
+		if status == "" || b.Status == status {
+			out = append(out, *b)
+		}
+	}
+	return out, nil
+}
+
+func (r *stubRepo) Claim(_ context.Context, broadcastID, leaseID string, until int64) (*domain.Broadcast, error) {
+	b := r.items[broadcastID]
+	ended := b.Status != domain.BroadcastPending && b.Status != domain.BroadcastRunning
+	if ended || b.LeaseUntil >= time.Now().Unix() {
+		return nil, domain.ErrConflict
+	}
+	b.Status, b.LeaseID, b.LeaseUntil = domain.BroadcastRunning, leaseID, until
+	cp := *b
+	return &cp, nil
+}
+
+func (r *stubRepo) Advance(_ context.Context, broadcastID, leaseID string, updates map[string]interface{}) (*domain.Broadcast, error) {
+	b := r.items[broadcastID]
+	if b.LeaseID != leaseID {
+		return nil, domain.ErrConflict
+	}
+	if c, ok := updates["cursor"]; ok {
+		b.Cursor = c.(string)
+	}
+	if rep, ok := updates["report"]; ok {
+		b.Report = rep.(domain.BroadcastReport)
+	}
+	if lu, ok := updates["lease_until"]; ok {
+		b.LeaseUntil = int64(toInt(lu))
+	}
+	cp := *b
+	return &cp, nil
+}
+
+func (r *stubRepo) Close(_ context.Context, broadcastID string, from []string, updates map[string]interface{}) error {
+	b := r.items[broadcastID]
+	if !slices.Contains(from, b.Status) {
+		return domain.ErrConflict
+	}
+	b.Status = updates["status"].(string)
+	if e, ok := updates["error"]; ok {
+		b.Error = e.(string)
+	}
+	return nil
+}
+
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	}
+	return 0
+}
+
+// stubUsers pages over the users in f.Role (all of them without one) with
+// the cursor as the offset.
+type stubUsers struct {
+	users []domain.User
+	err   error
+}
+
+func (s *stubUsers) matching(f domain.UserFilter) []domain.User {
+	return slices.DeleteFunc(slices.Clone(s.users), func(u domain.User) bool { return f.Role != "" && u.Role != f.Role })
+}
+
+func (s *stubUsers) QueryPage(_ context.Context, f domain.UserFilter, limit int32, cursor string) ([]domain.User, string, error) {
+	if s.err != nil {
+		return nil, "", s.err
+	}
+	users := s.matching(f)
+	start, _ := strconv.Atoi(cursor)
+	end := min(start+int(limit), len(users))
+	next := ""
+	if end < len(users) {
+		next = strconv.Itoa(end)
+	}
+	return users[start:end], next, nil
+}
+
+func (s *stubUsers) CountMatching(_ context.Context, f domain.UserFilter) (int, error) {
+	return len(s.matching(f)), s.err
+}
+
+// stubDevices gives every user listed in byUser one device on that app version.
+type stubDevices struct {
+	byUser map[string]string
+}
+
+func (s *stubDevices) ListByUser(_ context.Context, userID string) ([]domain.Device, error) {
+	if v, ok := s.byUser[userID]; ok {
+		return []domain.Device{{UserID: userID, AppVersionID: v, Enable: true}}, nil
+	}
+	return nil, nil
+}
+
+type stubVersions []domain.AppVersion
+
+func (s stubVersions) List(context.Context) ([]domain.AppVersion, error) { return s, nil }
+
+type stubNotifications struct {
+	written map[string]domain.Notification
+}
+
+func (s *stubNotifications) BatchPut(_ context.Context, notifications []domain.Notification) error {
+	for _, n := range notifications {
+		s.written[n.NotificationID] = n
+	}
+	return nil
+}
+
+type stubMailer struct {
+	sent   []string
+	failTo string
+}
+
+func (m *stubMailer) SendEmail(to, _, _ string) error {
+	if to == m.failTo {
+		return errors.New("mailbox unavailable")
+	}
+	m.sent = append(m.sent, to)
+	return nil
+}
+
+type stubAudit struct{ actions []string }
+
+func (a *stubAudit) Record(_ context.Context, e domain.AuditEntry) {
+	a.actions = append(a.actions, e.Action)
+}
+
+type fixture struct {
+	svc     *service
+	repo    *stubRepo
+	users   *stubUsers
+	devices *stubDevices
+	notes   *stubNotifications
+	mail    *stubMailer
+	audit   *stubAudit
+}
+
+func newFixture(n int) *fixture {
+	f := &fixture{
+		repo:    &stubRepo{items: map[string]*domain.Broadcast{}},
+		users:   &stubUsers{},
+		devices: &stubDevices{byUser: map[string]string{}},
+		notes:   &stubNotifications{written: map[string]domain.Notification{}},
+		mail:    &stubMailer{},
+		audit:   &stubAudit{},
+	}
+	for i := 0; i < n; i++ {
+		id := "u" + strconv.Itoa(i)
+		f.users.users = append(f.users.users, domain.User{UserID: id, Email: id + "@example.com", Enable: 1})
+	}
+	f.svc = NewService(ServiceDeps{
+		BroadcastRepo: f.repo,
+		Users:         f.users,
+		Devices:       f.devices,
+		AppVersions:   stubVersions{{VersionID: "v-ios", Platform: domain.PlatformIOS}, {VersionID: "v-web", Platform: domain.PlatformWeb}},
+		Notifications: f.notes,
+		Mailer:        f.mail,
+		Audit:         f.audit,
+		PageSize:      2,
+	}).(*service)
+	return f
+}
+
+func TestRun_FansOutToEveryUserAndReports(t *testing.T) {
+	f := newFixture(5)
+	f.mail.failTo = "u3@example.com"
+	ctx := context.Background()
+	b, err := f.svc.Create(ctx, "admin", domain.CreateBroadcastRequest{Message: "Maintenance tonight", EmailSubject: "Heads up"})
+	require.NoError(t, err)
+
+	require.NoError(t, f.svc.Run(ctx))
+
+	got, err := f.svc.Get(ctx, b.BroadcastID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastDone, got.Status)
+	assert.Equal(t, domain.BroadcastReport{Recipients: 5, Notified: 5, Emailed: 4, EmailFailed: 1, Pages: 3}, got.Report)
+	assert.Len(t, f.notes.written, 5)
+	n := f.notes.written[b.BroadcastID+"-u0"]
+	assert.Equal(t, domain.CategoryAnnouncement, n.Category)
+	assert.Equal(t, "Maintenance tonight", n.Message)
+	assert.Equal(t, []string{domain.AuditBroadcastCreate}, f.audit.actions)
+}
+
+func TestRun_InAppOnlySendsNoEmail(t *testing.T) {
+	f := newFixture(3)
+	ctx := context.Background()
+	_, err := f.svc.Create(ctx, "admin", domain.CreateBroadcastRequest{Message: "hello"})
+	require.NoError(t, err)
+
+	require.NoError(t, f.svc.Run(ctx))
+
+	assert.Empty(t, f.mail.sent)
+	assert.Len(t, f.notes.written, 3)
+}
+
+func TestRun_ResumesFromCursorAfterDeadline(t *testing.T) {
+	f := newFixture(5)
+	ctx := context.Background()
+	b, err := f.svc.Create(ctx, "admin", domain.CreateBroadcastRequest{Message: "hello"})
+	require.NoError(t, err)
+	clock := time.Now()
+	f.svc.now = func() time.Time {
+		clock = clock.Add(time.Minute) // each call uses up half the lease
+		return clock
+	}
+
+	require.NoError(t, f.svc.Run(ctx))
+	partial, _ := f.svc.Get(ctx, b.BroadcastID)
+	require.Equal(t, domain.BroadcastRunning, partial.Status)
+	require.Less(t, partial.Report.Recipients, 5)
+	f.repo.items[b.BroadcastID].LeaseUntil = 0 // the lease has run out by the next tick
+	f.svc.now = time.Now
+	require.NoError(t, f.svc.Run(ctx))
+
+	done, _ := f.svc.Get(ctx, b.BroadcastID)
+	assert.Equal(t, domain.BroadcastDone, done.Status)
+	assert.Equal(t, 5, done.Report.Recipients)
+	assert.Len(t, f.notes.written, 5)
+}
+
+func TestRun_SkipsBroadcastLeasedElsewhere(t *testing.T) {
+	f := newFixture(2)
+	ctx := context.Background()
+	b, err := f.svc.Create(ctx, "admin", domain.CreateBroadcastRequest{Message: "hello"})
+	require.NoError(t, err)
+	f.repo.items[b.BroadcastID].LeaseUntil = time.Now().Add(time.Minute).Unix()
+
+	require.NoError(t, f.svc.Run(ctx))
+
+	assert.Empty(t, f.notes.written)
+}
+
+func TestRun_UndecodableCursorFailsBroadcast(t *testing.T) {
+	f := newFixture(2)
+	f.users.err = domain.ErrBadRequest
+	ctx := context.Background()
+	b, err := f.svc.Create(ctx, "admin", domain.CreateBroadcastRequest{Message: "hello"})
+	require.NoError(t, err)
+
+	require.NoError(t, f.svc.Run(ctx))
+
+	got, _ := f.svc.Get(ctx, b.BroadcastID)
+	assert.Equal(t, domain.BroadcastFailed, got.Status)
+	assert.Contains(t, got.Error, "CURSOR_SECRET")
+}
+
+func TestCancel(t *testing.T) {
+	f := newFixture(2)
+	ctx := context.Background()
+	b, err := f.svc.Create(ctx, "admin", domain.CreateBroadcastRequest{Message: "hello"})
+	require.NoError(t, err)
+
+	got, err := f.svc.Cancel(ctx, "admin", b.BroadcastID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastCanceled, got.Status)
+	assert.Contains(t, f.audit.actions, domain.AuditBroadcastCancel)
+
+	require.NoError(t, f.svc.Run(ctx))
+	assert.Empty(t, f.notes.written, "a canceled broadcast is not fanned out")
+
+	_, err = f.svc.Cancel(ctx, "admin", b.BroadcastID)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	_, err = f.svc.Cancel(ctx, "admin", "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestRun_SegmentNarrowsRecipients(t *testing.T) {
+	f := newFixture(6)
+	for i := range f.users.users {
+		f.users.users[i].Role = []string{domain.RoleUser, "Beta"}[i%2]
+	}
+	f.devices.byUser = map[string]string{"u1": "v-ios", "u3": "v-web", "u4": "v-ios", "u5": "v-ios"}
+	ctx := context.Background()
+	seg := domain.BroadcastSegment{Role: "Beta", DevicePlatform: domain.PlatformIOS}
+	b, err := f.svc.Create(ctx, "admin", domain.CreateBroadcastRequest{Message: "New iOS beta", Segment: seg})
+	require.NoError(t, err)
+
+	require.NoError(t, f.svc.Run(ctx))
+
+	got, _ := f.svc.Get(ctx, b.BroadcastID)
+	assert.Equal(t, domain.BroadcastDone, got.Status)
+	assert.Equal(t, seg, got.Segment)
+	assert.Equal(t, 2, got.Report.Recipients)
+	assert.Contains(t, f.notes.written, b.BroadcastID+"-u1")
+	assert.Contains(t, f.notes.written, b.BroadcastID+"-u5")
+	assert.Len(t, f.notes.written, 2)
+}
+
+func TestPreview(t *testing.T) {
+	f := newFixture(5)
+	f.devices.byUser = map[string]string{"u0": "v-web", "u2": "v-ios"}
+	ctx := context.Background()
+
+	p, err := f.svc.Preview(ctx, domain.BroadcastSegment{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastPreview{EstimatedRecipients: 5, Exact: true}, *p)
+
+	p, err = f.svc.Preview(ctx, domain.BroadcastSegment{DevicePlatform: domain.PlatformWeb})
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastPreview{EstimatedRecipients: 1, Exact: true, Sampled: 5}, *p, "a small segment is checked in full")
+}
+
+func TestPreview_ExtrapolatesDevicePlatformFromSample(t *testing.T) {
+	f := newFixture(previewSample * 2)
+	f.svc.pageSize = 50
+	for i := 0; i < previewSample; i += 4 {
+		f.devices.byUser["u"+strconv.Itoa(i)] = "v-ios"
+	}
+
+	p, err := f.svc.Preview(context.Background(), domain.BroadcastSegment{DevicePlatform: domain.PlatformIOS})
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastPreview{EstimatedRecipients: previewSample / 2, Sampled: previewSample}, *p)
+}
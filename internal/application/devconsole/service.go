@@ -0,0 +1,176 @@
+// Package devconsole backs the development-only API console: it seeds
+// throwaway accounts and opens sessions without a password. It must never be
+// wired outside development.
+package devconsole
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	pkgdevice "github.com/go-api-nosql/internal/pkg/device"
+	"github.com/go-api-nosql/internal/pkg/id"
+	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
+)
+
+const fieldRole = "role"
+
+type Service interface {
+	// SeedUser registers a throwaway account with a random password and the
+	// given role (User when empty) and signs it in.
+	SeedUser(ctx context.Context, role string) (*SeedResult, error)
+	// MintToken opens a session for an existing user without a password.
+	MintToken(ctx context.Context, userID string) (*Tokens, error)
+}
+
+// Tokens are the credentials of a session opened by the console.
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	SessionID    string `json:"session_id"`
+}
+
+// SeedResult is a seeded account with its password and an open session.
+type SeedResult struct {
+	User     *domain.User
+	Password string
+	Tokens   *Tokens
+}
+
+type userRegistrar interface {
+	Register(ctx context.Context, req domain.CreateUserRequest) (*domain.User, error)
+}
+
+type userStore interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+	Update(ctx context.Context, userID string, updates map[string]interface{}) error
+}
+
+type sessionStore interface {
+	Put(ctx context.Context, s *domain.Session) error
+}
+
+type deviceStore interface {
+	GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error)
+	Put(ctx context.Context, d *domain.Device) error
+}
+
+type jwtSigner interface {
+	Sign(ctx context.Context, userID, deviceID, role, sessionID string) (string, error)
+}
+
+type service struct {
+	users           userRegistrar
+	userRepo        userStore
+	sessionRepo     sessionStore
+	deviceRepo      deviceStore
+	jwtProvider     jwtSigner
+	refreshTokenDur time.Duration
+}
+
+// ServiceDeps groups the dependencies for the dev console service.
+type ServiceDeps struct {
+	Users           userRegistrar
+	UserRepo        userStore
+	SessionRepo     sessionStore
+	DeviceRepo      deviceStore
+	JWTProvider     jwtSigner
+	RefreshTokenDur time.Duration
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{
+		users:           deps.Users,
+		userRepo:        deps.UserRepo,
+		sessionRepo:     deps.SessionRepo,
+		deviceRepo:      deps.DeviceRepo,
+		jwtProvider:     deps.JWTProvider,
+		refreshTokenDur: deps.RefreshTokenDur,
+	}
+}
+
+func (s *service) SeedUser(ctx context.Context, role string) (*SeedResult, error) {
+	suffix, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	password, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.Register(ctx, domain.CreateUserRequest{
+		Username:  "qa-" + suffix,
+		Email:     "qa-" + suffix + "@example.test",
+		Password:  password,
+		FirstName: "QA",
+		LastName:  suffix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if role != "" && role != u.Role {
+		if err := s.userRepo.Update(ctx, u.UserID, map[string]interface{}{fieldRole: role}); err != nil {
+			return nil, err
+		}
+		u.Role = role
+	}
+	tokens, err := s.openSession(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	return &SeedResult{User: u, Password: password, Tokens: tokens}, nil
+}
+
+func (s *service) MintToken(ctx context.Context, userID string) (*Tokens, error) {
+	u, err := s.userRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.DeletedAt != nil || u.Enable == 0 {
+		return nil, fmt.Errorf("user is deleted or disabled: %w", domain.ErrBadRequest)
+	}
+	return s.openSession(ctx, u)
+}
+
+// openSession creates a session on a new device for u and signs its bearer,
+// as a password login would.
+func (s *service) openSession(ctx context.Context, u *domain.User) (*Tokens, error) {
+	dev, err := pkgdevice.Resolve(ctx, s.deviceRepo, nil, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	sess := &domain.Session{
+		SessionID:        id.New(),
+		UserID:           u.UserID,
+		DeviceID:         dev.DeviceID,
+		Enable:           true,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: now.Add(s.refreshTokenDur).Unix(),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := s.sessionRepo.Put(ctx, sess); err != nil {
+		return nil, err
+	}
+	bearer, err := s.jwtProvider.Sign(ctx, u.UserID, dev.DeviceID, u.Role, sess.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{AccessToken: bearer, RefreshToken: refreshToken, SessionID: sess.SessionID}, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
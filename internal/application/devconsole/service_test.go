@@ -0,0 +1,124 @@
+package devconsole
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockRegistrar struct{ mock.Mock }
+
+func (m *mockRegistrar) Register(ctx context.Context, req domain.CreateUserRequest) (*domain.User, error) {
+	args := m.Called(ctx, req)
+	if u, _ := args.Get(0).(*domain.User); u != nil {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockUserStore struct{ mock.Mock }
+
+func (m *mockUserStore) Get(ctx context.Context, userID string) (*domain.User, error) {
+	args := m.Called(ctx, userID)
+	if u, _ := args.Get(0).(*domain.User); u != nil {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockUserStore) Update(ctx context.Context, userID string, updates map[string]interface{}) error {
+	return m.Called(ctx, userID, updates).Error(0)
+}
+
+type stubSessions struct{ put []*domain.Session }
+
+func (s *stubSessions) Put(_ context.Context, sess *domain.Session) error {
+	s.put = append(s.put, sess)
+	return nil
+}
+
+type stubDevices struct{}
+
+func (stubDevices) GetByUUID(context.Context, string, string) (*domain.Device, error) {
+	return nil, domain.ErrNotFound
+}
+func (stubDevices) Put(context.Context, *domain.Device) error { return nil }
+
+type stubSigner struct{ role string }
+
+func (s *stubSigner) Sign(_ context.Context, userID, deviceID, role, sessionID string) (string, error) {
+	s.role = role
+	return "bearer-" + userID, nil
+}
+
+func newTestService(reg *mockRegistrar, users *mockUserStore, sessions *stubSessions, signer *stubSigner) Service {
+	return NewService(ServiceDeps{
+		Users:           reg,
+		UserRepo:        users,
+		SessionRepo:     sessions,
+		DeviceRepo:      stubDevices{},
+		JWTProvider:     signer,
+		RefreshTokenDur: time.Hour,
+	})
+}
+
+// --- tests ---
+
+func TestSeedUser_SetsRoleAndOpensSession(t *testing.T) {
+	reg, users, sessions, signer := &mockRegistrar{}, &mockUserStore{}, &stubSessions{}, &stubSigner{}
+	reg.On("Register", mock.Anything, mock.AnythingOfType("domain.CreateUserRequest")).
+		Return(&domain.User{UserID: "u1", Username: "qa-x", Role: domain.RoleUser, Enable: 1}, nil)
+	users.On("Update", mock.Anything, "u1", map[string]interface{}{fieldRole: domain.RoleAdmin}).Return(nil)
+
+	res, err := newTestService(reg, users, sessions, signer).SeedUser(context.Background(), domain.RoleAdmin)
+
+	require.NoError(t, err)
+	assert.Len(t, res.Password, 16)
+	assert.Equal(t, domain.RoleAdmin, res.User.Role)
+	assert.Equal(t, domain.RoleAdmin, signer.role)
+	assert.Equal(t, "bearer-u1", res.Tokens.AccessToken)
+	require.Len(t, sessions.put, 1)
+	assert.Equal(t, res.Tokens.SessionID, sessions.put[0].SessionID)
+	users.AssertExpectations(t)
+}
+
+func TestSeedUser_DefaultRoleSkipsUpdate(t *testing.T) {
+	reg, users := &mockRegistrar{}, &mockUserStore{}
+	reg.On("Register", mock.Anything, mock.Anything).
+		Return(&domain.User{UserID: "u1", Role: domain.RoleUser, Enable: 1}, nil)
+
+	_, err := newTestService(reg, users, &stubSessions{}, &stubSigner{}).SeedUser(context.Background(), "")
+
+	require.NoError(t, err)
+	users.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMintToken_RejectsDisabledUser(t *testing.T) {
+	users := &mockUserStore{}
+	users.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Enable: 0}, nil)
+	sessions := &stubSessions{}
+
+	_, err := newTestService(&mockRegistrar{}, users, sessions, &stubSigner{}).MintToken(context.Background(), "u1")
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	assert.Empty(t, sessions.put)
+}
+
+func TestMintToken_UsesUserRole(t *testing.T) {
+	users := &mockUserStore{}
+	users.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Role: "Support", Enable: 1}, nil)
+	signer := &stubSigner{}
+
+	tokens, err := newTestService(&mockRegistrar{}, users, &stubSessions{}, signer).MintToken(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, "Support", signer.role)
+}
@@ -2,6 +2,9 @@ package device
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"strconv"
 
 	"github.com/go-api-nosql/internal/domain"
@@ -10,6 +13,7 @@ import (
 // DynamoDB attribute names used in partial update maps.
 const (
 	fieldToken        = "token"
+	fieldTokenStale   = "token_stale"
 	fieldAppVersionID = "app_version_id"
 )
 
@@ -18,8 +22,16 @@ type Service interface {
 	Get(ctx context.Context, deviceID string) (*domain.Device, error)
 	Update(ctx context.Context, deviceID string, req domain.UpdateDeviceRequest) (*domain.Device, error)
 	Delete(ctx context.Context, deviceID string) error
-	// CheckVersion returns true if version is up to date, false if update required.
-	CheckVersion(ctx context.Context, sessionID string, version float64) (bool, error)
+	// CheckVersion returns true if version is up to date with the latest
+	// enabled version of platform, false if update required.
+	// The session must be bound to an enabled device of the session's user.
+	CheckVersion(ctx context.Context, sessionID, platform string, version float64) (bool, error)
+	// RotateToken replaces the device's push token and clears any stale flag.
+	RotateToken(ctx context.Context, deviceID, token string) (*domain.Device, error)
+	// Push sends message to every enabled device of userID with a live token and
+	// returns how many deliveries succeeded. Tokens the provider rejects are
+	// marked stale and skipped on later sends until rotated.
+	Push(ctx context.Context, userID, message string) (int, error)
 }
 
 type deviceStore interface {
@@ -30,16 +42,28 @@ type deviceStore interface {
 }
 
 type appVersionStore interface {
-	GetLatest(ctx context.Context) (*domain.AppVersion, error)
+	Get(ctx context.Context, versionID string) (*domain.AppVersion, error)
+	GetLatest(ctx context.Context, platform string) (*domain.AppVersion, error)
+}
+
+type sessionStore interface {
+	Get(ctx context.Context, sessionID string) (*domain.Session, error)
+}
+
+type pushSender interface {
+	SendPush(ctx context.Context, token, message string) error
 }
 
 type service struct {
 	repo           deviceStore
 	appVersionRepo appVersionStore
+	sessions       sessionStore
+	push           pushSender
 }
 
-func NewService(repo deviceStore, appVersionRepo appVersionStore) Service {
-	return &service{repo: repo, appVersionRepo: appVersionRepo}
+// NewService builds the device service. push may be nil, in which case Push is a no-op.
+func NewService(repo deviceStore, appVersionRepo appVersionStore, sessions sessionStore, push pushSender) Service {
+	return &service{repo: repo, appVersionRepo: appVersionRepo, sessions: sessions, push: push}
 }
 
 func (s *service) List(ctx context.Context, userID string) ([]domain.Device, error) {
@@ -54,8 +78,15 @@ func (s *service) Update(ctx context.Context, deviceID string, req domain.Update
 	updates := map[string]interface{}{}
 	if req.Token != nil {
 		updates[fieldToken] = *req.Token
+		updates[fieldTokenStale] = false
 	}
 	if req.AppVersionID != nil {
+		if _, err := s.appVersionRepo.Get(ctx, *req.AppVersionID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("unknown app_version_id: %w", domain.ErrBadRequest)
+			}
+			return nil, err
+		}
 		updates[fieldAppVersionID] = *req.AppVersionID
 	}
 	if len(updates) == 0 {
@@ -71,8 +102,11 @@ func (s *service) Delete(ctx context.Context, deviceID string) error {
 	return s.repo.SoftDelete(ctx, deviceID)
 }
 
-func (s *service) CheckVersion(ctx context.Context, _ string, version float64) (bool, error) {
-	latest, err := s.appVersionRepo.GetLatest(ctx)
+func (s *service) CheckVersion(ctx context.Context, sessionID, platform string, version float64) (bool, error) {
+	if err := s.checkSessionDevice(ctx, sessionID); err != nil {
+		return false, err
+	}
+	latest, err := s.appVersionRepo.GetLatest(ctx, platform)
 	if err != nil {
 		// No version on record — pass.
 		return true, nil
@@ -83,3 +117,61 @@ func (s *service) CheckVersion(ctx context.Context, _ string, version float64) (
 	}
 	return version >= latestF, nil
 }
+
+// checkSessionDevice resolves the session's device and verifies both belong to
+// the same user, so a session cannot act through another account's device.
+func (s *service) checkSessionDevice(ctx context.Context, sessionID string) error {
+	sess, err := s.sessions.Get(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("session not found: %w", domain.ErrUnauthorized)
+		}
+		return err
+	}
+	d, err := s.repo.Get(ctx, sess.DeviceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("session device not found: %w", domain.ErrForbidden)
+		}
+		return err
+	}
+	if !d.Enable || d.UserID != sess.UserID {
+		return fmt.Errorf("session device does not belong to user: %w", domain.ErrForbidden)
+	}
+	return nil
+}
+
+func (s *service) RotateToken(ctx context.Context, deviceID, token string) (*domain.Device, error) {
+	if token == "" {
+		return nil, fmt.Errorf("token is required: %w", domain.ErrBadRequest)
+	}
+	return s.Update(ctx, deviceID, domain.UpdateDeviceRequest{Token: &token})
+}
+
+func (s *service) Push(ctx context.Context, userID, message string) (int, error) {
+	if s.push == nil {
+		return 0, nil
+	}
+	devices, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, d := range devices {
+		if d.Token == nil || *d.Token == "" || d.TokenStale {
+			continue
+		}
+		err := s.push.SendPush(ctx, *d.Token, message)
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, domain.ErrInvalidPushToken):
+			if uerr := s.repo.Update(ctx, d.DeviceID, map[string]interface{}{fieldTokenStale: true}); uerr != nil {
+				slog.Warn("failed to mark push token stale", "device_id", d.DeviceID, "err", uerr)
+			}
+		default:
+			slog.Warn("push delivery failed", "device_id", d.DeviceID, "err", err)
+		}
+	}
+	return sent, nil
+}
@@ -0,0 +1,184 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockDeviceStore struct{ mock.Mock }
+
+func (m *mockDeviceStore) ListByUser(ctx context.Context, userID string) ([]domain.Device, error) {
+	args := m.Called(ctx, userID)
+	devices, _ := args.Get(0).([]domain.Device)
+	return devices, args.Error(1)
+}
+
+func (m *mockDeviceStore) Get(ctx context.Context, deviceID string) (*domain.Device, error) {
+	args := m.Called(ctx, deviceID)
+	if d, _ := args.Get(0).(*domain.Device); d != nil {
+		return d, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockDeviceStore) Update(ctx context.Context, deviceID string, updates map[string]interface{}) error {
+	return m.Called(ctx, deviceID, updates).Error(0)
+}
+
+func (m *mockDeviceStore) SoftDelete(ctx context.Context, deviceID string) error {
+	return m.Called(ctx, deviceID).Error(0)
+}
+
+type stubSessionStore struct{ sessions map[string]*domain.Session }
+
+func (s *stubSessionStore) Get(_ context.Context, sessionID string) (*domain.Session, error) {
+	if sess, ok := s.sessions[sessionID]; ok {
+		return sess, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+type stubAppVersionStore struct{ versions map[string]*domain.AppVersion }
+
+func (s *stubAppVersionStore) Get(_ context.Context, versionID string) (*domain.AppVersion, error) {
+	if v, ok := s.versions[versionID]; ok {
+		return v, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (s *stubAppVersionStore) GetLatest(_ context.Context, platform string) (*domain.AppVersion, error) {
+	for _, v := range s.versions {
+		if v.Platform == platform {
+			return v, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+type mockPushSender struct{ mock.Mock }
+
+func (m *mockPushSender) SendPush(ctx context.Context, token, message string) error {
+	return m.Called(ctx, token, message).Error(0)
+}
+
+func strPtr(s string) *string { return &s }
+
+// --- Push tests ---
+
+func TestPush_SkipsStaleAndMarksRejectedTokens(t *testing.T) {
+	ds := &mockDeviceStore{}
+	ps := &mockPushSender{}
+	ds.On("ListByUser", mock.Anything, "u1").Return([]domain.Device{
+		{DeviceID: "d1", Token: strPtr("live")},
+		{DeviceID: "d2", Token: strPtr("dead")},
+		{DeviceID: "d3", Token: strPtr("old"), TokenStale: true},
+		{DeviceID: "d4"},
+	}, nil)
+	ps.On("SendPush", mock.Anything, "live", "hi").Return(nil)
+	ps.On("SendPush", mock.Anything, "dead", "hi").Return(domain.ErrInvalidPushToken)
+	ds.On("Update", mock.Anything, "d2", map[string]interface{}{fieldTokenStale: true}).Return(nil)
+
+	sent, err := NewService(ds, nil, nil, ps).Push(context.Background(), "u1", "hi")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	ds.AssertExpectations(t)
+	ps.AssertNumberOfCalls(t, "SendPush", 2)
+}
+
+func TestPush_TransientErrorDoesNotMarkStale(t *testing.T) {
+	ds := &mockDeviceStore{}
+	ps := &mockPushSender{}
+	ds.On("ListByUser", mock.Anything, "u1").Return([]domain.Device{{DeviceID: "d1", Token: strPtr("tok")}}, nil)
+	ps.On("SendPush", mock.Anything, "tok", "hi").Return(errors.New("throttled"))
+
+	sent, err := NewService(ds, nil, nil, ps).Push(context.Background(), "u1", "hi")
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	ds.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPush_NoSenderIsNoop(t *testing.T) {
+	sent, err := NewService(&mockDeviceStore{}, nil, nil, nil).Push(context.Background(), "u1", "hi")
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+}
+
+// --- RotateToken tests ---
+
+func TestRotateToken_EmptyToken(t *testing.T) {
+	_, err := NewService(&mockDeviceStore{}, nil, nil, nil).RotateToken(context.Background(), "d1", "")
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestRotateToken_ClearsStaleFlag(t *testing.T) {
+	ds := &mockDeviceStore{}
+	ds.On("Update", mock.Anything, "d1", map[string]interface{}{fieldToken: "new", fieldTokenStale: false}).Return(nil)
+	ds.On("Get", mock.Anything, "d1").Return(&domain.Device{DeviceID: "d1", Token: strPtr("new")}, nil)
+
+	d, err := NewService(ds, nil, nil, nil).RotateToken(context.Background(), "d1", "new")
+
+	require.NoError(t, err)
+	assert.Equal(t, "new", *d.Token)
+	ds.AssertExpectations(t)
+}
+
+// --- Update tests ---
+
+func TestUpdate_UnknownAppVersionIsBadRequest(t *testing.T) {
+	versions := &stubAppVersionStore{versions: map[string]*domain.AppVersion{}}
+
+	_, err := NewService(&mockDeviceStore{}, versions, nil, nil).
+		Update(context.Background(), "d1", domain.UpdateDeviceRequest{AppVersionID: strPtr("v9")})
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+// --- CheckVersion tests ---
+
+func TestCheckVersion_SessionOnAnotherUsersDevice(t *testing.T) {
+	ds := &mockDeviceStore{}
+	ds.On("Get", mock.Anything, "d1").Return(&domain.Device{DeviceID: "d1", UserID: "u2", Enable: true}, nil)
+	sessions := &stubSessionStore{sessions: map[string]*domain.Session{
+		"s1": {SessionID: "s1", UserID: "u1", DeviceID: "d1"},
+	}}
+
+	_, err := NewService(ds, &stubAppVersionStore{}, sessions, nil).CheckVersion(context.Background(), "s1", domain.PlatformIOS, 1.0)
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestCheckVersion_ComparesAgainstLatest(t *testing.T) {
+	ds := &mockDeviceStore{}
+	ds.On("Get", mock.Anything, "d1").Return(&domain.Device{DeviceID: "d1", UserID: "u1", Enable: true}, nil)
+	sessions := &stubSessionStore{sessions: map[string]*domain.Session{
+		"s1": {SessionID: "s1", UserID: "u1", DeviceID: "d1"},
+	}}
+	versions := &stubAppVersionStore{versions: map[string]*domain.AppVersion{
+		"v2": {Platform: domain.PlatformIOS, Version: "2.0"},
+		"a3": {Platform: domain.PlatformAndroid, Version: "3.0"},
+	}}
+	svc := NewService(ds, versions, sessions, nil)
+
+	upToDate, err := svc.CheckVersion(context.Background(), "s1", domain.PlatformIOS, 1.5)
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	upToDate, err = svc.CheckVersion(context.Background(), "s1", domain.PlatformIOS, 2.0)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	upToDate, err = svc.CheckVersion(context.Background(), "s1", domain.PlatformAndroid, 2.0)
+	require.NoError(t, err)
+	assert.False(t, upToDate, "each platform has its own latest version")
+}
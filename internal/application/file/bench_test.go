@@ -0,0 +1,48 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+type benchStorage struct{ data []byte }
+
+func (s benchStorage) Upload(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	return key, nil
+}
+func (s benchStorage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.data)), nil
+}
+func (s benchStorage) Delete(ctx context.Context, key string) error { return nil }
+
+type benchFiles struct{ f *domain.File }
+
+func (r benchFiles) Put(ctx context.Context, f *domain.File) error { return nil }
+func (r benchFiles) Get(ctx context.Context, fileID string) (*domain.File, error) {
+	f := *r.f
+	return &f, nil
+}
+func (r benchFiles) SoftDelete(ctx context.Context, fileID string) error { return nil }
+
+// BenchmarkDownload streams a 64 KiB private file to its owner.
+func BenchmarkDownload(b *testing.B) {
+	f := &domain.File{FileID: "file-1", Object: "files/user-1/a.bin", IsPrivate: true, UploadedByUserID: "user-1", Enable: true}
+	svc := NewService(benchStorage{data: make([]byte, 64<<10)}, benchFiles{f: f}, nil, nil)
+	ctx := context.Background()
+	b.SetBytes(64 << 10)
+
+	for b.Loop() {
+		rc, _, err := svc.Download(ctx, "file-1", "user-1", false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			b.Fatal(err)
+		}
+		rc.Close()
+	}
+}
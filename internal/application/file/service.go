@@ -46,13 +46,33 @@ type fileStore interface {
 	SoftDelete(ctx context.Context, fileID string) error
 }
 
+type activityRecorder interface {
+	Record(ctx context.Context, userID, kind, subject string)
+}
+
+// storageQuota rejects uploads that would take a user past their plan's storage.
+type storageQuota interface {
+	CheckStorage(ctx context.Context, userID string, size int64) error
+}
+
 type service struct {
 	s3       s3Store
 	fileRepo fileStore
+	activity activityRecorder
+	quota    storageQuota
 }
 
-func NewService(s3 s3Store, fileRepo fileStore) Service {
-	return &service{s3: s3, fileRepo: fileRepo}
+// NewService builds the file service. activity may be nil to skip recording
+// uploads, and quota nil to accept uploads of any total size.
+func NewService(s3 s3Store, fileRepo fileStore, activity activityRecorder, quota storageQuota) Service {
+	return &service{s3: s3, fileRepo: fileRepo, activity: activity, quota: quota}
+}
+
+func (s *service) checkQuota(ctx context.Context, userID string, size int64) error {
+	if s.quota == nil {
+		return nil
+	}
+	return s.quota.CheckStorage(ctx, userID, size)
 }
 
 func (s *service) Upload(ctx context.Context, input UploadInput) (*domain.File, error) {
@@ -60,6 +80,9 @@ func (s *service) Upload(ctx context.Context, input UploadInput) (*domain.File,
 	// invoking Upload. io.TeeReader streams through the SHA-256 hasher, so
 	// the full content is read into memory by the S3 upload; large files will
 	// increase memory pressure proportionally.
+	if err := s.checkQuota(ctx, input.UploaderID, input.Size); err != nil {
+		return nil, err
+	}
 	safeName := sanitizeFilename(input.Filename)
 	key := fmt.Sprintf("files/%s/%s", input.UploaderID, safeName)
 	hasher := sha256.New()
@@ -85,6 +108,9 @@ func (s *service) Upload(ctx context.Context, input UploadInput) (*domain.File,
 	if err := s.fileRepo.Put(ctx, f); err != nil {
 		return nil, err
 	}
+	if s.activity != nil {
+		s.activity.Record(ctx, f.UploadedByUserID, domain.ActivityFileUpload, f.FileID)
+	}
 	return f, nil
 }
 
@@ -98,6 +124,9 @@ func (s *service) UploadBase64(ctx context.Context, filename, base64Data string,
 	if err != nil {
 		return nil, fmt.Errorf("decode base64: %w", domain.ErrBadRequest)
 	}
+	if err := s.checkQuota(ctx, uploaderID, int64(len(decoded))); err != nil {
+		return nil, err
+	}
 	contentType := contentTypeFromName(safeName)
 	if _, err := s.s3.Upload(ctx, key, bytes.NewReader(decoded), contentType); err != nil {
 		return nil, err
@@ -121,6 +150,9 @@ func (s *service) UploadBase64(ctx context.Context, filename, base64Data string,
 	if err := s.fileRepo.Put(ctx, f); err != nil {
 		return nil, err
 	}
+	if s.activity != nil {
+		s.activity.Record(ctx, f.UploadedByUserID, domain.ActivityFileUpload, f.FileID)
+	}
 	return f, nil
 }
 
@@ -195,8 +227,8 @@ func contentTypeFromName(filename string) string {
 
 // sanitizeFilename strips directory components and keeps only safe characters
 // (alphanumeric, dot, dash, underscore) to prevent path traversal in S3 keys.
-// When the result would be empty or generic, a nanosecond timestamp suffix is
-// appended to avoid S3 key collisions.
+// When the result would be empty or a relative directory reference, a
+// nanosecond timestamp name is used instead, which also avoids S3 key collisions.
 func sanitizeFilename(name string) string {
 	name = path.Base(name) // drop any leading path components / traversal sequences
 	var b strings.Builder
@@ -208,7 +240,8 @@ func sanitizeFilename(name string) string {
 			b.WriteRune('_')
 		}
 	}
-	if result := b.String(); result != "" && result != "." {
+	// ".." survives path.Base and would make the key a parent reference.
+	if result := b.String(); result != "" && result != "." && result != ".." {
 		return result
 	}
 	return fmt.Sprintf("_%d", time.Now().UnixNano())
@@ -0,0 +1,91 @@
+package file
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStorage remembers the last key and payload uploaded.
+type recordingStorage struct {
+	benchStorage
+	key  string
+	data []byte
+}
+
+func (s *recordingStorage) Upload(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	data, err := io.ReadAll(r)
+	s.key, s.data = key, data
+	return key, err
+}
+
+// requireSafeName checks the invariant S3 keys rely on: one path segment of
+// safe characters that cannot be a relative directory reference.
+func requireSafeName(t *testing.T, name string) {
+	t.Helper()
+	require.NotEmpty(t, name)
+	require.NotContains(t, []string{".", ".."}, name)
+	for _, r := range name {
+		ok := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("._-", r)
+		require.True(t, ok, "unsafe rune %q in %q", r, name)
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	cases := map[string]string{
+		"report.pdf":            "report.pdf",
+		"../../etc/passwd":      "passwd",
+		"my file (1).png":       "my_file__1_.png",
+		"dir\\evil.exe":         "dir_evil.exe",
+		"résumé.txt":            "r_sum_.txt",
+		"/uploads/avatar.jpeg/": "avatar.jpeg",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, sanitizeFilename(in), in)
+	}
+	for _, in := range []string{"", ".", "..", "/", "a/.."} {
+		requireSafeName(t, sanitizeFilename(in))
+	}
+}
+
+func FuzzSanitizeFilename(f *testing.F) {
+	for _, seed := range []string{"report.pdf", "../../etc/passwd", "..", ".", "", "a/b/../..", "\x00.png", "日本.jpg"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		requireSafeName(t, sanitizeFilename(name))
+	})
+}
+
+func FuzzUploadBase64(f *testing.F) {
+	f.Add("photo.png", base64.StdEncoding.EncodeToString([]byte("png bytes")))
+	f.Add("../../x", "not base64!")
+	f.Add("..", "")
+	f.Add("a.pdf", "AAA=")
+
+	f.Fuzz(func(t *testing.T, filename, data string) {
+		store := &recordingStorage{}
+		svc := NewService(store, benchFiles{}, nil, nil)
+
+		got, err := svc.UploadBase64(context.Background(), filename, data, "user-1")
+
+		decoded, decodeErr := base64.StdEncoding.DecodeString(data)
+		if decodeErr != nil {
+			require.ErrorIs(t, err, domain.ErrBadRequest)
+			return
+		}
+		require.NoError(t, err)
+		name, ok := strings.CutPrefix(store.key, "files/user-1/")
+		require.True(t, ok, "key %q escapes the uploader prefix", store.key)
+		requireSafeName(t, name)
+		assert.Equal(t, name, got.Name)
+		assert.Equal(t, decoded, store.data)
+		assert.Equal(t, int64(len(decoded)), got.Size)
+	})
+}
@@ -0,0 +1,236 @@
+package launch
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// inviteCharset leaves out the easily confused 0, 1, I, L and O, since
+// invite codes are typed in by hand.
+const (
+	inviteCharset = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+	inviteLength  = 10
+)
+
+// Service gates registration during a soft launch: while LAUNCH_GATE is on,
+// only addresses on the allowlist, addresses at an allowlisted domain and
+// holders of an invite code may register. Existing users are not affected.
+type Service interface {
+	// PreRegister refuses a registration no entry admits with
+	// domain.ErrForbidden while the gate is on. It is a user.PreRegisterHook.
+	PreRegister(ctx context.Context, req *domain.CreateUserRequest) error
+	// PostRegister counts the registration against the entry that admitted
+	// it. It is a user.PostRegisterHook.
+	PostRegister(ctx context.Context, u *domain.User) error
+	List(ctx context.Context) ([]domain.LaunchEntry, error)
+	Add(ctx context.Context, actorID string, req domain.CreateLaunchEntryRequest) (*domain.LaunchEntry, error)
+	Remove(ctx context.Context, actorID, kind, value string) error
+	Stats(ctx context.Context) (domain.LaunchStats, error)
+}
+
+type allowlistStore interface {
+	Put(ctx context.Context, e *domain.LaunchEntry) error
+	Get(ctx context.Context, kind, value string) (*domain.LaunchEntry, error)
+	List(ctx context.Context) ([]domain.LaunchEntry, error)
+	Delete(ctx context.Context, kind, value string) error
+	Redeem(ctx context.Context, kind, value string) error
+	CountGated(ctx context.Context) error
+	CountAdmitted(ctx context.Context, kind string) error
+	Stats(ctx context.Context) (domain.LaunchStats, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type service struct {
+	repo    allowlistStore
+	audit   auditRecorder
+	enabled bool
+	exempt  map[string]bool
+	now     func() time.Time
+}
+
+type ServiceDeps struct {
+	LaunchRepo allowlistStore
+	Audit      auditRecorder
+	Enabled    bool     // LAUNCH_GATE; when off every registration is admitted
+	Exempt     []string // addresses always admitted and never counted, e.g. ADMIN_EMAIL
+}
+
+func NewService(deps ServiceDeps) Service {
+	exempt := make(map[string]bool, len(deps.Exempt))
+	for _, email := range deps.Exempt {
+		if email != "" {
+			exempt[domain.NormalizeEmail(email, false)] = true
+		}
+	}
+	return &service{repo: deps.LaunchRepo, audit: deps.Audit, enabled: deps.Enabled, exempt: exempt, now: time.Now}
+}
+
+// gated reports whether a registration for email goes through the gate.
+func (s *service) gated(email string) bool {
+	return s.enabled && !s.exempt[domain.NormalizeEmail(email, false)]
+}
+
+func (s *service) PreRegister(ctx context.Context, req *domain.CreateUserRequest) error {
+	if !s.gated(req.Email) {
+		req.InviteCode = ""
+		return nil
+	}
+	e, err := s.admit(ctx, req.Email, req.InviteCode)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		if err := s.repo.CountGated(ctx); err != nil {
+			slog.Warn("launch gate: could not count refused registration", "error", err)
+		}
+		return fmt.Errorf("registration is by invitation only for now: %w", domain.ErrForbidden)
+	}
+	if e.Kind != domain.LaunchInvite {
+		req.InviteCode = "" // the account is not tied to an invite it did not need
+	}
+	return nil
+}
+
+func (s *service) PostRegister(ctx context.Context, u *domain.User) error {
+	if !s.gated(u.Email) {
+		return nil
+	}
+	e, err := s.admit(ctx, u.Email, u.InviteCode)
+	if err != nil || e == nil {
+		return err
+	}
+	if err := s.repo.Redeem(ctx, e.Kind, e.Value); err != nil {
+		return fmt.Errorf("redeem launch %s entry: %w", e.Kind, err)
+	}
+	return s.repo.CountAdmitted(ctx, e.Kind)
+}
+
+// admit returns the entry that admits email or code, trying the address,
+// then its domain, then the invite code, or nil when none does. Invites
+// whose uses have run out admit no one.
+func (s *service) admit(ctx context.Context, email, code string) (*domain.LaunchEntry, error) {
+	email = domain.NormalizeEmail(email, false)
+	_, host, _ := strings.Cut(email, "@")
+	candidates := [][2]string{{domain.LaunchEmail, email}, {domain.LaunchDomain, host}, {domain.LaunchInvite, code}}
+	for _, c := range candidates {
+		if c[1] == "" {
+			continue
+		}
+		e, err := s.repo.Get(ctx, c[0], c[1])
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e.MaxUses > 0 && e.Uses >= e.MaxUses {
+			continue
+		}
+		return e, nil
+	}
+	return nil, nil
+}
+
+func (s *service) List(ctx context.Context) ([]domain.LaunchEntry, error) {
+	entries, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+func (s *service) Add(ctx context.Context, actorID string, req domain.CreateLaunchEntryRequest) (*domain.LaunchEntry, error) {
+	value, err := entryValue(req)
+	if err != nil {
+		return nil, err
+	}
+	e := &domain.LaunchEntry{
+		Kind:      req.Kind,
+		Value:     value,
+		Note:      req.Note,
+		MaxUses:   req.MaxUses,
+		CreatedBy: actorID,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.repo.Put(ctx, e); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditLaunchAllow,
+		ActorID:  actorID,
+		TargetID: e.Value,
+		Details:  map[string]string{"kind": e.Kind},
+	})
+	return e, nil
+}
+
+// entryValue normalizes the value of a new entry, generating the code of an
+// invite that has none.
+func entryValue(req domain.CreateLaunchEntryRequest) (string, error) {
+	value := strings.TrimSpace(req.Value)
+	if req.Kind != domain.LaunchInvite && req.MaxUses > 0 {
+		return "", fmt.Errorf("max_uses only applies to invites: %w", domain.ErrBadRequest)
+	}
+	switch req.Kind {
+	case domain.LaunchEmail:
+		if !strings.Contains(value, "@") {
+			return "", fmt.Errorf("email entry needs an email address: %w", domain.ErrBadRequest)
+		}
+		return domain.NormalizeEmail(value, false), nil
+	case domain.LaunchDomain:
+		value = strings.ToLower(strings.TrimPrefix(value, "@"))
+		if strings.Contains(value, "@") || !strings.Contains(value, ".") {
+			return "", fmt.Errorf("domain entry needs a domain such as example.com: %w", domain.ErrBadRequest)
+		}
+		return value, nil
+	}
+	if value != "" {
+		return value, nil
+	}
+	return newInviteCode()
+}
+
+func newInviteCode() (string, error) {
+	max := big.NewInt(int64(len(inviteCharset)))
+	b := make([]byte, inviteLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = inviteCharset[n.Int64()]
+	}
+	return string(b), nil
+}
+
+func (s *service) Remove(ctx context.Context, actorID, kind, value string) error {
+	if err := s.repo.Delete(ctx, kind, value); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditLaunchRevoke,
+		ActorID:  actorID,
+		TargetID: value,
+		Details:  map[string]string{"kind": kind},
+	})
+	return nil
+}
+
+func (s *service) Stats(ctx context.Context) (domain.LaunchStats, error) {
+	stats, err := s.repo.Stats(ctx)
+	stats.Enabled = s.enabled
+	return stats, err
+}
@@ -0,0 +1,187 @@
+package launch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRepo keeps entries in memory keyed like the DynamoDB repo.
+type stubRepo struct {
+	entries map[string]*domain.LaunchEntry
+	stats   domain.LaunchStats
+}
+
+func newStubRepo() *stubRepo {
+	return &stubRepo{entries: map[string]*domain.LaunchEntry{}, stats: domain.LaunchStats{Admitted: map[string]int{}}}
+}
+
+func (r *stubRepo) Put(_ context.Context, e *domain.LaunchEntry) error {
+	if _, ok := r.entries[e.Kind+"#"+e.Value]; ok {
+		return domain.ErrConflict
+	}
+	cp := *e
+	r.entries[e.Kind+"#"+e.Value] = &cp
+	return nil
+}
+
+func (r *stubRepo) Get(_ context.Context, kind, value string) (*domain.LaunchEntry, error) {
+	e, ok := r.entries[kind+"#"+value]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *e
+	return &cp, nil
+}
+
+func (r *stubRepo) List(_ context.Context) ([]domain.LaunchEntry, error) {
+	var out []domain.LaunchEntry
+	for _, e := range r.entries {
+		out = append(out, *e)
+	}
+	return out, nil
+}
+
+func (r *stubRepo) Delete(_ context.Context, kind, value string) error {
+	if _, ok := r.entries[kind+"#"+value]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.entries, kind+"#"+value)
+	return nil
+}
+
+func (r *stubRepo) Redeem(_ context.Context, kind, value string) error {
+	e, ok := r.entries[kind+"#"+value]
+	if !ok || (e.MaxUses > 0 && e.Uses >= e.MaxUses) {
+		return domain.ErrConflict
+	}
+	e.Uses++
+	return nil
+}
+
+func (r *stubRepo) CountGated(_ context.Context) error {
+	r.stats.Gated++
+	return nil
+}
+
+func (r *stubRepo) CountAdmitted(_ context.Context, kind string) error {
+	r.stats.Admitted[kind]++
+	return nil
+}
+
+func (r *stubRepo) Stats(_ context.Context) (domain.LaunchStats, error) {
+	return r.stats, nil
+}
+
+type stubAudit struct{ actions []string }
+
+func (a *stubAudit) Record(_ context.Context, e domain.AuditEntry) {
+	a.actions = append(a.actions, e.Action)
+}
+
+func newTestService(enabled bool) (*service, *stubRepo, *stubAudit) {
+	repo, audit := newStubRepo(), &stubAudit{}
+	svc := NewService(ServiceDeps{LaunchRepo: repo, Audit: audit, Enabled: enabled, Exempt: []string{"Admin@example.com"}}).(*service)
+	return svc, repo, audit
+}
+
+// register runs both hooks the way the user service does.
+func register(svc *service, email, code string) error {
+	req := &domain.CreateUserRequest{Email: email, InviteCode: code}
+	if err := svc.PreRegister(context.Background(), req); err != nil {
+		return err
+	}
+	return svc.PostRegister(context.Background(), &domain.User{Email: req.Email, InviteCode: req.InviteCode})
+}
+
+func TestPreRegister_GateOffAdmitsEveryone(t *testing.T) {
+	svc, repo, _ := newTestService(false)
+	req := &domain.CreateUserRequest{Email: "anyone@example.com", InviteCode: "whatever"}
+
+	require.NoError(t, svc.PreRegister(context.Background(), req))
+
+	assert.Empty(t, req.InviteCode, "an invite code is not kept while the gate is off")
+	assert.Zero(t, repo.stats.Gated)
+}
+
+func TestRegister_AdmitsAllowlistedEmailAndDomain(t *testing.T) {
+	svc, repo, audit := newTestService(true)
+	ctx := context.Background()
+	_, err := svc.Add(ctx, "admin", domain.CreateLaunchEntryRequest{Kind: domain.LaunchEmail, Value: " Jane@Example.com "})
+	require.NoError(t, err)
+	_, err = svc.Add(ctx, "admin", domain.CreateLaunchEntryRequest{Kind: domain.LaunchDomain, Value: "@Partner.io"})
+	require.NoError(t, err)
+
+	assert.NoError(t, register(svc, "jane@example.com", ""))
+	assert.NoError(t, register(svc, "bob@partner.io", ""))
+	err = register(svc, "eve@example.com", "")
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Equal(t, 1, repo.stats.Gated)
+	assert.Equal(t, map[string]int{domain.LaunchEmail: 1, domain.LaunchDomain: 1}, repo.stats.Admitted)
+	assert.Equal(t, []string{domain.AuditLaunchAllow, domain.AuditLaunchAllow}, audit.actions)
+}
+
+func TestRegister_InviteRunsOutOfUses(t *testing.T) {
+	svc, repo, _ := newTestService(true)
+	e, err := svc.Add(context.Background(), "admin", domain.CreateLaunchEntryRequest{Kind: domain.LaunchInvite, MaxUses: 2})
+	require.NoError(t, err)
+	require.Len(t, e.Value, inviteLength)
+
+	assert.NoError(t, register(svc, "a@example.com", e.Value))
+	assert.NoError(t, register(svc, "b@example.com", e.Value))
+	assert.ErrorIs(t, register(svc, "c@example.com", e.Value), domain.ErrForbidden)
+	assert.Equal(t, 2, repo.entries[domain.LaunchInvite+"#"+e.Value].Uses)
+	assert.ErrorIs(t, register(svc, "d@example.com", "NOPE"), domain.ErrForbidden)
+}
+
+func TestRegister_ExemptAddressBypassesGate(t *testing.T) {
+	svc, repo, _ := newTestService(true)
+
+	require.NoError(t, register(svc, "admin@example.com", ""))
+
+	assert.Zero(t, repo.stats.Gated)
+	assert.Empty(t, repo.stats.Admitted)
+}
+
+func TestPreRegister_DropsInviteCodeNotNeeded(t *testing.T) {
+	svc, _, _ := newTestService(true)
+	ctx := context.Background()
+	_, err := svc.Add(ctx, "admin", domain.CreateLaunchEntryRequest{Kind: domain.LaunchDomain, Value: "example.com"})
+	require.NoError(t, err)
+	req := &domain.CreateUserRequest{Email: "jane@example.com", InviteCode: "SOMECODE"}
+
+	require.NoError(t, svc.PreRegister(ctx, req))
+
+	assert.Empty(t, req.InviteCode)
+}
+
+func TestAdd_RejectsMalformedEntries(t *testing.T) {
+	svc, _, _ := newTestService(true)
+	ctx := context.Background()
+	for _, req := range []domain.CreateLaunchEntryRequest{
+		{Kind: domain.LaunchEmail, Value: "not-an-email"},
+		{Kind: domain.LaunchDomain, Value: "jane@example.com"},
+		{Kind: domain.LaunchDomain, Value: "localhost"},
+		{Kind: domain.LaunchEmail, Value: "jane@example.com", MaxUses: 3},
+	} {
+		_, err := svc.Add(ctx, "admin", req)
+		assert.ErrorIs(t, err, domain.ErrBadRequest, req.Value)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	svc, _, audit := newTestService(true)
+	ctx := context.Background()
+	_, err := svc.Add(ctx, "admin", domain.CreateLaunchEntryRequest{Kind: domain.LaunchEmail, Value: "jane@example.com"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Remove(ctx, "admin", domain.LaunchEmail, "jane@example.com"))
+
+	assert.ErrorIs(t, register(svc, "jane@example.com", ""), domain.ErrForbidden)
+	assert.ErrorIs(t, svc.Remove(ctx, "admin", domain.LaunchEmail, "jane@example.com"), domain.ErrNotFound)
+	assert.Contains(t, audit.actions, domain.AuditLaunchRevoke)
+}
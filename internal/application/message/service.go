@@ -0,0 +1,123 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+)
+
+type Service interface {
+	Send(ctx context.Context, senderID string, req domain.SendMessageRequest) (*domain.Message, error)
+	// List returns a page of the conversation between userID and otherUserID, newest first.
+	List(ctx context.Context, userID, otherUserID string, limit int, cursor string) ([]domain.Message, string, error)
+	// MarkRead marks every message otherUserID sent to userID as read and returns how many changed.
+	MarkRead(ctx context.Context, userID, otherUserID string) (int, error)
+	UnreadCounts(ctx context.Context, userID string) (*domain.UnreadCounts, error)
+}
+
+type messageStore interface {
+	Put(ctx context.Context, m *domain.Message) error
+	ListConversation(ctx context.Context, conversationID string, limit int32, cursor string) ([]domain.Message, string, error)
+	ListUnread(ctx context.Context, userID string) ([]domain.Message, error)
+	MarkRead(ctx context.Context, conversationID string, sentAt int64) error
+}
+
+type userStore interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+}
+
+type notifier interface {
+	Create(ctx context.Context, req domain.CreateNotificationRequest) (*domain.Notification, error)
+}
+
+type service struct {
+	repo     messageStore
+	userRepo userStore
+	notifier notifier
+}
+
+// NewService builds the messaging service. notifier may be nil to disable new-message alerts.
+func NewService(repo messageStore, userRepo userStore, notifier notifier) Service {
+	return &service{repo: repo, userRepo: userRepo, notifier: notifier}
+}
+
+func (s *service) Send(ctx context.Context, senderID string, req domain.SendMessageRequest) (*domain.Message, error) {
+	if req.RecipientID == senderID {
+		return nil, fmt.Errorf("cannot message yourself: %w", domain.ErrBadRequest)
+	}
+	recipient, err := s.userRepo.Get(ctx, req.RecipientID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.Enable != 1 {
+		return nil, fmt.Errorf("recipient not found: %w", domain.ErrNotFound)
+	}
+	now := time.Now().UTC()
+	m := &domain.Message{
+		ConversationID: domain.ConversationID(senderID, req.RecipientID),
+		SentAt:         now.UnixNano(),
+		MessageID:      id.New(),
+		SenderID:       senderID,
+		RecipientID:    req.RecipientID,
+		Body:           req.Body,
+		UnreadFor:      req.RecipientID,
+		CreatedAt:      now,
+	}
+	if err := s.repo.Put(ctx, m); err != nil {
+		return nil, err
+	}
+	s.alert(ctx, m)
+	return m, nil
+}
+
+// alert notifies the recipient in-app and by push. Failures are logged; the message is already stored.
+func (s *service) alert(ctx context.Context, m *domain.Message) {
+	if s.notifier == nil {
+		return
+	}
+	_, err := s.notifier.Create(ctx, domain.CreateNotificationRequest{
+		UserID:  m.RecipientID,
+		Message: "You have a new message",
+	})
+	if err != nil {
+		slog.Warn("failed to send new-message notification", "message_id", m.MessageID, "err", err)
+	}
+}
+
+func (s *service) List(ctx context.Context, userID, otherUserID string, limit int, cursor string) ([]domain.Message, string, error) {
+	return s.repo.ListConversation(ctx, domain.ConversationID(userID, otherUserID), int32(limit), cursor)
+}
+
+func (s *service) MarkRead(ctx context.Context, userID, otherUserID string) (int, error) {
+	unread, err := s.repo.ListUnread(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	marked := 0
+	for _, m := range unread {
+		if m.SenderID != otherUserID {
+			continue
+		}
+		if err := s.repo.MarkRead(ctx, m.ConversationID, m.SentAt); err != nil {
+			return marked, err
+		}
+		marked++
+	}
+	return marked, nil
+}
+
+func (s *service) UnreadCounts(ctx context.Context, userID string) (*domain.UnreadCounts, error) {
+	unread, err := s.repo.ListUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	counts := &domain.UnreadCounts{Total: len(unread), BySender: map[string]int{}}
+	for _, m := range unread {
+		counts.BySender[m.SenderID]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,121 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockMessageStore struct{ mock.Mock }
+
+func (m *mockMessageStore) Put(ctx context.Context, msg *domain.Message) error {
+	return m.Called(ctx, msg).Error(0)
+}
+
+func (m *mockMessageStore) ListConversation(ctx context.Context, conversationID string, limit int32, cursor string) ([]domain.Message, string, error) {
+	args := m.Called(ctx, conversationID, limit, cursor)
+	msgs, _ := args.Get(0).([]domain.Message)
+	return msgs, args.String(1), args.Error(2)
+}
+
+func (m *mockMessageStore) ListUnread(ctx context.Context, userID string) ([]domain.Message, error) {
+	args := m.Called(ctx, userID)
+	msgs, _ := args.Get(0).([]domain.Message)
+	return msgs, args.Error(1)
+}
+
+func (m *mockMessageStore) MarkRead(ctx context.Context, conversationID string, sentAt int64) error {
+	return m.Called(ctx, conversationID, sentAt).Error(0)
+}
+
+type mockUserStore struct{ mock.Mock }
+
+func (m *mockUserStore) Get(ctx context.Context, userID string) (*domain.User, error) {
+	args := m.Called(ctx, userID)
+	if u, _ := args.Get(0).(*domain.User); u != nil {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockNotifier struct{ mock.Mock }
+
+func (m *mockNotifier) Create(ctx context.Context, req domain.CreateNotificationRequest) (*domain.Notification, error) {
+	args := m.Called(ctx, req)
+	n, _ := args.Get(0).(*domain.Notification)
+	return n, args.Error(1)
+}
+
+// --- Send tests ---
+
+func TestSend_ToSelf(t *testing.T) {
+	_, err := NewService(nil, nil, nil).Send(context.Background(), "u1", domain.SendMessageRequest{RecipientID: "u1", Body: "hi"})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestSend_DisabledRecipient(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("Get", mock.Anything, "u2").Return(&domain.User{UserID: "u2", Enable: 0}, nil)
+
+	_, err := NewService(nil, us, nil).Send(context.Background(), "u1", domain.SendMessageRequest{RecipientID: "u2", Body: "hi"})
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestSend_StoresUnreadAndNotifies(t *testing.T) {
+	ms, us, nt := &mockMessageStore{}, &mockUserStore{}, &mockNotifier{}
+	us.On("Get", mock.Anything, "u2").Return(&domain.User{UserID: "u2", Enable: 1}, nil)
+	ms.On("Put", mock.Anything, mock.MatchedBy(func(m *domain.Message) bool {
+		return m.ConversationID == "u1#u2" && m.UnreadFor == "u2" && m.SentAt > 0
+	})).Return(nil)
+	nt.On("Create", mock.Anything, mock.MatchedBy(func(req domain.CreateNotificationRequest) bool {
+		return req.UserID == "u2"
+	})).Return(nil, errors.New("push down"))
+
+	m, err := NewService(ms, us, nt).Send(context.Background(), "u1", domain.SendMessageRequest{RecipientID: "u2", Body: "hi"})
+
+	require.NoError(t, err, "notification failures must not fail the send")
+	assert.Equal(t, "hi", m.Body)
+	ms.AssertExpectations(t)
+	nt.AssertExpectations(t)
+}
+
+// --- MarkRead / UnreadCounts tests ---
+
+func unreadFixture() []domain.Message {
+	return []domain.Message{
+		{ConversationID: "u1#u2", SentAt: 1, SenderID: "u2"},
+		{ConversationID: "u1#u2", SentAt: 2, SenderID: "u2"},
+		{ConversationID: "u1#u3", SentAt: 3, SenderID: "u3"},
+	}
+}
+
+func TestMarkRead_OnlyMessagesFromOtherUser(t *testing.T) {
+	ms := &mockMessageStore{}
+	ms.On("ListUnread", mock.Anything, "u1").Return(unreadFixture(), nil)
+	ms.On("MarkRead", mock.Anything, "u1#u2", mock.Anything).Return(nil)
+
+	n, err := NewService(ms, nil, nil).MarkRead(context.Background(), "u1", "u2")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	ms.AssertNotCalled(t, "MarkRead", mock.Anything, "u1#u3", mock.Anything)
+}
+
+func TestUnreadCounts(t *testing.T) {
+	ms := &mockMessageStore{}
+	ms.On("ListUnread", mock.Anything, "u1").Return(unreadFixture(), nil)
+
+	counts, err := NewService(ms, nil, nil).UnreadCounts(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, counts.Total)
+	assert.Equal(t, map[string]int{"u2": 2, "u3": 1}, counts.BySender)
+}
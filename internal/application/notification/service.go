@@ -2,35 +2,191 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
 )
 
 type Service interface {
 	ListUnread(ctx context.Context, userID string) ([]domain.Notification, error)
-	MarkAsRead(ctx context.Context, notificationID, userID string) (*domain.Notification, error)
+	// MarkAsRead marks a notification read. Only its recipient may do so, unless
+	// isAdmin is set; anyone else gets domain.ErrForbidden.
+	MarkAsRead(ctx context.Context, notificationID, requesterID string, isAdmin bool) (*domain.Notification, error)
+	// Dismiss soft-deletes one of userID's notifications; it disappears from
+	// their listings and is purged after the retention period.
+	Dismiss(ctx context.Context, notificationID, userID string) error
+	// DismissAll dismisses every notification userID can see, read or unread,
+	// and returns how many it dismissed.
+	DismissAll(ctx context.Context, userID string) (int, error)
+	// RecordReceipt stores a delivery or read event reported by the recipient's client.
+	// Only the first event per channel is kept.
+	RecordReceipt(ctx context.Context, notificationID, userID string, req domain.RecordReceiptRequest) error
+	Stats(ctx context.Context, notificationID string) (*domain.NotificationStats, error)
+	// Create delivers a notification now, or stores it for DeliverDue when SendAt
+	// or SendAtLocal is in the future.
+	Create(ctx context.Context, req domain.CreateNotificationRequest) (*domain.Notification, error)
+	UpdateScheduled(ctx context.Context, notificationID string, req domain.UpdateNotificationRequest) (*domain.Notification, error)
+	Cancel(ctx context.Context, notificationID string) error
+	// DeliverDue sends every scheduled notification whose time has come and returns how many it sent.
+	DeliverDue(ctx context.Context) (int, error)
 }
 
 type notificationStore interface {
 	ListUnread(ctx context.Context, userID string) ([]domain.Notification, error)
 	Get(ctx context.Context, notificationID string) (*domain.Notification, error)
-	MarkAsRead(ctx context.Context, notificationID string) (*domain.Notification, error)
+	MarkAsRead(ctx context.Context, notificationID, ownerID string) (*domain.Notification, error)
+	AddReceipt(ctx context.Context, notificationID string, rc domain.Receipt) error
+	Put(ctx context.Context, n *domain.Notification) error
+	ListDue(ctx context.Context, now time.Time) ([]domain.Notification, error)
+	UpdateScheduled(ctx context.Context, notificationID string, updates map[string]interface{}) error
+	CloseSchedule(ctx context.Context, notificationID, status string) error
+	SoftDelete(ctx context.Context, notificationID string, purgeAt int64) error
+	EachVisible(ctx context.Context, userID string, fn func(domain.Notification) error) error
 }
 
+type pusher interface {
+	Push(ctx context.Context, userID, message string) (int, error)
+}
+
+type templateRenderer interface {
+	Render(ctx context.Context, name string, params map[string]string) (*domain.NotificationTemplate, error)
+}
+
+type userLookup interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+}
+
+// DynamoDB attribute names used in partial update maps.
+const (
+	fieldMessage = "message"
+	fieldSendAt  = "send_at"
+	fieldDueAt   = "due_at"
+)
+
 type service struct {
-	repo notificationStore
+	repo      notificationStore
+	push      pusher
+	templates templateRenderer
+	users     userLookup
+	retention time.Duration
+}
+
+type ServiceDeps struct {
+	Repo      notificationStore
+	Push      pusher // optional; without it notifications are in-app only
+	Templates templateRenderer
+	Users     userLookup // resolves send_at_local in the recipient's time zone
+	// Retention is how long dismissed notifications are kept before the
+	// DynamoDB TTL purges them; zero keeps them.
+	Retention time.Duration
 }
 
-func NewService(repo notificationStore) Service {
-	return &service{repo: repo}
+func NewService(deps ServiceDeps) Service {
+	return &service{
+		repo:      deps.Repo,
+		push:      deps.Push,
+		templates: deps.Templates,
+		users:     deps.Users,
+		retention: deps.Retention,
+	}
 }
 
 func (s *service) ListUnread(ctx context.Context, userID string) ([]domain.Notification, error) {
 	return s.repo.ListUnread(ctx, userID)
 }
 
-func (s *service) MarkAsRead(ctx context.Context, notificationID, userID string) (*domain.Notification, error) {
+func (s *service) MarkAsRead(ctx context.Context, notificationID, requesterID string, isAdmin bool) (*domain.Notification, error) {
+	n, err := s.repo.Get(ctx, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	if n.UserID != requesterID && !isAdmin {
+		return nil, fmt.Errorf("forbidden: %w", domain.ErrForbidden)
+	}
+	if n.DeletedAt != nil {
+		return nil, fmt.Errorf("notification not found: %w", domain.ErrNotFound)
+	}
+	updated, err := s.repo.MarkAsRead(ctx, notificationID, n.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Only the recipient reading it counts as a read receipt.
+	if n.UserID != requesterID {
+		return updated, nil
+	}
+	// Receipts are analytics only; a failure here must not fail the read.
+	if err := s.addReceipt(ctx, n, domain.ChannelInApp, domain.ReceiptRead); err != nil {
+		slog.Warn("failed to record read receipt", "notification_id", notificationID, "err", err)
+	}
+	return updated, nil
+}
+
+func (s *service) Dismiss(ctx context.Context, notificationID, userID string) error {
+	if _, err := s.getOwned(ctx, notificationID, userID); err != nil {
+		return err
+	}
+	return s.repo.SoftDelete(ctx, notificationID, s.purgeAt())
+}
+
+func (s *service) DismissAll(ctx context.Context, userID string) (int, error) {
+	purgeAt := s.purgeAt()
+	dismissed := 0
+	err := s.repo.EachVisible(ctx, userID, func(n domain.Notification) error {
+		if err := s.repo.SoftDelete(ctx, n.NotificationID, purgeAt); err != nil {
+			return err
+		}
+		dismissed++
+		return nil
+	})
+	return dismissed, err
+}
+
+// purgeAt is the TTL for a notification dismissed now, or 0 to keep it.
+func (s *service) purgeAt() int64 {
+	if s.retention <= 0 {
+		return 0
+	}
+	return time.Now().Add(s.retention).Unix()
+}
+
+func (s *service) RecordReceipt(ctx context.Context, notificationID, userID string, req domain.RecordReceiptRequest) error {
+	n, err := s.getOwned(ctx, notificationID, userID)
+	if err != nil {
+		return err
+	}
+	return s.addReceipt(ctx, n, req.Channel, req.Event)
+}
+
+func (s *service) Stats(ctx context.Context, notificationID string) (*domain.NotificationStats, error) {
+	n, err := s.repo.Get(ctx, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	stats := &domain.NotificationStats{
+		NotificationID: n.NotificationID,
+		UserID:         n.UserID,
+		Channels:       map[string]domain.ChannelStats{},
+	}
+	for _, rc := range n.Receipts {
+		cs := stats.Channels[rc.Channel]
+		at := rc.At
+		switch rc.Event {
+		case domain.ReceiptDelivered:
+			cs.DeliveredAt = &at
+		case domain.ReceiptRead:
+			cs.ReadAt = &at
+		}
+		stats.Channels[rc.Channel] = cs
+	}
+	return stats, nil
+}
+
+// getOwned loads a notification addressed to userID that they have not dismissed.
+func (s *service) getOwned(ctx context.Context, notificationID, userID string) (*domain.Notification, error) {
 	n, err := s.repo.Get(ctx, notificationID)
 	if err != nil {
 		return nil, err
@@ -38,5 +194,164 @@ func (s *service) MarkAsRead(ctx context.Context, notificationID, userID string)
 	if n.UserID != userID {
 		return nil, fmt.Errorf("forbidden: %w", domain.ErrForbidden)
 	}
-	return s.repo.MarkAsRead(ctx, notificationID)
+	if n.DeletedAt != nil {
+		return nil, fmt.Errorf("notification not found: %w", domain.ErrNotFound)
+	}
+	return n, nil
+}
+
+// addReceipt appends a receipt unless one already exists for the same channel and event.
+func (s *service) addReceipt(ctx context.Context, n *domain.Notification, channel, event string) error {
+	for _, rc := range n.Receipts {
+		if rc.Channel == channel && rc.Event == event {
+			return nil
+		}
+	}
+	return s.repo.AddReceipt(ctx, n.NotificationID, domain.Receipt{
+		Channel: channel,
+		Event:   event,
+		At:      time.Now().UTC(),
+	})
+}
+
+func (s *service) Create(ctx context.Context, req domain.CreateNotificationRequest) (*domain.Notification, error) {
+	now := time.Now().UTC()
+	n := &domain.Notification{
+		NotificationID: id.New(),
+		UserID:         req.UserID,
+		Message:        req.Message,
+		Status:         domain.NotificationSent,
+		SendAt:         now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if req.Template != "" {
+		if err := s.applyTemplate(ctx, n, req); err != nil {
+			return nil, err
+		}
+	}
+	sendAt, err := s.sendAt(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	scheduled := sendAt != nil && sendAt.After(now)
+	if scheduled {
+		n.Status = domain.NotificationScheduled
+		n.SendAt = sendAt.UTC()
+		n.DueAt = n.SendAt.Unix()
+	}
+	if err := s.repo.Put(ctx, n); err != nil {
+		return nil, err
+	}
+	if !scheduled {
+		s.deliver(ctx, n)
+	}
+	return n, nil
+}
+
+// sendAt is when req asks the notification to go out: SendAtLocal read in
+// the recipient's time zone, or else SendAt.
+func (s *service) sendAt(ctx context.Context, req domain.CreateNotificationRequest) (*time.Time, error) {
+	if req.SendAtLocal == "" {
+		return req.SendAt, nil
+	}
+	u, err := s.users.Get(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := time.ParseInLocation(domain.LocalTimeLayout, req.SendAtLocal, u.Location())
+	if err != nil {
+		return nil, fmt.Errorf("send_at_local must be YYYY-MM-DDTHH:MM: %w", domain.ErrBadRequest)
+	}
+	return &t, nil
+}
+
+// applyTemplate fills n's category and per-channel text from the named template.
+func (s *service) applyTemplate(ctx context.Context, n *domain.Notification, req domain.CreateNotificationRequest) error {
+	t, err := s.templates.Render(ctx, req.Template, req.Params)
+	if err != nil {
+		return err
+	}
+	name := t.Name
+	n.TemplateID = &name
+	n.Category = t.Category
+	n.Message = t.Bodies[domain.ChannelInApp]
+	n.PushMessage = t.Bodies[domain.ChannelPush]
+	return nil
+}
+
+func (s *service) UpdateScheduled(ctx context.Context, notificationID string, req domain.UpdateNotificationRequest) (*domain.Notification, error) {
+	updates := map[string]interface{}{}
+	if req.Message != nil {
+		updates[fieldMessage] = *req.Message
+	}
+	if req.SendAt != nil {
+		if !req.SendAt.After(time.Now()) {
+			return nil, fmt.Errorf("send_at must be in the future: %w", domain.ErrBadRequest)
+		}
+		updates[fieldSendAt] = req.SendAt.UTC()
+		updates[fieldDueAt] = req.SendAt.Unix()
+	}
+	if _, err := s.repo.Get(ctx, notificationID); err != nil {
+		return nil, err
+	}
+	if len(updates) > 0 {
+		if err := s.repo.UpdateScheduled(ctx, notificationID, updates); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.Get(ctx, notificationID)
+}
+
+func (s *service) Cancel(ctx context.Context, notificationID string) error {
+	if _, err := s.repo.Get(ctx, notificationID); err != nil {
+		return err
+	}
+	return s.repo.CloseSchedule(ctx, notificationID, domain.NotificationCanceled)
+}
+
+func (s *service) DeliverDue(ctx context.Context) (int, error) {
+	due, err := s.repo.ListDue(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for i := range due {
+		n := &due[i]
+		// Claim first so a notification is delivered once even with several replicas polling.
+		if err := s.repo.CloseSchedule(ctx, n.NotificationID, domain.NotificationSent); err != nil {
+			if !errors.Is(err, domain.ErrConflict) {
+				slog.Warn("failed to claim scheduled notification", "notification_id", n.NotificationID, "err", err)
+			}
+			continue
+		}
+		s.deliver(ctx, n)
+		sent++
+	}
+	return sent, nil
+}
+
+// deliver pushes n to the user's devices and records delivery receipts. The
+// in-app copy is already visible once the notification is in the sent state.
+func (s *service) deliver(ctx context.Context, n *domain.Notification) {
+	if err := s.addReceipt(ctx, n, domain.ChannelInApp, domain.ReceiptDelivered); err != nil {
+		slog.Warn("failed to record delivery receipt", "notification_id", n.NotificationID, "err", err)
+	}
+	if s.push == nil {
+		return
+	}
+	msg := n.Message
+	if n.PushMessage != "" {
+		msg = n.PushMessage
+	}
+	pushed, err := s.push.Push(ctx, n.UserID, msg)
+	if err != nil {
+		slog.Warn("push delivery failed", "notification_id", n.NotificationID, "err", err)
+		return
+	}
+	if pushed > 0 {
+		if err := s.addReceipt(ctx, n, domain.ChannelPush, domain.ReceiptDelivered); err != nil {
+			slog.Warn("failed to record delivery receipt", "notification_id", n.NotificationID, "err", err)
+		}
+	}
 }
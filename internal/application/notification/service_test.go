@@ -0,0 +1,363 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockNotificationStore struct{ mock.Mock }
+
+func (m *mockNotificationStore) ListUnread(ctx context.Context, userID string) ([]domain.Notification, error) {
+	args := m.Called(ctx, userID)
+	ns, _ := args.Get(0).([]domain.Notification)
+	return ns, args.Error(1)
+}
+
+func (m *mockNotificationStore) Get(ctx context.Context, notificationID string) (*domain.Notification, error) {
+	args := m.Called(ctx, notificationID)
+	if n, _ := args.Get(0).(*domain.Notification); n != nil {
+		return n, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockNotificationStore) MarkAsRead(ctx context.Context, notificationID, ownerID string) (*domain.Notification, error) {
+	args := m.Called(ctx, notificationID, ownerID)
+	if n, _ := args.Get(0).(*domain.Notification); n != nil {
+		return n, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockNotificationStore) AddReceipt(ctx context.Context, notificationID string, rc domain.Receipt) error {
+	return m.Called(ctx, notificationID, rc).Error(0)
+}
+
+func (m *mockNotificationStore) Put(ctx context.Context, n *domain.Notification) error {
+	return m.Called(ctx, n).Error(0)
+}
+
+func (m *mockNotificationStore) ListDue(ctx context.Context, now time.Time) ([]domain.Notification, error) {
+	args := m.Called(ctx, now)
+	ns, _ := args.Get(0).([]domain.Notification)
+	return ns, args.Error(1)
+}
+
+func (m *mockNotificationStore) UpdateScheduled(ctx context.Context, notificationID string, updates map[string]interface{}) error {
+	return m.Called(ctx, notificationID, updates).Error(0)
+}
+
+func (m *mockNotificationStore) CloseSchedule(ctx context.Context, notificationID, status string) error {
+	return m.Called(ctx, notificationID, status).Error(0)
+}
+
+func (m *mockNotificationStore) SoftDelete(ctx context.Context, notificationID string, purgeAt int64) error {
+	return m.Called(ctx, notificationID, purgeAt).Error(0)
+}
+
+func (m *mockNotificationStore) EachVisible(ctx context.Context, userID string, fn func(domain.Notification) error) error {
+	args := m.Called(ctx, userID)
+	notifications, _ := args.Get(0).([]domain.Notification)
+	for _, n := range notifications {
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+type mockPusher struct{ mock.Mock }
+
+func (m *mockPusher) Push(ctx context.Context, userID, message string) (int, error) {
+	args := m.Called(ctx, userID, message)
+	return args.Int(0), args.Error(1)
+}
+
+func isReceipt(channel, event string) interface{} {
+	return mock.MatchedBy(func(rc domain.Receipt) bool {
+		return rc.Channel == channel && rc.Event == event && !rc.At.IsZero()
+	})
+}
+
+// --- MarkAsRead tests ---
+
+func TestMarkAsRead_RecordsInAppReadReceipt(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{NotificationID: "n1", UserID: "u1"}, nil)
+	repo.On("MarkAsRead", mock.Anything, "n1", "u1").Return(&domain.Notification{NotificationID: "n1", Readed: 1}, nil)
+	repo.On("AddReceipt", mock.Anything, "n1", isReceipt(domain.ChannelInApp, domain.ReceiptRead)).Return(nil)
+
+	n, err := NewService(ServiceDeps{Repo: repo}).MarkAsRead(context.Background(), "n1", "u1", false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n.Readed)
+	repo.AssertExpectations(t)
+}
+
+func TestMarkAsRead_OtherUserForbidden(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{NotificationID: "n1", UserID: "u2"}, nil)
+
+	_, err := NewService(ServiceDeps{Repo: repo}).MarkAsRead(context.Background(), "n1", "u1", false)
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	repo.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMarkAsRead_AdminExemptWithoutReceipt(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{NotificationID: "n1", UserID: "u2"}, nil)
+	repo.On("MarkAsRead", mock.Anything, "n1", "u2").Return(&domain.Notification{NotificationID: "n1", Readed: 1}, nil)
+
+	_, err := NewService(ServiceDeps{Repo: repo}).MarkAsRead(context.Background(), "n1", "admin", true)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "AddReceipt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- Dismiss tests ---
+
+func TestDismiss_SetsPurgeTTL(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{NotificationID: "n1", UserID: "u1"}, nil)
+	want := time.Now().Add(30 * 24 * time.Hour).Unix()
+	repo.On("SoftDelete", mock.Anything, "n1", mock.MatchedBy(func(purgeAt int64) bool {
+		return purgeAt >= want && purgeAt <= want+5
+	})).Return(nil)
+
+	err := NewService(ServiceDeps{Repo: repo, Retention: 30 * 24 * time.Hour}).Dismiss(context.Background(), "n1", "u1")
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestDismiss_NotOwner(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{NotificationID: "n1", UserID: "u2"}, nil)
+
+	err := NewService(ServiceDeps{Repo: repo}).Dismiss(context.Background(), "n1", "u1")
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	repo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDismiss_AlreadyDismissedIsNotFound(t *testing.T) {
+	repo := &mockNotificationStore{}
+	deleted := time.Now()
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{NotificationID: "n1", UserID: "u1", DeletedAt: &deleted}, nil)
+
+	err := NewService(ServiceDeps{Repo: repo}).Dismiss(context.Background(), "n1", "u1")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestDismissAll_DismissesEveryVisibleNotification(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("EachVisible", mock.Anything, "u1").Return([]domain.Notification{{NotificationID: "n1"}, {NotificationID: "n2"}}, nil)
+	repo.On("SoftDelete", mock.Anything, "n1", int64(0)).Return(nil)
+	repo.On("SoftDelete", mock.Anything, "n2", int64(0)).Return(nil)
+
+	n, err := NewService(ServiceDeps{Repo: repo}).DismissAll(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	repo.AssertExpectations(t)
+}
+
+// --- RecordReceipt tests ---
+
+func TestRecordReceipt_NotOwner(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{NotificationID: "n1", UserID: "u2"}, nil)
+
+	err := NewService(ServiceDeps{Repo: repo}).RecordReceipt(context.Background(), "n1", "u1", domain.RecordReceiptRequest{
+		Channel: domain.ChannelPush, Event: domain.ReceiptDelivered,
+	})
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	repo.AssertNotCalled(t, "AddReceipt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordReceipt_KeepsFirstEventOnly(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{
+		NotificationID: "n1",
+		UserID:         "u1",
+		Receipts:       []domain.Receipt{{Channel: domain.ChannelPush, Event: domain.ReceiptDelivered, At: time.Now()}},
+	}, nil)
+
+	err := NewService(ServiceDeps{Repo: repo}).RecordReceipt(context.Background(), "n1", "u1", domain.RecordReceiptRequest{
+		Channel: domain.ChannelPush, Event: domain.ReceiptDelivered,
+	})
+
+	require.NoError(t, err)
+	repo.AssertNotCalled(t, "AddReceipt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- Stats tests ---
+
+func TestStats_GroupsReceiptsByChannel(t *testing.T) {
+	delivered := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	read := delivered.Add(time.Hour)
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{
+		NotificationID: "n1",
+		UserID:         "u1",
+		Receipts: []domain.Receipt{
+			{Channel: domain.ChannelPush, Event: domain.ReceiptDelivered, At: delivered},
+			{Channel: domain.ChannelInApp, Event: domain.ReceiptRead, At: read},
+		},
+	}, nil)
+
+	stats, err := NewService(ServiceDeps{Repo: repo}).Stats(context.Background(), "n1")
+
+	require.NoError(t, err)
+	require.Len(t, stats.Channels, 2)
+	assert.Equal(t, delivered, *stats.Channels[domain.ChannelPush].DeliveredAt)
+	assert.Nil(t, stats.Channels[domain.ChannelPush].ReadAt)
+	assert.Equal(t, read, *stats.Channels[domain.ChannelInApp].ReadAt)
+}
+
+// --- Create tests ---
+
+func TestCreate_FutureSendAtIsScheduled(t *testing.T) {
+	repo := &mockNotificationStore{}
+	push := &mockPusher{}
+	sendAt := time.Now().Add(time.Hour)
+	repo.On("Put", mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Status == domain.NotificationScheduled && n.DueAt == sendAt.Unix()
+	})).Return(nil)
+
+	n, err := NewService(ServiceDeps{Repo: repo, Push: push}).Create(context.Background(), domain.CreateNotificationRequest{
+		UserID: "u1", Message: "hi", SendAt: &sendAt,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationScheduled, n.Status)
+	push.AssertNotCalled(t, "Push", mock.Anything, mock.Anything, mock.Anything)
+}
+
+type stubUsers map[string]*domain.User
+
+func (s stubUsers) Get(_ context.Context, userID string) (*domain.User, error) {
+	if u, ok := s[userID]; ok {
+		return u, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func TestCreate_SendAtLocalUsesRecipientTimezone(t *testing.T) {
+	repo := &mockNotificationStore{}
+	users := stubUsers{"u1": {UserID: "u1", Timezone: "America/New_York"}}
+	repo.On("Put", mock.Anything, mock.Anything).Return(nil)
+
+	n, err := NewService(ServiceDeps{Repo: repo, Users: users}).Create(context.Background(), domain.CreateNotificationRequest{
+		UserID: "u1", Message: "hi", SendAtLocal: "2099-01-15T09:00",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationScheduled, n.Status)
+	assert.Equal(t, time.Date(2099, 1, 15, 14, 0, 0, 0, time.UTC), n.SendAt)
+}
+
+func TestCreate_NoSendAtDeliversNow(t *testing.T) {
+	repo := &mockNotificationStore{}
+	push := &mockPusher{}
+	repo.On("Put", mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Status == domain.NotificationSent && n.DueAt == 0
+	})).Return(nil)
+	repo.On("AddReceipt", mock.Anything, mock.Anything, isReceipt(domain.ChannelInApp, domain.ReceiptDelivered)).Return(nil)
+	repo.On("AddReceipt", mock.Anything, mock.Anything, isReceipt(domain.ChannelPush, domain.ReceiptDelivered)).Return(nil)
+	push.On("Push", mock.Anything, "u1", "hi").Return(1, nil)
+
+	_, err := NewService(ServiceDeps{Repo: repo, Push: push}).Create(context.Background(), domain.CreateNotificationRequest{UserID: "u1", Message: "hi"})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+	push.AssertExpectations(t)
+}
+
+type mockRenderer struct{ mock.Mock }
+
+func (m *mockRenderer) Render(ctx context.Context, name string, params map[string]string) (*domain.NotificationTemplate, error) {
+	args := m.Called(ctx, name, params)
+	if t, _ := args.Get(0).(*domain.NotificationTemplate); t != nil {
+		return t, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestCreate_FromTemplateUsesPerChannelBodies(t *testing.T) {
+	repo := &mockNotificationStore{}
+	push := &mockPusher{}
+	tpl := &mockRenderer{}
+	params := map[string]string{"name": "Ada"}
+	tpl.On("Render", mock.Anything, "welcome", params).Return(&domain.NotificationTemplate{
+		Name:     "welcome",
+		Category: "account",
+		Bodies:   map[string]string{domain.ChannelInApp: "Welcome, Ada!", domain.ChannelPush: "Hi Ada"},
+	}, nil)
+	repo.On("Put", mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Message == "Welcome, Ada!" && n.Category == "account" && *n.TemplateID == "welcome"
+	})).Return(nil)
+	repo.On("AddReceipt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	push.On("Push", mock.Anything, "u1", "Hi Ada").Return(1, nil)
+
+	_, err := NewService(ServiceDeps{Repo: repo, Push: push, Templates: tpl}).Create(context.Background(), domain.CreateNotificationRequest{
+		UserID: "u1", Template: "welcome", Params: params,
+	})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+	push.AssertExpectations(t)
+}
+
+// --- UpdateScheduled / Cancel tests ---
+
+func TestUpdateScheduled_PastSendAt(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	_, err := NewService(ServiceDeps{Repo: &mockNotificationStore{}}).UpdateScheduled(context.Background(), "n1",
+		domain.UpdateNotificationRequest{SendAt: &past})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestCancel_AlreadySent(t *testing.T) {
+	repo := &mockNotificationStore{}
+	repo.On("Get", mock.Anything, "n1").Return(&domain.Notification{NotificationID: "n1"}, nil)
+	repo.On("CloseSchedule", mock.Anything, "n1", domain.NotificationCanceled).Return(domain.ErrConflict)
+
+	err := NewService(ServiceDeps{Repo: repo}).Cancel(context.Background(), "n1")
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+// --- DeliverDue tests ---
+
+func TestDeliverDue_SkipsNotificationsClaimedElsewhere(t *testing.T) {
+	repo := &mockNotificationStore{}
+	push := &mockPusher{}
+	repo.On("ListDue", mock.Anything, mock.Anything).Return([]domain.Notification{
+		{NotificationID: "n1", UserID: "u1", Message: "a"},
+		{NotificationID: "n2", UserID: "u2", Message: "b"},
+	}, nil)
+	repo.On("CloseSchedule", mock.Anything, "n1", domain.NotificationSent).Return(nil)
+	repo.On("CloseSchedule", mock.Anything, "n2", domain.NotificationSent).Return(domain.ErrConflict)
+	repo.On("AddReceipt", mock.Anything, "n1", isReceipt(domain.ChannelInApp, domain.ReceiptDelivered)).Return(nil)
+	push.On("Push", mock.Anything, "u1", "a").Return(0, nil)
+
+	sent, err := NewService(ServiceDeps{Repo: repo, Push: push}).DeliverDue(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	push.AssertNotCalled(t, "Push", mock.Anything, "u2", mock.Anything)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,118 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
+)
+
+func (s *service) Consent(ctx context.Context, req domain.AuthorizeRequest) (*domain.OAuthConsent, error) {
+	c, scopes, err := s.checkRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	consent := &domain.OAuthConsent{
+		ClientID:    c.ClientID,
+		ClientName:  c.Name,
+		Scopes:      make([]domain.OAuthScope, len(scopes)),
+		RedirectURI: req.RedirectURI,
+		State:       req.State,
+	}
+	for i, scope := range scopes {
+		consent.Scopes[i] = domain.OAuthScope{Name: scope, Description: domain.OAuthScopes[scope]}
+	}
+	return consent, nil
+}
+
+func (s *service) Authorize(ctx context.Context, userID string, req domain.AuthorizeRequest, approve bool) (string, error) {
+	c, scopes, err := s.checkRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if !approve {
+		return redirect(req.RedirectURI, map[string]string{"error": "access_denied", "state": req.State}), nil
+	}
+	code, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	// The refresh token index needs a value, so the session gets a random
+	// one that is replaced, and its expiry pushed out, when the code is
+	// exchanged. Until then the session is disabled.
+	placeholder, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	now := s.now().UTC()
+	sess := &domain.Session{
+		SessionID:        id.New(),
+		UserID:           userID,
+		RefreshToken:     placeholder,
+		RefreshExpiresAt: now.Add(s.codeTTL).Unix(),
+		ClientID:         c.ClientID,
+		Scopes:           scopes,
+		AuthCode:         code,
+		AuthCodeUntil:    now.Add(s.codeTTL).Unix(),
+		CodeChallenge:    req.CodeChallenge,
+		RedirectURI:      req.RedirectURI,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := s.sessions.Put(ctx, sess); err != nil {
+		return "", err
+	}
+	return redirect(req.RedirectURI, map[string]string{"code": sess.SessionID + "." + code, "state": req.State}), nil
+}
+
+// checkRequest returns the client of an authorization request and the
+// scopes it asks for, defaulting to every scope of the client.
+func (s *service) checkRequest(ctx context.Context, req domain.AuthorizeRequest) (*domain.OAuthClient, []string, error) {
+	c, err := s.clients.Get(ctx, req.ClientID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil, &domain.OAuthError{Code: "invalid_request", Description: "unknown client_id"}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case !slices.Contains(c.RedirectURIs, req.RedirectURI):
+		return nil, nil, &domain.OAuthError{Code: "invalid_request", Description: "redirect_uri is not registered for this client"}
+	case req.ResponseType != "code":
+		return nil, nil, &domain.OAuthError{Code: "unsupported_response_type", Description: "only response_type=code is supported"}
+	case req.CodeChallengeMethod != "S256":
+		return nil, nil, &domain.OAuthError{Code: "invalid_request", Description: "code_challenge_method must be S256"}
+	}
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		return c, c.Scopes, nil
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			return nil, nil, &domain.OAuthError{Code: "invalid_scope", Description: "scope " + scope + " is not allowed for this client"}
+		}
+	}
+	return c, scopes, nil
+}
+
+// redirect adds the non-empty params to the query of uri, which was
+// registered for the client and so parses.
+func redirect(uri string, params map[string]string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	q := u.Query()
+	for k, v := range params {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,147 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// Caller is who introspects or revokes a token: a client authenticating
+// with its credentials, or an admin, who may act on any token.
+type Caller struct {
+	ClientID     string
+	ClientSecret string
+	AdminID      string // set when an admin calls with a bearer token; the credentials are then unused
+}
+
+// tokenGrant is the session a token belongs to. Access is nil when the
+// token is a refresh token.
+type tokenGrant struct {
+	sess   *domain.Session
+	access *AccessToken
+}
+
+func (s *service) Introspect(ctx context.Context, c Caller, token string) (*domain.OAuthIntrospection, error) {
+	g, err := s.lookup(ctx, c, token)
+	if err != nil {
+		return nil, err
+	}
+	inactive := &domain.OAuthIntrospection{}
+	if g == nil || !g.sess.Enable {
+		return inactive, nil
+	}
+	if ok, err := s.live(ctx, g); err != nil || !ok {
+		return inactive, err
+	}
+	info := &domain.OAuthIntrospection{
+		Active:    true,
+		Scope:     strings.Join(g.sess.Scopes, " "),
+		ClientID:  g.sess.ClientID,
+		Subject:   g.sess.UserID,
+		ExpiresAt: g.sess.RefreshExpiresAt,
+	}
+	if at := g.access; at != nil {
+		info.Scope = strings.Join(at.Scopes, " ")
+		info.TokenType = "Bearer"
+		info.ExpiresAt, info.IssuedAt = at.ExpiresAt.Unix(), at.IssuedAt.Unix()
+	}
+	return info, nil
+}
+
+func (s *service) Revoke(ctx context.Context, c Caller, token string) error {
+	g, err := s.lookup(ctx, c, token)
+	if err != nil || g == nil || !g.sess.Enable {
+		return err
+	}
+	if err := s.sessions.Update(ctx, g.sess.SessionID, map[string]interface{}{fieldEnable: false}); err != nil {
+		return err
+	}
+	if c.AdminID != "" {
+		s.audit.Record(ctx, domain.AuditEntry{
+			Action:   domain.AuditOAuthTokenRevoke,
+			ActorID:  c.AdminID,
+			TargetID: g.sess.SessionID,
+			Details:  map[string]string{"user_id": g.sess.UserID, "client_id": g.sess.ClientID},
+		})
+	}
+	return nil
+}
+
+// lookup authenticates the caller and finds the grant token belongs to. It
+// returns nil for a token that is unknown, expired, or issued to another
+// client when the caller is a client.
+func (s *service) lookup(ctx context.Context, c Caller, token string) (*tokenGrant, error) {
+	clientID := ""
+	if c.AdminID == "" {
+		client, err := s.authenticate(ctx, c.ClientID, c.ClientSecret)
+		if err != nil {
+			return nil, err
+		}
+		clientID = client.ClientID
+	}
+	g, err := s.find(ctx, token)
+	if err != nil || g == nil {
+		return nil, err
+	}
+	if c.AdminID == "" && g.sess.ClientID != clientID {
+		return nil, nil
+	}
+	return g, nil
+}
+
+// find tries token as an access token, then as a refresh token.
+func (s *service) find(ctx context.Context, token string) (*tokenGrant, error) {
+	if token == "" {
+		return nil, nil
+	}
+	if at, err := s.tokens.Inspect(token); err == nil {
+		sess, err := s.sessions.Get(ctx, at.SessionID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if sess.UserID != at.UserID || sess.ClientID != at.ClientID {
+			return nil, nil
+		}
+		return &tokenGrant{sess: sess, access: at}, nil
+	}
+	sess, err := s.sessions.GetByRefreshToken(ctx, token)
+	if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrUnauthorized) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sess.RefreshExpiresAt < s.now().Unix() {
+		return nil, nil
+	}
+	return &tokenGrant{sess: sess}, nil
+}
+
+// live reports whether an enabled grant is still usable: its account is
+// active and, for the refresh token of a third-party grant, its client is
+// still registered.
+func (s *service) live(ctx context.Context, g *tokenGrant) (bool, error) {
+	u, err := s.users.Get(ctx, g.sess.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if u.DeletedAt != nil || u.Enable == 0 {
+		return false, nil
+	}
+	if g.access != nil || g.sess.ClientID == "" {
+		return true, nil
+	}
+	_, err = s.clients.Get(ctx, g.sess.ClientID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
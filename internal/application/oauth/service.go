@@ -0,0 +1,200 @@
+// Package oauth runs the API as an OAuth 2.0 authorization server for
+// third-party apps: the authorization code flow with PKCE, refresh tokens,
+// and token introspection and revocation. Each grant is a session in the
+// session store, so access tokens pass the session guard, and revoking the
+// session revokes the grant.
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
+)
+
+const (
+	defaultCodeTTL    = 5 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// Service registers OAuth clients and issues tokens to them.
+type Service interface {
+	CreateClient(ctx context.Context, actorID string, req domain.CreateOAuthClientRequest) (*domain.OAuthClientCreated, error)
+	ListClients(ctx context.Context) ([]domain.OAuthClient, error)
+	// DeleteClient removes a client. Its refresh tokens stop working at once;
+	// access tokens already issued stay valid until they expire.
+	DeleteClient(ctx context.Context, actorID, clientID string) error
+	// Consent checks an authorization request and describes it for the
+	// consent screen.
+	Consent(ctx context.Context, req domain.AuthorizeRequest) (*domain.OAuthConsent, error)
+	// Authorize answers an authorization request on behalf of userID and
+	// returns the URI to send the user back to: with an authorization code
+	// when approve is set, with error=access_denied otherwise.
+	Authorize(ctx context.Context, userID string, req domain.AuthorizeRequest, approve bool) (string, error)
+	// Token serves the authorization_code and refresh_token grants.
+	Token(ctx context.Context, req domain.OAuthTokenRequest) (*domain.OAuthToken, error)
+	// Introspect reports whether token, an access or refresh token, is
+	// active (RFC 7662). A client only sees the tokens issued to it; others
+	// are reported inactive. An admin sees every token, first-party ones too.
+	Introspect(ctx context.Context, c Caller, token string) (*domain.OAuthIntrospection, error)
+	// Revoke disables the session an access or refresh token belongs to
+	// (RFC 7009). Unknown tokens, and tokens a client may not see, are
+	// ignored.
+	Revoke(ctx context.Context, c Caller, token string) error
+}
+
+// Grant is what an access token is issued for.
+type Grant struct {
+	UserID    string
+	Role      string
+	SessionID string
+	ClientID  string
+	Scopes    []string
+}
+
+// AccessToken is a verified access token.
+type AccessToken struct {
+	Grant
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+}
+
+type tokenIssuer interface {
+	Issue(ctx context.Context, g Grant) (string, error)
+	// Inspect verifies token and returns its grant.
+	Inspect(token string) (*AccessToken, error)
+}
+
+type clientStore interface {
+	Put(ctx context.Context, c *domain.OAuthClient) error
+	Get(ctx context.Context, clientID string) (*domain.OAuthClient, error)
+	List(ctx context.Context) ([]domain.OAuthClient, error)
+	Delete(ctx context.Context, clientID string) error
+}
+
+type sessionStore interface {
+	Put(ctx context.Context, s *domain.Session) error
+	Get(ctx context.Context, sessionID string) (*domain.Session, error)
+	GetByRefreshToken(ctx context.Context, token string) (*domain.Session, error)
+	Update(ctx context.Context, sessionID string, updates map[string]interface{}) error
+	RedeemAuthCode(ctx context.Context, sessionID, code string, updates map[string]interface{}) error
+}
+
+type userGetter interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type service struct {
+	clients    clientStore
+	sessions   sessionStore
+	users      userGetter
+	tokens     tokenIssuer
+	audit      auditRecorder
+	accessTTL  time.Duration
+	codeTTL    time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+type ServiceDeps struct {
+	ClientRepo  clientStore
+	SessionRepo sessionStore
+	UserRepo    userGetter
+	Tokens      tokenIssuer
+	Audit       auditRecorder
+	AccessTTL   time.Duration // lifetime of the access tokens Tokens issues, reported as expires_in
+	CodeTTL     time.Duration // 0 means 5 minutes
+	RefreshTTL  time.Duration // 0 means 30 days
+}
+
+func NewService(deps ServiceDeps) Service {
+	s := &service{
+		clients:    deps.ClientRepo,
+		sessions:   deps.SessionRepo,
+		users:      deps.UserRepo,
+		tokens:     deps.Tokens,
+		audit:      deps.Audit,
+		accessTTL:  deps.AccessTTL,
+		codeTTL:    deps.CodeTTL,
+		refreshTTL: deps.RefreshTTL,
+		now:        time.Now,
+	}
+	if s.codeTTL <= 0 {
+		s.codeTTL = defaultCodeTTL
+	}
+	if s.refreshTTL <= 0 {
+		s.refreshTTL = defaultRefreshTTL
+	}
+	return s
+}
+
+func (s *service) CreateClient(ctx context.Context, actorID string, req domain.CreateOAuthClientRequest) (*domain.OAuthClientCreated, error) {
+	for _, scope := range req.Scopes {
+		if _, ok := domain.OAuthScopes[scope]; !ok {
+			return nil, fmt.Errorf("unknown scope %q: %w", scope, domain.ErrBadRequest)
+		}
+	}
+	c := &domain.OAuthClient{
+		ClientID:     id.New(),
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		Confidential: req.Confidential,
+		CreatedBy:    actorID,
+		CreatedAt:    s.now().UTC(),
+	}
+	created := &domain.OAuthClientCreated{OAuthClient: c}
+	if c.Confidential {
+		secret, err := pkgtoken.NewRefreshToken()
+		if err != nil {
+			return nil, err
+		}
+		created.ClientSecret, c.SecretHash = secret, hashSecret(secret)
+	}
+	if err := s.clients.Put(ctx, c); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditOAuthClientCreate,
+		ActorID:  actorID,
+		TargetID: c.ClientID,
+		Details:  map[string]string{"name": c.Name},
+	})
+	return created, nil
+}
+
+func (s *service) ListClients(ctx context.Context) ([]domain.OAuthClient, error) {
+	clients, err := s.clients.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].Name < clients[j].Name })
+	return clients, nil
+}
+
+func (s *service) DeleteClient(ctx context.Context, actorID, clientID string) error {
+	if err := s.clients.Delete(ctx, clientID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditOAuthClientDelete,
+		ActorID:  actorID,
+		TargetID: clientID,
+	})
+	return nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
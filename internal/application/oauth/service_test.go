@@ -0,0 +1,466 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubClients struct {
+	items map[string]*domain.OAuthClient
+}
+
+func (s *stubClients) Put(_ context.Context, c *domain.OAuthClient) error {
+	s.items[c.ClientID] = c
+	return nil
+}
+
+func (s *stubClients) Get(_ context.Context, clientID string) (*domain.OAuthClient, error) {
+	c, ok := s.items[clientID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *stubClients) List(context.Context) ([]domain.OAuthClient, error) {
+	var out []domain.OAuthClient
+	for _, c := range s.items {
+		out = append(out, *c)
+	}
+	return out, nil
+}
+
+func (s *stubClients) Delete(_ context.Context, clientID string) error {
+	if _, ok := s.items[clientID]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(s.items, clientID)
+	return nil
+}
+
+// stubSessions keeps sessions in memory and enforces the single use of
+// authorization codes like the DynamoDB repo.
+type stubSessions struct {
+	items map[string]*domain.Session
+}
+
+func (s *stubSessions) Put(_ context.Context, sess *domain.Session) error {
+	cp := *sess
+	s.items[sess.SessionID] = &cp
+	return nil
+}
+
+func (s *stubSessions) Get(_ context.Context, sessionID string) (*domain.Session, error) {
+	sess, ok := s.items[sessionID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *sess
+	return &cp, nil
+}
+
+func (s *stubSessions) GetByRefreshToken(_ context.Context, token string) (*domain.Session, error) {
+	for _, sess := range s.items {
+		if sess.RefreshToken == token && sess.Enable {
+			cp := *sess
+			return &cp, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (s *stubSessions) Update(_ context.Context, sessionID string, updates map[string]interface{}) error {
+	sess := s.items[sessionID]
+	if v, ok := updates[fieldEnable]; ok {
+		sess.Enable = v.(bool)
+	}
+	if v, ok := updates[fieldRefreshToken]; ok {
+		sess.RefreshToken = v.(string)
+	}
+	if v, ok := updates[fieldRefreshExpiresAt]; ok {
+		sess.RefreshExpiresAt = v.(int64)
+	}
+	if v, ok := updates[fieldAuthCode]; ok {
+		sess.AuthCode = v.(string)
+	}
+	return nil
+}
+
+func (s *stubSessions) RedeemAuthCode(ctx context.Context, sessionID, code string, updates map[string]interface{}) error {
+	if s.items[sessionID].AuthCode != code {
+		return domain.ErrConflict
+	}
+	return s.Update(ctx, sessionID, updates)
+}
+
+type stubUsers struct {
+	users map[string]*domain.User
+}
+
+func (s *stubUsers) Get(_ context.Context, userID string) (*domain.User, error) {
+	u, ok := s.users[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return u, nil
+}
+
+// stubTokens issues "<client>|<session>|<scopes>" as the access token.
+type stubTokens struct{}
+
+func (stubTokens) Issue(_ context.Context, g Grant) (string, error) {
+	return g.ClientID + "|" + g.SessionID + "|" + strings.Join(g.Scopes, " "), nil
+}
+
+func (stubTokens) Inspect(token string) (*AccessToken, error) {
+	parts := strings.Split(token, "|")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	now := time.Now()
+	return &AccessToken{
+		Grant:     Grant{UserID: "u1", ClientID: parts[0], SessionID: parts[1], Scopes: strings.Fields(parts[2])},
+		ExpiresAt: now.Add(time.Hour),
+		IssuedAt:  now,
+	}, nil
+}
+
+type stubAudit struct{ actions []string }
+
+func (a *stubAudit) Record(_ context.Context, e domain.AuditEntry) {
+	a.actions = append(a.actions, e.Action)
+}
+
+const (
+	redirectURI = "https://app.example.com/callback"
+	verifier    = "dBjftJeZ4CVP-mJ92K1YJxpnYHdq0MXuV8kfLqyCKRkg7wxAb3"
+)
+
+func challengeOf(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type fixture struct {
+	svc      *service
+	clients  *stubClients
+	sessions *stubSessions
+	users    *stubUsers
+	audit    *stubAudit
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		clients:  &stubClients{items: map[string]*domain.OAuthClient{}},
+		sessions: &stubSessions{items: map[string]*domain.Session{}},
+		users:    &stubUsers{users: map[string]*domain.User{"u1": {UserID: "u1", Role: domain.RoleUser, Enable: 1}}},
+		audit:    &stubAudit{},
+	}
+	f.svc = NewService(ServiceDeps{
+		ClientRepo:  f.clients,
+		SessionRepo: f.sessions,
+		UserRepo:    f.users,
+		Tokens:      stubTokens{},
+		Audit:       f.audit,
+		AccessTTL:   time.Hour,
+	}).(*service)
+	return f
+}
+
+func (f *fixture) client(t *testing.T, confidential bool) *domain.OAuthClientCreated {
+	c, err := f.svc.CreateClient(context.Background(), "admin", domain.CreateOAuthClientRequest{
+		Name:         "Photo printer",
+		RedirectURIs: []string{redirectURI},
+		Scopes:       []string{"profile:read", "files:read"},
+		Confidential: confidential,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func authorizeRequest(clientID string) domain.AuthorizeRequest {
+	return domain.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            clientID,
+		RedirectURI:         redirectURI,
+		State:               "xyz",
+		CodeChallenge:       challengeOf(verifier),
+		CodeChallengeMethod: "S256",
+	}
+}
+
+// approve runs the consent step and returns the authorization code.
+func (f *fixture) approve(t *testing.T, req domain.AuthorizeRequest) string {
+	to, err := f.svc.Authorize(context.Background(), "u1", req, true)
+	require.NoError(t, err)
+	u, err := url.Parse(to)
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", u.Query().Get("state"))
+	return u.Query().Get("code")
+}
+
+func TestCreateClient(t *testing.T) {
+	f := newFixture()
+
+	confidential := f.client(t, true)
+	public := f.client(t, false)
+
+	assert.NotEmpty(t, confidential.ClientSecret)
+	assert.Equal(t, hashSecret(confidential.ClientSecret), f.clients.items[confidential.ClientID].SecretHash)
+	assert.Empty(t, public.ClientSecret)
+	assert.Equal(t, []string{domain.AuditOAuthClientCreate, domain.AuditOAuthClientCreate}, f.audit.actions)
+
+	_, err := f.svc.CreateClient(context.Background(), "admin", domain.CreateOAuthClientRequest{
+		Name: "x", RedirectURIs: []string{redirectURI}, Scopes: []string{"admin"},
+	})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestConsent_DescribesRequestedScopes(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, false)
+	req := authorizeRequest(c.ClientID)
+	req.Scope = "files:read"
+
+	consent, err := f.svc.Consent(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Photo printer", consent.ClientName)
+	assert.Equal(t, []domain.OAuthScope{{Name: "files:read", Description: domain.OAuthScopes["files:read"]}}, consent.Scopes)
+}
+
+func TestConsent_RejectsBadRequests(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, false)
+	cases := map[string]func(*domain.AuthorizeRequest){
+		"unknown client":        func(r *domain.AuthorizeRequest) { r.ClientID = "nope" },
+		"unregistered redirect": func(r *domain.AuthorizeRequest) { r.RedirectURI = "https://evil.example.com/cb" },
+		"implicit flow":         func(r *domain.AuthorizeRequest) { r.ResponseType = "token" },
+		"plain challenge":       func(r *domain.AuthorizeRequest) { r.CodeChallengeMethod = "plain" },
+		"scope not allowed":     func(r *domain.AuthorizeRequest) { r.Scope = "files:write" },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := authorizeRequest(c.ClientID)
+			mutate(&req)
+			_, err := f.svc.Consent(context.Background(), req)
+			var oe *domain.OAuthError
+			require.ErrorAs(t, err, &oe)
+			assert.ErrorIs(t, err, domain.ErrBadRequest)
+		})
+	}
+}
+
+func TestAuthorize_DenyRedirectsWithError(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, false)
+
+	to, err := f.svc.Authorize(context.Background(), "u1", authorizeRequest(c.ClientID), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, redirectURI+"?error=access_denied&state=xyz", to)
+	assert.Empty(t, f.sessions.items)
+}
+
+func TestToken_CodeExchangeAndRefresh(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, true)
+	ctx := context.Background()
+	code := f.approve(t, authorizeRequest(c.ClientID))
+	sessionID, _, _ := strings.Cut(code, ".")
+	require.False(t, f.sessions.items[sessionID].Enable, "the grant waits for the code exchange")
+
+	tok, err := f.svc.Token(ctx, domain.OAuthTokenRequest{
+		GrantType: "authorization_code", Code: code, RedirectURI: redirectURI, CodeVerifier: verifier,
+		ClientID: c.ClientID, ClientSecret: c.ClientSecret,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "profile:read files:read", tok.Scope)
+	assert.Equal(t, int64(3600), tok.ExpiresIn)
+	assert.True(t, f.sessions.items[sessionID].Enable)
+
+	_, err = f.svc.Token(ctx, domain.OAuthTokenRequest{
+		GrantType: "authorization_code", Code: code, RedirectURI: redirectURI, CodeVerifier: verifier,
+		ClientID: c.ClientID, ClientSecret: c.ClientSecret,
+	})
+	assert.Equal(t, errInvalidCode, err, "a code is single-use")
+
+	refreshed, err := f.svc.Token(ctx, domain.OAuthTokenRequest{
+		GrantType: "refresh_token", RefreshToken: tok.RefreshToken, Scope: "files:read",
+		ClientID: c.ClientID, ClientSecret: c.ClientSecret,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "files:read", refreshed.Scope)
+	assert.NotEqual(t, tok.RefreshToken, refreshed.RefreshToken)
+}
+
+func TestToken_Rejections(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, true)
+	valid := domain.OAuthTokenRequest{
+		GrantType: "authorization_code", RedirectURI: redirectURI, CodeVerifier: verifier,
+		ClientID: c.ClientID, ClientSecret: c.ClientSecret,
+	}
+	cases := map[string]struct {
+		mutate func(*domain.OAuthTokenRequest)
+		code   string
+	}{
+		"wrong secret":       {func(r *domain.OAuthTokenRequest) { r.ClientSecret = "guess" }, "invalid_client"},
+		"wrong verifier":     {func(r *domain.OAuthTokenRequest) { r.CodeVerifier = strings.Repeat("a", 43) }, "invalid_grant"},
+		"other redirect":     {func(r *domain.OAuthTokenRequest) { r.RedirectURI = "https://app.example.com/other" }, "invalid_grant"},
+		"forged code":        {func(r *domain.OAuthTokenRequest) { r.Code += "x" }, "invalid_grant"},
+		"unsupported grant":  {func(r *domain.OAuthTokenRequest) { r.GrantType = "password" }, "unsupported_grant_type"},
+		"unknown refresh":    {func(r *domain.OAuthTokenRequest) { r.GrantType, r.RefreshToken = "refresh_token", "nope" }, "invalid_grant"},
+		"missing client id":  {func(r *domain.OAuthTokenRequest) { r.ClientID = "" }, "invalid_client"},
+		"another app's code": {func(r *domain.OAuthTokenRequest) { r.ClientID, r.ClientSecret = f.client(t, false).ClientID, "" }, "invalid_grant"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := valid
+			req.Code = f.approve(t, authorizeRequest(c.ClientID))
+			tc.mutate(&req)
+			_, err := f.svc.Token(context.Background(), req)
+			var oe *domain.OAuthError
+			require.ErrorAs(t, err, &oe)
+			assert.Equal(t, tc.code, oe.Code)
+		})
+	}
+}
+
+func TestToken_DisabledAccountCannotRedeem(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, false)
+	code := f.approve(t, authorizeRequest(c.ClientID))
+	f.users.users["u1"].Enable = 0
+
+	_, err := f.svc.Token(context.Background(), domain.OAuthTokenRequest{
+		GrantType: "authorization_code", Code: code, RedirectURI: redirectURI, CodeVerifier: verifier, ClientID: c.ClientID,
+	})
+
+	var oe *domain.OAuthError
+	require.ErrorAs(t, err, &oe)
+	assert.Equal(t, "invalid_grant", oe.Code)
+}
+
+// grant runs the code flow for c and returns the tokens.
+func (f *fixture) grant(t *testing.T, c *domain.OAuthClientCreated) *domain.OAuthToken {
+	tok, err := f.svc.Token(context.Background(), domain.OAuthTokenRequest{
+		GrantType: "authorization_code", Code: f.approve(t, authorizeRequest(c.ClientID)), RedirectURI: redirectURI,
+		CodeVerifier: verifier, ClientID: c.ClientID, ClientSecret: c.ClientSecret,
+	})
+	require.NoError(t, err)
+	return tok
+}
+
+func TestIntrospect(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, true)
+	other := f.client(t, true)
+	ctx := context.Background()
+	tok := f.grant(t, c)
+	caller := Caller{ClientID: c.ClientID, ClientSecret: c.ClientSecret}
+
+	got, err := f.svc.Introspect(ctx, caller, tok.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, got.Active)
+	assert.Equal(t, "u1", got.Subject)
+	assert.Equal(t, "Bearer", got.TokenType)
+	assert.Equal(t, "profile:read files:read", got.Scope)
+
+	got, err = f.svc.Introspect(ctx, caller, tok.RefreshToken)
+	require.NoError(t, err)
+	assert.True(t, got.Active, "refresh token")
+	assert.Empty(t, got.TokenType)
+
+	got, err = f.svc.Introspect(ctx, Caller{ClientID: other.ClientID, ClientSecret: other.ClientSecret}, tok.AccessToken)
+	require.NoError(t, err)
+	assert.False(t, got.Active, "another client's token")
+
+	got, err = f.svc.Introspect(ctx, Caller{AdminID: "admin"}, tok.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, got.Active, "admins see every token")
+
+	sessionID := strings.Split(tok.AccessToken, "|")[1]
+	f.sessions.items[sessionID].Enable = false
+	got, err = f.svc.Introspect(ctx, caller, tok.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.OAuthIntrospection{}, got, "a revoked grant")
+
+	_, err = f.svc.Introspect(ctx, Caller{ClientID: c.ClientID, ClientSecret: "wrong"}, tok.AccessToken)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
+
+func TestIntrospect_InactiveWhenAccountOrClientGone(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, true)
+	ctx := context.Background()
+	tok := f.grant(t, c)
+	admin := Caller{AdminID: "admin"}
+
+	require.NoError(t, f.svc.DeleteClient(ctx, "admin", c.ClientID))
+	got, err := f.svc.Introspect(ctx, admin, tok.RefreshToken)
+	require.NoError(t, err)
+	assert.False(t, got.Active, "refresh token of a deleted client")
+	got, err = f.svc.Introspect(ctx, admin, tok.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, got.Active, "access tokens live until they expire")
+
+	f.users.users["u1"].Enable = 0
+	got, err = f.svc.Introspect(ctx, admin, tok.AccessToken)
+	require.NoError(t, err)
+	assert.False(t, got.Active, "disabled account")
+}
+
+func TestRevoke(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, true)
+	other := f.client(t, true)
+	ctx := context.Background()
+	caller := Caller{ClientID: c.ClientID, ClientSecret: c.ClientSecret}
+
+	tok := f.grant(t, c)
+	sessionID := strings.Split(tok.AccessToken, "|")[1]
+	require.NoError(t, f.svc.Revoke(ctx, Caller{ClientID: other.ClientID, ClientSecret: other.ClientSecret}, tok.RefreshToken))
+	assert.True(t, f.sessions.items[sessionID].Enable, "another client's token is ignored")
+	require.NoError(t, f.svc.Revoke(ctx, caller, tok.RefreshToken))
+	assert.False(t, f.sessions.items[sessionID].Enable)
+	require.NoError(t, f.svc.Revoke(ctx, caller, tok.RefreshToken), "revoking twice")
+	require.NoError(t, f.svc.Revoke(ctx, caller, "unknown"))
+	assert.NotContains(t, f.audit.actions, domain.AuditOAuthTokenRevoke)
+
+	tok = f.grant(t, c)
+	sessionID = strings.Split(tok.AccessToken, "|")[1]
+	require.NoError(t, f.svc.Revoke(ctx, Caller{AdminID: "admin"}, tok.AccessToken))
+	assert.False(t, f.sessions.items[sessionID].Enable)
+	assert.Contains(t, f.audit.actions, domain.AuditOAuthTokenRevoke)
+
+	assert.ErrorIs(t, f.svc.Revoke(ctx, Caller{ClientID: c.ClientID}, tok.AccessToken), domain.ErrUnauthorized)
+}
+
+func TestDeleteClient_StopsRefresh(t *testing.T) {
+	f := newFixture()
+	c := f.client(t, false)
+	ctx := context.Background()
+	tok, err := f.svc.Token(ctx, domain.OAuthTokenRequest{
+		GrantType: "authorization_code", Code: f.approve(t, authorizeRequest(c.ClientID)), RedirectURI: redirectURI,
+		CodeVerifier: verifier, ClientID: c.ClientID,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, f.svc.DeleteClient(ctx, "admin", c.ClientID))
+
+	_, err = f.svc.Token(ctx, domain.OAuthTokenRequest{GrantType: "refresh_token", RefreshToken: tok.RefreshToken, ClientID: c.ClientID})
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	assert.Contains(t, f.audit.actions, domain.AuditOAuthClientDelete)
+	assert.ErrorIs(t, f.svc.DeleteClient(ctx, "admin", c.ClientID), domain.ErrNotFound)
+}
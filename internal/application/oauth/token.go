@@ -0,0 +1,188 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-api-nosql/internal/domain"
+	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
+)
+
+// DynamoDB attribute names used in partial update maps.
+const (
+	fieldEnable           = "enable"
+	fieldRefreshToken     = "refresh_token"
+	fieldRefreshExpiresAt = "refresh_expires_at"
+	fieldAuthCode         = "auth_code"
+	fieldAuthCodeUntil    = "auth_code_until"
+	fieldCodeChallenge    = "code_challenge"
+)
+
+var (
+	errInvalidClient = &domain.OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+	errInvalidCode   = &domain.OAuthError{Code: "invalid_grant", Description: "invalid or expired authorization code"}
+	errInvalidToken  = &domain.OAuthError{Code: "invalid_grant", Description: "invalid or expired refresh token"}
+)
+
+func (s *service) Token(ctx context.Context, req domain.OAuthTokenRequest) (*domain.OAuthToken, error) {
+	c, err := s.authenticate(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	switch req.GrantType {
+	case "authorization_code":
+		return s.exchangeCode(ctx, c, req)
+	case "refresh_token":
+		return s.refresh(ctx, c, req)
+	}
+	return nil, &domain.OAuthError{Code: "unsupported_grant_type", Description: "grant_type must be authorization_code or refresh_token"}
+}
+
+// authenticate returns the client clientID, checking the secret of a
+// confidential client. Public clients prove themselves through PKCE.
+func (s *service) authenticate(ctx context.Context, clientID, secret string) (*domain.OAuthClient, error) {
+	if clientID == "" {
+		return nil, errInvalidClient
+	}
+	c, err := s.clients.Get(ctx, clientID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, errInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.Confidential && subtle.ConstantTimeCompare([]byte(c.SecretHash), []byte(hashSecret(secret))) != 1 {
+		return nil, errInvalidClient
+	}
+	return c, nil
+}
+
+// exchangeCode redeems an authorization code of the form
+// "<session_id>.<secret>", enabling the session it names.
+func (s *service) exchangeCode(ctx context.Context, c *domain.OAuthClient, req domain.OAuthTokenRequest) (*domain.OAuthToken, error) {
+	sessionID, secret, ok := strings.Cut(req.Code, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return nil, errInvalidCode
+	}
+	sess, err := s.sessions.Get(ctx, sessionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, errInvalidCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	match := subtle.ConstantTimeCompare([]byte(sess.AuthCode), []byte(secret)) == 1
+	if !match || sess.ClientID != c.ClientID || sess.AuthCodeUntil < s.now().Unix() || sess.RedirectURI != req.RedirectURI {
+		return nil, errInvalidCode
+	}
+	if !verifierMatches(req.CodeVerifier, sess.CodeChallenge) {
+		return nil, &domain.OAuthError{Code: "invalid_grant", Description: "code_verifier does not match the code challenge"}
+	}
+	u, err := s.activeUser(ctx, sess.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if sess.RefreshToken, err = pkgtoken.NewRefreshToken(); err != nil {
+		return nil, err
+	}
+	err = s.sessions.RedeemAuthCode(ctx, sessionID, secret, map[string]interface{}{
+		fieldEnable:           true,
+		fieldAuthCode:         "",
+		fieldAuthCodeUntil:    0,
+		fieldCodeChallenge:    "",
+		fieldRefreshToken:     sess.RefreshToken,
+		fieldRefreshExpiresAt: s.now().Add(s.refreshTTL).Unix(),
+	})
+	if errors.Is(err, domain.ErrConflict) {
+		return nil, errInvalidCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, u, sess)
+}
+
+// refresh rotates the refresh token of a grant and issues a new access
+// token, for fewer scopes when req.Scope narrows them.
+func (s *service) refresh(ctx context.Context, c *domain.OAuthClient, req domain.OAuthTokenRequest) (*domain.OAuthToken, error) {
+	sess, err := s.sessions.GetByRefreshToken(ctx, req.RefreshToken)
+	if err != nil || sess.ClientID != c.ClientID || sess.RefreshExpiresAt < s.now().Unix() {
+		return nil, errInvalidToken
+	}
+	if narrowed := strings.Fields(req.Scope); len(narrowed) > 0 {
+		for _, scope := range narrowed {
+			if !slices.Contains(sess.Scopes, scope) {
+				return nil, &domain.OAuthError{Code: "invalid_scope", Description: "scope " + scope + " was not granted"}
+			}
+		}
+		sess.Scopes = narrowed
+	}
+	u, err := s.activeUser(ctx, sess.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if sess.RefreshToken, err = pkgtoken.NewRefreshToken(); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.Update(ctx, sess.SessionID, map[string]interface{}{
+		fieldRefreshToken:     sess.RefreshToken,
+		fieldRefreshExpiresAt: s.now().Add(s.refreshTTL).Unix(),
+	}); err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, u, sess)
+}
+
+// activeUser returns the user a grant belongs to, refusing the grant once
+// the account is disabled or deleted.
+func (s *service) activeUser(ctx context.Context, userID string) (*domain.User, error) {
+	u, err := s.users.Get(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, &domain.OAuthError{Code: "invalid_grant", Description: "account not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.DeletedAt != nil || u.Enable == 0 {
+		return nil, &domain.OAuthError{Code: "invalid_grant", Description: "account disabled"}
+	}
+	return u, nil
+}
+
+// issue signs an access token for sess, whose refresh token is current. The
+// token carries only the granted scopes the user's role holds.
+func (s *service) issue(ctx context.Context, u *domain.User, sess *domain.Session) (*domain.OAuthToken, error) {
+	scopes := domain.FilterScopes(sess.Scopes, u.Role)
+	access, err := s.tokens.Issue(ctx, Grant{
+		UserID:    u.UserID,
+		Role:      u.Role,
+		SessionID: sess.SessionID,
+		ClientID:  sess.ClientID,
+		Scopes:    scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sign access token: %w", err)
+	}
+	return &domain.OAuthToken{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+		RefreshToken: sess.RefreshToken,
+		Scope:        strings.Join(scopes, " "),
+	}, nil
+}
+
+// verifierMatches checks a PKCE code verifier against its S256 challenge.
+func verifierMatches(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 || challenge == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
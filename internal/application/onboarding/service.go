@@ -0,0 +1,183 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// welcomeCatchUp bounds the welcome emails Run sends for registrations the
+// PostRegister hook missed, so turning the feature on does not welcome
+// long-standing users.
+const welcomeCatchUp = 24 * time.Hour
+
+// Daytime steps go out between these local hours.
+const (
+	dayStart = 9
+	dayEnd   = 21
+)
+
+// Service sends the onboarding emails: a welcome on registration, a reminder
+// while the email is unconfirmed and a nudge while the profile is incomplete.
+type Service interface {
+	// PostRegister sends the welcome email; it is a user.PostRegisterHook.
+	PostRegister(ctx context.Context, u *domain.User) error
+	// Run sends every onboarding email that is due to users registered within
+	// the window and returns how many went out. Failed sends are retried on
+	// the next run.
+	Run(ctx context.Context) (int, error)
+}
+
+type userStore interface {
+	EachMatching(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error
+	ClaimOnboarding(ctx context.Context, userID, step string) (bool, error)
+	ReleaseOnboarding(ctx context.Context, userID, step string) error
+}
+
+// templateRenderer is template.Service's Render.
+type templateRenderer interface {
+	Render(ctx context.Context, name string, params map[string]string) (*domain.NotificationTemplate, error)
+}
+
+type mailer interface {
+	SendEmail(to, subject, body string) error
+}
+
+type service struct {
+	users     userStore
+	templates templateRenderer
+	mailer    mailer
+	steps     []step
+	window    time.Duration
+	now       func() time.Time
+}
+
+type ServiceDeps struct {
+	Users     userStore
+	Templates templateRenderer
+	Mailer    mailer
+	// ReminderAfter and ProfileNudgeAfter are the account ages at which the
+	// confirm-email reminder and the complete-profile nudge become due.
+	ReminderAfter     time.Duration
+	ProfileNudgeAfter time.Duration
+	// Window is the age past which users get no more onboarding emails.
+	Window time.Duration
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{
+		users:     deps.Users,
+		templates: deps.Templates,
+		mailer:    deps.Mailer,
+		steps:     steps(deps.ReminderAfter, deps.ProfileNudgeAfter),
+		window:    deps.Window,
+		now:       time.Now,
+	}
+}
+
+func (s *service) PostRegister(ctx context.Context, u *domain.User) error {
+	_, err := s.send(ctx, u, s.steps[0])
+	return err
+}
+
+func (s *service) Run(ctx context.Context) (int, error) {
+	enabled := 1
+	f := domain.UserFilter{
+		Enable:      &enabled,
+		CreatedFrom: s.now().UTC().Add(-s.window).Format("2006-01-02"),
+	}
+	sent := 0
+	var errs []error
+	err := s.users.EachMatching(ctx, f, func(u domain.User) error {
+		if u.DeletedAt != nil || u.OnboardOptOut {
+			return nil
+		}
+		age := s.now().Sub(u.CreatedAt)
+		if age > s.window {
+			return nil
+		}
+		for _, st := range s.steps {
+			if !st.due(&u, age) || (st.daytime && !s.daytime(&u)) {
+				continue
+			}
+			ok, err := s.send(ctx, &u, st)
+			if ok {
+				sent++
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s to %s: %w", st.name, u.UserID, err))
+			}
+		}
+		return ctx.Err()
+	})
+	return sent, errors.Join(append(errs, err)...)
+}
+
+// send emails st to u unless it was already sent, and reports whether it
+// went out. The step is claimed first so concurrent runs send it once, and
+// released again if the email cannot be sent.
+func (s *service) send(ctx context.Context, u *domain.User, st step) (bool, error) {
+	if slices.Contains(u.OnboardingSent, st.name) {
+		return false, nil
+	}
+	won, err := s.users.ClaimOnboarding(ctx, u.UserID, st.name)
+	if err != nil || !won {
+		return false, err
+	}
+	body, err := s.body(ctx, u, st)
+	if err == nil {
+		err = s.mailer.SendEmail(u.Email, st.subject, body)
+	}
+	if err != nil {
+		if rerr := s.users.ReleaseOnboarding(ctx, u.UserID, st.name); rerr != nil {
+			slog.Warn("onboarding: could not release claim", "step", st.name, "user_id", u.UserID, "error", rerr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// daytime reports whether it is daytime where u lives.
+func (s *service) daytime(u *domain.User) bool {
+	h := s.now().In(u.Location()).Hour()
+	return h >= dayStart && h < dayEnd
+}
+
+// body renders the email body of the step's template in u's locale, or the
+// built-in text when there is no such template with an email body.
+func (s *service) body(ctx context.Context, u *domain.User, st step) (string, error) {
+	params := map[string]string{"first_name": u.FirstName, "username": u.Username}
+	for _, name := range localized(domain.OnboardingTemplate(st.name), u.Locale) {
+		t, err := s.templates.Render(ctx, name, params)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if body := t.Bodies[domain.ChannelEmail]; body != "" {
+			return body, nil
+		}
+	}
+	return st.text(u), nil
+}
+
+// localized lists the templates tried for name, most specific first: the
+// full locale ("onboarding_welcome.pt-BR"), its language
+// ("onboarding_welcome.pt") and name itself.
+func localized(name, locale string) []string {
+	if locale == "" {
+		return []string{name}
+	}
+	names := []string{name + "." + locale}
+	if lang, _, ok := strings.Cut(locale, "-"); ok {
+		names = append(names, name+"."+lang)
+	}
+	return append(names, name)
+}
@@ -0,0 +1,190 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUsers struct {
+	users   []domain.User
+	claimed map[string]bool // userID + "/" + step
+}
+
+func (s *stubUsers) EachMatching(_ context.Context, _ domain.UserFilter, fn func(domain.User) error) error {
+	for _, u := range s.users {
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubUsers) ClaimOnboarding(_ context.Context, userID, step string) (bool, error) {
+	if s.claimed[userID+"/"+step] {
+		return false, nil
+	}
+	s.claimed[userID+"/"+step] = true
+	return true, nil
+}
+
+func (s *stubUsers) ReleaseOnboarding(_ context.Context, userID, step string) error {
+	delete(s.claimed, userID+"/"+step)
+	return nil
+}
+
+type stubTemplates struct {
+	bodies map[string]string // template name -> email body
+}
+
+func (s stubTemplates) Render(_ context.Context, name string, params map[string]string) (*domain.NotificationTemplate, error) {
+	body, ok := s.bodies[name]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &domain.NotificationTemplate{Bodies: map[string]string{domain.ChannelEmail: body + " " + params["first_name"]}}, nil
+}
+
+type sentMail struct{ to, subject, body string }
+
+type stubMailer struct {
+	sent []sentMail
+	err  error
+}
+
+func (m *stubMailer) SendEmail(to, subject, body string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, sentMail{to, subject, body})
+	return nil
+}
+
+// newTestService returns a service whose clock reads noon UTC today, so
+// daytime steps are due for users without a time zone.
+func newTestService(users *stubUsers, tpl stubTemplates, ml *stubMailer) *service {
+	svc := NewService(ServiceDeps{
+		Users:             users,
+		Templates:         tpl,
+		Mailer:            ml,
+		ReminderAfter:     3 * 24 * time.Hour,
+		ProfileNudgeAfter: 7 * 24 * time.Hour,
+		Window:            14 * 24 * time.Hour,
+	}).(*service)
+	noon := time.Now().UTC().Truncate(24 * time.Hour).Add(12 * time.Hour)
+	svc.now = func() time.Time { return noon }
+	return svc
+}
+
+func registered(id string, age time.Duration) domain.User {
+	phone := "+15550100"
+	return domain.User{
+		UserID: id, Username: id, Email: id + "@example.com", FirstName: "Jane", LastName: "Doe",
+		Phone: &phone, Birthday: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Enable: 1,
+		CreatedAt: time.Now().Add(-age),
+	}
+}
+
+func TestPostRegister_SendsWelcomeOnce(t *testing.T) {
+	users := &stubUsers{claimed: map[string]bool{}}
+	ml := &stubMailer{}
+	svc := newTestService(users, stubTemplates{}, ml)
+	u := registered("u1", 0)
+
+	require.NoError(t, svc.PostRegister(context.Background(), &u))
+	require.NoError(t, svc.PostRegister(context.Background(), &u))
+
+	require.Len(t, ml.sent, 1)
+	assert.Equal(t, "u1@example.com", ml.sent[0].to)
+	assert.Contains(t, ml.sent[0].body, "Hi Jane")
+}
+
+func TestRun_SendsDueStepsAndSkipsOptedOutAndSent(t *testing.T) {
+	unconfirmed := registered("late", 4*24*time.Hour)
+	incomplete := registered("bare", 8*24*time.Hour)
+	incomplete.EmailConfirmed, incomplete.Phone = true, nil
+	optedOut := registered("quiet", 8*24*time.Hour)
+	optedOut.OnboardOptOut = true
+	done := registered("done", 4*24*time.Hour)
+	done.OnboardingSent = []string{domain.OnboardingConfirmEmail}
+	users := &stubUsers{users: []domain.User{unconfirmed, incomplete, optedOut, done}, claimed: map[string]bool{}}
+	ml := &stubMailer{}
+	svc := newTestService(users, stubTemplates{}, ml)
+
+	sent, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	var got []string
+	for _, m := range ml.sent {
+		got = append(got, m.to+" "+m.subject)
+	}
+	assert.ElementsMatch(t, []string{
+		"late@example.com Please confirm your email",
+		"bare@example.com Complete your profile",
+	}, got)
+}
+
+func TestRun_UsesTemplateEmailBody(t *testing.T) {
+	u := registered("u1", time.Hour)
+	users := &stubUsers{users: []domain.User{u}, claimed: map[string]bool{}}
+	ml := &stubMailer{}
+	tpl := stubTemplates{bodies: map[string]string{domain.OnboardingTemplate(domain.OnboardingWelcome): "Hello"}}
+	svc := newTestService(users, tpl, ml)
+
+	_, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, ml.sent, 1)
+	assert.Equal(t, "Hello Jane", ml.sent[0].body)
+}
+
+func TestRun_RemindersWaitForLocalDaytime(t *testing.T) {
+	night := registered("tokyo", 4*24*time.Hour)
+	night.Timezone = "Asia/Tokyo" // noon UTC is 21:00 there
+	day := registered("madrid", 4*24*time.Hour)
+	day.Timezone = "Europe/Madrid"
+	users := &stubUsers{users: []domain.User{night, day}, claimed: map[string]bool{}}
+	ml := &stubMailer{}
+	svc := newTestService(users, stubTemplates{}, ml)
+
+	_, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, ml.sent, 1)
+	assert.Equal(t, "madrid@example.com", ml.sent[0].to)
+}
+
+func TestRun_UsesTemplateOfUserLocale(t *testing.T) {
+	u := registered("u1", time.Hour)
+	u.Locale = "pt-BR"
+	users := &stubUsers{users: []domain.User{u}, claimed: map[string]bool{}}
+	ml := &stubMailer{}
+	welcome := domain.OnboardingTemplate(domain.OnboardingWelcome)
+	tpl := stubTemplates{bodies: map[string]string{welcome: "Hello", welcome + ".pt": "Olá"}}
+	svc := newTestService(users, tpl, ml)
+
+	_, err := svc.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, ml.sent, 1)
+	assert.Equal(t, "Olá Jane", ml.sent[0].body)
+}
+
+func TestRun_FailedSendIsReleasedForRetry(t *testing.T) {
+	u := registered("u1", time.Hour)
+	users := &stubUsers{users: []domain.User{u}, claimed: map[string]bool{}}
+	ml := &stubMailer{err: errors.New("smtp down")}
+	svc := newTestService(users, stubTemplates{}, ml)
+
+	sent, err := svc.Run(context.Background())
+
+	assert.Error(t, err)
+	assert.Zero(t, sent)
+	assert.NotContains(t, users.claimed, "u1/"+domain.OnboardingWelcome, "the claim is released")
+}
@@ -0,0 +1,73 @@
+package onboarding
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// optOutNote closes the built-in reminder and nudge texts.
+const optOutNote = "\n\nNot interested? Turn off onboarding emails in your profile settings."
+
+// step is one onboarding email. text is its built-in body, used until an
+// admin creates the "onboarding_<name>" template with an email body. Daytime
+// steps wait until it is daytime in the recipient's time zone.
+type step struct {
+	name    string
+	subject string
+	daytime bool
+	due     func(u *domain.User, age time.Duration) bool
+	text    func(u *domain.User) string
+}
+
+// steps lists the onboarding emails; the welcome email comes first.
+func steps(reminderAfter, nudgeAfter time.Duration) []step {
+	return []step{
+		{
+			name:    domain.OnboardingWelcome,
+			subject: "Welcome aboard",
+			due:     func(_ *domain.User, age time.Duration) bool { return age < welcomeCatchUp },
+			text: func(u *domain.User) string {
+				return fmt.Sprintf("Hi %s,\n\nWelcome! Your account %s is ready.", greeting(u), u.Username)
+			},
+		},
+		{
+			name:    domain.OnboardingConfirmEmail,
+			subject: "Please confirm your email",
+			daytime: true,
+			due: func(u *domain.User, age time.Duration) bool {
+				return !u.EmailConfirmed && age >= reminderAfter
+			},
+			text: func(u *domain.User) string {
+				return fmt.Sprintf("Hi %s,\n\nYou have not confirmed your email address yet. "+
+					"Request a confirmation token from the app to finish setting up your account."+optOutNote, greeting(u))
+			},
+		},
+		{
+			name:    domain.OnboardingCompleteProfile,
+			subject: "Complete your profile",
+			daytime: true,
+			due: func(u *domain.User, age time.Duration) bool {
+				return profileIncomplete(u) && age >= nudgeAfter
+			},
+			text: func(u *domain.User) string {
+				return fmt.Sprintf("Hi %s,\n\nYour profile is missing a few details. "+
+					"Add your name, phone number and birthday to get the most out of your account."+optOutNote, greeting(u))
+			},
+		},
+	}
+}
+
+// profileIncomplete reports whether u lacks any of the optional profile
+// fields the nudge asks for.
+func profileIncomplete(u *domain.User) bool {
+	return len(u.MissingProfileFields()) > 0
+}
+
+func greeting(u *domain.User) string {
+	if u.FirstName != "" {
+		return u.FirstName
+	}
+	return u.Username
+}
@@ -0,0 +1,118 @@
+package overview
+
+import (
+	"context"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// Recent logins are the newest recentLoginLimit logins among the last
+// recentLoginScan activity feed entries.
+const (
+	recentLoginScan  = 50
+	recentLoginLimit = 10
+)
+
+type Service interface {
+	// Get aggregates a user's devices, active sessions, storage, notification
+	// counts and recent logins. Deleted users are ErrNotFound.
+	Get(ctx context.Context, userID string) (*domain.UserOverview, error)
+}
+
+type userStore interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+}
+
+type deviceStore interface {
+	ListByUser(ctx context.Context, userID string) ([]domain.Device, error)
+}
+
+type sessionStore interface {
+	ListActiveByUser(ctx context.Context, userID string) ([]domain.Session, error)
+}
+
+type fileStore interface {
+	UsageByUploader(ctx context.Context, userID string) (domain.StorageUsage, error)
+}
+
+type notificationStore interface {
+	CountByUser(ctx context.Context, userID string) (int, error)
+	ListUnread(ctx context.Context, userID string) ([]domain.Notification, error)
+}
+
+type activityStore interface {
+	ListByUser(ctx context.Context, userID string, limit int32, cursor string) ([]domain.Activity, string, error)
+}
+
+type ServiceDeps struct {
+	UserRepo         userStore
+	DeviceRepo       deviceStore
+	SessionRepo      sessionStore
+	FileRepo         fileStore
+	NotificationRepo notificationStore
+	ActivityRepo     activityStore
+}
+
+type service struct {
+	userRepo         userStore
+	deviceRepo       deviceStore
+	sessionRepo      sessionStore
+	fileRepo         fileStore
+	notificationRepo notificationStore
+	activityRepo     activityStore
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{
+		userRepo:         deps.UserRepo,
+		deviceRepo:       deps.DeviceRepo,
+		sessionRepo:      deps.SessionRepo,
+		fileRepo:         deps.FileRepo,
+		notificationRepo: deps.NotificationRepo,
+		activityRepo:     deps.ActivityRepo,
+	}
+}
+
+func (s *service) Get(ctx context.Context, userID string) (*domain.UserOverview, error) {
+	u, err := s.userRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	o := &domain.UserOverview{User: u}
+	if o.Devices, err = s.deviceRepo.ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if o.ActiveSessions, err = s.sessionRepo.ListActiveByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if o.Storage, err = s.fileRepo.UsageByUploader(ctx, userID); err != nil {
+		return nil, err
+	}
+	if o.NotificationsSent, err = s.notificationRepo.CountByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepo.ListUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	o.NotificationsUnread = len(unread)
+	if o.RecentLogins, err = s.recentLogins(ctx, userID); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// recentLogins picks login entries from the newest page of the activity feed.
+func (s *service) recentLogins(ctx context.Context, userID string) ([]domain.Activity, error) {
+	feed, _, err := s.activityRepo.ListByUser(ctx, userID, recentLoginScan, "")
+	if err != nil {
+		return nil, err
+	}
+	logins := []domain.Activity{}
+	for _, a := range feed {
+		if a.Kind == domain.ActivityLogin && len(logins) < recentLoginLimit {
+			logins = append(logins, a)
+		}
+	}
+	return logins, nil
+}
@@ -0,0 +1,118 @@
+package overview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- stubs ---
+
+type stubUsers struct{ err error }
+
+func (s *stubUsers) Get(_ context.Context, userID string) (*domain.User, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.User{UserID: userID}, nil
+}
+
+type stubDevices struct{}
+
+func (stubDevices) ListByUser(context.Context, string) ([]domain.Device, error) {
+	return []domain.Device{{DeviceID: "d1"}}, nil
+}
+
+type stubSessions struct{ err error }
+
+func (s *stubSessions) ListActiveByUser(context.Context, string) ([]domain.Session, error) {
+	return []domain.Session{{SessionID: "s1"}}, s.err
+}
+
+type stubFiles struct{}
+
+func (stubFiles) UsageByUploader(context.Context, string) (domain.StorageUsage, error) {
+	return domain.StorageUsage{Files: 2, Bytes: 300}, nil
+}
+
+type stubNotifications struct{}
+
+func (stubNotifications) CountByUser(context.Context, string) (int, error) { return 5, nil }
+
+func (stubNotifications) ListUnread(context.Context, string) ([]domain.Notification, error) {
+	return []domain.Notification{{}, {}}, nil
+}
+
+type stubActivity struct{ feed []domain.Activity }
+
+func (s *stubActivity) ListByUser(context.Context, string, int32, string) ([]domain.Activity, string, error) {
+	return s.feed, "", nil
+}
+
+func newTestService(users *stubUsers, sessions *stubSessions, activity *stubActivity) Service {
+	return NewService(ServiceDeps{
+		UserRepo:         users,
+		DeviceRepo:       stubDevices{},
+		SessionRepo:      sessions,
+		FileRepo:         stubFiles{},
+		NotificationRepo: stubNotifications{},
+		ActivityRepo:     activity,
+	})
+}
+
+// --- Get tests ---
+
+func TestGet_AggregatesAllSections(t *testing.T) {
+	feed := []domain.Activity{
+		{Subject: "a1", Kind: domain.ActivityLogin},
+		{Subject: "a2", Kind: domain.ActivityProfileUpdate},
+		{Subject: "a3", Kind: domain.ActivityLogin},
+	}
+	svc := newTestService(&stubUsers{}, &stubSessions{}, &stubActivity{feed: feed})
+
+	o, err := svc.Get(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "u1", o.User.UserID)
+	assert.Len(t, o.Devices, 1)
+	assert.Len(t, o.ActiveSessions, 1)
+	assert.Equal(t, domain.StorageUsage{Files: 2, Bytes: 300}, o.Storage)
+	assert.Equal(t, 5, o.NotificationsSent)
+	assert.Equal(t, 2, o.NotificationsUnread)
+	require.Len(t, o.RecentLogins, 2)
+	assert.Equal(t, "a1", o.RecentLogins[0].Subject)
+	assert.Equal(t, "a3", o.RecentLogins[1].Subject)
+}
+
+func TestGet_CapsRecentLogins(t *testing.T) {
+	feed := make([]domain.Activity, recentLoginLimit+5)
+	for i := range feed {
+		feed[i].Kind = domain.ActivityLogin
+	}
+	svc := newTestService(&stubUsers{}, &stubSessions{}, &stubActivity{feed: feed})
+
+	o, err := svc.Get(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.Len(t, o.RecentLogins, recentLoginLimit)
+}
+
+func TestGet_UnknownUserIsNotFound(t *testing.T) {
+	svc := newTestService(&stubUsers{err: domain.ErrNotFound}, &stubSessions{}, &stubActivity{})
+
+	_, err := svc.Get(context.Background(), "ghost")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestGet_PropagatesRepoErrors(t *testing.T) {
+	svc := newTestService(&stubUsers{}, &stubSessions{err: errors.New("throttled")}, &stubActivity{})
+
+	_, err := svc.Get(context.Background(), "u1")
+
+	assert.ErrorContains(t, err, "throttled")
+}
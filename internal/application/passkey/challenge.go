@@ -0,0 +1,79 @@
+package passkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/webauthn"
+)
+
+// issueChallenge stores a new challenge under userID, replacing any earlier
+// one of the same type.
+func (s *service) issueChallenge(ctx context.Context, userID, verType string) (string, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", err
+	}
+	return challenge, s.storeChallenge(ctx, userID, verType, challenge)
+}
+
+func (s *service) storeChallenge(ctx context.Context, key, verType, challenge string) error {
+	now := s.now()
+	return s.challenges.Put(ctx, &domain.UserVerification{
+		UserID:    key,
+		Type:      verType,
+		Challenge: challenge,
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(challengeTTL).Unix(),
+	})
+}
+
+// checkClientData checks the ceremony type and origin of clientDataJSON and
+// takes the challenge it signs: userID's registration challenge, or with an
+// empty userID the login challenge of that value.
+func (s *service) checkClientData(ctx context.Context, raw []byte, ceremony, userID string) error {
+	cd, err := webauthn.ParseClientData(raw)
+	if err != nil {
+		return err
+	}
+	if cd.Type != ceremony {
+		return fmt.Errorf("client data type %q, want %q: %w", cd.Type, ceremony, webauthn.ErrInvalid)
+	}
+	if !slices.Contains(s.origins, cd.Origin) {
+		return fmt.Errorf("origin %q is not allowed: %w", cd.Origin, webauthn.ErrInvalid)
+	}
+	key, verType := userID, domain.VerificationPasskeyRegister
+	if userID == "" {
+		key, verType = domain.PasskeyLoginKey(cd.Challenge), domain.VerificationPasskeyLogin
+	}
+	v, err := s.challenges.Take(ctx, key, verType)
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("unknown or used challenge: %w", webauthn.ErrInvalid)
+	}
+	if err != nil {
+		return err
+	}
+	if v.Challenge != cd.Challenge || v.ExpiresAt < s.now().Unix() {
+		return fmt.Errorf("challenge does not match or has expired: %w", webauthn.ErrInvalid)
+	}
+	return nil
+}
+
+// authenticatorData decodes authenticator data and checks it was made for
+// this relying party with the user present.
+func (s *service) authenticatorData(raw []byte) (webauthn.AuthenticatorData, error) {
+	a, err := webauthn.ParseAuthenticatorData(raw)
+	if err != nil {
+		return a, err
+	}
+	if a.RPIDHash != webauthn.RPIDHash(s.rp.ID) {
+		return a, fmt.Errorf("authenticator data is for another relying party: %w", webauthn.ErrInvalid)
+	}
+	if !a.UserPresent() {
+		return a, fmt.Errorf("user presence flag not set: %w", webauthn.ErrInvalid)
+	}
+	return a, nil
+}
@@ -0,0 +1,285 @@
+package passkey
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/webauthn"
+)
+
+const (
+	challengeTTL = 5 * time.Minute
+	defaultName  = "Passkey"
+)
+
+// Service registers passkeys and checks the assertions they sign at login.
+// Challenges live in the verification table and are taken, so each one
+// completes a single ceremony.
+type Service interface {
+	// BeginRegistration issues the options userID's authenticator creates a
+	// passkey from.
+	BeginRegistration(ctx context.Context, userID string) (*domain.PasskeyCreationOptions, error)
+	// Register stores the passkey created from the last registration
+	// challenge. A response that does not verify wraps domain.ErrBadRequest.
+	Register(ctx context.Context, userID string, req domain.RegisterPasskeyRequest) (*domain.Passkey, error)
+	List(ctx context.Context, userID string) ([]domain.Passkey, error)
+	Delete(ctx context.Context, userID, credentialID string) error
+	// BeginLogin issues the options a login assertion is signed over.
+	BeginLogin(ctx context.Context) (*domain.PasskeyRequestOptions, error)
+	// Verify checks a login assertion and returns the ID of the passkey's
+	// owner. Failures wrap domain.ErrUnauthorized.
+	Verify(ctx context.Context, a domain.PasskeyAssertion) (string, error)
+}
+
+type passkeyStore interface {
+	Put(ctx context.Context, p *domain.Passkey) error
+	Get(ctx context.Context, credentialID string) (*domain.Passkey, error)
+	ListByUser(ctx context.Context, userID string) ([]domain.Passkey, error)
+	Delete(ctx context.Context, userID, credentialID string) error
+	RecordUse(ctx context.Context, credentialID string, signCount uint32, at time.Time) error
+}
+
+type challengeStore interface {
+	Put(ctx context.Context, v *domain.UserVerification) error
+	Take(ctx context.Context, userID, verType string) (*domain.UserVerification, error)
+}
+
+type userGetter interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type service struct {
+	repo       passkeyStore
+	challenges challengeStore
+	users      userGetter
+	audit      auditRecorder
+	rp         domain.PasskeyRP
+	origins    []string
+	now        func() time.Time
+}
+
+type ServiceDeps struct {
+	PasskeyRepo      passkeyStore
+	VerificationRepo challengeStore
+	UserRepo         userGetter
+	Audit            auditRecorder
+	RPID             string   // WEBAUTHN_RP_ID, the domain passkeys are bound to
+	RPName           string   // WEBAUTHN_RP_NAME, shown by authenticators
+	Origins          []string // WEBAUTHN_ORIGINS, where ceremonies may run
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{
+		repo:       deps.PasskeyRepo,
+		challenges: deps.VerificationRepo,
+		users:      deps.UserRepo,
+		audit:      deps.Audit,
+		rp:         domain.PasskeyRP{ID: deps.RPID, Name: deps.RPName},
+		origins:    deps.Origins,
+		now:        time.Now,
+	}
+}
+
+func (s *service) BeginRegistration(ctx context.Context, userID string) (*domain.PasskeyCreationOptions, error) {
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := s.issueChallenge(ctx, userID, domain.VerificationPasskeyRegister)
+	if err != nil {
+		return nil, err
+	}
+	opts := &domain.PasskeyCreationOptions{
+		Challenge: challenge,
+		RP:        s.rp,
+		User: domain.PasskeyUser{
+			ID:          base64.RawURLEncoding.EncodeToString([]byte(userID)),
+			Name:        u.Email,
+			DisplayName: u.FirstName + " " + u.LastName,
+		},
+		Timeout:            challengeTTL.Milliseconds(),
+		ExcludeCredentials: make([]domain.PasskeyCredentialRef, len(existing)),
+		Attestation:        "none",
+		ResidentKey:        "required",
+		UserVerification:   "preferred",
+	}
+	for _, alg := range webauthn.Algorithms {
+		opts.PubKeyCredParams = append(opts.PubKeyCredParams, domain.PasskeyCredentialParam{Type: "public-key", Alg: alg})
+	}
+	for i, p := range existing {
+		opts.ExcludeCredentials[i] = domain.PasskeyCredentialRef{Type: "public-key", ID: p.CredentialID}
+	}
+	return opts, nil
+}
+
+func (s *service) Register(ctx context.Context, userID string, req domain.RegisterPasskeyRequest) (*domain.Passkey, error) {
+	p, err := s.checkRegistration(ctx, userID, req)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrInvalid) {
+			return nil, fmt.Errorf("%v: %w", err, domain.ErrBadRequest)
+		}
+		return nil, err
+	}
+	if err := s.repo.Put(ctx, p); err != nil {
+		return nil, err
+	}
+	if s.audit != nil {
+		s.audit.Record(ctx, domain.AuditEntry{
+			Action:   domain.AuditPasskeyRegister,
+			ActorID:  userID,
+			TargetID: p.CredentialID,
+			Details:  map[string]string{"name": p.Name, "algorithm": strconv.Itoa(p.Algorithm)},
+		})
+	}
+	return p, nil
+}
+
+// checkRegistration verifies a registration response against userID's
+// challenge and returns the passkey it creates.
+func (s *service) checkRegistration(ctx context.Context, userID string, req domain.RegisterPasskeyRequest) (*domain.Passkey, error) {
+	clientDataJSON, err := webauthn.Decode("client_data_json", req.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkClientData(ctx, clientDataJSON, webauthn.TypeCreate, userID); err != nil {
+		return nil, err
+	}
+	authDataRaw, err := webauthn.Decode("authenticator_data", req.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	authData, err := s.authenticatorData(authDataRaw)
+	if err != nil {
+		return nil, err
+	}
+	credentialID, err := webauthn.Decode("id", req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(authData.CredentialID) == 0 || !bytes.Equal(authData.CredentialID, credentialID) {
+		return nil, fmt.Errorf("credential ID does not match the authenticator data: %w", webauthn.ErrInvalid)
+	}
+	if !slices.Contains(webauthn.Algorithms, req.PublicKeyAlgorithm) {
+		return nil, fmt.Errorf("unsupported algorithm %d: %w", req.PublicKeyAlgorithm, webauthn.ErrInvalid)
+	}
+	publicKey, err := webauthn.Decode("public_key", req.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := webauthn.ParseKey(publicKey, req.PublicKeyAlgorithm); err != nil {
+		return nil, err
+	}
+	name := req.Name
+	if name == "" {
+		name = defaultName
+	}
+	return &domain.Passkey{
+		CredentialID: base64.RawURLEncoding.EncodeToString(credentialID),
+		UserID:       userID,
+		Name:         name,
+		PublicKey:    publicKey,
+		Algorithm:    req.PublicKeyAlgorithm,
+		SignCount:    authData.SignCount,
+		CreatedAt:    s.now().UTC(),
+	}, nil
+}
+
+func (s *service) List(ctx context.Context, userID string) ([]domain.Passkey, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Delete(ctx context.Context, userID, credentialID string) error {
+	if err := s.repo.Delete(ctx, userID, credentialID); err != nil {
+		return err
+	}
+	if s.audit != nil {
+		s.audit.Record(ctx, domain.AuditEntry{Action: domain.AuditPasskeyDelete, ActorID: userID, TargetID: credentialID})
+	}
+	return nil
+}
+
+func (s *service) BeginLogin(ctx context.Context) (*domain.PasskeyRequestOptions, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storeChallenge(ctx, domain.PasskeyLoginKey(challenge), domain.VerificationPasskeyLogin, challenge); err != nil {
+		return nil, err
+	}
+	return &domain.PasskeyRequestOptions{
+		Challenge:        challenge,
+		RPID:             s.rp.ID,
+		Timeout:          challengeTTL.Milliseconds(),
+		UserVerification: "preferred",
+	}, nil
+}
+
+func (s *service) Verify(ctx context.Context, a domain.PasskeyAssertion) (string, error) {
+	userID, err := s.checkAssertion(ctx, a)
+	if errors.Is(err, webauthn.ErrInvalid) || errors.Is(err, domain.ErrNotFound) {
+		return "", fmt.Errorf("passkey login failed: %v: %w", err, domain.ErrUnauthorized)
+	}
+	return userID, err
+}
+
+// checkAssertion verifies a login assertion against its challenge and the
+// stored passkey, then records the new signature counter.
+func (s *service) checkAssertion(ctx context.Context, a domain.PasskeyAssertion) (string, error) {
+	clientDataJSON, err := webauthn.Decode("client_data_json", a.ClientDataJSON)
+	if err != nil {
+		return "", err
+	}
+	if err := s.checkClientData(ctx, clientDataJSON, webauthn.TypeGet, ""); err != nil {
+		return "", err
+	}
+	credentialID, err := webauthn.Decode("id", a.ID)
+	if err != nil {
+		return "", err
+	}
+	p, err := s.repo.Get(ctx, base64.RawURLEncoding.EncodeToString(credentialID))
+	if err != nil {
+		return "", err
+	}
+	if a.UserHandle != "" {
+		if handle, err := webauthn.Decode("user_handle", a.UserHandle); err != nil || string(handle) != p.UserID {
+			return "", fmt.Errorf("user handle does not match the passkey: %w", webauthn.ErrInvalid)
+		}
+	}
+	authDataRaw, err := webauthn.Decode("authenticator_data", a.AuthenticatorData)
+	if err != nil {
+		return "", err
+	}
+	authData, err := s.authenticatorData(authDataRaw)
+	if err != nil {
+		return "", err
+	}
+	sig, err := webauthn.Decode("signature", a.Signature)
+	if err != nil {
+		return "", err
+	}
+	if err := webauthn.VerifySignature(p.PublicKey, p.Algorithm, authDataRaw, clientDataJSON, sig); err != nil {
+		return "", err
+	}
+	if err := s.repo.RecordUse(ctx, p.CredentialID, authData.SignCount, s.now().UTC()); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return "", fmt.Errorf("signature counter went backwards: %w", webauthn.ErrInvalid)
+		}
+		return "", err
+	}
+	return p.UserID, nil
+}
@@ -0,0 +1,348 @@
+package passkey
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+var b64 = base64.RawURLEncoding
+
+type stubPasskeys struct {
+	items map[string]*domain.Passkey
+}
+
+func (r *stubPasskeys) Put(_ context.Context, p *domain.Passkey) error {
+	if _, ok := r.items[p.CredentialID]; ok {
+		return domain.ErrConflict
+	}
+	cp := *p
+	r.items[p.CredentialID] = &cp
+	return nil
+}
+
+func (r *stubPasskeys) Get(_ context.Context, credentialID string) (*domain.Passkey, error) {
+	p, ok := r.items[credentialID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (r *stubPasskeys) ListByUser(_ context.Context, userID string) ([]domain.Passkey, error) {
+	out := []domain.Passkey{}
+	for _, p := range r.items {
+		if p.UserID == userID {
+			out = append(out, *p)
+		}
+	}
+	return out, nil
+}
+
+func (r *stubPasskeys) Delete(_ context.Context, userID, credentialID string) error {
+	p, ok := r.items[credentialID]
+	if !ok || p.UserID != userID {
+		return domain.ErrNotFound
+	}
+	delete(r.items, credentialID)
+	return nil
+}
+
+func (r *stubPasskeys) RecordUse(_ context.Context, credentialID string, signCount uint32, at time.Time) error {
+	p := r.items[credentialID]
+	if signCount != 0 && signCount <= p.SignCount {
+		return domain.ErrConflict
+	}
+	p.SignCount, p.LastUsedAt = signCount, &at
+	return nil
+}
+
+type stubChallenges struct {
+	items map[string]domain.UserVerification
+}
+
+func (s *stubChallenges) Put(_ context.Context, v *domain.UserVerification) error {
+	s.items[v.UserID+"/"+v.Type] = *v
+	return nil
+}
+
+func (s *stubChallenges) Take(_ context.Context, userID, verType string) (*domain.UserVerification, error) {
+	v, ok := s.items[userID+"/"+verType]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	delete(s.items, userID+"/"+verType)
+	return &v, nil
+}
+
+type stubUsers struct{}
+
+func (stubUsers) Get(_ context.Context, userID string) (*domain.User, error) {
+	return &domain.User{UserID: userID, Email: userID + "@example.com", FirstName: "Ada", LastName: "Lovelace"}, nil
+}
+
+type stubAudit struct{ actions []string }
+
+func (a *stubAudit) Record(_ context.Context, e domain.AuditEntry) {
+	a.actions = append(a.actions, e.Action)
+}
+
+// authenticator stands in for a platform authenticator holding one P-256
+// credential.
+type authenticator struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+}
+
+func newAuthenticator(t *testing.T) *authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &authenticator{key: key, id: []byte("credential-" + t.Name())}
+}
+
+func clientData(ceremony, challenge, origin string) []byte {
+	raw, _ := json.Marshal(webauthn.ClientData{Type: ceremony, Challenge: challenge, Origin: origin})
+	return raw
+}
+
+// authData builds authenticator data for rpID, with attested credential
+// data when attested is set.
+func (a *authenticator) authData(rpID string, attested bool) []byte {
+	hash := webauthn.RPIDHash(rpID)
+	flags := byte(0x01)
+	if attested {
+		flags |= 0x40
+	}
+	out := append(hash[:], flags)
+	out = binary.BigEndian.AppendUint32(out, a.signCount)
+	if attested {
+		out = append(out, make([]byte, 16)...) // aaguid
+		out = binary.BigEndian.AppendUint16(out, uint16(len(a.id)))
+		out = append(out, a.id...)
+	}
+	return out
+}
+
+func (a *authenticator) register(t *testing.T, challenge string) domain.RegisterPasskeyRequest {
+	spki, err := x509.MarshalPKIXPublicKey(&a.key.PublicKey)
+	require.NoError(t, err)
+	return domain.RegisterPasskeyRequest{
+		Name:               "Laptop",
+		ID:                 b64.EncodeToString(a.id),
+		ClientDataJSON:     b64.EncodeToString(clientData(webauthn.TypeCreate, challenge, testOrigin)),
+		AuthenticatorData:  b64.EncodeToString(a.authData(testRPID, true)),
+		PublicKey:          b64.EncodeToString(spki),
+		PublicKeyAlgorithm: webauthn.AlgES256,
+	}
+}
+
+func (a *authenticator) assert(t *testing.T, challenge, userID string) domain.PasskeyAssertion {
+	a.signCount++
+	cd := clientData(webauthn.TypeGet, challenge, testOrigin)
+	ad := a.authData(testRPID, false)
+	clientHash := sha256.Sum256(cd)
+	digest := sha256.Sum256(append(append([]byte{}, ad...), clientHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+	return domain.PasskeyAssertion{
+		ID:                b64.EncodeToString(a.id),
+		ClientDataJSON:    b64.EncodeToString(cd),
+		AuthenticatorData: b64.EncodeToString(ad),
+		Signature:         b64.EncodeToString(sig),
+		UserHandle:        b64.EncodeToString([]byte(userID)),
+	}
+}
+
+type fixture struct {
+	svc        *service
+	passkeys   *stubPasskeys
+	challenges *stubChallenges
+	audit      *stubAudit
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		passkeys:   &stubPasskeys{items: map[string]*domain.Passkey{}},
+		challenges: &stubChallenges{items: map[string]domain.UserVerification{}},
+		audit:      &stubAudit{},
+	}
+	f.svc = NewService(ServiceDeps{
+		PasskeyRepo:      f.passkeys,
+		VerificationRepo: f.challenges,
+		UserRepo:         stubUsers{},
+		Audit:            f.audit,
+		RPID:             testRPID,
+		RPName:           "Example",
+		Origins:          []string{testOrigin},
+	}).(*service)
+	return f
+}
+
+// registered runs the registration ceremony for userID with a.
+func (f *fixture) registered(t *testing.T, userID string, a *authenticator) *domain.Passkey {
+	ctx := context.Background()
+	opts, err := f.svc.BeginRegistration(ctx, userID)
+	require.NoError(t, err)
+	p, err := f.svc.Register(ctx, userID, a.register(t, opts.Challenge))
+	require.NoError(t, err)
+	return p
+}
+
+func TestRegisterThenLogin(t *testing.T) {
+	f := newFixture()
+	a := newAuthenticator(t)
+	ctx := context.Background()
+
+	p := f.registered(t, "u1", a)
+	assert.Equal(t, "Laptop", p.Name)
+	assert.Equal(t, []string{domain.AuditPasskeyRegister}, f.audit.actions)
+
+	opts, err := f.svc.BeginLogin(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, testRPID, opts.RPID)
+	userID, err := f.svc.Verify(ctx, a.assert(t, opts.Challenge, "u1"))
+	require.NoError(t, err)
+	assert.Equal(t, "u1", userID)
+	assert.Equal(t, uint32(1), f.passkeys.items[p.CredentialID].SignCount)
+	assert.NotNil(t, f.passkeys.items[p.CredentialID].LastUsedAt)
+}
+
+func TestBeginRegistration_ExcludesExistingPasskeys(t *testing.T) {
+	f := newFixture()
+	p := f.registered(t, "u1", newAuthenticator(t))
+
+	opts, err := f.svc.BeginRegistration(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PasskeyCredentialRef{{Type: "public-key", ID: p.CredentialID}}, opts.ExcludeCredentials)
+	assert.Equal(t, b64.EncodeToString([]byte("u1")), opts.User.ID)
+}
+
+func TestRegister_RejectsBadResponses(t *testing.T) {
+	cases := map[string]func(req *domain.RegisterPasskeyRequest, a *authenticator){
+		"wrong origin": func(req *domain.RegisterPasskeyRequest, _ *authenticator) {
+			req.ClientDataJSON = b64.EncodeToString(clientData(webauthn.TypeCreate, "x", "https://evil.example"))
+		},
+		"wrong relying party": func(req *domain.RegisterPasskeyRequest, a *authenticator) {
+			req.AuthenticatorData = b64.EncodeToString(a.authData("evil.example", true))
+		},
+		"credential ID mismatch": func(req *domain.RegisterPasskeyRequest, _ *authenticator) {
+			req.ID = b64.EncodeToString([]byte("other"))
+		},
+		"key does not match algorithm": func(req *domain.RegisterPasskeyRequest, _ *authenticator) {
+			req.PublicKeyAlgorithm = webauthn.AlgEdDSA
+		},
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture()
+			a := newAuthenticator(t)
+			ctx := context.Background()
+			opts, err := f.svc.BeginRegistration(ctx, "u1")
+			require.NoError(t, err)
+			req := a.register(t, opts.Challenge)
+			mutate(&req, a)
+
+			_, err = f.svc.Register(ctx, "u1", req)
+
+			assert.ErrorIs(t, err, domain.ErrBadRequest)
+			assert.Empty(t, f.passkeys.items)
+		})
+	}
+}
+
+func TestRegister_ChallengeIsSingleUse(t *testing.T) {
+	f := newFixture()
+	a := newAuthenticator(t)
+	ctx := context.Background()
+	opts, err := f.svc.BeginRegistration(ctx, "u1")
+	require.NoError(t, err)
+	req := a.register(t, opts.Challenge)
+	_, err = f.svc.Register(ctx, "u1", req)
+	require.NoError(t, err)
+
+	_, err = f.svc.Register(ctx, "u1", req)
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestVerify_RejectsReplayAndForgery(t *testing.T) {
+	f := newFixture()
+	a := newAuthenticator(t)
+	ctx := context.Background()
+	f.registered(t, "u1", a)
+
+	opts, _ := f.svc.BeginLogin(ctx)
+	assertion := a.assert(t, opts.Challenge, "u1")
+	_, err := f.svc.Verify(ctx, assertion)
+	require.NoError(t, err)
+	_, err = f.svc.Verify(ctx, assertion)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized, "a used challenge is refused")
+
+	opts, _ = f.svc.BeginLogin(ctx)
+	forged := a.assert(t, opts.Challenge, "u1")
+	forged.Signature = b64.EncodeToString([]byte("not a signature"))
+	_, err = f.svc.Verify(ctx, forged)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+
+	opts, _ = f.svc.BeginLogin(ctx)
+	_, err = f.svc.Verify(ctx, a.assert(t, opts.Challenge, "u2"))
+	assert.ErrorIs(t, err, domain.ErrUnauthorized, "the user handle must name the owner")
+}
+
+func TestVerify_RejectsCounterGoingBackwards(t *testing.T) {
+	f := newFixture()
+	a := newAuthenticator(t)
+	ctx := context.Background()
+	p := f.registered(t, "u1", a)
+	f.passkeys.items[p.CredentialID].SignCount = 10
+
+	opts, _ := f.svc.BeginLogin(ctx)
+	_, err := f.svc.Verify(ctx, a.assert(t, opts.Challenge, "u1"))
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
+
+func TestVerify_RejectsExpiredChallenge(t *testing.T) {
+	f := newFixture()
+	a := newAuthenticator(t)
+	ctx := context.Background()
+	f.registered(t, "u1", a)
+	opts, _ := f.svc.BeginLogin(ctx)
+	f.svc.now = func() time.Time { return time.Now().Add(challengeTTL + time.Minute) }
+
+	_, err := f.svc.Verify(ctx, a.assert(t, opts.Challenge, "u1"))
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
+
+func TestDelete(t *testing.T) {
+	f := newFixture()
+	p := f.registered(t, "u1", newAuthenticator(t))
+	ctx := context.Background()
+
+	assert.ErrorIs(t, f.svc.Delete(ctx, "u2", p.CredentialID), domain.ErrNotFound)
+	require.NoError(t, f.svc.Delete(ctx, "u1", p.CredentialID))
+	assert.Empty(t, f.passkeys.items)
+	assert.Contains(t, f.audit.actions, domain.AuditPasskeyDelete)
+}
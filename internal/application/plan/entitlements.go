@@ -0,0 +1,100 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+func (s *service) Entitlements(ctx context.Context, userID string) (domain.Entitlements, error) {
+	s.mu.Lock()
+	c, ok := s.cache[userID]
+	s.mu.Unlock()
+	if ok && s.now().Before(c.expires) {
+		return c.ent, nil
+	}
+	ent, err := s.resolve(ctx, userID)
+	if err != nil {
+		return domain.Entitlements{}, err
+	}
+	s.store(userID, ent)
+	return ent, nil
+}
+
+// resolve reads the entitlements of userID's plan. A plan that no longer
+// exists falls back to domain.PlanFree; without that either, nothing is limited.
+func (s *service) resolve(ctx context.Context, userID string) (domain.Entitlements, error) {
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return domain.Entitlements{}, err
+	}
+	name := u.Plan
+	if name == "" {
+		name = domain.PlanFree
+	}
+	p, err := s.repo.Get(ctx, name)
+	if errors.Is(err, domain.ErrNotFound) && name != domain.PlanFree {
+		slog.Warn("user plan not found; using the free plan", "user_id", userID, "plan", name)
+		p, err = s.repo.Get(ctx, domain.PlanFree)
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.Entitlements{}, nil
+	}
+	if err != nil {
+		return domain.Entitlements{}, err
+	}
+	return p.Entitlements, nil
+}
+
+func (s *service) store(userID string, ent domain.Entitlements) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if len(s.cache) >= maxCached {
+		for id, c := range s.cache {
+			if !now.Before(c.expires) {
+				delete(s.cache, id)
+			}
+		}
+	}
+	s.cache[userID] = cached{ent: ent, expires: now.Add(cacheTTL)}
+}
+
+func (s *service) CheckStorage(ctx context.Context, userID string, size int64) error {
+	ent, err := s.Entitlements(ctx, userID)
+	if err != nil || ent.StorageBytes == 0 {
+		return err
+	}
+	used, err := s.files.UsageByUploader(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if used.Bytes+size > ent.StorageBytes {
+		return fmt.Errorf("storage quota of %d bytes exceeded: %w", ent.StorageBytes, domain.ErrForbidden)
+	}
+	return nil
+}
+
+func (s *service) CheckNewDevice(ctx context.Context, userID string) error {
+	ent, err := s.Entitlements(ctx, userID)
+	if err != nil || ent.MaxDevices == 0 {
+		return err
+	}
+	devices, err := s.devices.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	enabled := 0
+	for _, d := range devices {
+		if d.Enable {
+			enabled++
+		}
+	}
+	if enabled >= ent.MaxDevices {
+		return fmt.Errorf("device limit of %d reached: %w", ent.MaxDevices, domain.ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package plan manages billing plans and resolves the entitlements, such as
+// storage, device and request limits, that a user's plan grants.
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// DynamoDB attribute names used in partial update maps.
+const (
+	fieldDescription  = "description"
+	fieldEntitlements = "entitlements"
+	fieldPlan         = "plan"
+)
+
+// cacheTTL bounds how long a user's resolved entitlements are reused, so a
+// plan change reaches every replica within it.
+const cacheTTL = time.Minute
+
+// maxCached is the cache size past which expired entries are dropped.
+const maxCached = 10000
+
+type Service interface {
+	List(ctx context.Context) ([]domain.Plan, error)
+	Get(ctx context.Context, name string) (*domain.Plan, error)
+	Create(ctx context.Context, req domain.CreatePlanRequest) (*domain.Plan, error)
+	Update(ctx context.Context, name string, input domain.PlanInput) (*domain.Plan, error)
+	Delete(ctx context.Context, name string) error // hard delete; domain.PlanFree is rejected
+	// EnsureBuiltins creates domain.PlanFree, with no limits, if it is missing.
+	EnsureBuiltins(ctx context.Context) error
+	// Assign puts userID on the plan name and audits actorID as the one who did.
+	Assign(ctx context.Context, actorID, userID, name string) (*domain.User, error)
+	// Entitlements returns what userID's plan grants. Users without a plan,
+	// or whose plan was deleted, get domain.PlanFree's.
+	Entitlements(ctx context.Context, userID string) (domain.Entitlements, error)
+	// CheckStorage returns a domain.ErrForbidden error when size more bytes
+	// would take userID past their plan's storage.
+	CheckStorage(ctx context.Context, userID string, size int64) error
+	// CheckNewDevice returns a domain.ErrForbidden error when userID already
+	// has as many enabled devices as their plan allows.
+	CheckNewDevice(ctx context.Context, userID string) error
+}
+
+type planStore interface {
+	Create(ctx context.Context, p *domain.Plan) error
+	Get(ctx context.Context, name string) (*domain.Plan, error)
+	List(ctx context.Context) ([]domain.Plan, error)
+	Update(ctx context.Context, name string, updates map[string]interface{}) error
+	HardDelete(ctx context.Context, name string) error
+}
+
+type userStore interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+	Update(ctx context.Context, userID string, updates map[string]interface{}) error
+}
+
+type storageCounter interface {
+	UsageByUploader(ctx context.Context, userID string) (domain.StorageUsage, error)
+}
+
+type deviceLister interface {
+	ListByUser(ctx context.Context, userID string) ([]domain.Device, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type ServiceDeps struct {
+	PlanRepo planStore
+	UserRepo userStore
+	FileRepo storageCounter
+	Devices  deviceLister
+	Audit    auditRecorder
+}
+
+type cached struct {
+	ent     domain.Entitlements
+	expires time.Time
+}
+
+type service struct {
+	repo    planStore
+	users   userStore
+	files   storageCounter
+	devices deviceLister
+	audit   auditRecorder
+	now     func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached // user_id -> entitlements
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{
+		repo:    deps.PlanRepo,
+		users:   deps.UserRepo,
+		files:   deps.FileRepo,
+		devices: deps.Devices,
+		audit:   deps.Audit,
+		now:     time.Now,
+		cache:   make(map[string]cached),
+	}
+}
+
+// List returns plans sorted by name.
+func (s *service) List(ctx context.Context) ([]domain.Plan, error) {
+	plans, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].Name < plans[j].Name })
+	return plans, nil
+}
+
+func (s *service) Get(ctx context.Context, name string) (*domain.Plan, error) {
+	return s.repo.Get(ctx, name)
+}
+
+func (s *service) Create(ctx context.Context, req domain.CreatePlanRequest) (*domain.Plan, error) {
+	now := s.now().UTC()
+	p := &domain.Plan{
+		Name:         req.Name,
+		Description:  req.Description,
+		Entitlements: req.Entitlements,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) Update(ctx context.Context, name string, input domain.PlanInput) (*domain.Plan, error) {
+	if _, err := s.repo.Get(ctx, name); err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{fieldDescription: input.Description, fieldEntitlements: input.Entitlements}
+	if err := s.repo.Update(ctx, name, updates); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, name)
+}
+
+func (s *service) Delete(ctx context.Context, name string) error {
+	if name == domain.PlanFree {
+		return fmt.Errorf("built-in plan %q cannot be deleted: %w", name, domain.ErrForbidden)
+	}
+	if _, err := s.repo.Get(ctx, name); err != nil {
+		return err
+	}
+	return s.repo.HardDelete(ctx, name)
+}
+
+func (s *service) EnsureBuiltins(ctx context.Context) error {
+	now := s.now().UTC()
+	err := s.repo.Create(ctx, &domain.Plan{
+		Name: domain.PlanFree, Description: "Default plan for users without one", CreatedAt: now, UpdatedAt: now,
+	})
+	if err != nil && !errors.Is(err, domain.ErrConflict) {
+		return fmt.Errorf("seed plan %s: %w", domain.PlanFree, err)
+	}
+	return nil
+}
+
+func (s *service) Assign(ctx context.Context, actorID, userID, name string) (*domain.User, error) {
+	if _, err := s.repo.Get(ctx, name); err != nil {
+		return nil, err
+	}
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	from := u.Plan
+	if from == "" {
+		from = domain.PlanFree
+	}
+	if err := s.users.Update(ctx, userID, map[string]interface{}{fieldPlan: name}); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	delete(s.cache, userID)
+	s.mu.Unlock()
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditUserPlanChange,
+		ActorID:  actorID,
+		TargetID: userID,
+		Details:  map[string]string{"from": from, "to": name},
+	})
+	return s.users.Get(ctx, userID)
+}
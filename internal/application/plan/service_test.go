@@ -0,0 +1,187 @@
+package plan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- stubs ---
+
+type memPlans struct {
+	items map[string]domain.Plan
+	gets  int
+}
+
+func (m *memPlans) Create(_ context.Context, p *domain.Plan) error {
+	if _, ok := m.items[p.Name]; ok {
+		return domain.ErrConflict
+	}
+	m.items[p.Name] = *p
+	return nil
+}
+
+func (m *memPlans) Get(_ context.Context, name string) (*domain.Plan, error) {
+	m.gets++
+	p, ok := m.items[name]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (m *memPlans) List(context.Context) ([]domain.Plan, error) {
+	var out []domain.Plan
+	for _, p := range m.items {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (m *memPlans) Update(_ context.Context, name string, updates map[string]interface{}) error {
+	p := m.items[name]
+	p.Description, _ = updates[fieldDescription].(string)
+	p.Entitlements, _ = updates[fieldEntitlements].(domain.Entitlements)
+	m.items[name] = p
+	return nil
+}
+
+func (m *memPlans) HardDelete(_ context.Context, name string) error {
+	delete(m.items, name)
+	return nil
+}
+
+type memUsers map[string]*domain.User
+
+func (m memUsers) Get(_ context.Context, userID string) (*domain.User, error) {
+	u, ok := m[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return u, nil
+}
+
+func (m memUsers) Update(_ context.Context, userID string, updates map[string]interface{}) error {
+	m[userID].Plan, _ = updates[fieldPlan].(string)
+	return nil
+}
+
+type fixedUsage domain.StorageUsage
+
+func (f fixedUsage) UsageByUploader(context.Context, string) (domain.StorageUsage, error) {
+	return domain.StorageUsage(f), nil
+}
+
+type fixedDevices []domain.Device
+
+func (f fixedDevices) ListByUser(context.Context, string) ([]domain.Device, error) { return f, nil }
+
+type auditLog []domain.AuditEntry
+
+func (a *auditLog) Record(_ context.Context, e domain.AuditEntry) { *a = append(*a, e) }
+
+func newTestService(users memUsers, usage fixedUsage, devices fixedDevices) (*service, *memPlans, *auditLog) {
+	plans := &memPlans{items: map[string]domain.Plan{
+		domain.PlanFree: {Name: domain.PlanFree, Entitlements: domain.Entitlements{StorageBytes: 100, MaxDevices: 2}},
+		"pro":           {Name: "pro", Entitlements: domain.Entitlements{StorageBytes: 1000}},
+	}}
+	audit := &auditLog{}
+	svc := NewService(ServiceDeps{PlanRepo: plans, UserRepo: users, FileRepo: usage, Devices: devices, Audit: audit})
+	return svc.(*service), plans, audit
+}
+
+// --- tests ---
+
+func TestDelete_FreePlanIsForbidden(t *testing.T) {
+	svc, plans, _ := newTestService(memUsers{}, fixedUsage{}, nil)
+
+	assert.ErrorIs(t, svc.Delete(context.Background(), domain.PlanFree), domain.ErrForbidden)
+	assert.Contains(t, plans.items, domain.PlanFree)
+}
+
+func TestEnsureBuiltins_KeepsExistingFreePlan(t *testing.T) {
+	svc, plans, _ := newTestService(memUsers{}, fixedUsage{}, nil)
+
+	require.NoError(t, svc.EnsureBuiltins(context.Background()))
+	assert.Equal(t, int64(100), plans.items[domain.PlanFree].Entitlements.StorageBytes)
+}
+
+func TestEntitlements_FallsBackToFreePlan(t *testing.T) {
+	users := memUsers{"u1": {UserID: "u1"}, "u2": {UserID: "u2", Plan: "gone"}}
+	svc, _, _ := newTestService(users, fixedUsage{}, nil)
+	ctx := context.Background()
+
+	for _, id := range []string{"u1", "u2"} {
+		ent, err := svc.Entitlements(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, 2, ent.MaxDevices, id)
+	}
+}
+
+func TestEntitlements_CachedUntilAssign(t *testing.T) {
+	users := memUsers{"u1": {UserID: "u1"}}
+	svc, plans, audit := newTestService(users, fixedUsage{}, nil)
+	ctx := context.Background()
+
+	_, err := svc.Entitlements(ctx, "u1")
+	require.NoError(t, err)
+	_, err = svc.Entitlements(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, plans.gets, "the second lookup is cached")
+
+	u, err := svc.Assign(ctx, "admin", "u1", "pro")
+	require.NoError(t, err)
+	assert.Equal(t, "pro", u.Plan)
+	ent, err := svc.Entitlements(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), ent.StorageBytes)
+	require.Len(t, *audit, 1)
+	assert.Equal(t, map[string]string{"from": domain.PlanFree, "to": "pro"}, (*audit)[0].Details)
+}
+
+func TestEntitlements_CacheExpires(t *testing.T) {
+	users := memUsers{"u1": {UserID: "u1"}}
+	svc, plans, _ := newTestService(users, fixedUsage{}, nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := svc.Entitlements(ctx, "u1")
+	require.NoError(t, err)
+	now = now.Add(cacheTTL)
+	_, err = svc.Entitlements(ctx, "u1")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, plans.gets)
+}
+
+func TestAssign_UnknownPlanReturnsNotFound(t *testing.T) {
+	svc, _, audit := newTestService(memUsers{"u1": {UserID: "u1"}}, fixedUsage{}, nil)
+
+	_, err := svc.Assign(context.Background(), "admin", "u1", "ghost")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Empty(t, *audit)
+}
+
+func TestCheckStorage_RejectsUploadPastQuota(t *testing.T) {
+	svc, _, _ := newTestService(memUsers{"u1": {UserID: "u1"}}, fixedUsage{Files: 3, Bytes: 90}, nil)
+	ctx := context.Background()
+
+	assert.NoError(t, svc.CheckStorage(ctx, "u1", 10))
+	assert.ErrorIs(t, svc.CheckStorage(ctx, "u1", 11), domain.ErrForbidden)
+}
+
+func TestCheckNewDevice_CountsEnabledDevices(t *testing.T) {
+	devices := fixedDevices{{Enable: true}, {Enable: false}}
+	svc, _, _ := newTestService(memUsers{"u1": {UserID: "u1"}}, fixedUsage{}, devices)
+	ctx := context.Background()
+
+	require.NoError(t, svc.CheckNewDevice(ctx, "u1"))
+	svc.devices = append(devices, domain.Device{Enable: true})
+	assert.ErrorIs(t, svc.CheckNewDevice(ctx, "u1"), domain.ErrForbidden)
+}
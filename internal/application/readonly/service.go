@@ -0,0 +1,100 @@
+package readonly
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// Service holds the read-only switch. The switch is stored in the settings
+// table so every replica follows it; each replica keeps a copy that Refresh
+// re-reads. READ_ONLY forces the mode on regardless of the stored switch.
+type Service interface {
+	// Mode returns the replica's copy of the switch without a round trip.
+	Mode() domain.ReadOnlyMode
+	// Refresh re-reads the stored switch. On error the last copy is kept.
+	Refresh(ctx context.Context) error
+	// Set stores the switch and applies it on this replica at once; other
+	// replicas follow on their next Refresh. Turning off a forced mode is
+	// domain.ErrConflict.
+	Set(ctx context.Context, actorID string, req domain.SetReadOnlyRequest) (domain.ReadOnlyMode, error)
+}
+
+type settingsStore interface {
+	GetReadOnly(ctx context.Context) (domain.ReadOnlyMode, error)
+	PutReadOnly(ctx context.Context, m domain.ReadOnlyMode) error
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type service struct {
+	store  settingsStore
+	audit  auditRecorder
+	forced bool
+	now    func() time.Time
+
+	mu     sync.RWMutex
+	stored domain.ReadOnlyMode
+}
+
+type ServiceDeps struct {
+	Settings settingsStore
+	Audit    auditRecorder
+	Forced   bool // READ_ONLY
+}
+
+func NewService(deps ServiceDeps) Service {
+	return &service{store: deps.Settings, audit: deps.Audit, forced: deps.Forced, now: time.Now}
+}
+
+func (s *service) Mode() domain.ReadOnlyMode {
+	s.mu.RLock()
+	m := s.stored
+	s.mu.RUnlock()
+	if s.forced {
+		m.Enabled, m.Forced = true, true
+	}
+	return m
+}
+
+func (s *service) Refresh(ctx context.Context) error {
+	m, err := s.store.GetReadOnly(ctx)
+	if err != nil {
+		return fmt.Errorf("read read-only switch: %w", err)
+	}
+	s.mu.Lock()
+	s.stored = m
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *service) Set(ctx context.Context, actorID string, req domain.SetReadOnlyRequest) (domain.ReadOnlyMode, error) {
+	if s.forced && !*req.Enabled {
+		return domain.ReadOnlyMode{}, fmt.Errorf("READ_ONLY is set in the configuration: %w", domain.ErrConflict)
+	}
+	m := domain.ReadOnlyMode{
+		Enabled:   *req.Enabled,
+		Reason:    req.Reason,
+		UpdatedBy: actorID,
+		UpdatedAt: s.now().UTC(),
+	}
+	if err := s.store.PutReadOnly(ctx, m); err != nil {
+		return domain.ReadOnlyMode{}, err
+	}
+	s.mu.Lock()
+	s.stored = m
+	s.mu.Unlock()
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditReadOnlySet,
+		ActorID:  actorID,
+		TargetID: "read_only",
+		Details:  map[string]string{"enabled": strconv.FormatBool(m.Enabled), "reason": m.Reason},
+	})
+	return s.Mode(), nil
+}
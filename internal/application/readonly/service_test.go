@@ -0,0 +1,66 @@
+package readonly
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSettings struct {
+	mode domain.ReadOnlyMode
+	err  error
+}
+
+func (s *stubSettings) GetReadOnly(context.Context) (domain.ReadOnlyMode, error) {
+	return s.mode, s.err
+}
+
+func (s *stubSettings) PutReadOnly(_ context.Context, m domain.ReadOnlyMode) error {
+	s.mode = m
+	return nil
+}
+
+type stubAudit struct{ entries []domain.AuditEntry }
+
+func (a *stubAudit) Record(_ context.Context, e domain.AuditEntry) { a.entries = append(a.entries, e) }
+
+func enabled(b bool) *bool { return &b }
+
+func TestSet_AppliesAtOnceAndAudits(t *testing.T) {
+	store, audit := &stubSettings{}, &stubAudit{}
+	svc := NewService(ServiceDeps{Settings: store, Audit: audit})
+
+	m, err := svc.Set(context.Background(), "admin", domain.SetReadOnlyRequest{Enabled: enabled(true), Reason: "migration"})
+
+	require.NoError(t, err)
+	assert.True(t, m.Enabled)
+	assert.Equal(t, "migration", svc.Mode().Reason)
+	assert.True(t, store.mode.Enabled)
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, domain.AuditReadOnlySet, audit.entries[0].Action)
+}
+
+func TestRefresh_FollowsStoreAndKeepsLastOnError(t *testing.T) {
+	store := &stubSettings{mode: domain.ReadOnlyMode{Enabled: true}}
+	svc := NewService(ServiceDeps{Settings: store, Audit: &stubAudit{}})
+
+	require.NoError(t, svc.Refresh(context.Background()))
+	assert.True(t, svc.Mode().Enabled)
+
+	store.mode, store.err = domain.ReadOnlyMode{}, errors.New("throttled")
+	assert.Error(t, svc.Refresh(context.Background()))
+	assert.True(t, svc.Mode().Enabled, "the last copy is kept")
+}
+
+func TestForced_CannotBeTurnedOff(t *testing.T) {
+	svc := NewService(ServiceDeps{Settings: &stubSettings{}, Audit: &stubAudit{}, Forced: true})
+
+	assert.True(t, svc.Mode().Enabled)
+	assert.True(t, svc.Mode().Forced)
+	_, err := svc.Set(context.Background(), "admin", domain.SetReadOnlyRequest{Enabled: enabled(false)})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
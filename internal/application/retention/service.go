@@ -0,0 +1,64 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// Service applies the data retention rules.
+type Service interface {
+	// Report counts the items each rule would purge without deleting anything.
+	Report(ctx context.Context) (*domain.RetentionReport, error)
+	// Purge deletes the items past each rule's retention period.
+	Purge(ctx context.Context) (*domain.RetentionReport, error)
+}
+
+// Rule applies Sweep to the items older than Days days; Days 0 disables it.
+// Sweep deletes or scrubs them, or with dryRun only counts them, and is
+// usually a repo method value such as AuditRepo.SweepBefore.
+type Rule struct {
+	Name  string
+	Days  int
+	Sweep func(ctx context.Context, cutoff time.Time, dryRun bool) (int, error)
+}
+
+type service struct {
+	rules    []Rule
+	enforced bool
+	now      func() time.Time
+}
+
+// NewService returns a service over rules. enforced is only reported; the
+// caller decides whether to schedule Purge.
+func NewService(rules []Rule, enforced bool) Service {
+	return &service{rules: rules, enforced: enforced, now: time.Now}
+}
+
+func (s *service) Report(ctx context.Context) (*domain.RetentionReport, error) {
+	return s.apply(ctx, true)
+}
+
+func (s *service) Purge(ctx context.Context) (*domain.RetentionReport, error) {
+	return s.apply(ctx, false)
+}
+
+func (s *service) apply(ctx context.Context, dryRun bool) (*domain.RetentionReport, error) {
+	now := s.now().UTC()
+	report := &domain.RetentionReport{Enforced: s.enforced, DryRun: dryRun, GeneratedAt: now}
+	for _, rule := range s.rules {
+		res := domain.RetentionRule{Name: rule.Name, RetentionDays: rule.Days}
+		if rule.Days > 0 {
+			cutoff := now.AddDate(0, 0, -rule.Days)
+			n, err := rule.Sweep(ctx, cutoff, dryRun)
+			if err != nil {
+				return nil, fmt.Errorf("retention rule %s: %w", rule.Name, err)
+			}
+			res.Cutoff, res.Expired = &cutoff, n
+		}
+		report.Rules = append(report.Rules, res)
+	}
+	return report, nil
+}
@@ -0,0 +1,69 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSweeper struct {
+	n      int
+	err    error
+	cutoff time.Time
+	dryRun bool
+	calls  int
+}
+
+func (s *stubSweeper) SweepBefore(_ context.Context, cutoff time.Time, dryRun bool) (int, error) {
+	s.calls++
+	s.cutoff, s.dryRun = cutoff, dryRun
+	return s.n, s.err
+}
+
+func TestReport_IsDryRun(t *testing.T) {
+	audit := &stubSweeper{n: 3}
+	svc := NewService([]Rule{{Name: "audit_logs", Days: 365, Sweep: audit.SweepBefore}}, false).(*service)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	report, err := svc.Report(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, audit.dryRun)
+	assert.True(t, report.DryRun)
+	assert.False(t, report.Enforced)
+	require.Len(t, report.Rules, 1)
+	assert.Equal(t, 3, report.Rules[0].Expired)
+	assert.Equal(t, now.AddDate(-1, 0, 0), audit.cutoff)
+	assert.Equal(t, audit.cutoff, *report.Rules[0].Cutoff)
+}
+
+func TestPurge_DeletesAndSkipsKeepForever(t *testing.T) {
+	users := &stubSweeper{n: 2}
+	kept := &stubSweeper{}
+	svc := NewService([]Rule{
+		{Name: "deleted_users", Days: 30, Sweep: users.SweepBefore},
+		{Name: "audit_logs", Days: 0, Sweep: kept.SweepBefore},
+	}, true)
+
+	report, err := svc.Purge(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, users.dryRun)
+	assert.Zero(t, kept.calls)
+	require.Len(t, report.Rules, 2)
+	assert.Equal(t, 2, report.Rules[0].Expired)
+	assert.Nil(t, report.Rules[1].Cutoff)
+}
+
+func TestPurge_StoreErrorNamesRule(t *testing.T) {
+	svc := NewService([]Rule{{Name: "notifications", Days: 30, Sweep: (&stubSweeper{err: errors.New("throttled")}).SweepBefore}}, true)
+
+	_, err := svc.Purge(context.Background())
+
+	assert.ErrorContains(t, err, "notifications")
+}
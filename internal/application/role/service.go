@@ -0,0 +1,106 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// DynamoDB attribute name used in partial update maps.
+const fieldDescription = "description"
+
+// builtins are seeded by EnsureBuiltins and cannot be deleted; RBAC checks
+// elsewhere depend on them.
+var builtins = map[string]string{
+	domain.RoleAdmin: "Full administrative access",
+	domain.RoleUser:  "Default role for registered users",
+}
+
+type Service interface {
+	List(ctx context.Context) ([]domain.Role, error)
+	Get(ctx context.Context, name string) (*domain.Role, error)
+	Create(ctx context.Context, req domain.CreateRoleRequest) (*domain.Role, error)
+	Update(ctx context.Context, name string, input domain.RoleInput) (*domain.Role, error)
+	Delete(ctx context.Context, name string) error // hard delete; built-in roles are rejected
+	// EnsureBuiltins creates the built-in roles if they are missing.
+	EnsureBuiltins(ctx context.Context) error
+}
+
+type roleStore interface {
+	Create(ctx context.Context, role *domain.Role) error
+	Get(ctx context.Context, name string) (*domain.Role, error)
+	List(ctx context.Context) ([]domain.Role, error)
+	Update(ctx context.Context, name string, updates map[string]interface{}) error
+	HardDelete(ctx context.Context, name string) error
+}
+
+type service struct {
+	repo roleStore
+}
+
+func NewService(repo roleStore) Service {
+	return &service{repo: repo}
+}
+
+// List returns roles sorted by name.
+func (s *service) List(ctx context.Context) ([]domain.Role, error) {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (s *service) Get(ctx context.Context, name string) (*domain.Role, error) {
+	return s.repo.Get(ctx, name)
+}
+
+func (s *service) Create(ctx context.Context, req domain.CreateRoleRequest) (*domain.Role, error) {
+	now := time.Now().UTC()
+	role := &domain.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *service) Update(ctx context.Context, name string, input domain.RoleInput) (*domain.Role, error) {
+	if _, err := s.repo.Get(ctx, name); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, name, map[string]interface{}{fieldDescription: input.Description}); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, name)
+}
+
+func (s *service) Delete(ctx context.Context, name string) error {
+	if _, ok := builtins[name]; ok {
+		return fmt.Errorf("built-in role %q cannot be deleted: %w", name, domain.ErrForbidden)
+	}
+	if _, err := s.repo.Get(ctx, name); err != nil {
+		return err
+	}
+	return s.repo.HardDelete(ctx, name)
+}
+
+func (s *service) EnsureBuiltins(ctx context.Context) error {
+	now := time.Now().UTC()
+	for name, desc := range builtins {
+		err := s.repo.Create(ctx, &domain.Role{Name: name, Description: desc, CreatedAt: now, UpdatedAt: now})
+		if err != nil && !errors.Is(err, domain.ErrConflict) {
+			return fmt.Errorf("seed role %s: %w", name, err)
+		}
+	}
+	return nil
+}
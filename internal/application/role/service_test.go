@@ -0,0 +1,101 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockRoleStore struct{ mock.Mock }
+
+func (m *mockRoleStore) Create(ctx context.Context, role *domain.Role) error {
+	return m.Called(ctx, role).Error(0)
+}
+
+func (m *mockRoleStore) Get(ctx context.Context, name string) (*domain.Role, error) {
+	args := m.Called(ctx, name)
+	if r, _ := args.Get(0).(*domain.Role); r != nil {
+		return r, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRoleStore) List(ctx context.Context) ([]domain.Role, error) {
+	args := m.Called(ctx)
+	rs, _ := args.Get(0).([]domain.Role)
+	return rs, args.Error(1)
+}
+
+func (m *mockRoleStore) Update(ctx context.Context, name string, updates map[string]interface{}) error {
+	return m.Called(ctx, name, updates).Error(0)
+}
+
+func (m *mockRoleStore) HardDelete(ctx context.Context, name string) error {
+	return m.Called(ctx, name).Error(0)
+}
+
+// --- List tests ---
+
+func TestList_SortsByName(t *testing.T) {
+	repo := &mockRoleStore{}
+	repo.On("List", mock.Anything).Return([]domain.Role{{Name: "User"}, {Name: "Admin"}, {Name: "Editor"}}, nil)
+
+	got, err := NewService(repo).List(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Admin", "Editor", "User"}, []string{got[0].Name, got[1].Name, got[2].Name})
+}
+
+// --- Delete tests ---
+
+func TestDelete_BuiltinRoleIsForbidden(t *testing.T) {
+	repo := &mockRoleStore{}
+
+	err := NewService(repo).Delete(context.Background(), domain.RoleAdmin)
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	repo.AssertNotCalled(t, "HardDelete", mock.Anything, mock.Anything)
+}
+
+func TestDelete_CustomRoleIsRemoved(t *testing.T) {
+	repo := &mockRoleStore{}
+	repo.On("Get", mock.Anything, "Editor").Return(&domain.Role{Name: "Editor"}, nil)
+	repo.On("HardDelete", mock.Anything, "Editor").Return(nil)
+
+	require.NoError(t, NewService(repo).Delete(context.Background(), "Editor"))
+	repo.AssertExpectations(t)
+}
+
+func TestDelete_UnknownRoleReturnsNotFound(t *testing.T) {
+	repo := &mockRoleStore{}
+	repo.On("Get", mock.Anything, "Ghost").Return(nil, domain.ErrNotFound)
+
+	assert.ErrorIs(t, NewService(repo).Delete(context.Background(), "Ghost"), domain.ErrNotFound)
+}
+
+// --- EnsureBuiltins tests ---
+
+func TestEnsureBuiltins_IgnoresExistingRoles(t *testing.T) {
+	repo := &mockRoleStore{}
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(r *domain.Role) bool { return r.Name == domain.RoleAdmin })).
+		Return(domain.ErrConflict)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(r *domain.Role) bool { return r.Name == domain.RoleUser })).
+		Return(nil)
+
+	require.NoError(t, NewService(repo).EnsureBuiltins(context.Background()))
+	repo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestEnsureBuiltins_PropagatesStoreErrors(t *testing.T) {
+	repo := &mockRoleStore{}
+	repo.On("Create", mock.Anything, mock.Anything).Return(errors.New("dynamo down"))
+
+	assert.Error(t, NewService(repo).EnsureBuiltins(context.Background()))
+}
@@ -0,0 +1,158 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// Index names double as the ?type= values accepted by Search.
+const (
+	KindUsers = "users"
+	KindFiles = "files"
+)
+
+type Service interface {
+	// Search runs a fuzzy full-text query against the kind's index and returns
+	// the matching projection documents, best match first.
+	Search(ctx context.Context, kind, query string, limit int) ([]json.RawMessage, error)
+	// Sync projects users and files changed since the previous call.
+	Sync(ctx context.Context) error
+	// ProjectUser indexes u, or removes it from the index once deleted.
+	ProjectUser(ctx context.Context, u *domain.User) error
+	// ProjectFile indexes f, or removes it from the index once deleted.
+	ProjectFile(ctx context.Context, f *domain.File) error
+}
+
+type searchIndex interface {
+	Index(ctx context.Context, index, id string, doc interface{}) error
+	Delete(ctx context.Context, index, id string) error
+	Search(ctx context.Context, index string, body interface{}) ([]json.RawMessage, error)
+}
+
+type userFeed interface {
+	Poll(ctx context.Context) ([]domain.User, error)
+}
+
+type fileFeed interface {
+	Poll(ctx context.Context) ([]domain.File, error)
+}
+
+type service struct {
+	index searchIndex
+	users userFeed
+	files fileFeed
+}
+
+// NewService builds the search service. users and files may be nil when only
+// projecting explicitly (e.g. from the reindex migration).
+func NewService(index searchIndex, users userFeed, files fileFeed) Service {
+	return &service{index: index, users: users, files: files}
+}
+
+// userDoc is the projection stored in the users index.
+type userDoc struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+	Enable    bool      `json:"enable"`
+	CreatedAt time.Time `json:"created"`
+}
+
+// fileDoc is the projection stored in the files index.
+type fileDoc struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Size       int64     `json:"size"`
+	UploadedBy string    `json:"user_who_uploaded_id"`
+	IsPrivate  bool      `json:"is_private"`
+	CreatedAt  time.Time `json:"created"`
+}
+
+// searchFields are the boosted fields matched per index.
+var searchFields = map[string][]string{
+	KindUsers: {"username^3", "email^2", "first_name", "last_name"},
+	KindFiles: {"name^2", "type"},
+}
+
+func (s *service) Search(ctx context.Context, kind, query string, limit int) ([]json.RawMessage, error) {
+	fields, ok := searchFields[kind]
+	if !ok {
+		return nil, fmt.Errorf("type must be users or files: %w", domain.ErrBadRequest)
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("q is required: %w", domain.ErrBadRequest)
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	return s.index.Search(ctx, kind, map[string]interface{}{
+		"size": limit,
+		"query": map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":     query,
+				"fields":    fields,
+				"fuzziness": "AUTO",
+			},
+		},
+	})
+}
+
+// Sync is at-most-once per replica: stream positions advance before the
+// projection is written, so a failed write is only repaired by a later change
+// to the same item or by a reindex.
+func (s *service) Sync(ctx context.Context) error {
+	var errs []error
+	users, err := s.users.Poll(ctx)
+	errs = append(errs, err)
+	for i := range users {
+		errs = append(errs, s.ProjectUser(ctx, &users[i]))
+	}
+	files, err := s.files.Poll(ctx)
+	errs = append(errs, err)
+	for i := range files {
+		errs = append(errs, s.ProjectFile(ctx, &files[i]))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *service) ProjectUser(ctx context.Context, u *domain.User) error {
+	if u.DeletedAt != nil {
+		return s.index.Delete(ctx, KindUsers, u.UserID)
+	}
+	return s.index.Index(ctx, KindUsers, u.UserID, userDoc{
+		ID:        u.UserID,
+		Username:  u.Username,
+		Email:     u.Email,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Role:      u.Role,
+		Enable:    u.Enable == 1,
+		CreatedAt: u.CreatedAt,
+	})
+}
+
+func (s *service) ProjectFile(ctx context.Context, f *domain.File) error {
+	if !f.Enable {
+		return s.index.Delete(ctx, KindFiles, f.FileID)
+	}
+	return s.index.Index(ctx, KindFiles, f.FileID, fileDoc{
+		ID:         f.FileID,
+		Name:       f.Name,
+		Type:       f.Type,
+		Size:       f.Size,
+		UploadedBy: f.UploadedByUserID,
+		IsPrivate:  f.IsPrivate,
+		CreatedAt:  f.CreatedAt,
+	})
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIndex struct{ mock.Mock }
+
+func (m *mockIndex) Index(ctx context.Context, index, id string, doc interface{}) error {
+	return m.Called(ctx, index, id, doc).Error(0)
+}
+
+func (m *mockIndex) Delete(ctx context.Context, index, id string) error {
+	return m.Called(ctx, index, id).Error(0)
+}
+
+func (m *mockIndex) Search(ctx context.Context, index string, body interface{}) ([]json.RawMessage, error) {
+	args := m.Called(ctx, index, body)
+	docs, _ := args.Get(0).([]json.RawMessage)
+	return docs, args.Error(1)
+}
+
+type stubUserFeed struct {
+	users []domain.User
+	err   error
+}
+
+func (f *stubUserFeed) Poll(context.Context) ([]domain.User, error) { return f.users, f.err }
+
+type stubFileFeed struct{ files []domain.File }
+
+func (f *stubFileFeed) Poll(context.Context) ([]domain.File, error) { return f.files, nil }
+
+func TestSearch_UnknownTypeIsBadRequest(t *testing.T) {
+	_, err := NewService(&mockIndex{}, nil, nil).Search(context.Background(), "sessions", "bob", 10)
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestSearch_EmptyQueryIsBadRequest(t *testing.T) {
+	_, err := NewService(&mockIndex{}, nil, nil).Search(context.Background(), KindUsers, "  ", 10)
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestSearch_SendsFuzzyMultiMatch(t *testing.T) {
+	idx := &mockIndex{}
+	var body map[string]interface{}
+	idx.On("Search", mock.Anything, KindFiles, mock.Anything).Run(func(args mock.Arguments) {
+		body = args.Get(2).(map[string]interface{})
+	}).Return([]json.RawMessage{json.RawMessage(`{"id":"f1"}`)}, nil)
+
+	docs, err := NewService(idx, nil, nil).Search(context.Background(), KindFiles, "invoce", 5)
+
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+	assert.Equal(t, 5, body["size"])
+	match := body["query"].(map[string]interface{})["multi_match"].(map[string]interface{})
+	assert.Equal(t, "invoce", match["query"])
+	assert.Equal(t, "AUTO", match["fuzziness"])
+}
+
+func TestSync_IndexesLiveItemsAndRemovesDeletedOnes(t *testing.T) {
+	deleted := time.Now()
+	users := &stubUserFeed{users: []domain.User{
+		{UserID: "u1", Username: "alice", Enable: 1},
+		{UserID: "u2", Username: "bob", DeletedAt: &deleted},
+	}}
+	files := &stubFileFeed{files: []domain.File{{FileID: "f1", Name: "old.pdf"}}}
+	idx := &mockIndex{}
+	idx.On("Index", mock.Anything, KindUsers, "u1", mock.AnythingOfType("search.userDoc")).Return(nil)
+	idx.On("Delete", mock.Anything, KindUsers, "u2").Return(nil)
+	idx.On("Delete", mock.Anything, KindFiles, "f1").Return(nil)
+
+	require.NoError(t, NewService(idx, users, files).Sync(context.Background()))
+	idx.AssertExpectations(t)
+}
+
+func TestSync_FeedErrorStillProjectsOtherKinds(t *testing.T) {
+	users := &stubUserFeed{err: errors.New("stream unavailable")}
+	files := &stubFileFeed{files: []domain.File{{FileID: "f1", Name: "a.png", Enable: true}}}
+	idx := &mockIndex{}
+	idx.On("Index", mock.Anything, KindFiles, "f1", mock.AnythingOfType("search.fileDoc")).Return(nil)
+
+	err := NewService(idx, users, files).Sync(context.Background())
+
+	assert.ErrorContains(t, err, "stream unavailable")
+	idx.AssertExpectations(t)
+}
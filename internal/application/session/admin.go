@@ -0,0 +1,73 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+func (s *service) TerminateSessions(ctx context.Context, actorID, userID, sessionID string) ([]string, error) {
+	if _, err := s.userRepo.Get(ctx, userID); err != nil {
+		return nil, err
+	}
+	var ended []string
+	var err error
+	if sessionID == "" {
+		ended, err = s.terminateAll(ctx, userID)
+	} else {
+		ended, err = s.terminateOne(ctx, userID, sessionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.audit != nil {
+		target := sessionID
+		if target == "" {
+			target = "all"
+		}
+		s.audit.Record(ctx, domain.AuditEntry{
+			Action:   domain.AuditSessionTerminate,
+			ActorID:  actorID,
+			TargetID: userID,
+			Details:  map[string]string{"session_id": target, "ended": strconv.Itoa(len(ended))},
+		})
+	}
+	return ended, nil
+}
+
+// terminateAll disables every session of userID, pending and expired ones
+// included, and returns the IDs of those that were active.
+func (s *service) terminateAll(ctx context.Context, userID string) ([]string, error) {
+	active, err := s.sessionRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.SoftDeleteByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	ended := make([]string, len(active))
+	for i := range active {
+		ended[i] = active[i].SessionID
+	}
+	return ended, nil
+}
+
+// terminateOne disables sessionID, which must belong to userID.
+func (s *service) terminateOne(ctx context.Context, userID, sessionID string) ([]string, error) {
+	sess, err := s.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if sess.UserID != userID {
+		return nil, fmt.Errorf("session not found: %w", domain.ErrNotFound)
+	}
+	if !sess.Enable {
+		return nil, nil
+	}
+	if err := s.sessionRepo.Update(ctx, sessionID, map[string]interface{}{fieldEnable: false}); err != nil {
+		return nil, err
+	}
+	return []string{sessionID}, nil
+}
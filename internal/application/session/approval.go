@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
+)
+
+const (
+	fieldApprovalToken = "approval_token"
+	fieldApprovalUntil = "approval_until"
+
+	approvalSubject = "Approve your sign-in"
+)
+
+type mailer interface {
+	SendEmail(to, subject, body string) error
+}
+
+// holdForApproval disables sess until it is approved through the emailed
+// link, which stays valid for approvalTTL.
+func (s *service) holdForApproval(sess *domain.Session) error {
+	token, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return err
+	}
+	sess.Enable = false
+	sess.ApprovalToken = token
+	sess.ApprovalUntil = sess.CreatedAt.Add(s.approvalTTL).Unix()
+	return nil
+}
+
+// sendApproval emails u the link approving sess. Without it the login can
+// never complete, so a failed send fails the login.
+func (s *service) sendApproval(u *domain.User, sess *domain.Session) error {
+	if s.mailer == nil {
+		return fmt.Errorf("no mailer for login approval: %w", domain.ErrUnavailable)
+	}
+	code := sess.SessionID + "." + sess.ApprovalToken
+	action := "Approve it with this code: " + code
+	if link, err := url.Parse(s.approvalURL); err == nil && s.approvalURL != "" {
+		q := link.Query()
+		q.Set("token", code)
+		link.RawQuery = q.Encode()
+		action = "Approve it by opening this link: " + link.String()
+	}
+	body := fmt.Sprintf("Hi %s,\n\nSomeone signed in to your account from %s (IP %s, %s).\n\n%s\n\n"+
+		"The sign-in stays blocked until you approve it, and expires at %s if you do not. "+
+		"If this was not you, change your password.",
+		u.FirstName, sess.Country, sess.IP, sess.UserAgent, action, time.Unix(sess.ApprovalUntil, 0).UTC().Format(time.RFC1123))
+	if err := s.mailer.SendEmail(u.Email, approvalSubject, body); err != nil {
+		return fmt.Errorf("send login approval: %v: %w", err, domain.ErrUnavailable)
+	}
+	return nil
+}
+
+func (s *service) ApproveLogin(ctx context.Context, approvalToken string) error {
+	invalid := fmt.Errorf("invalid or expired approval link: %w", domain.ErrUnauthorized)
+	sessionID, secret, ok := strings.Cut(approvalToken, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return invalid
+	}
+	sess, err := s.sessionRepo.Get(ctx, sessionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return invalid
+	}
+	if err != nil {
+		return err
+	}
+	match := subtle.ConstantTimeCompare([]byte(sess.ApprovalToken), []byte(secret)) == 1
+	if !sess.PendingApproval() || !match || sess.ApprovalUntil < time.Now().Unix() {
+		return invalid
+	}
+	if err := s.limitSessions(ctx, sess.UserID); err != nil {
+		return err
+	}
+	return s.sessionRepo.Update(ctx, sessionID, map[string]interface{}{
+		fieldEnable:        true,
+		fieldApprovalToken: "",
+		fieldApprovalUntil: 0,
+	})
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-api-nosql/internal/infrastructure/memory"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Benchmarks run the real bcrypt and RS256 work on in-memory repositories,
+// so they track the service's own cost; see "Load testing" in DEVELOPMENT.md
+// for baselines and the end-to-end scenarios.
+
+// benchDevices creates a device per session, as a login without a device UUID does.
+type benchDevices struct{}
+
+func (benchDevices) GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error) {
+	return nil, domain.ErrNotFound
+}
+func (benchDevices) Get(ctx context.Context, deviceID string) (*domain.Device, error) {
+	return nil, domain.ErrNotFound
+}
+func (benchDevices) Put(ctx context.Context, d *domain.Device) error { return nil }
+
+func newBenchService(b *testing.B) (Service, *memory.UserRepo) {
+	b.Helper()
+	users := memory.NewUserRepo(false)
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	require.NoError(b, err)
+	require.NoError(b, users.Put(context.Background(), &domain.User{
+		UserID: "user-1", Username: "alice", Email: "alice@example.com",
+		PasswordHash: string(hash), Role: domain.RoleUser, Enable: 1, CreatedAt: time.Now(),
+	}))
+	return NewService(ServiceDeps{
+		SessionRepo:     memory.NewSessionRepo(),
+		UserRepo:        users,
+		DeviceRepo:      benchDevices{},
+		JWTProvider:     newBenchJWTProvider(b),
+		RefreshTokenDur: 24 * time.Hour,
+	}), users
+}
+
+func newBenchJWTProvider(b *testing.B) *jwtinfra.Provider {
+	b.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(b, err)
+	dir := b.TempDir()
+	privPath, pubPath := filepath.Join(dir, "private.pem"), filepath.Join(dir, "public.pem")
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(b, os.WriteFile(privPath, privPEM, 0600))
+	pubBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(b, err)
+	require.NoError(b, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}), 0600))
+	p, err := jwtinfra.NewProvider(&config.Config{JWTPrivateKeyPath: privPath, JWTPublicKeyPath: pubPath, JWTExpiry: time.Hour})
+	require.NoError(b, err)
+	return p
+}
+
+func BenchmarkLogin(b *testing.B) {
+	svc, _ := newBenchService(b)
+	ctx := context.Background()
+	req := LoginRequest{Username: "alice", Password: "password123"}
+
+	for b.Loop() {
+		if _, err := svc.Login(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRefresh(b *testing.B) {
+	svc, _ := newBenchService(b)
+	ctx := context.Background()
+	res, err := svc.Login(ctx, LoginRequest{Username: "alice", Password: "password123"})
+	require.NoError(b, err)
+	token := res.RefreshToken
+
+	for b.Loop() {
+		_, token, err = svc.Refresh(ctx, token)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// PreLoginHook runs once the credentials have been verified and before a
+// session is created. Returning an error aborts the login; wrap a domain
+// sentinel (e.g. domain.ErrForbidden) to choose the HTTP status.
+type PreLoginHook interface {
+	PreLogin(ctx context.Context, u *domain.User) error
+}
+
+// PostLoginHook runs after a session has been created. It cannot fail the
+// login; errors are logged.
+type PostLoginHook interface {
+	PostLogin(ctx context.Context, sess *domain.Session) error
+}
+
+func (s *service) runPreLogin(ctx context.Context, u *domain.User) error {
+	for _, h := range s.preLogin {
+		if err := h.PreLogin(ctx, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) runPostLogin(ctx context.Context, sess *domain.Session) {
+	for _, h := range s.postLogin {
+		if err := h.PostLogin(ctx, sess); err != nil {
+			slog.Warn("post-login hook failed", "user_id", sess.UserID, "err", err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-api-nosql/internal/domain"
+	pkgdevice "github.com/go-api-nosql/internal/pkg/device"
+)
+
+// passkeyVerifier checks a passkey login assertion and returns the ID of
+// the user it signs in.
+type passkeyVerifier interface {
+	Verify(ctx context.Context, a domain.PasskeyAssertion) (string, error)
+}
+
+func (s *service) LoginWithPasskey(ctx context.Context, a domain.PasskeyAssertion) (*LoginResult, error) {
+	if s.passkeys == nil {
+		return nil, fmt.Errorf("passkey login is not enabled: %w", domain.ErrNotFound)
+	}
+	userID, err := s.passkeys.Verify(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.userRepo.Get(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("invalid credentials: %w", domain.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.DeletedAt != nil || u.Enable == 0 {
+		return nil, fmt.Errorf("account disabled: %w", domain.ErrUnauthorized)
+	}
+	if err := s.runPreLogin(ctx, u); err != nil {
+		return nil, err
+	}
+	dev, err := pkgdevice.Resolve(ctx, s.deviceRepo, a.DeviceUUID, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.openSession(ctx, u, dev, false)
+}
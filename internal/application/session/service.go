@@ -2,49 +2,101 @@ package session
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 	"unicode"
 
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/clientinfo"
 	pkgdevice "github.com/go-api-nosql/internal/pkg/device"
 	"github.com/go-api-nosql/internal/pkg/id"
+	"github.com/go-api-nosql/internal/pkg/password"
 	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// DynamoDB attribute name used in partial update maps.
-const fieldEnable = "enable"
+// DynamoDB attribute names used in partial update maps.
+const (
+	fieldEnable           = "enable"
+	fieldPasswordHash     = "password_hash"
+	fieldPasswordChange   = "password_change"
+	fieldRefreshToken     = "refresh_token"
+	fieldRefreshExpiresAt = "refresh_expires_at"
+	fieldRememberToken    = "remember_token"
+	fieldRememberUntil    = "remember_until"
+	fieldUserAgent        = "user_agent"
+	fieldAppVersion       = "app_version"
+	fieldIP               = "ip"
+)
+
+// dummyHash is a bcrypt hash (DefaultCost) of a throwaway password. Login
+// compares against it when the account does not exist or has no password, so
+// those paths do the same bcrypt work as a real one and timing does not reveal
+// which usernames are registered.
+var dummyHash = []byte("$2a$10$PdyxeIaV2JMuFb9dRYn2xeXljOh61ahsRGwO8V33HpajVCBOF9YgS")
 
 type LoginRequest struct {
 	Username   string  `json:"username" validate:"required"`
 	Password   string  `json:"password" validate:"required"`
 	DeviceUUID *string `json:"device_uuid"`
+	// NewPassword replaces the temporary password of a provisioned account;
+	// its first login without one is domain.ErrPrecondition.
+	NewPassword string `json:"new_password" validate:"omitempty,max=72"`
+	// RememberMe asks for a remember-me token, issued only when the device
+	// is trusted and ignored otherwise.
+	RememberMe bool `json:"remember_me"`
 }
 
 type LoginResult struct {
-	Bearer       string
-	RefreshToken string
-	Session      *domain.Session
+	Bearer        string
+	RefreshToken  string
+	RememberToken string // empty unless a remember-me token was issued
+	Session       *domain.Session
 }
 
 type Service interface {
 	Login(ctx context.Context, req LoginRequest) (*LoginResult, error)
 	LoginWithGoogle(ctx context.Context, credential string, deviceUUID *string) (*LoginResult, error)
+	// LoginWithPasskey signs in the owner of the passkey that signed a. A
+	// failed assertion wraps domain.ErrUnauthorized.
+	LoginWithPasskey(ctx context.Context, a domain.PasskeyAssertion) (*LoginResult, error)
 	Logout(ctx context.Context, sessionID string) error
 	GetCurrent(ctx context.Context, sessionID string) (*domain.Session, error)
+	// ListActive returns userID's enabled sessions whose refresh token has
+	// not expired, most recently used first.
+	ListActive(ctx context.Context, userID string) ([]domain.Session, error)
 	Refresh(ctx context.Context, refreshToken string) (bearer, newRefreshToken string, err error)
+	// SilentRefresh signs a trusted device back in with its remember-me
+	// token, without a password, rotating both the refresh and remember-me
+	// tokens. Failures wrap domain.ErrUnauthorized.
+	SilentRefresh(ctx context.Context, deviceUUID, rememberToken string) (*LoginResult, error)
+	// ApproveLogin activates a login from a high-risk country with the token
+	// emailed to its user. An unknown, used or expired token wraps
+	// domain.ErrUnauthorized.
+	ApproveLogin(ctx context.Context, approvalToken string) error
+	// Validate reports whether the session named in a bearer token is still
+	// usable by userID: enabled, owned by userID, and the account is neither
+	// deleted nor disabled. Failures wrap domain.ErrUnauthorized.
+	Validate(ctx context.Context, userID, sessionID string) error
+	// TerminateSessions signs userID out of sessionID, or out of every
+	// session when sessionID is empty, on behalf of the admin actorID. It
+	// returns the IDs of the active sessions it ended.
+	TerminateSessions(ctx context.Context, actorID, userID, sessionID string) ([]string, error)
 }
 
 type sessionStore interface {
 	Put(ctx context.Context, s *domain.Session) error
 	Get(ctx context.Context, sessionID string) (*domain.Session, error)
 	GetByRefreshToken(ctx context.Context, token string) (*domain.Session, error)
-	RotateRefreshToken(ctx context.Context, sessionID, newToken string, newExpiry int64) error
 	Update(ctx context.Context, sessionID string, updates map[string]interface{}) error
+	ListActiveByUser(ctx context.Context, userID string) ([]domain.Session, error)
+	SoftDeleteByUser(ctx context.Context, userID string) error
 }
 
 type userStore interface {
@@ -56,7 +108,8 @@ type userStore interface {
 }
 
 type deviceStore interface {
-	GetByUUID(ctx context.Context, uuid string) (*domain.Device, error)
+	GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error)
+	Get(ctx context.Context, deviceID string) (*domain.Device, error)
 	Put(ctx context.Context, d *domain.Device) error
 }
 
@@ -73,7 +126,22 @@ type GooglePayload struct {
 }
 
 type jwtSigner interface {
-	Sign(userID, deviceID, role, sessionID string) (string, error)
+	Sign(ctx context.Context, userID, deviceID, role, sessionID string) (string, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type activityRecorder interface {
+	Record(ctx context.Context, userID, kind, subject string)
+}
+
+// signupGate admits or refuses accounts created on first Google sign-in,
+// the way user.PreRegisterHook and user.PostRegisterHook do for Register.
+type signupGate interface {
+	PreRegister(ctx context.Context, req *domain.CreateUserRequest) error
+	PostRegister(ctx context.Context, u *domain.User) error
 }
 
 type service struct {
@@ -82,7 +150,24 @@ type service struct {
 	deviceRepo      deviceStore
 	jwtProvider     jwtSigner
 	googleVerifier  googleVerifier
+	passkeys        passkeyVerifier
 	refreshTokenDur time.Duration
+	untrustedDur    time.Duration
+	rememberDur     time.Duration
+	maxSessions     int
+	strictLimit     bool
+	highRisk        map[string]bool
+	approvalTTL     time.Duration
+	approvalURL     string
+	mailer          mailer
+	activity        activityRecorder
+	audit           auditRecorder
+	compareHash     func(hash, password []byte) error
+	preLogin        []PreLoginHook
+	postLogin       []PostLoginHook
+	signupGate      signupGate
+	closedSignup    bool
+	passwordPolicy  password.Policy
 }
 
 type ServiceDeps struct {
@@ -91,17 +176,80 @@ type ServiceDeps struct {
 	DeviceRepo      deviceStore
 	JWTProvider     jwtSigner
 	GoogleVerifier  googleVerifier
+	Passkeys        passkeyVerifier // optional; nil refuses passkey logins
 	RefreshTokenDur time.Duration
+	// UntrustedDur is the refresh-token lifetime on untrusted devices (0 = RefreshTokenDur).
+	UntrustedDur time.Duration
+	Activity     activityRecorder // optional; records logins in the user's feed
+	// RememberDur is the remember-me token lifetime, renewed on each silent
+	// refresh (0 = remember-me disabled).
+	RememberDur time.Duration
+	// MaxSessions caps a user's active sessions (0 = no limit). A login over
+	// the cap disables the oldest, or with StrictLimit is domain.ErrConflict.
+	MaxSessions int
+	StrictLimit bool
+	Audit       auditRecorder // optional; records sessions disabled by MaxSessions or by admins
+	// HighRiskCountries lists ISO country codes whose logins wait until the
+	// user approves them from an email sent through Mailer. The link goes to
+	// ApprovalURL and expires after ApprovalTTL (0 = 30 minutes).
+	HighRiskCountries []string
+	ApprovalTTL       time.Duration
+	ApprovalURL       string
+	Mailer            mailer
+	// CompareHash checks a password against its hash; defaults to bcrypt.
+	CompareHash func(hash, password []byte) error
+	// PreLogin and PostLogin are deployment hooks run, in order, around every
+	// password, Google and passkey login.
+	PreLogin  []PreLoginHook
+	PostLogin []PostLoginHook
+	// SignupGate, when set, may refuse the account a first Google sign-in
+	// would create.
+	SignupGate signupGate
+	// ClosedSignup refuses the account a first Google sign-in would create,
+	// for deployments where only admins create accounts.
+	ClosedSignup bool
+	// PasswordPolicy, breach check included, is checked on the password
+	// replacing a provisioned account's temporary one.
+	PasswordPolicy password.Policy
 }
 
 func NewService(deps ServiceDeps) Service {
+	compareHash := deps.CompareHash
+	if compareHash == nil {
+		compareHash = bcrypt.CompareHashAndPassword
+	}
+	highRisk := make(map[string]bool, len(deps.HighRiskCountries))
+	for _, c := range deps.HighRiskCountries {
+		highRisk[strings.ToUpper(c)] = true
+	}
+	approvalTTL := deps.ApprovalTTL
+	if approvalTTL <= 0 {
+		approvalTTL = 30 * time.Minute
+	}
 	return &service{
 		sessionRepo:     deps.SessionRepo,
 		userRepo:        deps.UserRepo,
 		deviceRepo:      deps.DeviceRepo,
 		jwtProvider:     deps.JWTProvider,
 		googleVerifier:  deps.GoogleVerifier,
+		passkeys:        deps.Passkeys,
 		refreshTokenDur: deps.RefreshTokenDur,
+		untrustedDur:    deps.UntrustedDur,
+		rememberDur:     deps.RememberDur,
+		maxSessions:     deps.MaxSessions,
+		strictLimit:     deps.StrictLimit,
+		highRisk:        highRisk,
+		approvalTTL:     approvalTTL,
+		approvalURL:     deps.ApprovalURL,
+		mailer:          deps.Mailer,
+		activity:        deps.Activity,
+		audit:           deps.Audit,
+		compareHash:     compareHash,
+		preLogin:        deps.PreLogin,
+		postLogin:       deps.PostLogin,
+		signupGate:      deps.SignupGate,
+		closedSignup:    deps.ClosedSignup,
+		passwordPolicy:  deps.PasswordPolicy,
 	}
 }
 
@@ -109,44 +257,167 @@ func (s *service) Login(ctx context.Context, req LoginRequest) (*LoginResult, er
 	u, err := s.userRepo.GetByUsername(ctx, req.Username)
 	if err != nil {
 		u, err = s.userRepo.GetByEmail(ctx, req.Username)
-		if err != nil {
-			return nil, fmt.Errorf("invalid credentials: %w", domain.ErrUnauthorized)
-		}
 	}
+	// Always run one password comparison, whether or not the account exists.
+	hash := dummyHash
+	if err == nil && u.PasswordHash != "" {
+		hash = []byte(u.PasswordHash)
+	}
+	mismatch := s.compareHash(hash, []byte(req.Password))
+	if err != nil || u.PasswordHash == "" || mismatch != nil {
+		return nil, fmt.Errorf("invalid credentials: %w", domain.ErrUnauthorized)
+	}
+	// Checked after the password so only the owner learns the account is disabled.
 	if u.Enable == 0 {
 		return nil, fmt.Errorf("account disabled: %w", domain.ErrUnauthorized)
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, fmt.Errorf("invalid credentials: %w", domain.ErrUnauthorized)
+	if err := s.replaceTemporaryPassword(ctx, u, req.NewPassword); err != nil {
+		return nil, err
+	}
+	if err := s.runPreLogin(ctx, u); err != nil {
+		return nil, err
 	}
 	dev, err := pkgdevice.Resolve(ctx, s.deviceRepo, req.DeviceUUID, u.UserID)
 	if err != nil {
 		return nil, err
 	}
-	refreshToken, err := pkgtoken.NewRefreshToken()
+	return s.openSession(ctx, u, dev, req.RememberMe)
+}
+
+// openSession starts a session for u on dev and signs its bearer token. A
+// remember-me token is issued only when remember is set and dev is trusted.
+// A login from a high-risk country opens the session disabled and emails u
+// a link to approve it; its tokens work once it is approved.
+func (s *service) openSession(ctx context.Context, u *domain.User, dev *domain.Device, remember bool) (*LoginResult, error) {
+	sess, rememberToken, err := s.newSession(ctx, u, dev, remember)
+	if err != nil {
+		return nil, err
+	}
+	pending := s.highRisk[sess.Country]
+	if pending {
+		if err := s.holdForApproval(sess); err != nil {
+			return nil, err
+		}
+	} else if err := s.limitSessions(ctx, u.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Put(ctx, sess); err != nil {
+		return nil, err
+	}
+	if pending {
+		if err := s.sendApproval(u, sess); err != nil {
+			return nil, err
+		}
+	}
+	bearer, err := s.jwtProvider.Sign(ctx, u.UserID, dev.DeviceID, u.Role, sess.SessionID)
 	if err != nil {
 		return nil, err
 	}
+	sess.User = u
+	if s.activity != nil {
+		s.activity.Record(ctx, u.UserID, domain.ActivityLogin, dev.DeviceID)
+	}
+	if !pending {
+		s.runPostLogin(ctx, sess)
+	}
+	return &LoginResult{Bearer: bearer, RefreshToken: sess.RefreshToken, RememberToken: rememberToken, Session: sess}, nil
+}
+
+// newSession builds an enabled session for u on dev, recording the request's
+// client info, and returns it with its remember-me token when one is issued.
+func (s *service) newSession(ctx context.Context, u *domain.User, dev *domain.Device, remember bool) (*domain.Session, string, error) {
+	refreshToken, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
 	now := time.Now().UTC()
+	info := clientinfo.FromContext(ctx)
 	sess := &domain.Session{
 		SessionID:        id.New(),
 		UserID:           u.UserID,
 		DeviceID:         dev.DeviceID,
 		Enable:           true,
 		RefreshToken:     refreshToken,
-		RefreshExpiresAt: now.Add(s.refreshTokenDur).Unix(),
+		RefreshExpiresAt: now.Add(pkgdevice.RefreshLifetime(dev, s.refreshTokenDur, s.untrustedDur)).Unix(),
+		UserAgent:        info.UserAgent,
+		AppVersion:       info.AppVersion,
+		IP:               info.IP,
+		Country:          info.Country,
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
-	if err := s.sessionRepo.Put(ctx, sess); err != nil {
-		return nil, err
+	if !remember || !dev.Trusted || s.rememberDur <= 0 {
+		return sess, "", nil
 	}
-	bearer, err := s.jwtProvider.Sign(u.UserID, dev.DeviceID, u.Role, sess.SessionID)
+	if sess.RememberToken, err = pkgtoken.NewRefreshToken(); err != nil {
+		return nil, "", err
+	}
+	sess.RememberUntil = now.Add(s.rememberDur).Unix()
+	return sess, sess.SessionID + "." + sess.RememberToken, nil
+}
+
+// limitSessions makes room for one more session of userID under
+// maxSessions by disabling its oldest active sessions, or refuses the login
+// in strict mode. Grants to OAuth clients do not count. Concurrent logins
+// may briefly overshoot the limit.
+func (s *service) limitSessions(ctx context.Context, userID string) error {
+	if s.maxSessions <= 0 {
+		return nil
+	}
+	active, err := s.sessionRepo.ListActiveByUser(ctx, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	sess.User = u
-	return &LoginResult{Bearer: bearer, RefreshToken: refreshToken, Session: sess}, nil
+	active = slices.DeleteFunc(active, func(sess domain.Session) bool { return sess.ClientID != "" })
+	excess := len(active) - s.maxSessions + 1
+	if excess <= 0 {
+		return nil
+	}
+	if s.strictLimit {
+		return fmt.Errorf("session limit of %d reached; sign out on another device first: %w", s.maxSessions, domain.ErrConflict)
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].CreatedAt.Before(active[j].CreatedAt) })
+	for _, old := range active[:excess] {
+		if err := s.sessionRepo.Update(ctx, old.SessionID, map[string]interface{}{fieldEnable: false}); err != nil {
+			return err
+		}
+		if s.audit != nil {
+			s.audit.Record(ctx, domain.AuditEntry{
+				Action:   domain.AuditSessionEvict,
+				ActorID:  userID,
+				TargetID: old.SessionID,
+				Details:  map[string]string{"device_id": old.DeviceID, "reason": "session_limit"},
+			})
+		}
+	}
+	return nil
+}
+
+// replaceTemporaryPassword sets newPassword on an account provisioned by an
+// admin, which must not sign in with the emailed password. Other accounts
+// ignore newPassword.
+func (s *service) replaceTemporaryPassword(ctx context.Context, u *domain.User, newPassword string) error {
+	if !u.PasswordChange {
+		return nil
+	}
+	if newPassword == "" {
+		return fmt.Errorf("password change required: sign in again with new_password: %w", domain.ErrPrecondition)
+	}
+	if err := s.passwordPolicy.CheckNew(ctx, newPassword); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.Update(ctx, u.UserID, map[string]interface{}{
+		fieldPasswordHash:   string(hash),
+		fieldPasswordChange: false,
+	}); err != nil {
+		return err
+	}
+	u.PasswordHash, u.PasswordChange = string(hash), false
+	return nil
 }
 
 func (s *service) Logout(ctx context.Context, sessionID string) error {
@@ -175,6 +446,41 @@ func (s *service) GetCurrent(ctx context.Context, sessionID string) (*domain.Ses
 	return sess, nil
 }
 
+func (s *service) ListActive(ctx context.Context, userID string) ([]domain.Session, error) {
+	sessions, err := s.sessionRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+func (s *service) Validate(ctx context.Context, userID, sessionID string) error {
+	sess, err := s.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("session not found: %w", domain.ErrUnauthorized)
+		}
+		return err
+	}
+	if !sess.Enable || sess.UserID != userID {
+		return fmt.Errorf("session revoked: %w", domain.ErrUnauthorized)
+	}
+	u, err := s.userRepo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("user not found: %w", domain.ErrUnauthorized)
+		}
+		return err
+	}
+	if u.DeletedAt != nil || u.Enable == 0 {
+		return fmt.Errorf("account disabled: %w", domain.ErrUnauthorized)
+	}
+	return nil
+}
+
 func (s *service) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
 	sess, err := s.sessionRepo.GetByRefreshToken(ctx, refreshToken)
 	if err != nil {
@@ -183,12 +489,20 @@ func (s *service) Refresh(ctx context.Context, refreshToken string) (string, str
 	if sess.RefreshExpiresAt < time.Now().Unix() {
 		return "", "", fmt.Errorf("refresh token expired: %w", domain.ErrUnauthorized)
 	}
+	if sess.ClientID != "" {
+		return "", "", fmt.Errorf("refresh token belongs to an OAuth client; use /v1/oauth/token: %w", domain.ErrUnauthorized)
+	}
 	newToken, err := pkgtoken.NewRefreshToken()
 	if err != nil {
 		return "", "", err
 	}
-	newExpiry := time.Now().Add(s.refreshTokenDur).Unix()
-	if err := s.sessionRepo.RotateRefreshToken(ctx, sess.SessionID, newToken, newExpiry); err != nil {
+	lifetime := s.refreshTokenDur
+	if dev, err := s.deviceRepo.Get(ctx, sess.DeviceID); err == nil {
+		lifetime = pkgdevice.RefreshLifetime(dev, s.refreshTokenDur, s.untrustedDur)
+	}
+	newExpiry := time.Now().Add(lifetime).Unix()
+	updates := map[string]interface{}{fieldRefreshToken: newToken, fieldRefreshExpiresAt: newExpiry}
+	if err := s.sessionRepo.Update(ctx, sess.SessionID, withClient(ctx, updates)); err != nil {
 		return "", "", err
 	}
 	u, err := s.userRepo.Get(ctx, sess.UserID)
@@ -198,13 +512,89 @@ func (s *service) Refresh(ctx context.Context, refreshToken string) (string, str
 		}
 		return "", "", err
 	}
-	bearer, err := s.jwtProvider.Sign(u.UserID, sess.DeviceID, u.Role, sess.SessionID)
+	bearer, err := s.jwtProvider.Sign(ctx, u.UserID, sess.DeviceID, u.Role, sess.SessionID)
 	if err != nil {
 		return "", "", err
 	}
 	return bearer, newToken, nil
 }
 
+func (s *service) SilentRefresh(ctx context.Context, deviceUUID, rememberToken string) (*LoginResult, error) {
+	sess, err := s.rememberedSession(ctx, rememberToken)
+	if err != nil {
+		return nil, err
+	}
+	dev, err := s.deviceRepo.Get(ctx, sess.DeviceID)
+	if err != nil || dev.UUID != deviceUUID || !dev.Enable || !dev.Trusted {
+		return nil, fmt.Errorf("remember-me token not valid on this device: %w", domain.ErrUnauthorized)
+	}
+	u, err := s.userRepo.Get(ctx, sess.UserID)
+	if err != nil || u.DeletedAt != nil || u.Enable == 0 {
+		return nil, fmt.Errorf("account disabled: %w", domain.ErrUnauthorized)
+	}
+	refreshToken, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	sess.RefreshToken, sess.RememberToken = refreshToken, secret
+	sess.RefreshExpiresAt = now.Add(pkgdevice.RefreshLifetime(dev, s.refreshTokenDur, s.untrustedDur)).Unix()
+	sess.RememberUntil = now.Add(s.rememberDur).Unix()
+	if err := s.sessionRepo.Update(ctx, sess.SessionID, withClient(ctx, map[string]interface{}{
+		fieldRefreshToken:     sess.RefreshToken,
+		fieldRefreshExpiresAt: sess.RefreshExpiresAt,
+		fieldRememberToken:    sess.RememberToken,
+		fieldRememberUntil:    sess.RememberUntil,
+	})); err != nil {
+		return nil, err
+	}
+	info := clientinfo.FromContext(ctx)
+	sess.UserAgent, sess.AppVersion, sess.IP = info.UserAgent, info.AppVersion, info.IP
+	bearer, err := s.jwtProvider.Sign(ctx, u.UserID, sess.DeviceID, u.Role, sess.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	sess.User = u
+	return &LoginResult{Bearer: bearer, RefreshToken: refreshToken, RememberToken: sess.SessionID + "." + secret, Session: sess}, nil
+}
+
+// rememberedSession returns the enabled session named in a remember-me token
+// of the form "<session_id>.<secret>" whose secret matches and is unexpired.
+// Logging out or a password change disables the session and so the token.
+func (s *service) rememberedSession(ctx context.Context, rememberToken string) (*domain.Session, error) {
+	invalid := fmt.Errorf("invalid or expired remember-me token: %w", domain.ErrUnauthorized)
+	sessionID, secret, ok := strings.Cut(rememberToken, ".")
+	if !ok || sessionID == "" || secret == "" || s.rememberDur <= 0 {
+		return nil, invalid
+	}
+	sess, err := s.sessionRepo.Get(ctx, sessionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	match := subtle.ConstantTimeCompare([]byte(sess.RememberToken), []byte(secret)) == 1
+	if !sess.Enable || !match || sess.RememberUntil < time.Now().Unix() {
+		return nil, invalid
+	}
+	return sess, nil
+}
+
+// withClient adds the request's client info to a session update, so the
+// session shows where it was last refreshed from.
+func withClient(ctx context.Context, updates map[string]interface{}) map[string]interface{} {
+	info := clientinfo.FromContext(ctx)
+	updates[fieldUserAgent] = info.UserAgent
+	updates[fieldAppVersion] = info.AppVersion
+	updates[fieldIP] = info.IP
+	return updates
+}
+
 func (s *service) LoginWithGoogle(ctx context.Context, credential string, deviceUUID *string) (*LoginResult, error) {
 	payload, err := s.googleVerifier.Verify(ctx, credential)
 	if err != nil {
@@ -225,28 +615,7 @@ func (s *service) LoginWithGoogle(ctx context.Context, credential string, device
 		if !errors.Is(err, domain.ErrNotFound) {
 			return nil, err
 		}
-		// User does not exist — create one.
-		username, err := s.deriveUsername(ctx, payload.Email)
-		if err != nil {
-			return nil, err
-		}
-		now := time.Now().UTC()
-		u = &domain.User{
-			UserID:         id.New(),
-			Username:       username,
-			Email:          payload.Email,
-			FirstName:      payload.FirstName,
-			LastName:       payload.LastName,
-			AuthProvider:   domain.AuthProviderGoogle,
-			GoogleSub:      payload.Sub,
-			Role:           domain.RoleUser,
-			Enable:         1,
-			Verified:       true,
-			EmailConfirmed: true,
-			CreatedAt:      now,
-			UpdatedAt:      now,
-		}
-		if err := s.userRepo.Put(ctx, u); err != nil {
+		if u, err = s.createGoogleUser(ctx, payload); err != nil {
 			return nil, err
 		}
 	} else {
@@ -275,35 +644,58 @@ func (s *service) LoginWithGoogle(ctx context.Context, credential string, device
 			u.AuthProvider = domain.AuthProviderGoogle
 		}
 	}
+	if err := s.runPreLogin(ctx, u); err != nil {
+		return nil, err
+	}
 
 	dev, err := pkgdevice.Resolve(ctx, s.deviceRepo, deviceUUID, u.UserID)
 	if err != nil {
 		return nil, err
 	}
-	refreshToken, err := pkgtoken.NewRefreshToken()
+	return s.openSession(ctx, u, dev, false)
+}
+
+// createGoogleUser creates the account for a first Google sign-in, passing
+// it through the signup gate when one is set, unless sign-up is closed.
+func (s *service) createGoogleUser(ctx context.Context, payload *GooglePayload) (*domain.User, error) {
+	if s.closedSignup {
+		return nil, fmt.Errorf("sign-up is closed; ask an administrator for an account: %w", domain.ErrForbidden)
+	}
+	if s.signupGate != nil {
+		req := &domain.CreateUserRequest{Email: payload.Email, FirstName: payload.FirstName, LastName: payload.LastName}
+		if err := s.signupGate.PreRegister(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	username, err := s.deriveUsername(ctx, payload.Email)
 	if err != nil {
 		return nil, err
 	}
 	now := time.Now().UTC()
-	sess := &domain.Session{
-		SessionID:        id.New(),
-		UserID:           u.UserID,
-		DeviceID:         dev.DeviceID,
-		Enable:           true,
-		RefreshToken:     refreshToken,
-		RefreshExpiresAt: now.Add(s.refreshTokenDur).Unix(),
-		CreatedAt:        now,
-		UpdatedAt:        now,
-	}
-	if err := s.sessionRepo.Put(ctx, sess); err != nil {
+	u := &domain.User{
+		UserID:         id.New(),
+		Username:       username,
+		Email:          payload.Email,
+		FirstName:      payload.FirstName,
+		LastName:       payload.LastName,
+		AuthProvider:   domain.AuthProviderGoogle,
+		GoogleSub:      payload.Sub,
+		Role:           domain.RoleUser,
+		Enable:         1,
+		Verified:       true,
+		EmailConfirmed: true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.userRepo.Put(ctx, u); err != nil {
 		return nil, err
 	}
-	bearer, err := s.jwtProvider.Sign(u.UserID, dev.DeviceID, u.Role, sess.SessionID)
-	if err != nil {
-		return nil, err
+	if s.signupGate != nil {
+		if err := s.signupGate.PostRegister(ctx, u); err != nil {
+			slog.Warn("signup gate post-register failed", "user_id", u.UserID, "error", err)
+		}
 	}
-	sess.User = u
-	return &LoginResult{Bearer: bearer, RefreshToken: refreshToken, Session: sess}, nil
+	return u, nil
 }
 
 // deriveUsername builds a unique username from the email local-part.
@@ -3,13 +3,18 @@ package session
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
+	"unicode"
 
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/clientinfo"
+	"github.com/go-api-nosql/internal/pkg/password"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // --- mocks ---
@@ -63,29 +68,42 @@ func (m *mockSessionStore) GetByRefreshToken(ctx context.Context, token string)
 	}
 	return nil, args.Error(1)
 }
-func (m *mockSessionStore) RotateRefreshToken(ctx context.Context, sessionID, newToken string, newExpiry int64) error {
-	return m.Called(ctx, sessionID, newToken, newExpiry).Error(0)
+func (m *mockSessionStore) ListActiveByUser(ctx context.Context, userID string) ([]domain.Session, error) {
+	args := m.Called(ctx, userID)
+	sessions, _ := args.Get(0).([]domain.Session)
+	return sessions, args.Error(1)
 }
 func (m *mockSessionStore) Update(ctx context.Context, sessionID string, updates map[string]interface{}) error {
 	return m.Called(ctx, sessionID, updates).Error(0)
 }
+func (m *mockSessionStore) SoftDeleteByUser(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
 
 type mockDeviceStore struct{ mock.Mock }
 
-func (m *mockDeviceStore) GetByUUID(ctx context.Context, uuid string) (*domain.Device, error) {
-	args := m.Called(ctx, uuid)
+func (m *mockDeviceStore) GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error) {
+	args := m.Called(ctx, uuid, userID)
+	if d, _ := args.Get(0).(*domain.Device); d != nil {
+		return d, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockDeviceStore) Get(ctx context.Context, deviceID string) (*domain.Device, error) {
+	args := m.Called(ctx, deviceID)
 	if d, _ := args.Get(0).(*domain.Device); d != nil {
 		return d, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
+
 func (m *mockDeviceStore) Put(ctx context.Context, d *domain.Device) error {
 	return m.Called(ctx, d).Error(0)
 }
 
 type mockJWTSigner struct{ mock.Mock }
 
-func (m *mockJWTSigner) Sign(userID, deviceID, role, sessionID string) (string, error) {
+func (m *mockJWTSigner) Sign(_ context.Context, userID, deviceID, role, sessionID string) (string, error) {
 	args := m.Called(userID, deviceID, role, sessionID)
 	return args.String(0), args.Error(1)
 }
@@ -136,7 +154,7 @@ func existingUser() *domain.User {
 
 func stubDevice(ds *mockDeviceStore) *domain.Device {
 	dev := &domain.Device{DeviceID: "dev-1", UUID: "uuid-1", UserID: "user-123", Enable: true}
-	ds.On("GetByUUID", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+	ds.On("GetByUUID", mock.Anything, mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
 	ds.On("Put", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
 	return dev
 }
@@ -358,3 +376,601 @@ func TestDeriveUsername_ExhaustionReturnsConflict(t *testing.T) {
 	require.Error(t, err)
 	assert.True(t, errors.Is(err, domain.ErrConflict))
 }
+
+// --- Validate tests ---
+
+func TestValidate_LiveSession(t *testing.T) {
+	us, ss := &mockUserStore{}, &mockSessionStore{}
+	ss.On("Get", mock.Anything, "s1").Return(&domain.Session{SessionID: "s1", UserID: "user-123", Enable: true}, nil)
+	us.On("Get", mock.Anything, "user-123").Return(existingUser(), nil)
+
+	err := newSvc(us, ss, nil, nil, nil).Validate(context.Background(), "user-123", "s1")
+
+	assert.NoError(t, err)
+}
+
+func TestValidate_SessionOfAnotherUser(t *testing.T) {
+	ss := &mockSessionStore{}
+	ss.On("Get", mock.Anything, "s1").Return(&domain.Session{SessionID: "s1", UserID: "someone-else", Enable: true}, nil)
+
+	err := newSvc(&mockUserStore{}, ss, nil, nil, nil).Validate(context.Background(), "user-123", "s1")
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
+
+func TestValidate_DeletedUser(t *testing.T) {
+	us, ss := &mockUserStore{}, &mockSessionStore{}
+	ss.On("Get", mock.Anything, "s1").Return(&domain.Session{SessionID: "s1", UserID: "user-123", Enable: true}, nil)
+	deleted := existingUser()
+	now := time.Now()
+	deleted.DeletedAt = &now
+	us.On("Get", mock.Anything, "user-123").Return(deleted, nil)
+
+	err := newSvc(us, ss, nil, nil, nil).Validate(context.Background(), "user-123", "s1")
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
+
+func TestValidate_StoreErrorIsNotUnauthorized(t *testing.T) {
+	ss := &mockSessionStore{}
+	ss.On("Get", mock.Anything, "s1").Return(nil, errors.New("throttled"))
+
+	err := newSvc(&mockUserStore{}, ss, nil, nil, nil).Validate(context.Background(), "user-123", "s1")
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrUnauthorized)
+}
+
+// --- Login timing tests ---
+
+func TestLogin_UnknownUserStillComparesHash(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("GetByUsername", mock.Anything, "ghost").Return(nil, domain.ErrNotFound)
+	us.On("GetByEmail", mock.Anything, "ghost").Return(nil, domain.ErrNotFound)
+	var hashes [][]byte
+	svc := NewService(ServiceDeps{UserRepo: us, CompareHash: func(hash, _ []byte) error {
+		hashes = append(hashes, hash)
+		return errors.New("mismatch")
+	}})
+
+	_, err := svc.Login(context.Background(), LoginRequest{Username: "ghost", Password: "secret123"})
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	require.Len(t, hashes, 1)
+	assert.Equal(t, dummyHash, hashes[0])
+}
+
+func TestLogin_WrongPasswordComparesUserHash(t *testing.T) {
+	us := &mockUserStore{}
+	u := existingUser()
+	u.PasswordHash = "$2a$10$user-hash"
+	us.On("GetByUsername", mock.Anything, "alice").Return(u, nil)
+	var hashes [][]byte
+	svc := NewService(ServiceDeps{UserRepo: us, CompareHash: func(hash, _ []byte) error {
+		hashes = append(hashes, hash)
+		return errors.New("mismatch")
+	}})
+
+	_, err := svc.Login(context.Background(), LoginRequest{Username: "alice", Password: "secret123"})
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	require.Len(t, hashes, 1)
+	assert.Equal(t, []byte(u.PasswordHash), hashes[0])
+}
+
+func TestLogin_ProvisionedAccountMustSetPassword(t *testing.T) {
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	u := existingUser()
+	u.PasswordHash, u.PasswordChange = "$2a$10$temporary", true
+	us.On("GetByUsername", mock.Anything, "alice").Return(u, nil)
+	us.On("Update", mock.Anything, "user-123", mock.MatchedBy(func(m map[string]interface{}) bool {
+		return m[fieldPasswordChange] == false && m[fieldPasswordHash] != nil
+	})).Return(nil)
+	stubDevice(ds)
+	ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
+	jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer", nil)
+	svc := NewService(ServiceDeps{
+		UserRepo: us, SessionRepo: ss, DeviceRepo: ds, JWTProvider: jwt,
+		CompareHash: func(_, _ []byte) error { return nil },
+	})
+
+	_, err := svc.Login(context.Background(), LoginRequest{Username: "alice", Password: "temporary"})
+	assert.ErrorIs(t, err, domain.ErrPrecondition)
+	us.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+
+	result, err := svc.Login(context.Background(), LoginRequest{Username: "alice", Password: "temporary", NewPassword: "chosen-password"})
+	require.NoError(t, err)
+	assert.False(t, result.Session.User.PasswordChange)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(result.Session.User.PasswordHash), []byte("chosen-password")))
+}
+
+// breachedPasswords reports every password in it as breached.
+type breachedPasswords map[string]bool
+
+func (b breachedPasswords) Breached(_ context.Context, pw string) (bool, error) { return b[pw], nil }
+
+func TestLogin_ProvisionedAccountRefusesBreachedPassword(t *testing.T) {
+	us := &mockUserStore{}
+	u := existingUser()
+	u.PasswordHash, u.PasswordChange = "$2a$10$temporary", true
+	us.On("GetByUsername", mock.Anything, "alice").Return(u, nil)
+	svc := NewService(ServiceDeps{
+		UserRepo:       us,
+		CompareHash:    func(_, _ []byte) error { return nil },
+		PasswordPolicy: password.Policy{Breaches: breachedPasswords{"Summer2024!": true}},
+	})
+
+	_, err := svc.Login(context.Background(), LoginRequest{Username: "alice", Password: "temporary", NewPassword: "Summer2024!"})
+
+	assert.ErrorIs(t, err, domain.ErrBreachedPassword)
+	us.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginWithGoogle_ClosedSignupRefusesNewUser(t *testing.T) {
+	us, gv := &mockUserStore{}, &mockGoogleVerifier{}
+	gv.On("Verify", mock.Anything, "tok").Return(validPayload(), nil)
+	us.On("GetByEmail", mock.Anything, "alice@gmail.com").Return(nil, domain.ErrNotFound)
+	svc := NewService(ServiceDeps{UserRepo: us, GoogleVerifier: gv, ClosedSignup: true})
+
+	_, err := svc.LoginWithGoogle(context.Background(), "tok", nil)
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	us.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestDummyHash_MatchesDefaultCost(t *testing.T) {
+	cost, err := bcrypt.Cost(dummyHash)
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+// --- hook tests ---
+
+type preLoginFunc func(ctx context.Context, u *domain.User) error
+
+func (f preLoginFunc) PreLogin(ctx context.Context, u *domain.User) error { return f(ctx, u) }
+
+type postLoginFunc func(ctx context.Context, sess *domain.Session) error
+
+func (f postLoginFunc) PostLogin(ctx context.Context, sess *domain.Session) error {
+	return f(ctx, sess)
+}
+
+func TestLoginWithGoogle_PreLoginHookRejects(t *testing.T) {
+	us, ss, gv := &mockUserStore{}, &mockSessionStore{}, &mockGoogleVerifier{}
+	gv.On("Verify", mock.Anything, "tok").Return(validPayload(), nil)
+	us.On("GetByEmail", mock.Anything, "alice@gmail.com").Return(existingUser(), nil)
+	svc := NewService(ServiceDeps{
+		UserRepo: us, SessionRepo: ss, GoogleVerifier: gv,
+		PreLogin: []PreLoginHook{preLoginFunc(func(context.Context, *domain.User) error {
+			return fmt.Errorf("blocked domain: %w", domain.ErrForbidden)
+		})},
+	})
+
+	_, err := svc.LoginWithGoogle(context.Background(), "tok", nil)
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	ss.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestLoginWithGoogle_PostLoginHookSeesSessionAndCannotFailLogin(t *testing.T) {
+	us, ss, ds, jwt, gv := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}, &mockGoogleVerifier{}
+	gv.On("Verify", mock.Anything, "tok").Return(validPayload(), nil)
+	us.On("GetByEmail", mock.Anything, "alice@gmail.com").Return(existingUser(), nil)
+	stubDevice(ds)
+	ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
+	jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer", nil)
+	var seen *domain.Session
+	svc := NewService(ServiceDeps{
+		UserRepo: us, SessionRepo: ss, DeviceRepo: ds, JWTProvider: jwt, GoogleVerifier: gv,
+		PostLogin: []PostLoginHook{postLoginFunc(func(_ context.Context, sess *domain.Session) error {
+			seen = sess
+			return errors.New("crm unavailable")
+		})},
+	})
+
+	result, err := svc.LoginWithGoogle(context.Background(), "tok", nil)
+
+	require.NoError(t, err)
+	require.NotNil(t, seen)
+	assert.Equal(t, result.Session.SessionID, seen.SessionID)
+	assert.Equal(t, "user-123", seen.User.UserID)
+}
+
+func FuzzSanitizeUsername(f *testing.F) {
+	for _, seed := range []string{"alice", "John.Doe+news", "../admin", "ǅemal", "İstanbul", "a b\tc", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, local string) {
+		got := sanitizeUsername(local)
+
+		assert.Equal(t, got, sanitizeUsername(got), "sanitizing is idempotent")
+		for _, r := range got {
+			ok := unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-'
+			assert.True(t, ok, "unexpected rune %q in %q", r, got)
+		}
+	})
+}
+
+// --- remember-me tests ---
+
+func rememberSvc(us *mockUserStore, ss *mockSessionStore, ds *mockDeviceStore, jwt *mockJWTSigner) Service {
+	return NewService(ServiceDeps{
+		UserRepo: us, SessionRepo: ss, DeviceRepo: ds, JWTProvider: jwt,
+		RefreshTokenDur: 24 * time.Hour, RememberDur: 90 * 24 * time.Hour,
+		CompareHash: func(_, _ []byte) error { return nil },
+	})
+}
+
+func TestLogin_RememberMeOnlyOnTrustedDevice(t *testing.T) {
+	for _, trusted := range []bool{true, false} {
+		us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+		u := existingUser()
+		u.PasswordHash = "$2a$10$hashedpassword"
+		us.On("GetByUsername", mock.Anything, "alice").Return(u, nil)
+		uuid := "uuid-1"
+		dev := &domain.Device{DeviceID: "dev-1", UUID: uuid, UserID: u.UserID, Enable: true, Trusted: trusted}
+		ds.On("GetByUUID", mock.Anything, uuid, u.UserID).Return(dev, nil)
+		ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
+		jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer", nil)
+
+		result, err := rememberSvc(us, ss, ds, jwt).Login(context.Background(),
+			LoginRequest{Username: "alice", Password: "secret123", DeviceUUID: &uuid, RememberMe: true})
+
+		require.NoError(t, err)
+		if !trusted {
+			assert.Empty(t, result.RememberToken, "untrusted devices are not remembered")
+			continue
+		}
+		assert.Equal(t, result.Session.SessionID+"."+result.Session.RememberToken, result.RememberToken)
+		assert.Greater(t, result.Session.RememberUntil, time.Now().Add(89*24*time.Hour).Unix())
+	}
+}
+
+func rememberedSession() *domain.Session {
+	return &domain.Session{
+		SessionID: "sess-1", UserID: "user-123", DeviceID: "dev-1", Enable: true,
+		RememberToken: "secret", RememberUntil: time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestSilentRefresh_RotatesTokens(t *testing.T) {
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	ss.On("Get", mock.Anything, "sess-1").Return(rememberedSession(), nil)
+	ds.On("Get", mock.Anything, "dev-1").Return(&domain.Device{DeviceID: "dev-1", UUID: "uuid-1", Enable: true, Trusted: true}, nil)
+	us.On("Get", mock.Anything, "user-123").Return(existingUser(), nil)
+	ss.On("Update", mock.Anything, "sess-1", mock.MatchedBy(func(m map[string]interface{}) bool {
+		return m[fieldRememberToken] != "secret" && m[fieldRefreshToken] != nil
+	})).Return(nil)
+	jwt.On("Sign", "user-123", "dev-1", domain.RoleUser, "sess-1").Return("bearer", nil)
+
+	result, err := rememberSvc(us, ss, ds, jwt).SilentRefresh(context.Background(), "uuid-1", "sess-1.secret")
+
+	require.NoError(t, err)
+	assert.Equal(t, "bearer", result.Bearer)
+	assert.NotEmpty(t, result.RefreshToken)
+	assert.NotEqual(t, "sess-1.secret", result.RememberToken)
+	assert.Equal(t, "alice", result.Session.User.Username)
+}
+
+func TestSilentRefresh_Rejects(t *testing.T) {
+	cases := map[string]struct {
+		token  string
+		uuid   string
+		mutate func(*domain.Session, *domain.Device)
+	}{
+		"wrong secret":      {token: "sess-1.guess", uuid: "uuid-1"},
+		"malformed token":   {token: "sess-1", uuid: "uuid-1"},
+		"other device":      {token: "sess-1.secret", uuid: "uuid-2"},
+		"expired":           {token: "sess-1.secret", uuid: "uuid-1", mutate: func(s *domain.Session, _ *domain.Device) { s.RememberUntil = 1 }},
+		"logged out":        {token: "sess-1.secret", uuid: "uuid-1", mutate: func(s *domain.Session, _ *domain.Device) { s.Enable = false }},
+		"device untrusted":  {token: "sess-1.secret", uuid: "uuid-1", mutate: func(_ *domain.Session, d *domain.Device) { d.Trusted = false }},
+		"not remembered":    {token: "sess-1.", uuid: "uuid-1", mutate: func(s *domain.Session, _ *domain.Device) { s.RememberToken = "" }},
+		"remember disabled": {token: "sess-1.secret", uuid: "uuid-1"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+			sess := rememberedSession()
+			dev := &domain.Device{DeviceID: "dev-1", UUID: "uuid-1", Enable: true, Trusted: true}
+			if tc.mutate != nil {
+				tc.mutate(sess, dev)
+			}
+			ss.On("Get", mock.Anything, "sess-1").Return(sess, nil)
+			ds.On("Get", mock.Anything, "dev-1").Return(dev, nil)
+			us.On("Get", mock.Anything, "user-123").Return(existingUser(), nil)
+			svc := rememberSvc(us, ss, ds, jwt)
+			if name == "remember disabled" {
+				svc = NewService(ServiceDeps{UserRepo: us, SessionRepo: ss, DeviceRepo: ds, JWTProvider: jwt})
+			}
+
+			_, err := svc.SilentRefresh(context.Background(), tc.uuid, tc.token)
+
+			assert.ErrorIs(t, err, domain.ErrUnauthorized)
+			ss.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// --- client info tests ---
+
+func TestLogin_RecordsClientInfo(t *testing.T) {
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	u := existingUser()
+	u.PasswordHash = "$2a$10$hashedpassword"
+	us.On("GetByUsername", mock.Anything, "alice").Return(u, nil)
+	stubDevice(ds)
+	ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
+	jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer", nil)
+	ctx := clientinfo.WithInfo(context.Background(), clientinfo.Info{UserAgent: "MyApp/2.4 (iOS 17)", AppVersion: "2.4.1", IP: "203.0.113.7"})
+
+	result, err := rememberSvc(us, ss, ds, jwt).Login(ctx, LoginRequest{Username: "alice", Password: "secret123"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "MyApp/2.4 (iOS 17)", result.Session.UserAgent)
+	assert.Equal(t, "2.4.1", result.Session.AppVersion)
+	assert.Equal(t, "203.0.113.7", result.Session.IP)
+}
+
+func TestRefresh_RecordsClientInfo(t *testing.T) {
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	sess := &domain.Session{SessionID: "sess-1", UserID: "user-123", DeviceID: "dev-1", Enable: true, RefreshExpiresAt: time.Now().Add(time.Hour).Unix()}
+	ss.On("GetByRefreshToken", mock.Anything, "rt").Return(sess, nil)
+	ds.On("Get", mock.Anything, "dev-1").Return(nil, domain.ErrNotFound)
+	ss.On("Update", mock.Anything, "sess-1", mock.MatchedBy(func(m map[string]interface{}) bool {
+		return m[fieldIP] == "198.51.100.2" && m[fieldAppVersion] == "2.5.0" && m[fieldRefreshToken] != "rt"
+	})).Return(nil)
+	us.On("Get", mock.Anything, "user-123").Return(existingUser(), nil)
+	jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer", nil)
+	ctx := clientinfo.WithInfo(context.Background(), clientinfo.Info{AppVersion: "2.5.0", IP: "198.51.100.2"})
+
+	_, _, err := rememberSvc(us, ss, ds, jwt).Refresh(ctx, "rt")
+
+	require.NoError(t, err)
+	ss.AssertExpectations(t)
+}
+
+func TestRefresh_RejectsOAuthGrant(t *testing.T) {
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	sess := &domain.Session{SessionID: "sess-1", UserID: "user-123", ClientID: "client-1", Enable: true, RefreshExpiresAt: time.Now().Add(time.Hour).Unix()}
+	ss.On("GetByRefreshToken", mock.Anything, "rt").Return(sess, nil)
+
+	_, _, err := rememberSvc(us, ss, ds, jwt).Refresh(context.Background(), "rt")
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	ss.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- session limit tests ---
+
+type stubAudit struct{ entries []domain.AuditEntry }
+
+func (a *stubAudit) Record(_ context.Context, e domain.AuditEntry) { a.entries = append(a.entries, e) }
+
+func limitedLogin(t *testing.T, strict bool) (*mockSessionStore, *stubAudit, error) {
+	t.Helper()
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	u := existingUser()
+	u.PasswordHash = "$2a$10$hashedpassword"
+	us.On("GetByUsername", mock.Anything, "alice").Return(u, nil)
+	stubDevice(ds)
+	now := time.Now()
+	ss.On("ListActiveByUser", mock.Anything, "user-123").Return([]domain.Session{
+		{SessionID: "newer", CreatedAt: now.Add(-time.Hour)},
+		{SessionID: "oldest", CreatedAt: now.Add(-3 * time.Hour)},
+		{SessionID: "older", CreatedAt: now.Add(-2 * time.Hour)},
+		{SessionID: "grant", ClientID: "client-1", CreatedAt: now.Add(-4 * time.Hour)},
+	}, nil)
+	ss.On("Update", mock.Anything, mock.Anything, map[string]interface{}{fieldEnable: false}).Return(nil)
+	ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
+	jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer", nil)
+	audit := &stubAudit{}
+	svc := NewService(ServiceDeps{
+		UserRepo: us, SessionRepo: ss, DeviceRepo: ds, JWTProvider: jwt, Audit: audit,
+		MaxSessions: 2, StrictLimit: strict,
+		CompareHash: func(_, _ []byte) error { return nil },
+	})
+	_, err := svc.Login(context.Background(), LoginRequest{Username: "alice", Password: "secret123"})
+	return ss, audit, err
+}
+
+func TestLogin_SessionLimitDisablesOldest(t *testing.T) {
+	ss, audit, err := limitedLogin(t, false)
+
+	require.NoError(t, err)
+	ss.AssertCalled(t, "Update", mock.Anything, "oldest", mock.Anything)
+	ss.AssertCalled(t, "Update", mock.Anything, "older", mock.Anything)
+	ss.AssertNotCalled(t, "Update", mock.Anything, "newer", mock.Anything)
+	ss.AssertNotCalled(t, "Update", mock.Anything, "grant", mock.Anything)
+	require.Len(t, audit.entries, 2)
+	assert.Equal(t, domain.AuditSessionEvict, audit.entries[0].Action)
+	assert.Equal(t, "oldest", audit.entries[0].TargetID)
+}
+
+func TestLogin_StrictSessionLimitRejects(t *testing.T) {
+	ss, audit, err := limitedLogin(t, true)
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	ss.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	ss.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+	assert.Empty(t, audit.entries)
+}
+
+// --- high-risk login approval tests ---
+
+type stubMailer struct{ bodies []string }
+
+func (m *stubMailer) SendEmail(_, _, body string) error {
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+func TestLogin_HighRiskCountryWaitsForApproval(t *testing.T) {
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	u := existingUser()
+	u.PasswordHash = "$2a$10$hashedpassword"
+	us.On("GetByUsername", mock.Anything, "alice").Return(u, nil)
+	stubDevice(ds)
+	var stored *domain.Session
+	ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Run(func(args mock.Arguments) {
+		cp := *args.Get(1).(*domain.Session)
+		stored = &cp
+	}).Return(nil)
+	jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer", nil)
+	ml := &stubMailer{}
+	svc := NewService(ServiceDeps{
+		UserRepo: us, SessionRepo: ss, DeviceRepo: ds, JWTProvider: jwt, Mailer: ml,
+		HighRiskCountries: []string{"kp"}, ApprovalURL: "https://app.example.com/approve",
+		CompareHash: func(_, _ []byte) error { return nil },
+	})
+	ctx := clientinfo.WithInfo(context.Background(), clientinfo.Info{Country: "KP", IP: "203.0.113.7"})
+
+	result, err := svc.Login(ctx, LoginRequest{Username: "alice", Password: "secret123"})
+
+	require.NoError(t, err)
+	assert.True(t, result.Session.PendingApproval())
+	require.NotNil(t, stored)
+	assert.False(t, stored.Enable, "the session is stored disabled")
+	require.Len(t, ml.bodies, 1)
+	assert.Contains(t, ml.bodies[0], "https://app.example.com/approve?token="+stored.SessionID+"."+stored.ApprovalToken)
+
+	ss.On("Get", mock.Anything, stored.SessionID).Return(stored, nil)
+	ss.On("Update", mock.Anything, stored.SessionID, mock.MatchedBy(func(m map[string]interface{}) bool {
+		return m[fieldEnable] == true && m[fieldApprovalToken] == ""
+	})).Return(nil)
+	assert.ErrorIs(t, svc.ApproveLogin(context.Background(), stored.SessionID+".wrong"), domain.ErrUnauthorized)
+	require.NoError(t, svc.ApproveLogin(context.Background(), stored.SessionID+"."+stored.ApprovalToken))
+	ss.AssertExpectations(t)
+}
+
+func TestApproveLogin_ExpiredLinkIsRejected(t *testing.T) {
+	ss := &mockSessionStore{}
+	ss.On("Get", mock.Anything, "sess-1").Return(&domain.Session{
+		SessionID: "sess-1", ApprovalToken: "secret", ApprovalUntil: time.Now().Add(-time.Minute).Unix(),
+	}, nil)
+	svc := NewService(ServiceDeps{SessionRepo: ss})
+
+	err := svc.ApproveLogin(context.Background(), "sess-1.secret")
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	ss.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogin_OtherCountriesAreNotHeld(t *testing.T) {
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	u := existingUser()
+	u.PasswordHash = "$2a$10$hashedpassword"
+	us.On("GetByUsername", mock.Anything, "alice").Return(u, nil)
+	stubDevice(ds)
+	ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
+	jwt.On("Sign", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("bearer", nil)
+	ml := &stubMailer{}
+	svc := NewService(ServiceDeps{
+		UserRepo: us, SessionRepo: ss, DeviceRepo: ds, JWTProvider: jwt, Mailer: ml,
+		HighRiskCountries: []string{"KP"},
+		CompareHash:       func(_, _ []byte) error { return nil },
+	})
+	ctx := clientinfo.WithInfo(context.Background(), clientinfo.Info{Country: "FR"})
+
+	result, err := svc.Login(ctx, LoginRequest{Username: "alice", Password: "secret123"})
+
+	require.NoError(t, err)
+	assert.True(t, result.Session.Enable)
+	assert.Empty(t, ml.bodies)
+}
+
+type stubPasskeyVerifier struct {
+	userID string
+	err    error
+}
+
+func (v stubPasskeyVerifier) Verify(_ context.Context, _ domain.PasskeyAssertion) (string, error) {
+	return v.userID, v.err
+}
+
+func TestLoginWithPasskey_OpensSessionForOwner(t *testing.T) {
+	us, ss, ds, jwt := &mockUserStore{}, &mockSessionStore{}, &mockDeviceStore{}, &mockJWTSigner{}
+	us.On("Get", mock.Anything, "user-123").Return(existingUser(), nil)
+	stubDevice(ds)
+	ss.On("Put", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
+	jwt.On("Sign", "user-123", mock.Anything, domain.RoleUser, mock.Anything).Return("bearer", nil)
+	svc := NewService(ServiceDeps{
+		UserRepo: us, SessionRepo: ss, DeviceRepo: ds, JWTProvider: jwt,
+		Passkeys: stubPasskeyVerifier{userID: "user-123"},
+	})
+
+	result, err := svc.LoginWithPasskey(context.Background(), domain.PasskeyAssertion{ID: "cred"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "bearer", result.Bearer)
+	assert.Equal(t, "user-123", result.Session.UserID)
+}
+
+func TestLoginWithPasskey_Rejects(t *testing.T) {
+	disabled := existingUser()
+	disabled.Enable = 0
+	cases := map[string]struct {
+		verifier passkeyVerifier
+		user     *domain.User
+		want     error
+	}{
+		"passkeys off":     {want: domain.ErrNotFound},
+		"failed assertion": {verifier: stubPasskeyVerifier{err: domain.ErrUnauthorized}, want: domain.ErrUnauthorized},
+		"disabled account": {verifier: stubPasskeyVerifier{userID: "user-123"}, user: disabled, want: domain.ErrUnauthorized},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			us := &mockUserStore{}
+			us.On("Get", mock.Anything, "user-123").Return(tc.user, nil)
+			svc := NewService(ServiceDeps{UserRepo: us, Passkeys: tc.verifier})
+
+			_, err := svc.LoginWithPasskey(context.Background(), domain.PasskeyAssertion{ID: "cred"})
+
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+// --- TerminateSessions tests ---
+
+func terminateSvc(us *mockUserStore, ss *mockSessionStore, audit *stubAudit) Service {
+	us.On("Get", mock.Anything, "user-123").Return(existingUser(), nil)
+	return NewService(ServiceDeps{UserRepo: us, SessionRepo: ss, Audit: audit})
+}
+
+func TestTerminateSessions_All(t *testing.T) {
+	us, ss, audit := &mockUserStore{}, &mockSessionStore{}, &stubAudit{}
+	ss.On("ListActiveByUser", mock.Anything, "user-123").Return([]domain.Session{{SessionID: "s1"}, {SessionID: "s2"}}, nil)
+	ss.On("SoftDeleteByUser", mock.Anything, "user-123").Return(nil)
+
+	ended, err := terminateSvc(us, ss, audit).TerminateSessions(context.Background(), "admin-1", "user-123", "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1", "s2"}, ended)
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, domain.AuditSessionTerminate, audit.entries[0].Action)
+	assert.Equal(t, "all", audit.entries[0].Details["session_id"])
+}
+
+func TestTerminateSessions_One(t *testing.T) {
+	us, ss, audit := &mockUserStore{}, &mockSessionStore{}, &stubAudit{}
+	ss.On("Get", mock.Anything, "s1").Return(&domain.Session{SessionID: "s1", UserID: "user-123", Enable: true}, nil)
+	ss.On("Update", mock.Anything, "s1", map[string]interface{}{fieldEnable: false}).Return(nil)
+
+	ended, err := terminateSvc(us, ss, audit).TerminateSessions(context.Background(), "admin-1", "user-123", "s1")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1"}, ended)
+	ss.AssertExpectations(t)
+}
+
+func TestTerminateSessions_SessionOfAnotherUser(t *testing.T) {
+	us, ss, audit := &mockUserStore{}, &mockSessionStore{}, &stubAudit{}
+	ss.On("Get", mock.Anything, "s1").Return(&domain.Session{SessionID: "s1", UserID: "someone-else", Enable: true}, nil)
+
+	_, err := terminateSvc(us, ss, audit).TerminateSessions(context.Background(), "admin-1", "user-123", "s1")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	ss.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, audit.entries)
+}
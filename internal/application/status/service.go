@@ -2,24 +2,36 @@ package status
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	"github.com/go-api-nosql/internal/domain"
 	"github.com/go-api-nosql/internal/pkg/id"
 )
 
-// DynamoDB attribute name used in partial update maps.
-const fieldDescription = "description"
+// DynamoDB attribute names used in partial update maps.
+const (
+	fieldCode        = "code"
+	fieldDescription = "description"
+	fieldSortOrder   = "sort_order"
+	fieldLabels      = "labels"
+	fieldEnable      = "enable"
+)
 
 type Service interface {
-	List(ctx context.Context) ([]domain.Status, error)
+	// List returns enabled statuses ordered by sort_order, then code;
+	// includeDisabled also returns soft-deleted ones. A non-empty lang fills
+	// Label from Labels, falling back to Description.
+	List(ctx context.Context, includeDisabled bool, lang string) ([]domain.Status, error)
 	Get(ctx context.Context, statusID string) (*domain.Status, error)
 	Create(ctx context.Context, input domain.StatusInput) (*domain.Status, error)
 	Update(ctx context.Context, statusID string, input domain.StatusInput) (*domain.Status, error)
-	Delete(ctx context.Context, statusID string) error // hard delete
+	// Delete disables the status. With force it is removed permanently instead.
+	Delete(ctx context.Context, statusID string, force bool) error
 }
 
 type statusStore interface {
-	Scan(ctx context.Context) ([]domain.Status, error)
+	List(ctx context.Context) ([]domain.Status, error)
 	Get(ctx context.Context, statusID string) (*domain.Status, error)
 	Put(ctx context.Context, s *domain.Status) error
 	Update(ctx context.Context, statusID string, updates map[string]interface{}) error
@@ -34,8 +46,31 @@ func NewService(repo statusStore) Service {
 	return &service{repo: repo}
 }
 
-func (s *service) List(ctx context.Context) ([]domain.Status, error) {
-	return s.repo.Scan(ctx)
+func (s *service) List(ctx context.Context, includeDisabled bool, lang string) ([]domain.Status, error) {
+	statuses, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.Status, 0, len(statuses))
+	for _, st := range statuses {
+		if !st.Enable && !includeDisabled {
+			continue
+		}
+		if lang != "" {
+			st.Label = st.Labels[lang]
+			if st.Label == "" {
+				st.Label = st.Description
+			}
+		}
+		out = append(out, st)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].SortOrder != out[j].SortOrder {
+			return out[i].SortOrder < out[j].SortOrder
+		}
+		return out[i].Code < out[j].Code
+	})
+	return out, nil
 }
 
 func (s *service) Get(ctx context.Context, statusID string) (*domain.Status, error) {
@@ -43,9 +78,16 @@ func (s *service) Get(ctx context.Context, statusID string) (*domain.Status, err
 }
 
 func (s *service) Create(ctx context.Context, input domain.StatusInput) (*domain.Status, error) {
+	if err := s.checkCodeUnique(ctx, input.Code, ""); err != nil {
+		return nil, err
+	}
 	st := &domain.Status{
 		StatusID:    id.New(),
+		Code:        input.Code,
 		Description: input.Description,
+		SortOrder:   input.SortOrder,
+		Labels:      input.Labels,
+		Enable:      true,
 	}
 	if err := s.repo.Put(ctx, st); err != nil {
 		return nil, err
@@ -54,12 +96,55 @@ func (s *service) Create(ctx context.Context, input domain.StatusInput) (*domain
 }
 
 func (s *service) Update(ctx context.Context, statusID string, input domain.StatusInput) (*domain.Status, error) {
-	if err := s.repo.Update(ctx, statusID, map[string]interface{}{fieldDescription: input.Description}); err != nil {
+	if _, err := s.repo.Get(ctx, statusID); err != nil {
+		return nil, err
+	}
+	if err := s.checkCodeUnique(ctx, input.Code, statusID); err != nil {
+		return nil, err
+	}
+	labels := input.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	updates := map[string]interface{}{
+		fieldCode:        input.Code,
+		fieldDescription: input.Description,
+		fieldSortOrder:   input.SortOrder,
+		fieldLabels:      labels,
+	}
+	if input.Enable != nil {
+		updates[fieldEnable] = *input.Enable
+	}
+	if err := s.repo.Update(ctx, statusID, updates); err != nil {
 		return nil, err
 	}
 	return s.repo.Get(ctx, statusID)
 }
 
-func (s *service) Delete(ctx context.Context, statusID string) error {
-	return s.repo.HardDelete(ctx, statusID)
+// Delete soft-deletes by default so records that still carry the status keep
+// resolving it; force is required to remove the item from the table.
+func (s *service) Delete(ctx context.Context, statusID string, force bool) error {
+	if _, err := s.repo.Get(ctx, statusID); err != nil {
+		return err
+	}
+	if force {
+		return s.repo.HardDelete(ctx, statusID)
+	}
+	return s.repo.Update(ctx, statusID, map[string]interface{}{fieldEnable: false})
+}
+
+// checkCodeUnique returns ErrConflict if a status other than selfID already
+// uses code. Disabled statuses still reserve their code. The statuses table
+// is small, so a scan is acceptable here.
+func (s *service) checkCodeUnique(ctx context.Context, code, selfID string) error {
+	statuses, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, st := range statuses {
+		if st.Code == code && st.StatusID != selfID {
+			return fmt.Errorf("status code %q already in use: %w", code, domain.ErrConflict)
+		}
+	}
+	return nil
 }
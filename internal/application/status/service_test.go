@@ -0,0 +1,186 @@
+package status
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockStatusStore struct{ mock.Mock }
+
+func (m *mockStatusStore) List(ctx context.Context) ([]domain.Status, error) {
+	args := m.Called(ctx)
+	ss, _ := args.Get(0).([]domain.Status)
+	return ss, args.Error(1)
+}
+
+func (m *mockStatusStore) Get(ctx context.Context, statusID string) (*domain.Status, error) {
+	args := m.Called(ctx, statusID)
+	if st, _ := args.Get(0).(*domain.Status); st != nil {
+		return st, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStatusStore) Put(ctx context.Context, s *domain.Status) error {
+	return m.Called(ctx, s).Error(0)
+}
+
+func (m *mockStatusStore) Update(ctx context.Context, statusID string, updates map[string]interface{}) error {
+	return m.Called(ctx, statusID, updates).Error(0)
+}
+
+func (m *mockStatusStore) HardDelete(ctx context.Context, statusID string) error {
+	return m.Called(ctx, statusID).Error(0)
+}
+
+func mixedStatuses() []domain.Status {
+	return []domain.Status{
+		{StatusID: "s2", Code: "retired", Description: "retired", SortOrder: 2, Enable: false},
+		{StatusID: "s1", Code: "active", Description: "active", SortOrder: 1, Enable: true,
+			Labels: map[string]string{"es": "activo"}},
+	}
+}
+
+// --- List tests ---
+
+func TestList_HidesDisabledStatuses(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("List", mock.Anything).Return(mixedStatuses(), nil)
+
+	got, err := NewService(repo).List(context.Background(), false, "")
+
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "s1", got[0].StatusID)
+}
+
+func TestList_IncludeDisabledReturnsAll(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("List", mock.Anything).Return(mixedStatuses(), nil)
+
+	got, err := NewService(repo).List(context.Background(), true, "")
+
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestList_SortsBySortOrderThenCode(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("List", mock.Anything).Return([]domain.Status{
+		{StatusID: "a", Code: "zeta", SortOrder: 1, Enable: true},
+		{StatusID: "b", Code: "beta", SortOrder: 2, Enable: true},
+		{StatusID: "c", Code: "alpha", SortOrder: 1, Enable: true},
+	}, nil)
+
+	got, err := NewService(repo).List(context.Background(), false, "")
+
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, []string{"alpha", "zeta", "beta"}, []string{got[0].Code, got[1].Code, got[2].Code})
+}
+
+func TestList_LangSelectsLabelWithDescriptionFallback(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("List", mock.Anything).Return(mixedStatuses(), nil)
+
+	got, err := NewService(repo).List(context.Background(), true, "es")
+
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "activo", got[0].Label)
+	assert.Equal(t, "retired", got[1].Label)
+}
+
+// --- Create / Update tests ---
+
+func TestCreate_EnablesNewStatus(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("List", mock.Anything).Return(mixedStatuses(), nil)
+	repo.On("Put", mock.Anything, mock.MatchedBy(func(s *domain.Status) bool { return s.Enable })).Return(nil)
+
+	st, err := NewService(repo).Create(context.Background(), domain.StatusInput{Code: "pending", Description: "pending"})
+
+	require.NoError(t, err)
+	assert.True(t, st.Enable)
+	assert.Equal(t, "pending", st.Code)
+}
+
+func TestCreate_DuplicateCodeReturnsConflict(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("List", mock.Anything).Return(mixedStatuses(), nil)
+
+	_, err := NewService(repo).Create(context.Background(), domain.StatusInput{Code: "retired", Description: "again"})
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	repo.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestUpdate_CodeTakenByAnotherStatusReturnsConflict(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("Get", mock.Anything, "s2").Return(&domain.Status{StatusID: "s2"}, nil)
+	repo.On("List", mock.Anything).Return(mixedStatuses(), nil)
+
+	_, err := NewService(repo).Update(context.Background(), "s2", domain.StatusInput{Code: "active", Description: "retired"})
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdate_RestoresWhenEnableSet(t *testing.T) {
+	repo := &mockStatusStore{}
+	enable := true
+	repo.On("Get", mock.Anything, "s2").Return(&domain.Status{StatusID: "s2"}, nil)
+	repo.On("List", mock.Anything).Return(mixedStatuses(), nil)
+	repo.On("Update", mock.Anything, "s2", map[string]interface{}{
+		fieldCode:        "retired",
+		fieldDescription: "retired",
+		fieldSortOrder:   2,
+		fieldLabels:      map[string]string{},
+		fieldEnable:      true,
+	}).Return(nil)
+
+	input := domain.StatusInput{Code: "retired", Description: "retired", SortOrder: 2, Enable: &enable}
+	_, err := NewService(repo).Update(context.Background(), "s2", input)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// --- Delete tests ---
+
+func TestDelete_SoftDeletesByDefault(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("Get", mock.Anything, "s1").Return(&domain.Status{StatusID: "s1", Enable: true}, nil)
+	repo.On("Update", mock.Anything, "s1", map[string]interface{}{fieldEnable: false}).Return(nil)
+
+	require.NoError(t, NewService(repo).Delete(context.Background(), "s1", false))
+
+	repo.AssertNotCalled(t, "HardDelete", mock.Anything, mock.Anything)
+}
+
+func TestDelete_ForceRemovesItem(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("Get", mock.Anything, "s1").Return(&domain.Status{StatusID: "s1"}, nil)
+	repo.On("HardDelete", mock.Anything, "s1").Return(nil)
+
+	require.NoError(t, NewService(repo).Delete(context.Background(), "s1", true))
+
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDelete_UnknownStatusReturnsNotFound(t *testing.T) {
+	repo := &mockStatusStore{}
+	repo.On("Get", mock.Anything, "nope").Return(nil, domain.ErrNotFound)
+
+	err := NewService(repo).Delete(context.Background(), "nope", true)
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	repo.AssertNotCalled(t, "HardDelete", mock.Anything, mock.Anything)
+}
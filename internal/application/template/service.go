@@ -0,0 +1,115 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// DynamoDB attribute names used in partial update maps.
+const (
+	fieldCategory = "category"
+	fieldBodies   = "bodies"
+)
+
+// placeholder matches {{name}} with optional surrounding spaces.
+var placeholder = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+
+type Service interface {
+	List(ctx context.Context) ([]domain.NotificationTemplate, error)
+	Get(ctx context.Context, name string) (*domain.NotificationTemplate, error)
+	Create(ctx context.Context, req domain.CreateNotificationTemplateRequest) (*domain.NotificationTemplate, error)
+	Update(ctx context.Context, name string, input domain.NotificationTemplateInput) (*domain.NotificationTemplate, error)
+	Delete(ctx context.Context, name string) error // hard delete
+	// Render returns the template with every body's placeholders replaced from params.
+	// A placeholder without a matching param is ErrBadRequest.
+	Render(ctx context.Context, name string, params map[string]string) (*domain.NotificationTemplate, error)
+}
+
+type templateStore interface {
+	Create(ctx context.Context, t *domain.NotificationTemplate) error
+	Get(ctx context.Context, name string) (*domain.NotificationTemplate, error)
+	List(ctx context.Context) ([]domain.NotificationTemplate, error)
+	Update(ctx context.Context, name string, updates map[string]interface{}) error
+	HardDelete(ctx context.Context, name string) error
+}
+
+type service struct {
+	repo templateStore
+}
+
+func NewService(repo templateStore) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) List(ctx context.Context) ([]domain.NotificationTemplate, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) Get(ctx context.Context, name string) (*domain.NotificationTemplate, error) {
+	return s.repo.Get(ctx, name)
+}
+
+func (s *service) Create(ctx context.Context, req domain.CreateNotificationTemplateRequest) (*domain.NotificationTemplate, error) {
+	if req.Bodies[domain.ChannelInApp] == "" {
+		return nil, fmt.Errorf("bodies.in_app is required: %w", domain.ErrBadRequest)
+	}
+	now := time.Now().UTC()
+	t := &domain.NotificationTemplate{
+		Name:      req.Name,
+		Category:  req.Category,
+		Bodies:    req.Bodies,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *service) Update(ctx context.Context, name string, input domain.NotificationTemplateInput) (*domain.NotificationTemplate, error) {
+	if input.Bodies[domain.ChannelInApp] == "" {
+		return nil, fmt.Errorf("bodies.in_app is required: %w", domain.ErrBadRequest)
+	}
+	if _, err := s.repo.Get(ctx, name); err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{fieldCategory: input.Category, fieldBodies: input.Bodies}
+	if err := s.repo.Update(ctx, name, updates); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, name)
+}
+
+func (s *service) Delete(ctx context.Context, name string) error {
+	return s.repo.HardDelete(ctx, name)
+}
+
+func (s *service) Render(ctx context.Context, name string, params map[string]string) (*domain.NotificationTemplate, error) {
+	t, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	bodies := make(map[string]string, len(t.Bodies))
+	var missing []string
+	for channel, body := range t.Bodies {
+		bodies[channel] = placeholder.ReplaceAllStringFunc(body, func(m string) string {
+			key := placeholder.FindStringSubmatch(m)[1]
+			v, ok := params[key]
+			if !ok {
+				missing = append(missing, key)
+			}
+			return v
+		})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing template params %s: %w", strings.Join(missing, ", "), domain.ErrBadRequest)
+	}
+	t.Bodies = bodies
+	return t, nil
+}
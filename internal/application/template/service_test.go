@@ -0,0 +1,90 @@
+package template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- mocks ---
+
+type mockTemplateStore struct{ mock.Mock }
+
+func (m *mockTemplateStore) Create(ctx context.Context, t *domain.NotificationTemplate) error {
+	return m.Called(ctx, t).Error(0)
+}
+
+func (m *mockTemplateStore) Get(ctx context.Context, name string) (*domain.NotificationTemplate, error) {
+	args := m.Called(ctx, name)
+	if t, _ := args.Get(0).(*domain.NotificationTemplate); t != nil {
+		return t, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockTemplateStore) List(ctx context.Context) ([]domain.NotificationTemplate, error) {
+	args := m.Called(ctx)
+	ts, _ := args.Get(0).([]domain.NotificationTemplate)
+	return ts, args.Error(1)
+}
+
+func (m *mockTemplateStore) Update(ctx context.Context, name string, updates map[string]interface{}) error {
+	return m.Called(ctx, name, updates).Error(0)
+}
+
+func (m *mockTemplateStore) HardDelete(ctx context.Context, name string) error {
+	return m.Called(ctx, name).Error(0)
+}
+
+func welcomeTemplate() *domain.NotificationTemplate {
+	return &domain.NotificationTemplate{
+		Name:     "welcome",
+		Category: "account",
+		Bodies: map[string]string{
+			domain.ChannelInApp: "Welcome, {{name}}! Your plan is {{ plan }}.",
+			domain.ChannelPush:  "Welcome {{name}}",
+		},
+	}
+}
+
+// --- Render tests ---
+
+func TestRender_ReplacesPlaceholdersInEveryBody(t *testing.T) {
+	repo := &mockTemplateStore{}
+	repo.On("Get", mock.Anything, "welcome").Return(welcomeTemplate(), nil)
+
+	got, err := NewService(repo).Render(context.Background(), "welcome", map[string]string{"name": "Ada", "plan": "pro"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome, Ada! Your plan is pro.", got.Bodies[domain.ChannelInApp])
+	assert.Equal(t, "Welcome Ada", got.Bodies[domain.ChannelPush])
+	assert.Equal(t, "account", got.Category)
+}
+
+func TestRender_MissingParam(t *testing.T) {
+	repo := &mockTemplateStore{}
+	repo.On("Get", mock.Anything, "welcome").Return(welcomeTemplate(), nil)
+
+	_, err := NewService(repo).Render(context.Background(), "welcome", map[string]string{"name": "Ada"})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	assert.Contains(t, err.Error(), "plan")
+}
+
+// --- Create tests ---
+
+func TestCreate_RequiresInAppBody(t *testing.T) {
+	_, err := NewService(&mockTemplateStore{}).Create(context.Background(), domain.CreateNotificationTemplateRequest{
+		Name: "push-only",
+		NotificationTemplateInput: domain.NotificationTemplateInput{
+			Category: "promo",
+			Bodies:   map[string]string{domain.ChannelPush: "hi"},
+		},
+	})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
@@ -0,0 +1,56 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// Policy maps roles to daily quotas. Roles without an entry get Default.
+type Policy struct {
+	Default domain.UsageQuota            `json:"default"`
+	Roles   map[string]domain.UsageQuota `json:"roles"`
+}
+
+// ParsePolicy decodes a JSON quota document and validates its limits.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse usage quotas: %w", err)
+	}
+	if err := validQuota(p.Default); err != nil {
+		return nil, fmt.Errorf("usage quotas default: %w", err)
+	}
+	for role, q := range p.Roles {
+		if err := validQuota(q); err != nil {
+			return nil, fmt.Errorf("usage quotas role %s: %w", role, err)
+		}
+	}
+	return &p, nil
+}
+
+// LoadPolicy reads and parses the JSON quota file at path.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read usage quotas: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// QuotaFor returns the quota of role.
+func (p *Policy) QuotaFor(role string) domain.UsageQuota {
+	if q, ok := p.Roles[role]; ok {
+		return q
+	}
+	return p.Default
+}
+
+func validQuota(q domain.UsageQuota) error {
+	if q.Requests < 0 || q.BytesIn < 0 || q.BytesOut < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Package usage meters API requests and body bytes per user and UTC day and
+// enforces the daily quota of the caller's role, capped by their plan.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// dayLayout keys the counters of one UTC day.
+const dayLayout = "2006-01-02"
+
+type Service interface {
+	// Admit counts one request by userID and returns a domain.ErrTooMany
+	// error once the day's quota for role is used up.
+	Admit(ctx context.Context, userID, role string) error
+	// RecordBytes adds the request and response body bytes of a served request.
+	RecordBytes(ctx context.Context, userID string, in, out int64) error
+	// Today returns userID's usage so far today with the quota of role.
+	Today(ctx context.Context, userID, role string) (*domain.UsageReport, error)
+}
+
+type usageStore interface {
+	Add(ctx context.Context, userID, day string, delta domain.Usage, expiresAt int64) (*domain.Usage, error)
+	Get(ctx context.Context, userID, day string) (*domain.Usage, error)
+}
+
+// planLimits resolves the entitlements of a user's billing plan.
+type planLimits interface {
+	Entitlements(ctx context.Context, userID string) (domain.Entitlements, error)
+}
+
+type service struct {
+	repo      usageStore
+	policy    *Policy
+	plans     planLimits
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewService keeps each day's counters for retention after the day ends.
+// plans may be nil to apply the role quotas alone.
+func NewService(repo usageStore, policy *Policy, plans planLimits, retention time.Duration) Service {
+	return &service{repo: repo, policy: policy, plans: plans, retention: retention, now: time.Now}
+}
+
+func (s *service) Admit(ctx context.Context, userID, role string) error {
+	u, err := s.add(ctx, userID, domain.Usage{Requests: 1})
+	if err != nil {
+		return err
+	}
+	q, err := s.quota(ctx, userID, role)
+	if err != nil {
+		return err
+	}
+	if q.Exceeded(*u) {
+		return fmt.Errorf("daily usage quota exceeded: %w", domain.ErrTooMany)
+	}
+	return nil
+}
+
+func (s *service) RecordBytes(ctx context.Context, userID string, in, out int64) error {
+	_, err := s.add(ctx, userID, domain.Usage{BytesIn: in, BytesOut: out})
+	return err
+}
+
+func (s *service) add(ctx context.Context, userID string, delta domain.Usage) (*domain.Usage, error) {
+	day, next := s.day()
+	return s.repo.Add(ctx, userID, day, delta, next.Add(s.retention).Unix())
+}
+
+func (s *service) Today(ctx context.Context, userID, role string) (*domain.UsageReport, error) {
+	day, next := s.day()
+	u, err := s.repo.Get(ctx, userID, day)
+	if err != nil {
+		return nil, err
+	}
+	q, err := s.quota(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.UsageReport{Usage: *u, Quota: q, ResetsAt: next}, nil
+}
+
+// quota returns the quota of role with the request limit lowered to the
+// daily requests of userID's plan when that is stricter. Zero is unlimited.
+func (s *service) quota(ctx context.Context, userID, role string) (domain.UsageQuota, error) {
+	q := s.policy.QuotaFor(role)
+	if s.plans == nil {
+		return q, nil
+	}
+	ent, err := s.plans.Entitlements(ctx, userID)
+	if err != nil {
+		return q, err
+	}
+	if ent.DailyRequests > 0 && (q.Requests == 0 || ent.DailyRequests < q.Requests) {
+		q.Requests = ent.DailyRequests
+	}
+	return q, nil
+}
+
+// day returns the key of the current UTC day and the midnight that ends it.
+func (s *service) day() (string, time.Time) {
+	now := s.now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return start.Format(dayLayout), start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,112 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- stubs ---
+
+// memStore keeps counters in a map keyed by user and day.
+type memStore struct {
+	items   map[string]domain.Usage
+	expires int64
+}
+
+func (m *memStore) Add(_ context.Context, userID, day string, delta domain.Usage, expiresAt int64) (*domain.Usage, error) {
+	u := m.items[userID+"/"+day]
+	u.UserID, u.Day = userID, day
+	u.Requests += delta.Requests
+	u.BytesIn += delta.BytesIn
+	u.BytesOut += delta.BytesOut
+	m.items[userID+"/"+day] = u
+	m.expires = expiresAt
+	return &u, nil
+}
+
+func (m *memStore) Get(_ context.Context, userID, day string) (*domain.Usage, error) {
+	u := m.items[userID+"/"+day]
+	u.UserID, u.Day = userID, day
+	return &u, nil
+}
+
+// fixedPlans grants every user the same plan entitlements.
+type fixedPlans domain.Entitlements
+
+func (p fixedPlans) Entitlements(context.Context, string) (domain.Entitlements, error) {
+	return domain.Entitlements(p), nil
+}
+
+func newTestService(t *testing.T, now time.Time) (*service, *memStore) {
+	policy, err := ParsePolicy([]byte(`{"default":{"requests":2,"bytes_out":100},"roles":{"Admin":{}}}`))
+	require.NoError(t, err)
+	store := &memStore{items: map[string]domain.Usage{}}
+	svc := NewService(store, policy, nil, 24*time.Hour).(*service)
+	svc.now = func() time.Time { return now }
+	return svc, store
+}
+
+// --- tests ---
+
+func TestAdmit_RejectsRequestsPastQuota(t *testing.T) {
+	svc, _ := newTestService(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	require.NoError(t, svc.Admit(ctx, "u1", domain.RoleUser))
+	require.NoError(t, svc.Admit(ctx, "u1", domain.RoleUser))
+	assert.ErrorIs(t, svc.Admit(ctx, "u1", domain.RoleUser), domain.ErrTooMany)
+	assert.NoError(t, svc.Admit(ctx, "u2", domain.RoleUser), "quotas are per user")
+	for range 5 {
+		assert.NoError(t, svc.Admit(ctx, "admin", domain.RoleAdmin), "an empty quota is unlimited")
+	}
+}
+
+func TestAdmit_RejectsOnceBytesAreUsedUp(t *testing.T) {
+	svc, _ := newTestService(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	require.NoError(t, svc.RecordBytes(ctx, "u1", 10, 100))
+
+	assert.ErrorIs(t, svc.Admit(ctx, "u1", domain.RoleUser), domain.ErrTooMany)
+}
+
+func TestToday_ReportsUsageQuotaAndReset(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	svc, store := newTestService(t, now)
+	ctx := context.Background()
+	require.NoError(t, svc.Admit(ctx, "u1", domain.RoleUser))
+	require.NoError(t, svc.RecordBytes(ctx, "u1", 5, 7))
+
+	report, err := svc.Today(ctx, "u1", domain.RoleUser)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.Usage{UserID: "u1", Day: "2026-03-01", Requests: 1, BytesIn: 5, BytesOut: 7}, report.Usage)
+	assert.Equal(t, domain.UsageQuota{Requests: 2, BytesOut: 100}, report.Quota)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), report.ResetsAt)
+	assert.Equal(t, time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC).Unix(), store.expires, "counters outlive the day by the retention")
+}
+
+func TestAdmit_PlanRequestLimitCapsRoleQuota(t *testing.T) {
+	svc, _ := newTestService(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	svc.plans = fixedPlans{DailyRequests: 1}
+	ctx := context.Background()
+
+	require.NoError(t, svc.Admit(ctx, "u1", domain.RoleUser))
+	assert.ErrorIs(t, svc.Admit(ctx, "u1", domain.RoleUser), domain.ErrTooMany, "the plan is stricter than the role")
+	require.NoError(t, svc.Admit(ctx, "admin", domain.RoleAdmin))
+	assert.ErrorIs(t, svc.Admit(ctx, "admin", domain.RoleAdmin), domain.ErrTooMany, "the plan limits unlimited roles")
+
+	report, err := svc.Today(ctx, "u1", domain.RoleUser)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UsageQuota{Requests: 1, BytesOut: 100}, report.Quota)
+}
+
+func TestParsePolicy_RejectsNegativeLimits(t *testing.T) {
+	_, err := ParsePolicy([]byte(`{"roles":{"User":{"requests":-1}}}`))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/infrastructure/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func BenchmarkGet(b *testing.B) {
+	repo := memory.NewUserRepo(false)
+	ctx := context.Background()
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("user-%04d", i)
+		require.NoError(b, repo.Put(ctx, &domain.User{
+			UserID: ids[i], Username: ids[i], Email: ids[i] + "@example.com",
+			Role: domain.RoleUser, Enable: 1, CreatedAt: time.Now(),
+		}))
+	}
+	svc := NewService(ServiceDeps{UserRepo: repo})
+
+	i := 0
+	for b.Loop() {
+		if _, err := svc.Get(ctx, ids[i%len(ids)]); err != nil {
+			b.Fatal(err)
+		}
+		i++
+	}
+}
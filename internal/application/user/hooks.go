@@ -0,0 +1,38 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// PreRegisterHook runs after a registration request passes validation and
+// before the account is stored. Returning an error rejects the registration;
+// wrap a domain sentinel (e.g. domain.ErrForbidden) to choose the HTTP status.
+type PreRegisterHook interface {
+	PreRegister(ctx context.Context, req *domain.CreateUserRequest) error
+}
+
+// PostRegisterHook runs after the account has been stored. It cannot fail the
+// registration; errors are logged.
+type PostRegisterHook interface {
+	PostRegister(ctx context.Context, u *domain.User) error
+}
+
+func (s *service) runPreRegister(ctx context.Context, req *domain.CreateUserRequest) error {
+	for _, h := range s.preRegister {
+		if err := h.PreRegister(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) runPostRegister(ctx context.Context, u *domain.User) {
+	for _, h := range s.postRegister {
+		if err := h.PostRegister(ctx, u); err != nil {
+			slog.Warn("post-register hook failed", "user_id", u.UserID, "err", err)
+		}
+	}
+}
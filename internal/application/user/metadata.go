@@ -0,0 +1,42 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+func (s *service) CheckSelfMetadata(patch map[string]*string) error {
+	for k := range patch {
+		if !s.selfMetadata[k] {
+			return fmt.Errorf("metadata key %q can only be set by an admin: %w", k, domain.ErrForbidden)
+		}
+	}
+	return nil
+}
+
+// mergeMetadata applies patch to userID's metadata, removing the keys whose
+// value is nil, and returns the validated result.
+func (s *service) mergeMetadata(ctx context.Context, userID string, patch map[string]*string) (map[string]string, error) {
+	u, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	merged := maps.Clone(u.Metadata)
+	if merged == nil {
+		merged = make(map[string]string, len(patch))
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = *v
+	}
+	if err := domain.ValidateMetadata(merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
@@ -0,0 +1,88 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const inviteSubject = "Your account is ready"
+
+func (s *service) Provision(ctx context.Context, actorID string, req domain.ProvisionUserRequest) (*domain.User, error) {
+	role := req.Role
+	switch role {
+	case "":
+		role = domain.RoleUser
+	case domain.RoleAdmin:
+		// Admin grants go through ChangeRole, which approvals can hold.
+		return nil, fmt.Errorf("grant Admin after provisioning, through the role endpoint: %w", domain.ErrBadRequest)
+	}
+	if _, err := s.roleRepo.Get(ctx, role); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("unknown role %q: %w", role, domain.ErrBadRequest)
+		}
+		return nil, err
+	}
+	if _, err := s.repo.GetByUsername(ctx, req.Username); err == nil {
+		return nil, fmt.Errorf("username already taken: %w", domain.ErrConflict)
+	}
+	if _, err := s.repo.GetByEmail(ctx, req.Email); err == nil {
+		return nil, fmt.Errorf("email already registered: %w", domain.ErrConflict)
+	}
+	password, err := pkgtoken.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	u := &domain.User{
+		UserID:         id.New(),
+		Username:       req.Username,
+		Email:          req.Email,
+		Phone:          req.Phone,
+		PasswordHash:   string(hash),
+		PasswordChange: true,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Role:           role,
+		Enable:         1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.Put(ctx, u); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditUserProvision,
+		ActorID:  actorID,
+		TargetID: u.UserID,
+		Details:  map[string]string{"role": role},
+	})
+	s.sendInvite(u, password)
+	return u, nil
+}
+
+// sendInvite emails u its username and temporary password. A failed send is
+// only logged: the account exists, and its owner can still set a password
+// through password recovery.
+func (s *service) sendInvite(u *domain.User, password string) {
+	if s.mailer == nil {
+		slog.Warn("no mailer configured; provisioned user must use password recovery", "user_id", u.UserID)
+		return
+	}
+	body := fmt.Sprintf("Hi %s,\n\nAn account has been created for you.\n\nUsername: %s\nTemporary password: %s\n\n"+
+		"You will be asked to choose a new password when you first sign in.", u.FirstName, u.Username, password)
+	if err := s.mailer.SendEmail(u.Email, inviteSubject, body); err != nil {
+		slog.Warn("provisioned user invite email failed", "user_id", u.UserID, "error", err)
+	}
+}
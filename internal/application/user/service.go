@@ -2,47 +2,99 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-api-nosql/internal/domain"
 	pkgdevice "github.com/go-api-nosql/internal/pkg/device"
 	"github.com/go-api-nosql/internal/pkg/id"
+	"github.com/go-api-nosql/internal/pkg/password"
+	"github.com/go-api-nosql/internal/pkg/tenant"
 	pkgtoken "github.com/go-api-nosql/internal/pkg/token"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // DynamoDB attribute names used in partial update maps.
 const (
-	fieldUsername     = "username"
-	fieldEmail        = "email"
-	fieldPhone        = "phone"
-	fieldFirstName    = "first_name"
-	fieldLastName     = "last_name"
-	fieldBirthday     = "birthday"
-	fieldRole         = "role"
-	fieldEnable       = "enable"
-	fieldPasswordHash = "password_hash"
+	fieldUsername      = "username"
+	fieldEmail         = "email"
+	fieldPhone         = "phone"
+	fieldFirstName     = "first_name"
+	fieldLastName      = "last_name"
+	fieldBirthday      = "birthday"
+	fieldRole          = "role"
+	fieldEnable        = "enable"
+	fieldPasswordHash  = "password_hash"
+	fieldPublicProfile = "public_profile"
+	fieldOnboardOptOut = "onboarding_opt_out"
+	fieldMetadata      = "metadata"
+	fieldTimezone      = "timezone"
+	fieldLocale        = "locale"
 )
 
 type Service interface {
 	Register(ctx context.Context, req domain.CreateUserRequest) (*domain.User, error)
+	// RegisterWithSession is self-service registration: Register, after the
+	// minimum age check, followed by a session on the caller's device.
 	RegisterWithSession(ctx context.Context, req domain.CreateUserRequest) (*domain.Session, string, string, error)
-	List(ctx context.Context, limit int, cursor string) ([]domain.User, string, error)
+	// Provision creates an account on an admin's behalf and emails it a
+	// temporary password that its first login must replace. Registration
+	// hooks do not run, and the role may not be Admin. The creation is
+	// audited as actorID.
+	Provision(ctx context.Context, actorID string, req domain.ProvisionUserRequest) (*domain.User, error)
+	// List returns a page of non-deleted users matching f; f.Enable defaults
+	// to enabled users only.
+	List(ctx context.Context, f domain.UserFilter, limit int, cursor string) ([]domain.User, string, error)
+	// ApproxTotal estimates the number of stored users (refreshed every few
+	// hours, deleted users included) for page indicators. Inside a tenant it
+	// fails with domain.ErrTableWideCount.
+	ApproxTotal(ctx context.Context) (int64, error)
 	Get(ctx context.Context, userID string) (*domain.User, error)
+	// GetPublic returns the enabled, non-deleted user with username if they
+	// opted into a public profile, and ErrNotFound otherwise.
+	GetPublic(ctx context.Context, username string) (*domain.User, error)
 	Update(ctx context.Context, userID string, req domain.UpdateUserRequest) (*domain.User, error)
+	// CheckSelfMetadata returns ErrForbidden unless users may set every key
+	// of patch on their own profile. Admins may set any key.
+	CheckSelfMetadata(patch map[string]*string) error
 	Delete(ctx context.Context, userID string) error
 	ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error
+	// RequireTrustedDevice returns ErrUntrustedDevice unless deviceID has completed an OTP
+	// challenge or TrustedDeviceRequired is off. Callers must check it before sensitive
+	// operations such as password or email changes.
+	RequireTrustedDevice(ctx context.Context, deviceID string) error
+	// ChangeRole sets targetID's role to one defined in the roles table and
+	// records an audit entry attributed to actorID. Demoting the last enabled
+	// admin is ErrConflict.
+	ChangeRole(ctx context.Context, actorID, targetID string, req domain.ChangeRoleRequest) (*domain.User, error)
+	// EnsureAdmin creates or promotes the account for email when no enabled
+	// admin exists. An empty password creates the account with a random one
+	// that must be reset through password recovery. An empty email only logs.
+	EnsureAdmin(ctx context.Context, email, password string) error
+	// Export calls fn for every user matching f, including deleted ones.
+	Export(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error
+	// Bulk applies req to each distinct user ID with bounded concurrency and
+	// reports a result per user, in request order. Each change is audited as
+	// actorID. Only request-level problems (e.g. an unknown role) are errors.
+	Bulk(ctx context.Context, actorID string, req domain.BulkUserRequest) ([]domain.BulkUserResult, error)
 }
 
 type userStore interface {
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	Put(ctx context.Context, u *domain.User) error
-	QueryPage(ctx context.Context, limit int32, cursor string) ([]domain.User, string, error)
+	QueryPage(ctx context.Context, f domain.UserFilter, limit int32, cursor string) ([]domain.User, string, error)
+	ApproxCount(ctx context.Context) (int64, error)
 	Get(ctx context.Context, userID string) (*domain.User, error)
 	Update(ctx context.Context, userID string, updates map[string]interface{}) error
 	SoftDelete(ctx context.Context, userID string) error
+	CountByRole(ctx context.Context, role string) (int, error)
+	EachMatching(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error
 }
 
 type sessionStore interface {
@@ -51,12 +103,29 @@ type sessionStore interface {
 }
 
 type deviceStore interface {
-	GetByUUID(ctx context.Context, uuid string) (*domain.Device, error)
+	GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error)
+	Get(ctx context.Context, deviceID string) (*domain.Device, error)
 	Put(ctx context.Context, d *domain.Device) error
 }
 
 type jwtSigner interface {
-	Sign(userID, deviceID, role, sessionID string) (string, error)
+	Sign(ctx context.Context, userID, deviceID, role, sessionID string) (string, error)
+}
+
+type activityRecorder interface {
+	Record(ctx context.Context, userID, kind, subject string)
+}
+
+type roleStore interface {
+	Get(ctx context.Context, name string) (*domain.Role, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, e domain.AuditEntry)
+}
+
+type mailer interface {
+	SendEmail(to, subject, body string) error
 }
 
 type service struct {
@@ -65,6 +134,18 @@ type service struct {
 	deviceRepo      deviceStore
 	jwtProvider     jwtSigner
 	refreshTokenDur time.Duration
+	untrustedDur    time.Duration
+	activity        activityRecorder
+	roleRepo        roleStore
+	audit           auditRecorder
+	antiEnumeration bool
+	preRegister     []PreRegisterHook
+	postRegister    []PostRegisterHook
+	mailer          mailer
+	selfMetadata    map[string]bool
+	minAge          int
+	passwordPolicy  password.Policy
+	trustedDevices  bool
 }
 
 type ServiceDeps struct {
@@ -73,36 +154,83 @@ type ServiceDeps struct {
 	DeviceRepo      deviceStore
 	JWTProvider     jwtSigner
 	RefreshTokenDur time.Duration
+	// UntrustedDur is the refresh-token lifetime on untrusted devices (0 = RefreshTokenDur).
+	UntrustedDur time.Duration
+	Activity     activityRecorder // optional; records profile updates in the user's feed
+	RoleRepo     roleStore
+	Audit        auditRecorder
+	// AntiEnumeration reports registration conflicts without saying whether
+	// the username or the email is taken.
+	AntiEnumeration bool
+	// PreRegister and PostRegister are deployment hooks run, in order, around
+	// self-service registration.
+	PreRegister  []PreRegisterHook
+	PostRegister []PostRegisterHook
+	Mailer       mailer // sends provisioned accounts their temporary password
+	// SelfMetadataKeys are the metadata keys users may set on their own
+	// profile; the rest are for admins.
+	SelfMetadataKeys []string
+	// MinimumAge refuses self-service registrations of anyone younger, with
+	// domain.ErrUnderage; the birthday is then required. Zero disables it.
+	MinimumAge int
+	// PasswordPolicy is checked, breach check included, on self-service
+	// registration, password changes and ADMIN_PASSWORD.
+	PasswordPolicy password.Policy
+	// TrustedDeviceRequired makes RequireTrustedDevice refuse devices that
+	// have not passed an OTP challenge; unset, every device may proceed.
+	TrustedDeviceRequired bool
 }
 
 func NewService(deps ServiceDeps) Service {
+	selfMetadata := make(map[string]bool, len(deps.SelfMetadataKeys))
+	for _, k := range deps.SelfMetadataKeys {
+		selfMetadata[k] = true
+	}
 	return &service{
 		repo:            deps.UserRepo,
 		sessionRepo:     deps.SessionRepo,
 		deviceRepo:      deps.DeviceRepo,
 		jwtProvider:     deps.JWTProvider,
 		refreshTokenDur: deps.RefreshTokenDur,
+		untrustedDur:    deps.UntrustedDur,
+		activity:        deps.Activity,
+		roleRepo:        deps.RoleRepo,
+		audit:           deps.Audit,
+		antiEnumeration: deps.AntiEnumeration,
+		preRegister:     deps.PreRegister,
+		postRegister:    deps.PostRegister,
+		mailer:          deps.Mailer,
+		selfMetadata:    selfMetadata,
+		minAge:          deps.MinimumAge,
+		passwordPolicy:  deps.PasswordPolicy,
+		trustedDevices:  deps.TrustedDeviceRequired,
 	}
 }
 
 func (s *service) Register(ctx context.Context, req domain.CreateUserRequest) (*domain.User, error) {
-	if _, err := s.repo.GetByUsername(ctx, req.Username); err == nil {
-		return nil, fmt.Errorf("username already taken: %w", domain.ErrConflict)
-	}
-	if _, err := s.repo.GetByEmail(ctx, req.Email); err == nil {
-		return nil, fmt.Errorf("email already registered: %w", domain.ErrConflict)
-	}
-	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, err
-	}
 	var birthday time.Time
 	if req.Birthday != "" {
+		var err error
 		birthday, err = time.Parse("2006-01-02", req.Birthday)
 		if err != nil {
 			return nil, fmt.Errorf("birthday must be in YYYY-MM-DD format: %w", domain.ErrBadRequest)
 		}
 	}
+	if err := s.runPreRegister(ctx, &req); err != nil {
+		return nil, err
+	}
+	// Hash before the uniqueness checks so a conflict takes as long as a
+	// successful registration and response times do not reveal taken emails.
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByUsername(ctx, req.Username); err == nil {
+		return nil, s.registrationConflict("username already taken")
+	}
+	if _, err := s.repo.GetByEmail(ctx, req.Email); err == nil {
+		return nil, s.registrationConflict("email already registered")
+	}
 	now := time.Now().UTC()
 	u := &domain.User{
 		UserID:       id.New(),
@@ -110,6 +238,7 @@ func (s *service) Register(ctx context.Context, req domain.CreateUserRequest) (*
 		Email:        req.Email,
 		Phone:        req.Phone,
 		PasswordHash: string(hash),
+		InviteCode:   req.InviteCode,
 		FirstName:    req.FirstName,
 		LastName:     req.LastName,
 		Birthday:     birthday,
@@ -121,10 +250,46 @@ func (s *service) Register(ctx context.Context, req domain.CreateUserRequest) (*
 	if err := s.repo.Put(ctx, u); err != nil {
 		return nil, err
 	}
+	s.runPostRegister(ctx, u)
 	return u, nil
 }
 
+// checkAge refuses self-service registrations below the minimum age, and
+// without a birthday while there is one. The bootstrap admin and dev console
+// users, created with Register, are exempt.
+func (s *service) checkAge(birthday string) error {
+	if s.minAge == 0 {
+		return nil
+	}
+	if birthday == "" {
+		return fmt.Errorf("birthday is required: %w", domain.ErrBadRequest)
+	}
+	born, err := time.Parse("2006-01-02", birthday)
+	if err != nil {
+		return fmt.Errorf("birthday must be in YYYY-MM-DD format: %w", domain.ErrBadRequest)
+	}
+	if domain.AgeOn(born, time.Now().UTC()) < s.minAge {
+		return fmt.Errorf("you must be at least %d years old to register: %w", s.minAge, domain.ErrUnderage)
+	}
+	return nil
+}
+
+// registrationConflict returns ErrConflict with msg, or with a message that
+// does not name the taken field in anti-enumeration mode.
+func (s *service) registrationConflict(msg string) error {
+	if s.antiEnumeration {
+		msg = "username or email is not available"
+	}
+	return fmt.Errorf("%s: %w", msg, domain.ErrConflict)
+}
+
 func (s *service) RegisterWithSession(ctx context.Context, req domain.CreateUserRequest) (*domain.Session, string, string, error) {
+	if err := s.checkAge(req.Birthday); err != nil {
+		return nil, "", "", err
+	}
+	if err := s.passwordPolicy.CheckNew(ctx, req.Password); err != nil {
+		return nil, "", "", err
+	}
 	u, err := s.Register(ctx, req)
 	if err != nil {
 		return nil, "", "", err
@@ -144,14 +309,14 @@ func (s *service) RegisterWithSession(ctx context.Context, req domain.CreateUser
 		DeviceID:         dev.DeviceID,
 		Enable:           true,
 		RefreshToken:     refreshToken,
-		RefreshExpiresAt: now.Add(s.refreshTokenDur).Unix(),
+		RefreshExpiresAt: now.Add(pkgdevice.RefreshLifetime(dev, s.refreshTokenDur, s.untrustedDur)).Unix(),
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
 	if err := s.sessionRepo.Put(ctx, sess); err != nil {
 		return nil, "", "", err
 	}
-	bearer, err := s.jwtProvider.Sign(u.UserID, dev.DeviceID, u.Role, sess.SessionID)
+	bearer, err := s.jwtProvider.Sign(ctx, u.UserID, dev.DeviceID, u.Role, sess.SessionID)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -159,18 +324,100 @@ func (s *service) RegisterWithSession(ctx context.Context, req domain.CreateUser
 	return sess, bearer, refreshToken, nil
 }
 
-func (s *service) List(ctx context.Context, limit int, cursor string) ([]domain.User, string, error) {
+func (s *service) List(ctx context.Context, f domain.UserFilter, limit int, cursor string) ([]domain.User, string, error) {
+	if err := checkFilter(f); err != nil {
+		return nil, "", err
+	}
 	if limit < 1 {
 		limit = 50
 	}
-	return s.repo.QueryPage(ctx, int32(limit), cursor)
+	return s.repo.QueryPage(ctx, f, int32(limit), cursor)
+}
+
+func (s *service) ApproxTotal(ctx context.Context) (int64, error) {
+	if _, ok := tenant.FromContext(ctx); ok {
+		return 0, domain.ErrTableWideCount
+	}
+	return s.repo.ApproxCount(ctx)
+}
+
+func (s *service) Export(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error {
+	if err := checkFilter(f); err != nil {
+		return err
+	}
+	return s.repo.EachMatching(ctx, f, fn)
+}
+
+func checkFilter(f domain.UserFilter) error {
+	if !isDay(f.CreatedFrom) || !isDay(f.CreatedTo) {
+		return fmt.Errorf("from and to must be in YYYY-MM-DD format: %w", domain.ErrBadRequest)
+	}
+	if f.Enable != nil && *f.Enable != 0 && *f.Enable != 1 {
+		return fmt.Errorf("enable must be 0 or 1: %w", domain.ErrBadRequest)
+	}
+	return nil
+}
+
+// isDay reports whether s is empty or a YYYY-MM-DD date.
+func isDay(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return s == "" || err == nil
 }
 
 func (s *service) Get(ctx context.Context, userID string) (*domain.User, error) {
 	return s.repo.Get(ctx, userID)
 }
 
+func (s *service) GetPublic(ctx context.Context, username string) (*domain.User, error) {
+	u, err := s.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	// Hidden profiles are indistinguishable from missing ones.
+	if u.Enable != 1 || u.DeletedAt != nil || !u.PublicProfile {
+		return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
+	}
+	return u, nil
+}
+
 func (s *service) Update(ctx context.Context, userID string, req domain.UpdateUserRequest) (*domain.User, error) {
+	updates, err := profileUpdates(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Metadata != nil {
+		if updates[fieldMetadata], err = s.mergeMetadata(ctx, userID, req.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	demoting := (req.Role != nil && *req.Role != domain.RoleAdmin) || (req.Enable != nil && *req.Enable == 0)
+	if demoting {
+		current, err := s.repo.Get(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.ensureNotLastAdmin(ctx, current); err != nil {
+			return nil, err
+		}
+	}
+	if len(updates) == 0 {
+		return s.repo.Get(ctx, userID)
+	}
+	if err := s.checkIdentityFree(ctx, userID, req); err != nil {
+		return nil, err
+	}
+	changed := changedFields(updates) // before Update, which adds bookkeeping fields to the map
+	if err := s.repo.Update(ctx, userID, updates); err != nil {
+		return nil, err
+	}
+	if s.activity != nil {
+		s.activity.Record(ctx, userID, domain.ActivityProfileUpdate, changed)
+	}
+	return s.repo.Get(ctx, userID)
+}
+
+// profileUpdates maps the plain fields of req to their attributes.
+func profileUpdates(req domain.UpdateUserRequest) (map[string]interface{}, error) {
 	updates := map[string]interface{}{}
 	if req.Username != nil {
 		updates[fieldUsername] = *req.Username
@@ -208,16 +455,60 @@ func (s *service) Update(ctx context.Context, userID string, req domain.UpdateUs
 		}
 		updates[fieldEnable] = *req.Enable
 	}
-	if len(updates) == 0 {
-		return s.repo.Get(ctx, userID)
+	preferenceUpdates(req, updates)
+	return updates, nil
+}
+
+// preferenceUpdates adds the user's preferences in req to updates.
+func preferenceUpdates(req domain.UpdateUserRequest, updates map[string]interface{}) {
+	if req.PublicProfile != nil {
+		updates[fieldPublicProfile] = *req.PublicProfile
 	}
-	if err := s.repo.Update(ctx, userID, updates); err != nil {
-		return nil, err
+	if req.OnboardOptOut != nil {
+		updates[fieldOnboardOptOut] = *req.OnboardOptOut
+	}
+	if req.Timezone != nil {
+		updates[fieldTimezone] = *req.Timezone
+	}
+	if req.Locale != nil {
+		updates[fieldLocale] = *req.Locale
 	}
-	return s.repo.Get(ctx, userID)
+}
+
+// checkIdentityFree returns ErrConflict if the requested username or email
+// already belongs to another user. Lookups are case-insensitive.
+func (s *service) checkIdentityFree(ctx context.Context, userID string, req domain.UpdateUserRequest) error {
+	if req.Username != nil {
+		if u, err := s.repo.GetByUsername(ctx, *req.Username); err == nil && u.UserID != userID {
+			return fmt.Errorf("username already taken: %w", domain.ErrConflict)
+		}
+	}
+	if req.Email != nil {
+		if u, err := s.repo.GetByEmail(ctx, *req.Email); err == nil && u.UserID != userID {
+			return fmt.Errorf("email already registered: %w", domain.ErrConflict)
+		}
+	}
+	return nil
+}
+
+// changedFields returns the sorted, comma-separated keys of updates.
+func changedFields(updates map[string]interface{}) string {
+	fields := make([]string, 0, len(updates))
+	for k := range updates {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
 }
 
 func (s *service) Delete(ctx context.Context, userID string) error {
+	u, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureNotLastAdmin(ctx, u); err != nil {
+		return err
+	}
 	if err := s.repo.SoftDelete(ctx, userID); err != nil {
 		return err
 	}
@@ -232,6 +523,9 @@ func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, n
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(currentPassword)); err != nil {
 		return fmt.Errorf("current password is incorrect: %w", domain.ErrUnauthorized)
 	}
+	if err := s.passwordPolicy.CheckNew(ctx, newPassword); err != nil {
+		return err
+	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -242,3 +536,267 @@ func (s *service) ChangePassword(ctx context.Context, userID, currentPassword, n
 	// Invalidate all sessions so other devices are logged out after a password change.
 	return s.sessionRepo.SoftDeleteByUser(ctx, userID)
 }
+
+func (s *service) RequireTrustedDevice(ctx context.Context, deviceID string) error {
+	if !s.trustedDevices {
+		return nil
+	}
+	d, err := s.deviceRepo.Get(ctx, deviceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrUntrustedDevice
+		}
+		return err
+	}
+	if !d.Trusted {
+		return domain.ErrUntrustedDevice
+	}
+	return nil
+}
+
+func (s *service) ChangeRole(ctx context.Context, actorID, targetID string, req domain.ChangeRoleRequest) (*domain.User, error) {
+	if _, err := s.roleRepo.Get(ctx, req.Role); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("unknown role %q: %w", req.Role, domain.ErrBadRequest)
+		}
+		return nil, err
+	}
+	u, err := s.repo.Get(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Role == req.Role {
+		return u, nil
+	}
+	if err := s.ensureNotLastAdmin(ctx, u); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, targetID, map[string]interface{}{fieldRole: req.Role}); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Action:   domain.AuditUserRoleChange,
+		ActorID:  actorID,
+		TargetID: targetID,
+		Details:  map[string]string{"from": u.Role, "to": req.Role},
+	})
+	if req.RevokeSessions {
+		if err := s.sessionRepo.SoftDeleteByUser(ctx, targetID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.Get(ctx, targetID)
+}
+
+// ensureNotLastAdmin returns ErrConflict if u is an enabled admin about to be
+// demoted, disabled or deleted and no other enabled admin exists.
+func (s *service) ensureNotLastAdmin(ctx context.Context, u *domain.User) error {
+	if u.Role != domain.RoleAdmin || u.Enable != 1 {
+		return nil
+	}
+	n, err := s.repo.CountByRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return fmt.Errorf("cannot remove the last admin: %w", domain.ErrConflict)
+	}
+	return nil
+}
+
+func (s *service) EnsureAdmin(ctx context.Context, email, password string) error {
+	n, err := s.repo.CountByRole(ctx, domain.RoleAdmin)
+	if err != nil || n > 0 {
+		return err
+	}
+	if email == "" {
+		slog.Warn("no admin account exists; set ADMIN_EMAIL to create one on startup")
+		return nil
+	}
+	u, err := s.repo.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		slog.Info("promoting existing account to admin", "email", email)
+	case errors.Is(err, domain.ErrNotFound):
+		if u, err = s.createAdmin(ctx, email, password); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+	return s.repo.Update(ctx, u.UserID, map[string]interface{}{fieldRole: domain.RoleAdmin, fieldEnable: 1})
+}
+
+// createAdmin registers the bootstrap admin account, using email as the username.
+func (s *service) createAdmin(ctx context.Context, email, password string) (*domain.User, error) {
+	generated := password == ""
+	if generated {
+		var err error
+		if password, err = pkgtoken.NewRefreshToken(); err != nil {
+			return nil, err
+		}
+	} else if len(password) > 72 {
+		return nil, fmt.Errorf("ADMIN_PASSWORD must be at most 72 bytes: %w", domain.ErrBadRequest)
+	} else if err := s.passwordPolicy.CheckNew(ctx, password); err != nil {
+		return nil, fmt.Errorf("ADMIN_PASSWORD: %w", err)
+	}
+	u, err := s.Register(ctx, domain.CreateUserRequest{
+		Username:  email,
+		Password:  password,
+		Email:     email,
+		FirstName: "Admin",
+		LastName:  "Admin",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create admin %s: %w", email, err)
+	}
+	if generated {
+		slog.Warn("created admin account without a password; set one via POST /v1/password-recovery/request", "email", email)
+	} else {
+		slog.Info("created admin account", "email", email)
+	}
+	return u, nil
+}
+
+// bulkConcurrency bounds how many users a bulk action updates at once.
+const bulkConcurrency = 8
+
+func (s *service) Bulk(ctx context.Context, actorID string, req domain.BulkUserRequest) ([]domain.BulkUserResult, error) {
+	if req.Action == domain.BulkSetRole {
+		if req.Role == "" {
+			return nil, fmt.Errorf("role is required for set_role: %w", domain.ErrBadRequest)
+		}
+		if _, err := s.roleRepo.Get(ctx, req.Role); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("unknown role %q: %w", req.Role, domain.ErrBadRequest)
+			}
+			return nil, err
+		}
+	}
+	ids := distinct(req.UserIDs)
+	results := make([]domain.BulkUserResult, len(ids))
+	run := &bulkRun{svc: s, actorID: actorID, req: req, adminsLeft: -1}
+	sem := make(chan struct{}, bulkConcurrency)
+	var wg sync.WaitGroup
+	for i, userID := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			results[i] = domain.BulkUserResult{UserID: userID, OK: true}
+			if err := run.apply(ctx, userID); err != nil {
+				results[i] = domain.BulkUserResult{UserID: userID, Error: err.Error()}
+			}
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// distinct returns ids without duplicates, keeping first occurrences in order.
+func distinct(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, userID := range ids {
+		if !seen[userID] {
+			seen[userID] = true
+			out = append(out, userID)
+		}
+	}
+	return out
+}
+
+// bulkRun is the state shared by the workers of one Bulk call.
+type bulkRun struct {
+	svc     *service
+	actorID string
+	req     domain.BulkUserRequest
+
+	// adminMu serialises changes that remove an enabled admin. adminsLeft is
+	// counted once (-1 until then) and tracked locally afterwards, because
+	// CountByRole would not yet see the batch's own earlier changes.
+	adminMu    sync.Mutex
+	adminsLeft int
+}
+
+func (b *bulkRun) apply(ctx context.Context, userID string) error {
+	if userID == b.actorID {
+		return fmt.Errorf("cannot apply a bulk action to yourself: %w", domain.ErrBadRequest)
+	}
+	u, err := b.svc.repo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !b.removesAdmin(u) {
+		return b.svc.applyBulk(ctx, b.actorID, u, b.req)
+	}
+	b.adminMu.Lock()
+	defer b.adminMu.Unlock()
+	if b.adminsLeft < 0 {
+		if b.adminsLeft, err = b.svc.repo.CountByRole(ctx, domain.RoleAdmin); err != nil {
+			b.adminsLeft = -1
+			return err
+		}
+	}
+	if b.adminsLeft <= 1 {
+		return fmt.Errorf("cannot remove the last admin: %w", domain.ErrConflict)
+	}
+	if err := b.svc.applyBulk(ctx, b.actorID, u, b.req); err != nil {
+		return err
+	}
+	b.adminsLeft--
+	return nil
+}
+
+// removesAdmin reports whether the action takes u out of the enabled admins.
+func (b *bulkRun) removesAdmin(u *domain.User) bool {
+	if u.Role != domain.RoleAdmin || u.Enable != 1 {
+		return false
+	}
+	switch b.req.Action {
+	case domain.BulkDisable, domain.BulkDelete:
+		return true
+	case domain.BulkSetRole:
+		return b.req.Role != domain.RoleAdmin
+	}
+	return false
+}
+
+// applyBulk performs one bulk action on u and audits it. Disabling and
+// deleting also revoke the user's sessions. No-op changes are not audited.
+func (s *service) applyBulk(ctx context.Context, actorID string, u *domain.User, req domain.BulkUserRequest) error {
+	entry := domain.AuditEntry{ActorID: actorID, TargetID: u.UserID, Details: map[string]string{"bulk": "true"}}
+	var err error
+	switch req.Action {
+	case domain.BulkDisable, domain.BulkEnable:
+		enable, action := 0, domain.AuditUserDisable
+		if req.Action == domain.BulkEnable {
+			enable, action = 1, domain.AuditUserEnable
+		}
+		if u.Enable == enable {
+			return nil
+		}
+		entry.Action = action
+		err = s.repo.Update(ctx, u.UserID, map[string]interface{}{fieldEnable: enable})
+	case domain.BulkDelete:
+		entry.Action = domain.AuditUserDelete
+		err = s.repo.SoftDelete(ctx, u.UserID)
+	case domain.BulkSetRole:
+		if u.Role == req.Role {
+			return nil
+		}
+		entry.Action = domain.AuditUserRoleChange
+		entry.Details["from"], entry.Details["to"] = u.Role, req.Role
+		err = s.repo.Update(ctx, u.UserID, map[string]interface{}{fieldRole: req.Role})
+	default:
+		return fmt.Errorf("unknown action %q: %w", req.Action, domain.ErrBadRequest)
+	}
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, entry)
+	if req.Action == domain.BulkDisable || req.Action == domain.BulkDelete {
+		return s.sessionRepo.SoftDeleteByUser(ctx, u.UserID)
+	}
+	return nil
+}
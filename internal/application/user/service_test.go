@@ -3,9 +3,14 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/password"
+	"github.com/go-api-nosql/internal/pkg/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -33,10 +38,14 @@ func (m *mockUserStore) GetByEmail(ctx context.Context, email string) (*domain.U
 func (m *mockUserStore) Put(ctx context.Context, u *domain.User) error {
 	return m.Called(ctx, u).Error(0)
 }
-func (m *mockUserStore) QueryPage(ctx context.Context, limit int32, cursor string) ([]domain.User, string, error) {
-	args := m.Called(ctx, limit, cursor)
+func (m *mockUserStore) QueryPage(ctx context.Context, f domain.UserFilter, limit int32, cursor string) ([]domain.User, string, error) {
+	args := m.Called(ctx, f, limit, cursor)
 	return args.Get(0).([]domain.User), args.String(1), args.Error(2)
 }
+func (m *mockUserStore) ApproxCount(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
 func (m *mockUserStore) Get(ctx context.Context, userID string) (*domain.User, error) {
 	args := m.Called(ctx, userID)
 	if u, _ := args.Get(0).(*domain.User); u != nil {
@@ -47,6 +56,23 @@ func (m *mockUserStore) Get(ctx context.Context, userID string) (*domain.User, e
 func (m *mockUserStore) Update(ctx context.Context, userID string, updates map[string]interface{}) error {
 	return m.Called(ctx, userID, updates).Error(0)
 }
+func (m *mockUserStore) EachMatching(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error {
+	args := m.Called(ctx, f, fn)
+	if users, _ := args.Get(0).([]domain.User); users != nil {
+		for _, u := range users {
+			if err := fn(u); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockUserStore) CountByRole(ctx context.Context, role string) (int, error) {
+	args := m.Called(ctx, role)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *mockUserStore) SoftDelete(ctx context.Context, userID string) error {
 	return m.Called(ctx, userID).Error(0)
 }
@@ -60,22 +86,46 @@ func (m *mockSessionStore) SoftDeleteByUser(ctx context.Context, userID string)
 	return m.Called(ctx, userID).Error(0)
 }
 
+type mockRoleStore struct{ mock.Mock }
+
+func (m *mockRoleStore) Get(ctx context.Context, name string) (*domain.Role, error) {
+	args := m.Called(ctx, name)
+	if r, _ := args.Get(0).(*domain.Role); r != nil {
+		return r, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockAuditRecorder struct{ mock.Mock }
+
+func (m *mockAuditRecorder) Record(ctx context.Context, e domain.AuditEntry) {
+	m.Called(ctx, e)
+}
+
 type mockDeviceStore struct{ mock.Mock }
 
-func (m *mockDeviceStore) GetByUUID(ctx context.Context, uuid string) (*domain.Device, error) {
-	args := m.Called(ctx, uuid)
+func (m *mockDeviceStore) GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error) {
+	args := m.Called(ctx, uuid, userID)
+	if d, _ := args.Get(0).(*domain.Device); d != nil {
+		return d, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockDeviceStore) Get(ctx context.Context, deviceID string) (*domain.Device, error) {
+	args := m.Called(ctx, deviceID)
 	if d, _ := args.Get(0).(*domain.Device); d != nil {
 		return d, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
+
 func (m *mockDeviceStore) Put(ctx context.Context, d *domain.Device) error {
 	return m.Called(ctx, d).Error(0)
 }
 
 type mockJWTSigner struct{ mock.Mock }
 
-func (m *mockJWTSigner) Sign(userID, deviceID, role, sessionID string) (string, error) {
+func (m *mockJWTSigner) Sign(_ context.Context, userID, deviceID, role, sessionID string) (string, error) {
 	args := m.Called(userID, deviceID, role, sessionID)
 	return args.String(0), args.Error(1)
 }
@@ -128,6 +178,18 @@ func TestRegister_EmailConflict(t *testing.T) {
 	us.AssertExpectations(t)
 }
 
+func TestRegister_AntiEnumerationHidesWhichFieldIsTaken(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("GetByUsername", mock.Anything, "alice").Return(nil, domain.ErrNotFound)
+	us.On("GetByEmail", mock.Anything, "alice@example.com").Return(&domain.User{}, nil)
+
+	svc := NewService(ServiceDeps{UserRepo: us, AntiEnumeration: true})
+	_, err := svc.Register(context.Background(), baseReq())
+
+	require.ErrorIs(t, err, domain.ErrConflict)
+	assert.NotContains(t, err.Error(), "email already registered")
+}
+
 func TestRegister_InvalidBirthday(t *testing.T) {
 	us := &mockUserStore{}
 	us.On("GetByUsername", mock.Anything, "alice").Return(nil, domain.ErrNotFound)
@@ -142,6 +204,30 @@ func TestRegister_InvalidBirthday(t *testing.T) {
 	assert.True(t, errors.Is(err, domain.ErrBadRequest))
 }
 
+func TestRegister_MinimumAge(t *testing.T) {
+	svc := NewService(ServiceDeps{UserRepo: &mockUserStore{}, MinimumAge: 13})
+	req := baseReq()
+
+	req.Birthday = time.Now().UTC().AddDate(-12, 0, 0).Format("2006-01-02")
+	_, _, _, err := svc.RegisterWithSession(context.Background(), req)
+	assert.ErrorIs(t, err, domain.ErrUnderage)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	req.Birthday = ""
+	_, _, _, err = svc.RegisterWithSession(context.Background(), req)
+	assert.ErrorIs(t, err, domain.ErrBadRequest, "the birthday is required")
+}
+
+func TestRegister_PasswordPolicy(t *testing.T) {
+	svc := NewService(ServiceDeps{UserRepo: &mockUserStore{}, PasswordPolicy: password.Policy{MinLength: 12, RequireDigit: true}})
+	req := baseReq()
+	req.Password = "longbutnodigits"
+
+	_, _, _, err := svc.RegisterWithSession(context.Background(), req)
+	assert.ErrorIs(t, err, domain.ErrWeakPassword)
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
 func TestRegister_HappyPath(t *testing.T) {
 	us := &mockUserStore{}
 	us.On("GetByUsername", mock.Anything, "alice").Return(nil, domain.ErrNotFound)
@@ -196,6 +282,7 @@ func TestUpdate_InvalidRole(t *testing.T) {
 func TestUpdate_HappyPath(t *testing.T) {
 	us := &mockUserStore{}
 	updated := &domain.User{UserID: "u1", Username: "bob"}
+	us.On("GetByUsername", mock.Anything, "bob").Return(nil, domain.ErrNotFound)
 	us.On("Update", mock.Anything, "u1", mock.Anything).Return(nil)
 	us.On("Get", mock.Anything, "u1").Return(updated, nil)
 
@@ -209,11 +296,48 @@ func TestUpdate_HappyPath(t *testing.T) {
 	us.AssertExpectations(t)
 }
 
+func TestUpdate_MergesMetadata(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Metadata: map[string]string{"tier": "gold", "team": "a"}}, nil)
+	us.On("Update", mock.Anything, "u1", map[string]interface{}{
+		fieldMetadata: map[string]string{"tier": "gold", "locale_hint": "pt"},
+	}).Return(nil)
+
+	svc := newService(us, nil, nil, nil)
+	_, err := svc.Update(context.Background(), "u1", domain.UpdateUserRequest{
+		Metadata: map[string]*string{"team": nil, "locale_hint": ptr("pt")},
+	})
+
+	require.NoError(t, err)
+	us.AssertExpectations(t)
+}
+
+func TestUpdate_InvalidMetadataKey(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1"}, nil)
+
+	svc := newService(us, nil, nil, nil)
+	_, err := svc.Update(context.Background(), "u1", domain.UpdateUserRequest{
+		Metadata: map[string]*string{"Bad-Key": ptr("x")},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	us.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCheckSelfMetadata(t *testing.T) {
+	svc := NewService(ServiceDeps{SelfMetadataKeys: []string{"nickname"}})
+
+	assert.NoError(t, svc.CheckSelfMetadata(map[string]*string{"nickname": ptr("al")}))
+	assert.ErrorIs(t, svc.CheckSelfMetadata(map[string]*string{"nickname": nil, "tier": nil}), domain.ErrForbidden)
+}
+
 // --- Delete tests ---
 
 func TestDelete_PropagatesStoreError(t *testing.T) {
 	us := &mockUserStore{}
 	storeErr := errors.New("dynamo error")
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Role: domain.RoleUser, Enable: 1}, nil)
 	us.On("SoftDelete", mock.Anything, "u1").Return(storeErr)
 
 	svc := newService(us, &mockSessionStore{}, nil, nil)
@@ -227,6 +351,7 @@ func TestDelete_PropagatesStoreError(t *testing.T) {
 func TestDelete_AlsoDeletesSessions(t *testing.T) {
 	us := &mockUserStore{}
 	ss := &mockSessionStore{}
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Role: domain.RoleUser, Enable: 1}, nil)
 	us.On("SoftDelete", mock.Anything, "u1").Return(nil)
 	ss.On("SoftDeleteByUser", mock.Anything, "u1").Return(nil)
 
@@ -238,6 +363,17 @@ func TestDelete_AlsoDeletesSessions(t *testing.T) {
 	ss.AssertExpectations(t)
 }
 
+func TestDelete_LastAdminIsRejected(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("Get", mock.Anything, "admin1").Return(&domain.User{UserID: "admin1", Role: domain.RoleAdmin, Enable: 1}, nil)
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(1, nil)
+
+	err := newService(us, &mockSessionStore{}, nil, nil).Delete(context.Background(), "admin1")
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	us.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything)
+}
+
 // --- ChangePassword tests ---
 
 func TestChangePassword_UserNotFound(t *testing.T) {
@@ -266,6 +402,66 @@ func TestChangePassword_WrongCurrentPassword(t *testing.T) {
 	us.AssertExpectations(t)
 }
 
+func TestChangePassword_WeakPasswordIsRejected(t *testing.T) {
+	us := &mockUserStore{}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("currentpassword"), bcrypt.MinCost)
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", PasswordHash: string(hash)}, nil)
+
+	svc := NewService(ServiceDeps{UserRepo: us, PasswordPolicy: password.Policy{MinLength: 8, BanCommon: true}})
+	err := svc.ChangePassword(context.Background(), "u1", "currentpassword", "password123")
+
+	assert.ErrorIs(t, err, domain.ErrWeakPassword)
+	us.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// stubBreaches reports the passwords in breached as breached, or fails with err.
+type stubBreaches struct {
+	breached map[string]bool
+	err      error
+}
+
+func (b stubBreaches) Breached(_ context.Context, pw string) (bool, error) {
+	return b.breached[pw], b.err
+}
+
+func TestRegister_BreachedPasswordIsRejected(t *testing.T) {
+	breaches := stubBreaches{breached: map[string]bool{"password123": true}}
+	svc := NewService(ServiceDeps{UserRepo: &mockUserStore{}, PasswordPolicy: password.Policy{Breaches: breaches}})
+
+	_, _, _, err := svc.RegisterWithSession(context.Background(), baseReq())
+	assert.ErrorIs(t, err, domain.ErrBreachedPassword)
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestChangePassword_BreachedPasswordIsRejected(t *testing.T) {
+	us := &mockUserStore{}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("currentpassword"), bcrypt.MinCost)
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", PasswordHash: string(hash)}, nil)
+
+	breaches := stubBreaches{breached: map[string]bool{"newpassword123": true}}
+	svc := NewService(ServiceDeps{UserRepo: us, PasswordPolicy: password.Policy{Breaches: breaches}})
+	err := svc.ChangePassword(context.Background(), "u1", "currentpassword", "newpassword123")
+
+	assert.ErrorIs(t, err, domain.ErrBreachedPassword)
+	us.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_BreachCheckFailsOpen(t *testing.T) {
+	us := &mockUserStore{}
+	ss := &mockSessionStore{}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("currentpassword"), bcrypt.MinCost)
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", PasswordHash: string(hash)}, nil)
+	us.On("Update", mock.Anything, "u1", mock.Anything).Return(nil)
+	ss.On("SoftDeleteByUser", mock.Anything, "u1").Return(nil)
+
+	breaches := stubBreaches{err: errors.New("hibp: status 503")}
+	svc := NewService(ServiceDeps{UserRepo: us, SessionRepo: ss, PasswordPolicy: password.Policy{Breaches: breaches}})
+	err := svc.ChangePassword(context.Background(), "u1", "currentpassword", "newpassword123")
+
+	require.NoError(t, err)
+	us.AssertExpectations(t)
+}
+
 func TestChangePassword_RepoUpdateError(t *testing.T) {
 	us := &mockUserStore{}
 	hash, _ := bcrypt.GenerateFromPassword([]byte("currentpassword"), bcrypt.MinCost)
@@ -314,3 +510,446 @@ func TestChangePassword_HappyPath(t *testing.T) {
 	us.AssertExpectations(t)
 	ss.AssertExpectations(t)
 }
+
+// --- RequireTrustedDevice tests ---
+
+func TestRequireTrustedDevice(t *testing.T) {
+	ds := &mockDeviceStore{}
+	ds.On("Get", mock.Anything, "trusted").Return(&domain.Device{DeviceID: "trusted", Trusted: true}, nil)
+	ds.On("Get", mock.Anything, "untrusted").Return(&domain.Device{DeviceID: "untrusted"}, nil)
+	ds.On("Get", mock.Anything, "missing").Return(nil, domain.ErrNotFound)
+
+	svc := NewService(ServiceDeps{DeviceRepo: ds, TrustedDeviceRequired: true})
+
+	assert.NoError(t, svc.RequireTrustedDevice(context.Background(), "trusted"))
+	assert.ErrorIs(t, svc.RequireTrustedDevice(context.Background(), "untrusted"), domain.ErrUntrustedDevice)
+	assert.ErrorIs(t, svc.RequireTrustedDevice(context.Background(), "missing"), domain.ErrForbidden)
+}
+
+func TestRequireTrustedDevice_OffLetsEveryDeviceThrough(t *testing.T) {
+	ds := &mockDeviceStore{}
+	svc := newService(nil, nil, ds, nil)
+
+	assert.NoError(t, svc.RequireTrustedDevice(context.Background(), "untrusted"))
+	ds.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+// --- ChangeRole tests ---
+
+func newRoleService(us *mockUserStore, ss *mockSessionStore, rs *mockRoleStore, au *mockAuditRecorder) Service {
+	return NewService(ServiceDeps{UserRepo: us, SessionRepo: ss, RoleRepo: rs, Audit: au})
+}
+
+func TestChangeRole_UnknownRoleIsBadRequest(t *testing.T) {
+	rs := &mockRoleStore{}
+	rs.On("Get", mock.Anything, "Ghost").Return(nil, domain.ErrNotFound)
+
+	_, err := newRoleService(&mockUserStore{}, nil, rs, nil).
+		ChangeRole(context.Background(), "admin1", "u2", domain.ChangeRoleRequest{Role: "Ghost"})
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestChangeRole_LastAdminCannotBeDemoted(t *testing.T) {
+	us := &mockUserStore{}
+	rs := &mockRoleStore{}
+	rs.On("Get", mock.Anything, domain.RoleUser).Return(&domain.Role{Name: domain.RoleUser}, nil)
+	us.On("Get", mock.Anything, "admin1").Return(&domain.User{UserID: "admin1", Role: domain.RoleAdmin, Enable: 1}, nil)
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(1, nil)
+
+	_, err := newRoleService(us, nil, rs, nil).
+		ChangeRole(context.Background(), "admin1", "admin1", domain.ChangeRoleRequest{Role: domain.RoleUser})
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	us.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangeRole_AuditsAndRevokesSessions(t *testing.T) {
+	us := &mockUserStore{}
+	ss := &mockSessionStore{}
+	rs := &mockRoleStore{}
+	au := &mockAuditRecorder{}
+	rs.On("Get", mock.Anything, "Editor").Return(&domain.Role{Name: "Editor"}, nil)
+	us.On("Get", mock.Anything, "u2").Return(&domain.User{UserID: "u2", Role: domain.RoleUser}, nil)
+	us.On("Update", mock.Anything, "u2", map[string]interface{}{fieldRole: "Editor"}).Return(nil)
+	au.On("Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditUserRoleChange && e.ActorID == "admin1" && e.TargetID == "u2" &&
+			e.Details["from"] == domain.RoleUser && e.Details["to"] == "Editor"
+	})).Return()
+	ss.On("SoftDeleteByUser", mock.Anything, "u2").Return(nil)
+
+	_, err := newRoleService(us, ss, rs, au).ChangeRole(context.Background(), "admin1", "u2",
+		domain.ChangeRoleRequest{Role: "Editor", RevokeSessions: true})
+
+	require.NoError(t, err)
+	au.AssertExpectations(t)
+	ss.AssertExpectations(t)
+}
+
+func TestUpdate_LastAdminCannotBeDemoted(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("Get", mock.Anything, "admin1").Return(&domain.User{UserID: "admin1", Role: domain.RoleAdmin, Enable: 1}, nil)
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(1, nil)
+
+	_, err := newService(us, nil, nil, nil).Update(context.Background(), "admin1", domain.UpdateUserRequest{Role: ptr(domain.RoleUser)})
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	us.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdate_DisablingLastAdminIsRejected(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("Get", mock.Anything, "admin1").Return(&domain.User{UserID: "admin1", Role: domain.RoleAdmin, Enable: 1}, nil)
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(1, nil)
+	disabled := 0
+
+	_, err := newService(us, nil, nil, nil).Update(context.Background(), "admin1", domain.UpdateUserRequest{Enable: &disabled})
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+// --- GetPublic tests ---
+
+func TestGetPublic_ReturnsOptedInUser(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("GetByUsername", mock.Anything, "bob").Return(&domain.User{UserID: "u2", Enable: 1, PublicProfile: true}, nil)
+
+	u, err := newService(us, nil, nil, nil).GetPublic(context.Background(), "bob")
+
+	require.NoError(t, err)
+	assert.Equal(t, "u2", u.UserID)
+}
+
+func TestGetPublic_HidesPrivateDisabledAndDeletedUsers(t *testing.T) {
+	deleted := time.Now()
+	cases := map[string]*domain.User{
+		"private":  {UserID: "u2", Enable: 1},
+		"disabled": {UserID: "u2", Enable: 0, PublicProfile: true},
+		"deleted":  {UserID: "u2", Enable: 1, PublicProfile: true, DeletedAt: &deleted},
+	}
+	for name, stored := range cases {
+		t.Run(name, func(t *testing.T) {
+			us := &mockUserStore{}
+			us.On("GetByUsername", mock.Anything, "bob").Return(stored, nil)
+
+			_, err := newService(us, nil, nil, nil).GetPublic(context.Background(), "bob")
+
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+		})
+	}
+}
+
+// --- Export tests ---
+
+func TestExport_InvalidDateIsBadRequest(t *testing.T) {
+	us := &mockUserStore{}
+
+	err := newService(us, nil, nil, nil).Export(context.Background(), domain.UserFilter{CreatedFrom: "01/02/2024"}, func(domain.User) error { return nil })
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	us.AssertNotCalled(t, "EachMatching", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExport_StreamsMatchingUsers(t *testing.T) {
+	us := &mockUserStore{}
+	f := domain.UserFilter{Role: domain.RoleAdmin, CreatedFrom: "2024-01-01", CreatedTo: "2024-12-31"}
+	us.On("EachMatching", mock.Anything, f, mock.Anything).Return([]domain.User{{UserID: "u1"}, {UserID: "u2"}}, nil)
+
+	var got []string
+	err := newService(us, nil, nil, nil).Export(context.Background(), f, func(u domain.User) error {
+		got = append(got, u.UserID)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, got)
+}
+
+// --- List tests ---
+
+func TestList_InvalidDateIsBadRequest(t *testing.T) {
+	us := &mockUserStore{}
+
+	_, _, err := newService(us, nil, nil, nil).List(context.Background(), domain.UserFilter{CreatedTo: "tomorrow"}, 10, "")
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	us.AssertNotCalled(t, "QueryPage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestList_PassesFilterAndDefaultLimit(t *testing.T) {
+	us := &mockUserStore{}
+	f := domain.UserFilter{Role: domain.RoleAdmin, CreatedFrom: "2024-01-01", Ascending: true}
+	us.On("QueryPage", mock.Anything, f, int32(50), "c1").Return([]domain.User{{UserID: "u1"}}, "c2", nil)
+
+	users, next, err := newService(us, nil, nil, nil).List(context.Background(), f, 0, "c1")
+
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "c2", next)
+}
+
+func TestApproxTotal_WithheldInsideATenant(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("ApproxCount", mock.Anything).Return(int64(1234), nil)
+	svc := newService(us, nil, nil, nil)
+
+	total, err := svc.ApproxTotal(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1234), total)
+
+	_, err = svc.ApproxTotal(tenant.WithID(context.Background(), "acme"))
+	assert.ErrorIs(t, err, domain.ErrTableWideCount)
+	us.AssertNumberOfCalls(t, "ApproxCount", 1)
+}
+
+// --- Bulk tests ---
+
+func TestBulk_DisablesAuditsAndReportsPerUser(t *testing.T) {
+	us := &mockUserStore{}
+	ss := &mockSessionStore{}
+	au := &mockAuditRecorder{}
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Role: domain.RoleUser, Enable: 1}, nil)
+	us.On("Get", mock.Anything, "u2").Return(nil, domain.ErrNotFound)
+	us.On("Update", mock.Anything, "u1", map[string]interface{}{fieldEnable: 0}).Return(nil)
+	ss.On("SoftDeleteByUser", mock.Anything, "u1").Return(nil)
+	au.On("Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditUserDisable && e.ActorID == "admin1" && e.TargetID == "u1" && e.Details["bulk"] == "true"
+	})).Return()
+
+	results, err := newRoleService(us, ss, nil, au).Bulk(context.Background(), "admin1",
+		domain.BulkUserRequest{Action: domain.BulkDisable, UserIDs: []string{"u1", "u2", "u1", "admin1"}})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, domain.BulkUserResult{UserID: "u1", OK: true}, results[0])
+	assert.Equal(t, "u2", results[1].UserID)
+	assert.False(t, results[1].OK)
+	assert.Contains(t, results[2].Error, "yourself")
+	au.AssertNumberOfCalls(t, "Record", 1)
+	ss.AssertExpectations(t)
+}
+
+func TestBulk_KeepsOneAdminWithinBatch(t *testing.T) {
+	us := &mockUserStore{}
+	ss := &mockSessionStore{}
+	au := &mockAuditRecorder{}
+	for _, userID := range []string{"a1", "a2", "a3"} {
+		us.On("Get", mock.Anything, userID).Return(&domain.User{UserID: userID, Role: domain.RoleAdmin, Enable: 1}, nil)
+		us.On("SoftDelete", mock.Anything, userID).Return(nil).Maybe()
+		ss.On("SoftDeleteByUser", mock.Anything, userID).Return(nil).Maybe()
+	}
+	// The index still reports every admin while the batch runs.
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(3, nil)
+	au.On("Record", mock.Anything, mock.Anything).Return()
+
+	results, err := newRoleService(us, ss, nil, au).Bulk(context.Background(), "root",
+		domain.BulkUserRequest{Action: domain.BulkDelete, UserIDs: []string{"a1", "a2", "a3"}})
+
+	require.NoError(t, err)
+	failed := 0
+	for _, r := range results {
+		if !r.OK {
+			failed++
+			assert.Contains(t, r.Error, "last admin")
+		}
+	}
+	assert.Equal(t, 1, failed)
+	us.AssertNumberOfCalls(t, "CountByRole", 1)
+}
+
+func TestBulk_SetRoleRequiresKnownRole(t *testing.T) {
+	rs := &mockRoleStore{}
+	rs.On("Get", mock.Anything, "Ghost").Return(nil, domain.ErrNotFound)
+
+	_, err := newRoleService(&mockUserStore{}, nil, rs, nil).Bulk(context.Background(), "admin1",
+		domain.BulkUserRequest{Action: domain.BulkSetRole, Role: "Ghost", UserIDs: []string{"u1"}})
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestBulk_SetRoleAuditsChange(t *testing.T) {
+	us := &mockUserStore{}
+	rs := &mockRoleStore{}
+	au := &mockAuditRecorder{}
+	rs.On("Get", mock.Anything, "Editor").Return(&domain.Role{Name: "Editor"}, nil)
+	us.On("Get", mock.Anything, "u1").Return(&domain.User{UserID: "u1", Role: domain.RoleUser, Enable: 1}, nil)
+	us.On("Update", mock.Anything, "u1", map[string]interface{}{fieldRole: "Editor"}).Return(nil)
+	au.On("Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditUserRoleChange && e.Details["from"] == domain.RoleUser && e.Details["to"] == "Editor"
+	})).Return()
+
+	results, err := newRoleService(us, nil, rs, au).Bulk(context.Background(), "admin1",
+		domain.BulkUserRequest{Action: domain.BulkSetRole, Role: "Editor", UserIDs: []string{"u1"}})
+
+	require.NoError(t, err)
+	assert.True(t, results[0].OK)
+	au.AssertExpectations(t)
+}
+
+// --- Provision tests ---
+
+type mockMailer struct{ mock.Mock }
+
+func (m *mockMailer) SendEmail(to, subject, body string) error {
+	return m.Called(to, subject, body).Error(0)
+}
+
+func TestProvision_CreatesAccountAwaitingPasswordAndEmailsIt(t *testing.T) {
+	us, rs, au, ml := &mockUserStore{}, &mockRoleStore{}, &mockAuditRecorder{}, &mockMailer{}
+	rs.On("Get", mock.Anything, domain.RoleUser).Return(&domain.Role{Name: domain.RoleUser}, nil)
+	us.On("GetByUsername", mock.Anything, "alice").Return(nil, domain.ErrNotFound)
+	us.On("GetByEmail", mock.Anything, "alice@example.com").Return(nil, domain.ErrNotFound)
+	us.On("Put", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	au.On("Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditUserProvision && e.ActorID == "admin1"
+	})).Return()
+	var body string
+	ml.On("SendEmail", "alice@example.com", inviteSubject, mock.Anything).
+		Run(func(args mock.Arguments) { body = args.String(2) }).Return(nil)
+	svc := NewService(ServiceDeps{UserRepo: us, RoleRepo: rs, Audit: au, Mailer: ml})
+
+	u, err := svc.Provision(context.Background(), "admin1", domain.ProvisionUserRequest{
+		Username: "alice", Email: "alice@example.com", FirstName: "Alice", LastName: "Smith",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, u.PasswordChange)
+	assert.Equal(t, domain.RoleUser, u.Role)
+	require.Contains(t, body, "Temporary password: ")
+	password := body[strings.Index(body, "Temporary password: ")+len("Temporary password: "):]
+	password = password[:strings.Index(password, "\n")]
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)))
+	au.AssertExpectations(t)
+}
+
+func TestProvision_RefusesAdminRoleAndTakenEmail(t *testing.T) {
+	us, rs := &mockUserStore{}, &mockRoleStore{}
+	rs.On("Get", mock.Anything, domain.RoleUser).Return(&domain.Role{Name: domain.RoleUser}, nil)
+	us.On("GetByUsername", mock.Anything, "alice").Return(nil, domain.ErrNotFound)
+	us.On("GetByEmail", mock.Anything, "alice@example.com").Return(&domain.User{}, nil)
+	svc := NewService(ServiceDeps{UserRepo: us, RoleRepo: rs})
+	req := domain.ProvisionUserRequest{Username: "alice", Email: "alice@example.com", FirstName: "A", LastName: "S"}
+
+	_, err := svc.Provision(context.Background(), "admin1", req)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	req.Role = domain.RoleAdmin
+	_, err = svc.Provision(context.Background(), "admin1", req)
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	us.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+// --- EnsureAdmin tests ---
+
+func TestEnsureAdmin_NoopWhenAdminExists(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(2, nil)
+
+	require.NoError(t, newService(us, nil, nil, nil).EnsureAdmin(context.Background(), "root@example.com", "password123"))
+	us.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+}
+
+func TestEnsureAdmin_PromotesExistingAccount(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(0, nil)
+	us.On("GetByEmail", mock.Anything, "root@example.com").Return(&domain.User{UserID: "u9"}, nil)
+	us.On("Update", mock.Anything, "u9", map[string]interface{}{fieldRole: domain.RoleAdmin, fieldEnable: 1}).Return(nil)
+
+	require.NoError(t, newService(us, nil, nil, nil).EnsureAdmin(context.Background(), "root@example.com", ""))
+	us.AssertExpectations(t)
+}
+
+func TestEnsureAdmin_CreatesAccountWithGeneratedPassword(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(0, nil)
+	us.On("GetByEmail", mock.Anything, "root@example.com").Return(nil, domain.ErrNotFound)
+	us.On("GetByUsername", mock.Anything, "root@example.com").Return(nil, domain.ErrNotFound)
+	us.On("Put", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	us.On("Update", mock.Anything, mock.Anything, map[string]interface{}{fieldRole: domain.RoleAdmin, fieldEnable: 1}).Return(nil)
+
+	require.NoError(t, newService(us, nil, nil, nil).EnsureAdmin(context.Background(), "root@example.com", ""))
+	us.AssertExpectations(t)
+}
+
+func TestEnsureAdmin_ShortPasswordIsRejected(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(0, nil)
+	us.On("GetByEmail", mock.Anything, "root@example.com").Return(nil, domain.ErrNotFound)
+
+	svc := NewService(ServiceDeps{UserRepo: us, PasswordPolicy: password.Policy{MinLength: 8}})
+	err := svc.EnsureAdmin(context.Background(), "root@example.com", "short")
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	us.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestEnsureAdmin_BreachedPasswordIsRejected(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(0, nil)
+	us.On("GetByEmail", mock.Anything, "root@example.com").Return(nil, domain.ErrNotFound)
+
+	breaches := stubBreaches{breached: map[string]bool{"Password1!": true}}
+	svc := NewService(ServiceDeps{UserRepo: us, PasswordPolicy: password.Policy{Breaches: breaches}})
+	err := svc.EnsureAdmin(context.Background(), "root@example.com", "Password1!")
+
+	assert.ErrorIs(t, err, domain.ErrBreachedPassword)
+	us.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestUpdate_EmailTakenByAnotherUserIsConflict(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("GetByEmail", mock.Anything, "Bob@Example.com").Return(&domain.User{UserID: "u2"}, nil)
+
+	_, err := newService(us, nil, nil, nil).Update(context.Background(), "u1", domain.UpdateUserRequest{Email: ptr("Bob@Example.com")})
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	us.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- hook tests ---
+
+type preRegisterFunc func(ctx context.Context, req *domain.CreateUserRequest) error
+
+func (f preRegisterFunc) PreRegister(ctx context.Context, req *domain.CreateUserRequest) error {
+	return f(ctx, req)
+}
+
+type postRegisterFunc func(ctx context.Context, u *domain.User) error
+
+func (f postRegisterFunc) PostRegister(ctx context.Context, u *domain.User) error { return f(ctx, u) }
+
+func TestRegister_PreRegisterHookRejectsBeforeAnyLookup(t *testing.T) {
+	us := &mockUserStore{}
+	svc := NewService(ServiceDeps{
+		UserRepo: us,
+		PreRegister: []PreRegisterHook{preRegisterFunc(func(context.Context, *domain.CreateUserRequest) error {
+			return fmt.Errorf("email domain not allowed: %w", domain.ErrForbidden)
+		})},
+	})
+
+	_, err := svc.Register(context.Background(), baseReq())
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	us.AssertNotCalled(t, "GetByUsername", mock.Anything, mock.Anything)
+	us.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
+
+func TestRegister_PostRegisterHookErrorDoesNotFailRegistration(t *testing.T) {
+	us := &mockUserStore{}
+	us.On("GetByUsername", mock.Anything, "alice").Return(nil, domain.ErrNotFound)
+	us.On("GetByEmail", mock.Anything, "alice@example.com").Return(nil, domain.ErrNotFound)
+	us.On("Put", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	var seen *domain.User
+	svc := NewService(ServiceDeps{
+		UserRepo: us,
+		PostRegister: []PostRegisterHook{postRegisterFunc(func(_ context.Context, u *domain.User) error {
+			seen = u
+			return errors.New("crm unavailable")
+		})},
+	})
+
+	u, err := svc.Register(context.Background(), baseReq())
+
+	require.NoError(t, err)
+	assert.Same(t, u, seen)
+}
@@ -9,27 +9,171 @@ import (
 
 // Config holds all runtime configuration loaded from environment variables.
 type Config struct {
-	AppPort                string
-	AppEnv                 string
-	AWSRegion              string
-	AWSEndpointURL         string // empty in prod, set to LocalStack URL in dev
-	AWSAccessKeyID         string
-	AWSSecretKey           string
-	DynamoTables           DynamoTables
-	S3BucketName           string
-	JWTPrivateKeyPath      string
-	JWTPublicKeyPath       string
-	JWTExpiry              time.Duration
-	RefreshTokenExpiryDays int
-	SMTPHost               string
-	SMTPPort               string
-	SMTPFrom               string
-	SMTPUsername           string
-	SMTPPassword           string
-	SMTPTLSEnabled         bool // enforce STARTTLS; set SMTP_TLS=true in production
-	SNSRegion              string
-	AllowedOrigins         []string // CORS allowed origins
-	GoogleClientID         string
+	AppPort                   string
+	AppEnv                    string
+	AWSRegion                 string
+	AWSEndpointURL            string // empty in prod, set to LocalStack URL in dev
+	AWSAccessKeyID            string
+	AWSSecretKey              string
+	DynamoTablePrefix         string // prepended to every table name, e.g. "staging-"
+	DynamoTables              DynamoTables
+	S3BucketName              string
+	S3KeyPrefix               string // prepended to every object key, e.g. "staging/"
+	JWTPrivateKeyPath         string
+	JWTPublicKeyPath          string
+	JWTExpiry                 time.Duration
+	RefreshTokenExpiryDays    int
+	UntrustedRefreshDays      int  // refresh-token lifetime on devices without a completed OTP; 0 disables
+	RememberMeDays            int  // remember-me token lifetime on trusted devices; 0 disables remember-me
+	TrustedDeviceRequired     bool // password and email changes need a device that passed an OTP challenge
+	SMTPHost                  string
+	SMTPPort                  string
+	SMTPFrom                  string
+	SMTPUsername              string
+	SMTPPassword              string
+	SMTPTLSEnabled            bool // enforce STARTTLS; set SMTP_TLS=true in production
+	SNSRegion                 string
+	SNSPlatformAppARN         string   // SNS platform application for mobile push; empty disables push
+	AllowedOrigins            []string // CORS allowed origins
+	GoogleClientID            string
+	RateLimitBackend          string        // "memory" (per instance) or "dynamo" (shared across replicas)
+	RateLimitExempt           []string      // CIDR ranges or addresses never rate limited, e.g. health probes
+	RateLimitIPv4Prefix       int           // IPv4 clients sharing this prefix share a bucket; 32 limits each address
+	RateLimitIPv6Prefix       int           // IPv6 clients sharing this prefix share a bucket, usually 64 or 56
+	TrustedProxies            []string      // CIDR ranges of proxies whose X-Forwarded-For is believed for rate limits and session IPs
+	MaxConcurrentRequests     int           // requests served at once across the API; 0 is unlimited
+	MaxConcurrentUploads      int           // file uploads served at once; 0 is unlimited
+	MaxConcurrentExports      int           // CSV exports served at once; 0 is unlimited
+	ConcurrencyQueueTimeout   time.Duration // how long a request waits for a free slot before a 503
+	RoutePolicyFile           string        // JSON route-to-role policy; empty uses the built-in default
+	SchedulerInterval         time.Duration // how often background jobs poll for due work
+	ActivityRetentionDays     int           // activity feed TTL; 0 keeps entries forever
+	NotificationRetentionDays int           // days a dismissed notification is kept before TTL purges it; 0 keeps it
+	UsageRetentionDays        int           // days daily usage counters are kept before TTL purges them
+	UsageQuotasFile           string        // JSON file of daily quotas per role; empty uses the built-in defaults
+	StripeSecretKey           string        // API key used to open customer-portal sessions
+	StripeWebhookSecret       string        // signing secret (whsec_...) of the POST /v1/webhooks/stripe endpoint
+	StripePricePlans          []string      // "price_id=plan" pairs mapping subscription prices to plans
+	StripePortalReturnURL     string        // where the customer portal sends users back to
+	OnboardingReminderDays    int           // account age at which an unconfirmed email gets a reminder
+	OnboardingNudgeDays       int           // account age at which an incomplete profile gets a nudge
+	OnboardingWindowDays      int           // account age past which no more onboarding emails are sent
+	OnboardingInterval        time.Duration // how often the onboarding job looks for due emails
+	BirthdayInterval          time.Duration // how often the birthday job looks for users to congratulate
+	MinimumAge                int           // self-service registrations younger than this are refused with the "underage" code; 0 disables
+	AuditRetentionDays        int           // days an audit entry is kept once retention is enforced; 0 keeps it
+	DeletionGraceDays         int           // days a soft-deleted user keeps their personal data before it is anonymized; 0 keeps it
+	DeletedUserRetentionDays  int           // days a soft-deleted user is kept before the purge job removes it; 0 keeps it
+	RetentionEnforce          bool          // apply retention rules; off only reports what would be purged
+	RetentionInterval         time.Duration // how often the purge job runs when retention is enforced
+	AdminEmail                string        // bootstrap admin created when no admin exists; empty disables
+	AdminPassword             string        // bootstrap admin password; empty requires password recovery
+	PasswordMinLength         int           // characters new passwords need (registration, changes, recovery, ADMIN_PASSWORD)
+	PasswordRequireUpper      bool          // new passwords need an uppercase letter
+	PasswordRequireLower      bool          // new passwords need a lowercase letter
+	PasswordRequireDigit      bool          // new passwords need a digit
+	PasswordRequireSymbol     bool          // new passwords need a character that is not a letter, digit or space
+	PasswordBanCommon         bool          // refuse the most frequently used passwords
+	PasswordBreachCheck       bool          // refuse passwords found in the Have I Been Pwned corpus
+	HIBPURL                   string        // Pwned Passwords range API; empty uses the public one
+	EmailFoldGmail            bool          // treat Gmail dot/+tag variants of an address as the same account
+	OpenSearchURL             string        // OpenSearch/Elasticsearch base URL for /v1/search; empty disables search
+	SearchSyncInterval        time.Duration // how often the search projection reads the table streams
+	CursorSecret              string        // HMAC key for pagination cursors; empty uses a per-process random key
+	VerificationCodeSecret    string        // HMAC key verification codes are hashed with at rest; required in production
+	RecoveryCodeLength        int           // characters in password-recovery OTPs
+	RecoveryCodeCharset       string        // "unambiguous", "digits", "alphanumeric" or the literal characters to draw from
+	RecoveryCodeTTL           time.Duration // how long a password-recovery OTP stays valid
+	EmailCodeLength           int           // characters in email confirmation tokens
+	EmailCodeCharset          string        // as RecoveryCodeCharset
+	EmailCodeTTL              time.Duration // how long an email confirmation token stays valid
+	PhoneCodeLength           int           // characters in phone confirmation OTPs
+	PhoneCodeCharset          string        // as RecoveryCodeCharset
+	PhoneCodeTTL              time.Duration // how long a phone confirmation OTP stays valid
+	CodeResendCooldown        time.Duration // minimum wait between codes sent through the confirm-email/confirm-phone resend actions
+	AntiEnumeration           bool          // answer recovery and registration without revealing which accounts exist
+	SessionCheckTTL           time.Duration // how long a validated bearer session is trusted before re-checking DynamoDB
+	MaxSessions               int           // active sessions per user; 0 means no limit
+	SessionLimitStrict        bool          // refuse logins over MaxSessions instead of disabling the oldest session
+	CountryHeader             string        // request header with the client's ISO country code, set by the CDN or gateway
+	HighRiskCountries         []string      // ISO country codes whose logins wait for approval by email; empty disables
+	UserMetadataSelfKeys      []string      // user metadata keys users may set on their own profile; admins may set any
+	DisplayNameFormat         string        // display_name template over {{first_name}}, {{last_name}} and {{username}}; users without names show their username
+	Gravatar                  string        // "off", "owner" or "public": which user objects get a Gravatar avatar_url
+	LoginApprovalTTL          time.Duration // how long a login from a high-risk country can be approved
+	LoginApprovalURL          string        // page the approval email links to; empty sends the code instead
+	WebAuthnRPID              string        // relying party ID passkeys are bound to: the site's registrable domain
+	WebAuthnRPName            string        // relying party name authenticators show when creating a passkey
+	WebAuthnOrigins           []string      // origins whose passkey ceremonies are accepted
+	MTLSPort                  string        // port of the optional mutual-TLS listener; empty disables it
+	MTLSCertFile              string        // server certificate for the mTLS listener
+	MTLSKeyFile               string        // server private key for the mTLS listener
+	MTLSClientCAFile          string        // PEM bundle of CAs that sign accepted client certificates
+	MTLSPrincipalsFile        string        // JSON mapping of client certificate subjects to API principals
+	DevConsole                bool          // serve the /dev/console QA console; only honoured when AppEnv is "development"
+	MailProvider              string        // "smtp" sends mail; "capture" keeps it in memory for GET /dev/emails
+	Chaos                     bool          // allow fault injection via /v1/admin/chaos; never honoured in production
+	ErrorDetails              bool          // add the full error text as "detail" to error responses; never honoured in production
+	ReplayProtection          bool          // require a fresh nonce and timestamp on password, role and delete requests
+	ReplayWindow              time.Duration // how far a request timestamp may drift from server time; nonces are kept this long
+	ApprovalsRequired         bool          // hold destructive admin actions until a second admin approves them
+	ApprovalWindow            time.Duration // how long a pending approval waits before it expires
+	ReadOnly                  bool          // refuse writes with 503; the runtime switch at /v1/admin/read-only cannot turn this off
+	ReadOnlyRefresh           time.Duration // how often each replica re-reads the runtime read-only switch
+	LaunchGate                bool          // admit new registrations only from the launch allowlist or with an invite code
+	ProfileRequired           bool          // answer 428 to non-admins until name, birthday and phone are filled in
+	PIIEncryption             string        // "local" or "kms" encrypts phone and birthday at rest; empty stores them in plaintext
+	PIILocalKeys              string        // "label:base64key,..." master keys for PII_ENCRYPTION=local; the first wraps new data keys
+	PIIKMSKeyID               string        // KMS key ID or ARN for PII_ENCRYPTION=kms
+	DynamoReplicaRegions      []string      // global table replica regions besides AWSRegion; empty is single-region
+	DynamoFailover            bool          // send DynamoDB requests to the next replica region when one fails
+	DynamoFailoverCooldown    time.Duration // how long a failed region is skipped before it is tried again
+	DynamoBillingMode         string        // "PAY_PER_REQUEST" or "PROVISIONED" switches existing tables too; empty creates on-demand tables and leaves existing ones alone
+	DynamoCapacity            string        // "table=rcu:wcu,..." provisioned throughput per table name
+	DynamoDefaultCapacity     string        // "rcu:wcu" for tables DynamoCapacity does not list
+	DynamoAutoscaling         bool          // register provisioned tables and indexes with Application Auto Scaling
+	DynamoAutoscalingMax      int           // autoscaling maximum as a multiple of the provisioned capacity
+	DynamoAutoscalingTarget   int           // target consumed/provisioned utilization, in percent
+	DynamoPITR                bool          // enable point-in-time recovery on every table at startup
+	BackupCheckInterval       time.Duration // how often table backup status is verified for the readiness report
+	StartupWait               time.Duration // how long startup retries an unreachable DynamoDB before giving up; 0 skips the check
+	DynamoCostTracking        bool          // meter DynamoDB capacity per route for /v1/admin/dynamo-costs
+	TenantMode                string        // "jwt" or "subdomain" isolates each tenant's data; empty is single-tenant
+	TenantBaseDomain          string        // domain below which each subdomain names a tenant, for TENANT_MODE=subdomain
+	AdminTenant               string        // tenant the bootstrap admin belongs to when TENANT_MODE is set
+	ForbidScans               bool          // reject DynamoDB Scans outside maintenance passes; only honoured in production
+	DynamoScanSegments        int           // segments each full-table scan (sweeps, reindexing) is split into
+	DynamoScanWorkers         int           // segments of one scan read concurrently
+	LogLevel                  string        // debug, info, warn or error
+	LogFormat                 string        // "json" or "text"
+	LogDebugSample            int           // keep one in N debug records of each message; 1 keeps all
+	LogRedact                 bool          // mask emails and drop tokens and passwords in log output
+	AccessLogSink             string        // where request logs go: "stdout", "file", "cloudwatch" or "off"
+	AccessLogFile             string        // path of the file sink
+	AccessLogMaxSizeMB        int           // size at which the file sink rotates
+	AccessLogMaxBackups       int           // rotated files kept next to the file sink
+	AccessLogGroup            string        // CloudWatch Logs group of the cloudwatch sink; must exist
+	AccessLogStream           string        // CloudWatch Logs stream; defaults to the hostname
+	AccessLogFlush            time.Duration // how often the cloudwatch sink sends buffered lines
+	Features                  Features
+}
+
+// Features switches optional route groups on or off. A disabled feature skips
+// its infrastructure clients and background jobs, and its routes return 404.
+type Features struct {
+	Files             bool // /v1/files and the S3 client
+	Notifications     bool // notifications, templates, scheduled delivery and SNS push
+	GoogleAuth        bool // POST /v1/sessions/google; GOOGLE_CLIENT_ID is only required when on
+	PhoneConfirmation bool // /v1/confirm-phone and the SNS SMS sender
+	AdminUI           bool // embedded admin web UI under /admin
+	UsageMetering     bool // per-user daily usage counters, quota enforcement and GET /v1/users/me/usage
+	StripeBilling     bool // POST /v1/webhooks/stripe and POST /v1/users/me/billing-portal
+	Onboarding        bool // welcome, confirm-email reminder and complete-profile nudge emails
+	SelfRegistration  bool // public POST /v1/users and first Google sign-ins; off leaves POST /v1/admin/users
+	Passkeys          bool // passkey registration under /v1/users/me/passkeys and login via POST /v1/sessions/webauthn
+	OAuthServer       bool // OAuth2 authorization server under /v1/oauth and client registration under /v1/admin/oauth/clients
+	Birthdays         bool // a notification on each user's birthday; needs Notifications
+	APIKeys           bool // X-API-Key authentication and key management under /v1/admin/api-keys
 }
 
 // DynamoTables holds the DynamoDB table name for each entity.
@@ -42,44 +186,264 @@ type DynamoTables struct {
 	Files             string
 	UserVerifications string
 	AppVersions       string
+	RateLimits        string
+	Templates         string // notification templates
+	Messages          string
+	Activities        string
+	Roles             string
+	AuditLogs         string
+	Approvals         string
+	Usage             string // daily per-user usage counters
+	Plans             string // billing plans and their entitlements
+	Broadcasts        string // admin announcements and their fan-out progress
+	Settings          string // runtime switches shared by every replica, e.g. read-only mode
+	LaunchAllowlist   string // emails, domains and invite codes admitted by the launch gate
+	Passkeys          string // WebAuthn credentials, by credential ID
+	OAuthClients      string // third-party apps registered with the OAuth2 server
+	APIKeys           string // hashed API keys of internal service callers
+}
+
+// Names lists every table name.
+func (t DynamoTables) Names() []string {
+	return []string{
+		t.Users, t.Sessions, t.Statuses, t.Devices, t.Notifications, t.Files, t.UserVerifications,
+		t.AppVersions, t.RateLimits, t.Templates, t.Messages, t.Activities, t.Roles, t.AuditLogs, t.Approvals, t.Usage,
+		t.Plans, t.Broadcasts, t.Settings, t.LaunchAllowlist, t.Passkeys, t.OAuthClients,
+		t.APIKeys,
+	}
+}
+
+// loadTables reads the table names and prepends prefix to each, so
+// environments sharing an AWS account get their own tables.
+func loadTables(prefix string) DynamoTables {
+	t := DynamoTables{
+		Users:             getEnv("DYNAMO_TABLE_USERS", "users"),
+		Sessions:          getEnv("DYNAMO_TABLE_SESSIONS", "sessions"),
+		Statuses:          getEnv("DYNAMO_TABLE_STATUSES", "statuses"),
+		Devices:           getEnv("DYNAMO_TABLE_DEVICES", "devices"),
+		Notifications:     getEnv("DYNAMO_TABLE_NOTIFICATIONS", "notifications"),
+		Files:             getEnv("DYNAMO_TABLE_FILES", "files"),
+		UserVerifications: getEnv("DYNAMO_TABLE_USER_VERIFICATIONS", "user_verifications"),
+		AppVersions:       getEnv("DYNAMO_TABLE_APP_VERSIONS", "app_versions"),
+		RateLimits:        getEnv("DYNAMO_TABLE_RATE_LIMITS", "rate_limits"),
+		Templates:         getEnv("DYNAMO_TABLE_NOTIFICATION_TEMPLATES", "notification_templates"),
+		Messages:          getEnv("DYNAMO_TABLE_MESSAGES", "messages"),
+		Activities:        getEnv("DYNAMO_TABLE_ACTIVITIES", "activities"),
+		Roles:             getEnv("DYNAMO_TABLE_ROLES", "roles"),
+		AuditLogs:         getEnv("DYNAMO_TABLE_AUDIT_LOGS", "audit_logs"),
+		Approvals:         getEnv("DYNAMO_TABLE_APPROVALS", "approvals"),
+		Usage:             getEnv("DYNAMO_TABLE_USAGE", "usage"),
+		Plans:             getEnv("DYNAMO_TABLE_PLANS", "plans"),
+		Broadcasts:        getEnv("DYNAMO_TABLE_BROADCASTS", "broadcasts"),
+		Settings:          getEnv("DYNAMO_TABLE_SETTINGS", "settings"),
+		LaunchAllowlist:   getEnv("DYNAMO_TABLE_LAUNCH_ALLOWLIST", "launch_allowlist"),
+		Passkeys:          getEnv("DYNAMO_TABLE_PASSKEYS", "passkeys"),
+		OAuthClients:      getEnv("DYNAMO_TABLE_OAUTH_CLIENTS", "oauth_clients"),
+		APIKeys:           getEnv("DYNAMO_TABLE_API_KEYS", "api_keys"),
+	}
+	for _, name := range []*string{
+		&t.Users, &t.Sessions, &t.Statuses, &t.Devices, &t.Notifications, &t.Files, &t.UserVerifications,
+		&t.AppVersions, &t.RateLimits, &t.Templates, &t.Messages, &t.Activities, &t.Roles, &t.AuditLogs, &t.Approvals, &t.Usage,
+		&t.Plans, &t.Broadcasts, &t.Settings, &t.LaunchAllowlist, &t.Passkeys, &t.OAuthClients,
+		&t.APIKeys,
+	} {
+		*name = prefix + *name
+	}
+	return t
 }
 
 // Load reads all configuration from environment variables.
 func Load() *Config {
 	return &Config{
-		AppPort:        getEnv("APP_PORT", "3000"),
-		AppEnv:         getEnv("APP_ENV", "development"),
-		AWSRegion:      getEnv("AWS_REGION", "us-east-1"),
-		AWSEndpointURL: getEnv("AWS_ENDPOINT_URL", ""),
-		AWSAccessKeyID: getEnv("AWS_ACCESS_KEY_ID", ""),
-		AWSSecretKey:   getEnv("AWS_SECRET_ACCESS_KEY", ""),
-		DynamoTables: DynamoTables{
-			Users:             getEnv("DYNAMO_TABLE_USERS", "users"),
-			Sessions:          getEnv("DYNAMO_TABLE_SESSIONS", "sessions"),
-			Statuses:          getEnv("DYNAMO_TABLE_STATUSES", "statuses"),
-			Devices:           getEnv("DYNAMO_TABLE_DEVICES", "devices"),
-			Notifications:     getEnv("DYNAMO_TABLE_NOTIFICATIONS", "notifications"),
-			Files:             getEnv("DYNAMO_TABLE_FILES", "files"),
-			UserVerifications: getEnv("DYNAMO_TABLE_USER_VERIFICATIONS", "user_verifications"),
-			AppVersions:       getEnv("DYNAMO_TABLE_APP_VERSIONS", "app_versions"),
+		AppPort:                   getEnv("APP_PORT", "3000"),
+		AppEnv:                    getEnv("APP_ENV", "development"),
+		AWSRegion:                 getEnv("AWS_REGION", "us-east-1"),
+		AWSEndpointURL:            getEnv("AWS_ENDPOINT_URL", ""),
+		AWSAccessKeyID:            getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretKey:              getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		DynamoTablePrefix:         getEnv("DYNAMO_TABLE_PREFIX", ""),
+		DynamoTables:              loadTables(getEnv("DYNAMO_TABLE_PREFIX", "")),
+		S3KeyPrefix:               getEnv("S3_KEY_PREFIX", ""),
+		S3BucketName:              getEnv("S3_BUCKET_NAME", "go-api-files"),
+		JWTPrivateKeyPath:         getEnv("JWT_PRIVATE_KEY_PATH", "./private_key.pem"),
+		JWTPublicKeyPath:          getEnv("JWT_PUBLIC_KEY_PATH", "./public_key.pem"),
+		JWTExpiry:                 getEnvDuration("JWT_EXPIRY", time.Hour),
+		RefreshTokenExpiryDays:    getEnvInt("REFRESH_TOKEN_EXPIRY_DAYS", 30),
+		UntrustedRefreshDays:      getEnvInt("UNTRUSTED_REFRESH_TOKEN_EXPIRY_DAYS", 1),
+		RememberMeDays:            getEnvInt("REMEMBER_ME_DAYS", 90),
+		TrustedDeviceRequired:     getEnvBool("TRUSTED_DEVICE_REQUIRED", true),
+		SMTPHost:                  getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:                  getEnv("SMTP_PORT", "1025"),
+		SMTPFrom:                  getEnv("SMTP_FROM", "noreply@example.com"),
+		SMTPUsername:              getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:              getEnv("SMTP_PASSWORD", ""),
+		SMTPTLSEnabled:            getEnvBool("SMTP_TLS", false),
+		SNSRegion:                 getEnv("SNS_REGION", "us-east-1"),
+		SNSPlatformAppARN:         getEnv("SNS_PLATFORM_APPLICATION_ARN", ""),
+		GoogleClientID:            getEnv("GOOGLE_CLIENT_ID", ""),
+		AllowedOrigins:            getEnvStringSlice("ALLOWED_ORIGINS", "*"),
+		RateLimitBackend:          getEnv("RATE_LIMIT_BACKEND", "memory"),
+		RateLimitExempt:           getEnvStringSlice("RATE_LIMIT_EXEMPT", ""),
+		RateLimitIPv4Prefix:       getEnvInt("RATE_LIMIT_IPV4_PREFIX", 32),
+		RateLimitIPv6Prefix:       getEnvInt("RATE_LIMIT_IPV6_PREFIX", 64),
+		TrustedProxies:            getEnvStringSlice("TRUSTED_PROXIES", "127.0.0.0/8,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"),
+		MaxConcurrentRequests:     getEnvInt("MAX_CONCURRENT_REQUESTS", 0),
+		MaxConcurrentUploads:      getEnvInt("MAX_CONCURRENT_UPLOADS", 10),
+		MaxConcurrentExports:      getEnvInt("MAX_CONCURRENT_EXPORTS", 2),
+		ConcurrencyQueueTimeout:   getEnvDuration("CONCURRENCY_QUEUE_TIMEOUT", 2*time.Second),
+		RoutePolicyFile:           getEnv("ROUTE_POLICY_FILE", ""),
+		SchedulerInterval:         getEnvDuration("SCHEDULER_INTERVAL", time.Minute),
+		ActivityRetentionDays:     getEnvInt("ACTIVITY_RETENTION_DAYS", 90),
+		NotificationRetentionDays: getEnvInt("NOTIFICATION_RETENTION_DAYS", 30),
+		UsageRetentionDays:        getEnvInt("USAGE_RETENTION_DAYS", 90),
+		UsageQuotasFile:           getEnv("USAGE_QUOTAS_FILE", ""),
+		StripeSecretKey:           getEnv("STRIPE_SECRET_KEY", ""),
+		StripeWebhookSecret:       getEnv("STRIPE_WEBHOOK_SECRET", ""),
+		StripePricePlans:          getEnvStringSlice("STRIPE_PRICE_PLANS", ""),
+		StripePortalReturnURL:     getEnv("STRIPE_PORTAL_RETURN_URL", ""),
+		OnboardingReminderDays:    getEnvInt("ONBOARDING_REMINDER_DAYS", 3),
+		OnboardingNudgeDays:       getEnvInt("ONBOARDING_NUDGE_DAYS", 7),
+		OnboardingWindowDays:      getEnvInt("ONBOARDING_WINDOW_DAYS", 14),
+		OnboardingInterval:        getEnvDuration("ONBOARDING_INTERVAL", time.Hour),
+		BirthdayInterval:          getEnvDuration("BIRTHDAY_INTERVAL", time.Hour),
+		MinimumAge:                getEnvInt("MINIMUM_AGE", 13),
+		PasswordMinLength:         getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:      getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		PasswordRequireLower:      getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		PasswordRequireDigit:      getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		PasswordRequireSymbol:     getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordBanCommon:         getEnvBool("PASSWORD_BAN_COMMON", true),
+		PasswordBreachCheck:       getEnvBool("PASSWORD_BREACH_CHECK", false),
+		HIBPURL:                   getEnv("HIBP_API_URL", ""),
+		AuditRetentionDays:        getEnvInt("AUDIT_RETENTION_DAYS", 365),
+		DeletionGraceDays:         getEnvInt("DELETION_GRACE_DAYS", 14),
+		DeletedUserRetentionDays:  getEnvInt("DELETED_USER_RETENTION_DAYS", 0),
+		RetentionEnforce:          getEnvBool("RETENTION_ENFORCE", false),
+		RetentionInterval:         getEnvDuration("RETENTION_INTERVAL", 24*time.Hour),
+		AdminEmail:                getEnv("ADMIN_EMAIL", ""),
+		AdminPassword:             getEnv("ADMIN_PASSWORD", ""),
+		EmailFoldGmail:            getEnvBool("EMAIL_FOLD_GMAIL", false),
+		OpenSearchURL:             getEnv("OPENSEARCH_URL", ""),
+		SearchSyncInterval:        getEnvDuration("SEARCH_SYNC_INTERVAL", 5*time.Second),
+		CursorSecret:              getEnv("CURSOR_SECRET", ""),
+		VerificationCodeSecret:    getEnv("VERIFICATION_CODE_SECRET", ""),
+		RecoveryCodeLength:        getEnvInt("RECOVERY_CODE_LENGTH", 6),
+		RecoveryCodeCharset:       getEnv("RECOVERY_CODE_CHARSET", "unambiguous"),
+		RecoveryCodeTTL:           getEnvDuration("RECOVERY_CODE_TTL", 15*time.Minute),
+		EmailCodeLength:           getEnvInt("EMAIL_CODE_LENGTH", 32),
+		EmailCodeCharset:          getEnv("EMAIL_CODE_CHARSET", "alphanumeric"),
+		EmailCodeTTL:              getEnvDuration("EMAIL_CODE_TTL", 24*time.Hour),
+		PhoneCodeLength:           getEnvInt("PHONE_CODE_LENGTH", 6),
+		PhoneCodeCharset:          getEnv("PHONE_CODE_CHARSET", "unambiguous"),
+		PhoneCodeTTL:              getEnvDuration("PHONE_CODE_TTL", 15*time.Minute),
+		CodeResendCooldown:        getEnvDuration("VERIFICATION_RESEND_COOLDOWN", time.Minute),
+		AntiEnumeration:           getEnvBool("ANTI_ENUMERATION", false),
+		SessionCheckTTL:           getEnvDuration("SESSION_CHECK_TTL", 30*time.Second),
+		MaxSessions:               getEnvInt("MAX_SESSIONS", 0),
+		SessionLimitStrict:        getEnvBool("SESSION_LIMIT_STRICT", false),
+		CountryHeader:             getEnv("GEO_COUNTRY_HEADER", "CloudFront-Viewer-Country"),
+		HighRiskCountries:         getEnvStringSlice("HIGH_RISK_COUNTRIES", ""),
+		UserMetadataSelfKeys:      getEnvStringSlice("USER_METADATA_SELF_KEYS", ""),
+		DisplayNameFormat:         getEnv("DISPLAY_NAME_FORMAT", "{{first_name}} {{last_name}}"),
+		Gravatar:                  getEnv("GRAVATAR", "off"),
+		LoginApprovalTTL:          getEnvDuration("LOGIN_APPROVAL_TTL", 30*time.Minute),
+		LoginApprovalURL:          getEnv("LOGIN_APPROVAL_URL", ""),
+		WebAuthnRPID:              getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:            getEnv("WEBAUTHN_RP_NAME", "go-api-nosql"),
+		WebAuthnOrigins:           getEnvStringSlice("WEBAUTHN_ORIGINS", "http://localhost:3000"),
+		MTLSPort:                  getEnv("MTLS_PORT", ""),
+		MTLSCertFile:              getEnv("MTLS_CERT_FILE", ""),
+		MTLSKeyFile:               getEnv("MTLS_KEY_FILE", ""),
+		MTLSClientCAFile:          getEnv("MTLS_CLIENT_CA_FILE", ""),
+		MTLSPrincipalsFile:        getEnv("MTLS_PRINCIPALS_FILE", ""),
+		DevConsole:                getEnvBool("DEV_CONSOLE", false),
+		MailProvider:              getEnv("MAIL_PROVIDER", "smtp"),
+		Chaos:                     getEnvBool("CHAOS_INJECTION", false),
+		ErrorDetails:              getEnvBool("ERROR_DETAILS", false),
+		ReplayProtection:          getEnvBool("REPLAY_PROTECTION", false),
+		ReplayWindow:              getEnvDuration("REPLAY_WINDOW", 5*time.Minute),
+		ApprovalsRequired:         getEnvBool("APPROVALS_REQUIRED", false),
+		ApprovalWindow:            getEnvDuration("APPROVAL_WINDOW", 24*time.Hour),
+		ReadOnly:                  getEnvBool("READ_ONLY", false),
+		ReadOnlyRefresh:           getEnvDuration("READ_ONLY_REFRESH", 10*time.Second),
+		LaunchGate:                getEnvBool("LAUNCH_GATE", false),
+		ProfileRequired:           getEnvBool("PROFILE_REQUIRED", false),
+		PIIEncryption:             getEnv("PII_ENCRYPTION", ""),
+		PIILocalKeys:              getEnv("PII_LOCAL_KEYS", ""),
+		PIIKMSKeyID:               getEnv("PII_KMS_KEY_ID", ""),
+		DynamoReplicaRegions:      getEnvStringSlice("DYNAMO_REPLICA_REGIONS", ""),
+		DynamoFailover:            getEnvBool("DYNAMO_FAILOVER", false),
+		DynamoFailoverCooldown:    getEnvDuration("DYNAMO_FAILOVER_COOLDOWN", 30*time.Second),
+		DynamoBillingMode:         getEnv("DYNAMO_BILLING_MODE", ""),
+		DynamoCapacity:            getEnv("DYNAMO_CAPACITY", ""),
+		DynamoDefaultCapacity:     getEnv("DYNAMO_DEFAULT_CAPACITY", "5:5"),
+		DynamoAutoscaling:         getEnvBool("DYNAMO_AUTOSCALING", false),
+		DynamoAutoscalingMax:      getEnvInt("DYNAMO_AUTOSCALING_MAX_FACTOR", 4),
+		DynamoAutoscalingTarget:   getEnvInt("DYNAMO_AUTOSCALING_TARGET", 70),
+		DynamoPITR:                getEnvBool("DYNAMO_PITR", true),
+		BackupCheckInterval:       getEnvDuration("BACKUP_CHECK_INTERVAL", time.Hour),
+		StartupWait:               getEnvDuration("STARTUP_WAIT", 30*time.Second),
+		DynamoCostTracking:        getEnvBool("DYNAMO_COST_TRACKING", true),
+		TenantMode:                getEnv("TENANT_MODE", ""),
+		TenantBaseDomain:          getEnv("TENANT_BASE_DOMAIN", ""),
+		AdminTenant:               getEnv("ADMIN_TENANT", ""),
+		ForbidScans:               getEnvBool("FORBID_SCANS", false),
+		DynamoScanSegments:        getEnvInt("DYNAMO_SCAN_SEGMENTS", 8),
+		DynamoScanWorkers:         getEnvInt("DYNAMO_SCAN_WORKERS", 4),
+		LogLevel:                  getEnv("LOG_LEVEL", "info"),
+		LogFormat:                 getEnv("LOG_FORMAT", "text"),
+		LogDebugSample:            getEnvInt("LOG_DEBUG_SAMPLE", 1),
+		LogRedact:                 getEnvBool("LOG_REDACT", true),
+		AccessLogSink:             getEnv("ACCESS_LOG_SINK", "stdout"),
+		AccessLogFile:             getEnv("ACCESS_LOG_FILE", "logs/access.log"),
+		AccessLogMaxSizeMB:        getEnvInt("ACCESS_LOG_MAX_SIZE_MB", 100),
+		AccessLogMaxBackups:       getEnvInt("ACCESS_LOG_MAX_BACKUPS", 5),
+		AccessLogGroup:            getEnv("ACCESS_LOG_GROUP", "go-api-nosql-access"),
+		AccessLogStream:           getEnv("ACCESS_LOG_STREAM", ""),
+		AccessLogFlush:            getEnvDuration("ACCESS_LOG_FLUSH", 5*time.Second),
+		Features: Features{
+			Files:             getEnvBool("FEATURE_FILES", true),
+			Notifications:     getEnvBool("FEATURE_NOTIFICATIONS", true),
+			GoogleAuth:        getEnvBool("FEATURE_GOOGLE_AUTH", true),
+			PhoneConfirmation: getEnvBool("FEATURE_PHONE_CONFIRMATION", true),
+			AdminUI:           getEnvBool("FEATURE_ADMIN_UI", true),
+			UsageMetering:     getEnvBool("FEATURE_USAGE_METERING", false),
+			StripeBilling:     getEnvBool("FEATURE_STRIPE_BILLING", false),
+			Onboarding:        getEnvBool("FEATURE_ONBOARDING_EMAILS", false),
+			Birthdays:         getEnvBool("FEATURE_BIRTHDAY_NOTIFICATIONS", false),
+			SelfRegistration:  getEnvBool("FEATURE_SELF_REGISTRATION", true),
+			Passkeys:          getEnvBool("FEATURE_PASSKEYS", false),
+			OAuthServer:       getEnvBool("FEATURE_OAUTH_SERVER", false),
+			APIKeys:           getEnvBool("FEATURE_API_KEYS", false),
 		},
-		S3BucketName:           getEnv("S3_BUCKET_NAME", "go-api-files"),
-		JWTPrivateKeyPath:      getEnv("JWT_PRIVATE_KEY_PATH", "./private_key.pem"),
-		JWTPublicKeyPath:       getEnv("JWT_PUBLIC_KEY_PATH", "./public_key.pem"),
-		JWTExpiry:              getEnvDuration("JWT_EXPIRY", time.Hour),
-		RefreshTokenExpiryDays: getEnvInt("REFRESH_TOKEN_EXPIRY_DAYS", 30),
-		SMTPHost:               getEnv("SMTP_HOST", "localhost"),
-		SMTPPort:               getEnv("SMTP_PORT", "1025"),
-		SMTPFrom:               getEnv("SMTP_FROM", "noreply@example.com"),
-		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
-		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
-		SMTPTLSEnabled:         getEnvBool("SMTP_TLS", false),
-		SNSRegion:              getEnv("SNS_REGION", "us-east-1"),
-		GoogleClientID: getEnv("GOOGLE_CLIENT_ID", ""),
-		AllowedOrigins:  getEnvStringSlice("ALLOWED_ORIGINS", "*"),
 	}
 }
 
+// DevConsoleEnabled reports whether the dev console should be served: it mints
+// tokens without authentication, so DEV_CONSOLE only counts in development.
+func (c *Config) DevConsoleEnabled() bool {
+	return c.DevConsole && c.AppEnv == "development"
+}
+
+// ChaosEnabled reports whether fault injection is available. It is meant for
+// staging, so CHAOS_INJECTION is ignored when AppEnv is "production".
+func (c *Config) ChaosEnabled() bool {
+	return c.Chaos && c.AppEnv != "production"
+}
+
+// ErrorDetailsEnabled reports whether error responses carry the underlying
+// error. The text can name tables and AWS requests, so ERROR_DETAILS is
+// ignored when AppEnv is "production".
+func (c *Config) ErrorDetailsEnabled() bool {
+	return c.ErrorDetails && c.AppEnv != "production"
+}
+
+// ScansForbidden reports whether DynamoDB Scans are rejected. FORBID_SCANS
+// guards production capacity, so it only counts when AppEnv is "production".
+func (c *Config) ScansForbidden() bool {
+	return c.ForbidScans && c.AppEnv == "production"
+}
+
 func getEnv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
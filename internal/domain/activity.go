@@ -0,0 +1,21 @@
+package domain
+
+// ActivityTimeLayout formats Activity.CreatedAt. It is fixed-width so the
+// string sort key orders chronologically.
+const ActivityTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Activity kinds recorded in a user's feed.
+const (
+	ActivityLogin         = "login"
+	ActivityProfileUpdate = "profile_update"
+	ActivityFileUpload    = "file_upload"
+)
+
+// Activity is one entry in a user's activity feed.
+type Activity struct {
+	UserID    string `json:"user_id" dynamodbav:"user_id"`
+	CreatedAt string `json:"created" dynamodbav:"created_at"` // ActivityTimeLayout, UTC; sort key
+	Kind      string `json:"kind" dynamodbav:"kind"`
+	Subject   string `json:"subject,omitempty" dynamodbav:"subject,omitempty"` // e.g. file ID or changed fields
+	ExpiresAt int64  `json:"-" dynamodbav:"expires_at,omitempty"`              // DynamoDB TTL (unix seconds)
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// APIKey lets an internal service call the API without a user's JWT. It acts
+// as UserID, a service account, with that user's current role, limited to
+// Scopes. The key itself is "<KeyID>.<secret>"; only a hash of the secret is
+// stored.
+type APIKey struct {
+	KeyID      string     `json:"id" dynamodbav:"key_id"`
+	Name       string     `json:"name" dynamodbav:"name"`
+	SecretHash string     `json:"-" dynamodbav:"secret_hash"` // SHA-256 of the secret
+	UserID     string     `json:"user_id" dynamodbav:"user_id"`
+	Scopes     []string   `json:"scopes" dynamodbav:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" dynamodbav:"expires_at,omitempty"` // nil never expires
+	CreatedBy  string     `json:"created_by" dynamodbav:"created_by"`
+	CreatedAt  time.Time  `json:"created" dynamodbav:"created_at"`
+	TenantID   string     `json:"-" dynamodbav:"tenant_id,omitempty"` // stamped on write when TENANT_MODE is set
+}
+
+// CreateAPIKeyRequest is the body for POST /v1/admin/api-keys.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	UserID    string     `json:"user_id" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyCreated is the response for POST /v1/admin/api-keys. The key is only
+// ever shown here.
+type APIKeyCreated struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipal is who a verified API key acts as: its user, with the
+// user's current role, and the key's scopes that role still holds, in the
+// tenant the key was created in.
+type APIKeyPrincipal struct {
+	KeyID    string
+	UserID   string
+	Role     string
+	Scopes   []string
+	TenantID string
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// Destructive admin actions that wait for a second admin when
+// APPROVALS_REQUIRED is on.
+const (
+	ApprovalUserDelete = "user.delete"      // an admin deleting another user
+	ApprovalGrantAdmin = "user.grant_admin" // PUT /v1/admin/users/{id}/role with role Admin
+	ApprovalBulk       = "user.bulk"        // bulk disable, delete or set_role Admin
+)
+
+// Approval lifecycle states. Pending approvals past ExpiresAt are reported as
+// expired and can no longer be approved.
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved" // approved and executed successfully
+	ApprovalFailed   = "failed"   // approved, but executing the action returned Error
+	ApprovalRejected = "rejected"
+	ApprovalExpired  = "expired"
+)
+
+// Approval is a destructive admin action held until a second admin approves
+// or rejects it. Exactly one of TargetID (with ChangeRole for a role grant)
+// or Bulk describes the action.
+type Approval struct {
+	ApprovalID  string             `json:"id" dynamodbav:"approval_id"`
+	Action      string             `json:"action" dynamodbav:"action"`
+	TargetID    string             `json:"target_id,omitempty" dynamodbav:"target_id,omitempty"`
+	ChangeRole  *ChangeRoleRequest `json:"change_role,omitempty" dynamodbav:"change_role,omitempty"`
+	Bulk        *BulkUserRequest   `json:"bulk,omitempty" dynamodbav:"bulk,omitempty"`
+	Status      string             `json:"status" dynamodbav:"status"`
+	RequestedBy string             `json:"requested_by" dynamodbav:"requested_by"`
+	RequestedAt time.Time          `json:"requested_at" dynamodbav:"requested_at"`
+	ExpiresAt   time.Time          `json:"expires_at" dynamodbav:"deadline"`
+	DecidedBy   string             `json:"decided_by,omitempty" dynamodbav:"decided_by,omitempty"`
+	DecidedAt   *time.Time         `json:"decided_at,omitempty" dynamodbav:"decided_at,omitempty"`
+	Reason      string             `json:"reason,omitempty" dynamodbav:"reason,omitempty"` // given on rejection
+	Error       string             `json:"error,omitempty" dynamodbav:"error,omitempty"`   // set when Status is failed
+	// Results holds the per-user outcome of an executed bulk action.
+	Results []BulkUserResult `json:"results,omitempty" dynamodbav:"results,omitempty"`
+}
+
+// RejectApprovalRequest is the body for POST /v1/admin/approvals/{id}/reject.
+type RejectApprovalRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
@@ -1,7 +1,27 @@
 package domain
 
+import "time"
+
+// App platforms. Each platform has its own line of app versions.
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
 type AppVersion struct {
-	VersionID string `json:"id" dynamodbav:"version_id"`
-	Version   string `json:"version" dynamodbav:"version"`
-	Enable    bool   `json:"enable" dynamodbav:"enable"`
+	VersionID  string    `json:"id" dynamodbav:"version_id"`
+	Platform   string    `json:"platform" dynamodbav:"platform,omitempty"`
+	Version    string    `json:"version" dynamodbav:"version"`
+	Enable     bool      `json:"enable" dynamodbav:"enable"`
+	ReleasedAt time.Time `json:"released_at" dynamodbav:"released_at"` // set on create; orders versions per platform
+}
+
+// AppVersionInput is the body for POST /v1/admin/app-versions and
+// PUT /v1/admin/app-versions/{id}. Enable defaults to true on create.
+// Platform is required on create and unchanged on update when omitted.
+type AppVersionInput struct {
+	Platform string `json:"platform" validate:"omitempty,oneof=android ios web"`
+	Version  string `json:"version" validate:"required,max=32"`
+	Enable   *bool  `json:"enable"`
 }
@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// Audit actions.
+const (
+	AuditUserRoleChange = "user.role_change"
+	AuditUserDisable    = "user.disable"
+	AuditUserEnable     = "user.enable"
+	AuditUserDelete     = "user.delete"
+	AuditUserPlanChange = "user.plan_change"
+	AuditUserProvision  = "user.provision"
+
+	AuditApprovalRequest = "approval.request"
+	AuditApprovalApprove = "approval.approve"
+	AuditApprovalReject  = "approval.reject"
+
+	AuditBroadcastCreate = "broadcast.create"
+	AuditBroadcastCancel = "broadcast.cancel"
+
+	AuditReadOnlySet = "settings.read_only"
+
+	AuditLaunchAllow  = "launch.allow"
+	AuditLaunchRevoke = "launch.revoke"
+
+	AuditSessionEvict     = "session.evict"
+	AuditSessionTerminate = "session.terminate"
+
+	AuditPasskeyRegister = "passkey.register"
+	AuditPasskeyDelete   = "passkey.delete"
+
+	AuditOAuthClientCreate = "oauth_client.create"
+	AuditOAuthClientDelete = "oauth_client.delete"
+	AuditOAuthTokenRevoke  = "oauth_token.revoke"
+
+	AuditAPIKeyCreate = "api_key.create"
+	AuditAPIKeyRevoke = "api_key.revoke"
+)
+
+// AuditEntry records an administrative action. Entries are partitioned by UTC
+// day and sorted by AuditID, a ULID, so a day's entries come back in time order.
+type AuditEntry struct {
+	Day       string            `json:"-" dynamodbav:"day"` // YYYY-MM-DD (UTC)
+	AuditID   string            `json:"id" dynamodbav:"audit_id"`
+	Action    string            `json:"action" dynamodbav:"action"`
+	ActorID   string            `json:"actor_id" dynamodbav:"actor_id"`
+	TargetID  string            `json:"target_id" dynamodbav:"target_id"`
+	Details   map[string]string `json:"details,omitempty" dynamodbav:"details,omitempty"`
+	CreatedAt time.Time         `json:"created" dynamodbav:"created_at"`
+	ExpiresAt int64             `json:"-" dynamodbav:"expires_at,omitempty"` // DynamoDB TTL (unix seconds)
+}
+
+// AuditFilter narrows audit log exports to an inclusive range of UTC days.
+type AuditFilter struct {
+	From   string // YYYY-MM-DD
+	To     string // YYYY-MM-DD
+	Action string // empty matches every action
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// BackupReport is the point-in-time recovery (PITR) status of every table.
+// Healthy is false when any table has PITR off, could not be checked, or has
+// a latest restorable time that lags too far behind.
+type BackupReport struct {
+	Healthy   bool          `json:"healthy"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Tables    []TableBackup `json:"tables"`
+}
+
+// TableBackup is one table's PITR status.
+type TableBackup struct {
+	Table              string     `json:"table"`
+	PITREnabled        bool       `json:"pitr_enabled"`
+	LatestRestorableAt *time.Time `json:"latest_restorable_at,omitempty"`
+	Problem            string     `json:"problem,omitempty"` // why the table makes the report unhealthy
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// SubscriptionEvent is a verified change to a user's paid subscription, as
+// reported by the payment provider's webhook.
+type SubscriptionEvent struct {
+	EventID    string
+	UserID     string // subscription metadata user_id, set when checkout is created
+	TenantID   string // subscription metadata tenant_id, in tenant mode
+	CustomerID string
+	Status     string // provider status: active, trialing, past_due, canceled, ...
+	PriceID    string // price of the subscription's first item
+	Ended      bool   // the subscription was deleted
+	Created    time.Time
+}
@@ -2,9 +2,22 @@ package domain
 
 import "time"
 
+// UpdateDeviceRequest is the body for PUT /v1/devices/{id}. Ownership and
+// trust are not client-settable; unknown fields are rejected.
 type UpdateDeviceRequest struct {
-	Token        *string `json:"token"`
-	AppVersionID *string `json:"app_version_id"`
+	Token        *string `json:"token" validate:"omitempty,max=4096"`
+	AppVersionID *string `json:"app_version_id" validate:"omitempty,max=64"`
+}
+
+// RotateDeviceTokenRequest is the body for POST /v1/devices/{id}/token.
+type RotateDeviceTokenRequest struct {
+	Token string `json:"token" validate:"required,max=4096"`
+}
+
+// CheckVersionRequest is the body for PUT /v1/devices/version.
+type CheckVersionRequest struct {
+	Platform      string  `json:"platform" validate:"required,oneof=android ios web"`
+	DeviceVersion float64 `json:"device_version"`
 }
 
 type Device struct {
@@ -14,6 +27,8 @@ type Device struct {
 	Token        *string   `json:"token" dynamodbav:"token"`
 	AppVersionID string    `json:"app_version_id" dynamodbav:"app_version_id"`
 	Enable       bool      `json:"enable" dynamodbav:"enable"`
+	Trusted      bool      `json:"trusted" dynamodbav:"trusted"`         // set after an OTP challenge on this device
+	TokenStale   bool      `json:"token_stale" dynamodbav:"token_stale"` // provider rejected Token; cleared on rotation
 	CreatedAt    time.Time `json:"created" dynamodbav:"created_at"`
 	UpdatedAt    time.Time `json:"updated" dynamodbav:"updated_at"`
 }
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultNameFormat renders display names as "First Last".
+const DefaultNameFormat = "{{first_name}} {{last_name}}"
+
+// namePlaceholder matches {{name}} with optional surrounding spaces.
+var namePlaceholder = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+
+// avatarColors is the palette avatar backgrounds are picked from. All of them
+// keep white initials readable.
+var avatarColors = []string{
+	"#E53935", "#D81B60", "#8E24AA", "#5E35B1", "#3949AB", "#1E88E5",
+	"#00897B", "#43A047", "#7CB342", "#F4511E", "#6D4C41", "#546E7A",
+}
+
+// ValidateNameFormat checks that format only uses the {{first_name}},
+// {{last_name}} and {{username}} placeholders.
+func ValidateNameFormat(format string) error {
+	for _, m := range namePlaceholder.FindAllStringSubmatch(format, -1) {
+		switch m[1] {
+		case "first_name", "last_name", "username":
+		default:
+			return fmt.Errorf("display name format: unknown placeholder %q", m[0])
+		}
+	}
+	return nil
+}
+
+// DisplayName renders format (see ValidateNameFormat) with u's names, with
+// runs of whitespace collapsed. Users without a first or last name are shown
+// by their username.
+func (u *User) DisplayName(format string) string {
+	first, last := strings.TrimSpace(u.FirstName), strings.TrimSpace(u.LastName)
+	if first == "" && last == "" {
+		return u.Username
+	}
+	name := namePlaceholder.ReplaceAllStringFunc(format, func(p string) string {
+		switch namePlaceholder.FindStringSubmatch(p)[1] {
+		case "first_name":
+			return first
+		case "last_name":
+			return last
+		case "username":
+			return u.Username
+		}
+		return ""
+	})
+	if name = strings.Join(strings.Fields(name), " "); name == "" {
+		return u.Username
+	}
+	return name
+}
+
+// Initials returns up to two uppercase letters for u's avatar: the first
+// letters of the first and last names, or of the username when both names are
+// blank.
+func (u *User) Initials() string {
+	var initials []rune
+	for _, part := range []string{u.FirstName, u.LastName} {
+		if r, ok := firstLetter(part); ok {
+			initials = append(initials, r)
+		}
+	}
+	if len(initials) == 0 {
+		if r, ok := firstLetter(u.Username); ok {
+			initials = append(initials, r)
+		}
+	}
+	return strings.ToUpper(string(initials))
+}
+
+// AvatarColor returns the background color of u's avatar, a hex "#RRGGBB"
+// picked from a fixed palette by the user ID so it never changes with the
+// user's names.
+func (u *User) AvatarColor() string {
+	h := fnv.New32a()
+	h.Write([]byte(u.UserID))
+	return avatarColors[h.Sum32()%uint32(len(avatarColors))]
+}
+
+// Gravatar modes: which user objects carry a Gravatar avatar_url.
+const (
+	GravatarOff    = "off"
+	GravatarOwner  = "owner"  // only the user's own profile and admin views
+	GravatarPublic = "public" // every user object, public profiles included
+)
+
+// GravatarURL is the Gravatar image of u's email. It answers 404 when the
+// address has no Gravatar, so clients fall back to the initials. Anonymized
+// users have none.
+func (u *User) GravatarURL() string {
+	if u.AnonymizedAt != nil || u.Email == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(NormalizeEmail(u.Email, false)))
+	return "https://www.gravatar.com/avatar/" + hex.EncodeToString(sum[:]) + "?d=404"
+}
+
+// firstLetter is the first letter or digit in s.
+func firstLetter(s string) (rune, bool) {
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayName(t *testing.T) {
+	cases := []struct {
+		first, last, format, want string
+	}{
+		{"Ada", "Lovelace", DefaultNameFormat, "Ada Lovelace"},
+		{"Ada", "", DefaultNameFormat, "Ada"},
+		{"", "", DefaultNameFormat, "ada_l"},
+		{"Ada", "Lovelace", "{{ last_name }}, {{first_name}}", "Lovelace, Ada"},
+		{"Ada", "Lovelace", "{{first_name}} (@{{username}})", "Ada (@ada_l)"},
+		{"  ", "", DefaultNameFormat, "ada_l"},
+	}
+	for _, c := range cases {
+		u := &User{Username: "ada_l", FirstName: c.first, LastName: c.last}
+		assert.Equal(t, c.want, u.DisplayName(c.format), c.format)
+	}
+}
+
+func TestValidateNameFormat(t *testing.T) {
+	assert.NoError(t, ValidateNameFormat("{{last_name}}, {{ first_name }}"))
+	assert.Error(t, ValidateNameFormat("{{first_name}} {{email}}"))
+}
+
+func TestInitials(t *testing.T) {
+	assert.Equal(t, "AL", (&User{FirstName: "ada", LastName: "Lovelace"}).Initials())
+	assert.Equal(t, "É", (&User{FirstName: "émile"}).Initials())
+	assert.Equal(t, "A", (&User{Username: "_ada"}).Initials())
+	assert.Equal(t, "", (&User{}).Initials())
+}
+
+func TestAvatarColor_StableForUser(t *testing.T) {
+	u := &User{UserID: "u1", FirstName: "Ada"}
+	color := u.AvatarColor()
+	u.FirstName = "Grace"
+	assert.Equal(t, color, u.AvatarColor())
+	assert.Regexp(t, `^#[0-9A-F]{6}$`, color)
+}
+
+func TestGravatarURL(t *testing.T) {
+	u := &User{Email: " Ada@Example.com "}
+	assert.Equal(t, (&User{Email: "ada@example.com"}).GravatarURL(), u.GravatarURL())
+	assert.Regexp(t, `^https://www\.gravatar\.com/avatar/[0-9a-f]{64}\?d=404$`, u.GravatarURL())
+
+	now := time.Now()
+	u.AnonymizedAt = &now
+	assert.Empty(t, u.GravatarURL())
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Sentinel errors for domain-level error discrimination.
 // Services wrap these so handlers can map to HTTP status codes without leaking infrastructure details.
@@ -10,4 +13,35 @@ var (
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrForbidden    = errors.New("forbidden")
 	ErrBadRequest   = errors.New("bad request")
+	ErrTooMany      = errors.New("too many requests")
+	ErrUnavailable  = errors.New("service unavailable")
+	// ErrPrecondition asks the client to complete a step, such as setting a
+	// new password, before the request can succeed.
+	ErrPrecondition = errors.New("precondition required")
 )
+
+// ErrUnderage refuses a registration below the minimum age. It is a
+// forbidden error that clients can tell apart by its "underage" code.
+var ErrUnderage = fmt.Errorf("below the minimum age: %w", ErrForbidden)
+
+// ErrWeakPassword refuses a new password that breaks the password policy. It
+// is a bad request that clients can tell apart by its "weak_password" code.
+var ErrWeakPassword = fmt.Errorf("password too weak: %w", ErrBadRequest)
+
+// ErrBreachedPassword refuses a new password found in a known data breach. It
+// is a bad request that clients can tell apart by its "breached_password" code.
+var ErrBreachedPassword = fmt.Errorf("password appears in a data breach, choose another: %w", ErrBadRequest)
+
+// ErrUntrustedDevice refuses a sensitive change from a device that has not
+// passed an OTP challenge. It is a forbidden error that clients can tell apart
+// by its "device_verification_required" code.
+var ErrUntrustedDevice = fmt.Errorf("device verification required, verify it with POST /v1/device-verification/request: %w", ErrForbidden)
+
+// ErrTableWideCount is returned for estimates DynamoDB only keeps for a whole
+// table, which inside a tenant would reveal the size of every tenant combined.
+// It is never surfaced over HTTP.
+var ErrTableWideCount = errors.New("count covers every tenant")
+
+// ErrInvalidPushToken is returned by push senders when the provider reports a
+// device token as unregistered or expired. It is never surfaced over HTTP.
+var ErrInvalidPushToken = errors.New("invalid push token")
@@ -17,3 +17,9 @@ type File struct {
 	CreatedAt        time.Time `json:"created" dynamodbav:"created_at"`
 	UpdatedAt        time.Time `json:"updated" dynamodbav:"updated_at"`
 }
+
+// StorageUsage totals a user's enabled uploads.
+type StorageUsage struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Launch allowlist entry kinds. An email entry admits one address, a domain
+// entry every address at that domain, and an invite entry whoever registers
+// with its code.
+const (
+	LaunchEmail  = "email"
+	LaunchDomain = "domain"
+	LaunchInvite = "invite"
+)
+
+// LaunchEntry pre-approves registrations while the launch gate is on.
+type LaunchEntry struct {
+	Kind      string    `json:"kind" dynamodbav:"kind"`
+	Value     string    `json:"value" dynamodbav:"value"` // lowercased email or domain, or the invite code
+	Note      string    `json:"note,omitempty" dynamodbav:"note,omitempty"`
+	MaxUses   int       `json:"max_uses,omitempty" dynamodbav:"max_uses,omitempty"` // invites only; 0 is unlimited
+	Uses      int       `json:"uses" dynamodbav:"uses"`                             // registrations admitted by this entry
+	CreatedBy string    `json:"created_by" dynamodbav:"created_by"`
+	CreatedAt time.Time `json:"created" dynamodbav:"created_at"`
+}
+
+// CreateLaunchEntryRequest is the body for POST /v1/admin/launch/allowlist.
+// An invite without a value gets a generated code.
+type CreateLaunchEntryRequest struct {
+	Kind    string `json:"kind" validate:"required,oneof=email domain invite"`
+	Value   string `json:"value" validate:"required_unless=Kind invite,max=254"`
+	Note    string `json:"note" validate:"max=200"`
+	MaxUses int    `json:"max_uses" validate:"min=0"`
+}
+
+// LaunchStats counts registrations seen by the launch gate since it was
+// first turned on.
+type LaunchStats struct {
+	Enabled  bool           `json:"enabled"`
+	Gated    int            `json:"gated"`    // registrations refused
+	Admitted map[string]int `json:"admitted"` // registrations admitted, by entry kind
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is a direct message between two users. Messages are partitioned by
+// conversation and sorted by send time.
+type Message struct {
+	ConversationID string    `json:"conversation_id" dynamodbav:"conversation_id"`
+	SentAt         int64     `json:"-" dynamodbav:"sent_at"` // unix nanoseconds; sort key
+	MessageID      string    `json:"id" dynamodbav:"message_id"`
+	SenderID       string    `json:"sender_id" dynamodbav:"sender_id"`
+	RecipientID    string    `json:"recipient_id" dynamodbav:"recipient_id"`
+	Body           string    `json:"body" dynamodbav:"body"`
+	Read           bool      `json:"read" dynamodbav:"read"`
+	UnreadFor      string    `json:"-" dynamodbav:"unread_for,omitempty"` // recipient while unread; sparse GSI key
+	CreatedAt      time.Time `json:"created" dynamodbav:"created_at"`
+}
+
+// SendMessageRequest is the body for POST /v1/messages.
+type SendMessageRequest struct {
+	RecipientID string `json:"recipient_id" validate:"required"`
+	Body        string `json:"body" validate:"required,max=4000"`
+}
+
+// UnreadCounts summarises a user's unread messages.
+type UnreadCounts struct {
+	Total    int            `json:"total"`
+	BySender map[string]int `json:"by_sender"`
+}
+
+// ConversationID returns the same ID for a pair of users regardless of argument order.
+func ConversationID(userA, userB string) string {
+	ids := []string{userA, userB}
+	sort.Strings(ids)
+	return strings.Join(ids, "#")
+}
@@ -8,7 +8,89 @@ type Notification struct {
 	DeviceID       *string   `json:"device_id" dynamodbav:"device_id"`
 	TemplateID     *string   `json:"template_id" dynamodbav:"template_id"`
 	Message        string    `json:"message" dynamodbav:"message"`
+	PushMessage    string    `json:"-" dynamodbav:"push_message,omitempty"` // push body when it differs from Message
+	Category       string    `json:"category,omitempty" dynamodbav:"category,omitempty"`
 	Readed         int       `json:"readed" dynamodbav:"readed"` // legacy field name preserved
+	Receipts       []Receipt `json:"-" dynamodbav:"receipts,omitempty"`
+	Status         string    `json:"status" dynamodbav:"status,omitempty"` // empty on legacy rows, treated as sent
+	SendAt         time.Time `json:"send_at" dynamodbav:"send_at"`
+	DueAt          int64     `json:"-" dynamodbav:"due_at,omitempty"` // unix SendAt, present only while scheduled
 	CreatedAt      time.Time `json:"created" dynamodbav:"created_at"`
 	UpdatedAt      time.Time `json:"updated" dynamodbav:"updated_at"`
+	// DeletedAt is set when the recipient dismisses the notification; it is
+	// then hidden from them and purged through the ExpiresAt TTL.
+	DeletedAt *time.Time `json:"-" dynamodbav:"deleted_at,omitempty"`
+	ExpiresAt int64      `json:"-" dynamodbav:"expires_at,omitempty"` // DynamoDB TTL (unix seconds)
+}
+
+// Notification lifecycle states.
+const (
+	NotificationScheduled = "scheduled"
+	NotificationSent      = "sent"
+	NotificationCanceled  = "canceled"
+)
+
+// LocalTimeLayout is the format of wall-clock times read in a user's time
+// zone, such as CreateNotificationRequest.SendAtLocal.
+const LocalTimeLayout = "2006-01-02T15:04"
+
+// CreateNotificationRequest is the body for POST /v1/admin/notifications.
+// Either Message or Template (with Params) must be set. A nil or past SendAt delivers immediately.
+type CreateNotificationRequest struct {
+	UserID   string            `json:"user_id" validate:"required"`
+	Message  string            `json:"message" validate:"required_without=Template,max=2000"`
+	Template string            `json:"template" validate:"max=100"`
+	Params   map[string]string `json:"params"`
+	SendAt   *time.Time        `json:"send_at"`
+	// SendAtLocal schedules by the recipient's clock instead: a
+	// LocalTimeLayout time in their time zone (UTC when they have none).
+	SendAtLocal string `json:"send_at_local" validate:"omitempty,excluded_with=SendAt,datetime=2006-01-02T15:04"`
+}
+
+// UpdateNotificationRequest is the body for PUT /v1/admin/notifications/{id}.
+// Only scheduled notifications can be updated.
+type UpdateNotificationRequest struct {
+	Message *string    `json:"message" validate:"omitempty,min=1,max=2000"`
+	SendAt  *time.Time `json:"send_at"`
+}
+
+// Notification delivery channels.
+const (
+	ChannelInApp = "in_app"
+	ChannelPush  = "push"
+	// ChannelEmail only names template bodies, used by the onboarding
+	// emails; notifications are never delivered by email.
+	ChannelEmail = "email"
+)
+
+// Receipt events recorded per channel.
+const (
+	ReceiptDelivered = "delivered"
+	ReceiptRead      = "read"
+)
+
+// Receipt records that a notification reached a channel or was read there.
+type Receipt struct {
+	Channel string    `json:"channel" dynamodbav:"channel"`
+	Event   string    `json:"event" dynamodbav:"event"`
+	At      time.Time `json:"at" dynamodbav:"at"`
+}
+
+// RecordReceiptRequest is the body for POST /v1/notifications/{id}/receipts.
+type RecordReceiptRequest struct {
+	Channel string `json:"channel" validate:"required,oneof=in_app push"`
+	Event   string `json:"event" validate:"required,oneof=delivered read"`
+}
+
+// ChannelStats holds the first delivery and read times seen on one channel.
+type ChannelStats struct {
+	DeliveredAt *time.Time `json:"delivered_at"`
+	ReadAt      *time.Time `json:"read_at"`
+}
+
+// NotificationStats is the admin view of a notification's receipts.
+type NotificationStats struct {
+	NotificationID string                  `json:"id"`
+	UserID         string                  `json:"user_id"`
+	Channels       map[string]ChannelStats `json:"channels"`
 }
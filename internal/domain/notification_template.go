@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// NotificationTemplate is a named message with {{placeholder}} params and one body per channel.
+type NotificationTemplate struct {
+	Name      string            `json:"name" dynamodbav:"name"`
+	Category  string            `json:"category" dynamodbav:"category"`
+	Bodies    map[string]string `json:"bodies" dynamodbav:"bodies"` // keyed by channel; in_app is required
+	CreatedAt time.Time         `json:"created" dynamodbav:"created_at"`
+	UpdatedAt time.Time         `json:"updated" dynamodbav:"updated_at"`
+}
+
+// NotificationTemplateInput is the body for PUT /v1/admin/notification-templates/{name}.
+type NotificationTemplateInput struct {
+	Category string            `json:"category" validate:"required,max=50"`
+	Bodies   map[string]string `json:"bodies" validate:"required,dive,keys,oneof=in_app push email,endkeys,required,max=2000"`
+}
+
+// CreateNotificationTemplateRequest is the body for POST /v1/admin/notification-templates.
+type CreateNotificationTemplateRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	NotificationTemplateInput
+}
@@ -0,0 +1,130 @@
+package domain
+
+import "time"
+
+// OAuthScopes lists the scopes third-party apps may ask for, with the text
+// the consent screen shows for each. The route policy names which routes
+// each scope opens to OAuth access tokens.
+var OAuthScopes = map[string]string{
+	"profile:read":       "See your profile",
+	"profile:write":      "Update your profile",
+	"files:read":         "See and download your files",
+	"files:write":        "Upload and delete your files",
+	"notifications:read": "See your notifications",
+	"activity:read":      "See your account activity",
+}
+
+// OAuthClient is a third-party app registered by an admin. Confidential
+// clients authenticate with their secret at the token endpoint; public
+// ones (mobile and single-page apps) rely on PKCE alone.
+type OAuthClient struct {
+	ClientID     string    `json:"client_id" dynamodbav:"client_id"`
+	Name         string    `json:"name" dynamodbav:"name"`
+	SecretHash   string    `json:"-" dynamodbav:"secret_hash,omitempty"` // SHA-256 of the secret; empty for public clients
+	RedirectURIs []string  `json:"redirect_uris" dynamodbav:"redirect_uris"`
+	Scopes       []string  `json:"scopes" dynamodbav:"scopes"` // the most the client may be granted
+	Confidential bool      `json:"confidential" dynamodbav:"confidential"`
+	CreatedBy    string    `json:"created_by" dynamodbav:"created_by"`
+	CreatedAt    time.Time `json:"created" dynamodbav:"created_at"`
+}
+
+// CreateOAuthClientRequest is the body for POST /v1/admin/oauth/clients.
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" validate:"required,min=1,max=10,dive,url,max=500"`
+	Scopes       []string `json:"scopes" validate:"required,min=1,dive,required"`
+	Confidential bool     `json:"confidential"`
+}
+
+// OAuthClientCreated is the response for POST /v1/admin/oauth/clients. The
+// secret of a confidential client is only ever shown here.
+type OAuthClientCreated struct {
+	*OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizeRequest carries the parameters of an authorization request
+// (RFC 6749 section 4.1.1 with PKCE, RFC 7636). Only the S256 challenge
+// method is accepted.
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type" validate:"required"`
+	ClientID            string `json:"client_id" validate:"required"`
+	RedirectURI         string `json:"redirect_uri" validate:"required"`
+	Scope               string `json:"scope"` // space-separated; empty asks for every scope of the client
+	State               string `json:"state" validate:"max=500"`
+	CodeChallenge       string `json:"code_challenge" validate:"required,min=43,max=128"`
+	CodeChallengeMethod string `json:"code_challenge_method" validate:"required"`
+}
+
+// AuthorizeDecision is the body for POST /v1/oauth/authorize: the
+// authorization request with the user's answer to it.
+type AuthorizeDecision struct {
+	AuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+// OAuthScope is one scope as shown on the consent screen.
+type OAuthScope struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// OAuthConsent is the response for GET /v1/oauth/authorize: what the
+// consent screen asks the user to approve.
+type OAuthConsent struct {
+	ClientID    string       `json:"client_id"`
+	ClientName  string       `json:"client_name"`
+	Scopes      []OAuthScope `json:"scopes"`
+	RedirectURI string       `json:"redirect_uri"`
+	State       string       `json:"state,omitempty"`
+}
+
+// OAuthTokenRequest carries the form parameters of a token request. The
+// client credentials come from HTTP Basic auth or the form.
+type OAuthTokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string // refresh only: narrows the grant's scopes
+	ClientID     string
+	ClientSecret string
+}
+
+// OAuthToken is a successful token response (RFC 6749 section 5.1).
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// OAuthIntrospection is a token introspection response (RFC 7662). Only
+// Active is set for a token that is not active.
+type OAuthIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// OAuthError is an OAuth error response (RFC 6749 section 5.2). It wraps
+// ErrUnauthorized for "invalid_client" and ErrBadRequest otherwise.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string { return e.Code + ": " + e.Description }
+
+func (e *OAuthError) Unwrap() error {
+	if e.Code == "invalid_client" {
+		return ErrUnauthorized
+	}
+	return ErrBadRequest
+}
@@ -0,0 +1,19 @@
+package domain
+
+// Onboarding emails, in the order they are usually sent. Each is sent to a
+// user at most once; User.OnboardingSent records which went out.
+const (
+	OnboardingWelcome         = "welcome"          // on registration
+	OnboardingConfirmEmail    = "confirm_email"    // email still unconfirmed after ONBOARDING_REMINDER_DAYS
+	OnboardingCompleteProfile = "complete_profile" // profile still incomplete after ONBOARDING_PROFILE_NUDGE_DAYS
+)
+
+// OnboardingTemplate names the notification template whose "email" body
+// replaces the built-in text of an onboarding email.
+func OnboardingTemplate(step string) string {
+	return "onboarding_" + step
+}
+
+// BirthdayTemplate names the notification template of the birthday
+// notification; without it a built-in message is sent.
+const BirthdayTemplate = "birthday"
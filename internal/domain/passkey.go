@@ -0,0 +1,104 @@
+package domain
+
+import "time"
+
+// Verification types holding WebAuthn challenges. A registration challenge
+// is keyed by the user registering; a login challenge, issued before anyone
+// is known, by PasskeyLoginKey of the challenge itself.
+const (
+	VerificationPasskeyRegister = "passkey_register"
+	VerificationPasskeyLogin    = "passkey_login"
+)
+
+// PasskeyLoginKey is the user_id under which a login challenge is stored.
+func PasskeyLoginKey(challenge string) string {
+	return "passkey#" + challenge
+}
+
+// Passkey is a WebAuthn credential registered by a user. CredentialID is the
+// base64url credential ID; PublicKey is its SPKI DER public key.
+type Passkey struct {
+	CredentialID string     `json:"id" dynamodbav:"credential_id"`
+	UserID       string     `json:"user_id" dynamodbav:"user_id"`
+	Name         string     `json:"name" dynamodbav:"name"`
+	PublicKey    []byte     `json:"-" dynamodbav:"public_key"`
+	Algorithm    int        `json:"algorithm" dynamodbav:"algorithm"` // COSE algorithm identifier
+	SignCount    uint32     `json:"-" dynamodbav:"sign_count"`
+	CreatedAt    time.Time  `json:"created" dynamodbav:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used,omitempty" dynamodbav:"last_used_at,omitempty"`
+}
+
+// PasskeyRP names the relying party in WebAuthn options.
+type PasskeyRP struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// PasskeyUser names the account a passkey is created for. ID is the
+// base64url user handle, returned by authenticators on login.
+type PasskeyUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// PasskeyCredentialParam is one accepted key algorithm.
+type PasskeyCredentialParam struct {
+	Type string `json:"type"` // always "public-key"
+	Alg  int    `json:"alg"`
+}
+
+// PasskeyCredentialRef names an existing credential.
+type PasskeyCredentialRef struct {
+	Type string `json:"type"` // always "public-key"
+	ID   string `json:"id"`
+}
+
+// PasskeyCreationOptions is the response for POST
+// /v1/users/me/passkeys/challenge, in the JSON form of
+// PublicKeyCredentialCreationOptions.
+type PasskeyCreationOptions struct {
+	Challenge          string                   `json:"challenge"`
+	RP                 PasskeyRP                `json:"rp"`
+	User               PasskeyUser              `json:"user"`
+	PubKeyCredParams   []PasskeyCredentialParam `json:"pubKeyCredParams"`
+	Timeout            int64                    `json:"timeout"` // milliseconds
+	ExcludeCredentials []PasskeyCredentialRef   `json:"excludeCredentials"`
+	Attestation        string                   `json:"attestation"`
+	ResidentKey        string                   `json:"residentKey"`
+	UserVerification   string                   `json:"userVerification"`
+}
+
+// PasskeyRequestOptions is the response for POST
+// /v1/sessions/webauthn/challenge, in the JSON form of
+// PublicKeyCredentialRequestOptions. It names no credentials, so the
+// authenticator offers the user's discoverable passkeys.
+type PasskeyRequestOptions struct {
+	Challenge        string `json:"challenge"`
+	RPID             string `json:"rpId"`
+	Timeout          int64  `json:"timeout"` // milliseconds
+	UserVerification string `json:"userVerification"`
+}
+
+// RegisterPasskeyRequest is the body for POST /v1/users/me/passkeys: the
+// registration response's id and the base64url fields of its response
+// object as produced by PublicKeyCredential.toJSON.
+type RegisterPasskeyRequest struct {
+	Name               string `json:"name" validate:"max=64"`
+	ID                 string `json:"id" validate:"required,max=1024"`
+	ClientDataJSON     string `json:"client_data_json" validate:"required"`
+	AuthenticatorData  string `json:"authenticator_data" validate:"required"`
+	PublicKey          string `json:"public_key" validate:"required"`
+	PublicKeyAlgorithm int    `json:"public_key_algorithm" validate:"required"`
+}
+
+// PasskeyAssertion is the body for POST /v1/sessions/webauthn: an
+// authentication response's id and base64url response fields.
+type PasskeyAssertion struct {
+	ID                string  `json:"id" validate:"required,max=1024"`
+	ClientDataJSON    string  `json:"client_data_json" validate:"required"`
+	AuthenticatorData string  `json:"authenticator_data" validate:"required"`
+	Signature         string  `json:"signature" validate:"required"`
+	UserHandle        string  `json:"user_handle"`
+	DeviceUUID        *string `json:"device_uuid"`
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// PlanFree is seeded at startup, cannot be deleted, and applies to users
+// without a plan of their own.
+const PlanFree = "free"
+
+// Entitlements are the limits a plan grants its users. A zero limit is
+// unlimited.
+type Entitlements struct {
+	StorageBytes  int64 `json:"storage_bytes" dynamodbav:"storage_bytes" validate:"min=0"`   // total size of enabled uploads
+	MaxDevices    int   `json:"max_devices" dynamodbav:"max_devices" validate:"min=0"`       // enabled devices
+	DailyRequests int64 `json:"daily_requests" dynamodbav:"daily_requests" validate:"min=0"` // API requests per UTC day, with usage metering on
+}
+
+// Plan is a billing plan stored in the plans table.
+type Plan struct {
+	Name         string       `json:"name" dynamodbav:"name"`
+	Description  string       `json:"description" dynamodbav:"description"`
+	Entitlements Entitlements `json:"entitlements" dynamodbav:"entitlements"`
+	CreatedAt    time.Time    `json:"created" dynamodbav:"created_at"`
+	UpdatedAt    time.Time    `json:"updated" dynamodbav:"updated_at"`
+}
+
+// PlanInput is the body for PUT /v1/admin/plans/{name}.
+type PlanInput struct {
+	Description  string       `json:"description" validate:"max=200"`
+	Entitlements Entitlements `json:"entitlements"`
+}
+
+// CreatePlanRequest is the body for POST /v1/admin/plans.
+type CreatePlanRequest struct {
+	Name string `json:"name" validate:"required,max=50,alphanum"`
+	PlanInput
+}
+
+// AssignPlanRequest is the body for PUT /v1/admin/users/{id}/plan.
+type AssignPlanRequest struct {
+	Plan string `json:"plan" validate:"required,max=50"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// RetentionReport lists, for each retention rule, how many items are past
+// their retention period. A dry run only counts them; otherwise they were
+// purged.
+type RetentionReport struct {
+	Enforced    bool            `json:"enforced"` // RETENTION_ENFORCE; when false the purge job does not run
+	DryRun      bool            `json:"dry_run"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Rules       []RetentionRule `json:"rules"`
+}
+
+// RetentionRule is one rule's result. Cutoff is omitted for rules with
+// RetentionDays 0, which keep their items forever.
+type RetentionRule struct {
+	Name          string     `json:"name"`
+	RetentionDays int        `json:"retention_days"`
+	Cutoff        *time.Time `json:"cutoff,omitempty"`
+	Expired       int        `json:"expired"`
+}
@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
 // Role name constants — used for RBAC checks across the application.
 const (
 	RoleAdmin = "Admin"
@@ -11,3 +17,54 @@ const (
 	AuthProviderLocal  = "local"
 	AuthProviderGoogle = "google"
 )
+
+// Role is an assignable role stored in the roles table. RoleAdmin and RoleUser
+// are seeded at startup and cannot be deleted.
+type Role struct {
+	Name        string    `json:"name" dynamodbav:"name"`
+	Description string    `json:"description" dynamodbav:"description"`
+	CreatedAt   time.Time `json:"created" dynamodbav:"created_at"`
+	UpdatedAt   time.Time `json:"updated" dynamodbav:"updated_at"`
+}
+
+// RoleInput is the body for PUT /v1/roles/{name}.
+type RoleInput struct {
+	Description string `json:"description" validate:"max=200"`
+}
+
+// CreateRoleRequest is the body for POST /v1/roles.
+type CreateRoleRequest struct {
+	Name string `json:"name" validate:"required,max=50,alphanum"`
+	RoleInput
+}
+
+// ScopeAdmin is carried by admins' tokens on top of the OAuth scopes, for
+// routes that check scopes rather than roles.
+const ScopeAdmin = "admin"
+
+// RoleScopes returns the scopes of a user's own token for role: every OAuth
+// scope, and ScopeAdmin for RoleAdmin.
+func RoleScopes(role string) []string {
+	scopes := make([]string, 0, len(OAuthScopes)+1)
+	for scope := range OAuthScopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	if role == RoleAdmin {
+		scopes = append(scopes, ScopeAdmin)
+	}
+	return scopes
+}
+
+// FilterScopes returns the requested scopes that role holds, so a token
+// minted for an integration never does more than its user could.
+func FilterScopes(requested []string, role string) []string {
+	held := RoleScopes(role)
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		if slices.Contains(held, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
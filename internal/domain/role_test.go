@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleScopes(t *testing.T) {
+	assert.NotContains(t, RoleScopes(RoleUser), ScopeAdmin)
+	assert.Contains(t, RoleScopes(RoleAdmin), ScopeAdmin)
+	assert.Len(t, RoleScopes("Support"), len(OAuthScopes))
+}
+
+func TestFilterScopes(t *testing.T) {
+	assert.Equal(t, []string{"files:read"}, FilterScopes([]string{"files:read", "admin", "unknown"}, RoleUser))
+	assert.Equal(t, []string{"files:read", "admin"}, FilterScopes([]string{"files:read", "admin"}, RoleAdmin))
+	assert.Empty(t, FilterScopes(nil, RoleAdmin))
+}
@@ -9,7 +9,27 @@ type Session struct {
 	Enable           bool      `json:"enable" dynamodbav:"enable"`
 	RefreshToken     string    `json:"-" dynamodbav:"refresh_token"`
 	RefreshExpiresAt int64     `json:"-" dynamodbav:"refresh_expires_at"`
+	RememberToken    string    `json:"-" dynamodbav:"remember_token,omitempty"` // set only for remember-me logins on trusted devices
+	RememberUntil    int64     `json:"-" dynamodbav:"remember_until,omitempty"`
+	UserAgent        string    `json:"user_agent,omitempty" dynamodbav:"user_agent,omitempty"` // client of the last sign-in or refresh, as it describes itself
+	AppVersion       string    `json:"app_version,omitempty" dynamodbav:"app_version,omitempty"`
+	IP               string    `json:"ip,omitempty" dynamodbav:"ip,omitempty"`
+	Country          string    `json:"country,omitempty" dynamodbav:"country,omitempty"`
+	ApprovalToken    string    `json:"-" dynamodbav:"approval_token,omitempty"` // set while a high-risk login waits for approval by email
+	ApprovalUntil    int64     `json:"-" dynamodbav:"approval_until,omitempty"`
+	ClientID         string    `json:"client_id,omitempty" dynamodbav:"client_id,omitempty"` // OAuth client the session was granted to; empty for first-party logins
+	Scopes           []string  `json:"scopes,omitempty" dynamodbav:"scopes,omitempty"`
+	AuthCode         string    `json:"-" dynamodbav:"auth_code,omitempty"` // set while an OAuth authorization code waits to be exchanged
+	AuthCodeUntil    int64     `json:"-" dynamodbav:"auth_code_until,omitempty"`
+	CodeChallenge    string    `json:"-" dynamodbav:"code_challenge,omitempty"`
+	RedirectURI      string    `json:"-" dynamodbav:"redirect_uri,omitempty"`
 	CreatedAt        time.Time `json:"created" dynamodbav:"created_at"`
 	UpdatedAt        time.Time `json:"updated" dynamodbav:"updated_at"`
 	User             *User     `json:"user,omitempty" dynamodbav:"-"`
 }
+
+// PendingApproval reports whether s is a high-risk login that has not been
+// approved. One not approved by ApprovalUntil never activates.
+func (s *Session) PendingApproval() bool {
+	return !s.Enable && s.ApprovalToken != ""
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// ReadOnlyMode is the runtime switch that makes the API refuse writes while
+// reads keep working, e.g. during a backup, a migration or an incident.
+type ReadOnlyMode struct {
+	Enabled   bool      `json:"enabled" dynamodbav:"enabled"`
+	Reason    string    `json:"reason,omitempty" dynamodbav:"reason,omitempty"` // shown to clients refused a write
+	UpdatedBy string    `json:"updated_by,omitempty" dynamodbav:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+	// Forced is set when READ_ONLY is on; the mode then cannot be switched off
+	// at runtime.
+	Forced bool `json:"forced" dynamodbav:"-"`
+}
+
+// SetReadOnlyRequest is the body for PUT /v1/admin/read-only.
+type SetReadOnlyRequest struct {
+	Enabled *bool  `json:"enabled" validate:"required"`
+	Reason  string `json:"reason" validate:"max=200"`
+}
@@ -1,10 +1,19 @@
 package domain
 
 type Status struct {
-	StatusID    string `json:"id" dynamodbav:"status_id"`
-	Description string `json:"description" dynamodbav:"description"`
+	StatusID    string            `json:"id" dynamodbav:"status_id"`
+	Code        string            `json:"code" dynamodbav:"code"` // stable identifier, unique across statuses
+	Description string            `json:"description" dynamodbav:"description"`
+	SortOrder   int               `json:"sort_order" dynamodbav:"sort_order"`
+	Labels      map[string]string `json:"labels,omitempty" dynamodbav:"labels,omitempty"` // language code -> label
+	Label       string            `json:"label,omitempty" dynamodbav:"-"`                 // Labels[lang] when ?lang= is given
+	Enable      bool              `json:"enable" dynamodbav:"enable"`
 }
 
 type StatusInput struct {
-	Description string `json:"description" validate:"required"`
+	Code        string            `json:"code" validate:"required,max=64"`
+	Description string            `json:"description" validate:"required"`
+	SortOrder   int               `json:"sort_order"`
+	Labels      map[string]string `json:"labels" validate:"omitempty,dive,keys,min=2,max=10,endkeys,required,max=200"`
+	Enable      *bool             `json:"enable,omitempty"` // set true to restore a soft-deleted status
 }
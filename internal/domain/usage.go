@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// Usage is one user's metered API consumption on one UTC day.
+type Usage struct {
+	UserID   string `json:"user_id" dynamodbav:"user_id"`
+	Day      string `json:"day" dynamodbav:"day"` // yyyy-mm-dd, UTC
+	Requests int64  `json:"requests" dynamodbav:"requests"`
+	BytesIn  int64  `json:"bytes_in" dynamodbav:"bytes_in"`   // request bodies uploaded
+	BytesOut int64  `json:"bytes_out" dynamodbav:"bytes_out"` // response bodies downloaded
+}
+
+// UsageQuota caps one day of Usage. A zero limit is unlimited.
+type UsageQuota struct {
+	Requests int64 `json:"requests"`
+	BytesIn  int64 `json:"bytes_in"`
+	BytesOut int64 `json:"bytes_out"`
+}
+
+// Exceeded reports whether u, which includes the request being admitted,
+// goes past q. Byte limits are checked against the bytes already used, so the
+// request that crosses one still completes.
+func (q UsageQuota) Exceeded(u Usage) bool {
+	return q.Requests > 0 && u.Requests > q.Requests ||
+		q.BytesIn > 0 && u.BytesIn >= q.BytesIn ||
+		q.BytesOut > 0 && u.BytesOut >= q.BytesOut
+}
+
+// UsageReport is the response of GET /v1/users/me/usage.
+type UsageReport struct {
+	Usage
+	Quota    UsageQuota `json:"quota"`
+	ResetsAt time.Time  `json:"resets_at"` // next UTC midnight
+}
@@ -1,6 +1,14 @@
 package domain
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" // time zone names validate and resolve without a system zoneinfo
+)
 
 type User struct {
 	UserID         string     `json:"id" dynamodbav:"user_id"`
@@ -9,6 +17,10 @@ type User struct {
 	Phone          *string    `json:"phone" dynamodbav:"phone"`
 	PasswordHash   string     `json:"-" dynamodbav:"password_hash"`
 	Role           string     `json:"role" dynamodbav:"role"`
+	Plan           string     `json:"plan,omitempty" dynamodbav:"plan,omitempty"` // billing plan; empty means domain.PlanFree
+	StripeCustomer string     `json:"-" dynamodbav:"stripe_customer_id,omitempty"`
+	BillingEventAt int64      `json:"-" dynamodbav:"billing_event_at,omitempty"` // unix time of the last subscription event applied
+	InviteCode     string     `json:"-" dynamodbav:"invite_code,omitempty"`      // launch invite the account registered with
 	FirstName      string     `json:"first_name" dynamodbav:"first_name"`
 	LastName       string     `json:"last_name" dynamodbav:"last_name"`
 	Birthday       time.Time  `json:"birthday" dynamodbav:"birthday"`
@@ -17,30 +29,230 @@ type User struct {
 	PhoneConfirmed bool       `json:"phone_confirmed" dynamodbav:"phone_confirmed"`
 	AuthProvider   string     `json:"auth_provider,omitempty" dynamodbav:"auth_provider"` // "local" | "google"
 	GoogleSub      string     `json:"-"                       dynamodbav:"google_sub"`
+	EmailKey       string     `json:"-" dynamodbav:"email_key,omitempty"`                           // NormalizeEmail(Email); email_key-index
+	UsernameKey    string     `json:"-" dynamodbav:"username_key,omitempty"`                        // NormalizeUsername(Username); username_key-index
+	PublicProfile  bool       `json:"public_profile" dynamodbav:"public_profile"`                   // opt-in: GET /v1/public/users/{username}
+	OnboardOptOut  bool       `json:"onboarding_opt_out" dynamodbav:"onboarding_opt_out,omitempty"` // no onboarding reminder or nudge emails
+	OnboardingSent []string   `json:"-" dynamodbav:"onboarding_sent,stringset,omitempty"`           // onboarding emails already sent (domain.Onboarding*)
+	PasswordChange bool       `json:"-" dynamodbav:"password_change,omitempty"`                     // provisioned by an admin; the first login must set a password
 	Enable         int        `json:"enable" dynamodbav:"enable"`
 	DeletedAt      *time.Time `json:"deleted_at,omitempty" dynamodbav:"deleted_at"`
+	AnonymizedAt   *time.Time `json:"anonymized_at,omitempty" dynamodbav:"anonymized_at,omitempty"` // personal data scrubbed after the deletion grace period
 	CreatedAt      time.Time  `json:"created" dynamodbav:"created_at"`
 	UpdatedAt      time.Time  `json:"updated" dynamodbav:"updated_at"`
+	Timezone       string     `json:"timezone,omitempty" dynamodbav:"timezone,omitempty"` // IANA name, e.g. "Europe/Madrid"; empty means UTC
+	Locale         string     `json:"locale,omitempty" dynamodbav:"locale,omitempty"`     // BCP 47 tag, e.g. "pt-BR"
+	// Metadata holds deployment-specific profile fields, kept as a DynamoDB
+	// map (see ValidateMetadata).
+	Metadata map[string]string `json:"metadata,omitempty" dynamodbav:"metadata,omitempty"`
+	// BirthdayGreeted is the year of the last birthday notification.
+	BirthdayGreeted string `json:"-" dynamodbav:"birthday_greeted,omitempty"`
+}
+
+// Limits on User.Metadata.
+const (
+	MaxMetadataKeys     = 50
+	MaxMetadataValueLen = 1000
+)
+
+var metadataKey = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// ValidateMetadata checks m against the metadata limits: at most
+// MaxMetadataKeys keys of lowercase letters, digits and underscores starting
+// with a letter (40 characters at most), each with a value of at most
+// MaxMetadataValueLen bytes. Failures wrap ErrBadRequest.
+func ValidateMetadata(m map[string]string) error {
+	if len(m) > MaxMetadataKeys {
+		return fmt.Errorf("metadata has more than %d keys: %w", MaxMetadataKeys, ErrBadRequest)
+	}
+	for k, v := range m {
+		if !metadataKey.MatchString(k) {
+			return fmt.Errorf("metadata key %q must be lowercase letters, digits and underscores: %w", k, ErrBadRequest)
+		}
+		if len(v) > MaxMetadataValueLen {
+			return fmt.Errorf("metadata value of %q is longer than %d bytes: %w", k, MaxMetadataValueLen, ErrBadRequest)
+		}
+	}
+	return nil
+}
+
+// NormalizeEmail returns the lookup key for email: trimmed and lowercased.
+// With foldGmail, dots and any +suffix are dropped from Gmail local parts, so
+// "J.Doe+news@googlemail.com" and "jdoe@gmail.com" collide.
+func NormalizeEmail(email string, foldGmail bool) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, host, ok := strings.Cut(email, "@")
+	if !ok || !foldGmail || (host != "gmail.com" && host != "googlemail.com") {
+		return email
+	}
+	local, _, _ = strings.Cut(local, "+")
+	return strings.ReplaceAll(local, ".", "") + "@gmail.com"
+}
+
+// TombstoneEmail replaces the email of an anonymized user. The hash of the
+// lookup key still tells analytics whether two deleted accounts shared an
+// address, but it is not a secret: anyone holding a candidate address can
+// compute it.
+func TombstoneEmail(emailKey string) string {
+	sum := sha256.Sum256([]byte(emailKey))
+	return hex.EncodeToString(sum[:16]) + "@deleted.invalid"
+}
+
+// MissingProfileFields lists, by their JSON names, the profile fields u has
+// not filled in among first_name, last_name, birthday and phone. The profile
+// is complete when none are missing.
+func (u *User) MissingProfileFields() []string {
+	var missing []string
+	if strings.TrimSpace(u.FirstName) == "" {
+		missing = append(missing, "first_name")
+	}
+	if strings.TrimSpace(u.LastName) == "" {
+		missing = append(missing, "last_name")
+	}
+	if u.Birthday.IsZero() {
+		missing = append(missing, "birthday")
+	}
+	if u.Phone == nil || strings.TrimSpace(*u.Phone) == "" {
+		missing = append(missing, "phone")
+	}
+	return missing
+}
+
+// Location is u's time zone, UTC when it has none or it is not a known IANA
+// name.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// AgeOn is the age in whole years on day of someone born on birthday.
+func AgeOn(birthday, day time.Time) int {
+	age := day.Year() - birthday.Year()
+	if day.Month() < birthday.Month() || (day.Month() == birthday.Month() && day.Day() < birthday.Day()) {
+		age--
+	}
+	return age
+}
+
+// IsBirthday reports whether day is u's birthday; those born on February 29
+// celebrate on February 28 in other years.
+func (u *User) IsBirthday(day time.Time) bool {
+	if u.Birthday.IsZero() {
+		return false
+	}
+	month, date := u.Birthday.Month(), u.Birthday.Day()
+	if month == time.February && date == 29 && !isLeap(day.Year()) {
+		date = 28
+	}
+	return day.Month() == month && day.Day() == date
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// NormalizeUsername returns the lookup key for username: trimmed and lowercased.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// UserFilter narrows admin user listings and exports. Zero values match
+// everything. CreatedFrom and CreatedTo are inclusive YYYY-MM-DD bounds (UTC).
+type UserFilter struct {
+	Role           string
+	Enable         *int
+	EmailConfirmed *bool
+	CreatedFrom    string
+	CreatedTo      string
+	Ascending      bool // list oldest first; exports are unordered
 }
 
 type CreateUserRequest struct {
 	Username   string  `json:"username" validate:"required"`
-	Password   string  `json:"password" validate:"required,min=8,max=72"`
+	Password   string  `json:"password" validate:"required,max=72"`
 	Email      string  `json:"email" validate:"required,email"`
 	Phone      *string `json:"phone"`
 	FirstName  string  `json:"first_name" validate:"required"`
 	LastName   string  `json:"last_name" validate:"required"`
-	Birthday   string  `json:"birthday"` // expected format: YYYY-MM-DD
+	Birthday   string  `json:"birthday"` // expected format: YYYY-MM-DD; required while a minimum age is set
 	DeviceUUID *string `json:"device_uuid"`
+	InviteCode string  `json:"invite_code" validate:"max=64"` // required to register while the launch gate is on, unless the email is allowlisted
 }
 
-type UpdateUserRequest struct {
-	Username  *string `json:"username"`
-	Email     *string `json:"email" validate:"omitempty,email"`
+// ProvisionUserRequest is the body for POST /v1/admin/users. The account is
+// created with a temporary password, emailed to it, that the first login must
+// replace.
+type ProvisionUserRequest struct {
+	Username  string  `json:"username" validate:"required"`
+	Email     string  `json:"email" validate:"required,email"`
 	Phone     *string `json:"phone"`
-	FirstName *string `json:"first_name"`
-	LastName  *string `json:"last_name"`
-	Birthday  *string `json:"birthday"` // expected format: YYYY-MM-DD
-	Role      *string `json:"role"`
-	Enable    *int    `json:"enable"` // 1 = enabled, 0 = disabled
+	FirstName string  `json:"first_name" validate:"required"`
+	LastName  string  `json:"last_name" validate:"required"`
+	Role      string  `json:"role"` // defaults to RoleUser
+}
+
+type UpdateUserRequest struct {
+	Username      *string `json:"username"`
+	Email         *string `json:"email" validate:"omitempty,email"`
+	Phone         *string `json:"phone"`
+	FirstName     *string `json:"first_name"`
+	LastName      *string `json:"last_name"`
+	Birthday      *string `json:"birthday"` // expected format: YYYY-MM-DD
+	Role          *string `json:"role"`
+	Enable        *int    `json:"enable"` // 1 = enabled, 0 = disabled
+	PublicProfile *bool   `json:"public_profile"`
+	OnboardOptOut *bool   `json:"onboarding_opt_out"` // stops the onboarding reminder and nudge emails
+	// Timezone is an IANA zone name and Locale a BCP 47 tag; "" clears them.
+	Timezone *string `json:"timezone" validate:"omitempty,timezone"`
+	Locale   *string `json:"locale" validate:"omitempty,bcp47_language_tag"`
+	// Metadata is merged into the user's metadata; a null value removes the
+	// key. Users may only set the keys their deployment allows them.
+	Metadata map[string]*string `json:"metadata"`
+}
+
+// ChangeRoleRequest is the body for PUT /v1/admin/users/{id}/role.
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,max=50"`
+	// RevokeSessions logs the user out everywhere so JWTs carrying the old role stop refreshing.
+	RevokeSessions bool `json:"revoke_sessions"`
+}
+
+// Bulk user actions accepted by POST /v1/admin/users/bulk.
+const (
+	BulkDisable = "disable"
+	BulkEnable  = "enable"
+	BulkDelete  = "delete"
+	BulkSetRole = "set_role"
+)
+
+// BulkUserRequest is the body for POST /v1/admin/users/bulk. Role is required
+// for set_role and ignored otherwise.
+type BulkUserRequest struct {
+	Action  string   `json:"action" validate:"required,oneof=disable enable delete set_role"`
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=100,dive,required"`
+	Role    string   `json:"role" validate:"max=50"`
+}
+
+// BulkUserResult reports the outcome of a bulk action for one user.
+type BulkUserResult struct {
+	UserID string `json:"user_id"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+}
+
+// UserOverview gathers what support needs to investigate an account.
+type UserOverview struct {
+	User                *User
+	Devices             []Device
+	ActiveSessions      []Session
+	Storage             StorageUsage
+	NotificationsSent   int
+	NotificationsUnread int
+	RecentLogins        []Activity
 }
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	cases := []struct {
+		in        string
+		foldGmail bool
+		want      string
+	}{
+		{"  Alice@X.com ", false, "alice@x.com"},
+		{"J.Doe+news@Gmail.com", false, "j.doe+news@gmail.com"},
+		{"J.Doe+news@Gmail.com", true, "jdoe@gmail.com"},
+		{"j.doe@googlemail.com", true, "jdoe@gmail.com"},
+		{"j.doe+x@example.com", true, "j.doe+x@example.com"},
+		{"not-an-email", true, "not-an-email"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, NormalizeEmail(c.in, c.foldGmail), c.in)
+	}
+}
+
+func TestNormalizeUsername(t *testing.T) {
+	assert.Equal(t, "alice", NormalizeUsername(" Alice "))
+}
+
+func TestLocation(t *testing.T) {
+	assert.Equal(t, "Europe/Madrid", (&User{Timezone: "Europe/Madrid"}).Location().String())
+	assert.Equal(t, time.UTC, (&User{}).Location())
+	assert.Equal(t, time.UTC, (&User{Timezone: "Mars/Olympus"}).Location())
+}
+
+func TestAgeOn(t *testing.T) {
+	born := time.Date(2010, 6, 15, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 12, AgeOn(born, time.Date(2023, 6, 14, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 13, AgeOn(born, time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestIsBirthday_LeapDay(t *testing.T) {
+	u := &User{Birthday: time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC)}
+	assert.True(t, u.IsBirthday(time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, u.IsBirthday(time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, u.IsBirthday(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, (&User{}).IsBirthday(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -1,11 +1,16 @@
 package domain
 
-// UserVerification stores OTP and email confirmation tokens.
-// PK: user_id, SK: type ("otp" | "email").
+// UserVerification stores OTP and email confirmation tokens. Only an HMAC of
+// the code is kept, so reading the table does not reveal live codes. WebAuthn
+// challenges, which are not secret, are kept as issued in Challenge.
+// PK: user_id, SK: type ("otp" | "email" | "passkey_register" | "passkey_login").
 // ExpiresAt is a Unix timestamp used as DynamoDB TTL.
 type UserVerification struct {
 	UserID    string `json:"user_id" dynamodbav:"user_id"`
 	Type      string `json:"type" dynamodbav:"type"` // "otp" | "email"
-	Code      string `json:"code" dynamodbav:"code"`
-	ExpiresAt int64  `json:"expires_at" dynamodbav:"expires_at"` // TTL (Unix seconds)
+	CodeHash  string `json:"-" dynamodbav:"code_hash,omitempty"`
+	Code      string `json:"-" dynamodbav:"code,omitempty"`                // plaintext; only on rows written before codes were hashed
+	Challenge string `json:"-" dynamodbav:"challenge,omitempty"`           // WebAuthn challenges only
+	CreatedAt int64  `json:"created_at" dynamodbav:"created_at,omitempty"` // Unix seconds; 0 on rows written before it was recorded
+	ExpiresAt int64  `json:"expires_at" dynamodbav:"expires_at"`           // TTL (Unix seconds)
 }
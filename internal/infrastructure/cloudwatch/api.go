@@ -0,0 +1,101 @@
+// Package cloudwatch ships log lines to CloudWatch Logs. It speaks the
+// service's JSON protocol directly, signed with the SDK's SigV4 signer, since
+// it needs only two operations.
+package cloudwatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// APIError is a CloudWatch Logs error response.
+type APIError struct {
+	Status  int
+	Code    string // e.g. "ResourceNotFoundException"
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("cloudwatch logs: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// logsAPI makes signed CloudWatch Logs calls.
+type logsAPI struct {
+	http     *http.Client
+	endpoint string
+	region   string
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+}
+
+// newLogsAPI calls the regional endpoint, or endpoint when set (LocalStack).
+func newLogsAPI(awsCfg aws.Config, endpoint string) *logsAPI {
+	if endpoint == "" {
+		endpoint = "https://logs." + awsCfg.Region + ".amazonaws.com"
+	}
+	return &logsAPI{
+		http:     &http.Client{Timeout: 10 * time.Second},
+		endpoint: strings.TrimRight(endpoint, "/") + "/",
+		region:   awsCfg.Region,
+		creds:    awsCfg.Credentials,
+		signer:   v4.NewSigner(),
+	}
+}
+
+// call runs action (e.g. "PutLogEvents") with the JSON body in.
+func (a *logsAPI) call(ctx context.Context, action string, in any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := a.signedRequest(ctx, action, body)
+	if err != nil {
+		return err
+	}
+	res, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("cloudwatch logs %s: %w", action, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	var e struct {
+		Type    string `json:"__type"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(res.Body).Decode(&e)
+	// __type may be namespaced: "com.amazonaws.logs#ResourceNotFoundException".
+	code := e.Type[strings.LastIndex(e.Type, "#")+1:]
+	return &APIError{Status: res.StatusCode, Code: code, Message: e.Message}
+}
+
+func (a *logsAPI) signedRequest(ctx context.Context, action string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "Logs_20140328."+action)
+	creds, err := a.creds.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cloudwatch logs credentials: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	if err := a.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "logs", a.region, time.Now()); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
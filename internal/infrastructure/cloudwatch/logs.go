@@ -0,0 +1,179 @@
+package cloudwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
+)
+
+// PutLogEvents accepts at most maxBatchEvents events and maxBatchBytes per
+// call, each event counting eventOverhead bytes on top of its message.
+const (
+	maxBatchEvents = 10000
+	maxBatchBytes  = 1 << 20
+	eventOverhead  = 26
+)
+
+// maxPending bounds the events held while CloudWatch is unreachable; newer
+// lines are dropped beyond it.
+const maxPending = 5 * maxBatchEvents
+
+// finalFlushTimeout bounds the last flush, which runs after shutdown began.
+const finalFlushTimeout = 5 * time.Second
+
+type logEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+// LogWriter buffers the lines written to it and sends them to one CloudWatch
+// Logs stream every interval, plus once more on shutdown. Every Write is one
+// event, so callers write whole lines.
+type LogWriter struct {
+	api      *logsAPI
+	group    string
+	stream   string
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending []logEvent
+	dropped int
+}
+
+// NewLogWriter creates the ACCESS_LOG_STREAM in ACCESS_LOG_GROUP, which must
+// already exist, and flushes to it in a background task until ctx ends.
+func NewLogWriter(ctx context.Context, cfg *config.Config) (*LogWriter, error) {
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.AWSRegion)}
+	if cfg.AWSAccessKeyID != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AWSAccessKeyID, cfg.AWSSecretKey, ""),
+		))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS config for CloudWatch Logs: %w", err)
+	}
+	stream := cfg.AccessLogStream
+	if stream == "" {
+		if stream, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("access log stream: %w", err)
+		}
+	}
+	w := &LogWriter{api: newLogsAPI(awsCfg, cfg.AWSEndpointURL), group: cfg.AccessLogGroup, stream: stream, interval: cfg.AccessLogFlush}
+	if err := w.createStream(ctx); err != nil {
+		return nil, err
+	}
+	lifecycle.Go(ctx, "cloudwatch-logs", w.run)
+	return w, nil
+}
+
+func (w *LogWriter) createStream(ctx context.Context) error {
+	err := w.api.call(ctx, "CreateLogStream", map[string]string{"logGroupName": w.group, "logStreamName": w.stream})
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == "ResourceAlreadyExistsException" {
+		return nil
+	}
+	return err
+}
+
+// Write queues p as one event. It never fails; lines beyond maxPending are
+// counted and reported on the next flush.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) >= maxPending {
+		w.dropped++
+		return len(p), nil
+	}
+	w.pending = append(w.pending, logEvent{Timestamp: time.Now().UnixMilli(), Message: strings.TrimSuffix(string(p), "\n")})
+	return len(p), nil
+}
+
+func (w *LogWriter) run(ctx context.Context) {
+	tick := time.NewTicker(w.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			w.flush(ctx)
+		case <-ctx.Done():
+			// ctx ends when shutdown starts, after the HTTP servers have
+			// drained, so this flush sends the last requests.
+			final, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalFlushTimeout)
+			w.flush(final)
+			cancel()
+			return
+		}
+	}
+}
+
+// flush sends the pending events in batches. A failed batch goes back to the
+// front of the queue for the next flush.
+func (w *LogWriter) flush(ctx context.Context) {
+	if n := w.takeDropped(); n > 0 {
+		slog.Warn("cloudwatch logs: dropped lines while the buffer was full", "count", n)
+	}
+	for {
+		batch := w.take()
+		if len(batch) == 0 {
+			return
+		}
+		err := w.api.call(ctx, "PutLogEvents", putLogEventsInput{LogGroupName: w.group, LogStreamName: w.stream, LogEvents: batch})
+		if err != nil {
+			slog.Warn("cloudwatch logs: put events failed", "error", err, "events", len(batch))
+			w.requeue(batch)
+			return
+		}
+	}
+}
+
+type putLogEventsInput struct {
+	LogGroupName  string     `json:"logGroupName"`
+	LogStreamName string     `json:"logStreamName"`
+	LogEvents     []logEvent `json:"logEvents"`
+}
+
+// take removes the oldest events that fit in one PutLogEvents call.
+func (w *LogWriter) take() []logEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, size := 0, 0
+	for n < len(w.pending) && n < maxBatchEvents {
+		size += len(w.pending[n].Message) + eventOverhead
+		if size > maxBatchBytes && n > 0 {
+			break
+		}
+		n++
+	}
+	batch := w.pending[:n:n]
+	w.pending = w.pending[n:]
+	return batch
+}
+
+func (w *LogWriter) requeue(batch []logEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(batch, w.pending...)
+	if over := len(w.pending) - maxPending; over > 0 {
+		w.pending = w.pending[:maxPending]
+		w.dropped += over
+	}
+}
+
+func (w *LogWriter) takeDropped() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := w.dropped
+	w.dropped = 0
+	return n
+}
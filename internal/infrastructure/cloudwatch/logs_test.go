@@ -0,0 +1,96 @@
+package cloudwatch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLogs records the calls it receives and fails PutLogEvents while failing is set.
+type fakeLogs struct {
+	mu      sync.Mutex
+	calls   []string
+	events  []string
+	failing bool
+}
+
+func (f *fakeLogs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "Logs_20140328.")
+	f.calls = append(f.calls, action)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	switch {
+	case action == "CreateLogStream":
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.logs#ResourceAlreadyExistsException","message":"exists"}`))
+	case f.failing:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"__type":"ServiceUnavailableException","message":"try later"}`))
+	default:
+		var in putLogEventsInput
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		for _, e := range in.LogEvents {
+			f.events = append(f.events, e.Message)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}
+}
+
+func newTestWriter(t *testing.T, fake *fakeLogs) *LogWriter {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	awsCfg := aws.Config{Region: "us-east-1", Credentials: credentials.NewStaticCredentialsProvider("key", "secret", "")}
+	w := &LogWriter{api: newLogsAPI(awsCfg, srv.URL), group: "access", stream: "host-1"}
+	require.NoError(t, w.createStream(t.Context()))
+	return w
+}
+
+func TestLogWriter_FlushSendsLinesInOrder(t *testing.T) {
+	fake := &fakeLogs{}
+	w := newTestWriter(t, fake)
+
+	_, _ = w.Write([]byte("one\n"))
+	_, _ = w.Write([]byte("two\n"))
+	w.flush(t.Context())
+
+	assert.Equal(t, []string{"CreateLogStream", "PutLogEvents"}, fake.calls)
+	assert.Equal(t, []string{"one", "two"}, fake.events)
+	assert.Empty(t, w.take())
+}
+
+func TestLogWriter_FailedBatchIsRetried(t *testing.T) {
+	fake := &fakeLogs{failing: true}
+	w := newTestWriter(t, fake)
+	_, _ = w.Write([]byte("one\n"))
+	w.flush(t.Context())
+	_, _ = w.Write([]byte("two\n"))
+
+	fake.failing = false
+	w.flush(t.Context())
+
+	assert.Equal(t, []string{"one", "two"}, fake.events)
+}
+
+func TestLogWriter_TakeHonoursBatchLimits(t *testing.T) {
+	w := &LogWriter{}
+	big := strings.Repeat("x", maxBatchBytes/2)
+	for range 3 {
+		_, _ = w.Write([]byte(big))
+	}
+
+	assert.Len(t, w.take(), 1)
+	assert.Len(t, w.take(), 1)
+	assert.Len(t, w.take(), 1)
+}
@@ -0,0 +1,69 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// ActivityRepo provides typed DynamoDB operations for the activities table.
+type ActivityRepo struct {
+	client    *dynamodb.Client
+	tableName string
+	cursors   *CursorCodec
+}
+
+func NewActivityRepo(client *dynamodb.Client, tableName string, cursors *CursorCodec) *ActivityRepo {
+	return &ActivityRepo{client: client, tableName: tableName, cursors: cursors}
+}
+
+func (r *ActivityRepo) Put(ctx context.Context, a *domain.Activity) error {
+	item, err := attributevalue.MarshalMap(a)
+	if err != nil {
+		return fmt.Errorf("marshal activity: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	return err
+}
+
+// ListByUser returns a page of a user's activity, newest first.
+// cursor is an opaque signed cursor from a previous page (see CursorCodec).
+func (r *ActivityRepo) ListByUser(ctx context.Context, userID string, limit int32, cursor string) ([]domain.Activity, string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("user_id = :uid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid": &types.AttributeValueMemberS{Value: userID},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(limit),
+	}
+	if cursor != "" {
+		key, err := r.cursors.Decode("activities/"+userID, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		input.ExclusiveStartKey = key
+	}
+	out, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, "", err
+	}
+	activities := make([]domain.Activity, 0, len(out.Items))
+	if err := attributevalue.UnmarshalListOfMaps(out.Items, &activities); err != nil {
+		return nil, "", err
+	}
+	nextCursor, err := r.cursors.Encode("activities/"+userID, out.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return activities, nextCursor, nil
+}
@@ -0,0 +1,72 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// APIKeyRepo provides typed DynamoDB operations for the API keys
+// table. PK: key_id.
+type APIKeyRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewAPIKeyRepo(client *dynamodb.Client, tableName string) *APIKeyRepo {
+	return &APIKeyRepo{client: client, tableName: tableName}
+}
+
+// Put stores k, returning domain.ErrConflict if the key ID is taken.
+func (r *APIKeyRepo) Put(ctx context.Context, k *domain.APIKey) error {
+	item, err := attributevalue.MarshalMap(k)
+	if err != nil {
+		return fmt.Errorf("marshal api key: %w", err)
+	}
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      listed(item),
+	}, "key_id")
+}
+
+func (r *APIKeyRepo) Get(ctx context.Context, keyID string) (*domain.APIKey, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("key_id", keyID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("api key not found: %w", domain.ErrNotFound)
+	}
+	var k domain.APIKey
+	if err := attributevalue.UnmarshalMap(out.Item, &k); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *APIKeyRepo) List(ctx context.Context) ([]domain.APIKey, error) {
+	return queryList[domain.APIKey](ctx, r.client, r.tableName)
+}
+
+// Delete removes a key, returning domain.ErrNotFound if there is none.
+func (r *APIKeyRepo) Delete(ctx context.Context, keyID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 strKey("key_id", keyID),
+		ConditionExpression: aws.String("attribute_exists(key_id)"),
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("api key not found: %w", domain.ErrNotFound)
+	}
+	return err
+}
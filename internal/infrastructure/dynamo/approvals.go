@@ -0,0 +1,127 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// ApprovalRepo provides typed DynamoDB operations for the approvals table.
+type ApprovalRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewApprovalRepo(client *dynamodb.Client, tableName string) *ApprovalRepo {
+	return &ApprovalRepo{client: client, tableName: tableName}
+}
+
+func (r *ApprovalRepo) Put(ctx context.Context, a *domain.Approval) error {
+	item, err := attributevalue.MarshalMap(a)
+	if err != nil {
+		return fmt.Errorf("marshal approval: %w", err)
+	}
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	}, "approval_id")
+}
+
+func (r *ApprovalRepo) Get(ctx context.Context, approvalID string) (*domain.Approval, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("approval_id", approvalID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("approval not found: %w", domain.ErrNotFound)
+	}
+	var a domain.Approval
+	if err := attributevalue.UnmarshalMap(out.Item, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// storedApprovalStatuses are the statuses an approval is saved with;
+// expired is derived from a pending approval's deadline when read.
+var storedApprovalStatuses = []string{
+	domain.ApprovalPending, domain.ApprovalApproved, domain.ApprovalFailed, domain.ApprovalRejected,
+}
+
+// List returns every approval with the given stored status, or all of them
+// when status is empty, querying one partition of status-requested_at-index
+// per status.
+func (r *ApprovalRepo) List(ctx context.Context, status string) ([]domain.Approval, error) {
+	statuses := storedApprovalStatuses
+	if status != "" {
+		statuses = []string{status}
+	}
+	var approvals []domain.Approval
+	for _, s := range statuses {
+		err := queryEach(ctx, r.client, &dynamodb.QueryInput{
+			TableName:                aws.String(r.tableName),
+			IndexName:                aws.String("status-requested_at-index"),
+			KeyConditionExpression:   aws.String("#s = :s"),
+			ExpressionAttributeNames: map[string]string{"#s": "status"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":s": &types.AttributeValueMemberS{Value: s},
+			},
+		}, func(a domain.Approval) error {
+			approvals = append(approvals, a)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return approvals, nil
+}
+
+// Decide applies updates only while the approval is still pending, so two
+// admins deciding at once cannot both act on it. It returns
+// domain.ErrConflict when the approval was already decided.
+func (r *ApprovalRepo) Decide(ctx context.Context, approvalID string, updates map[string]interface{}) error {
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	ue.Names["#status"] = "status"
+	ue.Values[":pending"] = &types.AttributeValueMemberS{Value: domain.ApprovalPending}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("approval_id", approvalID),
+		UpdateExpression:          aws.String(ue.Expr),
+		ConditionExpression:       aws.String("#status = :pending"),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("approval was already decided: %w", domain.ErrConflict)
+	}
+	return err
+}
+
+func (r *ApprovalRepo) Update(ctx context.Context, approvalID string, updates map[string]interface{}) error {
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("approval_id", approvalID),
+		UpdateExpression:          aws.String(ue.Expr),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return err
+}
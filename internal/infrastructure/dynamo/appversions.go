@@ -2,7 +2,6 @@ package dynamo
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -29,7 +28,7 @@ func (r *AppVersionRepo) Put(ctx context.Context, v *domain.AppVersion) error {
 	}
 	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
-		Item:      item,
+		Item:      listed(item),
 	})
 	return err
 }
@@ -43,7 +42,7 @@ func (r *AppVersionRepo) Get(ctx context.Context, versionID string) (*domain.App
 		return nil, err
 	}
 	if out.Item == nil {
-		return nil, errors.New("app version not found")
+		return nil, fmt.Errorf("app version not found: %w", domain.ErrNotFound)
 	}
 	var v domain.AppVersion
 	if err := attributevalue.UnmarshalMap(out.Item, &v); err != nil {
@@ -52,25 +51,44 @@ func (r *AppVersionRepo) Get(ctx context.Context, versionID string) (*domain.App
 	return &v, nil
 }
 
-// GetLatest returns the most recent enabled app version via full scan (table is tiny).
-func (r *AppVersionRepo) GetLatest(ctx context.Context) (*domain.AppVersion, error) {
-	out, err := r.client.Scan(ctx, &dynamodb.ScanInput{
-		TableName:        aws.String(r.tableName),
-		FilterExpression: aws.String("enable = :t"),
+// List returns every app version; the table holds a handful of items.
+func (r *AppVersionRepo) List(ctx context.Context) ([]domain.AppVersion, error) {
+	return queryList[domain.AppVersion](ctx, r.client, r.tableName)
+}
+
+// GetLatest returns the most recently released enabled app version for
+// platform, reading its partition of platform-released_at-index newest first.
+// It returns domain.ErrNotFound when the platform has none.
+func (r *AppVersionRepo) GetLatest(ctx context.Context, platform string) (*domain.AppVersion, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                aws.String(r.tableName),
+		IndexName:                aws.String("platform-released_at-index"),
+		KeyConditionExpression:   aws.String("#p = :p"),
+		FilterExpression:         aws.String("#en = :t"),
+		ExpressionAttributeNames: map[string]string{"#p": "platform", "#en": "enable"},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":p": &types.AttributeValueMemberS{Value: platform},
 			":t": &types.AttributeValueMemberBOOL{Value: true},
 		},
-		Limit: aws.Int32(1),
-	})
-	if err != nil {
-		return nil, err
+		ScanIndexForward: aws.Bool(false),
+		// Limit applies before the filter; disabled versions only cost another page.
+		Limit: aws.Int32(10),
 	}
-	if len(out.Items) == 0 {
-		return nil, errors.New("no active app version found")
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if len(out.Items) > 0 {
+			var v domain.AppVersion
+			if err := attributevalue.UnmarshalMap(out.Items[0], &v); err != nil {
+				return nil, err
+			}
+			return &v, nil
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil, fmt.Errorf("no enabled %s app version: %w", platform, domain.ErrNotFound)
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
 	}
-	var v domain.AppVersion
-	if err := attributevalue.UnmarshalMap(out.Items[0], &v); err != nil {
-		return nil, err
-	}
-	return &v, nil
 }
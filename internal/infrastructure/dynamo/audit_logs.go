@@ -0,0 +1,68 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// AuditRepo provides typed DynamoDB operations for the audit_logs table.
+type AuditRepo struct {
+	client    *dynamodb.Client
+	tableName string
+	scan      ParallelScan
+}
+
+func NewAuditRepo(client *dynamodb.Client, tableName string) *AuditRepo {
+	return &AuditRepo{client: client, tableName: tableName}
+}
+
+// WithParallelScan sets how the retention sweep splits its full-table scan.
+func (r *AuditRepo) WithParallelScan(p ParallelScan) *AuditRepo {
+	r.scan = p
+	return r
+}
+
+func (r *AuditRepo) Put(ctx context.Context, e *domain.AuditEntry) error {
+	item, err := attributevalue.MarshalMap(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	}, "day")
+}
+
+// EachInDay calls fn for every entry recorded on day (YYYY-MM-DD), oldest
+// first, one page at a time.
+func (r *AuditRepo) EachInDay(ctx context.Context, day string, fn func(domain.AuditEntry) error) error {
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    aws.String("#day = :day"),
+		ExpressionAttributeNames:  map[string]string{"#day": "day"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":day": &types.AttributeValueMemberS{Value: day}},
+	}
+	return queryEach(ctx, r.client, input, fn)
+}
+
+// SweepBefore deletes the entries recorded before cutoff's UTC day, or only
+// counts them when dryRun is set. It scans the whole table; entries written
+// with a TTL are normally removed by DynamoDB first.
+func (r *AuditRepo) SweepBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error) {
+	return r.scan.sweep(ctx, r.client, &dynamodb.ScanInput{
+		TableName:                aws.String(r.tableName),
+		FilterExpression:         aws.String("#day < :cutoff"),
+		ProjectionExpression:     aws.String("#day, audit_id"),
+		ExpressionAttributeNames: map[string]string{"#day": "day"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":cutoff": &types.AttributeValueMemberS{Value: cutoff.UTC().Format("2006-01-02")},
+		},
+	}, dryRun)
+}
@@ -0,0 +1,129 @@
+package dynamo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/go-api-nosql/internal/config"
+)
+
+// autoscaler registers provisioned tables and indexes with Application Auto
+// Scaling, which then moves their capacity between the configured capacity
+// and DYNAMO_AUTOSCALING_MAX_FACTOR times it to hold the target utilization.
+// It calls the JSON API directly, signed with SigV4, rather than pulling in
+// another SDK module for two operations.
+type autoscaler struct {
+	endpoint  string
+	region    string
+	creds     aws.CredentialsProvider
+	signer    *v4.Signer
+	client    *http.Client
+	maxFactor int64
+	target    float64
+}
+
+func newAutoscaler(cfg *config.Config) (*autoscaler, error) {
+	awsCfg, err := loadAWSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := "https://application-autoscaling." + cfg.AWSRegion + ".amazonaws.com"
+	if cfg.AWSEndpointURL != "" {
+		endpoint = cfg.AWSEndpointURL
+	}
+	return &autoscaler{
+		endpoint:  endpoint,
+		region:    cfg.AWSRegion,
+		creds:     awsCfg.Credentials,
+		signer:    v4.NewSigner(),
+		client:    &http.Client{Timeout: 10 * time.Second},
+		maxFactor: int64(max(cfg.DynamoAutoscalingMax, 1)),
+		target:    float64(cfg.DynamoAutoscalingTarget),
+	}, nil
+}
+
+// register puts read and write target tracking on resource, "table/<name>" or
+// "table/<name>/index/<index>". Both calls are upserts, so registering on
+// every startup picks up changed settings.
+func (a *autoscaler) register(ctx context.Context, resource string, tp throughput) error {
+	kind := "table"
+	if strings.Contains(resource, "/index/") {
+		kind = "index"
+	}
+	for _, dim := range []struct {
+		name string
+		min  int64
+	}{{"Read", tp.read}, {"Write", tp.write}} {
+		dimension := "dynamodb:" + kind + ":" + dim.name + "CapacityUnits"
+		err := a.call(ctx, "RegisterScalableTarget", map[string]any{
+			"ServiceNamespace":  "dynamodb",
+			"ResourceId":        resource,
+			"ScalableDimension": dimension,
+			"MinCapacity":       dim.min,
+			"MaxCapacity":       dim.min * a.maxFactor,
+		})
+		if err != nil {
+			return err
+		}
+		err = a.call(ctx, "PutScalingPolicy", map[string]any{
+			"PolicyName":        dim.name + "Utilization:" + resource,
+			"ServiceNamespace":  "dynamodb",
+			"ResourceId":        resource,
+			"ScalableDimension": dimension,
+			"PolicyType":        "TargetTrackingScaling",
+			"TargetTrackingScalingPolicyConfiguration": map[string]any{
+				"TargetValue": a.target,
+				"PredefinedMetricSpecification": map[string]any{
+					"PredefinedMetricType": "DynamoDB" + dim.name + "CapacityUtilization",
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call posts in to the Application Auto Scaling action, discarding the
+// response body.
+func (a *autoscaler) call(ctx context.Context, action string, in any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AnyScaleFrontendService."+action)
+	creds, err := a.creds.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("autoscaling credentials: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	if err := a.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "application-autoscaling", a.region, time.Now()); err != nil {
+		return err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("autoscaling %s: %s: %s", action, resp.Status, msg)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package dynamo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// EnablePITR turns on point-in-time recovery for every table that has it off.
+// Failures are logged: a table that is still being created gets it on the
+// next startup, and the backup check reports it until then.
+func EnablePITR(ctx context.Context, client *dynamodb.Client, tables []string) {
+	for _, table := range tables {
+		out, err := client.DescribeContinuousBackups(ctx, &dynamodb.DescribeContinuousBackupsInput{TableName: aws.String(table)})
+		if err == nil && pitrEnabled(out.ContinuousBackupsDescription) {
+			continue
+		}
+		_, err = client.UpdateContinuousBackups(ctx, &dynamodb.UpdateContinuousBackupsInput{
+			TableName:                        aws.String(table),
+			PointInTimeRecoverySpecification: &types.PointInTimeRecoverySpecification{PointInTimeRecoveryEnabled: aws.Bool(true)},
+		})
+		if err != nil {
+			slog.Warn("could not enable point-in-time recovery", "table", table, "err", err)
+			continue
+		}
+		slog.Info("enabled point-in-time recovery", "table", table)
+	}
+}
+
+// BackupStatus reads tables' point-in-time recovery status.
+type BackupStatus struct {
+	client *dynamodb.Client
+}
+
+func NewBackupStatus(client *dynamodb.Client) *BackupStatus {
+	return &BackupStatus{client: client}
+}
+
+// TableBackup returns table's PITR status. Problem is left for the caller.
+func (b *BackupStatus) TableBackup(ctx context.Context, table string) (domain.TableBackup, error) {
+	out, err := b.client.DescribeContinuousBackups(ctx, &dynamodb.DescribeContinuousBackupsInput{TableName: aws.String(table)})
+	if err != nil {
+		return domain.TableBackup{Table: table}, err
+	}
+	res := domain.TableBackup{Table: table, PITREnabled: pitrEnabled(out.ContinuousBackupsDescription)}
+	if d := out.ContinuousBackupsDescription; res.PITREnabled && d.PointInTimeRecoveryDescription != nil {
+		res.LatestRestorableAt = d.PointInTimeRecoveryDescription.LatestRestorableDateTime
+	}
+	return res, nil
+}
+
+func pitrEnabled(d *types.ContinuousBackupsDescription) bool {
+	return d != nil && d.PointInTimeRecoveryDescription != nil &&
+		d.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus == types.PointInTimeRecoveryStatusEnabled
+}
@@ -0,0 +1,81 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// batchSize is the most requests one BatchWriteItem call accepts.
+const batchSize = 25
+
+// batchRetries bounds how often a chunk's unprocessed items are resent.
+const batchRetries = 8
+
+// batchBackoff is the wait before the first resend; it doubles per retry.
+var batchBackoff = 50 * time.Millisecond
+
+// batchWrite sends requests to table in chunks of batchSize, resending the
+// items DynamoDB leaves unprocessed (throttling, partition limits) with
+// exponential backoff. Unlike PutItem and DeleteItem, batched writes take no
+// conditions: puts overwrite and deletes of missing items succeed.
+func batchWrite(ctx context.Context, client *dynamodb.Client, table string, requests []types.WriteRequest) error {
+	for start := 0; start < len(requests); start += batchSize {
+		chunk := requests[start:min(start+batchSize, len(requests))]
+		if err := writeChunk(ctx, client, table, chunk); err != nil {
+			return fmt.Errorf("batch write to %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func writeChunk(ctx context.Context, client *dynamodb.Client, table string, chunk []types.WriteRequest) error {
+	pending := map[string][]types.WriteRequest{table: chunk}
+	wait := batchBackoff
+	for retry := 0; ; retry++ {
+		out, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+		if err != nil {
+			return err
+		}
+		if len(out.UnprocessedItems[table]) == 0 {
+			return nil
+		}
+		if retry == batchRetries {
+			return fmt.Errorf("%d items still unprocessed after %d retries", len(out.UnprocessedItems[table]), retry)
+		}
+		pending = out.UnprocessedItems
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// putRequests marshals items into batch put requests.
+func putRequests[T any](items []T) ([]types.WriteRequest, error) {
+	requests := make([]types.WriteRequest, len(items))
+	for i := range items {
+		item, err := attributevalue.MarshalMap(items[i])
+		if err != nil {
+			return nil, err
+		}
+		requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}
+	}
+	return requests, nil
+}
+
+// deleteRequests builds batch delete requests for the items whose string
+// key attribute keyAttr is one of ids.
+func deleteRequests(keyAttr string, ids []string) []types.WriteRequest {
+	requests := make([]types.WriteRequest, len(ids))
+	for i, id := range ids {
+		requests[i] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: strKey(keyAttr, id)}}
+	}
+	return requests
+}
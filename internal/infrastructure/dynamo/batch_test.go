@@ -0,0 +1,56 @@
+package dynamo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchWrite_ChunksAndRetriesUnprocessed(t *testing.T) {
+	defer func(d time.Duration) { batchBackoff = d }(batchBackoff)
+	batchBackoff = 0
+	var sizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			RequestItems map[string][]json.RawMessage
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests := body.RequestItems["devices"]
+		sizes = append(sizes, len(requests))
+		if len(sizes) == 1 {
+			// Throttled: the last two requests come back unprocessed.
+			fmt.Fprintf(w, `{"UnprocessedItems":{"devices":[%s,%s]}}`, requests[23], requests[24])
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	client := testClient(t, srv.URL)
+
+	ids := make([]string, 30)
+	for i := range ids {
+		ids[i] = "d" + strconv.Itoa(i)
+	}
+	err := batchWrite(t.Context(), client, "devices", deleteRequests("device_id", ids))
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{25, 2, 5}, sizes)
+}
+
+func TestPutRequests(t *testing.T) {
+	type item struct {
+		ID string `dynamodbav:"id"`
+	}
+	requests, err := putRequests([]item{{"a"}, {"b"}})
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "b"}, requests[1].PutRequest.Item["id"])
+}
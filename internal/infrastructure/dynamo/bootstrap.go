@@ -11,21 +11,26 @@ import (
 	"github.com/go-api-nosql/internal/config"
 )
 
-// Bootstrap creates all DynamoDB tables and GSIs if they don't already exist.
-// Safe to call on every startup — skips tables that already exist.
-func Bootstrap(ctx context.Context, client *dynamodb.Client, tables config.DynamoTables) {
-	createTable(ctx, client, &dynamodb.CreateTableInput{
-		TableName:   aws.String(tables.Users),
-		BillingMode: types.BillingModePayPerRequest,
+// Bootstrap creates all DynamoDB tables and GSIs if they don't already exist,
+// in capacity's billing mode (see Capacity). Safe to call on every startup —
+// skips tables that already exist.
+func Bootstrap(ctx context.Context, client *dynamodb.Client, tables config.DynamoTables, capacity *Capacity) {
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Users),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{AttributeName: aws.String("user_id"), AttributeType: types.ScalarAttributeTypeS},
 			{AttributeName: aws.String("username"), AttributeType: types.ScalarAttributeTypeS},
 			{AttributeName: aws.String("email"), AttributeType: types.ScalarAttributeTypeS},
-			// NOTE: `enable` is stored as a Number (N) to support the enable-index GSI.
-			// This is a breaking change from a prior boolean representation.
-			// Existing items with a boolean `enable` attribute must be migrated
-			// (false → 0, true → 1) before enable-index queries return correct results.
+			// NOTE: `enable` is stored as a Number (N) to support the
+			// enable-created_at-index GSI. This is a breaking change from a prior
+			// boolean representation. Existing items with a boolean `enable`
+			// attribute must be migrated (false → 0, true → 1) before the index
+			// returns correct results.
 			{AttributeName: aws.String("enable"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("created_at"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("role"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("username_key"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("email_key"), AttributeType: types.ScalarAttributeTypeS},
 		},
 		KeySchema: []types.KeySchemaElement{
 			{AttributeName: aws.String("user_id"), KeyType: types.KeyTypeHash},
@@ -33,13 +38,36 @@ func Bootstrap(ctx context.Context, client *dynamodb.Client, tables config.Dynam
 		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
 			gsi("username-index", "username", ""),
 			gsi("email-index", "email", ""),
-			gsi("enable-index", "enable", ""),
+			gsi("enable-created_at-index", "enable", "created_at"),
+			gsi("role-created_at-index", "role", "created_at"),
+			gsi("username_key-index", "username_key", ""),
+			gsi("email_key-index", "email_key", ""),
 		},
+		StreamSpecification: tableStream,
 	})
+	// Tables created before normalized lookups need the key indexes added.
+	ensureGSI(ctx, client, tables.Users, []types.AttributeDefinition{
+		{AttributeName: aws.String("username_key"), AttributeType: types.ScalarAttributeTypeS},
+	}, gsi("username_key-index", "username_key", ""))
+	ensureGSI(ctx, client, tables.Users, []types.AttributeDefinition{
+		{AttributeName: aws.String("email_key"), AttributeType: types.ScalarAttributeTypeS},
+	}, gsi("email_key-index", "email_key", ""))
+	// Replaces the hash-only enable-index, which kept every enabled user under
+	// one unsorted key. The old index is left in place and can be dropped by hand.
+	ensureGSI(ctx, client, tables.Users, []types.AttributeDefinition{
+		{AttributeName: aws.String("enable"), AttributeType: types.ScalarAttributeTypeN},
+		{AttributeName: aws.String("created_at"), AttributeType: types.ScalarAttributeTypeS},
+	}, gsi("enable-created_at-index", "enable", "created_at"))
+	// DynamoDB builds one new index at a time, so on an older table this may
+	// only succeed on a later startup.
+	ensureGSI(ctx, client, tables.Users, []types.AttributeDefinition{
+		{AttributeName: aws.String("role"), AttributeType: types.ScalarAttributeTypeS},
+		{AttributeName: aws.String("created_at"), AttributeType: types.ScalarAttributeTypeS},
+	}, gsi("role-created_at-index", "role", "created_at"))
+	ensureStream(ctx, client, tables.Users)
 
-	createTable(ctx, client, &dynamodb.CreateTableInput{
-		TableName:   aws.String(tables.Sessions),
-		BillingMode: types.BillingModePayPerRequest,
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Sessions),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{AttributeName: aws.String("session_id"), AttributeType: types.ScalarAttributeTypeS},
 			{AttributeName: aws.String("user_id"), AttributeType: types.ScalarAttributeTypeS},
@@ -54,20 +82,21 @@ func Bootstrap(ctx context.Context, client *dynamodb.Client, tables config.Dynam
 		},
 	})
 
-	createTable(ctx, client, &dynamodb.CreateTableInput{
-		TableName:   aws.String(tables.Statuses),
-		BillingMode: types.BillingModePayPerRequest,
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Statuses),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{AttributeName: aws.String("status_id"), AttributeType: types.ScalarAttributeTypeS},
+			listAttrDef,
 		},
 		KeySchema: []types.KeySchemaElement{
 			{AttributeName: aws.String("status_id"), KeyType: types.KeyTypeHash},
 		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi(listIndex, listAttr, "")},
 	})
+	ensureGSI(ctx, client, tables.Statuses, []types.AttributeDefinition{listAttrDef}, gsi(listIndex, listAttr, ""))
 
-	createTable(ctx, client, &dynamodb.CreateTableInput{
-		TableName:   aws.String(tables.Devices),
-		BillingMode: types.BillingModePayPerRequest,
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Devices),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{AttributeName: aws.String("device_id"), AttributeType: types.ScalarAttributeTypeS},
 			{AttributeName: aws.String("user_id"), AttributeType: types.ScalarAttributeTypeS},
@@ -82,25 +111,28 @@ func Bootstrap(ctx context.Context, client *dynamodb.Client, tables config.Dynam
 		},
 	})
 
-	createTable(ctx, client, &dynamodb.CreateTableInput{
-		TableName:   aws.String(tables.Notifications),
-		BillingMode: types.BillingModePayPerRequest,
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Notifications),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{AttributeName: aws.String("notification_id"), AttributeType: types.ScalarAttributeTypeS},
 			{AttributeName: aws.String("user_id"), AttributeType: types.ScalarAttributeTypeS},
 			{AttributeName: aws.String("created_at"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("due_at"), AttributeType: types.ScalarAttributeTypeN},
 		},
 		KeySchema: []types.KeySchemaElement{
 			{AttributeName: aws.String("notification_id"), KeyType: types.KeyTypeHash},
 		},
 		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
 			gsi("user_id-created_at-index", "user_id", "created_at"),
+			// Sparse: due_at is only present while a notification is scheduled.
+			gsi("status-due_at-index", "status", "due_at"),
 		},
 	})
+	enableTTL(ctx, client, tables.Notifications, "expires_at")
 
-	createTable(ctx, client, &dynamodb.CreateTableInput{
-		TableName:   aws.String(tables.Files),
-		BillingMode: types.BillingModePayPerRequest,
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Files),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{AttributeName: aws.String("file_id"), AttributeType: types.ScalarAttributeTypeS},
 			{AttributeName: aws.String("uploaded_by_user_id"), AttributeType: types.ScalarAttributeTypeS},
@@ -111,11 +143,12 @@ func Bootstrap(ctx context.Context, client *dynamodb.Client, tables config.Dynam
 		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
 			gsi("uploaded_by_user_id-index", "uploaded_by_user_id", ""),
 		},
+		StreamSpecification: tableStream,
 	})
+	ensureStream(ctx, client, tables.Files)
 
-	createTable(ctx, client, &dynamodb.CreateTableInput{
-		TableName:   aws.String(tables.UserVerifications),
-		BillingMode: types.BillingModePayPerRequest,
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.UserVerifications),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{AttributeName: aws.String("user_id"), AttributeType: types.ScalarAttributeTypeS},
 			{AttributeName: aws.String("type"), AttributeType: types.ScalarAttributeTypeS},
@@ -127,18 +160,233 @@ func Bootstrap(ctx context.Context, client *dynamodb.Client, tables config.Dynam
 	})
 	enableTTL(ctx, client, tables.UserVerifications, "expires_at")
 
-	createTable(ctx, client, &dynamodb.CreateTableInput{
-		TableName:   aws.String(tables.AppVersions),
-		BillingMode: types.BillingModePayPerRequest,
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.AppVersions),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{AttributeName: aws.String("version_id"), AttributeType: types.ScalarAttributeTypeS},
+			listAttrDef,
+			{AttributeName: aws.String("platform"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("released_at"), AttributeType: types.ScalarAttributeTypeS},
 		},
 		KeySchema: []types.KeySchemaElement{
 			{AttributeName: aws.String("version_id"), KeyType: types.KeyTypeHash},
 		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			gsi(listIndex, listAttr, ""),
+			// Sparse: versions created before platforms existed have no platform.
+			gsi("platform-released_at-index", "platform", "released_at"),
+		},
+	})
+	ensureGSI(ctx, client, tables.AppVersions, []types.AttributeDefinition{listAttrDef}, gsi(listIndex, listAttr, ""))
+	// DynamoDB builds one new index at a time, so on an older table this may
+	// only succeed on a later startup.
+	ensureGSI(ctx, client, tables.AppVersions, []types.AttributeDefinition{
+		{AttributeName: aws.String("platform"), AttributeType: types.ScalarAttributeTypeS},
+		{AttributeName: aws.String("released_at"), AttributeType: types.ScalarAttributeTypeS},
+	}, gsi("platform-released_at-index", "platform", "released_at"))
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.RateLimits),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("limit_key"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("limit_key"), KeyType: types.KeyTypeHash},
+		},
+	})
+	enableTTL(ctx, client, tables.RateLimits, "expires_at")
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Templates),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("name"), AttributeType: types.ScalarAttributeTypeS},
+			listAttrDef,
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("name"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi(listIndex, listAttr, "")},
+	})
+	ensureGSI(ctx, client, tables.Templates, []types.AttributeDefinition{listAttrDef}, gsi(listIndex, listAttr, ""))
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Messages),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("conversation_id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("sent_at"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("unread_for"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("conversation_id"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("sent_at"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			// Sparse: unread_for is removed once the recipient reads the message.
+			gsi("unread_for-index", "unread_for", "sent_at"),
+		},
+	})
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Activities),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("user_id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("created_at"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("user_id"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("created_at"), KeyType: types.KeyTypeRange},
+		},
+	})
+	enableTTL(ctx, client, tables.Activities, "expires_at")
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Roles),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("name"), AttributeType: types.ScalarAttributeTypeS},
+			listAttrDef,
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("name"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi(listIndex, listAttr, "")},
+	})
+	ensureGSI(ctx, client, tables.Roles, []types.AttributeDefinition{listAttrDef}, gsi(listIndex, listAttr, ""))
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.AuditLogs),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("day"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("audit_id"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("day"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("audit_id"), KeyType: types.KeyTypeRange},
+		},
+	})
+	// Entries only carry expires_at once RETENTION_ENFORCE is on.
+	enableTTL(ctx, client, tables.AuditLogs, "expires_at")
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Approvals),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("approval_id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("requested_at"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("approval_id"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			gsi("status-requested_at-index", "status", "requested_at"),
+		},
+	})
+	ensureGSI(ctx, client, tables.Approvals, []types.AttributeDefinition{
+		{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+		{AttributeName: aws.String("requested_at"), AttributeType: types.ScalarAttributeTypeS},
+	}, gsi("status-requested_at-index", "status", "requested_at"))
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Usage),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("user_id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("day"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("user_id"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("day"), KeyType: types.KeyTypeRange},
+		},
+	})
+	enableTTL(ctx, client, tables.Usage, "expires_at")
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Plans),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("name"), AttributeType: types.ScalarAttributeTypeS},
+			listAttrDef,
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("name"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi(listIndex, listAttr, "")},
+	})
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Broadcasts),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("broadcast_id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("created_at"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("broadcast_id"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			gsi("status-created_at-index", "status", "created_at"),
+		},
+	})
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Settings),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("name"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("name"), KeyType: types.KeyTypeHash},
+		},
+	})
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.LaunchAllowlist),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("entry_key"), AttributeType: types.ScalarAttributeTypeS},
+			listAttrDef,
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("entry_key"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi(listIndex, listAttr, "")},
+	})
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.Passkeys),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("credential_id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("user_id"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("credential_id"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi("user_id-index", "user_id", "")},
+	})
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.OAuthClients),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("client_id"), AttributeType: types.ScalarAttributeTypeS},
+			listAttrDef,
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("client_id"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi(listIndex, listAttr, "")},
+	})
+
+	createTable(ctx, client, capacity, &dynamodb.CreateTableInput{
+		TableName: aws.String(tables.APIKeys),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("key_id"), AttributeType: types.ScalarAttributeTypeS},
+			listAttrDef,
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("key_id"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi(listIndex, listAttr, "")},
 	})
 }
 
+// listAttrDef declares listAttr, the key of the catalog tables' listIndex.
+var listAttrDef = types.AttributeDefinition{AttributeName: aws.String(listAttr), AttributeType: types.ScalarAttributeTypeS}
+
 // gsi builds a GSI descriptor. If sortKey is empty, only a hash key is added.
 func gsi(indexName, hashKey, sortKey string) types.GlobalSecondaryIndex {
 	ks := []types.KeySchemaElement{
@@ -156,7 +404,8 @@ func gsi(indexName, hashKey, sortKey string) types.GlobalSecondaryIndex {
 	}
 }
 
-func createTable(ctx context.Context, client *dynamodb.Client, input *dynamodb.CreateTableInput) {
+func createTable(ctx context.Context, client *dynamodb.Client, capacity *Capacity, input *dynamodb.CreateTableInput) {
+	capacity.apply(input)
 	_, err := client.CreateTable(ctx, input)
 	if err != nil {
 		// ResourceInUseException means the table already exists — that's fine.
@@ -167,6 +416,84 @@ func createTable(ctx context.Context, client *dynamodb.Client, input *dynamodb.C
 	} else {
 		slog.Info("created table", "table", *input.TableName)
 	}
+	capacity.reconcile(ctx, client, *input.TableName)
+}
+
+// ensureGSI adds index to an existing table if it is missing. attrs declares
+// the index's key attributes. DynamoDB builds the index in the background.
+func ensureGSI(ctx context.Context, client *dynamodb.Client, tableName string, attrs []types.AttributeDefinition, index types.GlobalSecondaryIndex) {
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		slog.Warn("could not describe table", "table", tableName, "err", err)
+		return
+	}
+	for _, existing := range out.Table.GlobalSecondaryIndexes {
+		if aws.ToString(existing.IndexName) == aws.ToString(index.IndexName) {
+			return
+		}
+	}
+	_, err = client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		TableName:            aws.String(tableName),
+		AttributeDefinitions: attrs,
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{
+			Create: &types.CreateGlobalSecondaryIndexAction{
+				IndexName:  index.IndexName,
+				KeySchema:  index.KeySchema,
+				Projection: index.Projection,
+				// A provisioned table's indexes need their own capacity; start
+				// them at the table's.
+				ProvisionedThroughput: indexThroughput(out.Table),
+			},
+		}},
+	})
+	if err != nil {
+		slog.Warn("could not add index", "table", tableName, "index", aws.ToString(index.IndexName), "err", err)
+		return
+	}
+	slog.Info("adding index", "table", tableName, "index", aws.ToString(index.IndexName))
+}
+
+// indexThroughput is the table's provisioned throughput, or nil when it is
+// on-demand.
+func indexThroughput(table *types.TableDescription) *types.ProvisionedThroughput {
+	if billingMode(table) != types.BillingModeProvisioned || table.ProvisionedThroughput == nil {
+		return nil
+	}
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  table.ProvisionedThroughput.ReadCapacityUnits,
+		WriteCapacityUnits: table.ProvisionedThroughput.WriteCapacityUnits,
+	}
+}
+
+// tableStream is the stream the search projection tails (see StreamReader).
+// It carries old images too because global tables (EnsureReplicas) require
+// NEW_AND_OLD_IMAGES.
+var tableStream = &types.StreamSpecification{
+	StreamEnabled:  aws.Bool(true),
+	StreamViewType: types.StreamViewTypeNewAndOldImages,
+}
+
+// ensureStream enables tableStream on an existing table that has no stream.
+// DynamoDB rejects the update while an index is still building; the next
+// startup retries it.
+func ensureStream(ctx context.Context, client *dynamodb.Client, tableName string) {
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		slog.Warn("could not describe table", "table", tableName, "err", err)
+		return
+	}
+	if spec := out.Table.StreamSpecification; spec != nil && aws.ToBool(spec.StreamEnabled) {
+		return
+	}
+	_, err = client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		TableName:           aws.String(tableName),
+		StreamSpecification: tableStream,
+	})
+	if err != nil {
+		slog.Warn("could not enable stream", "table", tableName, "err", err)
+		return
+	}
+	slog.Info("enabled stream", "table", tableName)
 }
 
 func enableTTL(ctx context.Context, client *dynamodb.Client, tableName, ttlAttr string) {
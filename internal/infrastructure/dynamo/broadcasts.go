@@ -0,0 +1,186 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// BroadcastRepo provides typed DynamoDB operations for the broadcasts table.
+type BroadcastRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewBroadcastRepo(client *dynamodb.Client, tableName string) *BroadcastRepo {
+	return &BroadcastRepo{client: client, tableName: tableName}
+}
+
+func (r *BroadcastRepo) Put(ctx context.Context, b *domain.Broadcast) error {
+	item, err := attributevalue.MarshalMap(b)
+	if err != nil {
+		return fmt.Errorf("marshal broadcast: %w", err)
+	}
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	}, "broadcast_id")
+}
+
+func (r *BroadcastRepo) Get(ctx context.Context, broadcastID string) (*domain.Broadcast, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("broadcast_id", broadcastID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("broadcast not found: %w", domain.ErrNotFound)
+	}
+	var b domain.Broadcast
+	if err := attributevalue.UnmarshalMap(out.Item, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+var broadcastStatuses = []string{
+	domain.BroadcastPending, domain.BroadcastRunning, domain.BroadcastDone,
+	domain.BroadcastCanceled, domain.BroadcastFailed,
+}
+
+// List returns every broadcast with the given status, or all of them when
+// status is empty, querying one partition of status-created_at-index per
+// status.
+func (r *BroadcastRepo) List(ctx context.Context, status string) ([]domain.Broadcast, error) {
+	statuses := broadcastStatuses
+	if status != "" {
+		statuses = []string{status}
+	}
+	var broadcasts []domain.Broadcast
+	for _, s := range statuses {
+		err := queryEach(ctx, r.client, &dynamodb.QueryInput{
+			TableName:                aws.String(r.tableName),
+			IndexName:                aws.String("status-created_at-index"),
+			KeyConditionExpression:   aws.String("#s = :s"),
+			ExpressionAttributeNames: map[string]string{"#s": "status"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":s": &types.AttributeValueMemberS{Value: s},
+			},
+		}, func(b domain.Broadcast) error {
+			broadcasts = append(broadcasts, b)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return broadcasts, nil
+}
+
+// Claim leases a pending or running broadcast to leaseID until the given
+// unix time, marking it running, and returns it as stored. A broadcast leased
+// to someone else whose lease has not run out, or one that has ended, is
+// domain.ErrConflict.
+func (r *BroadcastRepo) Claim(ctx context.Context, broadcastID, leaseID string, until int64) (*domain.Broadcast, error) {
+	now := time.Now().UTC()
+	out, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("broadcast_id", broadcastID),
+		UpdateExpression: aws.String("SET #s = :running, #lid = :lid, #lu = :until, " +
+			"started_at = if_not_exists(started_at, :now)"),
+		ConditionExpression: aws.String("#s IN (:pending, :running) AND (attribute_not_exists(#lu) OR #lu < :unix)"),
+		ExpressionAttributeNames: map[string]string{
+			"#s": "status", "#lid": "lease_id", "#lu": "lease_until",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":running": &types.AttributeValueMemberS{Value: domain.BroadcastRunning},
+			":pending": &types.AttributeValueMemberS{Value: domain.BroadcastPending},
+			":lid":     &types.AttributeValueMemberS{Value: leaseID},
+			":until":   &types.AttributeValueMemberN{Value: strconv.FormatInt(until, 10)},
+			":unix":    &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+			":now":     &types.AttributeValueMemberS{Value: now.Format(time.RFC3339Nano)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err := broadcastConflict(err, "broadcast is leased or has ended"); err != nil {
+		return nil, err
+	}
+	var b domain.Broadcast
+	if err := attributevalue.UnmarshalMap(out.Attributes, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Advance applies updates while leaseID still holds the broadcast and
+// returns it as stored, so the caller sees a cancellation. It returns
+// domain.ErrConflict once the lease is lost.
+func (r *BroadcastRepo) Advance(ctx context.Context, broadcastID, leaseID string, updates map[string]interface{}) (*domain.Broadcast, error) {
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return nil, err
+	}
+	ue.Names["#lease"] = "lease_id"
+	ue.Values[":lease"] = &types.AttributeValueMemberS{Value: leaseID}
+	out, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("broadcast_id", broadcastID),
+		UpdateExpression:          aws.String(ue.Expr),
+		ConditionExpression:       aws.String("#lease = :lease"),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err := broadcastConflict(err, "broadcast lease was lost"); err != nil {
+		return nil, err
+	}
+	var b domain.Broadcast
+	if err := attributevalue.UnmarshalMap(out.Attributes, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Close applies updates, which set the final status, only while the
+// broadcast is in one of the from statuses, so a cancellation and the job
+// finishing cannot both win. It returns domain.ErrConflict otherwise.
+func (r *BroadcastRepo) Close(ctx context.Context, broadcastID string, from []string, updates map[string]interface{}) error {
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	placeholders := make([]string, len(from))
+	for i, s := range from {
+		placeholders[i] = ":from" + strconv.Itoa(i)
+		ue.Values[placeholders[i]] = &types.AttributeValueMemberS{Value: s}
+	}
+	ue.Names["#cur"] = "status"
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("broadcast_id", broadcastID),
+		UpdateExpression:          aws.String(ue.Expr),
+		ConditionExpression:       aws.String("#cur IN (" + strings.Join(placeholders, ", ") + ")"),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return broadcastConflict(err, "broadcast has already ended")
+}
+
+func broadcastConflict(err error, msg string) error {
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("%s: %w", msg, domain.ErrConflict)
+	}
+	return err
+}
@@ -0,0 +1,163 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/config"
+)
+
+// throughput is a table's provisioned read and write capacity units.
+type throughput struct {
+	read, write int64
+}
+
+// Capacity is the billing mode Bootstrap gives tables. A nil *Capacity
+// creates on-demand tables and leaves existing ones in whatever mode they are.
+type Capacity struct {
+	mode     types.BillingMode
+	defaults throughput
+	tables   map[string]throughput
+	scaling  *autoscaler // nil unless DYNAMO_AUTOSCALING
+}
+
+// NewCapacity reads DYNAMO_BILLING_MODE and the capacity settings. It returns
+// nil when DYNAMO_BILLING_MODE is empty.
+func NewCapacity(cfg *config.Config) (*Capacity, error) {
+	switch mode := types.BillingMode(cfg.DynamoBillingMode); mode {
+	case "":
+		return nil, nil
+	case types.BillingModePayPerRequest:
+		return &Capacity{mode: mode}, nil
+	case types.BillingModeProvisioned:
+	default:
+		return nil, fmt.Errorf("DYNAMO_BILLING_MODE must be PAY_PER_REQUEST or PROVISIONED, got %q", mode)
+	}
+	c := &Capacity{mode: types.BillingModeProvisioned, tables: map[string]throughput{}}
+	var err error
+	if c.defaults, err = parseThroughput(cfg.DynamoDefaultCapacity); err != nil {
+		return nil, fmt.Errorf("DYNAMO_DEFAULT_CAPACITY: %w", err)
+	}
+	names := cfg.DynamoTables.Names()
+	for _, entry := range strings.Split(cfg.DynamoCapacity, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		table, value, _ := strings.Cut(entry, "=")
+		table = cfg.DynamoTablePrefix + strings.TrimSpace(table)
+		if !slices.Contains(names, table) {
+			return nil, fmt.Errorf("DYNAMO_CAPACITY: unknown table %q", table)
+		}
+		if c.tables[table], err = parseThroughput(value); err != nil {
+			return nil, fmt.Errorf("DYNAMO_CAPACITY %s: %w", table, err)
+		}
+	}
+	if cfg.DynamoAutoscaling {
+		if c.scaling, err = newAutoscaler(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// parseThroughput parses "rcu:wcu".
+func parseThroughput(s string) (throughput, error) {
+	r, w, ok := strings.Cut(strings.TrimSpace(s), ":")
+	read, rErr := strconv.ParseInt(r, 10, 64)
+	write, wErr := strconv.ParseInt(w, 10, 64)
+	if !ok || rErr != nil || wErr != nil || read < 1 || write < 1 {
+		return throughput{}, fmt.Errorf("want read:write capacity units, got %q", s)
+	}
+	return throughput{read: read, write: write}, nil
+}
+
+func (c *Capacity) of(table string) throughput {
+	if tp, ok := c.tables[table]; ok {
+		return tp
+	}
+	return c.defaults
+}
+
+func (tp throughput) provisioned() *types.ProvisionedThroughput {
+	return &types.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(tp.read), WriteCapacityUnits: aws.Int64(tp.write)}
+}
+
+// apply sets input's billing mode and, when provisioned, the throughput of
+// the table and every index. Indexes get the table's capacity.
+func (c *Capacity) apply(input *dynamodb.CreateTableInput) {
+	if c == nil || c.mode == types.BillingModePayPerRequest {
+		input.BillingMode = types.BillingModePayPerRequest
+		return
+	}
+	tp := c.of(aws.ToString(input.TableName)).provisioned()
+	input.BillingMode = types.BillingModeProvisioned
+	input.ProvisionedThroughput = tp
+	for i := range input.GlobalSecondaryIndexes {
+		input.GlobalSecondaryIndexes[i].ProvisionedThroughput = tp
+	}
+}
+
+// reconcile switches an existing table to c's billing mode and, with
+// autoscaling on, registers it and its indexes. Throughput of a table that is
+// already provisioned is left alone so autoscaling or an operator can move
+// it. DynamoDB allows one billing mode switch per table per 24 hours.
+func (c *Capacity) reconcile(ctx context.Context, client *dynamodb.Client, table string) {
+	if c == nil {
+		return
+	}
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		slog.Warn("could not describe table", "table", table, "err", err)
+		return
+	}
+	if billingMode(out.Table) != c.mode {
+		c.switchMode(ctx, client, out.Table)
+	}
+	if c.scaling == nil || c.mode != types.BillingModeProvisioned {
+		return
+	}
+	tp := c.of(table)
+	resources := []string{"table/" + table}
+	for _, idx := range out.Table.GlobalSecondaryIndexes {
+		resources = append(resources, "table/"+table+"/index/"+aws.ToString(idx.IndexName))
+	}
+	for _, r := range resources {
+		if err := c.scaling.register(ctx, r, tp); err != nil {
+			slog.Warn("could not register autoscaling", "resource", r, "err", err)
+		}
+	}
+}
+
+func (c *Capacity) switchMode(ctx context.Context, client *dynamodb.Client, table *types.TableDescription) {
+	input := &dynamodb.UpdateTableInput{TableName: table.TableName, BillingMode: c.mode}
+	if c.mode == types.BillingModeProvisioned {
+		tp := c.of(aws.ToString(table.TableName)).provisioned()
+		input.ProvisionedThroughput = tp
+		for _, idx := range table.GlobalSecondaryIndexes {
+			input.GlobalSecondaryIndexUpdates = append(input.GlobalSecondaryIndexUpdates, types.GlobalSecondaryIndexUpdate{
+				Update: &types.UpdateGlobalSecondaryIndexAction{IndexName: idx.IndexName, ProvisionedThroughput: tp},
+			})
+		}
+	}
+	if _, err := client.UpdateTable(ctx, input); err != nil {
+		slog.Warn("could not switch billing mode", "table", aws.ToString(table.TableName), "mode", c.mode, "err", err)
+		return
+	}
+	slog.Info("switched billing mode", "table", aws.ToString(table.TableName), "mode", c.mode)
+}
+
+// billingMode reports table's mode. Tables created before on-demand existed
+// have no billing mode summary and are provisioned.
+func billingMode(table *types.TableDescription) types.BillingMode {
+	if table.BillingModeSummary == nil {
+		return types.BillingModeProvisioned
+	}
+	return table.BillingModeSummary.BillingMode
+}
@@ -0,0 +1,110 @@
+package dynamo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capacityConfig(mode, perTable string) *config.Config {
+	return &config.Config{
+		AWSRegion:             "us-east-1",
+		DynamoTables:          config.DynamoTables{Users: "users", Sessions: "sessions"},
+		DynamoBillingMode:     mode,
+		DynamoCapacity:        perTable,
+		DynamoDefaultCapacity: "5:5",
+	}
+}
+
+func TestNewCapacity_Modes(t *testing.T) {
+	c, err := NewCapacity(capacityConfig("", ""))
+	require.NoError(t, err)
+	assert.Nil(t, c, "empty mode leaves tables alone")
+
+	c, err = NewCapacity(capacityConfig("PAY_PER_REQUEST", ""))
+	require.NoError(t, err)
+	assert.Equal(t, types.BillingModePayPerRequest, c.mode)
+
+	_, err = NewCapacity(capacityConfig("ON_DEMAND", ""))
+	assert.Error(t, err)
+}
+
+func TestNewCapacity_PerTable(t *testing.T) {
+	c, err := NewCapacity(capacityConfig("PROVISIONED", "users=20:10"))
+	require.NoError(t, err)
+	assert.Equal(t, throughput{read: 20, write: 10}, c.of("users"))
+	assert.Equal(t, throughput{read: 5, write: 5}, c.of("sessions"))
+
+	prefixed := capacityConfig("PROVISIONED", "users=20:10")
+	prefixed.DynamoTablePrefix, prefixed.DynamoTables.Users = "staging-", "staging-users"
+	c, err = NewCapacity(prefixed)
+	require.NoError(t, err)
+	assert.Equal(t, throughput{read: 20, write: 10}, c.of("staging-users"), "keys omit the table prefix")
+
+	for _, bad := range []string{"nope=1:1", "users=10", "users=0:5", "users=a:b"} {
+		_, err := NewCapacity(capacityConfig("PROVISIONED", bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestCapacityApply(t *testing.T) {
+	input := func() *dynamodb.CreateTableInput {
+		return &dynamodb.CreateTableInput{
+			TableName:              aws.String("users"),
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi("email-index", "email", "")},
+		}
+	}
+
+	onDemand := input()
+	var c *Capacity
+	c.apply(onDemand)
+	assert.Equal(t, types.BillingModePayPerRequest, onDemand.BillingMode)
+	assert.Nil(t, onDemand.ProvisionedThroughput)
+
+	c, err := NewCapacity(capacityConfig("PROVISIONED", "users=20:10"))
+	require.NoError(t, err)
+	provisioned := input()
+	c.apply(provisioned)
+	assert.Equal(t, types.BillingModeProvisioned, provisioned.BillingMode)
+	assert.Equal(t, int64(20), aws.ToInt64(provisioned.ProvisionedThroughput.ReadCapacityUnits))
+	assert.Equal(t, int64(10), aws.ToInt64(provisioned.GlobalSecondaryIndexes[0].ProvisionedThroughput.WriteCapacityUnits))
+}
+
+func TestAutoscalerRegister(t *testing.T) {
+	type call struct {
+		Target string
+		Body   map[string]any
+	}
+	var calls []call
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		calls = append(calls, call{r.Header.Get("X-Amz-Target"), body})
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+	cfg := capacityConfig("PROVISIONED", "")
+	cfg.AWSEndpointURL, cfg.AWSAccessKeyID, cfg.AWSSecretKey = srv.URL, "id", "secret"
+	cfg.DynamoAutoscalingMax, cfg.DynamoAutoscalingTarget = 4, 70
+
+	scaler, err := newAutoscaler(cfg)
+	require.NoError(t, err)
+	err = scaler.register(t.Context(), "table/users/index/email-index", throughput{read: 10, write: 5})
+	require.NoError(t, err)
+
+	require.Len(t, calls, 4, "a target and a policy per dimension")
+	assert.Equal(t, "AnyScaleFrontendService.RegisterScalableTarget", calls[0].Target)
+	assert.Equal(t, "dynamodb:index:ReadCapacityUnits", calls[0].Body["ScalableDimension"])
+	assert.Equal(t, float64(10), calls[0].Body["MinCapacity"])
+	assert.Equal(t, float64(40), calls[0].Body["MaxCapacity"])
+	assert.Equal(t, "AnyScaleFrontendService.PutScalingPolicy", calls[3].Target)
+	assert.Equal(t, "dynamodb:index:WriteCapacityUnits", calls[3].Body["ScalableDimension"])
+}
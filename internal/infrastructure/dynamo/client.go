@@ -2,17 +2,65 @@ package dynamo
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/go-api-nosql/internal/config"
 )
 
 // NewClient creates a DynamoDB client. When cfg.AWSEndpointURL is set (LocalStack),
-// it overrides the endpoint so all traffic goes to the local instance.
-func NewClient(cfg *config.Config) *dynamodb.Client {
+// it overrides the endpoint so all traffic goes to the local instance. With
+// DYNAMO_FAILOVER and replica regions configured, requests fail over between
+// regions (see regionFailover). SDK errors are translated to domain errors
+// (see translateError). optFns are applied after it.
+func NewClient(cfg *config.Config, optFns ...func(*dynamodb.Options)) (*dynamodb.Client, error) {
+	awsCfg, err := loadAWSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clientOpts := []func(*dynamodb.Options){func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, func(s *middleware.Stack) error {
+			return s.Initialize.Add(errorTranslation, middleware.Before)
+		})
+	}}
+	if cfg.AWSEndpointURL != "" {
+		clientOpts = append(clientOpts, func(o *dynamodb.Options) {
+			o.BaseEndpoint = aws.String(cfg.AWSEndpointURL)
+		})
+	}
+	if cfg.DynamoFailover && len(cfg.DynamoReplicaRegions) > 0 {
+		clientOpts = append(clientOpts, func(o *dynamodb.Options) {
+			o.HTTPClient = newRegionFailover(o.HTTPClient, awsCfg.Credentials, cfg)
+		})
+	}
+
+	return dynamodb.NewFromConfig(awsCfg, append(clientOpts, optFns...)...), nil
+}
+
+// NewStreamsClient creates a DynamoDB Streams client with the same endpoint
+// and credentials as NewClient. It always reads the primary region's stream:
+// stream shards are per replica and cannot be resumed in another region.
+func NewStreamsClient(cfg *config.Config) (*dynamodbstreams.Client, error) {
+	awsCfg, err := loadAWSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clientOpts := []func(*dynamodbstreams.Options){}
+	if cfg.AWSEndpointURL != "" {
+		clientOpts = append(clientOpts, func(o *dynamodbstreams.Options) {
+			o.BaseEndpoint = aws.String(cfg.AWSEndpointURL)
+		})
+	}
+
+	return dynamodbstreams.NewFromConfig(awsCfg, clientOpts...), nil
+}
+
+func loadAWSConfig(cfg *config.Config) (aws.Config, error) {
 	opts := []func(*awsconfig.LoadOptions) error{
 		awsconfig.WithRegion(cfg.AWSRegion),
 	}
@@ -25,15 +73,7 @@ func NewClient(cfg *config.Config) *dynamodb.Client {
 
 	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
 	if err != nil {
-		panic("failed to load AWS config: " + err.Error())
+		return aws.Config{}, fmt.Errorf("load AWS config: %w", err)
 	}
-
-	clientOpts := []func(*dynamodb.Options){}
-	if cfg.AWSEndpointURL != "" {
-		clientOpts = append(clientOpts, func(o *dynamodb.Options) {
-			o.BaseEndpoint = aws.String(cfg.AWSEndpointURL)
-		})
-	}
-
-	return dynamodb.NewFromConfig(awsCfg, clientOpts...)
+	return awsCfg, nil
 }
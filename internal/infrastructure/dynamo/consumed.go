@@ -0,0 +1,127 @@
+package dynamo
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/go-api-nosql/internal/pkg/dbcost"
+)
+
+// ConsumedCapacityAPIOptions asks DynamoDB for the capacity each item
+// operation consumes, broken down by table and index, and adds it to the
+// dbcost.Meter of the call's context. Calls without a meter, such as
+// background jobs, are left alone.
+func ConsumedCapacityAPIOptions() []func(*middleware.Stack) error {
+	meter := middleware.InitializeMiddlewareFunc("ConsumedCapacity", func(
+		ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		m := dbcost.FromContext(ctx)
+		if m == nil {
+			return next.HandleInitialize(ctx, in)
+		}
+		requestCapacity(in.Parameters)
+		out, md, err := next.HandleInitialize(ctx, in)
+		if err == nil {
+			if c, ok := consumedCall(out.Result); ok {
+				m.Add(c)
+			}
+		}
+		return out, md, err
+	})
+	return []func(*middleware.Stack) error{func(s *middleware.Stack) error {
+		return s.Initialize.Add(meter, middleware.After)
+	}}
+}
+
+// requestCapacity sets ReturnConsumedCapacity on params. It is set in place:
+// the value is the same on every call, so inputs reused across pages are
+// unaffected.
+func requestCapacity(params any) {
+	const indexes = types.ReturnConsumedCapacityIndexes
+	switch p := params.(type) {
+	case *dynamodb.GetItemInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.PutItemInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.UpdateItemInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.DeleteItemInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.QueryInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.ScanInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.BatchGetItemInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.BatchWriteItemInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.TransactGetItemsInput:
+		p.ReturnConsumedCapacity = indexes
+	case *dynamodb.TransactWriteItemsInput:
+		p.ReturnConsumedCapacity = indexes
+	}
+}
+
+// consumedCall converts the consumed capacity reported in result, an item
+// operation's output.
+func consumedCall(result any) (dbcost.Call, bool) {
+	switch o := result.(type) {
+	case *dynamodb.GetItemOutput:
+		return toCall(false, false, o.ConsumedCapacity), true
+	case *dynamodb.PutItemOutput:
+		return toCall(true, false, o.ConsumedCapacity), true
+	case *dynamodb.UpdateItemOutput:
+		return toCall(true, false, o.ConsumedCapacity), true
+	case *dynamodb.DeleteItemOutput:
+		return toCall(true, false, o.ConsumedCapacity), true
+	case *dynamodb.QueryOutput:
+		return toCall(false, false, o.ConsumedCapacity), true
+	case *dynamodb.ScanOutput:
+		return toCall(false, true, o.ConsumedCapacity), true
+	case *dynamodb.BatchGetItemOutput:
+		return toCall(false, false, ptrs(o.ConsumedCapacity)...), true
+	case *dynamodb.BatchWriteItemOutput:
+		return toCall(true, false, ptrs(o.ConsumedCapacity)...), true
+	case *dynamodb.TransactGetItemsOutput:
+		return toCall(false, false, ptrs(o.ConsumedCapacity)...), true
+	case *dynamodb.TransactWriteItemsOutput:
+		return toCall(true, false, ptrs(o.ConsumedCapacity)...), true
+	}
+	return dbcost.Call{}, false
+}
+
+// toCall sums ccs into one call; batch and transaction outputs report each
+// table separately.
+func toCall(write, scan bool, ccs ...*types.ConsumedCapacity) dbcost.Call {
+	c := dbcost.Call{Write: write, Scan: scan, Sources: map[string]float64{}}
+	for _, cc := range ccs {
+		if cc == nil {
+			continue
+		}
+		table := aws.ToString(cc.TableName)
+		c.Units += aws.ToFloat64(cc.CapacityUnits)
+		if cc.Table != nil {
+			c.Sources[table] += aws.ToFloat64(cc.Table.CapacityUnits)
+		} else if len(cc.GlobalSecondaryIndexes) == 0 && len(cc.LocalSecondaryIndexes) == 0 {
+			c.Sources[table] += aws.ToFloat64(cc.CapacityUnits)
+		}
+		for name, idx := range cc.GlobalSecondaryIndexes {
+			c.Sources[table+"/"+name] += aws.ToFloat64(idx.CapacityUnits)
+		}
+		for name, idx := range cc.LocalSecondaryIndexes {
+			c.Sources[table+"/"+name] += aws.ToFloat64(idx.CapacityUnits)
+		}
+	}
+	return c
+}
+
+func ptrs(ccs []types.ConsumedCapacity) []*types.ConsumedCapacity {
+	out := make([]*types.ConsumedCapacity, len(ccs))
+	for i := range ccs {
+		out[i] = &ccs[i]
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package dynamo
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumedCall(t *testing.T) {
+	c, ok := consumedCall(&dynamodb.ScanOutput{ConsumedCapacity: &types.ConsumedCapacity{
+		TableName:     aws.String("users"),
+		CapacityUnits: aws.Float64(12.5),
+		Table:         &types.Capacity{CapacityUnits: aws.Float64(2.5)},
+		GlobalSecondaryIndexes: map[string]types.Capacity{
+			"email_key-index": {CapacityUnits: aws.Float64(10)},
+		},
+	}})
+	require.True(t, ok)
+	assert.True(t, c.Scan)
+	assert.False(t, c.Write)
+	assert.Equal(t, 12.5, c.Units)
+	assert.Equal(t, map[string]float64{"users": 2.5, "users/email_key-index": 10}, c.Sources)
+
+	c, ok = consumedCall(&dynamodb.BatchWriteItemOutput{ConsumedCapacity: []types.ConsumedCapacity{
+		{TableName: aws.String("users"), CapacityUnits: aws.Float64(2)},
+		{TableName: aws.String("devices"), CapacityUnits: aws.Float64(1)},
+	}})
+	require.True(t, ok)
+	assert.True(t, c.Write)
+	assert.Equal(t, 3.0, c.Units)
+	assert.Equal(t, map[string]float64{"users": 2, "devices": 1}, c.Sources)
+
+	_, ok = consumedCall(&dynamodb.DescribeTableOutput{})
+	assert.False(t, ok)
+}
+
+func TestRequestCapacity(t *testing.T) {
+	in := &dynamodb.QueryInput{}
+	requestCapacity(in)
+	assert.Equal(t, types.ReturnConsumedCapacityIndexes, in.ReturnConsumedCapacity)
+}
@@ -0,0 +1,44 @@
+package dynamo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// approxCountTTL bounds how often a table's item count is re-read. DynamoDB
+// itself only refreshes the figure about every six hours, and DescribeTable
+// calls count against the control-plane rate limit.
+const approxCountTTL = 10 * time.Minute
+
+// approxCount caches the ItemCount DynamoDB reports for a table.
+type approxCount struct {
+	client    *dynamodb.Client
+	tableName string
+
+	mu        sync.Mutex
+	value     int64
+	fetchedAt time.Time
+}
+
+func newApproxCount(client *dynamodb.Client, tableName string) *approxCount {
+	return &approxCount{client: client, tableName: tableName}
+}
+
+func (c *approxCount) get(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < approxCountTTL {
+		return c.value, nil
+	}
+	out, err := c.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(c.tableName)})
+	if err != nil {
+		return 0, err
+	}
+	c.value = aws.ToInt64(out.Table.ItemCount)
+	c.fetchedAt = time.Now()
+	return c.value, nil
+}
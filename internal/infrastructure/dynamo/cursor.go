@@ -0,0 +1,101 @@
+package dynamo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// CursorCodec turns a query's LastEvaluatedKey into an opaque pagination
+// cursor and back. The cursor carries the complete key (table and index
+// attributes) and an HMAC over it, so clients cannot forge or edit one.
+//
+// Each cursor is bound to a scope naming the query it came from (e.g.
+// "activities/<user_id>"); a cursor presented to a different scope is rejected,
+// so one user's cursor cannot page through another user's partition.
+type CursorCodec struct {
+	secret []byte
+}
+
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// cursorAttr is the JSON form of a key attribute. Keys in this app are only
+// ever strings or numbers.
+type cursorAttr struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+}
+
+// Encode returns the cursor for key, or "" when key is empty (no more pages).
+func (c *CursorCodec) Encode(scope string, key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+	attrs := make(map[string]cursorAttr, len(key))
+	for name, v := range key {
+		switch v := v.(type) {
+		case *types.AttributeValueMemberS:
+			attrs[name] = cursorAttr{S: &v.Value}
+		case *types.AttributeValueMemberN:
+			attrs[name] = cursorAttr{N: &v.Value}
+		default:
+			return "", fmt.Errorf("cursor: unsupported key attribute %s (%T)", name, v)
+		}
+	}
+	payload, err := json.Marshal(attrs)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(scope, payload)), nil
+}
+
+// Decode verifies cursor against scope and returns the ExclusiveStartKey it
+// encodes. Malformed, tampered and foreign-scope cursors are ErrBadRequest.
+func (c *CursorCodec) Decode(scope, cursor string) (map[string]types.AttributeValue, error) {
+	invalid := fmt.Errorf("invalid cursor: %w", domain.ErrBadRequest)
+	encPayload, encSig, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, invalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return nil, invalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, c.sign(scope, payload)) {
+		return nil, invalid
+	}
+	var attrs map[string]cursorAttr
+	if err := json.Unmarshal(payload, &attrs); err != nil || len(attrs) == 0 {
+		return nil, invalid
+	}
+	key := make(map[string]types.AttributeValue, len(attrs))
+	for name, a := range attrs {
+		switch {
+		case a.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *a.S}
+		case a.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *a.N}
+		default:
+			return nil, invalid
+		}
+	}
+	return key, nil
+}
+
+func (c *CursorCodec) sign(scope string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
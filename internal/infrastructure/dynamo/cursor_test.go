@@ -0,0 +1,98 @@
+package dynamo
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gsiKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"enable":     &types.AttributeValueMemberN{Value: "1"},
+		"created_at": &types.AttributeValueMemberS{Value: "2026-01-02T03:04:05Z"},
+		"user_id":    &types.AttributeValueMemberS{Value: "01HUSER"},
+	}
+}
+
+func TestCursorCodec_RoundTripsFullKey(t *testing.T) {
+	c := NewCursorCodec([]byte("secret"))
+
+	cursor, err := c.Encode("users", gsiKey())
+	require.NoError(t, err)
+	key, err := c.Decode("users", cursor)
+
+	require.NoError(t, err)
+	assert.Equal(t, gsiKey(), key)
+}
+
+func TestCursorCodec_EmptyKeyIsNoCursor(t *testing.T) {
+	cursor, err := NewCursorCodec([]byte("secret")).Encode("users", nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, cursor)
+}
+
+func TestCursorCodec_RejectsTamperedPayload(t *testing.T) {
+	c := NewCursorCodec([]byte("secret"))
+	cursor, err := c.Encode("users", gsiKey())
+	require.NoError(t, err)
+	forged, err := NewCursorCodec([]byte("other")).Encode("users", gsiKey())
+	require.NoError(t, err)
+
+	_, err = c.Decode("users", forged)
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	_, err = c.Decode("users", "x"+cursor)
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestCursorCodec_RejectsOtherScope(t *testing.T) {
+	c := NewCursorCodec([]byte("secret"))
+	cursor, err := c.Encode("activities/u1", gsiKey())
+	require.NoError(t, err)
+
+	_, err = c.Decode("activities/u2", cursor)
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func TestCursorCodec_RejectsLegacyCursor(t *testing.T) {
+	_, err := NewCursorCodec([]byte("secret")).Decode("users", "MDFIVVNFUg")
+
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+}
+
+func FuzzCursorCodec_Decode(f *testing.F) {
+	c := NewCursorCodec([]byte("secret"))
+	valid, err := c.Encode("users", gsiKey())
+	require.NoError(f, err)
+	f.Add(valid, []byte(`{"user_id":{"S":"01HUSER"}}`))
+	f.Add("", []byte(`{}`))
+	f.Add("a.b", []byte(`{"n":{"N":"1"},"x":{}}`))
+	f.Add("..", []byte(`null`))
+
+	f.Fuzz(func(t *testing.T, cursor string, payload []byte) {
+		// Arbitrary cursors almost never pass the signature check, so also
+		// sign the fuzzed payload to reach the parsing behind it.
+		signed := base64.RawURLEncoding.EncodeToString(payload) + "." +
+			base64.RawURLEncoding.EncodeToString(c.sign("users", payload))
+		for _, in := range []string{cursor, signed} {
+			key, err := c.Decode("users", in)
+			if err != nil {
+				require.ErrorIs(t, err, domain.ErrBadRequest)
+				continue
+			}
+			require.NotEmpty(t, key)
+			for name, v := range key {
+				switch v.(type) {
+				case *types.AttributeValueMemberS, *types.AttributeValueMemberN:
+				default:
+					t.Fatalf("attribute %q decoded as %T", name, v)
+				}
+			}
+		}
+	})
+}
@@ -34,6 +34,21 @@ func (r *DeviceRepo) Put(ctx context.Context, d *domain.Device) error {
 	return err
 }
 
+// BatchPut writes devices in batches, overwriting any existing item with
+// the same ID.
+func (r *DeviceRepo) BatchPut(ctx context.Context, devices []domain.Device) error {
+	requests, err := putRequests(devices)
+	if err != nil {
+		return fmt.Errorf("marshal device: %w", err)
+	}
+	return batchWrite(ctx, r.client, r.tableName, requests)
+}
+
+// BatchDelete permanently removes the devices in ids. Missing IDs are ignored.
+func (r *DeviceRepo) BatchDelete(ctx context.Context, ids []string) error {
+	return batchWrite(ctx, r.client, r.tableName, deleteRequests("device_id", ids))
+}
+
 func (r *DeviceRepo) Get(ctx context.Context, deviceID string) (*domain.Device, error) {
 	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(r.tableName),
@@ -52,15 +67,22 @@ func (r *DeviceRepo) Get(ctx context.Context, deviceID string) (*domain.Device,
 	return &d, nil
 }
 
-func (r *DeviceRepo) GetByUUID(ctx context.Context, uuid string) (*domain.Device, error) {
+// GetByUUID returns userID's enabled device with the given client UUID. The
+// same UUID may be registered once per account.
+func (r *DeviceRepo) GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error) {
 	out, err := r.client.Query(ctx, &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
 		IndexName:              aws.String("device_uuid-index"),
 		KeyConditionExpression: aws.String("device_uuid = :u"),
+		FilterExpression:       aws.String("user_id = :uid AND #en = :t"),
+		ExpressionAttributeNames: map[string]string{
+			"#en": "enable",
+		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":u": &types.AttributeValueMemberS{Value: uuid},
+			":u":   &types.AttributeValueMemberS{Value: uuid},
+			":uid": &types.AttributeValueMemberS{Value: userID},
+			":t":   &types.AttributeValueMemberBOOL{Value: true},
 		},
-		Limit: aws.Int32(1),
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,83 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// dbError is a DynamoDB error translated to a domain sentinel. It unwraps to
+// both, so callers can test for domain.ErrConflict and still errors.As the
+// SDK error, e.g. to read ConditionalCheckFailedException.Item.
+type dbError struct {
+	msg  string
+	kind error
+	err  error
+}
+
+func (e *dbError) Error() string   { return e.msg + ": " + e.kind.Error() }
+func (e *dbError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// errorTranslation is added to every client by NewClient, so each repo gets
+// the same mapping without checking SDK error codes itself. It runs outside
+// the retryer and only sees the error of the last attempt.
+var errorTranslation = middleware.InitializeMiddlewareFunc("ErrorTranslation", func(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	out, md, err := next.HandleInitialize(ctx, in)
+	return out, md, translateError(err)
+})
+
+// translateError maps failed write conditions and transaction conflicts to
+// domain.ErrConflict, and throttling and DynamoDB outages that outlasted the
+// SDK's retries to domain.ErrUnavailable. Other errors are returned as they
+// are. A missing item is not an error in DynamoDB; the repos return
+// domain.ErrNotFound for it.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var quota ratelimit.QuotaExceededError
+	if errors.As(err, &quota) {
+		return &dbError{msg: "database retry budget exhausted", kind: domain.ErrUnavailable, err: err}
+	}
+	var api smithy.APIError
+	if !errors.As(err, &api) {
+		return err
+	}
+	switch api.ErrorCode() {
+	case "ConditionalCheckFailedException":
+		return &dbError{msg: "write condition failed", kind: domain.ErrConflict, err: err}
+	case "TransactionConflictException", "ReplicatedWriteConflictException":
+		return &dbError{msg: "concurrent write to the same item", kind: domain.ErrConflict, err: err}
+	case "TransactionCanceledException":
+		if conditionFailed(err) {
+			return &dbError{msg: "transaction condition failed", kind: domain.ErrConflict, err: err}
+		}
+	case "ProvisionedThroughputExceededException", "ThrottlingException", "RequestLimitExceeded":
+		return &dbError{msg: "database is throttling requests", kind: domain.ErrUnavailable, err: err}
+	case "InternalServerError", "ServiceUnavailable":
+		return &dbError{msg: "database is unavailable", kind: domain.ErrUnavailable, err: err}
+	}
+	return err
+}
+
+// conditionFailed reports whether a transaction was canceled because one of
+// its condition checks failed.
+func conditionFailed(err error) bool {
+	var tce *types.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		return false
+	}
+	for _, r := range tce.CancellationReasons {
+		if r.Code != nil && *r.Code == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
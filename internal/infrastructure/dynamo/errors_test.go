@@ -0,0 +1,73 @@
+package dynamo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingClient returns a client whose every call fails with body.
+func failingClient(t *testing.T, body string) *dynamodb.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return testClient(t, srv.URL, func(o *dynamodb.Options) { o.RetryMaxAttempts = 1 })
+}
+
+func TestErrorTranslation(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"conditional check", `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`, domain.ErrConflict},
+		{"throttled", `{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"slow down"}`, domain.ErrUnavailable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := failingClient(t, tc.body)
+			_, err := client.PutItem(t.Context(), &dynamodb.PutItemInput{
+				TableName: aws.String("users"),
+				Item:      strKey("user_id", "u1"),
+			})
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestErrorTranslation_TransactionCondition(t *testing.T) {
+	client := failingClient(t, `{"__type":"com.amazonaws.dynamodb.v20120810#TransactionCanceledException","message":"canceled","CancellationReasons":[{"Code":"None"},{"Code":"ConditionalCheckFailed"}]}`)
+	_, err := client.TransactWriteItems(t.Context(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{{Put: &types.Put{TableName: aws.String("users"), Item: strKey("user_id", "u1")}}},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestErrorTranslation_KeepsSDKError(t *testing.T) {
+	client := failingClient(t, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"failed","Item":{"user_id":{"S":"u1"}}}`)
+	_, err := client.PutItem(t.Context(), &dynamodb.PutItemInput{TableName: aws.String("users"), Item: strKey("user_id", "u1")})
+
+	var ccf *types.ConditionalCheckFailedException
+	assert.True(t, errors.As(err, &ccf))
+	assert.Equal(t, strKey("user_id", "u1"), ccf.Item)
+}
+
+func TestErrorTranslation_LeavesOtherErrors(t *testing.T) {
+	client := failingClient(t, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"no table"}`)
+	_, err := client.PutItem(t.Context(), &dynamodb.PutItemInput{TableName: aws.String("users"), Item: strKey("user_id", "u1")})
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrConflict)
+	assert.NotErrorIs(t, err, domain.ErrUnavailable)
+}
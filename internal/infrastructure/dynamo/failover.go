@@ -0,0 +1,134 @@
+package dynamo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/go-api-nosql/internal/config"
+)
+
+// regionFailover is the DynamoDB client's HTTP client when DYNAMO_FAILOVER is
+// on. Requests go to the primary region (AWS_REGION) first; when a region
+// fails with a transport error or a 5xx, the same request is re-signed and
+// sent to the next replica region, and the failed region is skipped until
+// its cooldown passes. Global tables replicate every write, so any replica
+// can serve any request; reads may briefly lag the primary.
+type regionFailover struct {
+	next     aws.HTTPClient
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+	regions  []string // primary first
+	endpoint func(region string) string
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	downUntil map[string]time.Time
+}
+
+func newRegionFailover(next aws.HTTPClient, creds aws.CredentialsProvider, cfg *config.Config) *regionFailover {
+	endpoint := func(region string) string { return "https://dynamodb." + region + ".amazonaws.com" }
+	if cfg.AWSEndpointURL != "" {
+		// LocalStack serves every region from one endpoint and picks the
+		// region from the signature.
+		endpoint = func(string) string { return cfg.AWSEndpointURL }
+	}
+	return &regionFailover{
+		next:      next,
+		creds:     creds,
+		signer:    v4.NewSigner(),
+		regions:   append([]string{cfg.AWSRegion}, cfg.DynamoReplicaRegions...),
+		endpoint:  endpoint,
+		cooldown:  cfg.DynamoFailoverCooldown,
+		now:       time.Now,
+		downUntil: map[string]time.Time{},
+	}
+}
+
+func (f *regionFailover) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	regions := f.candidates()
+	var resp *http.Response
+	var err error
+	for i, region := range regions {
+		resp, err = f.send(req, region, body)
+		if err == nil && resp.StatusCode < 500 {
+			return resp, nil
+		}
+		if i == len(regions)-1 {
+			break
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+		f.markDown(region)
+	}
+	return resp, err
+}
+
+// send issues req against region. The SDK signed req for the primary
+// region, so any other region gets a fresh signature.
+func (f *regionFailover) send(req *http.Request, region string, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	if region == f.regions[0] {
+		return f.next.Do(out)
+	}
+	u, err := url.Parse(f.endpoint(region))
+	if err != nil {
+		return nil, err
+	}
+	out.URL.Scheme, out.URL.Host, out.Host = u.Scheme, u.Host, ""
+	for _, h := range []string{"Authorization", "X-Amz-Date", "X-Amz-Security-Token"} {
+		out.Header.Del(h)
+	}
+	creds, err := f.creds.Retrieve(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	if err := f.signer.SignHTTP(req.Context(), creds, out, hex.EncodeToString(sum[:]), "dynamodb", region, f.now()); err != nil {
+		return nil, err
+	}
+	return f.next.Do(out)
+}
+
+// candidates returns the regions to try in order, leaving out those still
+// cooling down. When every region is down it tries them all again.
+func (f *regionFailover) candidates() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	up := make([]string, 0, len(f.regions))
+	for _, r := range f.regions {
+		if now.After(f.downUntil[r]) {
+			up = append(up, r)
+		}
+	}
+	if len(up) == 0 {
+		return f.regions
+	}
+	return up
+}
+
+func (f *regionFailover) markDown(region string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.downUntil[region] = f.now().Add(f.cooldown)
+}
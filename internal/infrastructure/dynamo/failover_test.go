@@ -0,0 +1,81 @@
+package dynamo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegionFailover(t *testing.T) {
+	var primaryHits atomic.Int32
+	var primaryStatus atomic.Int32
+	primaryStatus.Store(http.StatusInternalServerError)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		w.WriteHeader(int(primaryStatus.Load()))
+	}))
+	defer primary.Close()
+	var replicaAuth, replicaBody string
+	replica := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		replicaAuth = r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		replicaBody = string(b)
+	}))
+	defer replica.Close()
+
+	cfg := &config.Config{AWSRegion: "us-east-1", DynamoReplicaRegions: []string{"eu-west-1"}, DynamoFailoverCooldown: time.Minute}
+	f := newRegionFailover(http.DefaultClient, credentials.NewStaticCredentialsProvider("id", "secret", ""), cfg)
+	f.endpoint = func(string) string { return replica.URL }
+	now := time.Now()
+	f.now = func() time.Time { return now }
+	do := func() *http.Response {
+		req, err := http.NewRequest(http.MethodPost, primary.URL+"/", strings.NewReader(`{"TableName":"users"}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=id/20260101/us-east-1/dynamodb/aws4_request")
+		resp, err := f.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := do()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(1), primaryHits.Load())
+	assert.Contains(t, replicaAuth, "/eu-west-1/dynamodb/", "replica request is re-signed for its region")
+	assert.Equal(t, `{"TableName":"users"}`, replicaBody, "the body is resent")
+
+	primaryStatus.Store(http.StatusOK)
+	do()
+	assert.Equal(t, int32(1), primaryHits.Load(), "primary is skipped during its cooldown")
+
+	now = now.Add(2 * time.Minute)
+	resp = do()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), primaryHits.Load(), "primary is tried again after the cooldown")
+}
+
+func TestRegionFailover_ClientErrorsDoNotFailOver(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer primary.Close()
+	cfg := &config.Config{AWSRegion: "us-east-1", DynamoReplicaRegions: []string{"eu-west-1"}, DynamoFailoverCooldown: time.Minute}
+	f := newRegionFailover(http.DefaultClient, credentials.NewStaticCredentialsProvider("id", "secret", ""), cfg)
+	f.endpoint = func(string) string { t.Fatal("replica must not be called"); return "" }
+
+	req, err := http.NewRequest(http.MethodPost, primary.URL+"/", strings.NewReader("{}"))
+	require.NoError(t, err)
+	resp, err := f.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -8,4 +8,17 @@ const (
 	fieldRead             = "readed"
 	fieldRefreshToken     = "refresh_token"
 	fieldRefreshExpiresAt = "refresh_expires_at"
+	fieldEmailKey         = "email_key"
+	fieldUsernameKey      = "username_key"
+	fieldRole             = "role"
+	fieldCreatedAt        = "created_at"
+	fieldEmailConfirmed   = "email_confirmed"
+	fieldExpiresAt        = "expires_at"
+	fieldUserID           = "user_id"
+	fieldPhone            = "phone"
+	fieldBirthday         = "birthday"
+	fieldPIIKeyVersion    = "pii_key_version"
+	fieldAnonymizedAt     = "anonymized_at"
+	fieldOnboardingSent   = "onboarding_sent"
+	fieldBirthdayGreeted  = "birthday_greeted"
 )
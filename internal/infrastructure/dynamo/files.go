@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/go-api-nosql/internal/domain"
 )
 
@@ -15,12 +16,19 @@ import (
 type FileRepo struct {
 	client    *dynamodb.Client
 	tableName string
+	scan      ParallelScan
 }
 
 func NewFileRepo(client *dynamodb.Client, tableName string) *FileRepo {
 	return &FileRepo{client: client, tableName: tableName}
 }
 
+// WithParallelScan sets how Each splits its full-table scan.
+func (r *FileRepo) WithParallelScan(p ParallelScan) *FileRepo {
+	r.scan = p
+	return r
+}
+
 func (r *FileRepo) Put(ctx context.Context, f *domain.File) error {
 	item, err := attributevalue.MarshalMap(f)
 	if err != nil {
@@ -55,6 +63,50 @@ func (r *FileRepo) SoftDelete(ctx context.Context, fileID string) error {
 	return r.update(ctx, fileID, map[string]interface{}{fieldEnable: false})
 }
 
+// UsageByUploader totals the enabled files uploaded by userID.
+func (r *FileRepo) UsageByUploader(ctx context.Context, userID string) (domain.StorageUsage, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("uploaded_by_user_id-index"),
+		KeyConditionExpression: aws.String("uploaded_by_user_id = :uid"),
+		FilterExpression:       aws.String("#en = :true"),
+		ProjectionExpression:   aws.String("#size"),
+		ExpressionAttributeNames: map[string]string{
+			"#en":   fieldEnable,
+			"#size": "size",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid":  &types.AttributeValueMemberS{Value: userID},
+			":true": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	}
+	var usage domain.StorageUsage
+	err := queryEach(ctx, r.client, input, func(f domain.File) error {
+		usage.Files++
+		usage.Bytes += f.Size
+		return nil
+	})
+	return usage, err
+}
+
+// Each calls fn for every file in the table, including soft-deleted ones. It
+// is a parallel full scan, meant for reindexing.
+func (r *FileRepo) Each(ctx context.Context, fn func(domain.File) error) error {
+	input := &dynamodb.ScanInput{TableName: aws.String(r.tableName)}
+	return r.scan.eachPage(allowScans(ctx), r.client, input, func(items []map[string]types.AttributeValue) error {
+		files := make([]domain.File, 0, len(items))
+		if err := attributevalue.UnmarshalListOfMaps(items, &files); err != nil {
+			return err
+		}
+		for _, f := range files {
+			if err := fn(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *FileRepo) update(ctx context.Context, fileID string, updates map[string]interface{}) error {
 	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
 	ue, err := buildUpdateExpr(updates)
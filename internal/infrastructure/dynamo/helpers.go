@@ -1,11 +1,17 @@
 package dynamo
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
 )
 
 // strKey builds a DynamoDB primary key map with a single string attribute.
@@ -61,3 +67,157 @@ func buildUpdateExpr(updates map[string]interface{}) (updateExpr, error) {
 	}
 	return ue, nil
 }
+
+// queryEach pages through input, unmarshals every item into T and calls fn
+// with it.
+func queryEach[T any](ctx context.Context, client *dynamodb.Client, input *dynamodb.QueryInput, fn func(T) error) error {
+	for {
+		out, err := client.Query(ctx, input)
+		if err != nil {
+			return err
+		}
+		var items []T
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// Items of the small catalog tables (statuses, roles, templates, app
+// versions) carry listAttr, so listing a table is a Query on listIndex, whose
+// single partition holds every item, rather than a Scan.
+const (
+	listAttr  = "list_key"
+	listIndex = "list_key-index"
+)
+
+var listKey = &types.AttributeValueMemberS{Value: "all"}
+
+// listed stamps item with listAttr and returns it.
+func listed(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	item[listAttr] = listKey
+	return item
+}
+
+// queryList returns every item of a catalog table.
+func queryList[T any](ctx context.Context, client *dynamodb.Client, table string) ([]T, error) {
+	var items []T
+	err := queryEach(ctx, client, &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		IndexName:                 aws.String(listIndex),
+		KeyConditionExpression:    aws.String("#lk = :lk"),
+		ExpressionAttributeNames:  map[string]string{"#lk": listAttr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":lk": listKey},
+	}, func(item T) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// BackfillListKeys stamps listAttr on the items of catalog tables written
+// before listIndex existed. It is idempotent and returns the number of items
+// updated.
+func BackfillListKeys(ctx context.Context, client *dynamodb.Client, tables ...string) (int, error) {
+	ctx = allowScans(ctx)
+	n := 0
+	for _, table := range tables {
+		updated, err := backfillListKey(ctx, client, table)
+		n += updated
+		if err != nil {
+			return n, fmt.Errorf("backfill %s: %w", table, err)
+		}
+	}
+	return n, nil
+}
+
+func backfillListKey(ctx context.Context, client *dynamodb.Client, table string) (int, error) {
+	desc, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return 0, err
+	}
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(table),
+		FilterExpression:         aws.String("attribute_not_exists(#lk)"),
+		ExpressionAttributeNames: map[string]string{"#lk": listAttr},
+	}
+	n := 0
+	for {
+		out, err := client.Scan(ctx, input)
+		if err != nil {
+			return n, err
+		}
+		for _, item := range out.Items {
+			key := make(map[string]types.AttributeValue, len(desc.Table.KeySchema))
+			for _, k := range desc.Table.KeySchema {
+				key[aws.ToString(k.AttributeName)] = item[aws.ToString(k.AttributeName)]
+			}
+			if _, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:                 aws.String(table),
+				Key:                       key,
+				UpdateExpression:          aws.String("SET #lk = :lk"),
+				ExpressionAttributeNames:  map[string]string{"#lk": listAttr},
+				ExpressionAttributeValues: map[string]types.AttributeValue{":lk": listKey},
+			}); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return n, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// sweep pages through input, a filtered scan whose projection holds exactly
+// the table's key attributes, and batch-deletes every matching item unless
+// dryRun. It returns the number of matching items.
+func (p ParallelScan) sweep(ctx context.Context, client *dynamodb.Client, input *dynamodb.ScanInput, dryRun bool) (int, error) {
+	n := 0
+	err := p.eachPage(allowScans(ctx), client, input, func(keys []map[string]types.AttributeValue) error {
+		if !dryRun {
+			deletes := make([]types.WriteRequest, len(keys))
+			for i, key := range keys {
+				deletes[i] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}}
+			}
+			if err := batchWrite(ctx, client, aws.ToString(input.TableName), deletes); err != nil {
+				return err
+			}
+		}
+		n += len(keys)
+		return nil
+	})
+	return n, err
+}
+
+// putNew writes input.Item only if no item with its key exists yet; keyAttr
+// is the table's partition key. A write that already landed, because a retry
+// or a failover resent it after the first attempt succeeded, finds an
+// identical item and counts as success; the tenant_id stamped by
+// TenantAPIOptions is not compared. A different item under the same key is
+// domain.ErrConflict.
+func putNew(ctx context.Context, client *dynamodb.Client, input *dynamodb.PutItemInput, keyAttr string) error {
+	input.ConditionExpression = aws.String("attribute_not_exists(#k)")
+	input.ExpressionAttributeNames = map[string]string{"#k": keyAttr}
+	input.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+	_, err := client.PutItem(ctx, input)
+	var ccf *types.ConditionalCheckFailedException
+	if !errors.As(err, &ccf) {
+		return err
+	}
+	delete(ccf.Item, tenantAttr)
+	if reflect.DeepEqual(ccf.Item, input.Item) {
+		return nil
+	}
+	return fmt.Errorf("item already exists: %w", domain.ErrConflict)
+}
@@ -1,13 +1,27 @@
 package dynamo
 
 import (
+	"path/filepath"
+	"regexp"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// testClient returns a client that sends every request to endpoint, usually
+// an httptest server standing in for DynamoDB.
+func testClient(t *testing.T, endpoint string, optFns ...func(*dynamodb.Options)) *dynamodb.Client {
+	t.Helper()
+	cfg := &config.Config{AWSRegion: "us-east-1", AWSEndpointURL: endpoint, AWSAccessKeyID: "id", AWSSecretKey: "secret"}
+	client, err := NewClient(cfg, optFns...)
+	require.NoError(t, err)
+	return client
+}
+
 func TestBuildUpdateExpr_SingleField(t *testing.T) {
 	ue, err := buildUpdateExpr(map[string]interface{}{"username": "alice"})
 	require.NoError(t, err)
@@ -52,3 +66,36 @@ func TestBuildUpdateExpr_EmptyMap_ReturnsError(t *testing.T) {
 	_, err := buildUpdateExpr(map[string]interface{}{})
 	assert.ErrorContains(t, err, "no fields to update")
 }
+
+// updateExprShape is the only expression buildUpdateExpr may produce: field
+// names and values always go through placeholders, never into the string.
+var updateExprShape = regexp.MustCompile(`^SET #f\d+ = :v\d+(, #f\d+ = :v\d+)*$`)
+
+func FuzzBuildUpdateExpr(f *testing.F) {
+	f.Add("username", "alice", "enable", int64(1))
+	f.Add("a = :v0, #x", "REMOVE b", "", int64(-1))
+	f.Add("#f0", ":v1", "#f0", int64(0))
+
+	f.Fuzz(func(t *testing.T, k1, v1, k2 string, n int64) {
+		updates := map[string]interface{}{k1: v1, k2: n}
+		ue, err := buildUpdateExpr(updates)
+		require.NoError(t, err)
+
+		require.Regexp(t, updateExprShape, ue.Expr)
+		require.Len(t, ue.Names, len(updates))
+		require.Len(t, ue.Values, len(updates))
+		for placeholder, name := range ue.Names {
+			require.Contains(t, updates, name, "placeholder %s", placeholder)
+		}
+	})
+}
+
+func TestNewClient_ReturnsConfigError(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_PROFILE", "missing")
+
+	_, err := NewClient(&config.Config{AWSRegion: "us-east-1"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load AWS config")
+}
@@ -0,0 +1,151 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// launchStatsKey is the row holding the launch gate's counters. It carries
+// no list key, so List never returns it.
+const launchStatsKey = "#stats"
+
+// LaunchRepo provides typed DynamoDB operations for the launch allowlist
+// table. Entries are keyed by "<kind>#<value>".
+type LaunchRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewLaunchRepo(client *dynamodb.Client, tableName string) *LaunchRepo {
+	return &LaunchRepo{client: client, tableName: tableName}
+}
+
+func launchKey(kind, value string) map[string]types.AttributeValue {
+	return strKey("entry_key", kind+"#"+value)
+}
+
+type launchItem struct {
+	Key string `dynamodbav:"entry_key"`
+	domain.LaunchEntry
+}
+
+// Put stores e, returning domain.ErrConflict if the entry already exists.
+func (r *LaunchRepo) Put(ctx context.Context, e *domain.LaunchEntry) error {
+	item, err := attributevalue.MarshalMap(launchItem{Key: e.Kind + "#" + e.Value, LaunchEntry: *e})
+	if err != nil {
+		return fmt.Errorf("marshal launch entry: %w", err)
+	}
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      listed(item),
+	}, "entry_key")
+}
+
+func (r *LaunchRepo) Get(ctx context.Context, kind, value string) (*domain.LaunchEntry, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       launchKey(kind, value),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("launch entry not found: %w", domain.ErrNotFound)
+	}
+	var e domain.LaunchEntry
+	if err := attributevalue.UnmarshalMap(out.Item, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *LaunchRepo) List(ctx context.Context) ([]domain.LaunchEntry, error) {
+	return queryList[domain.LaunchEntry](ctx, r.client, r.tableName)
+}
+
+// Delete removes an entry, returning domain.ErrNotFound if there is none.
+func (r *LaunchRepo) Delete(ctx context.Context, kind, value string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 launchKey(kind, value),
+		ConditionExpression: aws.String("attribute_exists(entry_key)"),
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("launch entry not found: %w", domain.ErrNotFound)
+	}
+	return err
+}
+
+// Redeem counts one registration against an entry. An entry that was
+// removed, or an invite whose uses have run out, is domain.ErrConflict.
+func (r *LaunchRepo) Redeem(ctx context.Context, kind, value string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 launchKey(kind, value),
+		UpdateExpression:    aws.String("ADD uses :one"),
+		ConditionExpression: aws.String("attribute_exists(entry_key) AND (attribute_not_exists(max_uses) OR uses < max_uses)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("launch entry is used up or was removed: %w", domain.ErrConflict)
+	}
+	return err
+}
+
+// CountGated counts a refused registration.
+func (r *LaunchRepo) CountGated(ctx context.Context) error {
+	return r.count(ctx, "gated")
+}
+
+// CountAdmitted counts a registration admitted by an entry of the given kind.
+func (r *LaunchRepo) CountAdmitted(ctx context.Context, kind string) error {
+	return r.count(ctx, "admitted_"+kind)
+}
+
+func (r *LaunchRepo) count(ctx context.Context, counter string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("entry_key", launchStatsKey),
+		UpdateExpression:          aws.String("ADD #c :one"),
+		ExpressionAttributeNames:  map[string]string{"#c": counter},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+	})
+	return err
+}
+
+// Stats returns the counters; Enabled is left to the caller.
+func (r *LaunchRepo) Stats(ctx context.Context) (domain.LaunchStats, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("entry_key", launchStatsKey),
+	})
+	if err != nil {
+		return domain.LaunchStats{}, err
+	}
+	var row struct {
+		Gated  int `dynamodbav:"gated"`
+		Email  int `dynamodbav:"admitted_email"`
+		Domain int `dynamodbav:"admitted_domain"`
+		Invite int `dynamodbav:"admitted_invite"`
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &row); err != nil {
+		return domain.LaunchStats{}, err
+	}
+	return domain.LaunchStats{
+		Gated: row.Gated,
+		Admitted: map[string]int{
+			domain.LaunchEmail: row.Email, domain.LaunchDomain: row.Domain, domain.LaunchInvite: row.Invite,
+		},
+	}, nil
+}
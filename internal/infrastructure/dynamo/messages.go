@@ -0,0 +1,116 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// MessageRepo provides typed DynamoDB operations for the messages table.
+type MessageRepo struct {
+	client    *dynamodb.Client
+	tableName string
+	cursors   *CursorCodec
+}
+
+func NewMessageRepo(client *dynamodb.Client, tableName string, cursors *CursorCodec) *MessageRepo {
+	return &MessageRepo{client: client, tableName: tableName, cursors: cursors}
+}
+
+func (r *MessageRepo) Put(ctx context.Context, m *domain.Message) error {
+	item, err := attributevalue.MarshalMap(m)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	}, "conversation_id")
+}
+
+// ListConversation returns a page of messages in a conversation, newest first.
+// cursor is an opaque signed cursor from a previous page (see CursorCodec).
+func (r *MessageRepo) ListConversation(ctx context.Context, conversationID string, limit int32, cursor string) ([]domain.Message, string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("conversation_id = :c"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":c": &types.AttributeValueMemberS{Value: conversationID},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(limit),
+	}
+	if cursor != "" {
+		key, err := r.cursors.Decode("messages/"+conversationID, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		input.ExclusiveStartKey = key
+	}
+	out, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, "", err
+	}
+	messages := make([]domain.Message, 0, len(out.Items))
+	if err := attributevalue.UnmarshalListOfMaps(out.Items, &messages); err != nil {
+		return nil, "", err
+	}
+	nextCursor, err := r.cursors.Encode("messages/"+conversationID, out.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return messages, nextCursor, nil
+}
+
+// ListUnread returns every unread message addressed to userID via the sparse unread_for-index GSI.
+func (r *MessageRepo) ListUnread(ctx context.Context, userID string) ([]domain.Message, error) {
+	var messages []domain.Message
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("unread_for-index"),
+		KeyConditionExpression: aws.String("unread_for = :u"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":u": &types.AttributeValueMemberS{Value: userID},
+		},
+	}
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		var page []domain.Message
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &page); err != nil {
+			return nil, err
+		}
+		messages = append(messages, page...)
+		if len(out.LastEvaluatedKey) == 0 {
+			return messages, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// MarkRead sets read=true and removes the message from the unread index.
+func (r *MessageRepo) MarkRead(ctx context.Context, conversationID string, sentAt int64) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"conversation_id": &types.AttributeValueMemberS{Value: conversationID},
+			"sent_at":         &types.AttributeValueMemberN{Value: strconv.FormatInt(sentAt, 10)},
+		},
+		UpdateExpression: aws.String("SET #rd = :t REMOVE unread_for"),
+		ExpressionAttributeNames: map[string]string{
+			"#rd": "read",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":t": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+	return err
+}
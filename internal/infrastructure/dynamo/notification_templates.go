@@ -0,0 +1,91 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// NotificationTemplateRepo provides typed DynamoDB operations for the notification_templates table.
+type NotificationTemplateRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewNotificationTemplateRepo(client *dynamodb.Client, tableName string) *NotificationTemplateRepo {
+	return &NotificationTemplateRepo{client: client, tableName: tableName}
+}
+
+// Create stores t, returning domain.ErrConflict if a template with the same name exists.
+func (r *NotificationTemplateRepo) Create(ctx context.Context, t *domain.NotificationTemplate) error {
+	item, err := attributevalue.MarshalMap(t)
+	if err != nil {
+		return fmt.Errorf("marshal notification template: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(r.tableName),
+		Item:                     listed(item),
+		ConditionExpression:      aws.String("attribute_not_exists(#n)"),
+		ExpressionAttributeNames: map[string]string{"#n": "name"},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("template %q already exists: %w", t.Name, domain.ErrConflict)
+	}
+	return err
+}
+
+func (r *NotificationTemplateRepo) Get(ctx context.Context, name string) (*domain.NotificationTemplate, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("name", name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("notification template not found: %w", domain.ErrNotFound)
+	}
+	var t domain.NotificationTemplate
+	if err := attributevalue.UnmarshalMap(out.Item, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List returns every template.
+func (r *NotificationTemplateRepo) List(ctx context.Context) ([]domain.NotificationTemplate, error) {
+	return queryList[domain.NotificationTemplate](ctx, r.client, r.tableName)
+}
+
+func (r *NotificationTemplateRepo) Update(ctx context.Context, name string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("name", name),
+		UpdateExpression:          aws.String(ue.Expr),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return err
+}
+
+// HardDelete permanently removes a template. Notifications already created from it keep their rendered text.
+func (r *NotificationTemplateRepo) HardDelete(ctx context.Context, name string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("name", name),
+	})
+	return err
+}
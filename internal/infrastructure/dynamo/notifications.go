@@ -2,7 +2,10 @@ package dynamo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -15,22 +18,43 @@ import (
 type NotificationRepo struct {
 	client    *dynamodb.Client
 	tableName string
+	scan      ParallelScan
 }
 
 func NewNotificationRepo(client *dynamodb.Client, tableName string) *NotificationRepo {
 	return &NotificationRepo{client: client, tableName: tableName}
 }
 
+// WithParallelScan sets how the retention sweep splits its full-table scan.
+func (r *NotificationRepo) WithParallelScan(p ParallelScan) *NotificationRepo {
+	r.scan = p
+	return r
+}
+
 func (r *NotificationRepo) Put(ctx context.Context, n *domain.Notification) error {
 	item, err := attributevalue.MarshalMap(n)
 	if err != nil {
 		return fmt.Errorf("marshal notification: %w", err)
 	}
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
 		Item:      item,
-	})
-	return err
+	}, "notification_id")
+}
+
+// BatchPut writes notifications in batches, overwriting any existing item with
+// the same ID.
+func (r *NotificationRepo) BatchPut(ctx context.Context, notifications []domain.Notification) error {
+	requests, err := putRequests(notifications)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+	return batchWrite(ctx, r.client, r.tableName, requests)
+}
+
+// BatchDelete permanently removes the notifications in ids. Missing IDs are ignored.
+func (r *NotificationRepo) BatchDelete(ctx context.Context, ids []string) error {
+	return batchWrite(ctx, r.client, r.tableName, deleteRequests("notification_id", ids))
 }
 
 func (r *NotificationRepo) Get(ctx context.Context, notificationID string) (*domain.Notification, error) {
@@ -52,15 +76,22 @@ func (r *NotificationRepo) Get(ctx context.Context, notificationID string) (*dom
 }
 
 // ListUnread queries the user_id-created_at GSI and filters for readed=0.
+// Scheduled, canceled and dismissed notifications are excluded; rows without a
+// status predate scheduling.
 func (r *NotificationRepo) ListUnread(ctx context.Context, userID string) ([]domain.Notification, error) {
 	out, err := r.client.Query(ctx, &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
 		IndexName:              aws.String("user_id-created_at-index"),
 		KeyConditionExpression: aws.String("user_id = :uid"),
-		FilterExpression:       aws.String("readed = :zero"),
+		FilterExpression:       aws.String("readed = :zero AND (attribute_not_exists(#st) OR #st = :sent) AND attribute_not_exists(#del)"),
+		ExpressionAttributeNames: map[string]string{
+			"#st":  "status",
+			"#del": fieldDeletedAt,
+		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":uid":  &types.AttributeValueMemberS{Value: userID},
 			":zero": &types.AttributeValueMemberN{Value: "0"},
+			":sent": &types.AttributeValueMemberS{Value: domain.NotificationSent},
 		},
 	})
 	if err != nil {
@@ -73,19 +104,78 @@ func (r *NotificationRepo) ListUnread(ctx context.Context, userID string) ([]dom
 	return notifications, nil
 }
 
-func (r *NotificationRepo) MarkAsRead(ctx context.Context, notificationID string) (*domain.Notification, error) {
+// EachVisible calls fn for every sent notification userID has not dismissed,
+// read or unread.
+func (r *NotificationRepo) EachVisible(ctx context.Context, userID string, fn func(domain.Notification) error) error {
+	return queryEach(ctx, r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("user_id-created_at-index"),
+		KeyConditionExpression: aws.String("user_id = :uid"),
+		FilterExpression:       aws.String("(attribute_not_exists(#st) OR #st = :sent) AND attribute_not_exists(#del)"),
+		ExpressionAttributeNames: map[string]string{
+			"#st":  "status",
+			"#del": fieldDeletedAt,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid":  &types.AttributeValueMemberS{Value: userID},
+			":sent": &types.AttributeValueMemberS{Value: domain.NotificationSent},
+		},
+	}, fn)
+}
+
+// CountByUser returns how many notifications have been sent to userID.
+// Scheduled and canceled notifications are not counted.
+func (r *NotificationRepo) CountByUser(ctx context.Context, userID string) (int, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("user_id-created_at-index"),
+		KeyConditionExpression: aws.String("user_id = :uid"),
+		FilterExpression:       aws.String("attribute_not_exists(#st) OR #st = :sent"),
+		ExpressionAttributeNames: map[string]string{
+			"#st": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid":  &types.AttributeValueMemberS{Value: userID},
+			":sent": &types.AttributeValueMemberS{Value: domain.NotificationSent},
+		},
+		Select: types.SelectCount,
+	}
+	count := 0
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		count += int(out.Count)
+		if len(out.LastEvaluatedKey) == 0 {
+			return count, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// MarkAsRead sets readed=1, conditional on the notification belonging to
+// ownerID. It returns domain.ErrForbidden when it does not (or no longer exists).
+func (r *NotificationRepo) MarkAsRead(ctx context.Context, notificationID, ownerID string) (*domain.Notification, error) {
 	ue, err := buildUpdateExpr(map[string]interface{}{fieldRead: 1})
 	if err != nil {
 		return nil, err
 	}
+	ue.Names["#owner"] = fieldUserID
+	ue.Values[":owner"] = &types.AttributeValueMemberS{Value: ownerID}
 	out, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName:                 aws.String(r.tableName),
 		Key:                       strKey("notification_id", notificationID),
 		UpdateExpression:          aws.String(ue.Expr),
+		ConditionExpression:       aws.String("#owner = :owner"),
 		ExpressionAttributeNames:  ue.Names,
 		ExpressionAttributeValues: ue.Values,
 		ReturnValues:              types.ReturnValueAllNew,
 	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return nil, fmt.Errorf("notification does not belong to user: %w", domain.ErrForbidden)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -95,3 +185,136 @@ func (r *NotificationRepo) MarkAsRead(ctx context.Context, notificationID string
 	}
 	return &n, nil
 }
+
+// SoftDelete marks the notification dismissed. A non-zero purgeAt (unix
+// seconds) sets the TTL that later removes the item.
+func (r *NotificationRepo) SoftDelete(ctx context.Context, notificationID string, purgeAt int64) error {
+	updates := map[string]interface{}{fieldDeletedAt: time.Now().UTC().Format(time.RFC3339)}
+	if purgeAt > 0 {
+		updates[fieldExpiresAt] = purgeAt
+	}
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("notification_id", notificationID),
+		UpdateExpression:          aws.String(ue.Expr),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return err
+}
+
+// SweepBefore deletes notifications dismissed before cutoff, or only counts
+// them when dryRun is set. It catches dismissals made without a TTL, e.g.
+// while NOTIFICATION_RETENTION_DAYS was 0.
+func (r *NotificationRepo) SweepBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error) {
+	return r.scan.sweep(ctx, r.client, &dynamodb.ScanInput{
+		TableName:                aws.String(r.tableName),
+		FilterExpression:         aws.String("#del < :cutoff"),
+		ProjectionExpression:     aws.String("notification_id"),
+		ExpressionAttributeNames: map[string]string{"#del": fieldDeletedAt},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":cutoff": &types.AttributeValueMemberS{Value: cutoff.UTC().Format(time.RFC3339)},
+		},
+	}, dryRun)
+}
+
+// AddReceipt appends rc to the notification's receipts list, creating the list if absent.
+func (r *NotificationRepo) AddReceipt(ctx context.Context, notificationID string, rc domain.Receipt) error {
+	av, err := attributevalue.Marshal([]domain.Receipt{rc})
+	if err != nil {
+		return fmt.Errorf("marshal receipt: %w", err)
+	}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              strKey("notification_id", notificationID),
+		UpdateExpression: aws.String("SET #rc = list_append(if_not_exists(#rc, :empty), :rc)"),
+		ExpressionAttributeNames: map[string]string{
+			"#rc": "receipts",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":rc":    av,
+			":empty": &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+		},
+	})
+	return err
+}
+
+// ListDue returns up to 100 scheduled notifications whose send time is at or before now,
+// via the sparse status-due_at GSI.
+func (r *NotificationRepo) ListDue(ctx context.Context, now time.Time) ([]domain.Notification, error) {
+	out, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("status-due_at-index"),
+		KeyConditionExpression: aws.String("#st = :scheduled AND due_at <= :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#st": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":scheduled": &types.AttributeValueMemberS{Value: domain.NotificationScheduled},
+			":now":       &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+		},
+		Limit: aws.Int32(100),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var notifications []domain.Notification
+	if err := attributevalue.UnmarshalListOfMaps(out.Items, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// UpdateScheduled applies updates only while the notification is still scheduled.
+// It returns domain.ErrConflict if the notification was already sent or canceled.
+func (r *NotificationRepo) UpdateScheduled(ctx context.Context, notificationID string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	ue.Names["#st"] = "status"
+	ue.Values[":scheduled"] = &types.AttributeValueMemberS{Value: domain.NotificationScheduled}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("notification_id", notificationID),
+		UpdateExpression:          aws.String(ue.Expr),
+		ConditionExpression:       aws.String("#st = :scheduled"),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return scheduleConflict(err)
+}
+
+// CloseSchedule moves a scheduled notification to status and drops it from the due index.
+// The condition makes this a claim: when several replicas race, exactly one succeeds
+// and the rest get domain.ErrConflict.
+func (r *NotificationRepo) CloseSchedule(ctx context.Context, notificationID, status string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 strKey("notification_id", notificationID),
+		UpdateExpression:    aws.String("SET #st = :st, updated_at = :now REMOVE due_at"),
+		ConditionExpression: aws.String("#st = :scheduled"),
+		ExpressionAttributeNames: map[string]string{
+			"#st": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":st":        &types.AttributeValueMemberS{Value: status},
+			":now":       &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":scheduled": &types.AttributeValueMemberS{Value: domain.NotificationScheduled},
+		},
+	})
+	return scheduleConflict(err)
+}
+
+func scheduleConflict(err error) error {
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("notification is no longer scheduled: %w", domain.ErrConflict)
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// OAuthClientRepo provides typed DynamoDB operations for the OAuth clients
+// table. PK: client_id.
+type OAuthClientRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewOAuthClientRepo(client *dynamodb.Client, tableName string) *OAuthClientRepo {
+	return &OAuthClientRepo{client: client, tableName: tableName}
+}
+
+// Put stores c, returning domain.ErrConflict if the client ID is taken.
+func (r *OAuthClientRepo) Put(ctx context.Context, c *domain.OAuthClient) error {
+	item, err := attributevalue.MarshalMap(c)
+	if err != nil {
+		return fmt.Errorf("marshal oauth client: %w", err)
+	}
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      listed(item),
+	}, "client_id")
+}
+
+func (r *OAuthClientRepo) Get(ctx context.Context, clientID string) (*domain.OAuthClient, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("client_id", clientID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("oauth client not found: %w", domain.ErrNotFound)
+	}
+	var c domain.OAuthClient
+	if err := attributevalue.UnmarshalMap(out.Item, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *OAuthClientRepo) List(ctx context.Context) ([]domain.OAuthClient, error) {
+	return queryList[domain.OAuthClient](ctx, r.client, r.tableName)
+}
+
+// Delete removes a client, returning domain.ErrNotFound if there is none.
+func (r *OAuthClientRepo) Delete(ctx context.Context, clientID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 strKey("client_id", clientID),
+		ConditionExpression: aws.String("attribute_exists(client_id)"),
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("oauth client not found: %w", domain.ErrNotFound)
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package dynamo
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/config"
+)
+
+// ParallelScan splits the full-table scans of exports, retention sweeps and
+// reindexing into Segments, read by up to Workers goroutines at once. The zero
+// value scans sequentially.
+type ParallelScan struct {
+	Segments int
+	Workers  int
+}
+
+// NewParallelScan reads DYNAMO_SCAN_SEGMENTS and DYNAMO_SCAN_WORKERS.
+func NewParallelScan(cfg *config.Config) ParallelScan {
+	return ParallelScan{Segments: cfg.DynamoScanSegments, Workers: cfg.DynamoScanWorkers}
+}
+
+type scanPage struct {
+	items []map[string]types.AttributeValue
+	err   error
+}
+
+// eachPage calls fn with every page of input in segment order: all pages of
+// segment 0, then of segment 1, and so on, so the output does not depend on
+// which worker finishes first. fn runs on the calling goroutine. Workers read
+// ahead by at most one page each, and all stop when fn fails or ctx ends.
+func (p ParallelScan) eachPage(
+	ctx context.Context, client *dynamodb.Client, input *dynamodb.ScanInput, fn func([]map[string]types.AttributeValue) error,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	segments := max(p.Segments, 1)
+	pages := make([]chan scanPage, segments)
+	for i := range pages {
+		pages[i] = make(chan scanPage, 1)
+	}
+	// Segments start in order, so the one being merged always has a worker.
+	slots := make(chan struct{}, min(max(p.Workers, 1), segments))
+	go func() {
+		for seg := range segments {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func() {
+				defer func() { <-slots }()
+				segmentScan{client: client, input: *input, total: segments}.run(ctx, seg, pages[seg])
+			}()
+		}
+	}()
+	for _, ch := range pages {
+		if err := drainSegment(ctx, ch, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// segmentScan reads one of total segments of input.
+type segmentScan struct {
+	client *dynamodb.Client
+	input  dynamodb.ScanInput
+	total  int
+}
+
+// run sends every page of segment seg to out and closes it.
+func (s segmentScan) run(ctx context.Context, seg int, out chan<- scanPage) {
+	defer close(out)
+	input := s.input
+	if s.total > 1 {
+		input.Segment, input.TotalSegments = aws.Int32(int32(seg)), aws.Int32(int32(s.total))
+	}
+	for {
+		res, err := s.client.Scan(ctx, &input)
+		page := scanPage{err: err}
+		if err == nil {
+			page.items = res.Items
+		}
+		select {
+		case out <- page:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil || len(res.LastEvaluatedKey) == 0 {
+			return
+		}
+		input.ExclusiveStartKey = res.LastEvaluatedKey
+	}
+}
+
+func drainSegment(ctx context.Context, ch <-chan scanPage, fn func([]map[string]types.AttributeValue) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case page, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if page.err != nil {
+				return page.err
+			}
+			if err := fn(page.items); err != nil {
+				return err
+			}
+		}
+	}
+}
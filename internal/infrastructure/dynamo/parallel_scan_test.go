@@ -0,0 +1,70 @@
+package dynamo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// segmentServer answers Scans with two pages per segment, each holding one
+// item named after its segment and page. Segment 0 is the slowest.
+func segmentServer(t *testing.T) *dynamodb.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Segment           int
+			ExclusiveStartKey map[string]json.RawMessage
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		if in.Segment == 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if in.ExclusiveStartKey == nil {
+			fmt.Fprintf(w, `{"Items":[{"id":{"S":"s%dp0"}}],"LastEvaluatedKey":{"id":{"S":"s%dp0"}}}`, in.Segment, in.Segment)
+			return
+		}
+		fmt.Fprintf(w, `{"Items":[{"id":{"S":"s%dp1"}}]}`, in.Segment)
+	}))
+	t.Cleanup(srv.Close)
+	return testClient(t, srv.URL)
+}
+
+func TestParallelScan_MergesInSegmentOrder(t *testing.T) {
+	client := segmentServer(t)
+	var ids []string
+	err := ParallelScan{Segments: 3, Workers: 2}.eachPage(t.Context(), client,
+		&dynamodb.ScanInput{TableName: aws.String("files")},
+		func(items []map[string]types.AttributeValue) error {
+			for _, item := range items {
+				ids = append(ids, item["id"].(*types.AttributeValueMemberS).Value)
+			}
+			return nil
+		})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s0p0", "s0p1", "s1p0", "s1p1", "s2p0", "s2p1"}, ids)
+}
+
+func TestParallelScan_StopsOnCallbackError(t *testing.T) {
+	client := segmentServer(t)
+	boom := errors.New("boom")
+	calls := 0
+	err := ParallelScan{Segments: 4, Workers: 4}.eachPage(t.Context(), client,
+		&dynamodb.ScanInput{TableName: aws.String("files")},
+		func([]map[string]types.AttributeValue) error {
+			calls++
+			return boom
+		})
+
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, 1, calls)
+}
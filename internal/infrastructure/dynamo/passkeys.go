@@ -0,0 +1,113 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// PasskeyRepo provides typed DynamoDB operations for the passkeys table.
+// PK: credential_id; user_id-index lists a user's passkeys.
+type PasskeyRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewPasskeyRepo(client *dynamodb.Client, tableName string) *PasskeyRepo {
+	return &PasskeyRepo{client: client, tableName: tableName}
+}
+
+// Put stores p, returning domain.ErrConflict if the credential is already
+// registered.
+func (r *PasskeyRepo) Put(ctx context.Context, p *domain.Passkey) error {
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return fmt.Errorf("marshal passkey: %w", err)
+	}
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	}, "credential_id")
+}
+
+func (r *PasskeyRepo) Get(ctx context.Context, credentialID string) (*domain.Passkey, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("credential_id", credentialID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("passkey not found: %w", domain.ErrNotFound)
+	}
+	var p domain.Passkey
+	if err := attributevalue.UnmarshalMap(out.Item, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PasskeyRepo) ListByUser(ctx context.Context, userID string) ([]domain.Passkey, error) {
+	passkeys := []domain.Passkey{}
+	err := queryEach(ctx, r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("user_id-index"),
+		KeyConditionExpression: aws.String("user_id = :uid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid": &types.AttributeValueMemberS{Value: userID},
+		},
+	}, func(p domain.Passkey) error {
+		passkeys = append(passkeys, p)
+		return nil
+	})
+	return passkeys, err
+}
+
+// Delete removes userID's passkey, returning domain.ErrNotFound if the
+// credential does not exist or belongs to someone else.
+func (r *PasskeyRepo) Delete(ctx context.Context, userID, credentialID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 strKey("credential_id", credentialID),
+		ConditionExpression: aws.String("user_id = :uid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("passkey not found: %w", domain.ErrNotFound)
+	}
+	return err
+}
+
+// RecordUse stores the signature counter of a login and when it happened.
+// A counter that did not grow, from an authenticator that keeps one, is
+// domain.ErrConflict: the credential may have been cloned.
+func (r *PasskeyRepo) RecordUse(ctx context.Context, credentialID string, signCount uint32, at time.Time) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 strKey("credential_id", credentialID),
+		UpdateExpression:    aws.String("SET sign_count = :n, last_used_at = :at"),
+		ConditionExpression: aws.String("attribute_exists(credential_id) AND (sign_count < :n OR :n = :zero)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":n":    &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(signCount), 10)},
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+			":at":   &types.AttributeValueMemberS{Value: at.UTC().Format(time.RFC3339Nano)},
+		},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("passkey signature counter did not advance: %w", domain.ErrConflict)
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// PlanRepo provides typed DynamoDB operations for the plans table.
+type PlanRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewPlanRepo(client *dynamodb.Client, tableName string) *PlanRepo {
+	return &PlanRepo{client: client, tableName: tableName}
+}
+
+// Create stores p, returning domain.ErrConflict if a plan with the same name exists.
+func (r *PlanRepo) Create(ctx context.Context, p *domain.Plan) error {
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return fmt.Errorf("marshal plan: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(r.tableName),
+		Item:                     listed(item),
+		ConditionExpression:      aws.String("attribute_not_exists(#n)"),
+		ExpressionAttributeNames: map[string]string{"#n": "name"},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("plan %q already exists: %w", p.Name, domain.ErrConflict)
+	}
+	return err
+}
+
+func (r *PlanRepo) Get(ctx context.Context, name string) (*domain.Plan, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("name", name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("plan not found: %w", domain.ErrNotFound)
+	}
+	var p domain.Plan
+	if err := attributevalue.UnmarshalMap(out.Item, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns every plan.
+func (r *PlanRepo) List(ctx context.Context) ([]domain.Plan, error) {
+	return queryList[domain.Plan](ctx, r.client, r.tableName)
+}
+
+func (r *PlanRepo) Update(ctx context.Context, name string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("name", name),
+		UpdateExpression:          aws.String(ue.Expr),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return err
+}
+
+func (r *PlanRepo) HardDelete(ctx context.Context, name string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("name", name),
+	})
+	return err
+}
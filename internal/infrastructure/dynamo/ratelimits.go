@@ -0,0 +1,112 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// RateLimitRepo stores fixed-window request counters for the distributed rate limiter.
+// PK: limit_key. Items expire through the expires_at TTL attribute.
+type RateLimitRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewRateLimitRepo(client *dynamodb.Client, tableName string) *RateLimitRepo {
+	return &RateLimitRepo{client: client, tableName: tableName}
+}
+
+// Increment atomically adds one to the counter stored under key and returns the new value.
+// expiresAt (Unix seconds) is written as the item TTL.
+func (r *RateLimitRepo) Increment(ctx context.Context, key string, expiresAt int64) (int64, error) {
+	out, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              strKey("limit_key", key),
+		UpdateExpression: aws.String("ADD #c :one SET #exp = :exp"),
+		ExpressionAttributeNames: map[string]string{
+			"#c":   "count",
+			"#exp": "expires_at",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+			":exp": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return parseCount(out.Attributes)
+}
+
+// Count returns the counter stored under key, or 0 when the item does not exist.
+func (r *RateLimitRepo) Count(ctx context.Context, key string) (int64, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("limit_key", key),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if out.Item == nil {
+		return 0, nil
+	}
+	return parseCount(out.Item)
+}
+
+// Delete removes the counter stored under key.
+func (r *RateLimitRepo) Delete(ctx context.Context, key string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("limit_key", key),
+	})
+	return err
+}
+
+// Lock records that key is blocked until the given Unix time. The counter and
+// its TTL are left as they are.
+func (r *RateLimitRepo) Lock(ctx context.Context, key string, until int64) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              strKey("limit_key", key),
+		UpdateExpression: aws.String("SET locked_until = :until"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":until": &types.AttributeValueMemberN{Value: strconv.FormatInt(until, 10)},
+		},
+	})
+	return err
+}
+
+// LockedUntil returns the Unix time key is locked until, or 0 when it was never locked.
+func (r *RateLimitRepo) LockedUntil(ctx context.Context, key string) (int64, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(r.tableName),
+		Key:                  strKey("limit_key", key),
+		ProjectionExpression: aws.String("locked_until"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, ok := out.Item["locked_until"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(n.Value, 10, 64)
+}
+
+func parseCount(item map[string]types.AttributeValue) (int64, error) {
+	n, ok := item["count"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, nil
+	}
+	c, err := strconv.ParseInt(n.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse rate limit count: %w", err)
+	}
+	return c, nil
+}
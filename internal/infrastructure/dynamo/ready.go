@@ -0,0 +1,46 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// readyBackoff is the wait after the first failed startup ping; it doubles
+// per attempt up to readyBackoffMax.
+var readyBackoff = 500 * time.Millisecond
+
+const readyBackoffMax = 5 * time.Second
+
+// Ping checks that DynamoDB answers with the client's credentials.
+func Ping(ctx context.Context, client *dynamodb.Client) error {
+	_, err := client.ListTables(ctx, &dynamodb.ListTablesInput{Limit: aws.Int32(1)})
+	return err
+}
+
+// WaitReady pings DynamoDB until it answers or wait has passed, backing off
+// between attempts, so an API started alongside LocalStack or during a
+// network blip does not bootstrap against an endpoint that is not up yet.
+// It returns the last ping error when DynamoDB stays unreachable.
+func WaitReady(ctx context.Context, client *dynamodb.Client, wait time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	backoff := readyBackoff
+	for attempt := 1; ; attempt++ {
+		err := Ping(ctx, client)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("DynamoDB not reachable yet", "attempt", attempt, "retry_in", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("DynamoDB unreachable after %s: %w", wait, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, readyBackoffMax)
+	}
+}
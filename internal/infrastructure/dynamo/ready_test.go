@@ -0,0 +1,47 @@
+package dynamo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upAfter answers ListTables with 503 for the first failures calls.
+func upAfter(t *testing.T, failures int32) (*dynamodb.Client, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"TableNames":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return testClient(t, srv.URL, func(o *dynamodb.Options) { o.RetryMaxAttempts = 1 }), &calls
+}
+
+func TestWaitReady_RetriesUntilReachable(t *testing.T) {
+	defer func(d time.Duration) { readyBackoff = d }(readyBackoff)
+	readyBackoff = time.Millisecond
+	client, calls := upAfter(t, 2)
+
+	require.NoError(t, WaitReady(t.Context(), client, time.Second))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWaitReady_GivesUp(t *testing.T) {
+	defer func(d time.Duration) { readyBackoff = d }(readyBackoff)
+	readyBackoff = time.Millisecond
+	client, _ := upAfter(t, 1<<30)
+
+	err := WaitReady(t.Context(), client, 20*time.Millisecond)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DynamoDB unreachable after 20ms")
+}
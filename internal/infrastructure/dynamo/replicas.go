@@ -0,0 +1,133 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// replicaPollInterval is how often EnsureReplicas checks on a table while
+// DynamoDB updates it.
+const replicaPollInterval = 10 * time.Second
+
+// EnsureReplicas turns every table into a global table with a replica in each
+// of regions, adding the missing ones one at a time. It blocks until every
+// replica is ACTIVE, which can take many minutes per region on a large table.
+// Safe to re-run: tables that already have their replicas are left alone.
+func EnsureReplicas(ctx context.Context, client *dynamodb.Client, tables, regions []string) error {
+	for _, table := range tables {
+		if err := waitActive(ctx, client, table); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+		missing, err := missingReplicas(ctx, client, table, regions)
+		if err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if err := replicaStream(ctx, client, table); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+		for _, region := range missing {
+			slog.Info("adding replica", "table", table, "region", region)
+			_, err := client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+				TableName:      aws.String(table),
+				ReplicaUpdates: []types.ReplicationGroupUpdate{{Create: &types.CreateReplicationGroupMemberAction{RegionName: aws.String(region)}}},
+			})
+			if err != nil {
+				return fmt.Errorf("%s: add replica %s: %w", table, region, err)
+			}
+			if err := waitActive(ctx, client, table); err != nil {
+				return fmt.Errorf("%s: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckReplicas logs a warning for every table that lacks a replica in one of
+// regions. The API calls it on startup; adding replicas is left to
+// `migrate add-replicas` because it takes too long to block a deploy.
+func CheckReplicas(ctx context.Context, client *dynamodb.Client, tables, regions []string) {
+	for _, table := range tables {
+		missing, err := missingReplicas(ctx, client, table, regions)
+		if err != nil {
+			slog.Warn("could not check replicas", "table", table, "err", err)
+			continue
+		}
+		if len(missing) > 0 {
+			slog.Warn("table is missing replicas; run migrate add-replicas", "table", table, "regions", missing)
+		}
+	}
+}
+
+// missingReplicas returns the regions table has no replica in.
+func missingReplicas(ctx context.Context, client *dynamodb.Client, table string, regions []string) ([]string, error) {
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return nil, err
+	}
+	have := map[string]bool{}
+	for _, r := range out.Table.Replicas {
+		have[aws.ToString(r.RegionName)] = true
+	}
+	var missing []string
+	for _, region := range regions {
+		if !have[region] {
+			missing = append(missing, region)
+		}
+	}
+	return missing, nil
+}
+
+// replicaStream switches an existing NEW_IMAGE stream to NEW_AND_OLD_IMAGES,
+// which global tables require. The stream is recreated, so stream readers
+// start again from the new stream's latest records.
+func replicaStream(ctx context.Context, client *dynamodb.Client, table string) error {
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return err
+	}
+	spec := out.Table.StreamSpecification
+	if spec == nil || !aws.ToBool(spec.StreamEnabled) || spec.StreamViewType == types.StreamViewTypeNewAndOldImages {
+		return nil
+	}
+	slog.Info("recreating stream with NEW_AND_OLD_IMAGES", "table", table)
+	for _, s := range []*types.StreamSpecification{{StreamEnabled: aws.Bool(false)}, tableStream} {
+		if _, err := client.UpdateTable(ctx, &dynamodb.UpdateTableInput{TableName: aws.String(table), StreamSpecification: s}); err != nil {
+			return fmt.Errorf("update stream: %w", err)
+		}
+		if err := waitActive(ctx, client, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitActive polls until table and all its replicas are ACTIVE.
+func waitActive(ctx context.Context, client *dynamodb.Client, table string) error {
+	for {
+		out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err != nil {
+			return err
+		}
+		active := out.Table.TableStatus == types.TableStatusActive
+		for _, r := range out.Table.Replicas {
+			active = active && r.ReplicaStatus == types.ReplicaStatusActive
+		}
+		if active {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(replicaPollInterval):
+		}
+	}
+}
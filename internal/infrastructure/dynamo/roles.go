@@ -0,0 +1,90 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// RoleRepo provides typed DynamoDB operations for the roles table.
+type RoleRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewRoleRepo(client *dynamodb.Client, tableName string) *RoleRepo {
+	return &RoleRepo{client: client, tableName: tableName}
+}
+
+// Create stores role, returning domain.ErrConflict if a role with the same name exists.
+func (r *RoleRepo) Create(ctx context.Context, role *domain.Role) error {
+	item, err := attributevalue.MarshalMap(role)
+	if err != nil {
+		return fmt.Errorf("marshal role: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(r.tableName),
+		Item:                     listed(item),
+		ConditionExpression:      aws.String("attribute_not_exists(#n)"),
+		ExpressionAttributeNames: map[string]string{"#n": "name"},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("role %q already exists: %w", role.Name, domain.ErrConflict)
+	}
+	return err
+}
+
+func (r *RoleRepo) Get(ctx context.Context, name string) (*domain.Role, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("name", name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("role not found: %w", domain.ErrNotFound)
+	}
+	var role domain.Role
+	if err := attributevalue.UnmarshalMap(out.Item, &role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// List returns every role.
+func (r *RoleRepo) List(ctx context.Context) ([]domain.Role, error) {
+	return queryList[domain.Role](ctx, r.client, r.tableName)
+}
+
+func (r *RoleRepo) Update(ctx context.Context, name string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("name", name),
+		UpdateExpression:          aws.String(ue.Expr),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return err
+}
+
+func (r *RoleRepo) HardDelete(ctx context.Context, name string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       strKey("name", name),
+	})
+	return err
+}
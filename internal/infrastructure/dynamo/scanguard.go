@@ -0,0 +1,38 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// errScanForbidden is returned for a Scan that ScanGuardAPIOptions blocks.
+var errScanForbidden = errors.New("dynamodb: Scan is forbidden by FORBID_SCANS; query an index instead")
+
+type scanAllowedKey struct{}
+
+// allowScans marks ctx as a deliberate full-table pass — a retention sweep,
+// backfill or reindex — which the scan guard lets through.
+func allowScans(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scanAllowedKey{}, true)
+}
+
+// ScanGuardAPIOptions rejects every Scan whose context was not marked by
+// allowScans, so a request path that falls back to reading a whole table
+// fails loudly instead of burning capacity as the table grows.
+func ScanGuardAPIOptions() []func(*middleware.Stack) error {
+	return []func(*middleware.Stack) error{func(s *middleware.Stack) error {
+		return s.Initialize.Add(scanGuard, middleware.After)
+	}}
+}
+
+var scanGuard = middleware.InitializeMiddlewareFunc("ScanGuard", func(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	if _, ok := in.Parameters.(*dynamodb.ScanInput); ok && ctx.Value(scanAllowedKey{}) == nil {
+		return middleware.InitializeOutput{}, middleware.Metadata{}, errScanForbidden
+	}
+	return next.HandleInitialize(ctx, in)
+})
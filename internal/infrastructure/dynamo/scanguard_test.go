@@ -0,0 +1,49 @@
+package dynamo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanGuard_RejectsScansOutsideMaintenance(t *testing.T) {
+	reached := 0
+	next := middleware.InitializeHandlerFunc(func(
+		context.Context, middleware.InitializeInput,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		reached++
+		return middleware.InitializeOutput{}, middleware.Metadata{}, nil
+	})
+	run := func(ctx context.Context, params any) error {
+		_, _, err := scanGuard.HandleInitialize(ctx, middleware.InitializeInput{Parameters: params}, next)
+		return err
+	}
+	ctx := context.Background()
+	scan := &dynamodb.ScanInput{TableName: aws.String("users")}
+
+	assert.ErrorIs(t, run(ctx, scan), errScanForbidden)
+	assert.NoError(t, run(ctx, &dynamodb.QueryInput{TableName: aws.String("users")}))
+	assert.NoError(t, run(allowScans(ctx), scan))
+	assert.Equal(t, 2, reached)
+}
+
+func TestExportQuery_UsesIndexKeys(t *testing.T) {
+	disabled, confirmed := 0, true
+	f := domain.UserFilter{Role: "Admin", Enable: &disabled, EmailConfirmed: &confirmed, CreatedFrom: "2024-01-01"}
+
+	q := exportQuery("users", f, "role-created_at-index", fieldRole, &types.AttributeValueMemberS{Value: "Admin"})
+	assert.Equal(t, "role-created_at-index", aws.ToString(q.IndexName))
+	assert.Equal(t, "#k = :k AND #ca >= :from", aws.ToString(q.KeyConditionExpression))
+	assert.Equal(t, "#en = :en AND #ec = :ec", aws.ToString(q.FilterExpression))
+
+	f.Role = ""
+	q = exportQuery("users", f, "enable-created_at-index", fieldEnable, &types.AttributeValueMemberN{Value: "0"})
+	assert.Equal(t, "#ec = :ec", aws.ToString(q.FilterExpression), "enable is the partition key")
+	assert.Equal(t, fieldEnable, q.ExpressionAttributeNames["#k"])
+}
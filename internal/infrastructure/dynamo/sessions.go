@@ -2,8 +2,10 @@ package dynamo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -28,11 +30,10 @@ func (r *SessionRepo) Put(ctx context.Context, s *domain.Session) error {
 	if err != nil {
 		return fmt.Errorf("marshal session: %w", err)
 	}
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+	return putNew(ctx, r.client, &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
 		Item:      item,
-	})
-	return err
+	}, "session_id")
 }
 
 func (r *SessionRepo) Get(ctx context.Context, sessionID string) (*domain.Session, error) {
@@ -81,6 +82,31 @@ func (r *SessionRepo) SoftDeleteByUser(ctx context.Context, userID string) error
 	return firstErr
 }
 
+// ListActiveByUser returns the user's enabled sessions whose refresh token has
+// not expired.
+func (r *SessionRepo) ListActiveByUser(ctx context.Context, userID string) ([]domain.Session, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("user_id-index"),
+		KeyConditionExpression: aws.String("user_id = :uid"),
+		FilterExpression:       aws.String("#en = :true AND refresh_expires_at > :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#en": fieldEnable,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid":  &types.AttributeValueMemberS{Value: userID},
+			":true": &types.AttributeValueMemberBOOL{Value: true},
+			":now":  &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	}
+	sessions := []domain.Session{}
+	err := queryEach(ctx, r.client, input, func(s domain.Session) error {
+		sessions = append(sessions, s)
+		return nil
+	})
+	return sessions, err
+}
+
 func (r *SessionRepo) Update(ctx context.Context, sessionID string, updates map[string]interface{}) error {
 	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
 	ue, err := buildUpdateExpr(updates)
@@ -97,6 +123,32 @@ func (r *SessionRepo) Update(ctx context.Context, sessionID string, updates map[
 	return err
 }
 
+// RedeemAuthCode applies updates to the session holding the OAuth
+// authorization code, once: a code already redeemed, or never issued, is
+// domain.ErrConflict.
+func (r *SessionRepo) RedeemAuthCode(ctx context.Context, sessionID, code string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	ue, err := buildUpdateExpr(updates)
+	if err != nil {
+		return err
+	}
+	ue.Names["#code"] = "auth_code"
+	ue.Values[":code"] = &types.AttributeValueMemberS{Value: code}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("session_id", sessionID),
+		UpdateExpression:          aws.String(ue.Expr),
+		ConditionExpression:       aws.String("#code = :code"),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("authorization code already used: %w", domain.ErrConflict)
+	}
+	return err
+}
+
 // GetByRefreshToken looks up a session by its opaque refresh token via GSI.
 // Returns ErrUnauthorized (session disabled) when found but inactive.
 func (r *SessionRepo) GetByRefreshToken(ctx context.Context, token string) (*domain.Session, error) {
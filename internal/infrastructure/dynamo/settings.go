@@ -0,0 +1,60 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// settingReadOnly names the read-only switch's row in the settings table.
+const settingReadOnly = "read_only"
+
+// SettingsRepo provides typed DynamoDB operations for the settings table,
+// which holds one row per runtime switch keyed by name.
+type SettingsRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewSettingsRepo(client *dynamodb.Client, tableName string) *SettingsRepo {
+	return &SettingsRepo{client: client, tableName: tableName}
+}
+
+type readOnlyItem struct {
+	Name string `dynamodbav:"name"`
+	domain.ReadOnlyMode
+}
+
+// GetReadOnly returns the stored read-only switch, or the zero value (off)
+// when it was never set.
+func (r *SettingsRepo) GetReadOnly(ctx context.Context) (domain.ReadOnlyMode, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            strKey("name", settingReadOnly),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil || out.Item == nil {
+		return domain.ReadOnlyMode{}, err
+	}
+	var item readOnlyItem
+	if err := attributevalue.UnmarshalMap(out.Item, &item); err != nil {
+		return domain.ReadOnlyMode{}, err
+	}
+	return item.ReadOnlyMode, nil
+}
+
+func (r *SettingsRepo) PutReadOnly(ctx context.Context, m domain.ReadOnlyMode) error {
+	item, err := attributevalue.MarshalMap(readOnlyItem{Name: settingReadOnly, ReadOnlyMode: m})
+	if err != nil {
+		return fmt.Errorf("marshal read-only setting: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	return err
+}
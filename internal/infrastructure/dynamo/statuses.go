@@ -27,7 +27,7 @@ func (r *StatusRepo) Put(ctx context.Context, s *domain.Status) error {
 	}
 	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
-		Item:      item,
+		Item:      listed(item),
 	})
 	return err
 }
@@ -50,19 +50,12 @@ func (r *StatusRepo) Get(ctx context.Context, statusID string) (*domain.Status,
 	return &s, nil
 }
 
-func (r *StatusRepo) Scan(ctx context.Context) ([]domain.Status, error) {
-	out, err := r.client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String(r.tableName)})
-	if err != nil {
-		return nil, err
-	}
-	var statuses []domain.Status
-	if err := attributevalue.UnmarshalListOfMaps(out.Items, &statuses); err != nil {
-		return nil, err
-	}
-	return statuses, nil
+// List returns every status, disabled ones included.
+func (r *StatusRepo) List(ctx context.Context) ([]domain.Status, error) {
+	return queryList[domain.Status](ctx, r.client, r.tableName)
 }
 
-// HardDelete permanently removes a status item (no soft delete for statuses).
+// HardDelete permanently removes a status item.
 func (r *StatusRepo) HardDelete(ctx context.Context, statusID string) error {
 	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
 		TableName: aws.String(r.tableName),
@@ -0,0 +1,126 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/go-api-nosql/internal/infrastructure/envelope"
+)
+
+// StreamReader tails a table's DynamoDB stream (new images) and decodes
+// each inserted or modified item into T. REMOVE records are skipped: the
+// tables it is used on are soft-delete only.
+//
+// A reader is not safe for concurrent use; call Poll from a single goroutine.
+type StreamReader[T any] struct {
+	client    *dynamodb.Client
+	streams   *dynamodbstreams.Client
+	tableName string
+	// iterators holds the next iterator per shard; nil marks a closed shard.
+	iterators map[string]*string
+	started   bool
+}
+
+func NewStreamReader[T any](client *dynamodb.Client, streams *dynamodbstreams.Client, tableName string) *StreamReader[T] {
+	return &StreamReader[T]{client: client, streams: streams, tableName: tableName, iterators: map[string]*string{}}
+}
+
+// Poll returns the items written since the previous call. The first call
+// starts at the tip of the stream; shards found later (after splits or an
+// expired iterator) are read from their oldest record.
+func (r *StreamReader[T]) Poll(ctx context.Context) ([]T, error) {
+	if err := r.discoverShards(ctx); err != nil {
+		return nil, err
+	}
+	var items []T
+	for shardID, it := range r.iterators {
+		if it == nil {
+			continue
+		}
+		out, err := r.streams.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{ShardIterator: it})
+		if err != nil {
+			delete(r.iterators, shardID) // re-fetched from TRIM_HORIZON on the next poll
+			return items, fmt.Errorf("read stream shard %s: %w", shardID, err)
+		}
+		r.iterators[shardID] = out.NextShardIterator
+		decoded, err := decodeNewImages[T](out.Records)
+		if err != nil {
+			return items, err
+		}
+		items = append(items, decoded...)
+	}
+	return items, nil
+}
+
+func (r *StreamReader[T]) discoverShards(ctx context.Context) error {
+	table, err := r.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(r.tableName)})
+	if err != nil {
+		return err
+	}
+	if table.Table.LatestStreamArn == nil {
+		return fmt.Errorf("table %s has no stream enabled", r.tableName)
+	}
+	position := streamtypes.ShardIteratorTypeTrimHorizon
+	if !r.started {
+		position = streamtypes.ShardIteratorTypeLatest
+	}
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: table.Table.LatestStreamArn}
+	for {
+		out, err := r.streams.DescribeStream(ctx, input)
+		if err != nil {
+			return err
+		}
+		for _, shard := range out.StreamDescription.Shards {
+			if _, known := r.iterators[*shard.ShardId]; known {
+				continue
+			}
+			it, err := r.streams.GetShardIterator(ctx, &dynamodbstreams.GetShardIteratorInput{
+				StreamArn:         input.StreamArn,
+				ShardId:           shard.ShardId,
+				ShardIteratorType: position,
+			})
+			if err != nil {
+				return err
+			}
+			r.iterators[*shard.ShardId] = it.ShardIterator
+		}
+		if out.StreamDescription.LastEvaluatedShardId == nil {
+			break
+		}
+		input.ExclusiveStartShardId = out.StreamDescription.LastEvaluatedShardId
+	}
+	r.started = true
+	return nil
+}
+
+func decodeNewImages[T any](records []streamtypes.Record) ([]T, error) {
+	items := make([]T, 0, len(records))
+	for _, rec := range records {
+		if rec.EventName == streamtypes.OperationTypeRemove || rec.Dynamodb == nil || rec.Dynamodb.NewImage == nil {
+			continue
+		}
+		image, err := attributevalue.FromDynamoDBStreamsMap(rec.Dynamodb.NewImage)
+		if err != nil {
+			return nil, err
+		}
+		// Stream consumers never hold the PII key; sealed attributes decode
+		// as absent.
+		for name, av := range image {
+			if s, ok := av.(*types.AttributeValueMemberS); ok && envelope.IsSealed(s.Value) {
+				delete(image, name)
+			}
+		}
+		var item T
+		if err := attributevalue.UnmarshalMap(image, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,194 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+)
+
+// tenantAttr is stamped on every item written on behalf of a tenant.
+const tenantAttr = "tenant_id"
+
+// errTenantUnsupported rejects operations tenantIsolation cannot scope.
+var errTenantUnsupported = errors.New("dynamodb: batch reads and deletes, transactions and PartiQL are not tenant-scoped")
+
+// TenantAPIOptions keeps every DynamoDB item operation inside the tenant of
+// its context (see package tenant). Items are stamped with tenant_id on
+// write; reads, queries and scans only see the tenant's items, and updates
+// and deletes fail their condition on another tenant's item. Calls whose
+// context carries no tenant, such as background jobs and startup seeding,
+// act on all tenants. Tables in shared, e.g. rate limits and roles, are
+// common to every tenant and left alone.
+func TenantAPIOptions(shared []string) []func(*middleware.Stack) error {
+	isolate := tenantIsolation(shared)
+	return []func(*middleware.Stack) error{func(s *middleware.Stack) error {
+		return s.Initialize.Add(isolate, middleware.After)
+	}}
+}
+
+func tenantIsolation(shared []string) middleware.InitializeMiddleware {
+	skip := make(map[string]bool, len(shared))
+	for _, name := range shared {
+		skip[name] = true
+	}
+	return middleware.InitializeMiddlewareFunc("TenantIsolation", func(
+		ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		id, ok := tenant.FromContext(ctx)
+		if !ok {
+			return next.HandleInitialize(ctx, in)
+		}
+		if get, ok := in.Parameters.(*dynamodb.GetItemInput); ok && !skip[aws.ToString(get.TableName)] {
+			return getForTenant(ctx, in, next, id)
+		}
+		params, err := scopeToTenant(in.Parameters, skip, id)
+		if err != nil {
+			return middleware.InitializeOutput{}, middleware.Metadata{}, err
+		}
+		in.Parameters = params
+		return next.HandleInitialize(ctx, in)
+	})
+}
+
+// scopeToTenant returns a copy of params, a write, query or scan input,
+// limited to tenant id; the repos reuse inputs across pages, so they are
+// never changed in place. Other operations are returned as they are.
+func scopeToTenant(params any, skip map[string]bool, id string) (any, error) {
+	const owned = "#tenant_id = :tenant_id"
+	switch p := params.(type) {
+	case *dynamodb.PutItemInput:
+		if skip[aws.ToString(p.TableName)] {
+			return p, nil
+		}
+		c := *p
+		c.Item = maps.Clone(p.Item)
+		c.Item[tenantAttr] = &types.AttributeValueMemberS{Value: id}
+		c.ConditionExpression = andCondition(p.ConditionExpression, "(attribute_not_exists(#tenant_id) OR "+owned+")")
+		c.ExpressionAttributeNames, c.ExpressionAttributeValues = withTenantPlaceholders(p.ExpressionAttributeNames, p.ExpressionAttributeValues, id)
+		return &c, nil
+	case *dynamodb.UpdateItemInput:
+		if skip[aws.ToString(p.TableName)] {
+			return p, nil
+		}
+		c := *p
+		c.ConditionExpression = andCondition(p.ConditionExpression, owned)
+		c.ExpressionAttributeNames, c.ExpressionAttributeValues = withTenantPlaceholders(p.ExpressionAttributeNames, p.ExpressionAttributeValues, id)
+		return &c, nil
+	case *dynamodb.DeleteItemInput:
+		if skip[aws.ToString(p.TableName)] {
+			return p, nil
+		}
+		c := *p
+		c.ConditionExpression = andCondition(p.ConditionExpression, owned)
+		c.ExpressionAttributeNames, c.ExpressionAttributeValues = withTenantPlaceholders(p.ExpressionAttributeNames, p.ExpressionAttributeValues, id)
+		return &c, nil
+	case *dynamodb.QueryInput:
+		if skip[aws.ToString(p.TableName)] {
+			return p, nil
+		}
+		c := *p
+		c.FilterExpression = andCondition(p.FilterExpression, owned)
+		c.ExpressionAttributeNames, c.ExpressionAttributeValues = withTenantPlaceholders(p.ExpressionAttributeNames, p.ExpressionAttributeValues, id)
+		return &c, nil
+	case *dynamodb.ScanInput:
+		if skip[aws.ToString(p.TableName)] {
+			return p, nil
+		}
+		c := *p
+		c.FilterExpression = andCondition(p.FilterExpression, owned)
+		c.ExpressionAttributeNames, c.ExpressionAttributeValues = withTenantPlaceholders(p.ExpressionAttributeNames, p.ExpressionAttributeValues, id)
+		return &c, nil
+	case *dynamodb.BatchWriteItemInput:
+		return scopeBatchWrite(p, skip, id)
+	case *dynamodb.BatchGetItemInput, *dynamodb.TransactGetItemsInput,
+		*dynamodb.TransactWriteItemsInput, *dynamodb.ExecuteStatementInput, *dynamodb.BatchExecuteStatementInput,
+		*dynamodb.ExecuteTransactionInput:
+		return nil, errTenantUnsupported
+	}
+	return params, nil
+}
+
+// scopeBatchWrite stamps the puts of a batch write with tenant id. Batched
+// writes take no conditions, so a delete could remove another tenant's item
+// and is refused outside shared tables.
+func scopeBatchWrite(p *dynamodb.BatchWriteItemInput, skip map[string]bool, id string) (any, error) {
+	c := *p
+	c.RequestItems = make(map[string][]types.WriteRequest, len(p.RequestItems))
+	for table, requests := range p.RequestItems {
+		if skip[table] {
+			c.RequestItems[table] = requests
+			continue
+		}
+		scoped := make([]types.WriteRequest, len(requests))
+		for i, req := range requests {
+			if req.PutRequest == nil {
+				return nil, errTenantUnsupported
+			}
+			item := maps.Clone(req.PutRequest.Item)
+			item[tenantAttr] = &types.AttributeValueMemberS{Value: id}
+			scoped[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}
+		}
+		c.RequestItems[table] = scoped
+	}
+	return &c, nil
+}
+
+// getForTenant reads the item with its tenant_id and drops it when it
+// belongs to another tenant, so the caller sees it as missing.
+func getForTenant(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler, id string,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	p := in.Parameters.(*dynamodb.GetItemInput)
+	if p.ProjectionExpression != nil {
+		c := *p
+		c.ProjectionExpression = aws.String(*p.ProjectionExpression + ", #tenant_id")
+		c.ExpressionAttributeNames = maps.Clone(p.ExpressionAttributeNames)
+		if c.ExpressionAttributeNames == nil {
+			c.ExpressionAttributeNames = map[string]string{}
+		}
+		c.ExpressionAttributeNames["#tenant_id"] = tenantAttr
+		in.Parameters = &c
+	}
+	out, md, err := next.HandleInitialize(ctx, in)
+	if err != nil {
+		return out, md, err
+	}
+	if res, ok := out.Result.(*dynamodb.GetItemOutput); ok && res.Item != nil {
+		owner, _ := res.Item[tenantAttr].(*types.AttributeValueMemberS)
+		if owner == nil || owner.Value != id {
+			res.Item = nil
+		}
+	}
+	return out, md, nil
+}
+
+func andCondition(expr *string, cond string) *string {
+	if aws.ToString(expr) == "" {
+		return aws.String(cond)
+	}
+	return aws.String("(" + *expr + ") AND " + cond)
+}
+
+// withTenantPlaceholders returns copies of names and values that also define
+// #tenant_id and :tenant_id.
+func withTenantPlaceholders(
+	names map[string]string, values map[string]types.AttributeValue, id string,
+) (map[string]string, map[string]types.AttributeValue) {
+	names = maps.Clone(names)
+	if names == nil {
+		names = map[string]string{}
+	}
+	names["#tenant_id"] = tenantAttr
+	values = maps.Clone(values)
+	if values == nil {
+		values = map[string]types.AttributeValue{}
+	}
+	values[":tenant_id"] = &types.AttributeValueMemberS{Value: id}
+	return names, values
+}
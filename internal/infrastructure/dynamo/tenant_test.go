@@ -0,0 +1,167 @@
+package dynamo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runIsolated passes params through tenantIsolation and returns what reached
+// the next handler, which answers with result.
+func runIsolated(t *testing.T, ctx context.Context, params, result any) (sent, got any, err error) {
+	t.Helper()
+	next := middleware.InitializeHandlerFunc(func(
+		_ context.Context, in middleware.InitializeInput,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		sent = in.Parameters
+		return middleware.InitializeOutput{Result: result}, middleware.Metadata{}, nil
+	})
+	out, _, err := tenantIsolation([]string{"rate_limits"}).HandleInitialize(ctx, middleware.InitializeInput{Parameters: params}, next)
+	return sent, out.Result, err
+}
+
+func TestTenantIsolation_ScopesQueriesAndWrites(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+
+	query := &dynamodb.QueryInput{
+		TableName:                 aws.String("users"),
+		KeyConditionExpression:    aws.String("#r = :r"),
+		FilterExpression:          aws.String("#e = :e"),
+		ExpressionAttributeNames:  map[string]string{"#r": "role", "#e": "enable"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":r": &types.AttributeValueMemberS{Value: "admin"}},
+	}
+	sent, _, err := runIsolated(t, ctx, query, &dynamodb.QueryOutput{})
+	require.NoError(t, err)
+	q := sent.(*dynamodb.QueryInput)
+	assert.Equal(t, "(#e = :e) AND #tenant_id = :tenant_id", aws.ToString(q.FilterExpression))
+	assert.Equal(t, "tenant_id", q.ExpressionAttributeNames["#tenant_id"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "acme"}, q.ExpressionAttributeValues[":tenant_id"])
+	assert.Equal(t, "#e = :e", aws.ToString(query.FilterExpression), "the caller's input is reused across pages")
+	assert.NotContains(t, query.ExpressionAttributeNames, "#tenant_id")
+
+	put := &dynamodb.PutItemInput{TableName: aws.String("users"), Item: strKey("user_id", "u1")}
+	sent, _, err = runIsolated(t, ctx, put, &dynamodb.PutItemOutput{})
+	require.NoError(t, err)
+	p := sent.(*dynamodb.PutItemInput)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "acme"}, p.Item[tenantAttr])
+	assert.Equal(t, "(attribute_not_exists(#tenant_id) OR #tenant_id = :tenant_id)", aws.ToString(p.ConditionExpression))
+	assert.NotContains(t, put.Item, tenantAttr)
+
+	update := &dynamodb.UpdateItemInput{TableName: aws.String("users"), Key: strKey("user_id", "u1"), ConditionExpression: aws.String("attribute_exists(user_id)")}
+	sent, _, err = runIsolated(t, ctx, update, &dynamodb.UpdateItemOutput{})
+	require.NoError(t, err)
+	assert.Equal(t, "(attribute_exists(user_id)) AND #tenant_id = :tenant_id", aws.ToString(sent.(*dynamodb.UpdateItemInput).ConditionExpression))
+}
+
+func TestTenantIsolation_HidesOtherTenantsItems(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	get := &dynamodb.GetItemInput{TableName: aws.String("users"), Key: strKey("user_id", "u1")}
+
+	item := strKey("user_id", "u1")
+	item[tenantAttr] = &types.AttributeValueMemberS{Value: "globex"}
+	_, got, err := runIsolated(t, ctx, get, &dynamodb.GetItemOutput{Item: item})
+	require.NoError(t, err)
+	assert.Nil(t, got.(*dynamodb.GetItemOutput).Item)
+
+	item[tenantAttr] = &types.AttributeValueMemberS{Value: "acme"}
+	_, got, err = runIsolated(t, ctx, get, &dynamodb.GetItemOutput{Item: item})
+	require.NoError(t, err)
+	assert.NotNil(t, got.(*dynamodb.GetItemOutput).Item)
+
+	_, got, err = runIsolated(t, ctx, get, &dynamodb.GetItemOutput{Item: strKey("user_id", "u1")})
+	require.NoError(t, err)
+	assert.Nil(t, got.(*dynamodb.GetItemOutput).Item, "items written before tenancy belong to no tenant")
+}
+
+func TestTenantIsolation_PassThrough(t *testing.T) {
+	scan := &dynamodb.ScanInput{TableName: aws.String("users")}
+	sent, _, err := runIsolated(t, context.Background(), scan, &dynamodb.ScanOutput{})
+	require.NoError(t, err)
+	assert.Same(t, scan, sent, "calls without a tenant act on all tenants")
+
+	shared := &dynamodb.UpdateItemInput{TableName: aws.String("rate_limits")}
+	sent, _, err = runIsolated(t, tenant.WithID(context.Background(), "acme"), shared, &dynamodb.UpdateItemOutput{})
+	require.NoError(t, err)
+	assert.Same(t, shared, sent)
+
+	_, _, err = runIsolated(t, tenant.WithID(context.Background(), "acme"), &dynamodb.TransactWriteItemsInput{}, nil)
+	assert.ErrorIs(t, err, errTenantUnsupported)
+}
+
+func TestTenantIsolation_BatchWrites(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	puts := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"devices": {{PutRequest: &types.PutRequest{Item: strKey("device_id", "d1")}}},
+	}}
+	sent, _, err := runIsolated(t, ctx, puts, &dynamodb.BatchWriteItemOutput{})
+	require.NoError(t, err)
+	item := sent.(*dynamodb.BatchWriteItemInput).RequestItems["devices"][0].PutRequest.Item
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "acme"}, item[tenantAttr])
+	assert.NotContains(t, puts.RequestItems["devices"][0].PutRequest.Item, tenantAttr)
+
+	deletes := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"devices": {{DeleteRequest: &types.DeleteRequest{Key: strKey("device_id", "d1")}}},
+	}}
+	_, _, err = runIsolated(t, ctx, deletes, nil)
+	assert.ErrorIs(t, err, errTenantUnsupported, "a delete cannot be conditioned on the tenant")
+}
+
+// sharedEmailServer answers email_key-index Queries for two users sharing an
+// email, globex's first, one item per page. Like DynamoDB it applies the
+// tenant filter after reading a page, so an early page may come back empty.
+func sharedEmailServer(t *testing.T) *dynamodb.Client {
+	owners := []string{"globex", "acme"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Limit                     *int
+			ExclusiveStartKey         map[string]map[string]string
+			ExpressionAttributeValues map[string]map[string]string
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		assert.Nil(t, in.Limit, "a Limit would be applied before the tenant filter")
+		page := 0
+		if in.ExclusiveStartKey != nil {
+			page = 1
+		}
+		items := "[]"
+		if owners[page] == in.ExpressionAttributeValues[":tenant_id"]["S"] {
+			items = fmt.Sprintf(`[{"user_id":{"S":"%s-user"},"email":{"S":"a@b.com"},"tenant_id":{"S":"%s"}}]`, owners[page], owners[page])
+		}
+		next := ""
+		if page == 0 {
+			next = `,"LastEvaluatedKey":{"user_id":{"S":"globex-user"},"email_key":{"S":"a@b.com"}}`
+		}
+		fmt.Fprintf(w, `{"Items":%s%s}`, items, next)
+	}))
+	t.Cleanup(srv.Close)
+	return testClient(t, srv.URL, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, TenantAPIOptions(nil)...)
+	})
+}
+
+func TestUserRepo_GetByEmailFindsTheTenantsUserBehindAnother(t *testing.T) {
+	repo := NewUserRepo(sharedEmailServer(t), "users", false, nil)
+
+	u, err := repo.GetByEmail(tenant.WithID(context.Background(), "acme"), "a@b.com")
+	require.NoError(t, err)
+	assert.Equal(t, "acme-user", u.UserID)
+
+	u, err = repo.GetByEmail(tenant.WithID(context.Background(), "globex"), "a@b.com")
+	require.NoError(t, err)
+	assert.Equal(t, "globex-user", u.UserID)
+
+	_, err = repo.GetByEmail(tenant.WithID(context.Background(), "initech"), "a@b.com")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
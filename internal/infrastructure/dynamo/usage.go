@@ -0,0 +1,74 @@
+package dynamo
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// UsageRepo stores per-user daily usage counters.
+// PK: user_id, SK: day. Items expire through the expires_at TTL attribute.
+type UsageRepo struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewUsageRepo(client *dynamodb.Client, tableName string) *UsageRepo {
+	return &UsageRepo{client: client, tableName: tableName}
+}
+
+func usageKey(userID, day string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"user_id": &types.AttributeValueMemberS{Value: userID},
+		"day":     &types.AttributeValueMemberS{Value: day},
+	}
+}
+
+// Add atomically adds the counters of delta to userID's usage on day,
+// creating the item on first use, and returns the new totals. expiresAt
+// (Unix seconds) is written as the item TTL.
+func (r *UsageRepo) Add(ctx context.Context, userID, day string, delta domain.Usage, expiresAt int64) (*domain.Usage, error) {
+	out, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              usageKey(userID, day),
+		UpdateExpression: aws.String("ADD requests :r, bytes_in :in, bytes_out :out SET expires_at = :exp"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":r":   &types.AttributeValueMemberN{Value: strconv.FormatInt(delta.Requests, 10)},
+			":in":  &types.AttributeValueMemberN{Value: strconv.FormatInt(delta.BytesIn, 10)},
+			":out": &types.AttributeValueMemberN{Value: strconv.FormatInt(delta.BytesOut, 10)},
+			":exp": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var u domain.Usage
+	if err := attributevalue.UnmarshalMap(out.Attributes, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Get returns userID's usage on day; a day without requests is all zeros.
+func (r *UsageRepo) Get(ctx context.Context, userID, day string) (*domain.Usage, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       usageKey(userID, day),
+	})
+	if err != nil {
+		return nil, err
+	}
+	u := domain.Usage{UserID: userID, Day: day}
+	if out.Item != nil {
+		if err := attributevalue.UnmarshalMap(out.Item, &u); err != nil {
+			return nil, err
+		}
+	}
+	return &u, nil
+}
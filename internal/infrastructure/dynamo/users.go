@@ -2,8 +2,11 @@ package dynamo
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
+	"maps"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -14,27 +17,80 @@ import (
 )
 
 // UserRepo provides typed DynamoDB operations for the users table.
+// Email and username lookups go through normalized keys, so they are
+// case-insensitive (see domain.NormalizeEmail).
 type UserRepo struct {
 	client    *dynamodb.Client
 	tableName string
+	foldGmail bool
+	cursors   *CursorCodec
+	total     *approxCount
+	pii       fieldCipher // nil stores PII in plaintext; see WithPII
+	scan      ParallelScan
 }
 
-func NewUserRepo(client *dynamodb.Client, tableName string) *UserRepo {
-	return &UserRepo{client: client, tableName: tableName}
+// NewUserRepo builds the repo. cursors may be nil when QueryPage is not used
+// (e.g. in the migration tool).
+func NewUserRepo(client *dynamodb.Client, tableName string, foldGmail bool, cursors *CursorCodec) *UserRepo {
+	return &UserRepo{
+		client:    client,
+		tableName: tableName,
+		foldGmail: foldGmail,
+		cursors:   cursors,
+		total:     newApproxCount(client, tableName),
+	}
+}
+
+// WithParallelScan sets how SweepBefore, AnonymizeBefore, Each and
+// ReencryptPII split their full-table scans.
+func (r *UserRepo) WithParallelScan(p ParallelScan) *UserRepo {
+	r.scan = p
+	return r
 }
 
 func (r *UserRepo) Put(ctx context.Context, u *domain.User) error {
+	u.EmailKey = domain.NormalizeEmail(u.Email, r.foldGmail)
+	u.UsernameKey = domain.NormalizeUsername(u.Username)
 	item, err := attributevalue.MarshalMap(u)
 	if err != nil {
 		return fmt.Errorf("marshal user: %w", err)
 	}
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+	plain := maps.Clone(item)
+	r.sealItem(item)
+	err = putNew(ctx, r.client, &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
 		Item:      item,
-	})
+	}, "user_id")
+	if errors.Is(err, domain.ErrConflict) && r.pii != nil && r.samePlain(ctx, u.UserID, plain) {
+		return nil
+	}
 	return err
 }
 
+// BatchPut writes users in batches. Unlike Put it overwrites existing items
+// and does not check uniqueness, so callers must assign fresh IDs and have
+// checked usernames and emails themselves.
+func (r *UserRepo) BatchPut(ctx context.Context, users []domain.User) error {
+	requests := make([]types.WriteRequest, len(users))
+	for i := range users {
+		u := &users[i]
+		u.EmailKey = domain.NormalizeEmail(u.Email, r.foldGmail)
+		u.UsernameKey = domain.NormalizeUsername(u.Username)
+		item, err := attributevalue.MarshalMap(u)
+		if err != nil {
+			return fmt.Errorf("marshal user: %w", err)
+		}
+		r.sealItem(item)
+		requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}
+	}
+	return batchWrite(ctx, r.client, r.tableName, requests)
+}
+
+// BatchDelete permanently removes the users in userIDs. Missing IDs are ignored.
+func (r *UserRepo) BatchDelete(ctx context.Context, userIDs []string) error {
+	return batchWrite(ctx, r.client, r.tableName, deleteRequests("user_id", userIDs))
+}
+
 func (r *UserRepo) Get(ctx context.Context, userID string) (*domain.User, error) {
 	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(r.tableName),
@@ -46,26 +102,47 @@ func (r *UserRepo) Get(ctx context.Context, userID string) (*domain.User, error)
 	if out.Item == nil {
 		return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
 	}
-	var u domain.User
-	if err := attributevalue.UnmarshalMap(out.Item, &u); err != nil {
+	u, err := r.decodeUser(ctx, out.Item)
+	if err != nil {
 		return nil, err
 	}
 	if u.DeletedAt != nil {
 		return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
 	}
-	return &u, nil
+	return u, nil
 }
 
+// GetByUsername matches case-insensitively. Until cmd/migrate has backfilled
+// username_key, it falls back to an exact match on the legacy index.
 func (r *UserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
-	return r.queryGSI(ctx, "username-index", "username", username)
+	u, err := r.queryGSI(ctx, "username_key-index", fieldUsernameKey, domain.NormalizeUsername(username))
+	if errors.Is(err, domain.ErrNotFound) {
+		return r.queryGSI(ctx, "username-index", "username", username)
+	}
+	return u, err
 }
 
+// GetByEmail matches on the normalized email. Until cmd/migrate has
+// backfilled email_key, it falls back to an exact match on the legacy index.
 func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	return r.queryGSI(ctx, "email-index", "email", email)
+	u, err := r.queryGSI(ctx, "email_key-index", fieldEmailKey, domain.NormalizeEmail(email, r.foldGmail))
+	if errors.Is(err, domain.ErrNotFound) {
+		return r.queryGSI(ctx, "email-index", "email", email)
+	}
+	return u, err
 }
 
 func (r *UserRepo) Update(ctx context.Context, userID string, updates map[string]interface{}) error {
+	if email, ok := updates["email"].(string); ok {
+		updates[fieldEmailKey] = domain.NormalizeEmail(email, r.foldGmail)
+	}
+	if username, ok := updates["username"].(string); ok {
+		updates[fieldUsernameKey] = domain.NormalizeUsername(username)
+	}
 	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	if err := r.sealUpdates(updates); err != nil {
+		return err
+	}
 	ue, err := buildUpdateExpr(updates)
 	if err != nil {
 		return err
@@ -87,77 +164,421 @@ func (r *UserRepo) SoftDelete(ctx context.Context, userID string) error {
 	})
 }
 
-// QueryPage returns a page of enabled users via the enable-index GSI.
-// cursor is a base64-encoded user_id used as ExclusiveStartKey.
-// Returns the items, a next cursor (empty string when no more pages), and any error.
-func (r *UserRepo) QueryPage(ctx context.Context, limit int32, cursor string) ([]domain.User, string, error) {
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String(r.tableName),
-		IndexName:              aws.String("enable-index"),
-		KeyConditionExpression: aws.String("#en = :active"),
-		ExpressionAttributeNames: map[string]string{
-			"#en": "enable",
+// ClaimOnboarding adds step to the user's onboarding_sent set and reports
+// whether this call added it, so of several replicas racing to send the same
+// onboarding email exactly one wins.
+func (r *UserRepo) ClaimOnboarding(ctx context.Context, userID, step string) (bool, error) {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(r.tableName),
+		Key:                      strKey("user_id", userID),
+		UpdateExpression:         aws.String("ADD #s :set"),
+		ConditionExpression:      aws.String("attribute_exists(#id) AND NOT contains(#s, :step)"),
+		ExpressionAttributeNames: map[string]string{"#s": fieldOnboardingSent, "#id": fieldUserID},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":set":  &types.AttributeValueMemberSS{Value: []string{step}},
+			":step": &types.AttributeValueMemberS{Value: step},
+		},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ReleaseOnboarding removes step from the user's onboarding_sent set, after
+// the email claimed by ClaimOnboarding could not be sent.
+func (r *UserRepo) ReleaseOnboarding(ctx context.Context, userID, step string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("user_id", userID),
+		UpdateExpression:          aws.String("DELETE #s :set"),
+		ConditionExpression:       aws.String("attribute_exists(#id)"),
+		ExpressionAttributeNames:  map[string]string{"#s": fieldOnboardingSent, "#id": fieldUserID},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":set": &types.AttributeValueMemberSS{Value: []string{step}}},
+	})
+	return err
+}
+
+// ClaimBirthday sets the user's birthday_greeted to year and reports whether
+// this call changed it, so of several replicas racing to send the same
+// birthday notification exactly one wins.
+func (r *UserRepo) ClaimBirthday(ctx context.Context, userID, year string) (bool, error) {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("user_id", userID),
+		UpdateExpression:          aws.String("SET #b = :year"),
+		ConditionExpression:       aws.String("attribute_exists(#id) AND (attribute_not_exists(#b) OR #b <> :year)"),
+		ExpressionAttributeNames:  map[string]string{"#b": fieldBirthdayGreeted, "#id": fieldUserID},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":year": &types.AttributeValueMemberS{Value: year}},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SweepBefore hard-deletes users soft-deleted before cutoff, or only counts
+// them when dryRun is set.
+func (r *UserRepo) SweepBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error) {
+	return r.scan.sweep(ctx, r.client, &dynamodb.ScanInput{
+		TableName:                aws.String(r.tableName),
+		FilterExpression:         aws.String("#del < :cutoff"),
+		ProjectionExpression:     aws.String("user_id"),
+		ExpressionAttributeNames: map[string]string{"#del": fieldDeletedAt},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":cutoff": &types.AttributeValueMemberS{Value: cutoff.UTC().Format(time.RFC3339)},
 		},
+	}, dryRun)
+}
+
+// anonymizedRemove lists the attributes an anonymized user loses outright.
+var anonymizedRemove = []string{fieldPhone, fieldBirthday, "google_sub", "password_hash", fieldPIIKeyVersion}
+
+// AnonymizeBefore scrubs the personal data of users soft-deleted before
+// cutoff, or only counts them when dryRun is set. The item and its user_id
+// stay, so audit entries and other references still resolve.
+func (r *UserRepo) AnonymizeBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error) {
+	ctx = allowScans(ctx)
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(r.tableName),
+		FilterExpression:         aws.String("#del < :cutoff AND attribute_not_exists(#anon)"),
+		ExpressionAttributeNames: map[string]string{"#del": fieldDeletedAt, "#anon": fieldAnonymizedAt},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":cutoff": &types.AttributeValueMemberS{Value: cutoff.UTC().Format(time.RFC3339)},
+		},
+	}
+	n := 0
+	err := r.scanUsers(ctx, input, func(u domain.User) error {
+		if !dryRun {
+			if err := r.anonymize(ctx, &u); err != nil {
+				return fmt.Errorf("anonymize user %s: %w", u.UserID, err)
+			}
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+func (r *UserRepo) anonymize(ctx context.Context, u *domain.User) error {
+	tombstone := domain.TombstoneEmail(domain.NormalizeEmail(u.Email, r.foldGmail))
+	ue, err := buildUpdateExpr(map[string]interface{}{
+		"email":           tombstone,
+		fieldEmailKey:     tombstone,
+		"username":        "deleted-" + u.UserID,
+		fieldUsernameKey:  domain.NormalizeUsername("deleted-" + u.UserID),
+		"first_name":      "",
+		"last_name":       "",
+		fieldAnonymizedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	removes := make([]string, len(anonymizedRemove))
+	for i, name := range anonymizedRemove {
+		removes[i] = "#rm" + strconv.Itoa(i)
+		ue.Names[removes[i]] = name
+	}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("user_id", u.UserID),
+		UpdateExpression:          aws.String(ue.Expr + " REMOVE " + strings.Join(removes, ", ")),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return err
+}
+
+// Each calls fn for every user in the table, including disabled and deleted
+// ones. It is a parallel full scan, meant for reindexing.
+func (r *UserRepo) Each(ctx context.Context, fn func(domain.User) error) error {
+	return r.scanUsers(allowScans(ctx), &dynamodb.ScanInput{TableName: aws.String(r.tableName)}, fn)
+}
+
+// EachMatching calls fn for every user that matches f, including deleted
+// ones. It queries role-created_at-index when f names a role and otherwise
+// each enable partition of enable-created_at-index, so users come in
+// created_at order within each partition. f's dates must already be
+// validated.
+func (r *UserRepo) EachMatching(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error {
+	if f.Role != "" {
+		return r.queryUsers(ctx, exportQuery(r.tableName, f, "role-created_at-index", fieldRole, &types.AttributeValueMemberS{Value: f.Role}), fn)
+	}
+	enables := []int{1, 0}
+	if f.Enable != nil {
+		enables = []int{*f.Enable}
+	}
+	for _, en := range enables {
+		key := &types.AttributeValueMemberN{Value: strconv.Itoa(en)}
+		if err := r.queryUsers(ctx, exportQuery(r.tableName, f, "enable-created_at-index", fieldEnable, key), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportQuery builds EachMatching's query for the partition of index where
+// keyAttr is key. The created range is a sort key condition; the remaining
+// filters are applied after the read.
+func exportQuery(table string, f domain.UserFilter, index, keyAttr string, key types.AttributeValue) *dynamodb.QueryInput {
+	names := map[string]string{"#k": keyAttr}
+	values := map[string]types.AttributeValue{":k": key}
+	keyCond := "#k = :k"
+	if cond := createdRange(f, values); cond != "" {
+		keyCond += " AND " + cond
+		names["#ca"] = fieldCreatedAt
+	}
+	var filters []string
+	if f.Enable != nil && keyAttr != fieldEnable {
+		filters = append(filters, "#en = :en")
+		names["#en"] = fieldEnable
+		values[":en"] = &types.AttributeValueMemberN{Value: strconv.Itoa(*f.Enable)}
+	}
+	if f.EmailConfirmed != nil {
+		filters = append(filters, "#ec = :ec")
+		names["#ec"] = fieldEmailConfirmed
+		values[":ec"] = &types.AttributeValueMemberBOOL{Value: *f.EmailConfirmed}
+	}
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		IndexName:                 aws.String(index),
+		KeyConditionExpression:    aws.String(keyCond),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+	if len(filters) > 0 {
+		input.FilterExpression = aws.String(strings.Join(filters, " AND "))
+	}
+	return input
+}
+
+// BackfillKeys sets email_key and username_key on users written before they
+// existed. It is idempotent and returns the number of users updated.
+func (r *UserRepo) BackfillKeys(ctx context.Context) (int, error) {
+	ctx = allowScans(ctx)
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(r.tableName),
+		FilterExpression:         aws.String("attribute_not_exists(#ek) OR attribute_not_exists(#uk)"),
+		ExpressionAttributeNames: map[string]string{"#ek": fieldEmailKey, "#uk": fieldUsernameKey},
+	}
+	updated := 0
+	for {
+		out, err := r.client.Scan(ctx, input)
+		if err != nil {
+			return updated, err
+		}
+		users, err := r.decodeUsers(ctx, out.Items)
+		if err != nil {
+			return updated, err
+		}
+		for _, u := range users {
+			keys := map[string]interface{}{
+				fieldEmailKey:    domain.NormalizeEmail(u.Email, r.foldGmail),
+				fieldUsernameKey: domain.NormalizeUsername(u.Username),
+			}
+			if err := r.setKeys(ctx, u.UserID, keys); err != nil {
+				return updated, fmt.Errorf("backfill user %s: %w", u.UserID, err)
+			}
+			updated++
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return updated, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// setKeys writes lookup keys without touching updated_at.
+func (r *UserRepo) setKeys(ctx context.Context, userID string, keys map[string]interface{}) error {
+	ue, err := buildUpdateExpr(keys)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       strKey("user_id", userID),
+		UpdateExpression:          aws.String(ue.Expr),
+		ExpressionAttributeNames:  ue.Names,
+		ExpressionAttributeValues: ue.Values,
+	})
+	return err
+}
+
+// CountByRole returns how many enabled users have role, counting the role's
+// partition of role-created_at-index.
+func (r *UserRepo) CountByRole(ctx context.Context, role string) (int, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                aws.String(r.tableName),
+		IndexName:                aws.String("role-created_at-index"),
+		KeyConditionExpression:   aws.String("#r = :role"),
+		FilterExpression:         aws.String("#en = :active"),
+		ExpressionAttributeNames: map[string]string{"#r": fieldRole, "#en": fieldEnable},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":role":   &types.AttributeValueMemberS{Value: role},
 			":active": &types.AttributeValueMemberN{Value: "1"},
 		},
-		Limit: aws.Int32(limit),
+		Select: types.SelectCount,
 	}
-	if cursor != "" {
-		userID, err := decodeCursor(cursor)
+	count := 0
+	for {
+		out, err := r.client.Query(ctx, input)
 		if err != nil {
-			return nil, "", fmt.Errorf("invalid cursor: %w", domain.ErrBadRequest)
+			return 0, err
 		}
-		input.ExclusiveStartKey = map[string]types.AttributeValue{
-			"user_id": &types.AttributeValueMemberS{Value: userID},
-			"enable":  &types.AttributeValueMemberN{Value: "1"},
+		count += int(out.Count)
+		if len(out.LastEvaluatedKey) == 0 {
+			return count, nil
 		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// ApproxCount returns DynamoDB's periodically refreshed item count for the
+// table, deleted users included.
+func (r *UserRepo) ApproxCount(ctx context.Context) (int64, error) {
+	return r.total.get(ctx)
+}
+
+// QueryPage returns a page of non-deleted users matching f, newest first
+// unless f.Ascending. With a role it queries role-created_at-index, otherwise
+// enable-created_at-index (f.Enable defaults to 1); the created range is a
+// sort key condition. The remaining filters are applied by DynamoDB after the
+// read, so a page may hold fewer than limit users. f's dates must already be
+// validated.
+// cursor is an opaque signed cursor from a previous page (see CursorCodec).
+// Returns the items, a next cursor (empty string when no more pages), and any error.
+func (r *UserRepo) QueryPage(ctx context.Context, f domain.UserFilter, limit int32, cursor string) ([]domain.User, string, error) {
+	input, scope := r.listQuery(f)
+	input.Limit = aws.Int32(limit)
+	if cursor != "" {
+		key, err := r.cursors.Decode(scope, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		input.ExclusiveStartKey = key
 	}
 	out, err := r.client.Query(ctx, input)
 	if err != nil {
 		return nil, "", err
 	}
-	users := make([]domain.User, 0, len(out.Items))
-	if err := attributevalue.UnmarshalListOfMaps(out.Items, &users); err != nil {
+	users, err := r.decodeUsers(ctx, out.Items)
+	if err != nil {
 		return nil, "", err
 	}
-	nextCursor := ""
-	if v, ok := out.LastEvaluatedKey["user_id"].(*types.AttributeValueMemberS); ok {
-		nextCursor = encodeCursor(v.Value)
+	nextCursor, err := r.cursors.Encode(scope, out.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", err
 	}
 	return users, nextCursor, nil
 }
 
-func encodeCursor(userID string) string {
-	return base64.RawURLEncoding.EncodeToString([]byte(userID))
+// CountMatching counts the users QueryPage lists for f. It reads the same
+// index partition, so it costs as much read capacity as listing them.
+func (r *UserRepo) CountMatching(ctx context.Context, f domain.UserFilter) (int, error) {
+	input, _ := r.listQuery(f)
+	input.Select = types.SelectCount
+	count := 0
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		count += int(out.Count)
+		if len(out.LastEvaluatedKey) == 0 {
+			return count, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
 }
 
-func decodeCursor(cursor string) (string, error) {
-	b, err := base64.RawURLEncoding.DecodeString(cursor)
-	if err != nil {
-		return "", domain.ErrBadRequest
+// listQuery builds QueryPage's query for f and the cursor scope naming the
+// index partition it reads.
+func (r *UserRepo) listQuery(f domain.UserFilter) (*dynamodb.QueryInput, string) {
+	names := map[string]string{"#del": fieldDeletedAt}
+	values := map[string]types.AttributeValue{}
+	filters := []string{"attribute_not_exists(#del)"}
+	enable := 1
+	if f.Enable != nil {
+		enable = *f.Enable
 	}
-	return string(b), nil
+	values[":en"] = &types.AttributeValueMemberN{Value: strconv.Itoa(enable)}
+	names["#en"] = fieldEnable
+	index, keyCond, scope := "enable-created_at-index", "#en = :en", "users/enable/"+strconv.Itoa(enable)
+	if f.Role != "" {
+		index, keyCond, scope = "role-created_at-index", "#role = :role", "users/role/"+f.Role
+		names["#role"] = fieldRole
+		values[":role"] = &types.AttributeValueMemberS{Value: f.Role}
+		filters = append(filters, "#en = :en")
+	}
+	if f.EmailConfirmed != nil {
+		filters = append(filters, "#ec = :ec")
+		names["#ec"] = fieldEmailConfirmed
+		values[":ec"] = &types.AttributeValueMemberBOOL{Value: *f.EmailConfirmed}
+	}
+	if cond := createdRange(f, values); cond != "" {
+		keyCond += " AND " + cond
+		names["#ca"] = fieldCreatedAt
+	}
+	return &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		IndexName:                 aws.String(index),
+		KeyConditionExpression:    aws.String(keyCond),
+		FilterExpression:          aws.String(strings.Join(filters, " AND ")),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ScanIndexForward:          aws.Bool(f.Ascending),
+	}, scope
 }
 
+// createdRange returns the created_at sort key condition for f's date bounds
+// and sets its values, or "" when f has none. created_at is RFC 3339, so
+// "< next day" is an inclusive day bound.
+func createdRange(f domain.UserFilter, values map[string]types.AttributeValue) string {
+	var to string
+	if f.CreatedTo != "" {
+		day, _ := time.Parse("2006-01-02", f.CreatedTo)
+		to = day.AddDate(0, 0, 1).Format("2006-01-02")
+		values[":to"] = &types.AttributeValueMemberS{Value: to}
+	}
+	if f.CreatedFrom != "" {
+		values[":from"] = &types.AttributeValueMemberS{Value: f.CreatedFrom}
+	}
+	switch {
+	case f.CreatedFrom != "" && to != "":
+		return "#ca BETWEEN :from AND :to"
+	case f.CreatedFrom != "":
+		return "#ca >= :from"
+	case to != "":
+		return "#ca < :to"
+	}
+	return ""
+}
+
+// queryGSI returns the first user whose attr equals value on index. It reads
+// every page rather than setting Limit: DynamoDB applies Limit before the
+// tenant filter of TENANT_MODE, so another tenant's user with the same email
+// or username would otherwise hide the caller's.
 func (r *UserRepo) queryGSI(ctx context.Context, index, attr, value string) (*domain.User, error) {
-	out, err := r.client.Query(ctx, &dynamodb.QueryInput{
+	input := &dynamodb.QueryInput{
 		TableName:                 aws.String(r.tableName),
 		IndexName:                 aws.String(index),
 		KeyConditionExpression:    aws.String("#a = :v"),
 		ExpressionAttributeNames:  map[string]string{"#a": attr},
 		ExpressionAttributeValues: map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: value}},
-		Limit:                     aws.Int32(1),
-	})
-	if err != nil {
-		return nil, err
-	}
-	if len(out.Items) == 0 {
-		return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
 	}
-	var u domain.User
-	if err := attributevalue.UnmarshalMap(out.Items[0], &u); err != nil {
-		return nil, err
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if len(out.Items) > 0 {
+			return r.decodeUser(ctx, out.Items[0])
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
 	}
-	return &u, nil
 }
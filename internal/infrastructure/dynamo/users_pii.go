@@ -0,0 +1,206 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/infrastructure/envelope"
+)
+
+// piiFields are the user attributes sealed when PII encryption is on.
+var piiFields = []string{fieldPhone, fieldBirthday}
+
+// fieldCipher seals attribute values; *envelope.Cipher satisfies it.
+type fieldCipher interface {
+	Seal(plaintext string) string
+	Open(ctx context.Context, value string) (string, error)
+	Version() string
+}
+
+// WithPII makes the repo seal phone and birthday on every write and open them
+// on every read. pii_key_version records the master key an item was sealed
+// under; it is only written when all of the item's PII is sealed at once, so
+// a partial update never hides older ciphertext from ReencryptPII.
+func (r *UserRepo) WithPII(c fieldCipher) *UserRepo {
+	r.pii = c
+	return r
+}
+
+func (r *UserRepo) sealItem(item map[string]types.AttributeValue) {
+	if r.pii == nil {
+		return
+	}
+	for _, f := range piiFields {
+		if s, ok := item[f].(*types.AttributeValueMemberS); ok && !envelope.IsSealed(s.Value) {
+			item[f] = &types.AttributeValueMemberS{Value: r.pii.Seal(s.Value)}
+		}
+	}
+	item[fieldPIIKeyVersion] = &types.AttributeValueMemberS{Value: r.pii.Version()}
+}
+
+// sealUpdates seals the PII values in an Update map.
+func (r *UserRepo) sealUpdates(updates map[string]interface{}) error {
+	if r.pii == nil {
+		return nil
+	}
+	sealed := 0
+	for _, f := range piiFields {
+		v, ok := updates[f]
+		if !ok {
+			continue
+		}
+		av, err := attributevalue.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshal field %s: %w", f, err)
+		}
+		if s, ok := av.(*types.AttributeValueMemberS); ok {
+			updates[f] = r.pii.Seal(s.Value)
+			sealed++
+		}
+	}
+	if sealed == len(piiFields) {
+		updates[fieldPIIKeyVersion] = r.pii.Version()
+	}
+	return nil
+}
+
+// openItem replaces sealed PII in item with its plaintext.
+func (r *UserRepo) openItem(ctx context.Context, item map[string]types.AttributeValue) error {
+	for _, f := range piiFields {
+		s, ok := item[f].(*types.AttributeValueMemberS)
+		if !ok || !envelope.IsSealed(s.Value) {
+			continue
+		}
+		if r.pii == nil {
+			return fmt.Errorf("user %s is encrypted but PII_ENCRYPTION is off", f)
+		}
+		plain, err := r.pii.Open(ctx, s.Value)
+		if err != nil {
+			return fmt.Errorf("open user %s: %w", f, err)
+		}
+		item[f] = &types.AttributeValueMemberS{Value: plain}
+	}
+	return nil
+}
+
+// samePlain reports whether the stored user userID, once opened, equals
+// plain, an unsealed item. Sealing is randomized, so a resent Put of the same
+// user never matches the stored item byte for byte.
+func (r *UserRepo) samePlain(ctx context.Context, userID string, plain map[string]types.AttributeValue) bool {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            strKey("user_id", userID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil || out.Item == nil || r.openItem(ctx, out.Item) != nil {
+		return false
+	}
+	delete(out.Item, fieldPIIKeyVersion)
+	return reflect.DeepEqual(out.Item, plain)
+}
+
+func (r *UserRepo) decodeUser(ctx context.Context, item map[string]types.AttributeValue) (*domain.User, error) {
+	if err := r.openItem(ctx, item); err != nil {
+		return nil, err
+	}
+	var u domain.User
+	if err := attributevalue.UnmarshalMap(item, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *UserRepo) decodeUsers(ctx context.Context, items []map[string]types.AttributeValue) ([]domain.User, error) {
+	for _, item := range items {
+		if err := r.openItem(ctx, item); err != nil {
+			return nil, err
+		}
+	}
+	users := make([]domain.User, 0, len(items))
+	if err := attributevalue.UnmarshalListOfMaps(items, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// scanUsers runs input as a parallel scan (see WithParallelScan) and calls fn
+// for every user, opening sealed PII first.
+func (r *UserRepo) scanUsers(ctx context.Context, input *dynamodb.ScanInput, fn func(domain.User) error) error {
+	return r.scan.eachPage(ctx, r.client, input, func(items []map[string]types.AttributeValue) error {
+		users, err := r.decodeUsers(ctx, items)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			if err := fn(u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// queryUsers is scanUsers for queries, paged sequentially.
+func (r *UserRepo) queryUsers(ctx context.Context, input *dynamodb.QueryInput, fn func(domain.User) error) error {
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return err
+		}
+		users, err := r.decodeUsers(ctx, out.Items)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			if err := fn(u); err != nil {
+				return err
+			}
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// ReencryptPII seals the PII of every user not yet sealed under the current
+// master key: plaintext rows from before encryption was enabled, and rows
+// sealed before a key rotation. It is idempotent and returns the number of
+// users rewritten.
+func (r *UserRepo) ReencryptPII(ctx context.Context) (int, error) {
+	if r.pii == nil {
+		return 0, fmt.Errorf("PII_ENCRYPTION is off")
+	}
+	ctx = allowScans(ctx)
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(r.tableName),
+		FilterExpression:         aws.String("attribute_not_exists(#v) OR #v <> :v"),
+		ExpressionAttributeNames: map[string]string{"#v": fieldPIIKeyVersion},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":v": &types.AttributeValueMemberS{Value: r.pii.Version()},
+		},
+	}
+	updated := 0
+	err := r.scanUsers(ctx, input, func(u domain.User) error {
+		updates := map[string]interface{}{fieldBirthday: u.Birthday}
+		if u.Phone != nil {
+			updates[fieldPhone] = *u.Phone
+		}
+		if err := r.sealUpdates(updates); err != nil {
+			return err
+		}
+		updates[fieldPIIKeyVersion] = r.pii.Version()
+		if err := r.setKeys(ctx, u.UserID, updates); err != nil {
+			return fmt.Errorf("reencrypt user %s: %w", u.UserID, err)
+		}
+		updated++
+		return nil
+	})
+	return updated, err
+}
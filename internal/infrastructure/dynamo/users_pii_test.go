@@ -0,0 +1,79 @@
+package dynamo
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/infrastructure/envelope"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func piiRepo(t *testing.T) *UserRepo {
+	t.Helper()
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	c, err := envelope.New(context.Background(), &config.Config{PIIEncryption: "local", PIILocalKeys: "k1:" + key})
+	require.NoError(t, err)
+	return (&UserRepo{}).WithPII(c)
+}
+
+func TestPII_SealedItemRoundTrips(t *testing.T) {
+	r := piiRepo(t)
+	phone := "+15551234567"
+	birthday := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	item, err := attributevalue.MarshalMap(domain.User{UserID: "u1", Phone: &phone, Birthday: birthday})
+	require.NoError(t, err)
+
+	r.sealItem(item)
+
+	assert.True(t, envelope.IsSealed(item[fieldPhone].(*types.AttributeValueMemberS).Value))
+	assert.True(t, envelope.IsSealed(item[fieldBirthday].(*types.AttributeValueMemberS).Value))
+	assert.Equal(t, "local:k1", item[fieldPIIKeyVersion].(*types.AttributeValueMemberS).Value)
+	u, err := r.decodeUser(context.Background(), item)
+	require.NoError(t, err)
+	assert.Equal(t, phone, *u.Phone)
+	assert.True(t, birthday.Equal(u.Birthday))
+}
+
+func TestPII_PlaintextRowsStillLoad(t *testing.T) {
+	item, err := attributevalue.MarshalMap(domain.User{UserID: "u1", Birthday: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+
+	u, err := piiRepo(t).decodeUser(context.Background(), item)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1990, u.Birthday.Year())
+	assert.Nil(t, u.Phone)
+}
+
+func TestPII_SealedRowNeedsKey(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"user_id":  &types.AttributeValueMemberS{Value: "u1"},
+		fieldPhone: &types.AttributeValueMemberS{Value: piiRepo(t).pii.Seal("+1555")},
+	}
+
+	_, err := (&UserRepo{}).decodeUser(context.Background(), item)
+
+	assert.ErrorContains(t, err, "PII_ENCRYPTION is off")
+}
+
+func TestPII_PartialUpdateKeepsKeyVersion(t *testing.T) {
+	r := piiRepo(t)
+	updates := map[string]interface{}{fieldPhone: "+1555", "first_name": "Ann"}
+
+	require.NoError(t, r.sealUpdates(updates))
+
+	assert.True(t, envelope.IsSealed(updates[fieldPhone].(string)))
+	assert.Equal(t, "Ann", updates["first_name"])
+	assert.NotContains(t, updates, fieldPIIKeyVersion, "birthday may still be sealed under an older key")
+
+	updates = map[string]interface{}{fieldPhone: "+1555", fieldBirthday: time.Now()}
+	require.NoError(t, r.sealUpdates(updates))
+	assert.Equal(t, "local:k1", updates[fieldPIIKeyVersion])
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/go-api-nosql/internal/domain"
 )
 
@@ -58,3 +59,25 @@ func (r *VerificationRepo) Delete(ctx context.Context, userID, verType string) e
 	})
 	return err
 }
+
+// Take deletes a verification and returns it as it was, so a code or
+// challenge can be used once even by concurrent requests. A missing row is
+// domain.ErrNotFound; an expired one is returned for the caller to refuse.
+func (r *VerificationRepo) Take(ctx context.Context, userID, verType string) (*domain.UserVerification, error) {
+	out, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(r.tableName),
+		Key:          compositeKey("user_id", userID, "type", verType),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Attributes == nil {
+		return nil, fmt.Errorf("verification not found: %w", domain.ErrNotFound)
+	}
+	var v domain.UserVerification
+	if err := attributevalue.UnmarshalMap(out.Attributes, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
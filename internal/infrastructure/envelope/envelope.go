@@ -0,0 +1,172 @@
+// Package envelope encrypts individual attribute values with envelope
+// encryption. Each process asks a master key, held locally or in AWS KMS, for
+// a data key and seals values with AES-256-GCM under it. The wrapped data key
+// travels with every sealed value, so any process holding the master key can
+// open it, and a table dump alone exposes nothing.
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-api-nosql/internal/config"
+)
+
+// prefix marks a sealed value: "enc1.<wrapped data key>.<nonce+ciphertext>",
+// both parts unpadded base64url.
+const prefix = "enc1."
+
+var b64 = base64.RawURLEncoding
+
+// dataKey is a plaintext data key and the same key wrapped by the master key.
+type dataKey struct {
+	plain   []byte
+	wrapped []byte
+}
+
+// keyWrapper issues data keys under a master key and unwraps them again.
+type keyWrapper interface {
+	GenerateDataKey(ctx context.Context) (dataKey, error)
+	Decrypt(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// Cipher seals values under its process's data key and opens values sealed
+// by any process sharing the master keys.
+type Cipher struct {
+	keys    keyWrapper
+	version string
+	current cipher.AEAD
+	wrapped string
+
+	mu     sync.Mutex
+	opened map[string]cipher.AEAD // unwrapped data keys by wrapped form
+}
+
+// New returns the cipher configured by PII_ENCRYPTION, or nil when it is
+// off. It makes one master key call to issue the process's data key.
+func New(ctx context.Context, cfg *config.Config) (*Cipher, error) {
+	switch cfg.PIIEncryption {
+	case "":
+		return nil, nil
+	case "local":
+		keys, err := parseLocalKeys(cfg.PIILocalKeys)
+		if err != nil {
+			return nil, err
+		}
+		return newCipher(ctx, keys, "local:"+keys.current)
+	case "kms":
+		if cfg.PIIKMSKeyID == "" {
+			return nil, fmt.Errorf("PII_KMS_KEY_ID is required when PII_ENCRYPTION=kms")
+		}
+		keys, err := newKMSKeys(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newCipher(ctx, keys, "kms:"+cfg.PIIKMSKeyID)
+	}
+	return nil, fmt.Errorf("PII_ENCRYPTION must be local, kms or empty, got %q", cfg.PIIEncryption)
+}
+
+func newCipher(ctx context.Context, keys keyWrapper, version string) (*Cipher, error) {
+	dk, err := keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+	aead, err := newAEAD(dk.plain)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := b64.EncodeToString(dk.wrapped)
+	return &Cipher{
+		keys:    keys,
+		version: version,
+		current: aead,
+		wrapped: wrapped,
+		opened:  map[string]cipher.AEAD{wrapped: aead},
+	}, nil
+}
+
+// Version names the master key new values are sealed under. Values sealed
+// under another version still open as long as that key is available.
+func (c *Cipher) Version() string {
+	return c.version
+}
+
+// Seal encrypts plaintext under the current data key.
+func (c *Cipher) Seal(plaintext string) string {
+	nonce := make([]byte, c.current.NonceSize())
+	rand.Read(nonce)
+	sealed := c.current.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + c.wrapped + "." + b64.EncodeToString(sealed)
+}
+
+// Open decrypts a value returned by Seal. Values that are not sealed, such
+// as rows written before encryption was enabled, are returned unchanged.
+func (c *Cipher) Open(ctx context.Context, value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	wrapped, body, ok := strings.Cut(strings.TrimPrefix(value, prefix), ".")
+	sealed, err := b64.DecodeString(body)
+	if !ok || err != nil {
+		return "", fmt.Errorf("malformed sealed value")
+	}
+	aead, err := c.dataKey(ctx, wrapped)
+	if err != nil {
+		return "", err
+	}
+	n := aead.NonceSize()
+	if len(sealed) < n {
+		return "", fmt.Errorf("malformed sealed value")
+	}
+	plain, err := aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return "", fmt.Errorf("open sealed value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// dataKey returns the data key wrapped as wrapped, unwrapping it through the
+// master key the first time it is seen.
+func (c *Cipher) dataKey(ctx context.Context, wrapped string) (cipher.AEAD, error) {
+	c.mu.Lock()
+	aead, ok := c.opened[wrapped]
+	c.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+	raw, err := b64.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("malformed sealed value")
+	}
+	plain, err := c.keys.Decrypt(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	if aead, err = newAEAD(plain); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.opened[wrapped] = aead
+	c.mu.Unlock()
+	return aead, nil
+}
+
+// IsSealed reports whether value was produced by Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,139 @@
+package envelope
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune('a'+b)), 32)))
+}
+
+func newLocal(t *testing.T, spec string) *Cipher {
+	t.Helper()
+	c, err := New(context.Background(), &config.Config{PIIEncryption: "local", PIILocalKeys: spec})
+	require.NoError(t, err)
+	return c
+}
+
+func TestSealOpen_RoundTrip(t *testing.T) {
+	c := newLocal(t, "k1:"+testKey(1))
+
+	sealed := c.Seal("+15551234567")
+
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, sealed, "5551234567")
+	assert.NotEqual(t, sealed, c.Seal("+15551234567"), "every value gets its own nonce")
+	got, err := c.Open(context.Background(), sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "+15551234567", got)
+	assert.Equal(t, "local:k1", c.Version())
+}
+
+func TestOpen_PlaintextPassesThrough(t *testing.T) {
+	c := newLocal(t, "k1:"+testKey(1))
+
+	got, err := c.Open(context.Background(), "1990-01-02T00:00:00Z")
+
+	require.NoError(t, err)
+	assert.Equal(t, "1990-01-02T00:00:00Z", got)
+}
+
+func TestOpen_AfterRotation(t *testing.T) {
+	old := newLocal(t, "k1:"+testKey(1))
+	sealed := old.Seal("secret")
+
+	rotated := newLocal(t, "k2:"+testKey(2)+",k1:"+testKey(1))
+	got, err := rotated.Open(context.Background(), sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", got)
+	assert.Equal(t, "local:k2", rotated.Version())
+
+	retired := newLocal(t, "k2:"+testKey(2))
+	_, err = retired.Open(context.Background(), sealed)
+	assert.ErrorContains(t, err, `"k1"`)
+}
+
+func TestOpen_TamperedValueFails(t *testing.T) {
+	c := newLocal(t, "k1:"+testKey(1))
+	sealed := c.Seal("secret")
+	i := strings.LastIndex(sealed, ".")
+	body, err := b64.DecodeString(sealed[i+1:])
+	require.NoError(t, err)
+	body[len(body)-1] ^= 1
+
+	_, err = c.Open(context.Background(), sealed[:i+1]+b64.EncodeToString(body))
+
+	assert.Error(t, err)
+}
+
+func TestNew_RejectsBadConfig(t *testing.T) {
+	for _, cfg := range []config.Config{
+		{PIIEncryption: "rot13"},
+		{PIIEncryption: "local", PIILocalKeys: ""},
+		{PIIEncryption: "local", PIILocalKeys: "k1:c2hvcnQ="},
+		{PIIEncryption: "local", PIILocalKeys: "k1:" + testKey(1) + ",k1:" + testKey(2)},
+		{PIIEncryption: "kms"},
+	} {
+		_, err := New(context.Background(), &cfg)
+		assert.Error(t, err, "%+v", cfg)
+	}
+	c, err := New(context.Background(), &config.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+// TestKMS_WrapsThroughAPI fakes KMS by "wrapping" a data key as itself
+// reversed, and checks the request is signed and targeted.
+func TestKMS_WrapsThroughAPI(t *testing.T) {
+	plain := []byte(strings.Repeat("k", 32))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Authorization"), "/kms/aws4_request")
+		var in struct {
+			KeyId          string
+			CiphertextBlob []byte
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GenerateDataKey":
+			assert.Equal(t, "alias/pii", in.KeyId)
+			json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": plain, "CiphertextBlob": []byte("wrapped")})
+		case "TrentService.Decrypt":
+			assert.Equal(t, []byte("wrapped"), in.CiphertextBlob)
+			json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": plain})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+	keys := &kmsKeys{
+		keyID:    "alias/pii",
+		endpoint: srv.URL,
+		region:   "us-east-1",
+		creds:    credentials.NewStaticCredentialsProvider("id", "secret", ""),
+		signer:   v4.NewSigner(),
+		client:   srv.Client(),
+	}
+	writer, err := newCipher(context.Background(), keys, "kms:alias/pii")
+	require.NoError(t, err)
+	reader, err := newCipher(context.Background(), keys, "kms:alias/pii")
+	require.NoError(t, err)
+	reader.opened = map[string]cipher.AEAD{}
+
+	got, err := reader.Open(context.Background(), writer.Seal("1990-01-02"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "1990-01-02", got)
+}
@@ -0,0 +1,70 @@
+package envelope
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// localKeys is the master key ring from PII_LOCAL_KEYS, written as
+// "label:base64key,...". The first key wraps new data keys; the others only
+// unwrap data keys issued before a rotation.
+type localKeys struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+func parseLocalKeys(spec string) (*localKeys, error) {
+	lk := &localKeys{keys: map[string]cipher.AEAD{}}
+	for _, entry := range strings.Split(spec, ",") {
+		label, enc, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || label == "" || len(label) > 255 {
+			return nil, fmt.Errorf("PII_LOCAL_KEYS entries must be label:base64key")
+		}
+		raw, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("PII_LOCAL_KEYS key %q must be 32 bytes of base64", label)
+		}
+		if _, dup := lk.keys[label]; dup {
+			return nil, fmt.Errorf("PII_LOCAL_KEYS lists key %q twice", label)
+		}
+		if lk.keys[label], err = newAEAD(raw); err != nil {
+			return nil, err
+		}
+		if lk.current == "" {
+			lk.current = label
+		}
+	}
+	return lk, nil
+}
+
+// GenerateDataKey returns a random data key wrapped as
+// len(label) | label | nonce | ciphertext, with the label as additional data.
+func (k *localKeys) GenerateDataKey(ctx context.Context) (dataKey, error) {
+	plain := make([]byte, 32)
+	rand.Read(plain)
+	aead := k.keys[k.current]
+	wrapped := append([]byte{byte(len(k.current))}, k.current...)
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	wrapped = aead.Seal(append(wrapped, nonce...), nonce, plain, []byte(k.current))
+	return dataKey{plain: plain, wrapped: wrapped}, nil
+}
+
+func (k *localKeys) Decrypt(ctx context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 1 || len(wrapped) < 1+int(wrapped[0]) {
+		return nil, fmt.Errorf("malformed wrapped key")
+	}
+	label, rest := string(wrapped[1:1+wrapped[0]]), wrapped[1+wrapped[0]:]
+	aead, ok := k.keys[label]
+	if !ok {
+		return nil, fmt.Errorf("local key %q is not in PII_LOCAL_KEYS", label)
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed wrapped key")
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(label))
+}
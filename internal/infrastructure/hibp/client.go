@@ -0,0 +1,71 @@
+// Package hibp checks passwords against the Have I Been Pwned breach corpus
+// through its k-anonymity range API: only the first five hex characters of a
+// password's SHA-1 ever leave the process.
+package hibp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.pwnedpasswords.com"
+
+// Client queries the Pwned Passwords range API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient queries baseURL, e.g. a self-hosted mirror, or the public API
+// when it is empty.
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Breached reports whether password appears in a known breach. The response
+// is padded with decoy suffixes so its size does not hint at the prefix.
+func (c *Client) Breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "go-api-nosql")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("hibp: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("hibp: status %d", resp.StatusCode)
+	}
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 4<<20))
+	for scanner.Scan() {
+		s, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if ok && strings.EqualFold(s, suffix) {
+			n, _ := strconv.Atoi(count)
+			return n > 0, nil // padding entries have a count of 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("hibp: %w", err)
+	}
+	return false, nil
+}
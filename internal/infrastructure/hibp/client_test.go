@@ -0,0 +1,55 @@
+package hibp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+func TestBreached(t *testing.T) {
+	var gotPath, gotPadding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotPadding = r.URL.Path, r.Header.Get("Add-Padding")
+		_, _ = w.Write([]byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n" +
+			"1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\r\n" +
+			"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0\r\n"))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL + "/")
+
+	breached, err := c.Breached(context.Background(), "password")
+	require.NoError(t, err)
+	assert.True(t, breached)
+	assert.Equal(t, "/range/5BAA6", gotPath, "only the hash prefix is sent")
+	assert.Equal(t, "true", gotPadding)
+
+	breached, err = c.Breached(context.Background(), "correct horse battery staple 42")
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestBreached_PaddingEntryIsNotABreach(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("1E4C9B93F3F0682250B6CF8331B7EE68FD8:0\n"))
+	}))
+	defer srv.Close()
+
+	breached, err := NewClient(srv.URL).Breached(context.Background(), "password")
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestBreached_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL).Breached(context.Background(), "password")
+	assert.ErrorContains(t, err, "status 503")
+}
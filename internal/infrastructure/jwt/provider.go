@@ -1,13 +1,17 @@
 package jwtinfra
 
 import (
+	"context"
 	"crypto/rsa"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/tenant"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -17,9 +21,41 @@ type Claims struct {
 	DeviceID  string `json:"device_id"`
 	Role      string `json:"role"`
 	SessionID string `json:"session_id"`
+	Tenant    string `json:"tenant,omitempty"` // set when TENANT_MODE is on
+	// Scopes are what the token may do: those of the role for a user's own
+	// token, those granted to the client for an OAuth access token.
+	Scopes   []string `json:"scopes"`
+	ClientID string   `json:"client_id,omitempty"` // set on access tokens issued to OAuth clients
 	jwt.RegisteredClaims
 }
 
+// Delegated reports whether c was issued to an OAuth client rather than to
+// the user directly.
+func (c *Claims) Delegated() bool {
+	return c.ClientID != ""
+}
+
+// Scoped reports whether c is limited to its scopes. Tokens signed before
+// the scopes claim existed carry none and act with their role alone.
+func (c *Claims) Scoped() bool {
+	return c.Scopes != nil || c.Delegated()
+}
+
+// HasScope reports whether scope is among c's scopes.
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// Delegation describes an access token issued to an OAuth client on behalf
+// of a user.
+type Delegation struct {
+	UserID    string
+	Role      string
+	SessionID string
+	ClientID  string
+	Scopes    []string
+}
+
 // Provider signs and verifies RS256 JWTs.
 type Provider struct {
 	privateKey *rsa.PrivateKey
@@ -49,12 +85,17 @@ func NewProvider(cfg *config.Config) (*Provider, error) {
 	return &Provider{privateKey: privKey, publicKey: pubKey, expiry: cfg.JWTExpiry}, nil
 }
 
-func (p *Provider) Sign(userID, deviceID, role, sessionID string) (string, error) {
+// Sign issues a token for the session, carrying the scopes of role. The
+// tenant ctx acts for, if any, is carried in the tenant claim.
+func (p *Provider) Sign(ctx context.Context, userID, deviceID, role, sessionID string) (string, error) {
+	tenantID, _ := tenant.FromContext(ctx)
 	claims := Claims{
 		UserID:    userID,
 		DeviceID:  deviceID,
 		Role:      role,
 		SessionID: sessionID,
+		Tenant:    tenantID,
+		Scopes:    domain.RoleScopes(role),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(p.expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -64,6 +105,25 @@ func (p *Provider) Sign(userID, deviceID, role, sessionID string) (string, error
 	return token.SignedString(p.privateKey)
 }
 
+// SignDelegated issues an OAuth access token for d.
+func (p *Provider) SignDelegated(ctx context.Context, d Delegation) (string, error) {
+	tenantID, _ := tenant.FromContext(ctx)
+	claims := Claims{
+		UserID:    d.UserID,
+		Role:      d.Role,
+		SessionID: d.SessionID,
+		Tenant:    tenantID,
+		ClientID:  d.ClientID,
+		Scopes:    d.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   d.UserID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(p.expiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(p.privateKey)
+}
+
 func (p *Provider) Verify(tokenStr string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/go-api-nosql/internal/infrastructure/repotest"
+	transporthttp "github.com/go-api-nosql/internal/transport/http"
+)
+
+var (
+	_ transporthttp.UserRepository    = (*UserRepo)(nil)
+	_ transporthttp.SessionRepository = (*SessionRepo)(nil)
+)
+
+func TestUserRepoContract(t *testing.T) {
+	repotest.Users(t, NewUserRepo(false))
+}
+
+func TestSessionRepoContract(t *testing.T) {
+	repotest.Sessions(t, NewSessionRepo())
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// SessionRepo is an in-memory sessions table with dynamo.SessionRepo's
+// semantics: soft-deleted sessions are disabled, not removed.
+type SessionRepo struct {
+	sessions *table
+}
+
+func NewSessionRepo() *SessionRepo {
+	return &SessionRepo{sessions: newTable("session_id")}
+}
+
+func (r *SessionRepo) Put(ctx context.Context, s *domain.Session) error {
+	return r.sessions.put(s)
+}
+
+func (r *SessionRepo) Get(ctx context.Context, sessionID string) (*domain.Session, error) {
+	var s domain.Session
+	found, err := r.sessions.get(sessionID, &s)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("session not found: %w", domain.ErrNotFound)
+	}
+	return &s, nil
+}
+
+// GetByRefreshToken returns ErrUnauthorized when the session is disabled.
+func (r *SessionRepo) GetByRefreshToken(ctx context.Context, token string) (*domain.Session, error) {
+	sessions, err := all[domain.Session](r.sessions)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		if s.RefreshToken != token {
+			continue
+		}
+		if !s.Enable {
+			return nil, fmt.Errorf("session disabled: %w", domain.ErrUnauthorized)
+		}
+		return &s, nil
+	}
+	return nil, fmt.Errorf("session not found: %w", domain.ErrNotFound)
+}
+
+func (r *SessionRepo) RotateRefreshToken(ctx context.Context, sessionID, newToken string, newExpiry int64) error {
+	return r.Update(ctx, sessionID, map[string]interface{}{
+		"refresh_token":      newToken,
+		"refresh_expires_at": newExpiry,
+	})
+}
+
+func (r *SessionRepo) Update(ctx context.Context, sessionID string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	return r.sessions.update(sessionID, updates)
+}
+
+// RedeemAuthCode returns domain.ErrConflict unless the session holds code.
+func (r *SessionRepo) RedeemAuthCode(ctx context.Context, sessionID, code string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	ok, err := r.sessions.updateIf(sessionID, "auth_code", code, updates)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("authorization code already used: %w", domain.ErrConflict)
+	}
+	return nil
+}
+
+func (r *SessionRepo) SoftDeleteByUser(ctx context.Context, userID string) error {
+	sessions, err := all[domain.Session](r.sessions)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s.UserID != userID {
+			continue
+		}
+		if err := r.Update(ctx, s.SessionID, map[string]interface{}{"enable": false}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListActiveByUser returns the user's enabled sessions whose refresh token has
+// not expired.
+func (r *SessionRepo) ListActiveByUser(ctx context.Context, userID string) ([]domain.Session, error) {
+	sessions, err := all[domain.Session](r.sessions)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	active := []domain.Session{}
+	for _, s := range sessions {
+		if s.UserID == userID && s.Enable && s.RefreshExpiresAt > now {
+			active = append(active, s)
+		}
+	}
+	return active, nil
+}
@@ -0,0 +1,208 @@
+// Package memory provides in-process repositories with the same semantics as
+// the DynamoDB ones, for tests and the repository contract suite. Items are
+// kept in DynamoDB's attribute form, so update maps marshal exactly as they do
+// in an UpdateItem SET expression.
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// table is a map of items keyed by the string attribute key.
+type table struct {
+	key string
+
+	mu    sync.Mutex
+	items map[string]map[string]types.AttributeValue
+}
+
+func newTable(key string) *table {
+	return &table{key: key, items: make(map[string]map[string]types.AttributeValue)}
+}
+
+// put stores v like the DynamoDB repos' conditional put: re-putting an
+// identical item succeeds, a different item under the same key is
+// domain.ErrConflict.
+func (t *table) put(v any) error {
+	return t.write(v, true)
+}
+
+// replace stores v, overwriting any item with the same key.
+func (t *table) replace(v any) error {
+	return t.write(v, false)
+}
+
+func (t *table) write(v any, ifNew bool) error {
+	item, err := attributevalue.MarshalMap(v)
+	if err != nil {
+		return fmt.Errorf("marshal item: %w", err)
+	}
+	id, ok := item[t.key].(*types.AttributeValueMemberS)
+	if !ok {
+		return fmt.Errorf("item has no %s", t.key)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if old, ok := t.items[id.Value]; ok && ifNew && !reflect.DeepEqual(old, item) {
+		return fmt.Errorf("item already exists: %w", domain.ErrConflict)
+	}
+	t.items[id.Value] = item
+	return nil
+}
+
+// get unmarshals the item with id into out and reports whether it exists.
+func (t *table) get(id string, out any) (bool, error) {
+	t.mu.Lock()
+	item, ok := t.items[id]
+	t.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, attributevalue.UnmarshalMap(item, out)
+}
+
+// update sets each attribute like a SET expression. As with UpdateItem, a
+// missing item is created holding only its key and the updates.
+func (t *table) update(id string, updates map[string]interface{}) error {
+	values, err := marshalUpdates(updates)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	item, ok := t.items[id]
+	if !ok {
+		item = map[string]types.AttributeValue{t.key: &types.AttributeValueMemberS{Value: id}}
+		t.items[id] = item
+	}
+	for k, av := range values {
+		item[k] = av
+	}
+	return nil
+}
+
+// updateIf is update conditioned on the item's string attribute attr being
+// want, and reports whether the condition held.
+func (t *table) updateIf(id, attr, want string, updates map[string]interface{}) (bool, error) {
+	values, err := marshalUpdates(updates)
+	if err != nil {
+		return false, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	item, ok := t.items[id]
+	if !ok {
+		return false, nil
+	}
+	if got, _ := item[attr].(*types.AttributeValueMemberS); got == nil || got.Value != want {
+		return false, nil
+	}
+	for k, av := range values {
+		item[k] = av
+	}
+	return true, nil
+}
+
+func marshalUpdates(updates map[string]interface{}) (map[string]types.AttributeValue, error) {
+	if len(updates) == 0 {
+		return nil, fmt.Errorf("no fields to update")
+	}
+	values := make(map[string]types.AttributeValue, len(updates))
+	for k, v := range updates {
+		av, err := attributevalue.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("marshal field %s: %w", k, err)
+		}
+		values[k] = av
+	}
+	return values, nil
+}
+
+// addToSet adds value to the string set attr of the item with id, like an
+// ADD expression conditioned on the item existing and the set lacking
+// value, and reports whether it was added.
+func (t *table) addToSet(id, attr, value string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	item, ok := t.items[id]
+	if !ok {
+		return false
+	}
+	set, _ := item[attr].(*types.AttributeValueMemberSS)
+	if set != nil && slices.Contains(set.Value, value) {
+		return false
+	}
+	values := []string{value}
+	if set != nil {
+		values = append(slices.Clone(set.Value), value)
+	}
+	item[attr] = &types.AttributeValueMemberSS{Value: values}
+	return true
+}
+
+// setUnless sets the string attr of the item with id to value, like a SET
+// conditioned on the item existing and attr differing from value, and
+// reports whether it was set.
+func (t *table) setUnless(id, attr, value string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	item, ok := t.items[id]
+	if !ok {
+		return false
+	}
+	if got, _ := item[attr].(*types.AttributeValueMemberS); got != nil && got.Value == value {
+		return false
+	}
+	item[attr] = &types.AttributeValueMemberS{Value: value}
+	return true
+}
+
+// removeFromSet deletes value from the string set attr of the item with id,
+// dropping the attribute once the set is empty as DynamoDB does.
+func (t *table) removeFromSet(id, attr, value string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	item, ok := t.items[id]
+	if !ok {
+		return
+	}
+	set, _ := item[attr].(*types.AttributeValueMemberSS)
+	if set == nil {
+		return
+	}
+	values := slices.DeleteFunc(slices.Clone(set.Value), func(v string) bool { return v == value })
+	if len(values) == 0 {
+		delete(item, attr)
+		return
+	}
+	item[attr] = &types.AttributeValueMemberSS{Value: values}
+}
+
+// remove deletes the item with id, if any.
+func (t *table) remove(id string) {
+	t.mu.Lock()
+	delete(t.items, id)
+	t.mu.Unlock()
+}
+
+// all unmarshals every item in the table, in no particular order.
+func all[T any](t *table) ([]T, error) {
+	t.mu.Lock()
+	items := make([]map[string]types.AttributeValue, 0, len(t.items))
+	for _, item := range t.items {
+		items = append(items, item)
+	}
+	t.mu.Unlock()
+	out := make([]T, 0, len(items))
+	if err := attributevalue.UnmarshalListOfMaps(items, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
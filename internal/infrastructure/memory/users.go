@@ -0,0 +1,288 @@
+package memory
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// UserRepo is an in-memory users table. Like dynamo.UserRepo, email and
+// username lookups are case-insensitive and soft-deleted users are hidden
+// from Get and QueryPage only.
+type UserRepo struct {
+	users     *table
+	foldGmail bool
+}
+
+func NewUserRepo(foldGmail bool) *UserRepo {
+	return &UserRepo{users: newTable("user_id"), foldGmail: foldGmail}
+}
+
+func (r *UserRepo) Put(ctx context.Context, u *domain.User) error {
+	u.EmailKey = domain.NormalizeEmail(u.Email, r.foldGmail)
+	u.UsernameKey = domain.NormalizeUsername(u.Username)
+	return r.users.put(u)
+}
+
+// BatchPut stores users, overwriting any user with the same ID.
+func (r *UserRepo) BatchPut(ctx context.Context, users []domain.User) error {
+	for i := range users {
+		u := &users[i]
+		u.EmailKey = domain.NormalizeEmail(u.Email, r.foldGmail)
+		u.UsernameKey = domain.NormalizeUsername(u.Username)
+		if err := r.users.replace(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchDelete permanently removes the users in userIDs.
+func (r *UserRepo) BatchDelete(ctx context.Context, userIDs []string) error {
+	for _, id := range userIDs {
+		r.users.remove(id)
+	}
+	return nil
+}
+
+func (r *UserRepo) Get(ctx context.Context, userID string) (*domain.User, error) {
+	var u domain.User
+	found, err := r.users.get(userID, &u)
+	if err != nil {
+		return nil, err
+	}
+	if !found || u.DeletedAt != nil {
+		return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
+	}
+	return &u, nil
+}
+
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	key := domain.NormalizeUsername(username)
+	return r.find(func(u domain.User) bool { return u.UsernameKey == key })
+}
+
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	key := domain.NormalizeEmail(email, r.foldGmail)
+	return r.find(func(u domain.User) bool { return u.EmailKey == key })
+}
+
+func (r *UserRepo) find(match func(domain.User) bool) (*domain.User, error) {
+	users, err := all[domain.User](r.users)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if match(u) {
+			return &u, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found: %w", domain.ErrNotFound)
+}
+
+func (r *UserRepo) Update(ctx context.Context, userID string, updates map[string]interface{}) error {
+	if email, ok := updates["email"].(string); ok {
+		updates["email_key"] = domain.NormalizeEmail(email, r.foldGmail)
+	}
+	if username, ok := updates["username"].(string); ok {
+		updates["username_key"] = domain.NormalizeUsername(username)
+	}
+	updates["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+	return r.users.update(userID, updates)
+}
+
+// ClaimOnboarding adds step to the user's onboarding_sent set and reports
+// whether this call added it.
+func (r *UserRepo) ClaimOnboarding(ctx context.Context, userID, step string) (bool, error) {
+	return r.users.addToSet(userID, "onboarding_sent", step), nil
+}
+
+// ReleaseOnboarding removes step from the user's onboarding_sent set.
+func (r *UserRepo) ReleaseOnboarding(ctx context.Context, userID, step string) error {
+	r.users.removeFromSet(userID, "onboarding_sent", step)
+	return nil
+}
+
+// ClaimBirthday sets the user's birthday_greeted to year and reports whether
+// this call changed it.
+func (r *UserRepo) ClaimBirthday(ctx context.Context, userID, year string) (bool, error) {
+	return r.users.setUnless(userID, "birthday_greeted", year), nil
+}
+
+func (r *UserRepo) SoftDelete(ctx context.Context, userID string) error {
+	return r.Update(ctx, userID, map[string]interface{}{
+		"enable":     0,
+		"deleted_at": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// SweepBefore hard-deletes users soft-deleted before cutoff, or only counts
+// them when dryRun is set.
+func (r *UserRepo) SweepBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error) {
+	users, err := all[domain.User](r.users)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, u := range users {
+		if u.DeletedAt == nil || !u.DeletedAt.Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			r.users.remove(u.UserID)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// AnonymizeBefore scrubs the personal data of users soft-deleted before
+// cutoff, or only counts them when dryRun is set.
+func (r *UserRepo) AnonymizeBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error) {
+	users, err := all[domain.User](r.users)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, u := range users {
+		if u.DeletedAt == nil || !u.DeletedAt.Before(cutoff) || u.AnonymizedAt != nil {
+			continue
+		}
+		n++
+		if dryRun {
+			continue
+		}
+		now := time.Now().UTC()
+		tombstone := domain.TombstoneEmail(domain.NormalizeEmail(u.Email, r.foldGmail))
+		u.Email, u.EmailKey = tombstone, tombstone
+		u.Username, u.UsernameKey = "deleted-"+u.UserID, domain.NormalizeUsername("deleted-"+u.UserID)
+		u.FirstName, u.LastName, u.Phone, u.Birthday = "", "", nil, time.Time{}
+		u.GoogleSub, u.PasswordHash, u.AnonymizedAt = "", "", &now
+		if err := r.users.replace(&u); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// CountByRole returns how many enabled users have role.
+func (r *UserRepo) CountByRole(ctx context.Context, role string) (int, error) {
+	enabled := 1
+	users, err := r.matching(domain.UserFilter{Role: role, Enable: &enabled})
+	return len(users), err
+}
+
+// ApproxCount returns the exact item count, deleted users included.
+func (r *UserRepo) ApproxCount(ctx context.Context) (int64, error) {
+	users, err := all[domain.User](r.users)
+	return int64(len(users)), err
+}
+
+// EachMatching calls fn for every user matching f, including deleted ones.
+func (r *UserRepo) EachMatching(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error {
+	users, err := r.matching(f)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryPage returns a page of non-deleted users matching f, newest first
+// unless f.Ascending; f.Enable defaults to 1. The cursor is an offset into
+// the ordered result, so it is only stable while the table is unchanged.
+func (r *UserRepo) QueryPage(ctx context.Context, f domain.UserFilter, limit int32, cursor string) ([]domain.User, string, error) {
+	offset, err := decodeOffset(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if f.Enable == nil {
+		enabled := 1
+		f.Enable = &enabled
+	}
+	users, err := r.matching(f)
+	if err != nil {
+		return nil, "", err
+	}
+	users = slices.DeleteFunc(users, func(u domain.User) bool { return u.DeletedAt != nil })
+	slices.SortFunc(users, func(a, b domain.User) int {
+		if f.Ascending {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if offset >= len(users) {
+		return []domain.User{}, "", nil
+	}
+	end := min(offset+int(limit), len(users))
+	next := ""
+	if end < len(users) {
+		next = encodeOffset(end)
+	}
+	return users[offset:end], next, nil
+}
+
+// CountMatching counts the users QueryPage lists for f.
+func (r *UserRepo) CountMatching(ctx context.Context, f domain.UserFilter) (int, error) {
+	if f.Enable == nil {
+		enabled := 1
+		f.Enable = &enabled
+	}
+	users, err := r.matching(f)
+	if err != nil {
+		return 0, err
+	}
+	users = slices.DeleteFunc(users, func(u domain.User) bool { return u.DeletedAt != nil })
+	return len(users), nil
+}
+
+// matching returns the users matching every filter set in f, deleted ones
+// included. f's dates must already be validated.
+func (r *UserRepo) matching(f domain.UserFilter) ([]domain.User, error) {
+	users, err := all[domain.User](r.users)
+	if err != nil {
+		return nil, err
+	}
+	var to string
+	if f.CreatedTo != "" {
+		// created_at is RFC 3339, so "< next day" is an inclusive day bound.
+		day, _ := time.Parse("2006-01-02", f.CreatedTo)
+		to = day.AddDate(0, 0, 1).Format("2006-01-02")
+	}
+	return slices.DeleteFunc(users, func(u domain.User) bool {
+		created := u.CreatedAt.Format(time.RFC3339Nano)
+		return (f.Role != "" && u.Role != f.Role) ||
+			(f.Enable != nil && u.Enable != *f.Enable) ||
+			(f.EmailConfirmed != nil && u.EmailConfirmed != *f.EmailConfirmed) ||
+			(f.CreatedFrom != "" && created < f.CreatedFrom) ||
+			(to != "" && created >= to)
+	}), nil
+}
+
+func encodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeOffset(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor: %w", domain.ErrBadRequest)
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor: %w", domain.ErrBadRequest)
+	}
+	return offset, nil
+}
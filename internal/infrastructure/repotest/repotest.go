@@ -0,0 +1,85 @@
+// Package repotest is the conformance suite every repository backend must
+// pass: not-found semantics, soft deletes, pagination and update maps.
+//
+// Backends run it from their own tests, e.g.
+//
+//	func TestUserRepoContract(t *testing.T) {
+//		repotest.Users(t, NewUserRepo(false))
+//	}
+//
+// The repository may already hold data (a shared DynamoDB table), so every
+// case works on fresh IDs and its own role and never assumes an empty store.
+package repotest
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+)
+
+// UserRepository is the part of a user store the suite exercises.
+type UserRepository interface {
+	Put(ctx context.Context, u *domain.User) error
+	Get(ctx context.Context, userID string) (*domain.User, error)
+	GetByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	Update(ctx context.Context, userID string, updates map[string]interface{}) error
+	SoftDelete(ctx context.Context, userID string) error
+	SweepBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error)
+	AnonymizeBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error)
+	QueryPage(ctx context.Context, f domain.UserFilter, limit int32, cursor string) ([]domain.User, string, error)
+	CountMatching(ctx context.Context, f domain.UserFilter) (int, error)
+	BatchPut(ctx context.Context, users []domain.User) error
+	BatchDelete(ctx context.Context, userIDs []string) error
+	ClaimOnboarding(ctx context.Context, userID, step string) (bool, error)
+	ReleaseOnboarding(ctx context.Context, userID, step string) error
+	ClaimBirthday(ctx context.Context, userID, year string) (bool, error)
+}
+
+// SessionRepository is the part of a session store the suite exercises.
+type SessionRepository interface {
+	Put(ctx context.Context, s *domain.Session) error
+	Get(ctx context.Context, sessionID string) (*domain.Session, error)
+	GetByRefreshToken(ctx context.Context, token string) (*domain.Session, error)
+	RotateRefreshToken(ctx context.Context, sessionID, newToken string, newExpiry int64) error
+	Update(ctx context.Context, sessionID string, updates map[string]interface{}) error
+	SoftDeleteByUser(ctx context.Context, userID string) error
+	ListActiveByUser(ctx context.Context, userID string) ([]domain.Session, error)
+	RedeemAuthCode(ctx context.Context, sessionID, code string, updates map[string]interface{}) error
+}
+
+// newUser returns an enabled user with unique identifiers, created at created.
+func newUser(role string, created time.Time) *domain.User {
+	uid := id.New()
+	return &domain.User{
+		UserID:    uid,
+		Username:  "repotest-" + uid,
+		Email:     "repotest-" + uid + "@example.com",
+		Role:      role,
+		FirstName: "Repo",
+		LastName:  "Test",
+		Enable:    1,
+		CreatedAt: created.UTC(),
+	}
+}
+
+func newSession(userID string, refreshExpires time.Time) *domain.Session {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &domain.Session{
+		SessionID:        id.New(),
+		UserID:           userID,
+		DeviceID:         id.New(),
+		Enable:           true,
+		RefreshToken:     id.New(),
+		RefreshExpiresAt: refreshExpires.Unix(),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// uniqueRole isolates a case's users in the role index.
+func uniqueRole() string {
+	return "repotest-" + id.New()
+}
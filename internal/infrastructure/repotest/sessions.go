@@ -0,0 +1,143 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/id"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Sessions runs the session repository contract against repo.
+func Sessions(t *testing.T, repo SessionRepository) {
+	t.Run("missing session is not found", func(t *testing.T) { sessionsNotFound(t, repo) })
+	t.Run("put is idempotent and never overwrites", func(t *testing.T) { sessionsPutNew(t, repo) })
+	t.Run("refresh token rotation", func(t *testing.T) { sessionsRotate(t, repo) })
+	t.Run("update sets fields and keeps the rest", func(t *testing.T) { sessionsUpdate(t, repo) })
+	t.Run("soft delete by user disables only their sessions", func(t *testing.T) { sessionsSoftDelete(t, repo) })
+	t.Run("active list skips expired sessions", func(t *testing.T) { sessionsActive(t, repo) })
+	t.Run("authorization code is redeemed once", func(t *testing.T) { sessionsRedeemCode(t, repo) })
+}
+
+func sessionsNotFound(t *testing.T, repo SessionRepository) {
+	ctx := context.Background()
+
+	_, err := repo.Get(ctx, id.New())
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "Get: %v", err)
+	_, err = repo.GetByRefreshToken(ctx, id.New())
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "GetByRefreshToken: %v", err)
+	active, err := repo.ListActiveByUser(ctx, id.New())
+	require.NoError(t, err)
+	assert.NotNil(t, active, "an empty list, not nil")
+	assert.Empty(t, active)
+}
+
+func sessionsPutNew(t *testing.T, repo SessionRepository) {
+	ctx := context.Background()
+	s := newSession(id.New(), time.Now().Add(time.Hour))
+	require.NoError(t, repo.Put(ctx, s))
+
+	require.NoError(t, repo.Put(ctx, s), "resending the same session")
+	other := *s
+	other.RefreshToken = id.New()
+	err := repo.Put(ctx, &other)
+	assert.True(t, errors.Is(err, domain.ErrConflict), "different session, same ID: %v", err)
+
+	got, err := repo.Get(ctx, s.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, s.RefreshToken, got.RefreshToken)
+}
+
+func sessionsRotate(t *testing.T, repo SessionRepository) {
+	ctx := context.Background()
+	s := newSession(id.New(), time.Now().Add(time.Hour))
+	require.NoError(t, repo.Put(ctx, s))
+	newToken, newExpiry := id.New(), time.Now().Add(2*time.Hour).Unix()
+
+	require.NoError(t, repo.RotateRefreshToken(ctx, s.SessionID, newToken, newExpiry))
+
+	got, err := repo.GetByRefreshToken(ctx, newToken)
+	require.NoError(t, err)
+	assert.Equal(t, s.SessionID, got.SessionID)
+	assert.Equal(t, newExpiry, got.RefreshExpiresAt)
+	_, err = repo.GetByRefreshToken(ctx, s.RefreshToken)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "old token: %v", err)
+}
+
+func sessionsUpdate(t *testing.T, repo SessionRepository) {
+	ctx := context.Background()
+	s := newSession(id.New(), time.Now().Add(time.Hour))
+	require.NoError(t, repo.Put(ctx, s))
+
+	require.NoError(t, repo.Update(ctx, s.SessionID, map[string]interface{}{"device_id": "replaced"}))
+
+	got, err := repo.Get(ctx, s.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "replaced", got.DeviceID)
+	assert.Equal(t, s.UserID, got.UserID, "fields not in the update are kept")
+	assert.True(t, got.Enable)
+}
+
+func sessionsSoftDelete(t *testing.T, repo SessionRepository) {
+	ctx := context.Background()
+	userID := id.New()
+	mine := []*domain.Session{newSession(userID, time.Now().Add(time.Hour)), newSession(userID, time.Now().Add(time.Hour))}
+	other := newSession(id.New(), time.Now().Add(time.Hour))
+	for _, s := range append(mine, other) {
+		require.NoError(t, repo.Put(ctx, s))
+	}
+
+	require.NoError(t, repo.SoftDeleteByUser(ctx, userID))
+
+	for _, s := range mine {
+		got, err := repo.Get(ctx, s.SessionID)
+		require.NoError(t, err, "soft-deleted sessions are kept")
+		assert.False(t, got.Enable)
+		_, err = repo.GetByRefreshToken(ctx, s.RefreshToken)
+		assert.True(t, errors.Is(err, domain.ErrUnauthorized), "GetByRefreshToken: %v", err)
+	}
+	active, err := repo.ListActiveByUser(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	got, err := repo.GetByRefreshToken(ctx, other.RefreshToken)
+	require.NoError(t, err, "other users' sessions are untouched")
+	assert.True(t, got.Enable)
+}
+
+func sessionsActive(t *testing.T, repo SessionRepository) {
+	ctx := context.Background()
+	userID := id.New()
+	live := newSession(userID, time.Now().Add(time.Hour))
+	expired := newSession(userID, time.Now().Add(-time.Minute))
+	require.NoError(t, repo.Put(ctx, live))
+	require.NoError(t, repo.Put(ctx, expired))
+
+	active, err := repo.ListActiveByUser(ctx, userID)
+
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, live.SessionID, active[0].SessionID)
+}
+
+func sessionsRedeemCode(t *testing.T, repo SessionRepository) {
+	ctx := context.Background()
+	s := newSession(id.New(), time.Now().Add(time.Hour))
+	s.Enable, s.AuthCode = false, id.New()
+	require.NoError(t, repo.Put(ctx, s))
+	redeem := func(code string) error {
+		return repo.RedeemAuthCode(ctx, s.SessionID, code, map[string]interface{}{"enable": true, "auth_code": ""})
+	}
+
+	assert.True(t, errors.Is(redeem(id.New()), domain.ErrConflict), "wrong code")
+	require.NoError(t, redeem(s.AuthCode))
+	assert.True(t, errors.Is(redeem(s.AuthCode), domain.ErrConflict), "second redemption")
+
+	got, err := repo.Get(ctx, s.SessionID)
+	require.NoError(t, err)
+	assert.True(t, got.Enable)
+	assert.Empty(t, got.AuthCode)
+}
@@ -0,0 +1,317 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Users runs the user repository contract against repo.
+func Users(t *testing.T, repo UserRepository) {
+	t.Run("missing user is not found", func(t *testing.T) { usersNotFound(t, repo) })
+	t.Run("lookups ignore case", func(t *testing.T) { usersLookups(t, repo) })
+	t.Run("update sets fields and keeps the rest", func(t *testing.T) { usersUpdate(t, repo) })
+	t.Run("soft delete hides the user", func(t *testing.T) { usersSoftDelete(t, repo) })
+	t.Run("put is idempotent and never overwrites", func(t *testing.T) { usersPutNew(t, repo) })
+	t.Run("sweep removes only users deleted before the cutoff", func(t *testing.T) { usersSweep(t, repo) })
+	t.Run("anonymize scrubs deleted users and keeps their IDs", func(t *testing.T) { usersAnonymize(t, repo) })
+	t.Run("query pages newest first", func(t *testing.T) { usersPagination(t, repo) })
+	t.Run("query applies filters", func(t *testing.T) { usersFilters(t, repo) })
+	t.Run("malformed cursor is a bad request", func(t *testing.T) { usersBadCursor(t, repo) })
+	t.Run("batch put and delete span several batches", func(t *testing.T) { usersBatch(t, repo) })
+	t.Run("onboarding emails are claimed once", func(t *testing.T) { usersOnboardingClaim(t, repo) })
+	t.Run("birthdays are claimed once a year", func(t *testing.T) { usersBirthdayClaim(t, repo) })
+}
+
+func usersNotFound(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	u := newUser(uniqueRole(), time.Now())
+
+	_, err := repo.Get(ctx, u.UserID)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "Get: %v", err)
+	_, err = repo.GetByUsername(ctx, u.Username)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "GetByUsername: %v", err)
+	_, err = repo.GetByEmail(ctx, u.Email)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "GetByEmail: %v", err)
+}
+
+func usersLookups(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	u := newUser(uniqueRole(), time.Now())
+	require.NoError(t, repo.Put(ctx, u))
+
+	got, err := repo.GetByUsername(ctx, strings.ToUpper(u.Username))
+	require.NoError(t, err)
+	assert.Equal(t, u.UserID, got.UserID)
+
+	got, err = repo.GetByEmail(ctx, " "+strings.ToUpper(u.Email))
+	require.NoError(t, err)
+	assert.Equal(t, u.UserID, got.UserID)
+}
+
+func usersUpdate(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	u := newUser(uniqueRole(), time.Now())
+	require.NoError(t, repo.Put(ctx, u))
+	newEmail := "Renamed-" + u.Email
+
+	require.NoError(t, repo.Update(ctx, u.UserID, map[string]interface{}{
+		"first_name":      "Updated",
+		"email":           newEmail,
+		"email_confirmed": true,
+	}))
+
+	got, err := repo.Get(ctx, u.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", got.FirstName)
+	assert.True(t, got.EmailConfirmed)
+	assert.Equal(t, u.LastName, got.LastName, "fields not in the update are kept")
+	assert.False(t, got.UpdatedAt.IsZero(), "updated_at is set")
+
+	byEmail, err := repo.GetByEmail(ctx, strings.ToLower(newEmail))
+	require.NoError(t, err, "the email lookup key follows the new email")
+	assert.Equal(t, u.UserID, byEmail.UserID)
+}
+
+func usersSoftDelete(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	role := uniqueRole()
+	u := newUser(role, time.Now())
+	require.NoError(t, repo.Put(ctx, u))
+
+	require.NoError(t, repo.SoftDelete(ctx, u.UserID))
+
+	_, err := repo.Get(ctx, u.UserID)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "Get: %v", err)
+	for _, enable := range []int{0, 1} {
+		users := allPages(t, repo, domain.UserFilter{Role: role, Enable: &enable}, 10)
+		assert.Empty(t, users, "QueryPage with enable=%d", enable)
+	}
+}
+
+func usersPutNew(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	u := newUser(uniqueRole(), time.Now())
+	phone := "+15550100"
+	u.Phone = &phone
+	require.NoError(t, repo.Put(ctx, u))
+
+	require.NoError(t, repo.Put(ctx, u), "resending the same user")
+	other := *u
+	other.FirstName = "Other"
+	err := repo.Put(ctx, &other)
+	assert.True(t, errors.Is(err, domain.ErrConflict), "different user, same ID: %v", err)
+
+	got, err := repo.Get(ctx, u.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, "Repo", got.FirstName)
+}
+
+func usersOnboardingClaim(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	u := newUser(uniqueRole(), time.Now())
+	require.NoError(t, repo.Put(ctx, u))
+
+	won, err := repo.ClaimOnboarding(ctx, u.UserID, domain.OnboardingWelcome)
+	require.NoError(t, err)
+	assert.True(t, won)
+	won, err = repo.ClaimOnboarding(ctx, u.UserID, domain.OnboardingWelcome)
+	require.NoError(t, err)
+	assert.False(t, won, "a second claim loses")
+	won, err = repo.ClaimOnboarding(ctx, "missing-"+u.UserID, domain.OnboardingWelcome)
+	require.NoError(t, err)
+	assert.False(t, won, "no claim on a missing user")
+
+	got, err := repo.Get(ctx, u.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.OnboardingWelcome}, got.OnboardingSent)
+
+	require.NoError(t, repo.ReleaseOnboarding(ctx, u.UserID, domain.OnboardingWelcome))
+	won, err = repo.ClaimOnboarding(ctx, u.UserID, domain.OnboardingWelcome)
+	require.NoError(t, err)
+	assert.True(t, won, "a released step can be claimed again")
+}
+
+func usersSweep(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	deleted := newUser(uniqueRole(), time.Now())
+	live := newUser(uniqueRole(), time.Now())
+	require.NoError(t, repo.Put(ctx, deleted))
+	require.NoError(t, repo.Put(ctx, live))
+	require.NoError(t, repo.SoftDelete(ctx, deleted.UserID))
+	cutoff := time.Now().Add(time.Minute)
+
+	n, err := repo.SweepBefore(ctx, cutoff, true)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1, "dry run counts the deleted user")
+	_, err = repo.GetByEmail(ctx, deleted.Email)
+	require.NoError(t, err, "dry run keeps the deleted user")
+
+	_, err = repo.SweepBefore(ctx, cutoff, false)
+	require.NoError(t, err)
+	_, err = repo.GetByEmail(ctx, deleted.Email)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "GetByEmail after sweep: %v", err)
+	_, err = repo.Get(ctx, live.UserID)
+	assert.NoError(t, err, "users that are not deleted are kept")
+}
+
+func usersAnonymize(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	u := newUser(uniqueRole(), time.Now())
+	phone := "+15550000000"
+	u.Phone, u.Birthday = &phone, time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Put(ctx, u))
+	require.NoError(t, repo.SoftDelete(ctx, u.UserID))
+	cutoff := time.Now().Add(time.Minute)
+
+	_, err := repo.AnonymizeBefore(ctx, cutoff, false)
+	require.NoError(t, err)
+
+	_, err = repo.GetByEmail(ctx, u.Email)
+	assert.True(t, errors.Is(err, domain.ErrNotFound), "the old email is free again: %v", err)
+	got, err := repo.GetByEmail(ctx, domain.TombstoneEmail(domain.NormalizeEmail(u.Email, false)))
+	require.NoError(t, err, "the item stays under its user ID")
+	assert.Equal(t, u.UserID, got.UserID)
+	assert.Empty(t, got.FirstName)
+	assert.Nil(t, got.Phone)
+	assert.True(t, got.Birthday.IsZero())
+	assert.NotNil(t, got.AnonymizedAt)
+	assert.NotContains(t, got.Username, u.Username)
+
+	n, err := repo.AnonymizeBefore(ctx, cutoff, true)
+	require.NoError(t, err)
+	assert.Zero(t, n, "anonymized users are not counted again")
+}
+
+func usersPagination(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	role := uniqueRole()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var want []string
+	for i := range 5 {
+		u := newUser(role, base.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, repo.Put(ctx, u))
+		want = append([]string{u.UserID}, want...)
+	}
+
+	assert.Equal(t, want, userIDs(allPages(t, repo, domain.UserFilter{Role: role}, 2)))
+
+	slices.Reverse(want)
+	assert.Equal(t, want, userIDs(allPages(t, repo, domain.UserFilter{Role: role, Ascending: true}, 2)))
+}
+
+func usersFilters(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	role := uniqueRole()
+	old := newUser(role, time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC))
+	confirmed := newUser(role, time.Date(2020, 1, 20, 12, 0, 0, 0, time.UTC))
+	confirmed.EmailConfirmed = true
+	disabled := newUser(role, time.Date(2020, 1, 20, 13, 0, 0, 0, time.UTC))
+	disabled.Enable = 0
+	for _, u := range []*domain.User{old, confirmed, disabled} {
+		require.NoError(t, repo.Put(ctx, u))
+	}
+	yes, off := true, 0
+
+	assert.Equal(t, []string{confirmed.UserID, old.UserID},
+		userIDs(allPages(t, repo, domain.UserFilter{Role: role}, 10)), "enable defaults to 1")
+	assert.Equal(t, []string{disabled.UserID},
+		userIDs(allPages(t, repo, domain.UserFilter{Role: role, Enable: &off}, 10)))
+	assert.Equal(t, []string{confirmed.UserID},
+		userIDs(allPages(t, repo, domain.UserFilter{Role: role, EmailConfirmed: &yes}, 10)))
+	assert.Equal(t, []string{confirmed.UserID},
+		userIDs(allPages(t, repo, domain.UserFilter{Role: role, CreatedFrom: "2020-01-15", CreatedTo: "2020-01-20"}, 10)),
+		"the to date is inclusive")
+
+	for _, f := range []domain.UserFilter{{Role: role}, {Role: role, Enable: &off}, {Role: role, EmailConfirmed: &yes}} {
+		n, err := repo.CountMatching(ctx, f)
+		require.NoError(t, err)
+		assert.Equal(t, len(allPages(t, repo, f, 10)), n, "CountMatching agrees with QueryPage")
+	}
+}
+
+func usersBadCursor(t *testing.T, repo UserRepository) {
+	_, _, err := repo.QueryPage(context.Background(), domain.UserFilter{Role: uniqueRole()}, 10, "not-a-cursor")
+
+	assert.True(t, errors.Is(err, domain.ErrBadRequest), "QueryPage: %v", err)
+}
+
+// allPages follows cursors until the last page. A page may hold fewer than
+// limit users, so only an empty cursor ends the listing.
+func allPages(t *testing.T, repo UserRepository, f domain.UserFilter, limit int32) []domain.User {
+	t.Helper()
+	var users []domain.User
+	cursor := ""
+	for range 100 {
+		page, next, err := repo.QueryPage(context.Background(), f, limit, cursor)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), int(limit))
+		users = append(users, page...)
+		if next == "" {
+			return users
+		}
+		cursor = next
+	}
+	t.Fatal("QueryPage never returned an empty cursor")
+	return nil
+}
+
+func userIDs(users []domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.UserID)
+	}
+	return ids
+}
+
+func usersBatch(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	role := uniqueRole()
+	users := make([]domain.User, 30) // more than one BatchWriteItem call
+	ids := make([]string, len(users))
+	for i := range users {
+		users[i] = *newUser(role, time.Now())
+		ids[i] = users[i].UserID
+	}
+	require.NoError(t, repo.BatchPut(ctx, users))
+
+	got, err := repo.GetByEmail(ctx, strings.ToUpper(users[29].Email))
+	require.NoError(t, err, "batch puts set the lookup keys")
+	assert.Equal(t, ids[29], got.UserID)
+
+	require.NoError(t, repo.BatchDelete(ctx, ids))
+	for _, id := range []string{ids[0], ids[29]} {
+		_, err := repo.Get(ctx, id)
+		assert.True(t, errors.Is(err, domain.ErrNotFound), "Get after BatchDelete: %v", err)
+	}
+}
+
+func usersBirthdayClaim(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	u := newUser(uniqueRole(), time.Now())
+	require.NoError(t, repo.Put(ctx, u))
+
+	won, err := repo.ClaimBirthday(ctx, u.UserID, "2026")
+	require.NoError(t, err)
+	assert.True(t, won)
+	won, err = repo.ClaimBirthday(ctx, u.UserID, "2026")
+	require.NoError(t, err)
+	assert.False(t, won, "a second claim in the same year loses")
+	won, err = repo.ClaimBirthday(ctx, u.UserID, "2027")
+	require.NoError(t, err)
+	assert.True(t, won, "next year's birthday can be claimed")
+	won, err = repo.ClaimBirthday(ctx, "missing-"+u.UserID, "2026")
+	require.NoError(t, err)
+	assert.False(t, won, "no claim on a missing user")
+
+	got, err := repo.Get(ctx, u.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, "2027", got.BirthdayGreeted)
+}
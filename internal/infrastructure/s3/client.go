@@ -17,11 +17,13 @@ import (
 type Store struct {
 	client *s3.Client
 	bucket string
+	prefix string // S3_KEY_PREFIX, prepended to every key
 }
 
 // NewClient creates an S3 client. When cfg.AWSEndpointURL is set (LocalStack),
-// it overrides the endpoint and enables path-style addressing.
-func NewClient(cfg *config.Config) *s3.Client {
+// it overrides the endpoint and enables path-style addressing. optFns are
+// applied after it.
+func NewClient(cfg *config.Config, optFns ...func(*s3.Options)) (*s3.Client, error) {
 	opts := []func(*awsconfig.LoadOptions) error{
 		awsconfig.WithRegion(cfg.AWSRegion),
 	}
@@ -34,7 +36,7 @@ func NewClient(cfg *config.Config) *s3.Client {
 
 	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
 	if err != nil {
-		panic("failed to load AWS config for S3: " + err.Error())
+		return nil, fmt.Errorf("load AWS config for S3: %w", err)
 	}
 
 	clientOpts := []func(*s3.Options){}
@@ -45,33 +47,35 @@ func NewClient(cfg *config.Config) *s3.Client {
 		})
 	}
 
-	return s3.NewFromConfig(awsCfg, clientOpts...)
+	return s3.NewFromConfig(awsCfg, append(clientOpts, optFns...)...), nil
 }
 
-// NewStore creates a Store with the given S3 client and bucket name.
-func NewStore(client *s3.Client, bucket string) *Store {
-	return &Store{client: client, bucket: bucket}
+// NewStore creates a Store with the given S3 client and bucket name. prefix
+// namespaces the objects, so environments can share a bucket; callers keep
+// passing unprefixed keys.
+func NewStore(client *s3.Client, bucket, prefix string) *Store {
+	return &Store{client: client, bucket: bucket, prefix: prefix}
 }
 
 // Upload streams a file to S3 under key and returns the object URL.
 func (s *Store) Upload(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
 	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucket),
-		Key:         aws.String(key),
+		Key:         aws.String(s.prefix + key),
 		Body:        r,
 		ContentType: aws.String(contentType),
 	})
 	if err != nil {
 		return "", fmt.Errorf("s3 put object: %w", err)
 	}
-	return fmt.Sprintf("s3://%s/%s", s.bucket, key), nil
+	return fmt.Sprintf("s3://%s/%s%s", s.bucket, s.prefix, key), nil
 }
 
 // Download retrieves a file from S3 and returns its stream.
 func (s *Store) Download(ctx context.Context, key string) (io.ReadCloser, error) {
 	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
+		Key:    aws.String(s.prefix + key),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("s3 get object: %w", err)
@@ -84,7 +88,7 @@ func (s *Store) PresignedURL(ctx context.Context, key string, ttl time.Duration)
 	presigner := s3.NewPresignClient(s.client)
 	req, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
+		Key:    aws.String(s.prefix + key),
 	}, s3.WithPresignExpires(ttl))
 	if err != nil {
 		return "", fmt.Errorf("presign get object: %w", err)
@@ -96,7 +100,7 @@ func (s *Store) PresignedURL(ctx context.Context, key string, ttl time.Duration)
 func (s *Store) Delete(ctx context.Context, key string) error {
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
+		Key:    aws.String(s.prefix + key),
 	})
 	return err
 }
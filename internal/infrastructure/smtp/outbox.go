@@ -0,0 +1,59 @@
+package smtp
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// outboxSize is how many messages an Outbox keeps.
+const outboxSize = 50
+
+// Email is a message captured by an Outbox.
+type Email struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Outbox is a development Mailer that keeps the most recent messages in memory
+// for the dev console and forwards each one to next (e.g. MailHog). A failed
+// forward is only logged, so flows such as OTP keep working without an SMTP
+// server.
+type Outbox struct {
+	next Mailer
+
+	mu       sync.Mutex
+	messages []Email // oldest first
+}
+
+func NewOutbox(next Mailer) *Outbox {
+	return &Outbox{next: next}
+}
+
+func (o *Outbox) SendEmail(to, subject, body string) error {
+	o.mu.Lock()
+	o.messages = append(o.messages, Email{To: to, Subject: subject, Body: body, SentAt: time.Now().UTC()})
+	if len(o.messages) > outboxSize {
+		o.messages = o.messages[len(o.messages)-outboxSize:]
+	}
+	o.mu.Unlock()
+	if o.next != nil {
+		if err := o.next.SendEmail(to, subject, body); err != nil {
+			slog.Warn("dev outbox: forward failed; message is still captured", "to", to, "err", err)
+		}
+	}
+	return nil
+}
+
+// Messages returns the captured messages, newest first.
+func (o *Outbox) Messages() []Email {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := make([]Email, len(o.messages))
+	for i, m := range o.messages {
+		out[len(out)-1-i] = m
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package smtp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingMailer struct{ calls int }
+
+func (m *failingMailer) SendEmail(to, subject, body string) error {
+	m.calls++
+	return errors.New("connection refused")
+}
+
+func TestOutbox_CapturesNewestFirstAndForwards(t *testing.T) {
+	next := &failingMailer{}
+	o := NewOutbox(next)
+
+	require.NoError(t, o.SendEmail("a@example.com", "first", "1"))
+	require.NoError(t, o.SendEmail("b@example.com", "second", "2"))
+
+	msgs := o.Messages()
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "second", msgs[0].Subject)
+	assert.Equal(t, "a@example.com", msgs[1].To)
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestOutbox_KeepsOnlyRecentMessages(t *testing.T) {
+	o := NewOutbox(nil)
+	for i := 0; i < outboxSize+5; i++ {
+		require.NoError(t, o.SendEmail("a@example.com", fmt.Sprint(i), ""))
+	}
+
+	msgs := o.Messages()
+	require.Len(t, msgs, outboxSize)
+	assert.Equal(t, fmt.Sprint(outboxSize+4), msgs[0].Subject)
+}
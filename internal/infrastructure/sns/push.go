@@ -0,0 +1,67 @@
+package sns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// PushSender delivers mobile push notifications via an SNS platform application.
+type PushSender interface {
+	SendPush(ctx context.Context, token, message string) error
+}
+
+type pushSender struct {
+	client *sns.Client
+	appARN string
+}
+
+func NewPushSender(cfg *config.Config) (PushSender, error) {
+	if cfg.SNSPlatformAppARN == "" {
+		return nil, errors.New("SNS_PLATFORM_APPLICATION_ARN is not set")
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+		awsconfig.WithRegion(cfg.SNSRegion),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pushSender{client: sns.NewFromConfig(awsCfg), appARN: cfg.SNSPlatformAppARN}, nil
+}
+
+// SendPush registers token with the platform application (idempotent for an
+// existing token) and publishes message to the resulting endpoint. Provider
+// feedback that the token is dead is reported as domain.ErrInvalidPushToken.
+func (s *pushSender) SendPush(ctx context.Context, token, message string) error {
+	ep, err := s.client.CreatePlatformEndpoint(ctx, &sns.CreatePlatformEndpointInput{
+		PlatformApplicationArn: &s.appARN,
+		Token:                  &token,
+	})
+	if err != nil {
+		return classifyPushError(err)
+	}
+	_, err = s.client.Publish(ctx, &sns.PublishInput{
+		TargetArn: ep.EndpointArn,
+		Message:   &message,
+	})
+	return classifyPushError(err)
+}
+
+func classifyPushError(err error) error {
+	var disabled *types.EndpointDisabledException
+	var invalid *types.InvalidParameterException
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &disabled), errors.As(err, &invalid):
+		return fmt.Errorf("%s: %w", err.Error(), domain.ErrInvalidPushToken)
+	default:
+		return err
+	}
+}
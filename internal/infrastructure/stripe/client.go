@@ -0,0 +1,78 @@
+// Package stripe verifies Stripe webhook deliveries and opens customer-portal
+// sessions through the REST API, without the Stripe SDK.
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.stripe.com"
+
+// Client holds the API key used for REST calls and the signing secret of the
+// webhook endpoint.
+type Client struct {
+	secretKey     string
+	webhookSecret string
+	baseURL       string
+	http          *http.Client
+	now           func() time.Time
+}
+
+func NewClient(secretKey, webhookSecret string) *Client {
+	return &Client{
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+		baseURL:       defaultBaseURL,
+		http:          &http.Client{Timeout: 10 * time.Second},
+		now:           time.Now,
+	}
+}
+
+// PortalURL opens a customer-portal session for customerID and returns its
+// URL. The portal sends the customer back to returnURL when they leave.
+func (c *Client) PortalURL(ctx context.Context, customerID, returnURL string) (string, error) {
+	form := url.Values{"customer": {customerID}}
+	if returnURL != "" {
+		form.Set("return_url", returnURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/billing_portal/sessions",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("stripe: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return "", &StatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+	var out struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("stripe: decode portal session: %w", err)
+	}
+	return out.URL, nil
+}
+
+// StatusError is returned for non-2xx API responses.
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("stripe: status %d: %s", e.Code, e.Body)
+}
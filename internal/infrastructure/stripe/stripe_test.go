@@ -0,0 +1,91 @@
+package stripe
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "whsec_test"
+
+func sign(payload string, ts int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", ts, payload)
+	return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func testClient(now time.Time) *Client {
+	c := NewClient("sk_test", testSecret)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+const subscriptionUpdated = `{"id":"evt_1","type":"customer.subscription.updated","created":1772366400,
+"data":{"object":{"customer":"cus_1","status":"active","metadata":{"user_id":"u1"},
+"items":{"data":[{"price":{"id":"price_pro"}}]}}}}`
+
+func TestParseSubscriptionEvent_DecodesSignedEvent(t *testing.T) {
+	now := time.Unix(1772366400, 0)
+
+	ev, err := testClient(now).ParseSubscriptionEvent([]byte(subscriptionUpdated), sign(subscriptionUpdated, now.Unix(), testSecret))
+
+	require.NoError(t, err)
+	assert.Equal(t, &domain.SubscriptionEvent{
+		EventID: "evt_1", UserID: "u1", CustomerID: "cus_1", Status: "active", PriceID: "price_pro",
+		Created: now.UTC(),
+	}, ev)
+}
+
+func TestParseSubscriptionEvent_RejectsBadSignatures(t *testing.T) {
+	now := time.Unix(1772366400, 0)
+	c := testClient(now)
+
+	for name, header := range map[string]string{
+		"wrong secret": sign(subscriptionUpdated, now.Unix(), "whsec_other"),
+		"too old":      sign(subscriptionUpdated, now.Add(-10*time.Minute).Unix(), testSecret),
+		"missing":      "",
+		"no v1":        fmt.Sprintf("t=%d", now.Unix()),
+	} {
+		_, err := c.ParseSubscriptionEvent([]byte(subscriptionUpdated), header)
+		assert.ErrorIs(t, err, domain.ErrBadRequest, name)
+	}
+}
+
+func TestParseSubscriptionEvent_IgnoresOtherEvents(t *testing.T) {
+	now := time.Unix(1772366400, 0)
+	payload := `{"id":"evt_2","type":"invoice.paid","created":1772366400,"data":{"object":{}}}`
+
+	ev, err := testClient(now).ParseSubscriptionEvent([]byte(payload), sign(payload, now.Unix(), testSecret))
+
+	require.NoError(t, err)
+	assert.Nil(t, ev)
+}
+
+func TestPortalURL_PostsCustomerAndReturnURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/billing_portal/sessions", r.URL.Path)
+		assert.Equal(t, "Bearer sk_test", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "cus_1", r.PostForm.Get("customer"))
+		assert.Equal(t, "https://app.example.com/account", r.PostForm.Get("return_url"))
+		_, _ = w.Write([]byte(`{"url":"https://billing.stripe.com/p/session/x"}`))
+	}))
+	defer srv.Close()
+	c := NewClient("sk_test", testSecret)
+	c.baseURL = srv.URL
+
+	got, err := c.PortalURL(context.Background(), "cus_1", "https://app.example.com/account")
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://billing.stripe.com/p/session/x", got)
+}
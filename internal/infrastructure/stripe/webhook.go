@@ -0,0 +1,117 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// signatureTolerance is how old a signed webhook timestamp may be, which
+// bounds how long a captured delivery can be replayed.
+const signatureTolerance = 5 * time.Minute
+
+// Subscription event types; every other event type is ignored.
+const (
+	eventSubscriptionCreated = "customer.subscription.created"
+	eventSubscriptionUpdated = "customer.subscription.updated"
+	eventSubscriptionDeleted = "customer.subscription.deleted"
+)
+
+type event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+type subscription struct {
+	Customer string            `json:"customer"`
+	Status   string            `json:"status"`
+	Metadata map[string]string `json:"metadata"`
+	Items    struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// ParseSubscriptionEvent verifies the Stripe-Signature header of a webhook
+// delivery and decodes it. It returns nil without error for events that are
+// not about subscriptions, and a domain.ErrBadRequest error for deliveries
+// that are unsigned, forged or too old.
+func (c *Client) ParseSubscriptionEvent(payload []byte, header string) (*domain.SubscriptionEvent, error) {
+	if err := verify(payload, header, c.webhookSecret, c.now()); err != nil {
+		return nil, err
+	}
+	var ev event
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("decode stripe event: %w", domain.ErrBadRequest)
+	}
+	switch ev.Type {
+	case eventSubscriptionCreated, eventSubscriptionUpdated, eventSubscriptionDeleted:
+	default:
+		return nil, nil
+	}
+	var sub subscription
+	if err := json.Unmarshal(ev.Data.Object, &sub); err != nil {
+		return nil, fmt.Errorf("decode stripe subscription: %w", domain.ErrBadRequest)
+	}
+	out := &domain.SubscriptionEvent{
+		EventID:    ev.ID,
+		UserID:     sub.Metadata["user_id"],
+		TenantID:   sub.Metadata["tenant_id"],
+		CustomerID: sub.Customer,
+		Status:     sub.Status,
+		Ended:      ev.Type == eventSubscriptionDeleted,
+		Created:    time.Unix(ev.Created, 0).UTC(),
+	}
+	if len(sub.Items.Data) > 0 {
+		out.PriceID = sub.Items.Data[0].Price.ID
+	}
+	return out, nil
+}
+
+// verify checks header, "t=<unix>,v1=<hex hmac>[,v1=...]", against the
+// HMAC-SHA256 of "<t>.<payload>" under secret. Any matching v1 signature is
+// accepted so deliveries keep working while the secret is rolled.
+func verify(payload []byte, header, secret string, now time.Time) error {
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return fmt.Errorf("malformed stripe signature: %w", domain.ErrBadRequest)
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return fmt.Errorf("stripe signature timestamp outside tolerance: %w", domain.ErrBadRequest)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(payload)
+	want := mac.Sum(nil)
+	for _, s := range sigs {
+		if got, err := hex.DecodeString(s); err == nil && hmac.Equal(got, want) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid stripe signature: %w", domain.ErrBadRequest)
+}
@@ -0,0 +1,51 @@
+// Package accesslog writes one JSON line per HTTP request to a sink kept
+// apart from the application log: stdout, a rotating file or CloudWatch Logs
+// (see infrastructure/cloudwatch), chosen with ACCESS_LOG_SINK.
+package accesslog
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Entry is one served request.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	DurationMS int64     `json:"duration_ms"`
+	RemoteAddr string    `json:"remote_addr"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// Logger serialises entries to its sink, one Write per line.
+type Logger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// New returns a Logger writing to w.
+func New(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+// Log writes e. A failing sink is reported through slog; it never fails the
+// request being logged.
+func (l *Logger) Log(e Entry) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	l.mu.Lock()
+	_, err = l.w.Write(line)
+	l.mu.Unlock()
+	if err != nil {
+		slog.Warn("access log write failed", "error", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package chaos holds the fault-injection rules used to exercise client
+// retries and error handling outside production. The HTTP middleware and the
+// AWS SDK clients consult the same Controller, so faults can target incoming
+// requests or a single dependency.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault targets.
+const (
+	TargetHTTP     = "http"
+	TargetDynamoDB = "dynamodb"
+	TargetS3       = "s3"
+)
+
+// ErrInjected is returned by dependency calls failed on purpose.
+var ErrInjected = errors.New("chaos: injected failure")
+
+// Rule delays and/or fails a share of the calls it matches.
+type Rule struct {
+	Target string `json:"target" validate:"required,oneof=http dynamodb s3"`
+	// Match is a request path prefix for http and an operation name (e.g.
+	// "GetItem", "PutObject") for dependencies; empty matches everything.
+	Match     string  `json:"match,omitempty" validate:"max=256"`
+	Percent   float64 `json:"percent" validate:"gt=0,lte=100"`
+	LatencyMS int     `json:"latency_ms,omitempty" validate:"gte=0,lte=30000"`
+	Fail      bool    `json:"fail,omitempty"`
+	// Status is the HTTP status of a failed http request (default 503).
+	Status int `json:"status,omitempty" validate:"omitempty,gte=400,lte=599"`
+}
+
+func (r Rule) matches(target, name string) bool {
+	if r.Target != target {
+		return false
+	}
+	if target == TargetHTTP {
+		return strings.HasPrefix(name, r.Match)
+	}
+	return r.Match == "" || r.Match == name
+}
+
+// Apply waits out the rule's latency and returns ErrInjected when it fails
+// the call. It returns ctx's error if ctx ends first.
+func (r Rule) Apply(ctx context.Context) error {
+	if r.LatencyMS > 0 {
+		t := time.NewTimer(time.Duration(r.LatencyMS) * time.Millisecond)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	if r.Fail {
+		return ErrInjected
+	}
+	return nil
+}
+
+// Controller holds the active rules. The zero value injects nothing.
+type Controller struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// Rules returns a copy of the active rules.
+func (c *Controller) Rules() []Rule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Rule{}, c.rules...)
+}
+
+// Set replaces the active rules; nil clears them.
+func (c *Controller) Set(rules []Rule) {
+	c.mu.Lock()
+	c.rules = append([]Rule(nil), rules...)
+	c.mu.Unlock()
+}
+
+// Pick returns the first rule matching target and name if the call falls in
+// its percentage. Only the first matching rule is rolled.
+func (c *Controller) Pick(target, name string) (Rule, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range c.rules {
+		if r.matches(target, name) {
+			return r, rand.Float64()*100 < r.Percent
+		}
+	}
+	return Rule{}, false
+}
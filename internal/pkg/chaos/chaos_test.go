@@ -0,0 +1,62 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPick_MatchesTargetAndName(t *testing.T) {
+	c := NewController()
+	c.Set([]Rule{
+		{Target: TargetHTTP, Match: "/v1/files", Percent: 100, Fail: true},
+		{Target: TargetDynamoDB, Match: "GetItem", Percent: 100, LatencyMS: 5},
+	})
+
+	_, ok := c.Pick(TargetHTTP, "/v1/files/s3/1")
+	assert.True(t, ok)
+	_, ok = c.Pick(TargetHTTP, "/v1/users")
+	assert.False(t, ok)
+	r, ok := c.Pick(TargetDynamoDB, "GetItem")
+	assert.True(t, ok)
+	assert.Equal(t, 5, r.LatencyMS)
+	_, ok = c.Pick(TargetDynamoDB, "PutItem")
+	assert.False(t, ok)
+	_, ok = c.Pick(TargetS3, "GetObject")
+	assert.False(t, ok)
+}
+
+func TestPick_RespectsPercent(t *testing.T) {
+	c := NewController()
+	c.Set([]Rule{{Target: TargetS3, Percent: 50, Fail: true}})
+
+	hits := 0
+	for range 2000 {
+		if _, ok := c.Pick(TargetS3, "GetObject"); ok {
+			hits++
+		}
+	}
+
+	assert.InDelta(t, 1000, hits, 150)
+}
+
+func TestApply(t *testing.T) {
+	assert.ErrorIs(t, Rule{Fail: true}.Apply(context.Background()), ErrInjected)
+	assert.NoError(t, Rule{LatencyMS: 1}.Apply(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, Rule{LatencyMS: 10_000, Fail: true}.Apply(ctx), context.DeadlineExceeded)
+}
+
+func TestSet_NilClears(t *testing.T) {
+	c := NewController()
+	c.Set([]Rule{{Target: TargetHTTP, Percent: 100}})
+	c.Set(nil)
+
+	assert.Empty(t, c.Rules())
+	_, ok := c.Pick(TargetHTTP, "/")
+	assert.False(t, ok)
+}
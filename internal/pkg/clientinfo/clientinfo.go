@@ -0,0 +1,29 @@
+// Package clientinfo carries what a request tells about the client that sent
+// it. The HTTP middleware reads it from the request headers, and the session
+// service stores it on the sessions it opens and refreshes.
+package clientinfo
+
+import "context"
+
+// Info describes the client behind a request. Every field is
+// client-supplied and only fit for display.
+type Info struct {
+	UserAgent  string
+	AppVersion string // X-App-Version, sent by the mobile apps
+	IP         string
+	Country    string // upper-case ISO 3166-1 alpha-2 code from the CDN or gateway; empty when unknown
+}
+
+type contextKey struct{}
+
+// WithInfo returns a copy of ctx carrying info.
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the Info ctx carries, or the zero Info for requests the
+// middleware did not see and for background jobs.
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
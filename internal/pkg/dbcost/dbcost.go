@@ -0,0 +1,147 @@
+// Package dbcost accounts for the DynamoDB capacity each request consumes.
+// The DynamoDB client adds every call to the Meter in the request context,
+// and the HTTP middleware adds the request's total to a Ledger kept per
+// route, so expensive access patterns stand out.
+package dbcost
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Call is the capacity one DynamoDB call consumed.
+type Call struct {
+	Write   bool               // a write operation; otherwise a read
+	Scan    bool               // a Scan, which reads the whole table or index
+	Units   float64            // capacity units consumed in total
+	Sources map[string]float64 // units by "table" or "table/index"
+}
+
+// Usage is the capacity consumed by one request, or by all requests to a
+// route.
+type Usage struct {
+	Calls      int                `json:"calls"`
+	Scans      int                `json:"scans"`
+	ReadUnits  float64            `json:"read_units"`
+	WriteUnits float64            `json:"write_units"`
+	Sources    map[string]float64 `json:"sources,omitempty"`
+}
+
+func (u *Usage) add(o Usage) {
+	u.Calls += o.Calls
+	u.Scans += o.Scans
+	u.ReadUnits += o.ReadUnits
+	u.WriteUnits += o.WriteUnits
+	for src, units := range o.Sources {
+		if u.Sources == nil {
+			u.Sources = make(map[string]float64)
+		}
+		u.Sources[src] += units
+	}
+}
+
+// Meter sums the calls made while serving one request. Handlers may call
+// DynamoDB from several goroutines, so it is safe for concurrent use.
+type Meter struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// Add records c.
+func (m *Meter) Add(c Call) {
+	u := Usage{Calls: 1, Sources: c.Sources}
+	if c.Scan {
+		u.Scans = 1
+	}
+	if c.Write {
+		u.WriteUnits = c.Units
+	} else {
+		u.ReadUnits = c.Units
+	}
+	m.mu.Lock()
+	m.usage.add(u)
+	m.mu.Unlock()
+}
+
+// Usage returns what has been recorded so far.
+func (m *Meter) Usage() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var u Usage
+	u.add(m.usage)
+	return u
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying a new Meter.
+func NewContext(ctx context.Context) (context.Context, *Meter) {
+	m := &Meter{}
+	return context.WithValue(ctx, contextKey{}, m), m
+}
+
+// FromContext returns the Meter in ctx, or nil outside a metered request.
+func FromContext(ctx context.Context) *Meter {
+	m, _ := ctx.Value(contextKey{}).(*Meter)
+	return m
+}
+
+// RouteCost is the capacity consumed by every request to one route since the
+// instance started or the ledger was reset.
+type RouteCost struct {
+	Route    string `json:"route"` // method and route pattern, e.g. "GET /v1/users"
+	Requests int    `json:"requests"`
+	Usage
+}
+
+// Ledger aggregates request usage per route. It is kept in memory, so each
+// replica reports its own traffic.
+type Ledger struct {
+	mu     sync.Mutex
+	routes map[string]*RouteCost
+}
+
+func NewLedger() *Ledger {
+	return &Ledger{routes: make(map[string]*RouteCost)}
+}
+
+// Record adds one request to route.
+func (l *Ledger) Record(route string, u Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rc, ok := l.routes[route]
+	if !ok {
+		rc = &RouteCost{Route: route}
+		l.routes[route] = rc
+	}
+	rc.Requests++
+	rc.add(u)
+}
+
+// Snapshot returns every route, most capacity consumed first.
+func (l *Ledger) Snapshot() []RouteCost {
+	l.mu.Lock()
+	out := make([]RouteCost, 0, len(l.routes))
+	for _, rc := range l.routes {
+		c := RouteCost{Route: rc.Route, Requests: rc.Requests}
+		c.add(rc.Usage)
+		out = append(out, c)
+	}
+	l.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		ti, tj := out[i].ReadUnits+out[i].WriteUnits, out[j].ReadUnits+out[j].WriteUnits
+		if ti != tj {
+			return ti > tj
+		}
+		return out[i].Route < out[j].Route
+	})
+	return out
+}
+
+// Reset forgets every route.
+func (l *Ledger) Reset() {
+	l.mu.Lock()
+	l.routes = make(map[string]*RouteCost)
+	l.mu.Unlock()
+}
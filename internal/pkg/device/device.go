@@ -10,15 +10,26 @@ import (
 )
 
 type deviceStorer interface {
-	GetByUUID(ctx context.Context, uuid string) (*domain.Device, error)
+	GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error)
 	Put(ctx context.Context, d *domain.Device) error
 }
 
-// Resolve returns the existing Device for deviceUUID when found, otherwise
-// creates a new one associated with userID and persists it.
+// RefreshLifetime returns the refresh-token lifetime for a session on d.
+// Untrusted devices get the shorter untrusted duration when one is configured.
+func RefreshLifetime(d *domain.Device, trusted, untrusted time.Duration) time.Duration {
+	if d != nil && !d.Trusted && untrusted > 0 {
+		return untrusted
+	}
+	return trusted
+}
+
+// Resolve returns userID's enabled Device for deviceUUID when found, otherwise
+// creates a new one associated with userID and persists it. A UUID already
+// registered by another account gets its own row, so accounts sharing a
+// handset never share a device record or its trust.
 func Resolve(ctx context.Context, repo deviceStorer, deviceUUID *string, userID string) (*domain.Device, error) {
 	if deviceUUID != nil {
-		d, err := repo.GetByUUID(ctx, *deviceUUID)
+		d, err := repo.GetByUUID(ctx, *deviceUUID, userID)
 		if err == nil {
 			return d, nil
 		}
@@ -0,0 +1,56 @@
+// Package jobs runs periodic background work inside the API process.
+//
+// Every replica runs every job, so jobs must be safe to run concurrently —
+// typically by claiming work with a conditional write.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
+)
+
+// Job is a unit of periodic work.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start launches each job on its own ticker until ctx is cancelled, through
+// the lifecycle.Manager carried by ctx if any. A run in progress at shutdown
+// is allowed to finish (see lifecycle.InFlight). Errors are logged and the
+// job runs again on the next tick. Jobs with a non-positive interval are
+// skipped.
+func Start(ctx context.Context, jobs ...Job) {
+	for _, j := range jobs {
+		if j.Interval <= 0 {
+			slog.Warn("job disabled: interval must be positive", "job", j.Name)
+			continue
+		}
+		lifecycle.Go(ctx, "job "+j.Name, func(ctx context.Context) { loop(ctx, j) })
+	}
+}
+
+func loop(ctx context.Context, j Job) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run(ctx, j)
+		}
+	}
+}
+
+func run(ctx context.Context, j Job) {
+	ctx, cancel := lifecycle.InFlight(ctx)
+	defer cancel()
+	if err := j.Run(ctx); err != nil {
+		slog.Error("job failed", "job", j.Name, "err", err)
+	}
+}
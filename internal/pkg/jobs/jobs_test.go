@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart_RunsUntilCancelled(t *testing.T) {
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	Start(ctx, Job{Name: "count", Interval: time.Millisecond, Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+	time.Sleep(5 * time.Millisecond)
+	stopped := runs.Load()
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+}
+
+func TestStart_ShutdownLetsRunFinish(t *testing.T) {
+	m := lifecycle.New(context.Background())
+	started := make(chan struct{})
+	var finished atomic.Bool
+	Start(m.Context(), Job{Name: "sweep", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+		finished.Store(ctx.Err() == nil)
+		return nil
+	}})
+	<-started
+
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.True(t, finished.Load(), "the run in progress completes with a live context")
+}
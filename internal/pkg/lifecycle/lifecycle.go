@@ -0,0 +1,137 @@
+// Package lifecycle starts the API's background goroutines — cache cleanups,
+// periodic jobs, queue consumers — and stops them together on shutdown.
+//
+// A Manager travels in the context its Context method returns, so code that
+// is handed that context starts goroutines with Go and needs no extra
+// parameter. Without a Manager in the context, Go falls back to a plain
+// goroutine bounded by the context alone.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+type managerKey struct{}
+
+// Manager tracks background goroutines so Shutdown can stop them and wait
+// for the work they have in flight.
+type Manager struct {
+	ctx    context.Context // cancelled when Shutdown starts
+	cancel context.CancelFunc
+	hard   context.Context // cancelled when Shutdown stops waiting
+	abort  context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	stopped bool
+	running map[string]int // task name -> goroutines still running
+}
+
+// New returns a Manager whose tasks run until parent is cancelled or
+// Shutdown is called.
+func New(parent context.Context) *Manager {
+	m := &Manager{running: make(map[string]int)}
+	m.hard, m.abort = context.WithCancel(parent)
+	m.ctx, m.cancel = context.WithCancel(m.hard)
+	m.ctx = context.WithValue(m.ctx, managerKey{}, m)
+	return m
+}
+
+// Context returns the context tasks run under. It carries m for Go.
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Go runs fn in a new goroutine with m's context. fn must return soon after
+// the context is cancelled. Tasks started after Shutdown are not run.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.start(m.ctx, name, fn)
+}
+
+func (m *Manager) start(ctx context.Context, name string, fn func(ctx context.Context)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return
+	}
+	m.running[name]++
+	m.wg.Add(1)
+	go func() {
+		defer m.done(name)
+		fn(ctx)
+	}()
+}
+
+func (m *Manager) done(name string) {
+	m.mu.Lock()
+	if m.running[name]--; m.running[name] == 0 {
+		delete(m.running, name)
+	}
+	m.mu.Unlock()
+	m.wg.Done()
+}
+
+// Shutdown cancels the tasks' context and waits for them to return. Work
+// running under InFlight keeps its context until ctx ends; then Shutdown
+// cancels it too and returns an error naming the tasks still running.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopped = true
+	m.mu.Unlock()
+	m.cancel()
+	finished := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		m.abort()
+		return nil
+	case <-ctx.Done():
+		m.abort()
+		return fmt.Errorf("background tasks still running: %v: %w", m.pending(), ctx.Err())
+	}
+}
+
+func (m *Manager) pending() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.running))
+	for name := range m.running {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Go runs fn in a new goroutine with ctx, tracked by the Manager carried by
+// ctx if there is one.
+func Go(ctx context.Context, name string, fn func(ctx context.Context)) {
+	if m, ok := ctx.Value(managerKey{}).(*Manager); ok {
+		m.start(ctx, name, fn)
+		return
+	}
+	go fn(ctx)
+}
+
+// InFlight returns a context for one unit of work that should finish even
+// though shutdown has started, such as a job run that already began. It
+// ignores the cancellation of ctx and ends when the Manager carried by ctx
+// stops waiting. Without a Manager it returns ctx. Call cancel when the
+// work is done.
+func InFlight(ctx context.Context) (context.Context, context.CancelFunc) {
+	m, ok := ctx.Value(managerKey{}).(*Manager)
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	work, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(m.hard, cancel)
+	return work, func() {
+		stop()
+		cancel()
+	}
+}
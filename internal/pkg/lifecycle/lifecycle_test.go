@@ -0,0 +1,85 @@
+package lifecycle
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown_WaitsForTasks(t *testing.T) {
+	m := New(context.Background())
+	var finished atomic.Bool
+	Go(m.Context(), "cleanup", func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(5 * time.Millisecond)
+		finished.Store(true)
+	})
+
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.True(t, finished.Load())
+}
+
+func TestShutdown_NamesTasksStillRunning(t *testing.T) {
+	m := New(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	m.Go("stuck", func(context.Context) { <-release })
+	m.Go("quick", func(ctx context.Context) { <-ctx.Done() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := m.Shutdown(ctx)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "[stuck]")
+}
+
+func TestInFlight_OutlivesStopUntilShutdownGivesUp(t *testing.T) {
+	m := New(context.Background())
+	work := make(chan context.Context, 1)
+	m.Go("job", func(ctx context.Context) {
+		<-ctx.Done()
+		run, cancel := InFlight(ctx)
+		defer cancel()
+		work <- run
+		<-run.Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	go func() { _ = m.Shutdown(ctx) }()
+
+	run := <-work
+	assert.NoError(t, run.Err(), "in-flight work keeps running after the stop signal")
+	select {
+	case <-run.Done():
+	case <-time.After(time.Second):
+		t.Fatal("in-flight work was not cancelled at the shutdown deadline")
+	}
+}
+
+func TestGo_AfterShutdownDoesNotRun(t *testing.T) {
+	m := New(context.Background())
+	require.NoError(t, m.Shutdown(context.Background()))
+
+	var ran atomic.Bool
+	Go(m.Context(), "late", func(context.Context) { ran.Store(true) })
+
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.False(t, ran.Load())
+}
+
+func TestGo_WithoutManager(t *testing.T) {
+	done := make(chan struct{})
+	Go(context.Background(), "plain", func(context.Context) { close(done) })
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task did not run")
+	}
+}
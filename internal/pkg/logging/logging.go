@@ -0,0 +1,112 @@
+// Package logging builds the process-wide slog handler: level, JSON or text
+// output, sampling of debug records and redaction of personal data and
+// credentials.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Options configures New.
+type Options struct {
+	Level       slog.Level
+	Format      string // "json" or "text"
+	DebugSample int    // keep one in DebugSample debug records of each message; 1 or less keeps all
+	Redact      bool   // mask emails and drop credentials, see redact
+}
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", s)
+	}
+	return l, nil
+}
+
+// New returns a logger writing to w.
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	ho := &slog.HandlerOptions{Level: opts.Level}
+	if opts.Redact {
+		ho.ReplaceAttr = redact
+	}
+	var h slog.Handler
+	switch opts.Format {
+	case "json":
+		h = slog.NewJSONHandler(w, ho)
+	case "text":
+		h = slog.NewTextHandler(w, ho)
+	default:
+		return nil, fmt.Errorf("LOG_FORMAT must be json or text, got %q", opts.Format)
+	}
+	if opts.DebugSample > 1 {
+		h = &sampler{Handler: h, every: uint64(opts.DebugSample), counts: &sync.Map{}}
+	}
+	return slog.New(h), nil
+}
+
+// sampler passes the first debug record of each message and every nth after
+// it, so a debug line in a hot path cannot flood the output while rare ones
+// still show. Records at info and above always pass.
+type sampler struct {
+	slog.Handler
+	every  uint64
+	counts *sync.Map // message -> *atomic.Uint64
+}
+
+func (s *sampler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelInfo {
+		c, _ := s.counts.LoadOrStore(r.Message, new(atomic.Uint64))
+		if (c.(*atomic.Uint64).Add(1)-1)%s.every != 0 {
+			return nil
+		}
+	}
+	return s.Handler.Handle(ctx, r)
+}
+
+func (s *sampler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampler{Handler: s.Handler.WithAttrs(attrs), every: s.every, counts: s.counts}
+}
+
+func (s *sampler) WithGroup(name string) slog.Handler {
+	return &sampler{Handler: s.Handler.WithGroup(name), every: s.every, counts: s.counts}
+}
+
+// secretKeys are attribute key fragments whose values are never logged.
+var secretKeys = []string{"password", "token", "secret", "authorization", "cookie", "otp"}
+
+var emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+// redact drops credentials by key and masks email addresses anywhere in
+// string values, errors and the message, keeping the first letter and the
+// domain for debugging: jane@example.com becomes j***@example.com.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return slog.String(a.Key, "[REDACTED]")
+		}
+	}
+	switch v := a.Value.Any().(type) {
+	case string:
+		if strings.Contains(v, "@") {
+			return slog.String(a.Key, maskEmails(v))
+		}
+	case error:
+		if msg := v.Error(); strings.Contains(msg, "@") {
+			return slog.String(a.Key, maskEmails(msg))
+		}
+	}
+	return a
+}
+
+func maskEmails(s string) string {
+	return emailPattern.ReplaceAllString(s, "$1***@$2")
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_RedactsEmailsAndCredentials(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Options{Level: slog.LevelInfo, Format: "json", Redact: true})
+	require.NoError(t, err)
+
+	logger.Info("created admin jane@example.com",
+		"email", "jane@example.com",
+		"refresh_token", "abc123",
+		"err", errors.New("send to bob@example.org: timeout"),
+		"user_id", "u1",
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "jane@example.com")
+	assert.NotContains(t, out, "bob@example.org")
+	assert.NotContains(t, out, "abc123")
+	assert.Contains(t, out, `"msg":"created admin j***@example.com"`)
+	assert.Contains(t, out, `"email":"j***@example.com"`)
+	assert.Contains(t, out, `"refresh_token":"[REDACTED]"`)
+	assert.Contains(t, out, `"err":"send to b***@example.org: timeout"`)
+	assert.Contains(t, out, `"user_id":"u1"`)
+}
+
+func TestNew_SamplesDebugPerMessage(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Options{Level: slog.LevelDebug, Format: "text", DebugSample: 3})
+	require.NoError(t, err)
+
+	for range 7 {
+		logger.Debug("cache hit")
+		logger.Info("request")
+	}
+	logger.With("k", "v").Debug("rare")
+
+	out := buf.String()
+	assert.Equal(t, 3, strings.Count(out, "msg=\"cache hit\""), "records 1, 4 and 7")
+	assert.Equal(t, 7, strings.Count(out, "msg=request"))
+	assert.Equal(t, 1, strings.Count(out, "msg=rare"))
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	level, err := ParseLevel("warn")
+	require.NoError(t, err)
+	logger, err := New(&buf, Options{Level: level, Format: "text"})
+	require.NoError(t, err)
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "shown")
+}
+
+func TestNew_RejectsUnknownSettings(t *testing.T) {
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+	_, err = New(&bytes.Buffer{}, Options{Format: "xml"})
+	assert.Error(t, err)
+}
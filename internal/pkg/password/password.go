@@ -0,0 +1,126 @@
+// Package password checks new passwords against a configurable strength
+// policy and, optionally, a corpus of breached passwords.
+package password
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+//go:embed common.txt
+var commonList string
+
+// common holds the lowercased entries of common.txt.
+var common = func() map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(commonList, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			set[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return set
+}()
+
+// Policy is what a new password must satisfy. The zero Policy accepts any
+// password.
+type Policy struct {
+	MinLength     int  // in characters, not bytes
+	RequireUpper  bool // an uppercase letter
+	RequireLower  bool // a lowercase letter
+	RequireDigit  bool
+	RequireSymbol bool // anything but a letter, digit or space
+	BanCommon     bool // refuse frequently used passwords, ignoring case
+	// Breaches, when set, is asked by CheckNew whether a password appears
+	// in a known data breach.
+	Breaches Breaches
+}
+
+// Breaches reports whether a password appears in a known data breach.
+type Breaches interface {
+	Breached(ctx context.Context, password string) (bool, error)
+}
+
+// CheckNew is Check followed by the breach check, which refuses pw with
+// domain.ErrBreachedPassword. Every path that sets a password uses it. The
+// breach check fails open: while it is unavailable, pw is only held to the
+// policy.
+func (p Policy) CheckNew(ctx context.Context, pw string) error {
+	if err := p.Check(pw); err != nil || p.Breaches == nil {
+		return err
+	}
+	breached, err := p.Breaches.Breached(ctx, pw)
+	if err != nil {
+		slog.Warn("password breach check unavailable", "error", err)
+		return nil
+	}
+	if breached {
+		return domain.ErrBreachedPassword
+	}
+	return nil
+}
+
+// Check returns domain.ErrWeakPassword describing every rule pw breaks, or
+// nil.
+func (p Policy) Check(pw string) error {
+	var needs []string
+	if n := utf8.RuneCountInString(pw); n < p.MinLength {
+		needs = append(needs, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	upper, lower, digit, symbol := classes(pw)
+	for _, c := range []struct {
+		required, present bool
+		desc              string
+	}{
+		{p.RequireUpper, upper, "an uppercase letter"},
+		{p.RequireLower, lower, "a lowercase letter"},
+		{p.RequireDigit, digit, "a digit"},
+		{p.RequireSymbol, symbol, "a symbol"},
+	} {
+		if c.required && !c.present {
+			needs = append(needs, c.desc)
+		}
+	}
+	var problems []string
+	if len(needs) > 0 {
+		problems = append(problems, "password needs "+joinAnd(needs))
+	}
+	if _, ok := common[strings.ToLower(pw)]; p.BanCommon && ok {
+		problems = append(problems, "password is too common")
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", strings.Join(problems, "; "), domain.ErrWeakPassword)
+}
+
+// classes reports which character classes pw contains.
+func classes(pw string) (upper, lower, digit, symbol bool) {
+	for _, r := range pw {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	return upper, lower, digit, symbol
+}
+
+// joinAnd joins items as "a, b and c".
+func joinAnd(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
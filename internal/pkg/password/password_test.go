@@ -0,0 +1,67 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	strict := Policy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true, BanCommon: true}
+	cases := []struct {
+		name   string
+		policy Policy
+		pw     string
+		want   string // "" means accepted
+	}{
+		{"zero policy accepts anything", Policy{}, "a", ""},
+		{"long enough", Policy{MinLength: 8}, "abcdefgh", ""},
+		{"too short", Policy{MinLength: 8}, "abcdefg", "password needs at least 8 characters"},
+		{"length counts characters", Policy{MinLength: 4}, "ñçüé", ""},
+		{"all classes", strict, "Tr0ub4dor&3x", ""},
+		{"missing classes", strict, "troubadourx", "password needs an uppercase letter, a digit and a symbol"},
+		{"non-ASCII letters count", Policy{RequireUpper: true, RequireLower: true}, "Ñandú", ""},
+		{"space is not a symbol", Policy{RequireSymbol: true}, "correct horse", "password needs a symbol"},
+		{"common password", Policy{BanCommon: true}, "Password123", "password is too common"},
+		{"common and weak", strict, "qwerty", "password needs at least 10 characters, an uppercase letter, a digit and a symbol; password is too common"},
+		{"common allowed when not banned", Policy{}, "password123", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Check(tc.pw)
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, domain.ErrWeakPassword)
+			assert.ErrorIs(t, err, domain.ErrBadRequest)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
+
+// stubBreaches reports the passwords in breached as breached, or fails with err.
+type stubBreaches struct {
+	breached map[string]bool
+	err      error
+}
+
+func (b stubBreaches) Breached(_ context.Context, pw string) (bool, error) {
+	return b.breached[pw], b.err
+}
+
+func TestCheckNew(t *testing.T) {
+	ctx := context.Background()
+	p := Policy{MinLength: 8, Breaches: stubBreaches{breached: map[string]bool{"P@ssw0rd!": true}}}
+
+	assert.ErrorIs(t, p.CheckNew(ctx, "P@ssw0rd!"), domain.ErrBreachedPassword)
+	assert.ErrorIs(t, p.CheckNew(ctx, "short"), domain.ErrWeakPassword, "the policy is checked first")
+	assert.NoError(t, p.CheckNew(ctx, "unbreached passphrase"))
+	assert.NoError(t, Policy{}.CheckNew(ctx, "P@ssw0rd!"), "no breach check without Breaches")
+
+	p.Breaches = stubBreaches{err: errors.New("hibp: status 503")}
+	assert.NoError(t, p.CheckNew(ctx, "P@ssw0rd!"), "an unavailable breach check fails open")
+}
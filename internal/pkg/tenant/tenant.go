@@ -0,0 +1,53 @@
+// Package tenant carries the tenant a request acts for. The HTTP middleware
+// resolves it from the subdomain or the bearer token, and the DynamoDB client
+// reads it back to keep every item operation inside that tenant.
+package tenant
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Modes for TENANT_MODE.
+const (
+	ModeJWT       = "jwt"       // tenant claim of the bearer token; X-Tenant-ID before login
+	ModeSubdomain = "subdomain" // first label of the Host below TENANT_BASE_DOMAIN
+)
+
+type contextKey struct{}
+
+var validID = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Valid reports whether id can name a tenant: a lowercase DNS label, so the
+// same IDs work in both modes.
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+// WithID returns a copy of ctx acting for tenant id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx acts for. Background jobs and startup
+// code carry none and operate across all tenants.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// FromHost returns the tenant named by the first label of host, which must be
+// exactly one label below baseDomain (e.g. "acme.api.example.com" for
+// "api.example.com"). A port in host is ignored.
+func FromHost(host, baseDomain string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !ok || !Valid(label) {
+		return "", false
+	}
+	return label, true
+}
@@ -0,0 +1,181 @@
+// Package webauthn checks the parts of WebAuthn registration and
+// authentication responses the passkey service relies on. It reads the JSON
+// form browsers and platform SDKs produce (PublicKeyCredential.toJSON), whose
+// public key is already SPKI DER, so no CBOR decoding is needed. Attestation
+// is not verified: passkeys are registered with attestation "none".
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// COSE algorithm identifiers of the supported credential keys.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// Algorithms lists the supported algorithms in order of preference.
+var Algorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// Client data types of the two ceremonies.
+const (
+	TypeCreate = "webauthn.create"
+	TypeGet    = "webauthn.get"
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// ErrInvalid is wrapped by every error about a malformed or failing response.
+var ErrInvalid = errors.New("invalid webauthn response")
+
+// ClientData is the part of clientDataJSON the server checks.
+type ClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// AuthenticatorData is the parsed authenticator data. CredentialID is only
+// set on registration, where the authenticator attests the new credential.
+type AuthenticatorData struct {
+	RPIDHash     [32]byte
+	Flags        byte
+	SignCount    uint32
+	CredentialID []byte
+}
+
+func (a AuthenticatorData) UserPresent() bool  { return a.Flags&flagUserPresent != 0 }
+func (a AuthenticatorData) UserVerified() bool { return a.Flags&flagUserVerified != 0 }
+
+// NewChallenge returns 32 random bytes, base64url-encoded as they appear in
+// clientDataJSON.
+func NewChallenge() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate webauthn challenge: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes a base64url field of a response, with or without padding.
+func Decode(field, s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(trimPadding(s))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not base64url: %w", field, ErrInvalid)
+	}
+	return b, nil
+}
+
+func trimPadding(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '=' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// ParseClientData decodes clientDataJSON.
+func ParseClientData(raw []byte) (ClientData, error) {
+	var cd ClientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return ClientData{}, fmt.Errorf("clientDataJSON: %w", ErrInvalid)
+	}
+	return cd, nil
+}
+
+// ParseAuthenticatorData decodes authenticator data, including the
+// credential ID of attested credential data when present.
+func ParseAuthenticatorData(raw []byte) (AuthenticatorData, error) {
+	var a AuthenticatorData
+	if len(raw) < 37 {
+		return a, fmt.Errorf("authenticator data too short: %w", ErrInvalid)
+	}
+	copy(a.RPIDHash[:], raw[:32])
+	a.Flags = raw[32]
+	a.SignCount = binary.BigEndian.Uint32(raw[33:37])
+	if a.Flags&flagAttested == 0 {
+		return a, nil
+	}
+	// aaguid (16 bytes), credential ID length (2 bytes), credential ID
+	rest := raw[37:]
+	if len(rest) < 18 {
+		return a, fmt.Errorf("attested credential data too short: %w", ErrInvalid)
+	}
+	n := int(binary.BigEndian.Uint16(rest[16:18]))
+	if len(rest) < 18+n {
+		return a, fmt.Errorf("credential ID truncated: %w", ErrInvalid)
+	}
+	a.CredentialID = rest[18 : 18+n]
+	return a, nil
+}
+
+// RPIDHash returns the hash authenticators report for rpID.
+func RPIDHash(rpID string) [32]byte {
+	return sha256.Sum256([]byte(rpID))
+}
+
+// ParseKey parses an SPKI DER public key and checks that it suits alg.
+func ParseKey(spki []byte, alg int) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(spki)
+	if err != nil {
+		return nil, fmt.Errorf("public key: %w", ErrInvalid)
+	}
+	var ok bool
+	switch alg {
+	case AlgES256:
+		var k *ecdsa.PublicKey
+		k, ok = key.(*ecdsa.PublicKey)
+		ok = ok && k.Curve.Params().Name == "P-256"
+	case AlgEdDSA:
+		_, ok = key.(ed25519.PublicKey)
+	case AlgRS256:
+		_, ok = key.(*rsa.PublicKey)
+	}
+	if !ok {
+		return nil, fmt.Errorf("public key does not match algorithm %d: %w", alg, ErrInvalid)
+	}
+	return key, nil
+}
+
+// VerifySignature checks an assertion signature, made over the
+// authenticator data followed by the SHA-256 of clientDataJSON, with the
+// credential's SPKI public key.
+func VerifySignature(spki []byte, alg int, authData, clientDataJSON, sig []byte) error {
+	key, err := ParseKey(spki, alg)
+	if err != nil {
+		return err
+	}
+	clientHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authData...), clientHash[:]...)
+	digest := sha256.Sum256(signed)
+	var valid bool
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(k, digest[:], sig)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(k, signed, sig)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	}
+	if !valid {
+		return fmt.Errorf("signature does not verify: %w", ErrInvalid)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package webauthn
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthenticatorData_AttestedCredential(t *testing.T) {
+	hash := RPIDHash("example.com")
+	raw := append(hash[:], flagUserPresent|flagUserVerified|flagAttested)
+	raw = binary.BigEndian.AppendUint32(raw, 7)
+	raw = append(raw, make([]byte, 16)...)
+	raw = binary.BigEndian.AppendUint16(raw, 3)
+	raw = append(raw, "abc"...)
+
+	a, err := ParseAuthenticatorData(raw)
+
+	require.NoError(t, err)
+	assert.Equal(t, hash, a.RPIDHash)
+	assert.True(t, a.UserPresent())
+	assert.True(t, a.UserVerified())
+	assert.Equal(t, uint32(7), a.SignCount)
+	assert.Equal(t, []byte("abc"), a.CredentialID)
+
+	_, err = ParseAuthenticatorData(raw[:len(raw)-1])
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestVerifySignature_Ed25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	spki, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	authData, clientData := []byte("authenticator data"), []byte(`{"type":"webauthn.get"}`)
+	clientHash := sha256.Sum256(clientData)
+	sig := ed25519.Sign(priv, append(append([]byte{}, authData...), clientHash[:]...))
+
+	require.NoError(t, VerifySignature(spki, AlgEdDSA, authData, clientData, sig))
+	assert.ErrorIs(t, VerifySignature(spki, AlgEdDSA, authData, []byte("{}"), sig), ErrInvalid)
+	assert.ErrorIs(t, VerifySignature(spki, AlgES256, authData, clientData, sig), ErrInvalid, "the key must match the algorithm")
+}
+
+func TestDecode_AcceptsPadding(t *testing.T) {
+	b, err := Decode("id", "YWI=")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ab"), b)
+	_, err = Decode("id", "not base64!")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
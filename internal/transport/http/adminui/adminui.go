@@ -0,0 +1,35 @@
+// Package adminui serves the embedded admin web UI.
+//
+// The UI is a static page that signs in through POST /v1/sessions/login and
+// calls the existing /v1 admin endpoints with the returned bearer token. The
+// assets hold no data, so they are public; every API call is authorized by the
+// route policy like any other client's.
+package adminui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the UI's assets. Mount it with the mount prefix stripped.
+func Handler() http.Handler {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // the embedded directory is fixed at build time
+	}
+	files := http.FileServer(http.FS(sub))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		// Scripts are only loaded from this origin and the page cannot be framed,
+		// which keeps the admin bearer token out of reach of injected content.
+		h.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'")
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,309 @@
+'use strict';
+
+// Tokens live in sessionStorage so they are dropped when the tab closes.
+const store = window.sessionStorage;
+const $ = (sel) => document.querySelector(sel);
+
+let nextCursor = '';
+const selected = new Set();
+
+function flash(msg, isError) {
+  const el = $('#flash');
+  el.textContent = msg;
+  el.className = isError ? 'error' : '';
+  el.hidden = !msg;
+}
+
+async function refresh() {
+  const token = store.getItem('refresh_token');
+  if (!token) return false;
+  const res = await fetch('/v1/sessions/refresh', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ refresh_token: token }),
+  });
+  if (!res.ok) return false;
+  const body = await res.json();
+  store.setItem('access_token', body.access_token);
+  store.setItem('refresh_token', body.refresh_token);
+  return true;
+}
+
+// api calls a /v1 endpoint with the admin bearer token, refreshing it once on 401.
+async function api(method, path, body, retried) {
+  const headers = { Authorization: 'Bearer ' + store.getItem('access_token') };
+  if (body !== undefined) headers['Content-Type'] = 'application/json';
+  if (method !== 'GET') {
+    // Replay protection (REPLAY_PROTECTION) wants a fresh nonce per write; other routes ignore it.
+    headers['X-Request-Nonce'] = crypto.randomUUID();
+    headers['X-Request-Timestamp'] = String(Math.floor(Date.now() / 1000));
+  }
+  const res = await fetch('/v1' + path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (res.status === 401 && !retried && await refresh()) {
+    return api(method, path, body, true);
+  }
+  if (res.status === 401) {
+    signOut();
+    throw new Error('session expired; sign in again');
+  }
+  const data = res.status === 204 ? null : await res.json().catch(() => null);
+  if (!res.ok) throw new Error((data && data.error) || res.statusText);
+  return data;
+}
+
+function cell(text, className) {
+  const td = document.createElement('td');
+  td.textContent = text;
+  if (className) td.className = className;
+  return td;
+}
+
+function button(label, onClick) {
+  const b = document.createElement('button');
+  b.type = 'button';
+  b.textContent = label;
+  b.addEventListener('click', onClick);
+  return b;
+}
+
+// --- session ---
+
+function showApp(user) {
+  $('#login').hidden = true;
+  $('#tabs').hidden = false;
+  $('#logout').hidden = false;
+  $('#whoami').textContent = user;
+  showTab('users');
+  loadRoles().then(() => loadUsers(true)).catch((e) => flash(e.message, true));
+}
+
+function signOut() {
+  store.clear();
+  document.querySelectorAll('main > section').forEach((s) => { s.hidden = s.id !== 'login'; });
+  $('#tabs').hidden = true;
+  $('#logout').hidden = true;
+  $('#whoami').textContent = '';
+}
+
+async function login(ev) {
+  ev.preventDefault();
+  const form = ev.target;
+  const res = await fetch('/v1/sessions/login', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ username: form.username.value, password: form.password.value }),
+  });
+  const body = await res.json().catch(() => ({}));
+  if (!res.ok) {
+    flash(body.error || 'sign-in failed', true);
+    return;
+  }
+  store.setItem('access_token', body.access_token);
+  store.setItem('refresh_token', body.refresh_token);
+  if (body.user.role !== 'Admin') {
+    await api('POST', '/sessions/logout').catch(() => {});
+    signOut();
+    flash('this account is not an administrator', true);
+    return;
+  }
+  store.setItem('user', body.user.username);
+  form.reset();
+  flash('');
+  showApp(body.user.username);
+}
+
+async function logout() {
+  await api('POST', '/sessions/logout').catch(() => {});
+  signOut();
+}
+
+function showTab(name) {
+  document.querySelectorAll('main > section').forEach((s) => { s.hidden = s.id !== name; });
+  document.querySelectorAll('#tabs button').forEach((b) => {
+    b.classList.toggle('active', b.dataset.tab === name);
+  });
+  if (name === 'versions') loadVersions().catch((e) => flash(e.message, true));
+}
+
+// --- users ---
+
+let roles = [];
+
+async function loadRoles() {
+  roles = (await api('GET', '/roles')).map((r) => r.name);
+  const filter = $('#user-filter').role;
+  roles.forEach((name) => filter.add(new Option(name, name)));
+}
+
+function userRow(u) {
+  const tr = document.createElement('tr');
+  const pick = document.createElement('input');
+  pick.type = 'checkbox';
+  pick.checked = selected.has(u.id);
+  pick.addEventListener('change', () => { pick.checked ? selected.add(u.id) : selected.delete(u.id); });
+  const pickCell = document.createElement('td');
+  pickCell.append(pick);
+
+  const role = document.createElement('select');
+  roles.forEach((name) => role.add(new Option(name, name, false, name === u.role)));
+  role.addEventListener('change', () => changeRole(u, role));
+  const roleCell = document.createElement('td');
+  roleCell.append(role);
+
+  const status = cell(u.enable ? 'enabled ' : 'disabled ', u.enable ? '' : 'off');
+  status.append(button(u.enable ? 'Disable' : 'Enable', () => toggleUser(u, tr)));
+
+  tr.append(pickCell, cell(u.username), cell(u.email), cell(`${u.first_name} ${u.last_name}`), roleCell, status);
+  return tr;
+}
+
+async function loadUsers(reset) {
+  const f = $('#user-filter');
+  const rows = $('#user-rows');
+  if (reset) {
+    rows.replaceChildren();
+    nextCursor = '';
+  }
+  let users;
+  if (f.q.value.trim()) {
+    // Free-text search needs the search backend (OPENSEARCH_URL).
+    const res = await api('GET', '/search?type=users&limit=100&q=' + encodeURIComponent(f.q.value.trim()));
+    users = res.data.filter((u) => (!f.role.value || u.role === f.role.value) &&
+      (!f.enabled.value || String(u.enable) === f.enabled.value));
+    nextCursor = '';
+  } else {
+    const q = new URLSearchParams({ limit: '50' });
+    if (f.role.value) q.set('role', f.role.value);
+    if (f.enabled.value) q.set('enabled', f.enabled.value);
+    if (nextCursor) q.set('cursor', nextCursor);
+    const res = await api('GET', '/users?' + q);
+    users = res.data;
+    nextCursor = res.next_cursor || '';
+  }
+  users.forEach((u) => rows.append(userRow(u)));
+  $('#more-users').hidden = !nextCursor;
+}
+
+async function toggleUser(u, tr) {
+  try {
+    await api('PUT', '/users/' + encodeURIComponent(u.id), { enable: u.enable ? 0 : 1 });
+    u.enable = !u.enable;
+    tr.replaceWith(userRow(u));
+    flash(`${u.username} ${u.enable ? 'enabled' : 'disabled'}`);
+  } catch (e) {
+    flash(e.message, true);
+  }
+}
+
+async function changeRole(u, select) {
+  const role = select.value;
+  try {
+    const res = await api('PUT', `/admin/users/${encodeURIComponent(u.id)}/role`, {
+      role,
+      revoke_sessions: $('#revoke-sessions').checked,
+    });
+    if (res.status === 'pending') {
+      // APPROVALS_REQUIRED: the grant waits for a second admin.
+      select.value = u.role;
+      flash(`making ${u.username} ${role} needs a second admin's approval (request ${res.id})`);
+      return;
+    }
+    u.role = role;
+    flash(`${u.username} is now ${role}`);
+  } catch (e) {
+    select.value = u.role;
+    flash(e.message, true);
+  }
+}
+
+// --- notifications ---
+
+async function sendNotification(ev) {
+  ev.preventDefault();
+  const form = ev.target;
+  const ids = form.user_ids.value.split(/\s+/).filter(Boolean);
+  const recipients = ids.length ? ids : [...selected];
+  if (!recipients.length) {
+    flash('select users on the Users tab or list their IDs', true);
+    return;
+  }
+  const body = { message: form.message.value };
+  if (form.send_at.value) body.send_at = new Date(form.send_at.value).toISOString();
+  let sent = 0;
+  const failed = [];
+  for (const id of recipients) {
+    try {
+      await api('POST', '/admin/notifications', { ...body, user_id: id });
+      sent++;
+    } catch (e) {
+      failed.push(`${id}: ${e.message}`);
+    }
+    $('#notify-result').textContent = `${sent} of ${recipients.length} sent`;
+  }
+  if (failed.length) flash('failed: ' + failed.join('; '), true);
+  else flash(`notification sent to ${sent} user(s)`);
+}
+
+// --- app versions ---
+
+async function loadVersions() {
+  const rows = $('#version-rows');
+  rows.replaceChildren();
+  (await api('GET', '/admin/app-versions')).forEach((v) => {
+    const tr = document.createElement('tr');
+    const status = cell(v.enable ? 'enabled ' : 'disabled ', v.enable ? '' : 'off');
+    status.append(button(v.enable ? 'Disable' : 'Enable', () => toggleVersion(v)));
+    tr.append(cell(v.platform || '—'), cell(v.version), cell(v.released_at ? v.released_at.slice(0, 10) : ''), cell(v.id), status);
+    rows.append(tr);
+  });
+}
+
+async function toggleVersion(v) {
+  try {
+    await api('PUT', '/admin/app-versions/' + encodeURIComponent(v.id), { version: v.version, enable: !v.enable });
+    await loadVersions();
+  } catch (e) {
+    flash(e.message, true);
+  }
+}
+
+async function addVersion(ev) {
+  ev.preventDefault();
+  try {
+    await api('POST', '/admin/app-versions', {
+      platform: ev.target.platform.value,
+      version: ev.target.version.value.trim(),
+    });
+    ev.target.reset();
+    await loadVersions();
+  } catch (e) {
+    flash(e.message, true);
+  }
+}
+
+// --- wiring ---
+
+document.addEventListener('DOMContentLoaded', () => {
+  $('#login-form').addEventListener('submit', (ev) => login(ev).catch((e) => flash(e.message, true)));
+  $('#logout').addEventListener('click', logout);
+  document.querySelectorAll('#tabs button').forEach((b) => b.addEventListener('click', () => showTab(b.dataset.tab)));
+  $('#user-filter').addEventListener('submit', (ev) => {
+    ev.preventDefault();
+    loadUsers(true).catch((e) => flash(e.message, true));
+  });
+  $('#more-users').addEventListener('click', () => loadUsers(false).catch((e) => flash(e.message, true)));
+  $('#select-all').addEventListener('change', (ev) => {
+    document.querySelectorAll('#user-rows input[type=checkbox]').forEach((c) => {
+      c.checked = ev.target.checked;
+      c.dispatchEvent(new Event('change'));
+    });
+  });
+  $('#notify-form').addEventListener('submit', (ev) => sendNotification(ev).catch((e) => flash(e.message, true)));
+  $('#version-form').addEventListener('submit', addVersion);
+
+  if (store.getItem('access_token')) showApp(store.getItem('user') || '');
+});
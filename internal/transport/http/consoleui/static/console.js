@@ -0,0 +1,84 @@
+'use strict';
+
+const $ = (sel) => document.querySelector(sel);
+
+async function call(method, path, body, token) {
+  const headers = {};
+  if (body !== undefined) headers['Content-Type'] = 'application/json';
+  if (token) headers.Authorization = 'Bearer ' + token;
+  const res = await fetch(path, { method, headers, body: body === undefined ? undefined : JSON.stringify(body) });
+  const text = await res.text();
+  let data = text;
+  try { data = JSON.parse(text); } catch (_) { /* not JSON */ }
+  return { status: res.status, data };
+}
+
+function show(el, res) {
+  el.textContent = `${res.status}\n` + (typeof res.data === 'string' ? res.data : JSON.stringify(res.data, null, 2));
+}
+
+// useToken copies a freshly issued bearer into the request form.
+function useToken(res) {
+  const tokens = res.data && (res.data.tokens || res.data);
+  if (res.status < 300 && tokens && tokens.access_token) $('#token').value = tokens.access_token;
+}
+
+async function seed(ev) {
+  ev.preventDefault();
+  const role = ev.target.role.value.trim();
+  const res = await call('POST', '/dev/api/users', role ? { role } : {});
+  show($('#seed-result'), res);
+  useToken(res);
+}
+
+async function mint(ev) {
+  ev.preventDefault();
+  const res = await call('POST', '/dev/api/tokens', { user_id: ev.target.user_id.value.trim() });
+  show($('#mint-result'), res);
+  useToken(res);
+}
+
+async function send(ev) {
+  ev.preventDefault();
+  const f = ev.target;
+  let body;
+  if (f.body.value.trim()) {
+    try {
+      body = JSON.parse(f.body.value);
+    } catch (e) {
+      $('#request-result').textContent = 'body is not valid JSON: ' + e.message;
+      return;
+    }
+  }
+  show($('#request-result'), await call(f.method.value, f.path.value, body, f.token.value.trim()));
+}
+
+async function loadEmails() {
+  const res = await call('GET', '/dev/emails');
+  const list = $('#emails');
+  list.replaceChildren();
+  if (!res.data.data || res.data.data.length === 0) {
+    list.textContent = 'No email captured yet.';
+    return;
+  }
+  res.data.data.forEach((m) => {
+    const item = document.createElement('div');
+    item.className = 'email';
+    const title = document.createElement('h3');
+    title.textContent = m.subject;
+    const meta = document.createElement('div');
+    meta.textContent = `to ${m.to} at ${new Date(m.sent_at).toLocaleString()}`;
+    const body = document.createElement('pre');
+    body.textContent = m.body;
+    item.append(title, meta, body);
+    list.append(item);
+  });
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  $('#seed-form').addEventListener('submit', seed);
+  $('#mint-form').addEventListener('submit', mint);
+  $('#request-form').addEventListener('submit', send);
+  $('#refresh-emails').addEventListener('click', loadEmails);
+  loadEmails();
+});
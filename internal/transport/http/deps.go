@@ -2,7 +2,9 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"io"
+	"time"
 
 	"github.com/go-api-nosql/internal/domain"
 )
@@ -12,12 +14,30 @@ type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	Put(ctx context.Context, u *domain.User) error
-	// QueryPage returns a page of enabled users via the `enable-index` GSI.
-	// Only users with enable=1 are returned; this is not a full table scan.
-	QueryPage(ctx context.Context, limit int32, cursor string) ([]domain.User, string, error)
+	// QueryPage returns a page of users matching the filter via the
+	// `enable-created_at-index` or `role-created_at-index` GSI; this is not a
+	// full table scan.
+	QueryPage(ctx context.Context, f domain.UserFilter, limit int32, cursor string) ([]domain.User, string, error)
+	// CountMatching counts the users QueryPage lists for f, reading the same GSI.
+	CountMatching(ctx context.Context, f domain.UserFilter) (int, error)
+	// ApproxCount returns the table's item count as last reported by DynamoDB.
+	ApproxCount(ctx context.Context) (int64, error)
 	Get(ctx context.Context, userID string) (*domain.User, error)
 	Update(ctx context.Context, userID string, updates map[string]interface{}) error
 	SoftDelete(ctx context.Context, userID string) error
+	CountByRole(ctx context.Context, role string) (int, error)
+	EachMatching(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error
+	// SweepBefore deletes users soft-deleted before cutoff, or only counts them when dryRun is set.
+	SweepBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error)
+	// AnonymizeBefore scrubs the personal data of users soft-deleted before cutoff, keeping their IDs.
+	AnonymizeBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error)
+	// ClaimOnboarding records that the onboarding email step is being sent to
+	// the user and reports false when another caller already claimed it.
+	ClaimOnboarding(ctx context.Context, userID, step string) (bool, error)
+	ReleaseOnboarding(ctx context.Context, userID, step string) error
+	// ClaimBirthday records that the year's birthday notification is being
+	// sent to the user and reports false when another caller already claimed it.
+	ClaimBirthday(ctx context.Context, userID, year string) (bool, error)
 }
 
 // SessionRepository is the minimal interface the router requires from a session store.
@@ -28,11 +48,15 @@ type SessionRepository interface {
 	RotateRefreshToken(ctx context.Context, sessionID, newToken string, newExpiry int64) error
 	Update(ctx context.Context, sessionID string, updates map[string]interface{}) error
 	SoftDeleteByUser(ctx context.Context, userID string) error
+	ListActiveByUser(ctx context.Context, userID string) ([]domain.Session, error)
+	// RedeemAuthCode applies updates to the session holding an OAuth
+	// authorization code, failing with domain.ErrConflict once it is used.
+	RedeemAuthCode(ctx context.Context, sessionID, code string, updates map[string]interface{}) error
 }
 
 // DeviceRepository is the minimal interface the router requires from a device store.
 type DeviceRepository interface {
-	GetByUUID(ctx context.Context, uuid string) (*domain.Device, error)
+	GetByUUID(ctx context.Context, uuid, userID string) (*domain.Device, error)
 	Put(ctx context.Context, d *domain.Device) error
 	ListByUser(ctx context.Context, userID string) ([]domain.Device, error)
 	Get(ctx context.Context, deviceID string) (*domain.Device, error)
@@ -42,7 +66,7 @@ type DeviceRepository interface {
 
 // StatusRepository is the minimal interface the router requires from a status store.
 type StatusRepository interface {
-	Scan(ctx context.Context) ([]domain.Status, error)
+	List(ctx context.Context) ([]domain.Status, error)
 	Get(ctx context.Context, statusID string) (*domain.Status, error)
 	Put(ctx context.Context, s *domain.Status) error
 	Update(ctx context.Context, statusID string, updates map[string]interface{}) error
@@ -53,7 +77,64 @@ type StatusRepository interface {
 type NotificationRepository interface {
 	ListUnread(ctx context.Context, userID string) ([]domain.Notification, error)
 	Get(ctx context.Context, notificationID string) (*domain.Notification, error)
-	MarkAsRead(ctx context.Context, notificationID string) (*domain.Notification, error)
+	MarkAsRead(ctx context.Context, notificationID, ownerID string) (*domain.Notification, error)
+	AddReceipt(ctx context.Context, notificationID string, rc domain.Receipt) error
+	Put(ctx context.Context, n *domain.Notification) error
+	// BatchPut writes notifications, overwriting any with the same ID.
+	BatchPut(ctx context.Context, notifications []domain.Notification) error
+	ListDue(ctx context.Context, now time.Time) ([]domain.Notification, error)
+	UpdateScheduled(ctx context.Context, notificationID string, updates map[string]interface{}) error
+	CloseSchedule(ctx context.Context, notificationID, status string) error
+	CountByUser(ctx context.Context, userID string) (int, error)
+	SoftDelete(ctx context.Context, notificationID string, purgeAt int64) error
+	EachVisible(ctx context.Context, userID string, fn func(domain.Notification) error) error
+	// SweepBefore deletes notifications dismissed before cutoff, or only counts them when dryRun is set.
+	SweepBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error)
+}
+
+// NotificationTemplateRepository is the minimal interface the router requires from a notification template store.
+type NotificationTemplateRepository interface {
+	Create(ctx context.Context, t *domain.NotificationTemplate) error
+	Get(ctx context.Context, name string) (*domain.NotificationTemplate, error)
+	List(ctx context.Context) ([]domain.NotificationTemplate, error)
+	Update(ctx context.Context, name string, updates map[string]interface{}) error
+	HardDelete(ctx context.Context, name string) error
+}
+
+// MessageRepository is the minimal interface the router requires from a direct-message store.
+type MessageRepository interface {
+	Put(ctx context.Context, m *domain.Message) error
+	ListConversation(ctx context.Context, conversationID string, limit int32, cursor string) ([]domain.Message, string, error)
+	ListUnread(ctx context.Context, userID string) ([]domain.Message, error)
+	MarkRead(ctx context.Context, conversationID string, sentAt int64) error
+}
+
+// RoleRepository is the minimal interface the router requires from a role store.
+type RoleRepository interface {
+	Create(ctx context.Context, role *domain.Role) error
+	Get(ctx context.Context, name string) (*domain.Role, error)
+	List(ctx context.Context) ([]domain.Role, error)
+	Update(ctx context.Context, name string, updates map[string]interface{}) error
+	HardDelete(ctx context.Context, name string) error
+}
+
+// BackupStatusReader reports a table's point-in-time recovery status.
+type BackupStatusReader interface {
+	TableBackup(ctx context.Context, table string) (domain.TableBackup, error)
+}
+
+// AuditRepository is the minimal interface the router requires from an audit log store.
+type AuditRepository interface {
+	Put(ctx context.Context, e *domain.AuditEntry) error
+	EachInDay(ctx context.Context, day string, fn func(domain.AuditEntry) error) error
+	// SweepBefore deletes entries recorded before cutoff, or only counts them when dryRun is set.
+	SweepBefore(ctx context.Context, cutoff time.Time, dryRun bool) (int, error)
+}
+
+// ActivityRepository is the minimal interface the router requires from an activity feed store.
+type ActivityRepository interface {
+	Put(ctx context.Context, a *domain.Activity) error
+	ListByUser(ctx context.Context, userID string, limit int32, cursor string) ([]domain.Activity, string, error)
 }
 
 // FileRepository is the minimal interface the router requires from a file store.
@@ -61,6 +142,7 @@ type FileRepository interface {
 	Put(ctx context.Context, f *domain.File) error
 	Get(ctx context.Context, fileID string) (*domain.File, error)
 	SoftDelete(ctx context.Context, fileID string) error
+	UsageByUploader(ctx context.Context, userID string) (domain.StorageUsage, error)
 }
 
 // VerificationRepository is the minimal interface the router requires from a verification store.
@@ -68,11 +150,118 @@ type VerificationRepository interface {
 	Put(ctx context.Context, v *domain.UserVerification) error
 	Get(ctx context.Context, userID, verType string) (*domain.UserVerification, error)
 	Delete(ctx context.Context, userID, verType string) error
+	Take(ctx context.Context, userID, verType string) (*domain.UserVerification, error)
 }
 
 // AppVersionRepository is the minimal interface the router requires from an app-version store.
 type AppVersionRepository interface {
-	GetLatest(ctx context.Context) (*domain.AppVersion, error)
+	Get(ctx context.Context, versionID string) (*domain.AppVersion, error)
+	GetLatest(ctx context.Context, platform string) (*domain.AppVersion, error)
+	List(ctx context.Context) ([]domain.AppVersion, error)
+	Put(ctx context.Context, v *domain.AppVersion) error
+}
+
+// RateLimitRepository is the minimal interface the router requires from a shared rate-limit counter store.
+type RateLimitRepository interface {
+	Increment(ctx context.Context, key string, expiresAt int64) (int64, error)
+	Count(ctx context.Context, key string) (int64, error)
+	Delete(ctx context.Context, key string) error
+	Lock(ctx context.Context, key string, until int64) error
+	LockedUntil(ctx context.Context, key string) (int64, error)
+}
+
+// ApprovalRepository is the minimal interface the router requires from an approval store.
+type ApprovalRepository interface {
+	Put(ctx context.Context, a *domain.Approval) error
+	Get(ctx context.Context, approvalID string) (*domain.Approval, error)
+	List(ctx context.Context, status string) ([]domain.Approval, error)
+	Decide(ctx context.Context, approvalID string, updates map[string]interface{}) error
+	Update(ctx context.Context, approvalID string, updates map[string]interface{}) error
+}
+
+// BroadcastRepository is the minimal interface the router requires from a broadcast store.
+type BroadcastRepository interface {
+	Put(ctx context.Context, b *domain.Broadcast) error
+	Get(ctx context.Context, broadcastID string) (*domain.Broadcast, error)
+	List(ctx context.Context, status string) ([]domain.Broadcast, error)
+	Claim(ctx context.Context, broadcastID, leaseID string, until int64) (*domain.Broadcast, error)
+	Advance(ctx context.Context, broadcastID, leaseID string, updates map[string]interface{}) (*domain.Broadcast, error)
+	Close(ctx context.Context, broadcastID string, from []string, updates map[string]interface{}) error
+}
+
+// SettingsRepository is the minimal interface the router requires from the runtime settings store.
+type SettingsRepository interface {
+	GetReadOnly(ctx context.Context) (domain.ReadOnlyMode, error)
+	PutReadOnly(ctx context.Context, m domain.ReadOnlyMode) error
+}
+
+// LaunchRepository is the minimal interface the router requires from a launch allowlist store.
+type LaunchRepository interface {
+	Put(ctx context.Context, e *domain.LaunchEntry) error
+	Get(ctx context.Context, kind, value string) (*domain.LaunchEntry, error)
+	List(ctx context.Context) ([]domain.LaunchEntry, error)
+	Delete(ctx context.Context, kind, value string) error
+	Redeem(ctx context.Context, kind, value string) error
+	CountGated(ctx context.Context) error
+	CountAdmitted(ctx context.Context, kind string) error
+	Stats(ctx context.Context) (domain.LaunchStats, error)
+}
+
+// PasskeyRepository is the minimal interface the router requires from a passkey store.
+type PasskeyRepository interface {
+	Put(ctx context.Context, p *domain.Passkey) error
+	Get(ctx context.Context, credentialID string) (*domain.Passkey, error)
+	ListByUser(ctx context.Context, userID string) ([]domain.Passkey, error)
+	Delete(ctx context.Context, userID, credentialID string) error
+	RecordUse(ctx context.Context, credentialID string, signCount uint32, at time.Time) error
+}
+
+// OAuthClientRepository is the minimal interface the router requires from an OAuth client store.
+type OAuthClientRepository interface {
+	Put(ctx context.Context, c *domain.OAuthClient) error
+	Get(ctx context.Context, clientID string) (*domain.OAuthClient, error)
+	List(ctx context.Context) ([]domain.OAuthClient, error)
+	Delete(ctx context.Context, clientID string) error
+}
+
+// APIKeyRepository is the minimal interface the router requires from an API key store.
+type APIKeyRepository interface {
+	Put(ctx context.Context, k *domain.APIKey) error
+	Get(ctx context.Context, keyID string) (*domain.APIKey, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	Delete(ctx context.Context, keyID string) error
+}
+
+// PlanRepository is the minimal interface the router requires from a plan store.
+type PlanRepository interface {
+	Create(ctx context.Context, p *domain.Plan) error
+	Get(ctx context.Context, name string) (*domain.Plan, error)
+	List(ctx context.Context) ([]domain.Plan, error)
+	Update(ctx context.Context, name string, updates map[string]interface{}) error
+	HardDelete(ctx context.Context, name string) error
+}
+
+// UsageRepository is the minimal interface the router requires from a usage counter store.
+type UsageRepository interface {
+	Add(ctx context.Context, userID, day string, delta domain.Usage, expiresAt int64) (*domain.Usage, error)
+	Get(ctx context.Context, userID, day string) (*domain.Usage, error)
+}
+
+// SearchIndex is the minimal interface the router requires from a full-text search backend.
+type SearchIndex interface {
+	Index(ctx context.Context, index, id string, doc interface{}) error
+	Delete(ctx context.Context, index, id string) error
+	Search(ctx context.Context, index string, body interface{}) ([]json.RawMessage, error)
+}
+
+// UserStream yields users written since the previous poll.
+type UserStream interface {
+	Poll(ctx context.Context) ([]domain.User, error)
+}
+
+// FileStream yields files written since the previous poll.
+type FileStream interface {
+	Poll(ctx context.Context) ([]domain.File, error)
 }
 
 // ObjectStore is the minimal interface the router requires from an object storage backend.
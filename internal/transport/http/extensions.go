@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/auth"
+	"github.com/go-api-nosql/internal/application/device"
+	fileapp "github.com/go-api-nosql/internal/application/file"
+	"github.com/go-api-nosql/internal/application/message"
+	"github.com/go-api-nosql/internal/application/notification"
+	"github.com/go-api-nosql/internal/application/session"
+	"github.com/go-api-nosql/internal/application/user"
+	"github.com/go-chi/chi/v5"
+)
+
+// Extensions lets a deployment add routes, middleware and service decorators
+// without editing NewRouter. Every field is optional.
+type Extensions struct {
+	// Middleware wraps every request, after the built-in logging, recovery,
+	// request ID and CORS middleware.
+	Middleware []func(http.Handler) http.Handler
+	// AuthMiddleware runs on authenticated routes after the route policy, so
+	// the caller's claims are available.
+	AuthMiddleware []func(http.Handler) http.Handler
+
+	// Routes mounts extra public routes under /v1.
+	Routes func(r chi.Router)
+	// AuthRoutes mounts extra routes under /v1 behind authentication, the
+	// session check, the route policy and AuthMiddleware.
+	AuthRoutes func(r chi.Router)
+
+	// Services wraps or replaces the built-in services before they are handed
+	// to handlers and to the services that depend on them.
+	Services ServiceOverrides
+}
+
+// ServiceOverrides holds optional decorators for the application services.
+// Each receives the built-in service; return it wrapped, or return a
+// different implementation to replace it.
+type ServiceOverrides struct {
+	Session      func(session.Service) session.Service
+	User         func(user.Service) user.Service
+	Auth         func(auth.Service) auth.Service
+	Device       func(device.Service) device.Service
+	Notification func(notification.Service) notification.Service
+	Message      func(message.Service) message.Service
+	File         func(fileapp.Service) fileapp.Service
+}
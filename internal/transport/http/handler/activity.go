@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/activity"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+)
+
+// ActivityHandler handles activity feed endpoints.
+type ActivityHandler struct {
+	svc activity.Service
+}
+
+func NewActivityHandler(svc activity.Service) *ActivityHandler { return &ActivityHandler{svc: svc} }
+
+// CursorActivitiesEnvelope wraps cursor-paginated activity feed responses.
+type CursorActivitiesEnvelope struct {
+	Data       []domain.Activity `json:"data"`
+	Returned   int               `json:"returned"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// ListMine returns the caller's activity feed, newest first.
+func (h *ActivityHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	limit, cursor := parseCursorPagination(r)
+	activities, nextCursor, err := h.svc.List(r.Context(), claims.UserID, limit, cursor)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, CursorActivitiesEnvelope{
+		Data:       activities,
+		Returned:   len(activities),
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/session"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// sessionCache is the session guard's cache of checked sessions.
+type sessionCache interface {
+	Forget(userID string, sessionIDs ...string)
+}
+
+// AdminSessionHandler lets admins see and end any user's sessions, for
+// answering account-compromise reports.
+type AdminSessionHandler struct {
+	svc   session.Service
+	cache sessionCache
+}
+
+func NewAdminSessionHandler(svc session.Service, cache sessionCache) *AdminSessionHandler {
+	return &AdminSessionHandler{svc: svc, cache: cache}
+}
+
+// TerminatedSessionsEnvelope is the response for DELETE
+// /v1/admin/users/{id}/sessions[/{session_id}].
+type TerminatedSessionsEnvelope struct {
+	Terminated []string `json:"terminated"`
+}
+
+// List serves GET /v1/admin/users/{id}/sessions.
+func (h *AdminSessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.svc.ListActive(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	out := make([]*SafeSession, 0, len(sessions))
+	for i := range sessions {
+		out = append(out, toSafeSession(&sessions[i]))
+	}
+	writeJSON(w, http.StatusOK, SessionsEnvelope{Data: out})
+}
+
+// Terminate serves DELETE /v1/admin/users/{id}/sessions, ending every
+// session of the user, and DELETE /v1/admin/users/{id}/sessions/{session_id}.
+func (h *AdminSessionHandler) Terminate(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	userID := chi.URLParam(r, "id")
+	ended, err := h.svc.TerminateSessions(r.Context(), claims.UserID, userID, chi.URLParam(r, "session_id"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	h.cache.Forget(userID, ended...)
+	if ended == nil {
+		ended = []string{}
+	}
+	writeJSON(w, http.StatusOK, TerminatedSessionsEnvelope{Terminated: ended})
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/apikey"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// APIKeyHandler lets admins mint and revoke API keys for internal services.
+type APIKeyHandler struct {
+	svc apikey.Service
+}
+
+func NewAPIKeyHandler(svc apikey.Service) *APIKeyHandler {
+	return &APIKeyHandler{svc: svc}
+}
+
+// APIKeysEnvelope is the response for GET /v1/admin/api-keys.
+type APIKeysEnvelope struct {
+	Data []domain.APIKey `json:"data"`
+}
+
+// Create serves POST /v1/admin/api-keys. The key is only in this response.
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	k, err := h.svc.Create(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, k)
+}
+
+// List serves GET /v1/admin/api-keys.
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.svc.List(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, APIKeysEnvelope{Data: keys})
+}
+
+// Revoke serves DELETE /v1/admin/api-keys/{id}.
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if err := h.svc.Revoke(r.Context(), claims.UserID, chi.URLParam(r, "id")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
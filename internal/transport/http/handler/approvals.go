@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/approval"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// ApprovalHandler lists and decides pending destructive admin actions. It is
+// only mounted when APPROVALS_REQUIRED is on.
+type ApprovalHandler struct {
+	svc approval.Service
+}
+
+func NewApprovalHandler(svc approval.Service) *ApprovalHandler {
+	return &ApprovalHandler{svc: svc}
+}
+
+// ApprovalsEnvelope is the response for GET /v1/admin/approvals.
+type ApprovalsEnvelope struct {
+	Data []domain.Approval `json:"data"`
+}
+
+// List serves GET /v1/admin/approvals?status=.
+func (h *ApprovalHandler) List(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", domain.ApprovalPending, domain.ApprovalApproved, domain.ApprovalFailed,
+		domain.ApprovalRejected, domain.ApprovalExpired:
+	default:
+		writeError(w, http.StatusBadRequest, "status must be pending, approved, failed, rejected or expired")
+		return
+	}
+	approvals, err := h.svc.List(r.Context(), status)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	if approvals == nil {
+		approvals = []domain.Approval{}
+	}
+	writeJSON(w, http.StatusOK, ApprovalsEnvelope{Data: approvals})
+}
+
+// Approve serves POST /v1/admin/approvals/{id}/approve.
+func (h *ApprovalHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	a, err := h.svc.Approve(r.Context(), claims.UserID, chi.URLParam(r, "id"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+// Reject serves POST /v1/admin/approvals/{id}/reject. The body is optional.
+func (h *ApprovalHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.RejectApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	a, err := h.svc.Reject(r.Context(), claims.UserID, chi.URLParam(r, "id"), req.Reason)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
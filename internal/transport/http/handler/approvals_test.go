@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockApprovalSvc struct{ mock.Mock }
+
+func (m *mockApprovalSvc) Request(ctx context.Context, requesterID string, a domain.Approval) (*domain.Approval, error) {
+	args := m.Called(ctx, requesterID, a)
+	out, _ := args.Get(0).(*domain.Approval)
+	return out, args.Error(1)
+}
+
+func (m *mockApprovalSvc) List(ctx context.Context, status string) ([]domain.Approval, error) {
+	args := m.Called(ctx, status)
+	out, _ := args.Get(0).([]domain.Approval)
+	return out, args.Error(1)
+}
+
+func (m *mockApprovalSvc) Approve(ctx context.Context, approverID, approvalID string) (*domain.Approval, error) {
+	args := m.Called(ctx, approverID, approvalID)
+	out, _ := args.Get(0).(*domain.Approval)
+	return out, args.Error(1)
+}
+
+func (m *mockApprovalSvc) Reject(ctx context.Context, deciderID, approvalID, reason string) (*domain.Approval, error) {
+	args := m.Called(ctx, deciderID, approvalID, reason)
+	out, _ := args.Get(0).(*domain.Approval)
+	return out, args.Error(1)
+}
+
+func TestChangeRole_GrantAdminIsHeldForApproval(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	approvals := &mockApprovalSvc{}
+	req := domain.ChangeRoleRequest{Role: domain.RoleAdmin}
+	want := domain.Approval{Action: domain.ApprovalGrantAdmin, TargetID: "u2", ChangeRole: &req}
+	approvals.On("Request", mock.Anything, "admin1", want).
+		Return(&domain.Approval{ApprovalID: "ap1", Status: domain.ApprovalPending}, nil)
+	h := NewUserHandler(svc, approvals)
+	body, _ := json.Marshal(req)
+
+	r := withChiID(bearerReq(t, p, http.MethodPut, "/v1/admin/users/u2/role", "admin1", domain.RoleAdmin, body), "u2")
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.ChangeRole), rr, r)
+
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	var got domain.Approval
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, "ap1", got.ApprovalID)
+	svc.AssertNotCalled(t, "ChangeRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangeRole_NonAdminRoleSkipsApproval(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	approvals := &mockApprovalSvc{}
+	req := domain.ChangeRoleRequest{Role: "Editor"}
+	svc.On("ChangeRole", mock.Anything, "admin1", "u2", req).Return(&domain.User{UserID: "u2", Role: "Editor"}, nil)
+	h := NewUserHandler(svc, approvals)
+	body, _ := json.Marshal(req)
+
+	r := withChiID(bearerReq(t, p, http.MethodPut, "/v1/admin/users/u2/role", "admin1", domain.RoleAdmin, body), "u2")
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.ChangeRole), rr, r)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	approvals.AssertNotCalled(t, "Request", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDelete_OtherUserIsHeldButSelfDeleteIsNot(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	approvals := &mockApprovalSvc{}
+	want := domain.Approval{Action: domain.ApprovalUserDelete, TargetID: "u2"}
+	approvals.On("Request", mock.Anything, "admin1", want).Return(&domain.Approval{ApprovalID: "ap1"}, nil)
+	svc.On("Delete", mock.Anything, "admin1").Return(nil)
+	h := NewUserHandler(svc, approvals)
+
+	rr := httptest.NewRecorder()
+	r := withChiID(bearerReq(t, p, http.MethodDelete, "/v1/users/u2", "admin1", domain.RoleAdmin, nil), "u2")
+	serveAuthed(p, http.HandlerFunc(h.Delete), rr, r)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+
+	rr = httptest.NewRecorder()
+	r = withChiID(bearerReq(t, p, http.MethodDelete, "/v1/users/admin1", "admin1", domain.RoleAdmin, nil), "admin1")
+	serveAuthed(p, http.HandlerFunc(h.Delete), rr, r)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	svc.AssertExpectations(t)
+	approvals.AssertExpectations(t)
+}
+
+func TestBulkNeedsApproval(t *testing.T) {
+	assert.True(t, bulkNeedsApproval(domain.BulkUserRequest{Action: domain.BulkDisable}))
+	assert.True(t, bulkNeedsApproval(domain.BulkUserRequest{Action: domain.BulkDelete}))
+	assert.True(t, bulkNeedsApproval(domain.BulkUserRequest{Action: domain.BulkSetRole, Role: domain.RoleAdmin}))
+	assert.False(t, bulkNeedsApproval(domain.BulkUserRequest{Action: domain.BulkSetRole, Role: "Editor"}))
+	assert.False(t, bulkNeedsApproval(domain.BulkUserRequest{Action: domain.BulkEnable}))
+}
+
+func TestApprovalList_RejectsUnknownStatus(t *testing.T) {
+	h := NewApprovalHandler(&mockApprovalSvc{})
+	rr := httptest.NewRecorder()
+
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/v1/admin/approvals?status=done", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestApprovalApprove_SameAdminIsForbidden(t *testing.T) {
+	p := newTestJWTProvider(t)
+	approvals := &mockApprovalSvc{}
+	approvals.On("Approve", mock.Anything, "admin1", "ap1").
+		Return(nil, fmt.Errorf("a second admin must approve this request: %w", domain.ErrForbidden))
+	h := NewApprovalHandler(approvals)
+
+	r := withChiID(bearerReq(t, p, http.MethodPost, "/v1/admin/approvals/ap1/approve", "admin1", domain.RoleAdmin, nil), "ap1")
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.Approve), rr, r)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestApprovalReject_BodyIsOptional(t *testing.T) {
+	p := newTestJWTProvider(t)
+	approvals := &mockApprovalSvc{}
+	approvals.On("Reject", mock.Anything, "admin2", "ap1", "").
+		Return(&domain.Approval{ApprovalID: "ap1", Status: domain.ApprovalRejected}, nil)
+	h := NewApprovalHandler(approvals)
+
+	r := withChiID(bearerReq(t, p, http.MethodPost, "/v1/admin/approvals/ap1/reject", "admin2", domain.RoleAdmin, nil), "ap1")
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.Reject), rr, r)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	approvals.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/appversion"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-chi/chi/v5"
+)
+
+// AppVersionHandler handles the admin app-version endpoints.
+type AppVersionHandler struct {
+	svc appversion.Service
+}
+
+func NewAppVersionHandler(svc appversion.Service) *AppVersionHandler {
+	return &AppVersionHandler{svc: svc}
+}
+
+func (h *AppVersionHandler) List(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.svc.List(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, versions)
+}
+
+func (h *AppVersionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.AppVersionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&input); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	created, err := h.svc.Create(r.Context(), input)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (h *AppVersionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input domain.AppVersionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&input); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	updated, err := h.svc.Update(r.Context(), chi.URLParam(r, "id"), input)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/backup"
+)
+
+// BackupHandler reports whether the tables can be restored.
+type BackupHandler struct {
+	svc backup.Service
+}
+
+func NewBackupHandler(svc backup.Service) *BackupHandler {
+	return &BackupHandler{svc: svc}
+}
+
+// Status serves GET /v1/admin/backups. It checks every table now rather than
+// returning the last scheduled check.
+func (h *BackupHandler) Status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.svc.Check(r.Context()))
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/billing"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+)
+
+// maxWebhookBody caps webhook deliveries; subscription events are a few KiB.
+const maxWebhookBody = 256 << 10
+
+// BillingHandler handles the Stripe webhook and the customer-portal link.
+type BillingHandler struct {
+	svc billing.Service
+}
+
+func NewBillingHandler(svc billing.Service) *BillingHandler { return &BillingHandler{svc: svc} }
+
+// StripeWebhook handles POST /v1/webhooks/stripe. The raw body is needed to
+// check the Stripe-Signature header, so it is read before any decoding.
+func (h *BillingHandler) StripeWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	if err := h.svc.HandleWebhook(r.Context(), payload, r.Header.Get("Stripe-Signature")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "received"})
+}
+
+// BillingPortalEnvelope is the response for POST /v1/users/me/billing-portal.
+type BillingPortalEnvelope struct {
+	URL string `json:"url"`
+}
+
+// Portal handles POST /v1/users/me/billing-portal.
+func (h *BillingHandler) Portal(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	url, err := h.svc.PortalURL(r.Context(), claims.UserID)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, BillingPortalEnvelope{URL: url})
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/broadcast"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// BroadcastHandler previews, creates, inspects and cancels announcements sent
+// to a segment of the enabled users. It is only mounted when notifications
+// are enabled.
+type BroadcastHandler struct {
+	svc broadcast.Service
+}
+
+func NewBroadcastHandler(svc broadcast.Service) *BroadcastHandler {
+	return &BroadcastHandler{svc: svc}
+}
+
+// BroadcastsEnvelope is the response for GET /v1/admin/broadcasts.
+type BroadcastsEnvelope struct {
+	Data []domain.Broadcast `json:"data"`
+}
+
+// List serves GET /v1/admin/broadcasts?status=.
+func (h *BroadcastHandler) List(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", domain.BroadcastPending, domain.BroadcastRunning, domain.BroadcastDone,
+		domain.BroadcastCanceled, domain.BroadcastFailed:
+	default:
+		writeError(w, http.StatusBadRequest, "status must be pending, running, done, canceled or failed")
+		return
+	}
+	broadcasts, err := h.svc.List(r.Context(), status)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	if broadcasts == nil {
+		broadcasts = []domain.Broadcast{}
+	}
+	writeJSON(w, http.StatusOK, BroadcastsEnvelope{Data: broadcasts})
+}
+
+// Create serves POST /v1/admin/broadcasts. The broadcast starts pending and
+// is fanned out by a background job.
+func (h *BroadcastHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.CreateBroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	b, err := h.svc.Create(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, b)
+}
+
+// Preview serves POST /v1/admin/broadcasts/preview: the estimated recipients
+// of a broadcast to the segment in the body, sending nothing.
+func (h *BroadcastHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	var seg domain.BroadcastSegment
+	if err := json.NewDecoder(r.Body).Decode(&seg); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&seg); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	preview, err := h.svc.Preview(r.Context(), seg)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, preview)
+}
+
+// Get serves GET /v1/admin/broadcasts/{id}, including the delivery report.
+func (h *BroadcastHandler) Get(w http.ResponseWriter, r *http.Request) {
+	b, err := h.svc.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+// Cancel serves POST /v1/admin/broadcasts/{id}/cancel.
+func (h *BroadcastHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	b, err := h.svc.Cancel(r.Context(), claims.UserID, chi.URLParam(r, "id"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
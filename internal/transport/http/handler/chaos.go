@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/pkg/chaos"
+	"github.com/go-api-nosql/internal/pkg/validate"
+)
+
+type chaosRules interface {
+	Rules() []chaos.Rule
+	Set(rules []chaos.Rule)
+}
+
+// ChaosEnvelope is the body and response of /v1/admin/chaos.
+type ChaosEnvelope struct {
+	Rules []chaos.Rule `json:"rules" validate:"max=50,dive"`
+}
+
+// ChaosHandler manages the fault-injection rules. It is only mounted when
+// CHAOS_INJECTION is on outside production.
+type ChaosHandler struct {
+	rules chaosRules
+}
+
+func NewChaosHandler(rules chaosRules) *ChaosHandler {
+	return &ChaosHandler{rules: rules}
+}
+
+// Get serves GET /v1/admin/chaos.
+func (h *ChaosHandler) Get(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, ChaosEnvelope{Rules: h.rules.Rules()})
+}
+
+// Put serves PUT /v1/admin/chaos, replacing every rule.
+func (h *ChaosHandler) Put(w http.ResponseWriter, r *http.Request) {
+	var input ChaosEnvelope
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&input); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	for _, rule := range input.Rules {
+		if !rule.Fail && rule.LatencyMS == 0 {
+			writeError(w, http.StatusUnprocessableEntity, "each rule needs latency_ms, fail or both")
+			return
+		}
+	}
+	h.rules.Set(input.Rules)
+	writeJSON(w, http.StatusOK, ChaosEnvelope{Rules: h.rules.Rules()})
+}
+
+// Clear serves DELETE /v1/admin/chaos.
+func (h *ChaosHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	h.rules.Set(nil)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-api-nosql/internal/pkg/chaos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosHandler_PutReplacesRules(t *testing.T) {
+	c := chaos.NewController()
+	h := NewChaosHandler(c)
+	body := `{"rules":[{"target":"dynamodb","match":"GetItem","percent":25,"fail":true}]}`
+
+	rec := httptest.NewRecorder()
+	h.Put(rec, httptest.NewRequest(http.MethodPut, "/v1/admin/chaos", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got ChaosEnvelope
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, []chaos.Rule{{Target: "dynamodb", Match: "GetItem", Percent: 25, Fail: true}}, got.Rules)
+	assert.Equal(t, got.Rules, c.Rules())
+}
+
+func TestChaosHandler_PutRejectsInvalidRules(t *testing.T) {
+	for _, body := range []string{
+		`{"rules":[{"target":"smtp","percent":10,"fail":true}]}`,
+		`{"rules":[{"target":"http","percent":0,"fail":true}]}`,
+		`{"rules":[{"target":"http","percent":10}]}`,
+	} {
+		rec := httptest.NewRecorder()
+		NewChaosHandler(chaos.NewController()).Put(rec, httptest.NewRequest(http.MethodPut, "/v1/admin/chaos", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, body)
+	}
+}
+
+func TestChaosHandler_Clear(t *testing.T) {
+	c := chaos.NewController()
+	c.Set([]chaos.Rule{{Target: "http", Percent: 100, Fail: true}})
+
+	rec := httptest.NewRecorder()
+	NewChaosHandler(c).Clear(rec, httptest.NewRequest(http.MethodDelete, "/v1/admin/chaos", nil))
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, c.Rules())
+}
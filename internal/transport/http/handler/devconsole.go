@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-api-nosql/internal/application/devconsole"
+	"github.com/go-api-nosql/internal/infrastructure/smtp"
+	"github.com/go-api-nosql/internal/pkg/validate"
+)
+
+// DevConsoleHandler serves the development-only console API under /dev/api.
+type DevConsoleHandler struct {
+	svc devconsole.Service
+}
+
+func NewDevConsoleHandler(svc devconsole.Service) *DevConsoleHandler {
+	return &DevConsoleHandler{svc: svc}
+}
+
+// DevSeedEnvelope is the response of POST /dev/api/users.
+type DevSeedEnvelope struct {
+	User     *SafeUser          `json:"user"`
+	Password string             `json:"password"`
+	Tokens   *devconsole.Tokens `json:"tokens"`
+}
+
+// DevEmailsEnvelope is the response of GET /dev/emails.
+type DevEmailsEnvelope struct {
+	Data []smtp.Email `json:"data"`
+}
+
+type emailOutbox interface {
+	Messages() []smtp.Email
+}
+
+// DevEmailsHandler exposes mail captured instead of (or as well as) being
+// sent, so tests and QA can read OTP codes. Never mounted in production.
+type DevEmailsHandler struct {
+	outbox emailOutbox
+}
+
+func NewDevEmailsHandler(outbox emailOutbox) *DevEmailsHandler {
+	return &DevEmailsHandler{outbox: outbox}
+}
+
+// List serves GET /dev/emails?to=, newest first; to filters by recipient.
+func (h *DevEmailsHandler) List(w http.ResponseWriter, r *http.Request) {
+	emails := h.outbox.Messages()
+	if to := r.URL.Query().Get("to"); to != "" {
+		matching := make([]smtp.Email, 0, len(emails))
+		for _, e := range emails {
+			if strings.EqualFold(e.To, to) {
+				matching = append(matching, e)
+			}
+		}
+		emails = matching
+	}
+	writeJSON(w, http.StatusOK, DevEmailsEnvelope{Data: emails})
+}
+
+// SeedUser serves POST /dev/api/users {"role": "Admin"}; an empty body seeds a User.
+func (h *DevConsoleHandler) SeedUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Role string `json:"role" validate:"max=50"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	res, err := h.svc.SeedUser(r.Context(), req.Role)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, DevSeedEnvelope{User: toSafeUser(res.User), Password: res.Password, Tokens: res.Tokens})
+}
+
+// MintToken serves POST /dev/api/tokens {"user_id": "..."}.
+func (h *DevConsoleHandler) MintToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id" validate:"required,max=64"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	tokens, err := h.svc.MintToken(r.Context(), req.UserID)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/infrastructure/smtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevEmails_FiltersByRecipient(t *testing.T) {
+	outbox := smtp.NewOutbox(nil)
+	require.NoError(t, outbox.SendEmail("jane@example.com", "code", "123456"))
+	require.NoError(t, outbox.SendEmail("bob@example.com", "code", "654321"))
+	h := NewDevEmailsHandler(outbox)
+
+	rec := httptest.NewRecorder()
+	h.List(rec, httptest.NewRequest(http.MethodGet, "/dev/emails?to=Jane@example.com", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body DevEmailsEnvelope
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "123456", body.Data[0].Body)
+}
+
+func TestDevEmails_AllNewestFirst(t *testing.T) {
+	outbox := smtp.NewOutbox(nil)
+	require.NoError(t, outbox.SendEmail("jane@example.com", "first", ""))
+	require.NoError(t, outbox.SendEmail("bob@example.com", "second", ""))
+
+	rec := httptest.NewRecorder()
+	NewDevEmailsHandler(outbox).List(rec, httptest.NewRequest(http.MethodGet, "/dev/emails", nil))
+
+	var body DevEmailsEnvelope
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data, 2)
+	assert.Equal(t, "second", body.Data[0].Subject)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/auth"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// DeviceVerificationHandler handles the step-up that trusts the caller's
+// device before a password or email change.
+type DeviceVerificationHandler struct {
+	svc auth.DeviceVerificationService
+}
+
+func NewDeviceVerificationHandler(svc auth.DeviceVerificationService) *DeviceVerificationHandler {
+	return &DeviceVerificationHandler{svc: svc}
+}
+
+func (h *DeviceVerificationHandler) Action(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	switch chi.URLParam(r, "action") {
+	case "request":
+		if err := h.svc.RequestDeviceVerification(r.Context(), claims.UserID); err != nil {
+			httpError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "verification email sent"})
+	case "validate-code":
+		var body struct {
+			OTP string `json:"otp"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if err := h.svc.ValidateDeviceOTP(r.Context(), claims.UserID, claims.DeviceID, body.OTP); err != nil {
+			httpError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "device verified"})
+	default:
+		writeError(w, http.StatusBadRequest, "unknown action")
+	}
+}
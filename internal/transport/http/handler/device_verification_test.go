@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDeviceVerificationSvc struct{ mock.Mock }
+
+func (m *mockDeviceVerificationSvc) RequestDeviceVerification(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *mockDeviceVerificationSvc) ValidateDeviceOTP(ctx context.Context, userID, deviceID, otp string) error {
+	return m.Called(ctx, userID, deviceID, otp).Error(0)
+}
+
+// withChiAction sets the {action} URL parameter chi would have matched.
+func withChiAction(r *http.Request, action string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("action", action)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestDeviceVerification_StepUpUnlocksPasswordChange(t *testing.T) {
+	p := newTestJWTProvider(t)
+	users := &mockUserSvc{}
+	users.On("RequireTrustedDevice", mock.Anything, "dev1").Return(domain.ErrUntrustedDevice).Once()
+	users.On("RequireTrustedDevice", mock.Anything, "dev1").Return(nil).Once()
+	users.On("ChangePassword", mock.Anything, "u1", "oldpass1", "newpass123").Return(nil)
+	verify := &mockDeviceVerificationSvc{}
+	verify.On("RequestDeviceVerification", mock.Anything, "u1").Return(nil)
+	verify.On("ValidateDeviceOTP", mock.Anything, "u1", "dev1", "ABC234").Return(nil)
+	uh := NewUserHandler(users, nil)
+	dh := NewDeviceVerificationHandler(verify)
+	change, _ := json.Marshal(ChangePasswordRequest{CurrentPassword: "oldpass1", NewPassword: "newpass123"})
+
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(uh.ChangePassword), rr, bearerReq(t, p, http.MethodPost, "/v1/users/me/password", "u1", domain.RoleUser, change))
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	var refused MessageEnvelope
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&refused))
+	assert.Equal(t, "device_verification_required", refused.Code)
+	assert.Contains(t, refused.Error, "/v1/device-verification/request")
+
+	rr = httptest.NewRecorder()
+	r := withChiAction(bearerReq(t, p, http.MethodPost, "/v1/device-verification/request", "u1", domain.RoleUser, nil), "request")
+	serveAuthed(p, http.HandlerFunc(dh.Action), rr, r)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	r = withChiAction(bearerReq(t, p, http.MethodPost, "/v1/device-verification/validate-code", "u1", domain.RoleUser, []byte(`{"otp":"ABC234"}`)), "validate-code")
+	serveAuthed(p, http.HandlerFunc(dh.Action), rr, r)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(uh.ChangePassword), rr, bearerReq(t, p, http.MethodPost, "/v1/users/me/password", "u1", domain.RoleUser, change))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	users.AssertExpectations(t)
+	verify.AssertExpectations(t)
+}
+
+func TestDeviceVerification_WrongCodeLeavesDeviceUntrusted(t *testing.T) {
+	p := newTestJWTProvider(t)
+	verify := &mockDeviceVerificationSvc{}
+	verify.On("ValidateDeviceOTP", mock.Anything, "u1", "dev1", "WRONG1").
+		Return(domain.ErrUnauthorized)
+	h := NewDeviceVerificationHandler(verify)
+
+	rr := httptest.NewRecorder()
+	r := withChiAction(bearerReq(t, p, http.MethodPost, "/v1/device-verification/validate-code", "u1", domain.RoleUser, []byte(`{"otp":"WRONG1"}`)), "validate-code")
+	serveAuthed(p, http.HandlerFunc(h.Action), rr, r)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-api-nosql/internal/application/device"
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
 	"github.com/go-api-nosql/internal/transport/http/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -25,98 +26,125 @@ func (h *DeviceHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 	devices, err := h.svc.List(r.Context(), claims.UserID)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, devices)
 }
 
 func (h *DeviceHandler) Get(w http.ResponseWriter, r *http.Request) {
-	claims, ok := middleware.ClaimsFromContext(r.Context())
+	d, ok := h.ownedDevice(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "unauthorized")
-		return
-	}
-	d, err := h.svc.Get(r.Context(), chi.URLParam(r, "id"))
-	if err != nil {
-		httpError(w, err)
-		return
-	}
-	if d.UserID != claims.UserID && claims.Role != domain.RoleAdmin {
-		writeError(w, http.StatusForbidden, "forbidden")
 		return
 	}
 	writeJSON(w, http.StatusOK, d)
 }
 
 func (h *DeviceHandler) Update(w http.ResponseWriter, r *http.Request) {
-	claims, ok := middleware.ClaimsFromContext(r.Context())
+	d, ok := h.ownedDevice(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
-	deviceID := chi.URLParam(r, "id")
-	d, err := h.svc.Get(r.Context(), deviceID)
+	var req domain.UpdateDeviceRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	updated, err := h.svc.Update(r.Context(), d.DeviceID, req)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
-	if d.UserID != claims.UserID && claims.Role != domain.RoleAdmin {
-		writeError(w, http.StatusForbidden, "forbidden")
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// RotateToken replaces a device's push token, e.g. after the OS issues a new one.
+func (h *DeviceHandler) RotateToken(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.ownedDevice(w, r)
+	if !ok {
 		return
 	}
-	var req domain.UpdateDeviceRequest
+	var req domain.RotateDeviceTokenRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	updated, err := h.svc.Update(r.Context(), deviceID, req)
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	updated, err := h.svc.RotateToken(r.Context(), d.DeviceID, req.Token)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, updated)
 }
 
 func (h *DeviceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.ownedDevice(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), d.DeviceID); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "device deleted"})
+}
+
+// ownedDevice loads the {id} device and checks the caller may act on it:
+// its owner or an admin. Deleted devices are not found for their owner. It
+// writes the error response and returns false otherwise.
+func (h *DeviceHandler) ownedDevice(w http.ResponseWriter, r *http.Request) (*domain.Device, bool) {
 	claims, ok := middleware.ClaimsFromContext(r.Context())
 	if !ok {
 		writeError(w, http.StatusUnauthorized, "unauthorized")
-		return
+		return nil, false
 	}
-	deviceID := chi.URLParam(r, "id")
-	d, err := h.svc.Get(r.Context(), deviceID)
+	d, err := h.svc.Get(r.Context(), chi.URLParam(r, "id"))
 	if err != nil {
-		httpError(w, err)
-		return
+		httpError(w, r, err)
+		return nil, false
 	}
-	if d.UserID != claims.UserID && claims.Role != domain.RoleAdmin {
+	if claims.Role == domain.RoleAdmin {
+		return d, true
+	}
+	if d.UserID != claims.UserID {
 		writeError(w, http.StatusForbidden, "forbidden")
-		return
+		return nil, false
 	}
-	if err := h.svc.Delete(r.Context(), deviceID); err != nil {
-		httpError(w, err)
-		return
+	if !d.Enable {
+		writeError(w, http.StatusNotFound, "device not found")
+		return nil, false
 	}
-	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "device deleted"})
+	return d, true
 }
 
 func (h *DeviceHandler) CheckVersion(w http.ResponseWriter, r *http.Request) {
-	var body struct {
-		DeviceVersion float64 `json:"device_version"`
-	}
+	var body domain.CheckVersionRequest
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if err := validate.Struct(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
 	claims, ok := middleware.ClaimsFromContext(r.Context())
 	if !ok {
 		writeError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
-	upToDate, err := h.svc.CheckVersion(r.Context(), claims.SessionID, body.DeviceVersion)
+	upToDate, err := h.svc.CheckVersion(r.Context(), claims.SessionID, body.Platform, body.DeviceVersion)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	if !upToDate {
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-api-nosql/internal/pkg/dbcost"
+)
+
+// costLedger is satisfied by *dbcost.Ledger.
+type costLedger interface {
+	Snapshot() []dbcost.RouteCost
+	Reset()
+}
+
+// DynamoCostHandler serves the DynamoDB capacity consumed per route.
+type DynamoCostHandler struct {
+	ledger costLedger
+}
+
+func NewDynamoCostHandler(ledger costLedger) *DynamoCostHandler {
+	return &DynamoCostHandler{ledger: ledger}
+}
+
+// List serves GET /v1/admin/dynamo-costs: this instance's routes, most
+// capacity consumed first.
+func (h *DynamoCostHandler) List(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"routes": h.ledger.Snapshot()})
+}
+
+// Reset serves DELETE /v1/admin/dynamo-costs.
+func (h *DynamoCostHandler) Reset(w http.ResponseWriter, _ *http.Request) {
+	h.ledger.Reset()
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "dynamo costs reset"})
+}
@@ -27,7 +27,13 @@ func (h *EmailConfirmHandler) Action(w http.ResponseWriter, r *http.Request) {
 	switch chi.URLParam(r, "action") {
 	case "request":
 		if err := h.svc.RequestEmailConfirmation(r.Context(), claims.UserID); err != nil {
-			httpError(w, err)
+			httpError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "confirmation email sent"})
+	case "resend":
+		if err := h.svc.ResendEmailConfirmation(r.Context(), claims.UserID); err != nil {
+			resendError(w, r, err)
 			return
 		}
 		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "confirmation email sent"})
@@ -40,7 +46,7 @@ func (h *EmailConfirmHandler) Action(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if err := h.svc.ValidateEmailToken(r.Context(), claims.UserID, body.Token); err != nil {
-			httpError(w, err)
+			httpError(w, r, err)
 			return
 		}
 		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "email confirmed"})
@@ -3,11 +3,16 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
+	"strconv"
 	"time"
 
+	"github.com/go-api-nosql/internal/application/auth"
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
 )
 
 // SafeUser is the full user DTO returned to the owner or an admin.
@@ -17,66 +22,165 @@ type SafeUser struct {
 	Email          string    `json:"email"`
 	Phone          *string   `json:"phone,omitempty"`
 	Role           string    `json:"role"`
+	Plan           string    `json:"plan"`
 	FirstName      string    `json:"first_name"`
 	LastName       string    `json:"last_name"`
 	Birthday       string    `json:"birthday,omitempty"`
+	Timezone       string    `json:"timezone,omitempty"`
+	Locale         string    `json:"locale,omitempty"`
 	Verified       bool      `json:"verified"`
 	EmailConfirmed bool      `json:"email_confirmed"`
 	PhoneConfirmed bool      `json:"phone_confirmed"`
+	PublicProfile  bool      `json:"public_profile"`
+	OnboardOptOut  bool      `json:"onboarding_opt_out"`
+	PasswordChange bool      `json:"password_change_required,omitempty"`
+	ProfileDone    bool      `json:"profile_complete"`
+	MissingFields  []string  `json:"missing_profile_fields,omitempty"`
 	Enable         bool      `json:"enable"`
 	CreatedAt      time.Time `json:"created"`
 	UpdatedAt      time.Time `json:"updated"`
+	// DisplayName, Initials and AvatarColor are derived from the names so
+	// every client renders the user the same way.
+	DisplayName string `json:"display_name"`
+	Initials    string `json:"initials"`
+	AvatarColor string `json:"avatar_color"`
+	AvatarURL   string `json:"avatar_url,omitempty"` // Gravatar image, when GRAVATAR allows it
+	// Metadata holds the deployment's custom profile fields.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
-// PublicUser is the reduced user DTO returned to other authenticated users.
+// PublicUser is the reduced user DTO returned to other users and on public profiles.
 type PublicUser struct {
-	UserID    string `json:"id"`
-	Username  string `json:"username"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	UserID      string `json:"id"`
+	Username    string `json:"username"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	DisplayName string `json:"display_name"`
+	Initials    string `json:"initials"`
+	AvatarColor string `json:"avatar_color"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+}
+
+// nameFormat is the display name template of user DTOs; see SetNameFormat.
+var nameFormat = domain.DefaultNameFormat
+
+// SetNameFormat sets the template (see domain.ValidateNameFormat) the
+// display_name of every user DTO is rendered with; empty restores
+// domain.DefaultNameFormat. Call it before serving.
+func SetNameFormat(format string) error {
+	if format == "" {
+		format = domain.DefaultNameFormat
+	}
+	if err := domain.ValidateNameFormat(format); err != nil {
+		return err
+	}
+	nameFormat = format
+	return nil
+}
+
+// gravatar is the domain.Gravatar* mode of user DTOs; see SetGravatar.
+var gravatar = domain.GravatarOff
+
+// SetGravatar sets which user DTOs carry a Gravatar avatar_url: none
+// (domain.GravatarOff, or empty), SafeUser only (domain.GravatarOwner) or
+// PublicUser too (domain.GravatarPublic). The email hash is computed here so
+// clients never need another user's address. Call it before serving.
+func SetGravatar(mode string) error {
+	switch mode {
+	case "":
+		mode = domain.GravatarOff
+	case domain.GravatarOff, domain.GravatarOwner, domain.GravatarPublic:
+	default:
+		return fmt.Errorf("gravatar: unknown mode %q", mode)
+	}
+	gravatar = mode
+	return nil
+}
+
+// avatarURL is u's avatar_url in a DTO shown to its owner or an admin when
+// owner is true, and to anyone otherwise.
+func avatarURL(u *domain.User, owner bool) string {
+	if gravatar == domain.GravatarPublic || (owner && gravatar == domain.GravatarOwner) {
+		return u.GravatarURL()
+	}
+	return ""
 }
 
 // SafeSession is the public-facing session DTO that omits RefreshToken, RefreshExpiresAt, and User.
 type SafeSession struct {
-	SessionID string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	DeviceID  *string   `json:"device_id"`
-	Enable    bool      `json:"enable"`
-	CreatedAt time.Time `json:"created"`
-	UpdatedAt time.Time `json:"updated"`
+	SessionID  string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	DeviceID   *string   `json:"device_id"`
+	Enable     bool      `json:"enable"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	AppVersion string    `json:"app_version,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	Country    string    `json:"country,omitempty"`
+	Pending    bool      `json:"pending_approval,omitempty"` // a high-risk login awaiting approval by email
+	Current    bool      `json:"current,omitempty"`          // the session of the bearer token, in session listings
+	ClientID   string    `json:"client_id,omitempty"`        // the OAuth client of a grant to a third-party app
+	Scopes     []string  `json:"scopes,omitempty"`
+	CreatedAt  time.Time `json:"created"`
+	UpdatedAt  time.Time `json:"updated"`
 }
 
 func toSafeUser(u *domain.User) *SafeUser {
 	if u == nil {
 		return nil
 	}
+	missing := u.MissingProfileFields()
 	return &SafeUser{
 		UserID:         u.UserID,
 		Username:       u.Username,
 		Email:          u.Email,
 		Phone:          u.Phone,
 		Role:           u.Role,
+		Plan:           planOf(u),
 		FirstName:      u.FirstName,
 		LastName:       u.LastName,
 		Birthday:       formatDate(u.Birthday),
+		Timezone:       u.Timezone,
+		Locale:         u.Locale,
 		Verified:       u.Verified,
 		EmailConfirmed: u.EmailConfirmed,
 		PhoneConfirmed: u.PhoneConfirmed,
+		PublicProfile:  u.PublicProfile,
+		OnboardOptOut:  u.OnboardOptOut,
+		PasswordChange: u.PasswordChange,
+		ProfileDone:    len(missing) == 0,
+		MissingFields:  missing,
 		Enable:         u.Enable == 1,
 		CreatedAt:      u.CreatedAt,
 		UpdatedAt:      u.UpdatedAt,
+		DisplayName:    u.DisplayName(nameFormat),
+		Initials:       u.Initials(),
+		AvatarColor:    u.AvatarColor(),
+		AvatarURL:      avatarURL(u, true),
+		Metadata:       u.Metadata,
 	}
 }
 
+// planOf is u's plan; users never assigned one are on domain.PlanFree.
+func planOf(u *domain.User) string {
+	if u.Plan == "" {
+		return domain.PlanFree
+	}
+	return u.Plan
+}
+
 func toPublicUser(u *domain.User) *PublicUser {
 	if u == nil {
 		return nil
 	}
 	return &PublicUser{
-		UserID:    u.UserID,
-		Username:  u.Username,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
+		UserID:      u.UserID,
+		Username:    u.Username,
+		FirstName:   u.FirstName,
+		LastName:    u.LastName,
+		DisplayName: u.DisplayName(nameFormat),
+		Initials:    u.Initials(),
+		AvatarColor: u.AvatarColor(),
+		AvatarURL:   avatarURL(u, false),
 	}
 }
 
@@ -89,30 +193,47 @@ func toSafeSession(s *domain.Session) *SafeSession {
 		deviceID = &s.DeviceID
 	}
 	return &SafeSession{
-		SessionID: s.SessionID,
-		UserID:    s.UserID,
-		DeviceID:  deviceID,
-		Enable:    s.Enable,
-		CreatedAt: s.CreatedAt,
-		UpdatedAt: s.UpdatedAt,
+		SessionID:  s.SessionID,
+		UserID:     s.UserID,
+		DeviceID:   deviceID,
+		Enable:     s.Enable,
+		UserAgent:  s.UserAgent,
+		AppVersion: s.AppVersion,
+		IP:         s.IP,
+		Country:    s.Country,
+		Pending:    s.PendingApproval(),
+		ClientID:   s.ClientID,
+		Scopes:     s.Scopes,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
 	}
 }
 
+// ReadinessEnvelope is the health-check "ready" response. Backups is the
+// latest backup verification, absent until the first check completes.
+type ReadinessEnvelope struct {
+	Message string               `json:"message"`
+	Backups *domain.BackupReport `json:"backups,omitempty"`
+}
+
 // MessageEnvelope is the generic response wrapper.
 type MessageEnvelope struct {
 	Message   string `json:"message,omitempty"`
 	Error     string `json:"error,omitempty"`
 	ErrorCode int    `json:"error_code,omitempty"`
+	Code      string `json:"code,omitempty"`   // stable identifier of errors clients handle specially, e.g. "underage"
+	Detail    string `json:"detail,omitempty"` // full error text; only with ERROR_DETAILS outside production
 }
 
 // AuthEnvelope wraps login/register responses.
 type AuthEnvelope struct {
-	AccessToken  string       `json:"access_token,omitempty"`
-	RefreshToken string       `json:"refresh_token,omitempty"`
-	Session      *SafeSession `json:"session,omitempty"`
-	User         *SafeUser    `json:"user,omitempty"`
-	Message      string       `json:"message,omitempty"`
-	Error        string       `json:"error,omitempty"`
+	AccessToken   string       `json:"access_token,omitempty"`
+	RefreshToken  string       `json:"refresh_token,omitempty"`
+	RememberToken string       `json:"remember_token,omitempty"`
+	Session       *SafeSession `json:"session,omitempty"`
+	User          *SafeUser    `json:"user,omitempty"`
+	Message       string       `json:"message,omitempty"`
+	Error         string       `json:"error,omitempty"`
 }
 
 // SessionEnvelope wraps current-session responses.
@@ -124,11 +245,14 @@ type SessionEnvelope struct {
 }
 
 // CursorUsersEnvelope wraps cursor-paginated user list responses.
+// ApproxTotal is an estimate for page indicators and is omitted when the
+// estimate is unavailable.
 type CursorUsersEnvelope struct {
-	Data       []*SafeUser `json:"data"`
-	Returned   int         `json:"returned"`
-	NextCursor string      `json:"next_cursor,omitempty"`
-	Error      string      `json:"error,omitempty"`
+	Data        []*SafeUser `json:"data"`
+	Returned    int         `json:"returned"`
+	NextCursor  string      `json:"next_cursor,omitempty"`
+	ApproxTotal *int64      `json:"approx_total,omitempty"`
+	Error       string      `json:"error,omitempty"`
 }
 
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
@@ -142,23 +266,103 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 }
 
 // httpError maps domain sentinel errors to HTTP status codes.
-// Infrastructure errors (DynamoDB, S3, etc.) are hidden behind a generic 500 message.
-func httpError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, domain.ErrNotFound):
-		writeError(w, http.StatusNotFound, err.Error())
-	case errors.Is(err, domain.ErrConflict):
-		writeError(w, http.StatusConflict, err.Error())
-	case errors.Is(err, domain.ErrUnauthorized):
-		writeError(w, http.StatusUnauthorized, err.Error())
-	case errors.Is(err, domain.ErrForbidden):
-		writeError(w, http.StatusForbidden, err.Error())
-	case errors.Is(err, domain.ErrBadRequest):
-		writeError(w, http.StatusBadRequest, err.Error())
-	default:
+// Infrastructure errors (DynamoDB, S3, etc.) are hidden behind a generic 500
+// message; see clientMessage for the text of the 4xx responses. When the
+// request was marked by the ErrorDetails middleware, the full error is added
+// as "detail".
+func httpError(w http.ResponseWriter, r *http.Request, err error) {
+	status, msg := http.StatusInternalServerError, "internal server error"
+	for _, m := range errorStatuses {
+		if errors.Is(err, m.sentinel) {
+			status, msg = m.status, clientMessage(err, m.sentinel)
+			break
+		}
+	}
+	switch status {
+	case http.StatusServiceUnavailable:
+		slog.Warn("backend unavailable", "error", err)
+		w.Header().Set("Retry-After", "1")
+		msg = "service temporarily unavailable, retry shortly"
+	case http.StatusInternalServerError:
 		slog.Error("internal server error", "error", err)
-		writeError(w, http.StatusInternalServerError, "internal server error")
 	}
+	env := MessageEnvelope{Error: msg, Code: errorCode(err)}
+	if middleware.ErrorDetailsEnabled(r.Context()) {
+		env.Detail = err.Error()
+	}
+	writeJSON(w, status, env)
+}
+
+// CooldownEnvelope is the 429 body of a confirmation resend made too soon.
+type CooldownEnvelope struct {
+	Error      string `json:"error"`
+	RetryAfter int    `json:"retry_after"` // seconds until a resend is accepted
+}
+
+// resendError is httpError for the confirmation resend actions: a cooldown
+// is answered with Retry-After and the remaining seconds in the body.
+func resendError(w http.ResponseWriter, r *http.Request, err error) {
+	var cd *auth.CooldownError
+	if !errors.As(err, &cd) {
+		httpError(w, r, err)
+		return
+	}
+	secs := cd.Seconds()
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	writeJSON(w, http.StatusTooManyRequests, CooldownEnvelope{
+		Error:      "a code was sent recently, retry later",
+		RetryAfter: secs,
+	})
+}
+
+var errorStatuses = []struct {
+	sentinel error
+	status   int
+}{
+	{domain.ErrNotFound, http.StatusNotFound},
+	{domain.ErrConflict, http.StatusConflict},
+	{domain.ErrUnauthorized, http.StatusUnauthorized},
+	{domain.ErrForbidden, http.StatusForbidden},
+	{domain.ErrBadRequest, http.StatusBadRequest},
+	{domain.ErrTooMany, http.StatusTooManyRequests},
+	{domain.ErrUnavailable, http.StatusServiceUnavailable},
+	{domain.ErrPrecondition, http.StatusPreconditionRequired},
+}
+
+// infraDetail matches error text that names AWS operations, resources or
+// request IDs, as the SDK errors wrapped by the repositories do.
+var infraDetail = regexp.MustCompile(`operation error|api error|RequestID|StatusCode:|arn:aws|amazonaws\.com|dynamodb|DynamoDB|S3:`)
+
+// errorCodes give clients a stable "code" for errors they handle specially.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{domain.ErrUnderage, "underage"},
+	{domain.ErrWeakPassword, "weak_password"},
+	{domain.ErrBreachedPassword, "breached_password"},
+	{domain.ErrUntrustedDevice, "device_verification_required"},
+}
+
+// errorCode is the "code" of err, or "" when it has none.
+func errorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ""
+}
+
+// clientMessage is the text shown for err, a wrapped sentinel. Services word
+// their own errors for clients ("email already registered: conflict"), but a
+// translated DynamoDB failure carries the SDK message, so such text is
+// replaced by the sentinel's.
+func clientMessage(err, sentinel error) string {
+	if msg := err.Error(); !infraDetail.MatchString(msg) {
+		return msg
+	}
+	return sentinel.Error()
 }
 
 // formatDate formats a time.Time as "yyyy-mm-dd". Returns "" for zero time.
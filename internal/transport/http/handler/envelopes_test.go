@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func errorResponse(t *testing.T, r *http.Request, err error) (int, MessageEnvelope) {
+	rec := httptest.NewRecorder()
+	httpError(rec, r, err)
+	var env MessageEnvelope
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	return rec.Code, env
+}
+
+func TestHTTPError_RedactsInfrastructureDetail(t *testing.T) {
+	sdk := errors.New("operation error DynamoDB: PutItem, https response error StatusCode: 400, RequestID: ABC, ConditionalCheckFailedException")
+	cases := []struct {
+		err    error
+		status int
+		msg    string
+	}{
+		{fmt.Errorf("email already registered: %w", domain.ErrConflict), http.StatusConflict, "email already registered: conflict"},
+		{fmt.Errorf("put user: %w", errors.Join(domain.ErrConflict, sdk)), http.StatusConflict, "conflict"},
+		{fmt.Errorf("get item from table users-prod: %w", sdk), http.StatusInternalServerError, "internal server error"},
+	}
+	for _, c := range cases {
+		status, env := errorResponse(t, httptest.NewRequest(http.MethodGet, "/", nil), c.err)
+
+		assert.Equal(t, c.status, status)
+		assert.Equal(t, c.msg, env.Error)
+		assert.Empty(t, env.Detail)
+	}
+}
+
+func TestHTTPError_DetailOnlyWithErrorDetails(t *testing.T) {
+	err := fmt.Errorf("get item from table users-prod: %w", errors.New("RequestID: ABC"))
+	var r *http.Request
+	middleware.ErrorDetails(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) { r = req })).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	status, env := errorResponse(t, r, err)
+
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "internal server error", env.Error)
+	assert.Equal(t, err.Error(), env.Detail)
+}
+
+func TestAvatarURL_FollowsGravatarMode(t *testing.T) {
+	t.Cleanup(func() { _ = SetGravatar("") })
+	u := &domain.User{UserID: "u1", Email: " Ada@Example.com"}
+
+	require.NoError(t, SetGravatar(domain.GravatarOff))
+	assert.Empty(t, toSafeUser(u).AvatarURL)
+
+	require.NoError(t, SetGravatar(domain.GravatarOwner))
+	assert.Equal(t, u.GravatarURL(), toSafeUser(u).AvatarURL)
+	assert.Empty(t, toPublicUser(u).AvatarURL)
+
+	require.NoError(t, SetGravatar(domain.GravatarPublic))
+	assert.Equal(t, u.GravatarURL(), toPublicUser(u).AvatarURL)
+
+	assert.Error(t, SetGravatar("always"))
+}
+
+func TestHTTPError_CodeOfUnderage(t *testing.T) {
+	err := fmt.Errorf("you must be at least 13 years old to register: %w", domain.ErrUnderage)
+
+	status, env := errorResponse(t, httptest.NewRequest(http.MethodPost, "/", nil), err)
+
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "underage", env.Code)
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/user"
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// ExportHandler streams admin CSV reports. Rows are written as each page is
+// read from DynamoDB, so memory stays flat regardless of the export size.
+type ExportHandler struct {
+	users user.Service
+	audit audit.Service
+}
+
+func NewExportHandler(users user.Service, audit audit.Service) *ExportHandler {
+	return &ExportHandler{users: users, audit: audit}
+}
+
+var userCSVHeader = []string{
+	"id", "username", "email", "phone", "role", "first_name", "last_name",
+	"enable", "email_confirmed", "phone_confirmed", "created", "deleted_at",
+}
+
+// Users serves GET /v1/admin/export/users.csv?role=&enabled=&email_confirmed=&from=&to=.
+func (h *ExportHandler) Users(w http.ResponseWriter, r *http.Request) {
+	f, err := parseUserFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	out := newCSVStream(w, r, "users.csv", userCSVHeader)
+	err = h.users.Export(r.Context(), f, func(u domain.User) error {
+		var phone, deletedAt string
+		if u.Phone != nil {
+			phone = *u.Phone
+		}
+		if u.DeletedAt != nil {
+			deletedAt = u.DeletedAt.UTC().Format(time.RFC3339)
+		}
+		return out.write(
+			u.UserID, u.Username, u.Email, phone, u.Role, u.FirstName, u.LastName,
+			strconv.FormatBool(u.Enable == 1), strconv.FormatBool(u.EmailConfirmed),
+			strconv.FormatBool(u.PhoneConfirmed), u.CreatedAt.UTC().Format(time.RFC3339), deletedAt,
+		)
+	})
+	out.finish(err)
+}
+
+// Audit serves GET /v1/admin/export/audit.csv?from=&to=&action=.
+func (h *ExportHandler) Audit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := domain.AuditFilter{From: q.Get("from"), To: q.Get("to"), Action: q.Get("action")}
+	out := newCSVStream(w, r, "audit.csv", []string{"id", "created", "action", "actor_id", "target_id", "details"})
+	err := h.audit.Export(r.Context(), f, func(e domain.AuditEntry) error {
+		var details []byte
+		if len(e.Details) > 0 {
+			var err error
+			if details, err = json.Marshal(e.Details); err != nil {
+				return err
+			}
+		}
+		return out.write(e.AuditID, e.CreatedAt.UTC().Format(time.RFC3339), e.Action, e.ActorID, e.TargetID, string(details))
+	})
+	out.finish(err)
+}
+
+// parseUserFilter reads the role, enabled, email_confirmed, from and to query
+// parameters shared by the user list and export. Dates are checked by the
+// service.
+func parseUserFilter(r *http.Request) (domain.UserFilter, error) {
+	q := r.URL.Query()
+	f := domain.UserFilter{Role: q.Get("role"), CreatedFrom: q.Get("from"), CreatedTo: q.Get("to")}
+	if v := q.Get("enabled"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("enabled must be true or false")
+		}
+		f.Enable = new(int)
+		if enabled {
+			*f.Enable = 1
+		}
+	}
+	if v := q.Get("email_confirmed"); v != "" {
+		confirmed, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("email_confirmed must be true or false")
+		}
+		f.EmailConfirmed = &confirmed
+	}
+	return f, nil
+}
+
+// csvStream writes CSV rows to an HTTP response. Nothing is sent until the
+// first row is written, so errors raised before any row (bad filters, a failed
+// first page) still get a proper JSON error response.
+type csvStream struct {
+	w        http.ResponseWriter
+	r        *http.Request
+	csv      *csv.Writer
+	filename string
+	header   []string
+	started  bool
+}
+
+func newCSVStream(w http.ResponseWriter, r *http.Request, filename string, header []string) *csvStream {
+	return &csvStream{w: w, r: r, csv: csv.NewWriter(w), filename: filename, header: header}
+}
+
+func (s *csvStream) write(fields ...string) error {
+	if !s.started {
+		s.start()
+	}
+	for i, f := range fields {
+		fields[i] = csvSafe(f)
+	}
+	return s.csv.Write(fields)
+}
+
+func (s *csvStream) start() {
+	s.started = true
+	// Large exports outlive the server's WriteTimeout; lift it for this response.
+	_ = http.NewResponseController(s.w).SetWriteDeadline(time.Time{})
+	s.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	s.w.Header().Set("Content-Disposition", `attachment; filename="`+s.filename+`"`)
+	s.w.WriteHeader(http.StatusOK)
+	_ = s.csv.Write(s.header)
+}
+
+// finish flushes the export. Once rows have been sent the status is already
+// 200, so a later error can only truncate the file; it is logged instead.
+func (s *csvStream) finish(err error) {
+	if err != nil && !s.started {
+		httpError(s.w, s.r, err)
+		return
+	}
+	if !s.started {
+		s.start()
+	}
+	s.csv.Flush()
+	if err == nil {
+		err = s.csv.Error()
+	}
+	if err != nil {
+		slog.Error("csv export aborted", "file", s.filename, "err", err)
+	}
+}
+
+// csvSafe defuses spreadsheet formula injection by prefixing cells that start
+// with a formula trigger character with a single quote.
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
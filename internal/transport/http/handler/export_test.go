@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportUsers_StreamsFilteredRows(t *testing.T) {
+	svc := &mockUserSvc{}
+	enabled := 1
+	f := domain.UserFilter{Role: domain.RoleAdmin, Enable: &enabled, CreatedFrom: "2024-01-01"}
+	svc.On("Export", mock.Anything, f, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(2).(func(domain.User) error)
+		_ = fn(domain.User{UserID: "u1", Username: "alice", FirstName: "=HYPERLINK(\"x\")", Role: domain.RoleAdmin, Enable: 1})
+	}).Return(nil)
+	h := NewExportHandler(svc, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/admin/export/users.csv?role=Admin&enabled=true&from=2024-01-01", nil)
+	rr := httptest.NewRecorder()
+	h.Users(rr, r)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	rows, err := csv.NewReader(rr.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, userCSVHeader, rows[0])
+	assert.Equal(t, "u1", rows[1][0])
+	assert.Equal(t, "'=HYPERLINK(\"x\")", rows[1][5], "formula cells must be neutralised")
+}
+
+func TestExportUsers_ErrorBeforeFirstRowIsJSON(t *testing.T) {
+	svc := &mockUserSvc{}
+	svc.On("Export", mock.Anything, mock.Anything, mock.Anything).Return(domain.ErrBadRequest)
+	h := NewExportHandler(svc, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/admin/export/users.csv?from=bad", nil)
+	rr := httptest.NewRecorder()
+	h.Users(rr, r)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+}
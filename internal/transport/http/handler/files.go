@@ -46,7 +46,7 @@ func (h *FileHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		UploaderID:  claims.UserID,
 	})
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, uploaded)
@@ -73,7 +73,7 @@ func (h *FileHandler) UploadBase64(w http.ResponseWriter, r *http.Request) {
 	}
 	uploaded, err := h.svc.UploadBase64(r.Context(), body.FileName, body.Base64, claims.UserID)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, uploaded)
@@ -87,7 +87,7 @@ func (h *FileHandler) Download(w http.ResponseWriter, r *http.Request) {
 	}
 	rc, f, err := h.svc.Download(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role == domain.RoleAdmin)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	defer rc.Close()
@@ -103,7 +103,7 @@ func (h *FileHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.svc.Delete(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role == domain.RoleAdmin); err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "file deleted"})
@@ -117,7 +117,7 @@ func (h *FileHandler) GetBase64(w http.ResponseWriter, r *http.Request) {
 	}
 	f, b64, err := h.svc.GetBase64(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role == domain.RoleAdmin)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{"file": f, "base64": b64})
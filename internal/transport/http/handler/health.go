@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/go-api-nosql/internal/domain"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -12,12 +13,20 @@ type dbPinger interface {
 	Ping(ctx context.Context) error
 }
 
+// backupReporter returns the latest backup verification, nil before the first.
+type backupReporter interface {
+	Last() *domain.BackupReport
+}
+
 // HealthHandler handles health-check endpoints.
 type HealthHandler struct {
-	db dbPinger
+	db      dbPinger
+	backups backupReporter // nil omits backups from the readiness report
 }
 
-func NewHealthHandler(db dbPinger) *HealthHandler { return &HealthHandler{db: db} }
+func NewHealthHandler(db dbPinger, backups backupReporter) *HealthHandler {
+	return &HealthHandler{db: db, backups: backups}
+}
 
 func (h *HealthHandler) Ping(w http.ResponseWriter, r *http.Request) {
 	action := chi.URLParam(r, "action")
@@ -29,7 +38,13 @@ func (h *HealthHandler) Ping(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusServiceUnavailable, "database unavailable")
 			return
 		}
-		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "ok"})
+		// Unhealthy backups are reported but do not fail readiness: the
+		// instance can still serve traffic.
+		report := ReadinessEnvelope{Message: "ok"}
+		if h.backups != nil {
+			report.Backups = h.backups.Last()
+		}
+		writeJSON(w, http.StatusOK, report)
 	default:
 		// Unknown action — reject with 400. Valid actions: "ping", "ready".
 		writeError(w, http.StatusBadRequest, "unknown action")
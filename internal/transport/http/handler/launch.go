@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/launch"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// LaunchHandler manages the soft-launch allowlist and reports how many
+// registrations the launch gate admitted and refused.
+type LaunchHandler struct {
+	svc launch.Service
+}
+
+func NewLaunchHandler(svc launch.Service) *LaunchHandler {
+	return &LaunchHandler{svc: svc}
+}
+
+// LaunchEntriesEnvelope is the response for GET /v1/admin/launch/allowlist.
+type LaunchEntriesEnvelope struct {
+	Data []domain.LaunchEntry `json:"data"`
+}
+
+// List serves GET /v1/admin/launch/allowlist, newest entries first.
+func (h *LaunchHandler) List(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.svc.List(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	if entries == nil {
+		entries = []domain.LaunchEntry{}
+	}
+	writeJSON(w, http.StatusOK, LaunchEntriesEnvelope{Data: entries})
+}
+
+// Create serves POST /v1/admin/launch/allowlist. An invite without a value
+// gets a generated code, returned in the response.
+func (h *LaunchHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.CreateLaunchEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	e, err := h.svc.Add(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, e)
+}
+
+// Delete serves DELETE /v1/admin/launch/allowlist/{kind}/{value}. Accounts
+// the entry already admitted are kept.
+func (h *LaunchHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	kind := chi.URLParam(r, "kind")
+	switch kind {
+	case domain.LaunchEmail, domain.LaunchDomain, domain.LaunchInvite:
+	default:
+		writeError(w, http.StatusBadRequest, "kind must be email, domain or invite")
+		return
+	}
+	if err := h.svc.Remove(r.Context(), claims.UserID, kind, chi.URLParam(r, "value")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Stats serves GET /v1/admin/launch/stats.
+func (h *LaunchHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.svc.Stats(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
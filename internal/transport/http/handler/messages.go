@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/message"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// MessageHandler handles direct-message endpoints.
+type MessageHandler struct {
+	svc message.Service
+}
+
+func NewMessageHandler(svc message.Service) *MessageHandler { return &MessageHandler{svc: svc} }
+
+// CursorMessagesEnvelope wraps cursor-paginated message list responses.
+type CursorMessagesEnvelope struct {
+	Data       []domain.Message `json:"data"`
+	Returned   int              `json:"returned"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+func (h *MessageHandler) Send(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	m, err := h.svc.Send(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, m)
+}
+
+// List returns the conversation with the user in the {userID} path param.
+func (h *MessageHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	limit, cursor := parseCursorPagination(r)
+	messages, nextCursor, err := h.svc.List(r.Context(), claims.UserID, chi.URLParam(r, "userID"), limit, cursor)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, CursorMessagesEnvelope{
+		Data:       messages,
+		Returned:   len(messages),
+		NextCursor: nextCursor,
+	})
+}
+
+func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if _, err := h.svc.MarkRead(r.Context(), claims.UserID, chi.URLParam(r, "userID")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "conversation marked as read"})
+}
+
+func (h *MessageHandler) Unread(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	counts, err := h.svc.UnreadCounts(r.Context(), claims.UserID)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, counts)
+}
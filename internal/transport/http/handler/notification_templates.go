@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/template"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-chi/chi/v5"
+)
+
+// NotificationTemplateHandler handles admin notification template endpoints.
+type NotificationTemplateHandler struct {
+	svc template.Service
+}
+
+func NewNotificationTemplateHandler(svc template.Service) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{svc: svc}
+}
+
+func (h *NotificationTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.svc.List(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, templates)
+}
+
+func (h *NotificationTemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateNotificationTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	created, err := h.svc.Create(r.Context(), req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (h *NotificationTemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	t, err := h.svc.Get(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (h *NotificationTemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input domain.NotificationTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&input); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	updated, err := h.svc.Update(r.Context(), chi.URLParam(r, "name"), input)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// Delete is a hard delete; notifications already sent keep their rendered text.
+func (h *NotificationTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(r.Context(), chi.URLParam(r, "name")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "template deleted"})
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/go-api-nosql/internal/application/notification"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
 	"github.com/go-api-nosql/internal/transport/http/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -25,7 +28,7 @@ func (h *NotificationHandler) ListUnread(w http.ResponseWriter, r *http.Request)
 	}
 	notifications, err := h.svc.ListUnread(r.Context(), claims.UserID)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, notifications)
@@ -37,10 +40,124 @@ func (h *NotificationHandler) MarkAsRead(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
-	n, err := h.svc.MarkAsRead(r.Context(), chi.URLParam(r, "id"), claims.UserID)
+	n, err := h.svc.MarkAsRead(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role == domain.RoleAdmin)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, n)
 }
+
+// Dismiss serves DELETE /v1/notifications/{id} for the recipient.
+func (h *NotificationHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if err := h.svc.Dismiss(r.Context(), chi.URLParam(r, "id"), claims.UserID); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "notification dismissed"})
+}
+
+// DismissedEnvelope is the response for DELETE /v1/notifications.
+type DismissedEnvelope struct {
+	Dismissed int `json:"dismissed"`
+}
+
+// DismissAll serves DELETE /v1/notifications, clearing the caller's notifications.
+func (h *NotificationHandler) DismissAll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	n, err := h.svc.DismissAll(r.Context(), claims.UserID)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, DismissedEnvelope{Dismissed: n})
+}
+
+// RecordReceipt lets a client report that a notification was delivered or read on a channel.
+func (h *NotificationHandler) RecordReceipt(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.RecordReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err := h.svc.RecordReceipt(r.Context(), chi.URLParam(r, "id"), claims.UserID, req); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "receipt recorded"})
+}
+
+// Stats returns per-channel delivery and read times for a notification (admin only).
+func (h *NotificationHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.svc.Stats(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// Create sends a notification to a user now, or schedules it when send_at is in the future (admin only).
+func (h *NotificationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	n, err := h.svc.Create(r.Context(), req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, n)
+}
+
+// Update changes the message or send time of a scheduled notification (admin only).
+func (h *NotificationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req domain.UpdateNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	n, err := h.svc.UpdateScheduled(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, n)
+}
+
+// Cancel stops a scheduled notification from being delivered (admin only).
+func (h *NotificationHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Cancel(r.Context(), chi.URLParam(r, "id")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "notification canceled"})
+}
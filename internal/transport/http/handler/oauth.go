@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/oauth"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// OAuthHandler serves the OAuth2 authorization server: client registration
+// for admins, the consent endpoints for signed-in users, and the token and
+// introspection endpoints for clients.
+type OAuthHandler struct {
+	svc oauth.Service
+}
+
+func NewOAuthHandler(svc oauth.Service) *OAuthHandler {
+	return &OAuthHandler{svc: svc}
+}
+
+// OAuthClientsEnvelope is the response for GET /v1/admin/oauth/clients.
+type OAuthClientsEnvelope struct {
+	Data []domain.OAuthClient `json:"data"`
+}
+
+// OAuthRedirectEnvelope is the response for POST /v1/oauth/authorize: where
+// the consent screen sends the user next.
+type OAuthRedirectEnvelope struct {
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// OAuthErrorEnvelope is an OAuth error response (RFC 6749 section 5.2).
+type OAuthErrorEnvelope struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// CreateClient serves POST /v1/admin/oauth/clients.
+func (h *OAuthHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.CreateOAuthClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	c, err := h.svc.CreateClient(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// ListClients serves GET /v1/admin/oauth/clients.
+func (h *OAuthHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := h.svc.ListClients(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, OAuthClientsEnvelope{Data: clients})
+}
+
+// DeleteClient serves DELETE /v1/admin/oauth/clients/{id}.
+func (h *OAuthHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if err := h.svc.DeleteClient(r.Context(), claims.UserID, chi.URLParam(r, "id")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Consent serves GET /v1/oauth/authorize, taking the authorization request
+// from the query string and describing it for the consent screen.
+func (h *OAuthHandler) Consent(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := domain.AuthorizeRequest{
+		ResponseType:        q.Get("response_type"),
+		ClientID:            q.Get("client_id"),
+		RedirectURI:         q.Get("redirect_uri"),
+		Scope:               q.Get("scope"),
+		State:               q.Get("state"),
+		CodeChallenge:       q.Get("code_challenge"),
+		CodeChallengeMethod: q.Get("code_challenge_method"),
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	consent, err := h.svc.Consent(r.Context(), req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, consent)
+}
+
+// Authorize serves POST /v1/oauth/authorize with the signed-in user's answer
+// to the consent screen.
+func (h *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.AuthorizeDecision
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	to, err := h.svc.Authorize(r.Context(), claims.UserID, req.AuthorizeRequest, req.Approve)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, OAuthRedirectEnvelope{RedirectURI: to})
+}
+
+// Token serves POST /v1/oauth/token, a form-encoded token request. Clients
+// authenticate with HTTP Basic auth or client_id and client_secret in the
+// form.
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, r, &domain.OAuthError{Code: "invalid_request", Description: "malformed form body"})
+		return
+	}
+	id, secret := clientCredentials(r)
+	tok, err := h.svc.Token(r.Context(), domain.OAuthTokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+		ClientID:     id,
+		ClientSecret: secret,
+	})
+	if err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, tok)
+}
+
+// Introspect serves POST /v1/oauth/introspect (RFC 7662), authenticated
+// like Token or by an admin's bearer token.
+func (h *OAuthHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, r, &domain.OAuthError{Code: "invalid_request", Description: "malformed form body"})
+		return
+	}
+	info, err := h.svc.Introspect(r.Context(), caller(r), r.PostForm.Get("token"))
+	if err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// Revoke serves POST /v1/oauth/revoke (RFC 7009), authenticated like
+// Introspect. It answers 200 for unknown tokens too.
+func (h *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, r, &domain.OAuthError{Code: "invalid_request", Description: "malformed form body"})
+		return
+	}
+	if err := h.svc.Revoke(r.Context(), caller(r), r.PostForm.Get("token")); err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// clientCredentials reads the client ID and secret from HTTP Basic auth,
+// falling back to the form.
+func clientCredentials(r *http.Request) (string, string) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		return id, secret
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+}
+
+// caller is the admin whose bearer token the route policy let through, or
+// else the client whose credentials came with the request.
+func caller(r *http.Request) oauth.Caller {
+	if claims, ok := middleware.ClaimsFromContext(r.Context()); ok {
+		return oauth.Caller{AdminID: claims.UserID}
+	}
+	id, secret := clientCredentials(r)
+	return oauth.Caller{ClientID: id, ClientSecret: secret}
+}
+
+// writeOAuthError writes an OAuth error in the RFC 6749 format; other errors
+// go through httpError.
+func writeOAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	var oe *domain.OAuthError
+	if !errors.As(err, &oe) {
+		httpError(w, r, err)
+		return
+	}
+	status := http.StatusBadRequest
+	if errors.Is(oe, domain.ErrUnauthorized) {
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	writeJSON(w, status, OAuthErrorEnvelope{Error: oe.Code, Description: oe.Description})
+}
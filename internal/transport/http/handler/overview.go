@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/overview"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-chi/chi/v5"
+)
+
+// OverviewHandler serves the admin account overview.
+type OverviewHandler struct {
+	svc overview.Service
+}
+
+func NewOverviewHandler(svc overview.Service) *OverviewHandler { return &OverviewHandler{svc: svc} }
+
+// NotificationCounts summarises notifications delivered to a user.
+type NotificationCounts struct {
+	Sent   int `json:"sent"`
+	Unread int `json:"unread"`
+}
+
+// UserOverviewEnvelope is the response for GET /v1/admin/users/{id}/overview.
+type UserOverviewEnvelope struct {
+	User           *SafeUser           `json:"user"`
+	Devices        []domain.Device     `json:"devices"`
+	ActiveSessions []*SafeSession      `json:"active_sessions"`
+	Storage        domain.StorageUsage `json:"storage"`
+	Notifications  NotificationCounts  `json:"notifications"`
+	RecentLogins   []domain.Activity   `json:"recent_logins"`
+}
+
+func (h *OverviewHandler) Get(w http.ResponseWriter, r *http.Request) {
+	o, err := h.svc.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	sessions := make([]*SafeSession, 0, len(o.ActiveSessions))
+	for i := range o.ActiveSessions {
+		sessions = append(sessions, toSafeSession(&o.ActiveSessions[i]))
+	}
+	devices := o.Devices
+	if devices == nil {
+		devices = []domain.Device{}
+	}
+	writeJSON(w, http.StatusOK, UserOverviewEnvelope{
+		User:           toSafeUser(o.User),
+		Devices:        devices,
+		ActiveSessions: sessions,
+		Storage:        o.Storage,
+		Notifications:  NotificationCounts{Sent: o.NotificationsSent, Unread: o.NotificationsUnread},
+		RecentLogins:   o.RecentLogins,
+	})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/passkey"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// PasskeyHandler runs the passkey registration ceremony, lists and removes a
+// user's passkeys, and issues login challenges. The login itself is
+// SessionHandler.PasskeyLogin.
+type PasskeyHandler struct {
+	svc passkey.Service
+}
+
+func NewPasskeyHandler(svc passkey.Service) *PasskeyHandler {
+	return &PasskeyHandler{svc: svc}
+}
+
+// PasskeysEnvelope is the response for GET /v1/users/me/passkeys.
+type PasskeysEnvelope struct {
+	Data []domain.Passkey `json:"data"`
+}
+
+// BeginRegistration serves POST /v1/users/me/passkeys/challenge.
+func (h *PasskeyHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	opts, err := h.svc.BeginRegistration(r.Context(), claims.UserID)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, opts)
+}
+
+// Register serves POST /v1/users/me/passkeys with the authenticator's
+// response to the last registration challenge.
+func (h *PasskeyHandler) Register(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.RegisterPasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	p, err := h.svc.Register(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// List serves GET /v1/users/me/passkeys.
+func (h *PasskeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	passkeys, err := h.svc.List(r.Context(), claims.UserID)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, PasskeysEnvelope{Data: passkeys})
+}
+
+// Delete serves DELETE /v1/users/me/passkeys/{id}. Sessions the passkey
+// opened stay signed in.
+func (h *PasskeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if err := h.svc.Delete(r.Context(), claims.UserID, chi.URLParam(r, "id")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BeginLogin serves POST /v1/sessions/webauthn/challenge.
+func (h *PasskeyHandler) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	opts, err := h.svc.BeginLogin(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, opts)
+}
@@ -27,7 +27,7 @@ func (h *PasswordRecoveryHandler) Action(w http.ResponseWriter, r *http.Request)
 			return
 		}
 		if err := h.svc.RequestPasswordRecovery(r.Context(), req); err != nil {
-			httpError(w, err)
+			httpError(w, r, err)
 			return
 		}
 		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "OTP sent"})
@@ -43,7 +43,7 @@ func (h *PasswordRecoveryHandler) Action(w http.ResponseWriter, r *http.Request)
 		}
 		result, err := h.svc.ValidateOTP(r.Context(), req)
 		if err != nil {
-			httpError(w, err)
+			httpError(w, r, err)
 			return
 		}
 		writeJSON(w, http.StatusOK, AuthEnvelope{AccessToken: result.Bearer, RefreshToken: result.RefreshToken, Session: toSafeSession(result.Session), User: toSafeUser(result.Session.User)})
@@ -27,7 +27,13 @@ func (h *PhoneConfirmHandler) Action(w http.ResponseWriter, r *http.Request) {
 	switch chi.URLParam(r, "action") {
 	case "request":
 		if err := h.svc.RequestPhoneConfirmation(r.Context(), claims.UserID); err != nil {
-			httpError(w, err)
+			httpError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "confirmation SMS sent"})
+	case "resend":
+		if err := h.svc.ResendPhoneConfirmation(r.Context(), claims.UserID); err != nil {
+			resendError(w, r, err)
 			return
 		}
 		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "confirmation SMS sent"})
@@ -39,8 +45,8 @@ func (h *PhoneConfirmHandler) Action(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "invalid request body")
 			return
 		}
-		if err := h.svc.ValidatePhoneOTP(r.Context(), claims.UserID, body.OTP); err != nil {
-			httpError(w, err)
+		if err := h.svc.ValidatePhoneOTP(r.Context(), claims.UserID, claims.DeviceID, body.OTP); err != nil {
+			httpError(w, r, err)
 			return
 		}
 		writeJSON(w, http.StatusOK, MessageEnvelope{Message: "phone confirmed"})
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/plan"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// PlanHandler handles admin plan endpoints and plan assignment.
+type PlanHandler struct {
+	svc plan.Service
+}
+
+func NewPlanHandler(svc plan.Service) *PlanHandler { return &PlanHandler{svc: svc} }
+
+func (h *PlanHandler) List(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.svc.List(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, plans)
+}
+
+func (h *PlanHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreatePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	created, err := h.svc.Create(r.Context(), req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (h *PlanHandler) Get(w http.ResponseWriter, r *http.Request) {
+	p, err := h.svc.Get(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (h *PlanHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input domain.PlanInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&input); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	updated, err := h.svc.Update(r.Context(), chi.URLParam(r, "name"), input)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// Delete is a hard delete. The free plan is rejected with 403.
+func (h *PlanHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(r.Context(), chi.URLParam(r, "name")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "plan deleted"})
+}
+
+// Assign handles PUT /v1/admin/users/{id}/plan.
+func (h *PlanHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.AssignPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	u, err := h.svc.Assign(r.Context(), claims.UserID, chi.URLParam(r, "id"), req.Plan)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toSafeUser(u))
+}
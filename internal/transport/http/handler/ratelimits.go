@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// rateLimitManager is satisfied by the rate limiters in the middleware package.
+type rateLimitManager interface {
+	Inspect(ctx context.Context, key string) (*middleware.RateLimitState, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// RateLimitHandler handles admin endpoints for inspecting and clearing rate limit state.
+type RateLimitHandler struct {
+	limiter rateLimitManager
+}
+
+func NewRateLimitHandler(limiter rateLimitManager) *RateLimitHandler {
+	return &RateLimitHandler{limiter: limiter}
+}
+
+func (h *RateLimitHandler) Inspect(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+	state, err := h.limiter.Inspect(r.Context(), key)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (h *RateLimitHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if err := h.limiter.Reset(r.Context(), chi.URLParam(r, "key")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "rate limit reset"})
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/readonly"
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+)
+
+// ReadOnlyHandler shows and switches read-only mode.
+type ReadOnlyHandler struct {
+	svc readonly.Service
+}
+
+func NewReadOnlyHandler(svc readonly.Service) *ReadOnlyHandler {
+	return &ReadOnlyHandler{svc: svc}
+}
+
+// Get serves GET /v1/admin/read-only. It reports this replica's view, which
+// trails a change made elsewhere by up to READ_ONLY_REFRESH.
+func (h *ReadOnlyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.svc.Mode())
+}
+
+// Put serves PUT /v1/admin/read-only.
+func (h *ReadOnlyHandler) Put(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.SetReadOnlyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	m, err := h.svc.Set(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
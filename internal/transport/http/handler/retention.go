@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/retention"
+)
+
+// RetentionHandler reports what the data retention rules would purge.
+type RetentionHandler struct {
+	svc retention.Service
+}
+
+func NewRetentionHandler(svc retention.Service) *RetentionHandler {
+	return &RetentionHandler{svc: svc}
+}
+
+// Report serves GET /v1/admin/retention/report. It never deletes anything.
+func (h *RetentionHandler) Report(w http.ResponseWriter, r *http.Request) {
+	report, err := h.svc.Report(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -1,13 +1,81 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 
+	"github.com/go-api-nosql/internal/application/role"
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-chi/chi/v5"
 )
 
-// ListRoles returns the available role names. Roles are not stored in the
-// database — they are hardcoded constants used for RBAC.
-func ListRoles(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, []string{domain.RoleAdmin, domain.RoleUser})
+// RoleHandler handles admin role endpoints.
+type RoleHandler struct {
+	svc role.Service
+}
+
+func NewRoleHandler(svc role.Service) *RoleHandler { return &RoleHandler{svc: svc} }
+
+func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.svc.List(r.Context())
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	created, err := h.svc.Create(r.Context(), req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	role, err := h.svc.Get(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, role)
+}
+
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input domain.RoleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&input); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	updated, err := h.svc.Update(r.Context(), chi.URLParam(r, "name"), input)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// Delete is a hard delete. Built-in roles are rejected with 403.
+func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(r.Context(), chi.URLParam(r, "name")); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "role deleted"})
 }
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/search"
+)
+
+// SearchHandler handles the admin search endpoint.
+type SearchHandler struct {
+	svc search.Service
+}
+
+func NewSearchHandler(svc search.Service) *SearchHandler { return &SearchHandler{svc: svc} }
+
+// SearchEnvelope wraps search results; Data holds the projection documents of
+// the requested type.
+type SearchEnvelope struct {
+	Data     []json.RawMessage `json:"data"`
+	Returned int               `json:"returned"`
+}
+
+// Search serves GET /v1/search?type=users|files&q=.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	limit, _ := parseCursorPagination(r)
+	q := r.URL.Query()
+	docs, err := h.svc.Search(r.Context(), q.Get("type"), q.Get("q"), limit)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, SearchEnvelope{Data: docs, Returned: len(docs)})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/go-api-nosql/internal/application/session"
+	"github.com/go-api-nosql/internal/domain"
 	"github.com/go-api-nosql/internal/pkg/validate"
 	"github.com/go-api-nosql/internal/transport/http/middleware"
 )
@@ -30,15 +31,27 @@ func (h *SessionHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	result, err := h.svc.Login(r.Context(), req)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, AuthEnvelope{
-		AccessToken:  result.Bearer,
-		RefreshToken: result.RefreshToken,
-		Session:      toSafeSession(result.Session),
-		User:         toSafeUser(result.Session.User),
-	})
+	writeLogin(w, result)
+}
+
+// writeLogin answers a login with its tokens: 200 once the session is
+// active, 202 while it waits for approval by email.
+func writeLogin(w http.ResponseWriter, result *session.LoginResult) {
+	status, env := http.StatusOK, AuthEnvelope{
+		AccessToken:   result.Bearer,
+		RefreshToken:  result.RefreshToken,
+		RememberToken: result.RememberToken,
+		Session:       toSafeSession(result.Session),
+		User:          toSafeUser(result.Session.User),
+	}
+	if result.Session.PendingApproval() {
+		status = http.StatusAccepted
+		env.Message = "sign-in from a high-risk location; approve it from the link emailed to you, then refresh"
+	}
+	writeJSON(w, status, env)
 }
 
 func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
@@ -51,12 +64,60 @@ func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	}
 	bearer, newToken, err := h.svc.Refresh(r.Context(), req.RefreshToken)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, AuthEnvelope{AccessToken: bearer, RefreshToken: newToken})
 }
 
+// SilentRefresh signs a trusted device back in with its remember-me token.
+func (h *SessionHandler) SilentRefresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DeviceUUID    string `json:"device_uuid" validate:"required"`
+		RememberToken string `json:"remember_token" validate:"required"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	result, err := h.svc.SilentRefresh(r.Context(), req.DeviceUUID, req.RememberToken)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, AuthEnvelope{
+		AccessToken:   result.Bearer,
+		RefreshToken:  result.RefreshToken,
+		RememberToken: result.RememberToken,
+		Session:       toSafeSession(result.Session),
+		User:          toSafeUser(result.Session.User),
+	})
+}
+
+// ApproveLogin activates a high-risk login with the token from its email.
+func (h *SessionHandler) ApproveLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token" validate:"required"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err := h.svc.ApproveLogin(r.Context(), req.Token); err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "sign-in approved"})
+}
+
 func (h *SessionHandler) GetCurrent(w http.ResponseWriter, r *http.Request) {
 	claims, ok := middleware.ClaimsFromContext(r.Context())
 	if !ok {
@@ -65,12 +126,38 @@ func (h *SessionHandler) GetCurrent(w http.ResponseWriter, r *http.Request) {
 	}
 	sess, err := h.svc.GetCurrent(r.Context(), claims.SessionID)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, SessionEnvelope{Session: toSafeSession(sess), User: toSafeUser(sess.User)})
 }
 
+// SessionsEnvelope is the response for GET /v1/sessions/active.
+type SessionsEnvelope struct {
+	Data []*SafeSession `json:"data"`
+}
+
+// ListActive lists the caller's active sessions, marking the current one.
+func (h *SessionHandler) ListActive(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	sessions, err := h.svc.ListActive(r.Context(), claims.UserID)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	out := make([]*SafeSession, 0, len(sessions))
+	for i := range sessions {
+		safe := toSafeSession(&sessions[i])
+		safe.Current = sessions[i].SessionID == claims.SessionID
+		out = append(out, safe)
+	}
+	writeJSON(w, http.StatusOK, SessionsEnvelope{Data: out})
+}
+
 func (h *SessionHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Credential string  `json:"credential"`
@@ -86,15 +173,30 @@ func (h *SessionHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 	result, err := h.svc.LoginWithGoogle(r.Context(), req.Credential, req.DeviceUUID)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, AuthEnvelope{
-		AccessToken:  result.Bearer,
-		RefreshToken: result.RefreshToken,
-		Session:      toSafeSession(result.Session),
-		User:         toSafeUser(result.Session.User),
-	})
+	writeLogin(w, result)
+}
+
+// PasskeyLogin serves POST /v1/sessions/webauthn with an assertion signed
+// over a challenge from POST /v1/sessions/webauthn/challenge.
+func (h *SessionHandler) PasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req domain.PasskeyAssertion
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	result, err := h.svc.LoginWithPasskey(r.Context(), req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeLogin(w, result)
 }
 
 func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
@@ -104,7 +206,7 @@ func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.svc.Logout(r.Context(), claims.SessionID); err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "logged out"})
@@ -6,6 +6,8 @@ import (
 
 	"github.com/go-api-nosql/internal/application/status"
 	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-api-nosql/internal/pkg/validate"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -16,10 +18,16 @@ type StatusHandler struct {
 
 func NewStatusHandler(svc status.Service) *StatusHandler { return &StatusHandler{svc: svc} }
 
+// List returns enabled statuses in display order. ?lang= selects the localized
+// label; admins may pass include_disabled=true to also see soft-deleted ones.
 func (h *StatusHandler) List(w http.ResponseWriter, r *http.Request) {
-	statuses, err := h.svc.List(r.Context())
+	includeDisabled := false
+	if claims, ok := middleware.ClaimsFromContext(r.Context()); ok && claims.Role == domain.RoleAdmin {
+		includeDisabled = r.URL.Query().Get("include_disabled") == "true"
+	}
+	statuses, err := h.svc.List(r.Context(), includeDisabled, r.URL.Query().Get("lang"))
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, statuses)
@@ -31,9 +39,13 @@ func (h *StatusHandler) Create(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if err := validate.Struct(&input); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
 	created, err := h.svc.Create(r.Context(), input)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, created)
@@ -42,7 +54,7 @@ func (h *StatusHandler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *StatusHandler) Get(w http.ResponseWriter, r *http.Request) {
 	st, err := h.svc.Get(r.Context(), chi.URLParam(r, "id"))
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, st)
@@ -54,19 +66,28 @@ func (h *StatusHandler) Update(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if err := validate.Struct(&input); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
 	updated, err := h.svc.Update(r.Context(), chi.URLParam(r, "id"), input)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, updated)
 }
 
-// Delete is a hard delete (no soft delete for statuses).
+// Delete disables a status. Pass force=true to remove it permanently.
 func (h *StatusHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	if err := h.svc.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
-		httpError(w, err)
+	force := r.URL.Query().Get("force") == "true"
+	if err := h.svc.Delete(r.Context(), chi.URLParam(r, "id"), force); err != nil {
+		httpError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "status deleted"})
+	msg := "status disabled"
+	if force {
+		msg = "status deleted"
+	}
+	writeJSON(w, http.StatusOK, MessageEnvelope{Message: msg})
 }
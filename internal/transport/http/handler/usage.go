@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-api-nosql/internal/application/usage"
+	"github.com/go-api-nosql/internal/transport/http/middleware"
+)
+
+// UsageHandler serves the caller's metered usage.
+type UsageHandler struct {
+	svc usage.Service
+}
+
+func NewUsageHandler(svc usage.Service) *UsageHandler { return &UsageHandler{svc: svc} }
+
+// Me returns the caller's usage so far today with their role's quota.
+func (h *UsageHandler) Me(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	report, err := h.svc.Today(r.Context(), claims.UserID, claims.Role)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -2,9 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 
+	"github.com/go-api-nosql/internal/application/approval"
 	"github.com/go-api-nosql/internal/application/user"
 	"github.com/go-api-nosql/internal/domain"
 	"github.com/go-api-nosql/internal/pkg/validate"
@@ -14,10 +17,15 @@ import (
 
 // UserHandler handles user CRUD endpoints.
 type UserHandler struct {
-	svc user.Service
+	svc       user.Service
+	approvals approval.Service // nil runs destructive admin actions immediately
 }
 
-func NewUserHandler(svc user.Service) *UserHandler { return &UserHandler{svc: svc} }
+// NewUserHandler builds the handler. With approvals set, destructive admin
+// actions are stored as pending approvals and answered with 202 instead.
+func NewUserHandler(svc user.Service, approvals approval.Service) *UserHandler {
+	return &UserHandler{svc: svc, approvals: approvals}
+}
 
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req domain.CreateUserRequest
@@ -31,7 +39,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 	sess, bearer, refreshToken, err := h.svc.RegisterWithSession(r.Context(), req)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, AuthEnvelope{
@@ -42,22 +50,38 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// List serves GET /v1/users?role=&enabled=&email_confirmed=&from=&to=&order=.
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	f, err := parseUserFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch r.URL.Query().Get("order") {
+	case "", "desc":
+	case "asc":
+		f.Ascending = true
+	default:
+		writeError(w, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
 	limit, cursor := parseCursorPagination(r)
-	users, nextCursor, err := h.svc.List(r.Context(), limit, cursor)
+	users, nextCursor, err := h.svc.List(r.Context(), f, limit, cursor)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	safe := make([]*SafeUser, len(users))
 	for i := range users {
 		safe[i] = toSafeUser(&users[i])
 	}
-	writeJSON(w, http.StatusOK, CursorUsersEnvelope{
-		Data:       safe,
-		Returned:   len(safe),
-		NextCursor: nextCursor,
-	})
+	env := CursorUsersEnvelope{Data: safe, Returned: len(safe), NextCursor: nextCursor}
+	if total, err := h.svc.ApproxTotal(r.Context()); err == nil {
+		env.ApproxTotal = &total
+	} else if !errors.Is(err, domain.ErrTableWideCount) {
+		slog.Warn("approximate user count unavailable", "err", err)
+	}
+	writeJSON(w, http.StatusOK, env)
 }
 
 func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
@@ -68,7 +92,7 @@ func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 	u, err := h.svc.Get(r.Context(), chi.URLParam(r, "id"))
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	if claims.UserID == u.UserID || claims.Role == domain.RoleAdmin {
@@ -78,6 +102,16 @@ func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, toPublicUser(u))
 }
 
+// GetPublic serves GET /v1/public/users/{username} without authentication.
+func (h *UserHandler) GetPublic(w http.ResponseWriter, r *http.Request) {
+	u, err := h.svc.GetPublic(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toPublicUser(u))
+}
+
 func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	claims, ok := middleware.ClaimsFromContext(r.Context())
 	if !ok {
@@ -98,6 +132,12 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
+	if req.Email != nil && claims.UserID == targetID {
+		if err := h.svc.RequireTrustedDevice(r.Context(), claims.DeviceID); err != nil {
+			httpError(w, r, err)
+			return
+		}
+	}
 	if claims.Role != domain.RoleAdmin {
 		if req.Role != nil {
 			writeError(w, http.StatusForbidden, "cannot set role as non-admin")
@@ -107,10 +147,16 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusForbidden, "cannot set enable as non-admin")
 			return
 		}
+		if req.Metadata != nil {
+			if err := h.svc.CheckSelfMetadata(req.Metadata); err != nil {
+				httpError(w, r, err)
+				return
+			}
+		}
 	}
 	u, err := h.svc.Update(r.Context(), targetID, req)
 	if err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, toSafeUser(u))
@@ -127,17 +173,146 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusForbidden, "cannot delete another user")
 		return
 	}
+	hold := domain.Approval{Action: domain.ApprovalUserDelete, TargetID: targetID}
+	if claims.UserID != targetID && h.holdForApproval(w, r, claims.UserID, hold) {
+		return
+	}
 	if err := h.svc.Delete(r.Context(), targetID); err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "deleted"})
 }
 
+// ChangeRole assigns a role from the roles table to a user (admin only by policy).
+func (h *UserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.ChangeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	targetID := chi.URLParam(r, "id")
+	hold := domain.Approval{Action: domain.ApprovalGrantAdmin, TargetID: targetID, ChangeRole: &req}
+	if req.Role == domain.RoleAdmin && h.holdForApproval(w, r, claims.UserID, hold) {
+		return
+	}
+	u, err := h.svc.ChangeRole(r.Context(), claims.UserID, targetID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toSafeUser(u))
+}
+
+// BulkUsersEnvelope is the response for POST /v1/admin/users/bulk.
+type BulkUsersEnvelope struct {
+	Results   []domain.BulkUserResult `json:"results"`
+	Succeeded int                     `json:"succeeded"`
+	Failed    int                     `json:"failed"`
+}
+
+// Provision serves POST /v1/admin/users. The account's temporary password is
+// emailed to it, never returned.
+func (h *UserHandler) Provision(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.ProvisionUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	u, err := h.svc.Provision(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toSafeUser(u))
+}
+
+// Bulk serves POST /v1/admin/users/bulk. Per-user failures are reported in
+// the results with a 200; only an invalid request fails as a whole.
+func (h *UserHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req domain.BulkUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	hold := domain.Approval{Action: domain.ApprovalBulk, Bulk: &req}
+	if bulkNeedsApproval(req) && h.holdForApproval(w, r, claims.UserID, hold) {
+		return
+	}
+	results, err := h.svc.Bulk(r.Context(), claims.UserID, req)
+	if err != nil {
+		httpError(w, r, err)
+		return
+	}
+	env := BulkUsersEnvelope{Results: results}
+	for _, res := range results {
+		if res.OK {
+			env.Succeeded++
+		} else {
+			env.Failed++
+		}
+	}
+	writeJSON(w, http.StatusOK, env)
+}
+
+// bulkNeedsApproval reports whether a bulk action is destructive: disabling or
+// deleting accounts, or granting the Admin role.
+func bulkNeedsApproval(req domain.BulkUserRequest) bool {
+	switch req.Action {
+	case domain.BulkDisable, domain.BulkDelete:
+		return true
+	case domain.BulkSetRole:
+		return req.Role == domain.RoleAdmin
+	}
+	return false
+}
+
+// holdForApproval stores a as a pending approval requested by requesterID and
+// answers 202 with it. It reports false, writing nothing, when approvals are off.
+func (h *UserHandler) holdForApproval(w http.ResponseWriter, r *http.Request, requesterID string, a domain.Approval) bool {
+	if h.approvals == nil {
+		return false
+	}
+	pending, err := h.approvals.Request(r.Context(), requesterID, a)
+	if err != nil {
+		httpError(w, r, err)
+		return true
+	}
+	writeJSON(w, http.StatusAccepted, pending)
+	return true
+}
+
 // ChangePasswordRequest is the body for POST /v1/users/me/password.
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
-	NewPassword     string `json:"new_password"     validate:"required,min=8,max=72"`
+	NewPassword     string `json:"new_password"     validate:"required,max=72"`
 }
 
 func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
@@ -155,8 +330,12 @@ func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
+	if err := h.svc.RequireTrustedDevice(r.Context(), claims.DeviceID); err != nil {
+		httpError(w, r, err)
+		return
+	}
 	if err := h.svc.ChangePassword(r.Context(), claims.UserID, req.CurrentPassword, req.NewPassword); err != nil {
-		httpError(w, err)
+		httpError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, MessageEnvelope{Message: "password changed"})
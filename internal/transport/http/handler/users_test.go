@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -45,11 +46,30 @@ func (m *mockUserSvc) RegisterWithSession(ctx context.Context, req domain.Create
 	return nil, "", "", args.Error(3)
 }
 
-func (m *mockUserSvc) List(ctx context.Context, limit int, cursor string) ([]domain.User, string, error) {
-	args := m.Called(ctx, limit, cursor)
+func (m *mockUserSvc) Provision(ctx context.Context, actorID string, req domain.ProvisionUserRequest) (*domain.User, error) {
+	args := m.Called(ctx, actorID, req)
+	if u, _ := args.Get(0).(*domain.User); u != nil {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockUserSvc) List(ctx context.Context, f domain.UserFilter, limit int, cursor string) ([]domain.User, string, error) {
+	args := m.Called(ctx, f, limit, cursor)
 	return args.Get(0).([]domain.User), args.String(1), args.Error(2)
 }
 
+func (m *mockUserSvc) Bulk(ctx context.Context, actorID string, req domain.BulkUserRequest) ([]domain.BulkUserResult, error) {
+	args := m.Called(ctx, actorID, req)
+	results, _ := args.Get(0).([]domain.BulkUserResult)
+	return results, args.Error(1)
+}
+
+func (m *mockUserSvc) ApproxTotal(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *mockUserSvc) Get(ctx context.Context, userID string) (*domain.User, error) {
 	args := m.Called(ctx, userID)
 	if u, _ := args.Get(0).(*domain.User); u != nil {
@@ -58,6 +78,18 @@ func (m *mockUserSvc) Get(ctx context.Context, userID string) (*domain.User, err
 	return nil, args.Error(1)
 }
 
+func (m *mockUserSvc) GetPublic(ctx context.Context, username string) (*domain.User, error) {
+	args := m.Called(ctx, username)
+	if u, _ := args.Get(0).(*domain.User); u != nil {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockUserSvc) Export(ctx context.Context, f domain.UserFilter, fn func(domain.User) error) error {
+	return m.Called(ctx, f, fn).Error(0)
+}
+
 func (m *mockUserSvc) Update(ctx context.Context, userID string, req domain.UpdateUserRequest) (*domain.User, error) {
 	args := m.Called(ctx, userID, req)
 	if u, _ := args.Get(0).(*domain.User); u != nil {
@@ -66,6 +98,10 @@ func (m *mockUserSvc) Update(ctx context.Context, userID string, req domain.Upda
 	return nil, args.Error(1)
 }
 
+func (m *mockUserSvc) CheckSelfMetadata(patch map[string]*string) error {
+	return m.Called(patch).Error(0)
+}
+
 func (m *mockUserSvc) Delete(ctx context.Context, userID string) error {
 	return m.Called(ctx, userID).Error(0)
 }
@@ -74,6 +110,22 @@ func (m *mockUserSvc) ChangePassword(ctx context.Context, userID, currentPasswor
 	return m.Called(ctx, userID, currentPassword, newPassword).Error(0)
 }
 
+func (m *mockUserSvc) RequireTrustedDevice(ctx context.Context, deviceID string) error {
+	return m.Called(ctx, deviceID).Error(0)
+}
+
+func (m *mockUserSvc) EnsureAdmin(ctx context.Context, email, password string) error {
+	return m.Called(ctx, email, password).Error(0)
+}
+
+func (m *mockUserSvc) ChangeRole(ctx context.Context, actorID, targetID string, req domain.ChangeRoleRequest) (*domain.User, error) {
+	args := m.Called(ctx, actorID, targetID, req)
+	if u, _ := args.Get(0).(*domain.User); u != nil {
+		return u, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 // --- helpers ---
 
 // newTestJWTProvider generates a fresh RSA key pair and returns a *jwtinfra.Provider.
@@ -106,7 +158,7 @@ func newTestJWTProvider(t *testing.T) *jwtinfra.Provider {
 // bearerReq builds a request with a signed Bearer token for the given userID and role.
 func bearerReq(t *testing.T, p *jwtinfra.Provider, method, target, userID, role string, body []byte) *http.Request {
 	t.Helper()
-	token, err := p.Sign(userID, "dev1", role, "sess1")
+	token, err := p.Sign(context.Background(), userID, "dev1", role, "sess1")
 	require.NoError(t, err)
 	var r *http.Request
 	if body != nil {
@@ -134,7 +186,7 @@ func serveAuthed(p *jwtinfra.Provider, h http.Handler, w http.ResponseWriter, r
 
 func TestRegister_InvalidBody(t *testing.T) {
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	r := httptest.NewRequest(http.MethodPost, "/v1/users", bytes.NewBufferString("not-json"))
 	rr := httptest.NewRecorder()
 	h.Register(rr, r)
@@ -143,7 +195,7 @@ func TestRegister_InvalidBody(t *testing.T) {
 
 func TestRegister_ValidationFailure(t *testing.T) {
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	body, _ := json.Marshal(domain.CreateUserRequest{Username: "alice"}) // missing required fields
 	r := httptest.NewRequest(http.MethodPost, "/v1/users", bytes.NewReader(body))
 	rr := httptest.NewRecorder()
@@ -154,7 +206,7 @@ func TestRegister_ValidationFailure(t *testing.T) {
 func TestRegister_ServiceConflict(t *testing.T) {
 	svc := &mockUserSvc{}
 	svc.On("RegisterWithSession", mock.Anything, mock.Anything).Return(nil, "", "", domain.ErrConflict)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	body, _ := json.Marshal(domain.CreateUserRequest{
 		Username: "alice", Password: "secret123", Email: "alice@example.com",
 		FirstName: "Alice", LastName: "Smith",
@@ -170,7 +222,7 @@ func TestRegister_HappyPath(t *testing.T) {
 	svc := &mockUserSvc{}
 	sess := &domain.Session{SessionID: "s1", UserID: "u1", User: &domain.User{UserID: "u1", Username: "alice"}}
 	svc.On("RegisterWithSession", mock.Anything, mock.Anything).Return(sess, "access-token", "refresh-token", nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	body, _ := json.Marshal(domain.CreateUserRequest{
 		Username: "alice", Password: "secret123", Email: "alice@example.com",
 		FirstName: "Alice", LastName: "Smith",
@@ -190,7 +242,7 @@ func TestRegister_HappyPath(t *testing.T) {
 
 func TestGet_MissingClaims(t *testing.T) {
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	r := withChiID(httptest.NewRequest(http.MethodGet, "/v1/users/u1", nil), "u1")
 	rr := httptest.NewRecorder()
 	h.Get(rr, r) // called directly, no claims in context
@@ -202,7 +254,7 @@ func TestGet_Owner_SeesFullUser(t *testing.T) {
 	svc := &mockUserSvc{}
 	u := &domain.User{UserID: "u1", Username: "alice", Email: "alice@example.com", Role: domain.RoleUser}
 	svc.On("Get", mock.Anything, "u1").Return(u, nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 
 	r := bearerReq(t, p, http.MethodGet, "/v1/users/u1", "u1", domain.RoleUser, nil)
 	r = withChiID(r, "u1")
@@ -221,7 +273,7 @@ func TestGet_Admin_SeesFullUser(t *testing.T) {
 	svc := &mockUserSvc{}
 	u := &domain.User{UserID: "u2", Username: "bob", Email: "bob@example.com", Role: domain.RoleUser}
 	svc.On("Get", mock.Anything, "u2").Return(u, nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 
 	r := bearerReq(t, p, http.MethodGet, "/v1/users/u2", "admin1", domain.RoleAdmin, nil)
 	r = withChiID(r, "u2")
@@ -240,7 +292,7 @@ func TestGet_OtherUser_SeesPublicOnly(t *testing.T) {
 	svc := &mockUserSvc{}
 	u := &domain.User{UserID: "u2", Username: "bob", Email: "bob@example.com", Role: domain.RoleUser}
 	svc.On("Get", mock.Anything, "u2").Return(u, nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 
 	r := bearerReq(t, p, http.MethodGet, "/v1/users/u2", "u1", domain.RoleUser, nil) // u1 viewing u2
 	r = withChiID(r, "u2")
@@ -256,11 +308,136 @@ func TestGet_OtherUser_SeesPublicOnly(t *testing.T) {
 	svc.AssertExpectations(t)
 }
 
+func TestGetPublic_NoAuthReturnsPublicProjection(t *testing.T) {
+	svc := &mockUserSvc{}
+	u := &domain.User{UserID: "u2", Username: "bob", Email: "bob@example.com", PublicProfile: true}
+	svc.On("GetPublic", mock.Anything, "bob").Return(u, nil)
+	h := NewUserHandler(svc, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/public/users/bob", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("username", "bob")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+	h.GetPublic(rr, r)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "bob", resp["username"])
+	_, hasEmail := resp["email"]
+	assert.False(t, hasEmail, "public profiles must not expose email")
+}
+
+// --- List tests ---
+
+func TestList_ParsesFiltersAndOrder(t *testing.T) {
+	svc := &mockUserSvc{}
+	confirmed := true
+	disabled := 0
+	want := domain.UserFilter{
+		Role: "Admin", Enable: &disabled, EmailConfirmed: &confirmed,
+		CreatedFrom: "2024-01-01", CreatedTo: "2024-02-01", Ascending: true,
+	}
+	svc.On("List", mock.Anything, want, 10, "c1").Return([]domain.User{{UserID: "u1"}}, "c2", nil)
+	svc.On("ApproxTotal", mock.Anything).Return(int64(0), errors.New("throttled"))
+	h := NewUserHandler(svc, nil)
+
+	r := httptest.NewRequest(http.MethodGet,
+		"/v1/users?role=Admin&enabled=false&email_confirmed=true&from=2024-01-01&to=2024-02-01&order=asc&limit=10&cursor=c1", nil)
+	rr := httptest.NewRecorder()
+	h.List(rr, r)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "approx_total", "a failed estimate is omitted, not reported as 0")
+	svc.AssertExpectations(t)
+}
+
+func TestList_OmitsTableWideTotalInsideATenant(t *testing.T) {
+	svc := &mockUserSvc{}
+	svc.On("List", mock.Anything, mock.Anything, 50, "").Return([]domain.User{{UserID: "u1"}}, "", nil)
+	svc.On("ApproxTotal", mock.Anything).Return(int64(0), domain.ErrTableWideCount)
+	h := NewUserHandler(svc, nil)
+
+	rr := httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "approx_total")
+}
+
+func TestList_IncludesApproxTotal(t *testing.T) {
+	svc := &mockUserSvc{}
+	svc.On("List", mock.Anything, mock.Anything, 50, "").Return([]domain.User{{UserID: "u1"}}, "", nil)
+	svc.On("ApproxTotal", mock.Anything).Return(int64(1234), nil)
+	h := NewUserHandler(svc, nil)
+
+	rr := httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp CursorUsersEnvelope
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.NotNil(t, resp.ApproxTotal)
+	assert.Equal(t, int64(1234), *resp.ApproxTotal)
+	assert.Equal(t, 1, resp.Returned)
+}
+
+func TestList_InvalidOrderIsBadRequest(t *testing.T) {
+	svc := &mockUserSvc{}
+	h := NewUserHandler(svc, nil)
+
+	rr := httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/v1/users?order=sideways", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	svc.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- Bulk tests ---
+
+func TestBulk_ReportsCounts(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	req := domain.BulkUserRequest{Action: domain.BulkDisable, UserIDs: []string{"u1", "u2"}}
+	svc.On("Bulk", mock.Anything, "admin1", req).Return([]domain.BulkUserResult{
+		{UserID: "u1", OK: true},
+		{UserID: "u2", Error: "user not found: not found"},
+	}, nil)
+	h := NewUserHandler(svc, nil)
+
+	body, _ := json.Marshal(req)
+	r := bearerReq(t, p, http.MethodPost, "/v1/admin/users/bulk", "admin1", domain.RoleAdmin, body)
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.Bulk), rr, r)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp BulkUsersEnvelope
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Len(t, resp.Results, 2)
+}
+
+func TestBulk_UnknownActionIsValidationError(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	h := NewUserHandler(svc, nil)
+
+	r := bearerReq(t, p, http.MethodPost, "/v1/admin/users/bulk", "admin1", domain.RoleAdmin,
+		[]byte(`{"action":"purge","user_ids":["u1"]}`))
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.Bulk), rr, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	svc.AssertNotCalled(t, "Bulk", mock.Anything, mock.Anything, mock.Anything)
+}
+
 // --- Update tests ---
 
 func TestUpdate_MissingClaims(t *testing.T) {
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	r := withChiID(httptest.NewRequest(http.MethodPut, "/v1/users/u1", nil), "u1")
 	rr := httptest.NewRecorder()
 	h.Update(rr, r)
@@ -270,7 +447,7 @@ func TestUpdate_MissingClaims(t *testing.T) {
 func TestUpdate_NotOwnerOrAdmin(t *testing.T) {
 	p := newTestJWTProvider(t)
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 
 	r := bearerReq(t, p, http.MethodPut, "/v1/users/u2", "u1", domain.RoleUser, nil)
 	r = withChiID(r, "u2") // u1 trying to update u2
@@ -283,7 +460,7 @@ func TestUpdate_NotOwnerOrAdmin(t *testing.T) {
 func TestUpdate_NonAdmin_CannotSetRole(t *testing.T) {
 	p := newTestJWTProvider(t)
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	role := domain.RoleAdmin
 	body, _ := json.Marshal(domain.UpdateUserRequest{Role: &role})
 
@@ -295,12 +472,29 @@ func TestUpdate_NonAdmin_CannotSetRole(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, rr.Code)
 }
 
+func TestUpdate_NonAdmin_MetadataKeysAreChecked(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	svc.On("CheckSelfMetadata", mock.Anything).Return(domain.ErrForbidden)
+	h := NewUserHandler(svc, nil)
+	tier := "gold"
+	body, _ := json.Marshal(domain.UpdateUserRequest{Metadata: map[string]*string{"tier": &tier}})
+
+	r := bearerReq(t, p, http.MethodPut, "/v1/users/u1", "u1", domain.RoleUser, body)
+	r = withChiID(r, "u1")
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.Update), rr, r)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	svc.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestUpdate_HappyPath_SelfUpdate(t *testing.T) {
 	p := newTestJWTProvider(t)
 	svc := &mockUserSvc{}
 	updated := &domain.User{UserID: "u1", Username: "alice2", Email: "alice@example.com"}
 	svc.On("Update", mock.Anything, "u1", mock.Anything).Return(updated, nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	newName := "alice2"
 	body, _ := json.Marshal(domain.UpdateUserRequest{Username: &newName})
 
@@ -321,7 +515,7 @@ func TestUpdate_Admin_CanSetRole(t *testing.T) {
 	svc := &mockUserSvc{}
 	updated := &domain.User{UserID: "u2", Username: "bob", Role: domain.RoleAdmin}
 	svc.On("Update", mock.Anything, "u2", mock.Anything).Return(updated, nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	newRole := domain.RoleAdmin
 	body, _ := json.Marshal(domain.UpdateUserRequest{Role: &newRole})
 
@@ -334,11 +528,45 @@ func TestUpdate_Admin_CanSetRole(t *testing.T) {
 	svc.AssertExpectations(t)
 }
 
+// --- ChangeRole tests ---
+
+func TestChangeRole_PassesActorAndTarget(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	req := domain.ChangeRoleRequest{Role: "Editor", RevokeSessions: true}
+	updated := &domain.User{UserID: "u2", Username: "bob", Role: "Editor"}
+	svc.On("ChangeRole", mock.Anything, "admin1", "u2", req).Return(updated, nil)
+	h := NewUserHandler(svc, nil)
+	body, _ := json.Marshal(req)
+
+	r := bearerReq(t, p, http.MethodPut, "/v1/admin/users/u2/role", "admin1", domain.RoleAdmin, body)
+	r = withChiID(r, "u2")
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.ChangeRole), rr, r)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestChangeRole_MissingRoleIs422(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	h := NewUserHandler(svc, nil)
+
+	r := bearerReq(t, p, http.MethodPut, "/v1/admin/users/u2/role", "admin1", domain.RoleAdmin, []byte(`{}`))
+	r = withChiID(r, "u2")
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.ChangeRole), rr, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	svc.AssertNotCalled(t, "ChangeRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 // --- Delete tests ---
 
 func TestDelete_MissingClaims(t *testing.T) {
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	r := withChiID(httptest.NewRequest(http.MethodDelete, "/v1/users/u1", nil), "u1")
 	rr := httptest.NewRecorder()
 	h.Delete(rr, r)
@@ -348,7 +576,7 @@ func TestDelete_MissingClaims(t *testing.T) {
 func TestDelete_NotOwnerOrAdmin(t *testing.T) {
 	p := newTestJWTProvider(t)
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 
 	r := bearerReq(t, p, http.MethodDelete, "/v1/users/u2", "u1", domain.RoleUser, nil)
 	r = withChiID(r, "u2") // u1 trying to delete u2
@@ -362,7 +590,7 @@ func TestDelete_HappyPath_SelfDelete(t *testing.T) {
 	p := newTestJWTProvider(t)
 	svc := &mockUserSvc{}
 	svc.On("Delete", mock.Anything, "u1").Return(nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 
 	r := bearerReq(t, p, http.MethodDelete, "/v1/users/u1", "u1", domain.RoleUser, nil)
 	r = withChiID(r, "u1")
@@ -377,7 +605,7 @@ func TestDelete_Admin_DeletesOtherUser(t *testing.T) {
 	p := newTestJWTProvider(t)
 	svc := &mockUserSvc{}
 	svc.On("Delete", mock.Anything, "u2").Return(nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 
 	r := bearerReq(t, p, http.MethodDelete, "/v1/users/u2", "admin1", domain.RoleAdmin, nil)
 	r = withChiID(r, "u2")
@@ -392,7 +620,7 @@ func TestDelete_Admin_DeletesOtherUser(t *testing.T) {
 
 func TestChangePassword_MissingClaims(t *testing.T) {
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	r := httptest.NewRequest(http.MethodPost, "/v1/users/me/password", nil)
 	rr := httptest.NewRecorder()
 	h.ChangePassword(rr, r)
@@ -402,7 +630,7 @@ func TestChangePassword_MissingClaims(t *testing.T) {
 func TestChangePassword_InvalidBody(t *testing.T) {
 	p := newTestJWTProvider(t)
 	svc := &mockUserSvc{}
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	body, _ := json.Marshal(map[string]string{"current_password": "old"}) // missing new_password
 
 	r := bearerReq(t, p, http.MethodPost, "/v1/users/me/password", "u1", domain.RoleUser, body)
@@ -415,8 +643,9 @@ func TestChangePassword_InvalidBody(t *testing.T) {
 func TestChangePassword_HappyPath(t *testing.T) {
 	p := newTestJWTProvider(t)
 	svc := &mockUserSvc{}
+	svc.On("RequireTrustedDevice", mock.Anything, "dev1").Return(nil)
 	svc.On("ChangePassword", mock.Anything, "u1", "oldpass1", "newpass123").Return(nil)
-	h := NewUserHandler(svc)
+	h := NewUserHandler(svc, nil)
 	body, _ := json.Marshal(ChangePasswordRequest{CurrentPassword: "oldpass1", NewPassword: "newpass123"})
 
 	r := bearerReq(t, p, http.MethodPost, "/v1/users/me/password", "u1", domain.RoleUser, body)
@@ -426,3 +655,18 @@ func TestChangePassword_HappyPath(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 	svc.AssertExpectations(t)
 }
+
+func TestChangePassword_UntrustedDevice(t *testing.T) {
+	p := newTestJWTProvider(t)
+	svc := &mockUserSvc{}
+	svc.On("RequireTrustedDevice", mock.Anything, "dev1").Return(domain.ErrForbidden)
+	h := NewUserHandler(svc, nil)
+	body, _ := json.Marshal(ChangePasswordRequest{CurrentPassword: "oldpass1", NewPassword: "newpass123"})
+
+	r := bearerReq(t, p, http.MethodPost, "/v1/users/me/password", "u1", domain.RoleUser, body)
+	rr := httptest.NewRecorder()
+	serveAuthed(p, http.HandlerFunc(h.ChangePassword), rr, r)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	svc.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubKeys knows "good" as an admin key and "read" as a key limited to
+// profile:read; "broken" fails the lookup.
+type stubKeys struct{}
+
+func (stubKeys) Verify(_ context.Context, key string) (*domain.APIKeyPrincipal, error) {
+	switch key {
+	case "good":
+		return &domain.APIKeyPrincipal{KeyID: "k1", UserID: "svc", Role: domain.RoleAdmin, Scopes: []string{domain.ScopeAdmin}, TenantID: "acme"}, nil
+	case "read":
+		return &domain.APIKeyPrincipal{KeyID: "k2", UserID: "svc", Role: domain.RoleAdmin, Scopes: []string{"profile:read"}}, nil
+	case "broken":
+		return nil, errors.New("dynamo unavailable")
+	}
+	return nil, domain.ErrUnauthorized
+}
+
+// rejectAll stands in for Auth: requests without a key are refused.
+func rejectAll(http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONError(w, http.StatusUnauthorized, "missing or invalid authorization header")
+	})
+}
+
+func keyRequest(method, target, key string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	if key != "" {
+		req.Header.Set(APIKeyHeader, key)
+	}
+	return req
+}
+
+func TestAPIKeyAuth_InjectsClaims(t *testing.T) {
+	var got *jwtinfra.Claims
+	var viaKey, noSession bool
+	capture := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = ClaimsFromContext(r.Context())
+		viaKey, noSession = ViaAPIKey(r.Context()), sessionless(r.Context())
+	})
+
+	rr := httptest.NewRecorder()
+	APIKeyAuth(stubKeys{}, rejectAll)(capture).ServeHTTP(rr, keyRequest(http.MethodGet, "/", "good"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.NotNil(t, got)
+	assert.Equal(t, "svc", got.UserID)
+	assert.Equal(t, domain.RoleAdmin, got.Role)
+	assert.Equal(t, "acme", got.Tenant)
+	assert.True(t, got.Scoped())
+	assert.True(t, viaKey)
+	assert.True(t, noSession)
+}
+
+func TestAPIKeyAuth_Rejections(t *testing.T) {
+	cases := map[string]int{
+		"":       http.StatusUnauthorized, // falls back to rejectAll
+		"forged": http.StatusUnauthorized,
+		"broken": http.StatusInternalServerError,
+	}
+	for key, want := range cases {
+		rr := httptest.NewRecorder()
+		APIKeyAuth(stubKeys{}, rejectAll)(http.HandlerFunc(okHandler)).ServeHTTP(rr, keyRequest(http.MethodGet, "/", key))
+		assert.Equal(t, want, rr.Code, key)
+	}
+}
+
+func TestAPIKeyAuth_PolicyNeedsScopes(t *testing.T) {
+	p, err := ParseRoutePolicy([]byte(`{"rules":[
+		{"method":"GET","pattern":"/v1/users/{id}","scope":"profile:read"},
+		{"method":"*","pattern":"/v1/admin/users","roles":["Admin"]}
+	]}`))
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(APIKeyAuth(stubKeys{}, rejectAll), p.Enforce)
+		r.Get("/v1/users/{id}", okHandler)
+		r.Delete("/v1/users/{id}", okHandler)
+		r.Get("/v1/admin/users", okHandler)
+	})
+
+	cases := []struct {
+		key, method, target string
+		want                int
+	}{
+		{"read", http.MethodGet, "/v1/users/u1", http.StatusOK},
+		{"good", http.MethodGet, "/v1/users/u1", http.StatusForbidden},
+		{"good", http.MethodGet, "/v1/admin/users", http.StatusOK},
+		{"read", http.MethodGet, "/v1/admin/users", http.StatusForbidden},
+		{"good", http.MethodDelete, "/v1/users/u1", http.StatusForbidden}, // no rule, so no scope
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, keyRequest(tc.method, tc.target, tc.key))
+		assert.Equal(t, tc.want, rr.Code, "%s %s %s", tc.key, tc.method, tc.target)
+	}
+}
@@ -33,6 +33,24 @@ func Auth(provider *jwtinfra.Provider) func(http.Handler) http.Handler {
 	}
 }
 
+// WhenBearer applies mws only to requests carrying a bearer token, so a route
+// can serve signed-in callers alongside ones with credentials of their own.
+func WhenBearer(mws ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withBearer := next
+		for i := len(mws) - 1; i >= 0; i-- {
+			withBearer = mws[i](withBearer)
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+				withBearer.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // ClaimsFromContext extracts JWT claims from the request context.
 func ClaimsFromContext(ctx context.Context) (*jwtinfra.Claims, bool) {
 	c, ok := ctx.Value(claimsKey).(*jwtinfra.Claims)
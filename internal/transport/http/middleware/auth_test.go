@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -97,7 +98,7 @@ func TestAuth_ExpiredToken(t *testing.T) {
 func TestAuth_ValidToken_InjectsClaims(t *testing.T) {
 	p := newTestProvider(t)
 
-	signed, err := p.Sign("u1", "dev1", "user", "sess1")
+	signed, err := p.Sign(context.Background(), "u1", "dev1", "user", "sess1")
 	require.NoError(t, err)
 
 	var gotClaims *jwtinfra.Claims
@@ -116,3 +117,32 @@ func TestAuth_ValidToken_InjectsClaims(t *testing.T) {
 	assert.Equal(t, "u1", gotClaims.UserID)
 	assert.Equal(t, "user", gotClaims.Role)
 }
+
+func TestWhenBearer_OnlyAuthenticatesBearerRequests(t *testing.T) {
+	p := newTestProvider(t)
+	var gotClaims bool
+	h := WhenBearer(Auth(p))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, gotClaims = ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.SetBasicAuth("client", "secret")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, gotClaims)
+
+	req.Header.Set("Authorization", "Bearer not-a-jwt")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	signed, err := p.Sign(context.Background(), "u1", "dev1", "Admin", "sess1")
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, gotClaims)
+}
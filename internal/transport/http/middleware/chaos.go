@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/pkg/chaos"
+)
+
+// ChaosControlPath is never faulted, so rules can always be cleared.
+const ChaosControlPath = "/v1/admin/chaos"
+
+// Chaos applies c's http rules to matching request paths: the request is
+// delayed and, for failing rules, answered with the rule's status (503 by
+// default) without reaching the handler.
+func Chaos(c *chaos.Controller) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, hit := c.Pick(chaos.TargetHTTP, r.URL.Path)
+			if !hit || r.URL.Path == ChaosControlPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+			err := rule.Apply(r.Context())
+			if err == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !errors.Is(err, chaos.ErrInjected) {
+				return // the client went away during the injected latency
+			}
+			status := rule.Status
+			if status == 0 {
+				status = http.StatusServiceUnavailable
+			}
+			w.Header().Set("X-Chaos-Injected", "true")
+			writeJSONError(w, status, "injected failure")
+		})
+	}
+}
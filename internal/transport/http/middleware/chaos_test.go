@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/pkg/chaos"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveChaos(c *chaos.Controller, path string) *httptest.ResponseRecorder {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	rr := httptest.NewRecorder()
+	Chaos(c)(ok).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	return rr
+}
+
+func TestChaos_FailsMatchingRequests(t *testing.T) {
+	c := chaos.NewController()
+	c.Set([]chaos.Rule{{Target: chaos.TargetHTTP, Match: "/v1/files", Percent: 100, Fail: true, Status: 502}})
+
+	rr := serveChaos(c, "/v1/files/s3/1")
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Equal(t, "true", rr.Header().Get("X-Chaos-Injected"))
+
+	assert.Equal(t, http.StatusOK, serveChaos(c, "/v1/users").Code)
+}
+
+func TestChaos_DefaultsTo503AndSparesControlPath(t *testing.T) {
+	c := chaos.NewController()
+	c.Set([]chaos.Rule{{Target: chaos.TargetHTTP, Percent: 100, Fail: true}})
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveChaos(c, "/v1/users").Code)
+	assert.Equal(t, http.StatusOK, serveChaos(c, ChaosControlPath).Code)
+}
+
+func TestChaos_LatencyOnlyStillServes(t *testing.T) {
+	c := chaos.NewController()
+	c.Set([]chaos.Rule{{Target: chaos.TargetHTTP, Percent: 100, LatencyMS: 1}})
+
+	assert.Equal(t, http.StatusOK, serveChaos(c, "/v1/users").Code)
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/go-api-nosql/internal/domain"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+)
+
+const clientCertKey contextKey = "client_cert"
+
+// CertPrincipal maps a client certificate subject to the API identity it
+// acts as. Subject is the certificate's distinguished name in RFC 2253 form
+// (e.g. "CN=billing,O=Acme Corp"); Role is checked by the route policy like a
+// JWT role. Tenant is the only tenant the certificate may act in when
+// TENANT_MODE is set, like the tenant claim of a JWT.
+type CertPrincipal struct {
+	Subject string `json:"subject"`
+	UserID  string `json:"user_id"`
+	Role    string `json:"role"`
+	Tenant  string `json:"tenant,omitempty"`
+}
+
+// CertPrincipals is the set of client certificates allowed to call the API.
+type CertPrincipals struct {
+	Principals []CertPrincipal `json:"principals"`
+
+	bySubject map[string]CertPrincipal
+}
+
+// LoadCertPrincipals reads and validates the JSON principal mapping at path.
+// With tenants set, every principal must name a valid tenant.
+func LoadCertPrincipals(path string, tenants bool) (*CertPrincipals, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cert principals: %w", err)
+	}
+	var p CertPrincipals
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse cert principals: %w", err)
+	}
+	p.bySubject = make(map[string]CertPrincipal, len(p.Principals))
+	for i, cp := range p.Principals {
+		if cp.Subject == "" || cp.UserID == "" || cp.Role == "" {
+			return nil, fmt.Errorf("cert principal %d: subject, user_id and role are required", i)
+		}
+		if tenants && !tenant.Valid(cp.Tenant) {
+			return nil, fmt.Errorf("cert principal %d: a valid tenant is required when TENANT_MODE is set", i)
+		}
+		p.bySubject[cp.Subject] = cp
+	}
+	return &p, nil
+}
+
+// ClientCert authenticates requests that arrive with a client certificate
+// verified by the TLS listener, injecting the mapped principal as claims.
+// Requests without one are passed to fallback (normally Auth), so JWTs keep
+// working alongside certificates; a nil fallback makes a certificate required.
+// A verified certificate whose subject is not mapped is rejected.
+func ClientCert(principals *CertPrincipals, fallback func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		var viaFallback http.Handler
+		if fallback != nil {
+			viaFallback = fallback(next)
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				if viaFallback == nil {
+					writeJSONError(w, http.StatusUnauthorized, "client certificate required")
+					return
+				}
+				viaFallback.ServeHTTP(w, r)
+				return
+			}
+			subject := r.TLS.VerifiedChains[0][0].Subject.String()
+			p, ok := principals.bySubject[subject]
+			if !ok {
+				writeJSONError(w, http.StatusForbidden, "client certificate not authorized")
+				return
+			}
+			claims := &jwtinfra.Claims{UserID: p.UserID, Role: p.Role, Scopes: domain.RoleScopes(p.Role), Tenant: p.Tenant}
+			ctx := context.WithValue(r.Context(), claimsKey, claims)
+			ctx = context.WithValue(ctx, clientCertKey, true)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ViaClientCert reports whether the request was authenticated by a client
+// certificate rather than a bearer token. Such requests have no session.
+func ViaClientCert(ctx context.Context) bool {
+	v, _ := ctx.Value(clientCertKey).(bool)
+	return v
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestPrincipals(t *testing.T) *CertPrincipals {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "principals.json")
+	doc := `{"principals": [{"subject": "CN=billing,O=Acme", "user_id": "svc-acme", "role": "Admin", "tenant": "acme"}]}`
+	require.NoError(t, os.WriteFile(path, []byte(doc), 0600))
+	p, err := LoadCertPrincipals(path, false)
+	require.NoError(t, err)
+	return p
+}
+
+func certRequest(cn, org string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn, Organization: []string{org}}}
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	return req
+}
+
+func TestClientCert_MappedSubjectInjectsClaims(t *testing.T) {
+	var got *jwtinfra.Claims
+	var viaCert bool
+	capture := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = ClaimsFromContext(r.Context())
+		viaCert = ViaClientCert(r.Context())
+	})
+
+	rr := httptest.NewRecorder()
+	ClientCert(loadTestPrincipals(t), nil)(capture).ServeHTTP(rr, certRequest("billing", "Acme"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.NotNil(t, got)
+	assert.Equal(t, "svc-acme", got.UserID)
+	assert.Equal(t, "Admin", got.Role)
+	assert.Equal(t, "acme", got.Tenant)
+	assert.True(t, viaCert)
+}
+
+func TestLoadCertPrincipals_TenantModeNeedsTenants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "principals.json")
+	doc := `{"principals": [{"subject": "CN=billing,O=Acme", "user_id": "svc-acme", "role": "Admin"}]}`
+	require.NoError(t, os.WriteFile(path, []byte(doc), 0600))
+
+	_, err := LoadCertPrincipals(path, true)
+	assert.ErrorContains(t, err, "tenant")
+	_, err = LoadCertPrincipals(path, false)
+	assert.NoError(t, err)
+}
+
+func TestClientCert_UnmappedSubjectForbidden(t *testing.T) {
+	rr := httptest.NewRecorder()
+	ClientCert(loadTestPrincipals(t), nil)(http.HandlerFunc(okHandler)).ServeHTTP(rr, certRequest("intruder", "Acme"))
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestClientCert_NoCertFallsBackToJWT(t *testing.T) {
+	p := newTestProvider(t)
+	signed, err := p.Sign(context.Background(), "u1", "dev1", "user", "sess1")
+	require.NoError(t, err)
+	mw := ClientCert(loadTestPrincipals(t), Auth(p))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	rr := httptest.NewRecorder()
+	mw(http.HandlerFunc(okHandler)).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	ClientCert(loadTestPrincipals(t), nil)(http.HandlerFunc(okHandler)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
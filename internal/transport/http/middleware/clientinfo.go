@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-api-nosql/internal/pkg/clientinfo"
+)
+
+// AppVersionHeader names the client app version sent by the mobile apps.
+const AppVersionHeader = "X-App-Version"
+
+// Longer header values are cut so a client cannot bloat the session items
+// they are stored on.
+const (
+	maxUserAgent  = 256
+	maxAppVersion = 32
+)
+
+// ClientInfo puts the request's user agent, app version, client IP and
+// country in the context for the services that record them. The IP is read
+// the way the rate limiter reads it, believing forwarding headers only from
+// proxies. The country comes from countryHeader, which the CDN or gateway in
+// front of the API must set, overwriting any value the client sent; it is
+// read only from requests whose TCP peer is one of proxies, so a client
+// reaching the API directly cannot pick its country.
+func ClientInfo(countryHeader string, proxies IPAllowlist) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			info := clientinfo.Info{
+				UserAgent:  truncate(r.UserAgent(), maxUserAgent),
+				AppVersion: truncate(r.Header.Get(AppVersionHeader), maxAppVersion),
+				IP:         clientIP(r, proxies),
+			}
+			if countryHeader != "" && proxies.Contains(peerIP(r)) {
+				info.Country = strings.ToUpper(truncate(strings.TrimSpace(r.Header.Get(countryHeader)), 2))
+			}
+			next.ServeHTTP(w, r.WithContext(clientinfo.WithInfo(r.Context(), info)))
+		})
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-api-nosql/internal/pkg/clientinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientInfo_ReadsHeaders(t *testing.T) {
+	proxies, err := ParseIPAllowlist([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	var got clientinfo.Info
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = clientinfo.FromContext(r.Context()) })
+	req := httptest.NewRequest(http.MethodPost, "/v1/sessions/login", nil)
+	req.RemoteAddr = "10.0.0.2:443"
+	req.Header.Set("User-Agent", strings.Repeat("a", 300))
+	req.Header.Set(AppVersionHeader, "2.4.1")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	req.Header.Set("CloudFront-Viewer-Country", "kp")
+
+	ClientInfo("CloudFront-Viewer-Country", proxies)(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Len(t, got.UserAgent, maxUserAgent)
+	assert.Equal(t, "2.4.1", got.AppVersion)
+	assert.Equal(t, "203.0.113.7", got.IP)
+	assert.Equal(t, "KP", got.Country)
+}
+
+func TestClientInfo_IgnoresForwardedForFromUntrustedPeer(t *testing.T) {
+	proxies, err := ParseIPAllowlist([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	var got clientinfo.Info
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = clientinfo.FromContext(r.Context()) })
+	req := httptest.NewRequest(http.MethodPost, "/v1/sessions/login", nil)
+	req.RemoteAddr = "198.51.100.9:5000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.Header.Set("X-Real-Ip", "203.0.113.8")
+
+	ClientInfo("", proxies)(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "198.51.100.9", got.IP)
+}
+
+func TestClientInfo_IgnoresCountryFromUntrustedPeer(t *testing.T) {
+	proxies, err := ParseIPAllowlist([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	var got clientinfo.Info
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = clientinfo.FromContext(r.Context()) })
+	req := httptest.NewRequest(http.MethodPost, "/v1/sessions/login", nil)
+	req.RemoteAddr = "198.51.100.9:5000"
+	req.Header.Set("CloudFront-Viewer-Country", "US")
+
+	ClientInfo("CloudFront-Viewer-Country", proxies)(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Empty(t, got.Country)
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// clientIP returns the address rate limiting is decided on and sessions
+// record. It trusts forwarding headers only when they were set by one of
+// proxies: the TCP peer must be a trusted proxy, and X-Forwarded-For is then
+// read from the right, skipping trusted hops, so the first untrusted hop is
+// the client. Entries left of it were written by the client and are never
+// consulted, so a spoofed header can neither claim an exempt address, pick a
+// bucket nor disguise where a session was opened.
+func clientIP(r *http.Request, proxies IPAllowlist) string {
+	peer := peerIP(r)
+	if !proxies.Contains(peer) {
+		return peer
+	}
+	hops := forwardedHops(r)
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !proxies.Contains(hops[i]) {
+			return hops[i]
+		}
+	}
+	if len(hops) > 0 {
+		// Every hop is a trusted proxy: the request started inside the network.
+		return hops[0]
+	}
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-Ip")); xri != "" {
+		return xri
+	}
+	return peer
+}
+
+// peerIP is the address of the TCP peer of r, without the port.
+func peerIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return peer
+}
+
+// forwardedHops lists the X-Forwarded-For entries of r from left to right,
+// across repeated headers.
+func forwardedHops(r *http.Request) []string {
+	var hops []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(h, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// OverloadedCode is the "code" of the 503 answered when a concurrency limit
+// stays saturated.
+const OverloadedCode = "overloaded"
+
+// ConcurrencyLimiter caps how many requests are served at once, shedding load
+// before it piles up in DynamoDB and memory. A request over the cap waits up
+// to the queue timeout for a slot.
+type ConcurrencyLimiter struct {
+	slots  chan struct{}
+	wait   time.Duration
+	exempt func(*http.Request) bool
+}
+
+// NewConcurrencyLimiter serves up to limit requests at once; others wait up
+// to wait for one of them to finish.
+func NewConcurrencyLimiter(limit int, wait time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{slots: make(chan struct{}, limit), wait: wait}
+}
+
+// Except serves requests matching exempt without taking a slot, such as
+// health checks that must keep answering on an instance that is merely busy.
+func (c *ConcurrencyLimiter) Except(exempt func(*http.Request) bool) *ConcurrencyLimiter {
+	c.exempt = exempt
+	return c
+}
+
+// Limit is the middleware that holds a slot for the duration of each request.
+// A request that gets no slot within the queue timeout is answered 503 with
+// code "overloaded" and a Retry-After; one whose client goes away while
+// queued is dropped. Requests exempted with Except are never queued.
+func (c *ConcurrencyLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.exempt != nil && c.exempt(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !c.acquire(r) {
+			if r.Context().Err() != nil {
+				return
+			}
+			retry := int(math.Max(1, math.Ceil(c.wait.Seconds())))
+			w.Header().Set("Retry-After", strconv.Itoa(retry))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "server is busy, retry later", "code": OverloadedCode})
+			return
+		}
+		defer func() { <-c.slots }()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acquire takes a slot, waiting up to c.wait or until the request is
+// cancelled.
+func (c *ConcurrencyLimiter) acquire(r *http.Request) bool {
+	select {
+	case c.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if c.wait <= 0 {
+		return false
+	}
+	timer := time.NewTimer(c.wait)
+	defer timer.Stop()
+	select {
+	case c.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingHandler holds each request until release is closed.
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestConcurrencyLimiter_ShedsWhenSaturated(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	h := NewConcurrencyLimiter(1, 10*time.Millisecond).Limit(blockingHandler(started, release))
+
+	done := make(chan int)
+	go func() {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/admin/export/users.csv", nil))
+		done <- rr.Code
+	}()
+	<-started
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/admin/export/users.csv", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"server is busy, retry later","code":"overloaded"}`, rr.Body.String())
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/admin/export/users.csv", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, "the slot is freed when the request ends")
+	<-started
+}
+
+func TestConcurrencyLimiter_QueuedRequestGetsFreedSlot(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	h := NewConcurrencyLimiter(1, time.Second).Limit(blockingHandler(started, release))
+
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/files/s3", nil))
+			codes <- rr.Code
+		}()
+	}
+	<-started
+	close(release)
+	assert.Equal(t, http.StatusOK, <-codes)
+	assert.Equal(t, http.StatusOK, <-codes)
+}
+
+func TestConcurrencyLimiter_SparesExemptRequestsAndCancelledClients(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	defer close(release)
+	probe := func(r *http.Request) bool { return r.URL.Path == "/v1/health-check/ping" }
+	h := NewConcurrencyLimiter(1, time.Minute).Except(probe).Limit(blockingHandler(started, release))
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+	<-started
+
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/health-check/ping", nil))
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/users", nil).WithContext(ctx))
+	assert.Empty(t, rr.Header().Get("Retry-After"), "nothing is written to a client that left")
+}
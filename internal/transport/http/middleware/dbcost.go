@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-api-nosql/internal/pkg/dbcost"
+	"github.com/go-chi/chi/v5"
+)
+
+// DynamoCapacityHeader reports the DynamoDB capacity a response consumed,
+// e.g. "calls=3 scans=0 read=1.5 write=2".
+const DynamoCapacityHeader = "X-Dynamo-Capacity"
+
+// DynamoCost meters the DynamoDB capacity consumed by each request and adds
+// it to ledger under the request's method and chi route pattern. It must wrap
+// the chi router so the pattern is known once the request is served. With
+// header set, the usage so far is also sent in DynamoCapacityHeader; meant
+// for development, it exposes the cost of every call to clients.
+func DynamoCost(ledger *dbcost.Ledger, header bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, meter := dbcost.NewContext(r.Context())
+			if header {
+				w = &costWriter{ResponseWriter: w, meter: meter}
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+			pattern := "unmatched"
+			if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+				pattern = rctx.RoutePattern()
+			}
+			ledger.Record(r.Method+" "+pattern, meter.Usage())
+		})
+	}
+}
+
+// costWriter adds DynamoCapacityHeader just before the response headers go
+// out, which is after the handler's DynamoDB calls in almost every case.
+type costWriter struct {
+	http.ResponseWriter
+	meter       *dbcost.Meter
+	wroteHeader bool
+}
+
+func (cw *costWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		u := cw.meter.Usage()
+		cw.Header().Set(DynamoCapacityHeader, fmt.Sprintf("calls=%d scans=%d read=%g write=%g",
+			u.Calls, u.Scans, u.ReadUnits, u.WriteUnits))
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *costWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *costWriter) Unwrap() http.ResponseWriter { return cw.ResponseWriter }
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/pkg/dbcost"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamoCost_RecordsPerRoute(t *testing.T) {
+	ledger := dbcost.NewLedger()
+	r := chi.NewRouter()
+	r.Use(DynamoCost(ledger, true))
+	r.Get("/v1/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		m := dbcost.FromContext(r.Context())
+		m.Add(dbcost.Call{Units: 0.5, Sources: map[string]float64{"users": 0.5}})
+		m.Add(dbcost.Call{Write: true, Units: 1, Sources: map[string]float64{"audit_logs": 1}})
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, id := range []string{"u1", "u2"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/users/"+id, nil))
+		assert.Equal(t, "calls=2 scans=0 read=0.5 write=1", rr.Header().Get(DynamoCapacityHeader))
+	}
+
+	routes := ledger.Snapshot()
+	require.Len(t, routes, 1)
+	assert.Equal(t, "GET /v1/users/{id}", routes[0].Route)
+	assert.Equal(t, 2, routes[0].Requests)
+	assert.Equal(t, 4, routes[0].Calls)
+	assert.InDelta(t, 1.0, routes[0].ReadUnits, 1e-9)
+	assert.InDelta(t, 2.0, routes[0].WriteUnits, 1e-9)
+	assert.Equal(t, map[string]float64{"users": 1, "audit_logs": 2}, routes[0].Sources)
+}
+
+func TestDynamoCost_NoHeaderOutsideDevelopment(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(DynamoCost(dbcost.NewLedger(), false))
+	r.Get("/", okHandler)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, rr.Header().Get(DynamoCapacityHeader))
+}
@@ -1,9 +1,11 @@
 package middleware
 
 import (
-	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/go-api-nosql/internal/pkg/accesslog"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 )
 
 // responseWriter wraps http.ResponseWriter to capture status code.
@@ -17,18 +19,28 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// RequestLogger logs each HTTP request with method, path, status, and duration.
-func RequestLogger(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(rw, r)
-		slog.Info("request",
-			"method", r.Method,
-			"path", r.URL.Path,
-			"status", rw.status,
-			"duration_ms", time.Since(start).Milliseconds(),
-			"remote_addr", r.RemoteAddr,
-		)
-	})
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rw *responseWriter) Unwrap() http.ResponseWriter { return rw.ResponseWriter }
+
+// RequestLogger writes an access log entry for each HTTP request with its
+// method, path, status and duration. It reads the request ID, so it runs
+// after chi's RequestID middleware.
+func RequestLogger(l *accesslog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+			l.Log(accesslog.Entry{
+				Time:       start.UTC(),
+				RequestID:  chimiddleware.GetReqID(r.Context()),
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				Status:     rw.status,
+				DurationMS: time.Since(start).Milliseconds(),
+				RemoteAddr: r.RemoteAddr,
+				UserAgent:  r.UserAgent(),
+			})
+		})
+	}
 }
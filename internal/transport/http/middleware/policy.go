@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-chi/chi/v5"
+)
+
+// PolicyRule requires one of Roles for requests whose method and chi route
+// pattern match. Method "*" (or empty) matches any method. Scope, when set,
+// is also required of the token, and opens the route to OAuth access tokens
+// granted it.
+type PolicyRule struct {
+	Method  string   `json:"method"`
+	Pattern string   `json:"pattern"`
+	Roles   []string `json:"roles,omitempty"`
+	Scope   string   `json:"scope,omitempty"`
+}
+
+// RoutePolicy is a declarative route-to-role mapping. Routes without a
+// matching rule are open to any authenticated caller, except OAuth access
+// tokens and API keys, which only reach routes whose rule names one of their
+// scopes, and routes under adminPrefix, which fail closed to the Admin role.
+// Rules requiring the Admin role count as naming domain.ScopeAdmin.
+type RoutePolicy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// ParseRoutePolicy decodes a JSON policy document and validates its rules.
+func ParseRoutePolicy(data []byte) (*RoutePolicy, error) {
+	var p RoutePolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse route policy: %w", err)
+	}
+	for i, rule := range p.Rules {
+		if rule.Pattern == "" || (len(rule.Roles) == 0 && rule.Scope == "") {
+			return nil, fmt.Errorf("route policy rule %d: pattern and roles or scope are required", i)
+		}
+	}
+	return &p, nil
+}
+
+// LoadRoutePolicy reads and parses the JSON policy file at path.
+func LoadRoutePolicy(path string) (*RoutePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read route policy: %w", err)
+	}
+	return ParseRoutePolicy(data)
+}
+
+// adminPrefix is where admin routes are mounted; forgetting one of them in the
+// policy must not open it to every user.
+const adminPrefix = "/v1/admin/"
+
+// ruleFor returns the first rule matching method and pattern. Unmatched admin
+// routes get an implicit rule requiring the Admin role.
+func (p *RoutePolicy) ruleFor(method, pattern string) (PolicyRule, bool) {
+	for _, rule := range p.Rules {
+		if rule.Pattern != pattern {
+			continue
+		}
+		if rule.Method == "" || rule.Method == "*" || rule.Method == method {
+			return rule, true
+		}
+	}
+	if strings.HasPrefix(pattern, adminPrefix) {
+		return PolicyRule{Method: method, Pattern: pattern, Roles: []string{domain.RoleAdmin}}, false
+	}
+	return PolicyRule{}, false
+}
+
+// Enforce is the middleware that applies the policy. It must run after Auth and
+// inside a chi router so the matched route pattern is available.
+func (p *RoutePolicy) Enforce(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := ""
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			pattern = rctx.RoutePattern()
+		}
+		rule, _ := p.ruleFor(r.Method, pattern)
+		scope := rule.Scope
+		if scope == "" && slices.Contains(rule.Roles, domain.RoleAdmin) {
+			scope = domain.ScopeAdmin
+		}
+		claims, _ := ClaimsFromContext(r.Context())
+		if claims != nil && (claims.Delegated() || ViaAPIKey(r.Context())) && scope == "" {
+			writeJSONError(w, http.StatusForbidden, errMissingScope)
+			return
+		}
+		h := next
+		if len(rule.Roles) > 0 {
+			h = RequireRole(rule.Roles...)(h)
+		}
+		if scope != "" && claims != nil && claims.Scoped() {
+			h = RequireScope(scope)(h)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// policyRouter mounts GET and DELETE /v1/users/{id} behind the given policy,
+// injecting claims with role into every request.
+func policyRouter(p *RoutePolicy, role string) http.Handler {
+	return policyRouterFor(p, &jwtinfra.Claims{Role: role})
+}
+
+func policyRouterFor(p *RoutePolicy, claims *jwtinfra.Claims) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/v1", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					ctx := context.WithValue(req.Context(), claimsKey, claims)
+					next.ServeHTTP(w, req.WithContext(ctx))
+				})
+			})
+			r.Use(p.Enforce)
+			r.Get("/users/{id}", okHandler)
+			r.Delete("/users/{id}", okHandler)
+		})
+	})
+	return r
+}
+
+func TestParseRoutePolicy_RejectsRuleWithoutRoles(t *testing.T) {
+	_, err := ParseRoutePolicy([]byte(`{"rules":[{"method":"GET","pattern":"/v1/users"}]}`))
+	assert.Error(t, err)
+}
+
+func TestRoutePolicy_EnforcesMatchingRule(t *testing.T) {
+	p, err := ParseRoutePolicy([]byte(`{"rules":[{"method":"DELETE","pattern":"/v1/users/{id}","roles":["Admin"]}]}`))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	policyRouter(p, "User").ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/v1/users/u1", nil))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = httptest.NewRecorder()
+	policyRouter(p, "Admin").ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/v1/users/u1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRoutePolicy_UnmatchedRouteIsAllowed(t *testing.T) {
+	p, err := ParseRoutePolicy([]byte(`{"rules":[{"method":"DELETE","pattern":"/v1/users/{id}","roles":["Admin"]}]}`))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	policyRouter(p, "User").ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/users/u1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRoutePolicy_UnmatchedAdminRouteRequiresAdmin(t *testing.T) {
+	p, err := ParseRoutePolicy([]byte(`{"rules":[]}`))
+	require.NoError(t, err)
+	cases := []struct {
+		name   string
+		claims *jwtinfra.Claims
+		want   int
+	}{
+		{"user", &jwtinfra.Claims{Role: "User"}, http.StatusForbidden},
+		{"admin", &jwtinfra.Claims{Role: domain.RoleAdmin}, http.StatusOK},
+		{"admin token without the admin scope", &jwtinfra.Claims{Role: domain.RoleAdmin, Scopes: []string{"files:read"}}, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		r := chi.NewRouter()
+		r.Group(func(r chi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), claimsKey, tc.claims)))
+				})
+			})
+			r.Use(p.Enforce)
+			r.Get("/v1/admin/widgets", okHandler)
+		})
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/admin/widgets", nil))
+		assert.Equal(t, tc.want, rr.Code, tc.name)
+	}
+}
+
+func TestRoutePolicy_ScopedTokensNeedRuleScope(t *testing.T) {
+	p, err := ParseRoutePolicy([]byte(`{"rules":[
+		{"method":"GET","pattern":"/v1/users/{id}","scope":"profile:read"}
+	]}`))
+	require.NoError(t, err)
+	cases := []struct {
+		name   string
+		claims *jwtinfra.Claims
+		method string
+		want   int
+	}{
+		{"token from before scopes", &jwtinfra.Claims{Role: "User"}, http.MethodGet, http.StatusOK},
+		{"first-party token", &jwtinfra.Claims{Role: "User", Scopes: domain.RoleScopes("User")}, http.MethodGet, http.StatusOK},
+		{"first-party token without the scope", &jwtinfra.Claims{Role: "User", Scopes: []string{"files:read"}}, http.MethodGet, http.StatusForbidden},
+		{"granted scope", &jwtinfra.Claims{Role: "User", ClientID: "c1", Scopes: []string{"files:read", "profile:read"}}, http.MethodGet, http.StatusOK},
+		{"missing scope", &jwtinfra.Claims{Role: "User", ClientID: "c1", Scopes: []string{"files:read"}}, http.MethodGet, http.StatusForbidden},
+		{"route without a scope rule", &jwtinfra.Claims{Role: "Admin", ClientID: "c1", Scopes: []string{"profile:read"}}, http.MethodDelete, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			policyRouterFor(p, tc.claims).ServeHTTP(rr, httptest.NewRequest(tc.method, "/v1/users/u1", nil))
+			assert.Equal(t, tc.want, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/go-chi/chi/v5"
+)
+
+// ProfileIncompleteCode is the "code" of the 428 answered while the caller's
+// profile is incomplete.
+const ProfileIncompleteCode = "profile_incomplete"
+
+// profileExempt lists the routes served while a profile is incomplete: those
+// needed to fill it in, confirm contact details and manage the session, and
+// the device verification that password and email changes may ask for.
+var profileExempt = map[string]bool{
+	"/v1/sessions":                     true,
+	"/v1/sessions/logout":              true,
+	"/v1/users/{id}":                   true,
+	"/v1/users/me/password":            true,
+	"/v1/confirm-email/{action}":       true,
+	"/v1/confirm-phone/{action}":       true,
+	"/v1/device-verification/{action}": true,
+}
+
+// UserGetter loads the caller's account.
+type UserGetter interface {
+	Get(ctx context.Context, userID string) (*domain.User, error)
+}
+
+// ProfileGate answers 428 with code "profile_incomplete" and the missing
+// fields to non-admin callers whose profile lacks a field that
+// domain.User.MissingProfileFields checks, except on the routes in
+// profileExempt.
+//
+// Complete profiles are cached per user for ttl; incomplete ones are checked
+// on every request, so filling in the last field takes effect at once.
+type ProfileGate struct {
+	users    UserGetter
+	complete *trustCache // user_id
+}
+
+// NewProfileGate creates a gate caching complete profiles for ttl; a zero ttl
+// checks every request. ctx bounds the background cache cleanup.
+func NewProfileGate(ctx context.Context, users UserGetter, ttl time.Duration) *ProfileGate {
+	return &ProfileGate{users: users, complete: newTrustCache(ctx, "profile gate cleanup", ttl)}
+}
+
+// Check is the middleware handler. It must run after Auth, inside a chi
+// router so the matched route pattern is available.
+func (g *ProfileGate) Check(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok || claims.Role == domain.RoleAdmin || sessionless(r.Context()) || g.complete.has(claims.UserID) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && profileExempt[rctx.RoutePattern()] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		u, err := g.users.Get(r.Context(), claims.UserID)
+		if err != nil {
+			slog.Error("profile check failed", "user_id", claims.UserID, "err", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		missing := u.MissingProfileFields()
+		if len(missing) == 0 {
+			g.complete.add(claims.UserID)
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionRequired)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":          "complete your profile to continue",
+			"code":           ProfileIncompleteCode,
+			"missing_fields": missing,
+		})
+	})
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUsers struct {
+	user  *domain.User
+	calls int
+}
+
+func (s *stubUsers) Get(_ context.Context, _ string) (*domain.User, error) {
+	s.calls++
+	cp := *s.user
+	return &cp, nil
+}
+
+// profileRouter mounts PUT /v1/users/{id} and POST /v1/messages behind the
+// gate, injecting claims for user u1 with role into every request.
+func profileRouter(g *ProfileGate, role string) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/v1", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					ctx := context.WithValue(req.Context(), claimsKey, &jwtinfra.Claims{UserID: "u1", Role: role})
+					next.ServeHTTP(w, req.WithContext(ctx))
+				})
+			})
+			r.Use(g.Check)
+			r.Put("/users/{id}", okHandler)
+			r.Post("/messages", okHandler)
+		})
+	})
+	return r
+}
+
+func TestProfileGate_RefusesIncompleteProfileWithMissingFields(t *testing.T) {
+	users := &stubUsers{user: &domain.User{UserID: "u1", FirstName: "Jane", LastName: "Doe"}}
+	h := profileRouter(NewProfileGate(context.Background(), users, 0), domain.RoleUser)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	require.Equal(t, http.StatusPreconditionRequired, rr.Code)
+	var body struct {
+		Code          string   `json:"code"`
+		MissingFields []string `json:"missing_fields"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, ProfileIncompleteCode, body.Code)
+	assert.Equal(t, []string{"birthday", "phone"}, body.MissingFields)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/v1/users/u1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, "the profile can still be filled in")
+}
+
+func TestProfileGate_AdminsAreNotGated(t *testing.T) {
+	users := &stubUsers{user: &domain.User{UserID: "u1"}}
+	rr := httptest.NewRecorder()
+
+	profileRouter(NewProfileGate(context.Background(), users, 0), domain.RoleAdmin).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Zero(t, users.calls)
+}
+
+func TestProfileGate_CachesCompleteProfiles(t *testing.T) {
+	phone := "+15550100"
+	users := &stubUsers{user: &domain.User{
+		UserID: "u1", FirstName: "Jane", LastName: "Doe", Phone: &phone,
+		Birthday: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := profileRouter(NewProfileGate(ctx, users, time.Minute), domain.RoleUser)
+
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+	assert.Equal(t, 1, users.calls)
+}
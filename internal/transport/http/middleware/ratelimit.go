@@ -2,12 +2,13 @@ package middleware
 
 import (
 	"context"
-	"net"
+	"math"
 	"net/http"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
 	"golang.org/x/time/rate"
 )
 
@@ -22,6 +23,9 @@ type RateLimiter struct {
 	limiters map[string]*ipLimiter
 	r        rate.Limit
 	burst    int
+	exempt   IPAllowlist
+	buckets  IPBuckets
+	proxies  IPAllowlist
 }
 
 // NewRateLimiter creates a per-IP limiter: r requests/second, burst up to burst requests.
@@ -33,7 +37,26 @@ func NewRateLimiter(ctx context.Context, r rate.Limit, burst int) *RateLimiter {
 		r:        r,
 		burst:    burst,
 	}
-	go rl.cleanup(ctx)
+	lifecycle.Go(ctx, "rate limiter cleanup", rl.cleanup)
+	return rl
+}
+
+// Exempt lets clients in list through without counting their requests.
+func (rl *RateLimiter) Exempt(list IPAllowlist) *RateLimiter {
+	rl.exempt = list
+	return rl
+}
+
+// Buckets sets how client IPs are grouped into buckets; see IPBuckets.
+func (rl *RateLimiter) Buckets(b IPBuckets) *RateLimiter {
+	rl.buckets = b
+	return rl
+}
+
+// TrustProxies sets the proxies whose X-Forwarded-For entries are believed.
+// Without any, clients are identified by their TCP address alone.
+func (rl *RateLimiter) TrustProxies(list IPAllowlist) *RateLimiter {
+	rl.proxies = list
 	return rl
 }
 
@@ -70,16 +93,29 @@ func (rl *RateLimiter) cleanup(ctx context.Context) {
 }
 
 // Limit is the middleware handler that enforces the rate limit per client IP.
-// It reads the real client IP from X-Forwarded-For (set by API Gateway / proxies)
-// and falls back to RemoteAddr for direct connections.
+// The client IP is the TCP peer, or the rightmost X-Forwarded-For hop that is
+// not a trusted proxy when the peer is one; see TrustProxies.
+//
+// Every response carries the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers; rejected requests additionally get Retry-After.
+// Exempt clients get neither. Clients sharing an IPBuckets prefix share a bucket.
 //
 // NOTE: for Lambda + API Gateway deployments this in-process limiter is a
 // secondary defence only — its state is lost on cold starts. Configure
 // API Gateway throttling and/or WAF rate-based rules as the primary layer.
 func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := realIP(r)
-		if !rl.get(ip).Allow() {
+		ip := clientIP(r, rl.proxies)
+		if rl.exempt.Contains(ip) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		l := rl.get(rl.buckets.Key(ip))
+		allowed := l.Allow()
+		tokens := l.Tokens()
+		setRateLimitHeaders(w, rl.burst, tokens, secondsUntil(float64(rl.burst)-tokens, rl.r))
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(secondsUntil(1-tokens, rl.r)))
 			writeJSONError(w, http.StatusTooManyRequests, "too many requests")
 			return
 		}
@@ -87,28 +123,59 @@ func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 	})
 }
 
-// realIP extracts the originating client IP from X-Forwarded-For (first entry),
-// X-Real-Ip, or falls back to the TCP remote address.
-//
-// SECURITY NOTE: X-Forwarded-For can be spoofed by clients if the API is
-// reached directly without a trusted proxy. This limiter should be treated as
-// a secondary defence. Configure rate limits at the API Gateway / WAF level
-// as the primary layer so that untrusted headers never reach this code.
-func realIP(r *http.Request) string {
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// X-Forwarded-For can be a comma-separated list: client, proxy1, proxy2
-		// The leftmost entry is the original client IP.
-		if ip := strings.TrimSpace(strings.SplitN(xff, ",", 2)[0]); ip != "" {
-			return ip
-		}
+// RateLimitState describes the current limiter state for a single client key.
+type RateLimitState struct {
+	Key          string `json:"key"`
+	Limit        int    `json:"limit"`
+	Remaining    int    `json:"remaining"`
+	ResetSeconds int    `json:"reset_seconds"`
+	Throttled    bool   `json:"throttled"`
+}
+
+// Inspect reports the state of the bucket for key (a client IP or its bucket
+// prefix) without consuming a token. Unknown keys report a full bucket.
+func (rl *RateLimiter) Inspect(_ context.Context, key string) (*RateLimitState, error) {
+	key = rl.buckets.Key(key)
+	tokens := float64(rl.burst)
+	rl.mu.Lock()
+	if v, ok := rl.limiters[key]; ok {
+		tokens = v.limiter.Tokens()
 	}
-	if xri := r.Header.Get("X-Real-Ip"); xri != "" {
-		return xri
+	rl.mu.Unlock()
+	return &RateLimitState{
+		Key:          key,
+		Limit:        rl.burst,
+		Remaining:    int(math.Max(0, math.Floor(tokens))),
+		ResetSeconds: secondsUntil(float64(rl.burst)-tokens, rl.r),
+		Throttled:    tokens < 1,
+	}, nil
+}
+
+// Reset discards the bucket for key so the client starts again with a full quota.
+func (rl *RateLimiter) Reset(_ context.Context, key string) error {
+	key = rl.buckets.Key(key)
+	rl.mu.Lock()
+	delete(rl.limiters, key)
+	rl.mu.Unlock()
+	return nil
+}
+
+// setRateLimitHeaders writes the IETF draft RateLimit-* response headers.
+func setRateLimitHeaders(w http.ResponseWriter, limit int, remaining float64, resetSeconds int) {
+	if remaining < 0 {
+		remaining = 0
 	}
-	// Strip port from RemoteAddr for consistency.
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(int(math.Floor(remaining))))
+	h.Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+}
+
+// secondsUntil returns how many whole seconds it takes to refill missing
+// tokens at rate r. Returns 0 when nothing is missing.
+func secondsUntil(missing float64, r rate.Limit) int {
+	if missing <= 0 || r <= 0 {
+		return 0
 	}
-	return host
+	return int(math.Ceil(missing / float64(r)))
 }
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// IPAllowlist is a set of IPv4 and IPv6 ranges, such as the clients exempt
+// from rate limiting (health probes, office networks, monitoring) or the
+// proxies trusted to set X-Forwarded-For.
+type IPAllowlist []netip.Prefix
+
+// ParseIPAllowlist parses CIDR ranges ("10.0.0.0/8", "2001:db8::/32"). A bare
+// address stands for itself alone.
+func ParseIPAllowlist(cidrs []string) (IPAllowlist, error) {
+	list := make(IPAllowlist, 0, len(cidrs))
+	for _, s := range cidrs {
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("IP range %q: %w", s, err)
+			}
+			addr = addr.Unmap()
+			list = append(list, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("IP range %q: %w", s, err)
+		}
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		list = append(list, p.Masked())
+	}
+	return list, nil
+}
+
+// Contains reports whether ip falls in one of the ranges. IPv4-mapped IPv6
+// addresses ("::ffff:10.0.0.1") match IPv4 ranges and zones ("%eth0") are
+// ignored; unparsable input matches nothing.
+func (a IPAllowlist) Contains(ip string) bool {
+	if len(a) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap().WithZone("")
+	for _, p := range a {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
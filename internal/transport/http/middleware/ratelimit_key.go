@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// IPBuckets decides which clients share a rate limit bucket. Providers hand
+// each IPv6 subscriber a whole /64 or /56, so limiting single IPv6 addresses
+// lets one client rotate through millions of them; V6 aggregates them by
+// prefix instead. V4 optionally does the same for IPv4 (e.g. /24).
+//
+// A zero length keeps full addresses (/32 and /128).
+type IPBuckets struct {
+	V4 int
+	V6 int
+}
+
+// NewIPBuckets validates the IPv4 and IPv6 prefix lengths.
+func NewIPBuckets(v4, v6 int) (IPBuckets, error) {
+	if v4 < 0 || v4 > 32 {
+		return IPBuckets{}, fmt.Errorf("rate limit IPv4 prefix /%d: must be between 0 and 32", v4)
+	}
+	if v6 < 0 || v6 > 128 {
+		return IPBuckets{}, fmt.Errorf("rate limit IPv6 prefix /%d: must be between 0 and 128", v6)
+	}
+	return IPBuckets{V4: v4, V6: v6}, nil
+}
+
+// Key returns the bucket ip is counted in: the canonical address, or its
+// masked prefix ("2001:db8:1:2::/64") when aggregated. IPv4-mapped IPv6
+// addresses count as IPv4 and zones are ignored. Anything that is not an
+// address, such as a prefix an admin passes to Inspect, is returned as is.
+func (b IPBuckets) Key(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	addr = addr.Unmap().WithZone("")
+	bits := b.V6
+	if addr.Is4() {
+		bits = b.V4
+	}
+	if bits == 0 || bits == addr.BitLen() {
+		return addr.String()
+	}
+	return netip.PrefixFrom(addr, bits).Masked().String()
+}
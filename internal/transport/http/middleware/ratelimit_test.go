@@ -1,34 +1,311 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
-func TestRealIP_XForwardedFor(t *testing.T) {
+func TestLimit_SetsRateLimitHeaders(t *testing.T) {
+	rl := NewRateLimiter(context.Background(), rate.Limit(1), 2)
+	h := rl.Limit(http.HandlerFunc(okHandler))
+
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("X-Forwarded-For", "1.2.3.4, 5.6.7.8")
-	assert.Equal(t, "1.2.3.4", realIP(req))
+	req.RemoteAddr = "10.0.0.1:1234"
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Reset"))
 }
 
-func TestRealIP_XRealIP_Fallback(t *testing.T) {
+func TestLimit_Exceeded_ReturnsJSONWithRetryAfter(t *testing.T) {
+	rl := NewRateLimiter(context.Background(), rate.Limit(1), 1)
+	h := rl.Limit(http.HandlerFunc(okHandler))
+
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("X-Real-Ip", "9.10.11.12")
-	assert.Equal(t, "9.10.11.12", realIP(req))
+	req.RemoteAddr = "10.0.0.2:1234"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"too many requests"}`, rr.Body.String())
+}
+
+// memCounter is an in-memory windowCounter used to exercise SlidingWindowLimiter.
+type memCounter struct{ counts map[string]int64 }
+
+func (m *memCounter) Increment(_ context.Context, key string, _ int64) (int64, error) {
+	m.counts[key]++
+	return m.counts[key], nil
+}
+
+func (m *memCounter) Count(_ context.Context, key string) (int64, error) {
+	return m.counts[key], nil
+}
+
+func (m *memCounter) Delete(_ context.Context, key string) error {
+	delete(m.counts, key)
+	return nil
 }
 
-func TestRealIP_RemoteAddr_Fallback(t *testing.T) {
+func TestSlidingWindowLimiter_BlocksAfterLimit(t *testing.T) {
+	store := &memCounter{counts: map[string]int64{}}
+	l := NewSlidingWindowLimiter(store, 2, time.Minute)
+	l.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC) }
+	h := l.Limit(http.HandlerFunc(okHandler))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.3:1234"
+	codes := make([]int, 3)
+	for i := range codes {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		codes[i] = rr.Code
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestSlidingWindowLimiter_WeightsPreviousWindow(t *testing.T) {
+	store := &memCounter{counts: map[string]int64{}}
+	l := NewSlidingWindowLimiter(store, 10, time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 45, 0, time.UTC) // 75% into the window
+	l.now = func() time.Time { return now }
+	store.counts[windowKey("10.0.0.4", now.Truncate(time.Minute).Add(-time.Minute))] = 20
+
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.RemoteAddr = "192.168.1.1:54321"
-	assert.Equal(t, "192.168.1.1", realIP(req))
+	req.RemoteAddr = "10.0.0.4:1234"
+	rr := httptest.NewRecorder()
+	l.Limit(http.HandlerFunc(okHandler)).ServeHTTP(rr, req)
+
+	// 20 * 0.25 + 1 = 6 requests in the sliding window.
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "4", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "15", rr.Header().Get("RateLimit-Reset"))
+}
+
+func TestRateLimiter_InspectAndReset(t *testing.T) {
+	rl := NewRateLimiter(context.Background(), rate.Limit(1), 1)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	rl.Limit(http.HandlerFunc(okHandler)).ServeHTTP(httptest.NewRecorder(), req)
+
+	state, err := rl.Inspect(context.Background(), "10.0.0.5")
+	assert.NoError(t, err)
+	assert.True(t, state.Throttled)
+	assert.Equal(t, 0, state.Remaining)
+
+	assert.NoError(t, rl.Reset(context.Background(), "10.0.0.5"))
+	state, err = rl.Inspect(context.Background(), "10.0.0.5")
+	assert.NoError(t, err)
+	assert.False(t, state.Throttled)
+	assert.Equal(t, 1, state.Remaining)
 }
 
-func TestRealIP_XForwardedFor_TakesPrecedenceOverXRealIP(t *testing.T) {
+func TestSlidingWindowLimiter_InspectAndReset(t *testing.T) {
+	store := &memCounter{counts: map[string]int64{}}
+	l := NewSlidingWindowLimiter(store, 1, time.Minute)
+	l.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("X-Forwarded-For", "1.1.1.1")
-	req.Header.Set("X-Real-Ip", "2.2.2.2")
-	assert.Equal(t, "1.1.1.1", realIP(req))
+	req.RemoteAddr = "10.0.0.6:1234"
+	l.Limit(http.HandlerFunc(okHandler)).ServeHTTP(httptest.NewRecorder(), req)
+
+	state, err := l.Inspect(context.Background(), "10.0.0.6")
+	assert.NoError(t, err)
+	assert.True(t, state.Throttled)
+
+	assert.NoError(t, l.Reset(context.Background(), "10.0.0.6"))
+	state, err = l.Inspect(context.Background(), "10.0.0.6")
+	assert.NoError(t, err)
+	assert.False(t, state.Throttled)
+	assert.Equal(t, 1, state.Remaining)
+}
+
+func TestIPAllowlist_Contains(t *testing.T) {
+	list, err := ParseIPAllowlist([]string{"10.0.0.0/8", "192.168.1.7", "2001:db8::/32", "::1", "::ffff:172.16.0.0/108"})
+	require.NoError(t, err)
+	cases := map[string]bool{
+		"10.1.2.3":           true,
+		"11.0.0.1":           false,
+		"192.168.1.7":        true,
+		"192.168.1.8":        false,
+		"::ffff:10.9.9.9":    true, // IPv4-mapped IPv6 matches the IPv4 range
+		"2001:db8:1::42":     true,
+		"2001:db9::1":        false,
+		"::1":                true,
+		"172.16.5.5":         true, // from the IPv4-mapped range
+		"172.32.0.1":         false,
+		"not-an-ip":          false,
+		"2001:db8::1%eth0":   true,
+		"fe80::1":            false,
+		"0:0:0:0:0:0:0:0001": true,
+	}
+	for ip, want := range cases {
+		assert.Equal(t, want, list.Contains(ip), ip)
+	}
+}
+
+func TestParseIPAllowlist_RejectsInvalidRanges(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/33", "2001:db8::/129", "example.com", "10.0.0/8"} {
+		_, err := ParseIPAllowlist([]string{s})
+		assert.Error(t, err, s)
+	}
+}
+
+func TestLimit_ExemptClientsAreNotLimited(t *testing.T) {
+	list, err := ParseIPAllowlist([]string{"10.0.0.0/24", "2001:db8::/64"})
+	require.NoError(t, err)
+	store := &memCounter{counts: map[string]int64{}}
+	limiters := map[string]http.Handler{
+		"token bucket":   NewRateLimiter(context.Background(), rate.Limit(1), 1).Exempt(list).TrustProxies(testProxies(t)).Limit(http.HandlerFunc(okHandler)),
+		"sliding window": NewSlidingWindowLimiter(store, 1, time.Minute).Exempt(list).TrustProxies(testProxies(t)).Limit(http.HandlerFunc(okHandler)),
+	}
+	for name, h := range limiters {
+		for _, ip := range []string{"10.0.0.9", "2001:db8::9"} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Forwarded-For", ip)
+			for i := 0; i < 3; i++ {
+				rr := httptest.NewRecorder()
+				h.ServeHTTP(rr, req)
+				assert.Equal(t, http.StatusOK, rr.Code, "%s %s", name, ip)
+				assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Forwarded-For", "2001:db8:1::9")
+		codes := make([]int, 2)
+		for i := range codes {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			codes[i] = rr.Code
+		}
+		assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes, name)
+	}
+	assert.Len(t, store.counts, 1, "exempt requests are not counted")
+}
+
+func TestIPBuckets_Key(t *testing.T) {
+	b, err := NewIPBuckets(24, 56)
+	require.NoError(t, err)
+	cases := map[string]string{
+		"203.0.113.7":            "203.0.113.0/24",
+		"::ffff:203.0.113.200":   "203.0.113.0/24",
+		"2001:db8:1:2ff::1":      "2001:db8:1:200::/56",
+		"2001:DB8:1:2aa::9%eth0": "2001:db8:1:200::/56",
+		"2001:db8:1:200::/56":    "2001:db8:1:200::/56",
+		"not-an-ip":              "not-an-ip",
+	}
+	for ip, want := range cases {
+		assert.Equal(t, want, b.Key(ip), ip)
+	}
+
+	assert.Equal(t, "203.0.113.7", IPBuckets{V6: 64}.Key("::ffff:203.0.113.7"))
+	assert.Equal(t, "2001:db8::1", IPBuckets{}.Key("2001:DB8:0::1"))
+}
+
+func TestNewIPBuckets_RejectsInvalidLengths(t *testing.T) {
+	for _, lens := range [][2]int{{33, 64}, {-1, 64}, {32, 129}, {32, -8}} {
+		_, err := NewIPBuckets(lens[0], lens[1])
+		assert.Error(t, err, lens)
+	}
+}
+
+func TestLimit_AggregatesIPv6Prefix(t *testing.T) {
+	b := IPBuckets{V4: 32, V6: 64}
+	store := &memCounter{counts: map[string]int64{}}
+	rl := NewRateLimiter(context.Background(), rate.Limit(1), 1).Buckets(b).TrustProxies(testProxies(t))
+	limiters := map[string]http.Handler{
+		"token bucket":   rl.Limit(http.HandlerFunc(okHandler)),
+		"sliding window": NewSlidingWindowLimiter(store, 1, time.Minute).Buckets(b).TrustProxies(testProxies(t)).Limit(http.HandlerFunc(okHandler)),
+	}
+	for name, h := range limiters {
+		var codes []int
+		for _, ip := range []string{"2001:db8:1:2::1", "2001:db8:1:2:ffff::9", "2001:db8:1:3::1", "10.0.0.1", "10.0.0.2"} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Forwarded-For", ip)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			codes = append(codes, rr.Code)
+		}
+		want := []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK, http.StatusOK, http.StatusOK}
+		assert.Equal(t, want, codes, name)
+	}
+
+	state, err := rl.Inspect(context.Background(), "2001:db8:1:2::abcd")
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8:1:2::/64", state.Key)
+	assert.True(t, state.Throttled)
+}
+
+// testProxies trusts the RemoteAddr httptest gives requests (192.0.2.1), as if
+// they came through a load balancer.
+func testProxies(t *testing.T) IPAllowlist {
+	t.Helper()
+	list, err := ParseIPAllowlist([]string{"192.0.2.0/24"})
+	require.NoError(t, err)
+	return list
+}
+
+func TestClientIP_TrustsOnlyProxyHops(t *testing.T) {
+	proxies, err := ParseIPAllowlist([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	cases := []struct {
+		name, remote, xff, want string
+	}{
+		{"direct client ignores forwarding headers", "203.0.113.7:1234", "10.0.0.9", "203.0.113.7"},
+		{"proxy forwards the client", "10.0.0.1:1234", "203.0.113.7", "203.0.113.7"},
+		{"spoofed leftmost entry is skipped", "10.0.0.1:1234", "10.0.0.9, 203.0.113.7", "203.0.113.7"},
+		{"proxy chain is walked from the right", "10.0.0.1:1234", "198.51.100.4, 203.0.113.7, 10.0.0.2", "203.0.113.7"},
+		{"internal request through proxies", "10.0.0.1:1234", "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+		{"proxy without header", "10.0.0.1:1234", "", "10.0.0.1"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remote
+		if tc.xff != "" {
+			req.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		assert.Equal(t, tc.want, clientIP(req, proxies), tc.name)
+	}
+}
+
+func TestLimit_SpoofedForwardedForIsNotExempt(t *testing.T) {
+	list, err := ParseIPAllowlist([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	store := &memCounter{counts: map[string]int64{}}
+	limiters := map[string]http.Handler{
+		"token bucket":   NewRateLimiter(context.Background(), rate.Limit(1), 1).Exempt(list).TrustProxies(testProxies(t)).Limit(http.HandlerFunc(okHandler)),
+		"sliding window": NewSlidingWindowLimiter(store, 1, time.Minute).Exempt(list).TrustProxies(testProxies(t)).Limit(http.HandlerFunc(okHandler)),
+	}
+	for name, h := range limiters {
+		for remote, xff := range map[string]string{
+			"203.0.113.7:1234": "10.0.0.9",              // direct client
+			"192.0.2.1:1234":   "10.0.0.9, 203.0.113.8", // through the load balancer
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = remote
+			req.Header.Set("X-Forwarded-For", xff)
+			codes := make([]int, 2)
+			for i := range codes {
+				rr := httptest.NewRecorder()
+				h.ServeHTTP(rr, req)
+				codes[i] = rr.Code
+			}
+			assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes, "%s %s", name, xff)
+		}
+	}
 }
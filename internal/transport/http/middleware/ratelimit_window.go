@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// windowCounter is satisfied by any store that keeps atomic per-key counters
+// (e.g. the DynamoDB rate_limits table).
+type windowCounter interface {
+	Increment(ctx context.Context, key string, expiresAt int64) (int64, error)
+	Count(ctx context.Context, key string) (int64, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// SlidingWindowLimiter is a per-IP limiter whose state lives in a shared
+// counter store, so limits hold across replicas and Lambda cold starts.
+//
+// It approximates a sliding window by weighting the previous fixed window's
+// count by how much of it still overlaps the sliding window.
+type SlidingWindowLimiter struct {
+	store   windowCounter
+	limit   int
+	window  time.Duration
+	exempt  IPAllowlist
+	buckets IPBuckets
+	proxies IPAllowlist
+	now     func() time.Time
+}
+
+// NewSlidingWindowLimiter allows up to limit requests per client IP within any window-long interval.
+func NewSlidingWindowLimiter(store windowCounter, limit int, window time.Duration) *SlidingWindowLimiter {
+	return &SlidingWindowLimiter{store: store, limit: limit, window: window, now: time.Now}
+}
+
+// Exempt lets clients in list through without counting their requests.
+func (l *SlidingWindowLimiter) Exempt(list IPAllowlist) *SlidingWindowLimiter {
+	l.exempt = list
+	return l
+}
+
+// Buckets sets how client IPs are grouped into buckets; see IPBuckets.
+func (l *SlidingWindowLimiter) Buckets(b IPBuckets) *SlidingWindowLimiter {
+	l.buckets = b
+	return l
+}
+
+// TrustProxies sets the proxies whose X-Forwarded-For entries are believed.
+// Without any, clients are identified by their TCP address alone.
+func (l *SlidingWindowLimiter) TrustProxies(list IPAllowlist) *SlidingWindowLimiter {
+	l.proxies = list
+	return l
+}
+
+// Limit is the middleware handler that enforces the limit per client IP, or
+// per IPBuckets prefix. Exempt clients are not counted. Store failures fail open: the request is logged and allowed through,
+// since this limiter is a secondary defence behind API Gateway / WAF.
+func (l *SlidingWindowLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, l.proxies)
+		if l.exempt.Contains(ip) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		estimate, reset, err := l.hit(r.Context(), l.buckets.Key(ip))
+		if err != nil {
+			slog.Warn("rate limit store unavailable, allowing request", "err", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		remaining := float64(l.limit) - estimate
+		setRateLimitHeaders(w, l.limit, remaining, reset)
+		if estimate > float64(l.limit) {
+			w.Header().Set("Retry-After", strconv.Itoa(reset))
+			writeJSONError(w, http.StatusTooManyRequests, "too many requests")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hit records one request for ip and returns the weighted request count in the
+// sliding window together with the seconds left in the current fixed window.
+func (l *SlidingWindowLimiter) hit(ctx context.Context, ip string) (float64, int, error) {
+	now := l.now()
+	start := now.Truncate(l.window)
+	// Keep counters for two windows so the previous one can still be weighted.
+	expiresAt := start.Add(2 * l.window).Unix()
+	current, err := l.store.Increment(ctx, windowKey(ip, start), expiresAt)
+	if err != nil {
+		return 0, 0, err
+	}
+	return l.weigh(ctx, ip, now, current)
+}
+
+// weigh combines current with the previous window's count for ip.
+func (l *SlidingWindowLimiter) weigh(ctx context.Context, ip string, now time.Time, current int64) (float64, int, error) {
+	start := now.Truncate(l.window)
+	previous, err := l.store.Count(ctx, windowKey(ip, start.Add(-l.window)))
+	if err != nil {
+		return 0, 0, err
+	}
+	elapsed := now.Sub(start).Seconds() / l.window.Seconds()
+	estimate := float64(previous)*(1-elapsed) + float64(current)
+	reset := int(math.Ceil(start.Add(l.window).Sub(now).Seconds()))
+	return estimate, reset, nil
+}
+
+// Inspect reports the sliding-window state for key (a client IP or its bucket
+// prefix) without recording a request.
+func (l *SlidingWindowLimiter) Inspect(ctx context.Context, key string) (*RateLimitState, error) {
+	key = l.buckets.Key(key)
+	now := l.now()
+	current, err := l.store.Count(ctx, windowKey(key, now.Truncate(l.window)))
+	if err != nil {
+		return nil, err
+	}
+	estimate, reset, err := l.weigh(ctx, key, now, current)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimitState{
+		Key:          key,
+		Limit:        l.limit,
+		Remaining:    int(math.Max(0, math.Floor(float64(l.limit)-estimate))),
+		ResetSeconds: reset,
+		Throttled:    estimate >= float64(l.limit),
+	}, nil
+}
+
+// Reset deletes the current and previous window counters for key.
+func (l *SlidingWindowLimiter) Reset(ctx context.Context, key string) error {
+	key = l.buckets.Key(key)
+	start := l.now().Truncate(l.window)
+	if err := l.store.Delete(ctx, windowKey(key, start)); err != nil {
+		return err
+	}
+	return l.store.Delete(ctx, windowKey(key, start.Add(-l.window)))
+}
+
+func windowKey(ip string, start time.Time) string {
+	return fmt.Sprintf("ip#%s#%d", ip, start.UnixMilli())
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// ReadOnlyControlPath switches read-only mode and is never refused, so the
+// mode can always be lifted.
+const ReadOnlyControlPath = "/v1/admin/read-only"
+
+// ReadOnlyCode is the "code" of the 503 answered to writes in read-only mode.
+const ReadOnlyCode = "read_only"
+
+// readOnlyExempt lists the writes still served in read-only mode: signing in
+// and refreshing tokens, OAuth clients included, so admins can reach the
+// control path, and revoking OAuth tokens.
+var readOnlyExempt = map[string]bool{
+	"/v1/sessions/login":              true,
+	"/v1/sessions/google":             true,
+	"/v1/sessions/refresh":            true,
+	"/v1/sessions/silent-refresh":     true,
+	"/v1/sessions/approve":            true,
+	"/v1/sessions/webauthn/challenge": true,
+	"/v1/sessions/webauthn":           true,
+	"/v1/oauth/token":                 true,
+	"/v1/oauth/introspect":            true,
+	"/v1/oauth/revoke":                true,
+	ReadOnlyControlPath:               true,
+}
+
+// ReadOnlyGate reports the current read-only mode.
+type ReadOnlyGate interface {
+	Mode() domain.ReadOnlyMode
+}
+
+// ReadOnly answers every request but GET, HEAD and OPTIONS with 503 and code
+// "read_only" while the gate's mode is on. Health checks and the paths in
+// readOnlyExempt are always served.
+func ReadOnly(gate ReadOnlyGate) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			if readOnlyExempt[r.URL.Path] || strings.HasPrefix(r.URL.Path, "/v1/health-check/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			m := gate.Mode()
+			if !m.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			msg := "the API is read-only"
+			if m.Reason != "" {
+				msg += ": " + m.Reason
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": msg, "code": ReadOnlyCode})
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedMode domain.ReadOnlyMode
+
+func (m fixedMode) Mode() domain.ReadOnlyMode { return domain.ReadOnlyMode(m) }
+
+func serveReadOnly(m fixedMode, method, path string) *httptest.ResponseRecorder {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	rr := httptest.NewRecorder()
+	ReadOnly(m)(ok).ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+	return rr
+}
+
+func TestReadOnly_RefusesWritesWithCode(t *testing.T) {
+	on := fixedMode{Enabled: true, Reason: "nightly backup"}
+
+	rr := serveReadOnly(on, http.MethodPut, "/v1/users/u1")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.JSONEq(t, `{"error":"the API is read-only: nightly backup","code":"read_only"}`, rr.Body.String())
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveReadOnly(on, http.MethodDelete, "/v1/files/s3/f1").Code)
+	assert.Equal(t, http.StatusOK, serveReadOnly(on, http.MethodGet, "/v1/users/u1").Code)
+}
+
+func TestReadOnly_SparesSignInAndControlPath(t *testing.T) {
+	on := fixedMode{Enabled: true}
+
+	for _, path := range []string{"/v1/sessions/login", "/v1/sessions/refresh", ReadOnlyControlPath, "/v1/health-check/ping"} {
+		assert.Equal(t, http.StatusOK, serveReadOnly(on, http.MethodPost, path).Code, path)
+	}
+}
+
+func TestReadOnly_OffServesWrites(t *testing.T) {
+	assert.Equal(t, http.StatusOK, serveReadOnly(fixedMode{}, http.MethodPost, "/v1/users").Code)
+}
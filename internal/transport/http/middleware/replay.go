@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
+)
+
+// Headers carrying the replay-protection nonce and the client's Unix timestamp.
+const (
+	NonceHeader     = "X-Request-Nonce"
+	TimestampHeader = "X-Request-Timestamp"
+)
+
+// nonceCounter is satisfied by any store that keeps atomic per-key counters
+// (e.g. the DynamoDB rate_limits table). A count above one means the nonce was
+// already used.
+type nonceCounter interface {
+	Increment(ctx context.Context, key string, expiresAt int64) (int64, error)
+}
+
+// ReplayGuard rejects sensitive requests that do not carry a fresh, unused
+// nonce. A request is accepted once per nonce and only while its timestamp is
+// within window of the server clock, so a captured request cannot be sent
+// again. Nonces are scoped to the caller and remembered until their timestamp
+// leaves the window.
+type ReplayGuard struct {
+	store  nonceCounter
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewReplayGuard creates a guard accepting timestamps up to window away from
+// the server clock. A nil store keeps nonces in process memory, which only
+// protects a single instance; ctx bounds its background cleanup.
+func NewReplayGuard(ctx context.Context, store nonceCounter, window time.Duration) *ReplayGuard {
+	if store == nil {
+		mem := &memoryNonces{seen: make(map[string]int64)}
+		if window > 0 {
+			lifecycle.Go(ctx, "replay nonce cleanup", func(ctx context.Context) { mem.cleanup(ctx, window) })
+		}
+		store = mem
+	}
+	return &ReplayGuard{store: store, window: window, now: time.Now}
+}
+
+// Check is the middleware handler. It must run after Auth. Store failures
+// fail closed, since letting the request through would defeat the check.
+func (g *ReplayGuard) Check(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce := r.Header.Get(NonceHeader)
+		ts, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if !validNonce(nonce) || err != nil {
+			writeJSONError(w, http.StatusBadRequest, "X-Request-Nonce (16-128 letters, digits, '-' or '_') and X-Request-Timestamp (Unix seconds) are required")
+			return
+		}
+		skew := g.now().Sub(time.Unix(ts, 0))
+		if skew > g.window || skew < -g.window {
+			writeJSONError(w, http.StatusUnauthorized, "request timestamp is outside the allowed window")
+			return
+		}
+		var userID string
+		if claims, ok := ClaimsFromContext(r.Context()); ok {
+			userID = claims.UserID
+		}
+		n, err := g.store.Increment(r.Context(), "nonce:"+userID+":"+nonce, ts+int64(g.window/time.Second))
+		if err != nil {
+			slog.Error("nonce store unavailable", "err", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		if n > 1 {
+			writeJSONError(w, http.StatusConflict, "request nonce was already used")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validNonce accepts 16 to 128 URL-safe characters, enough for a UUID or a
+// base64url-encoded random value.
+func validNonce(s string) bool {
+	if len(s) < 16 || len(s) > 128 {
+		return false
+	}
+	for _, c := range s {
+		ok := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryNonces is the in-process nonce store used when no shared store is configured.
+type memoryNonces struct {
+	mu   sync.Mutex
+	seen map[string]int64 // key -> expires at (Unix seconds)
+}
+
+// Increment returns 1 the first time key is seen and 2 on any later call; the
+// guard only needs to tell the two apart.
+func (m *memoryNonces) Increment(_ context.Context, key string, expiresAt int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.seen[key]; ok {
+		return 2, nil
+	}
+	m.seen[key] = expiresAt
+	return 1, nil
+}
+
+// cleanup drops expired nonces every window until ctx is cancelled.
+func (m *memoryNonces) cleanup(ctx context.Context, window time.Duration) {
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now().Unix()
+			m.mu.Lock()
+			for k, exp := range m.seen {
+				if now > exp {
+					delete(m.seen, k)
+				}
+			}
+			m.mu.Unlock()
+		}
+	}
+}
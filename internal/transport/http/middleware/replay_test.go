@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testNonce = "0f8b1c2e-5d4a-4e7b-9c3f-2a1b0c9d8e7f"
+
+func replayRequest(userID, nonce string, ts time.Time) *http.Request {
+	req := sessionRequest(userID, "s1")
+	req.Header.Set(NonceHeader, nonce)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+	return req
+}
+
+func serveReplay(g *ReplayGuard, req *http.Request) int {
+	rr := httptest.NewRecorder()
+	g.Check(http.HandlerFunc(okHandler)).ServeHTTP(rr, req)
+	return rr.Code
+}
+
+func TestReplayGuard_RejectsReusedNonce(t *testing.T) {
+	g := NewReplayGuard(t.Context(), nil, time.Minute)
+	now := time.Now()
+
+	assert.Equal(t, http.StatusOK, serveReplay(g, replayRequest("u1", testNonce, now)))
+	assert.Equal(t, http.StatusConflict, serveReplay(g, replayRequest("u1", testNonce, now)))
+	// Nonces are scoped per user, so another caller may pick the same value.
+	assert.Equal(t, http.StatusOK, serveReplay(g, replayRequest("u2", testNonce, now)))
+}
+
+func TestReplayGuard_RejectsTimestampOutsideWindow(t *testing.T) {
+	g := NewReplayGuard(t.Context(), nil, time.Minute)
+
+	assert.Equal(t, http.StatusUnauthorized, serveReplay(g, replayRequest("u1", testNonce, time.Now().Add(-2*time.Minute))))
+	assert.Equal(t, http.StatusUnauthorized, serveReplay(g, replayRequest("u1", testNonce, time.Now().Add(2*time.Minute))))
+}
+
+func TestReplayGuard_RequiresHeaders(t *testing.T) {
+	g := NewReplayGuard(t.Context(), nil, time.Minute)
+
+	assert.Equal(t, http.StatusBadRequest, serveReplay(g, sessionRequest("u1", "s1")))
+	assert.Equal(t, http.StatusBadRequest, serveReplay(g, replayRequest("u1", "short", time.Now())))
+	assert.Equal(t, http.StatusBadRequest, serveReplay(g, replayRequest("u1", "not a valid nonce!!", time.Now())))
+
+	req := replayRequest("u1", testNonce, time.Now())
+	req.Header.Set(TimestampHeader, "yesterday")
+	assert.Equal(t, http.StatusBadRequest, serveReplay(g, req))
+}
+
+type failingCounter struct{}
+
+func (failingCounter) Increment(context.Context, string, int64) (int64, error) {
+	return 0, errors.New("table unavailable")
+}
+
+func TestReplayGuard_StoreErrorFailsClosed(t *testing.T) {
+	g := NewReplayGuard(t.Context(), failingCounter{}, time.Minute)
+
+	assert.Equal(t, http.StatusInternalServerError, serveReplay(g, replayRequest("u1", testNonce, time.Now())))
+}
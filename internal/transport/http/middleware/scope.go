@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+const errMissingScope = "access token lacks the scope for this route"
+
+// RequireScope returns middleware that allows access only to tokens whose
+// scopes claim holds scope (e.g. "files:write"), for routes that integrations
+// may call with least-privilege tokens.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			if !claims.HasScope(scope) {
+				writeJSONError(w, http.StatusForbidden, errMissingScope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(t *testing.T) {
+	cases := []struct {
+		name   string
+		claims *jwtinfra.Claims
+		want   int
+	}{
+		{"no claims", nil, http.StatusUnauthorized},
+		{"scope held", &jwtinfra.Claims{Scopes: []string{"files:read", "files:write"}}, http.StatusOK},
+		{"scope missing", &jwtinfra.Claims{Scopes: []string{"files:read"}}, http.StatusForbidden},
+		{"token without scopes", &jwtinfra.Claims{Role: "Admin"}, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.claims != nil {
+				req = req.WithContext(context.WithValue(req.Context(), claimsKey, tc.claims))
+			}
+			rr := httptest.NewRecorder()
+			RequireScope("files:write")(http.HandlerFunc(okHandler)).ServeHTTP(rr, req)
+			assert.Equal(t, tc.want, rr.Code)
+		})
+	}
+}
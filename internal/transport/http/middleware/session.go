@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// SessionValidator checks that a bearer token's session is still live.
+type SessionValidator interface {
+	Validate(ctx context.Context, userID, sessionID string) error
+}
+
+// SessionGuard rejects bearer tokens whose session was logged out or revoked,
+// or whose account was deleted or disabled, before the token itself expires.
+//
+// Successful checks are cached per session for ttl, so a revocation takes up
+// to ttl to reach every replica. Failures are never cached.
+type SessionGuard struct {
+	validator SessionValidator
+	valid     *trustCache // cacheKey(session_id, user_id)
+}
+
+// NewSessionGuard creates a guard caching successful checks for ttl; a zero
+// ttl checks every request. ctx bounds the background cache cleanup.
+func NewSessionGuard(ctx context.Context, validator SessionValidator, ttl time.Duration) *SessionGuard {
+	return &SessionGuard{validator: validator, valid: newTrustCache(ctx, "session guard cleanup", ttl)}
+}
+
+// Check is the middleware handler. It must run after Auth. Requests
+// authenticated by a client certificate or an API key carry no session and are
+// passed through.
+func (g *SessionGuard) Check(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sessionless(r.Context()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok || claims.SessionID == "" {
+			writeJSONError(w, http.StatusUnauthorized, "invalid or expired token")
+			return
+		}
+		key := cacheKey(claims.SessionID, claims.UserID)
+		if !g.valid.has(key) {
+			err := g.validator.Validate(r.Context(), claims.UserID, claims.SessionID)
+			if errors.Is(err, domain.ErrUnauthorized) {
+				writeJSONError(w, http.StatusUnauthorized, "session is no longer valid")
+				return
+			}
+			if err != nil {
+				slog.Error("session check failed", "session_id", claims.SessionID, "err", err)
+				writeJSONError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			g.valid.add(key)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Forget drops userID's sessions from this replica's cache, so their
+// revocation takes effect here on the next request. Other replicas still
+// trust them for up to ttl.
+func (g *SessionGuard) Forget(userID string, sessionIDs ...string) {
+	for _, id := range sessionIDs {
+		g.valid.remove(cacheKey(id, userID))
+	}
+}
+
+// cacheKey binds the cached result to the user as well as the session, so a
+// token naming someone else's session is always checked.
+func cacheKey(sessionID, userID string) string {
+	return sessionID + "\x00" + userID
+}
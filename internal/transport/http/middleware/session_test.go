@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubValidator struct {
+	err   error
+	calls int
+}
+
+func (v *stubValidator) Validate(context.Context, string, string) error {
+	v.calls++
+	return v.err
+}
+
+func sessionRequest(userID, sessionID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	claims := &jwtinfra.Claims{UserID: userID, SessionID: sessionID}
+	return req.WithContext(context.WithValue(req.Context(), claimsKey, claims))
+}
+
+func TestSessionGuard_RevokedSessionIsUnauthorized(t *testing.T) {
+	v := &stubValidator{err: fmt.Errorf("session revoked: %w", domain.ErrUnauthorized)}
+	g := NewSessionGuard(t.Context(), v, time.Minute)
+
+	rr := httptest.NewRecorder()
+	g.Check(http.HandlerFunc(okHandler)).ServeHTTP(rr, sessionRequest("u1", "s1"))
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestSessionGuard_CachesSuccess(t *testing.T) {
+	v := &stubValidator{}
+	g := NewSessionGuard(t.Context(), v, time.Minute)
+	h := g.Check(http.HandlerFunc(okHandler))
+
+	for range 3 {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, sessionRequest("u1", "s1"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+	assert.Equal(t, 1, v.calls)
+
+	// The cache is keyed by user too, so another user's claim is re-checked.
+	h.ServeHTTP(httptest.NewRecorder(), sessionRequest("u2", "s1"))
+	assert.Equal(t, 2, v.calls)
+}
+
+func TestSessionGuard_StoreErrorIsNotCached(t *testing.T) {
+	v := &stubValidator{err: errors.New("throttled")}
+	g := NewSessionGuard(t.Context(), v, time.Minute)
+	h := g.Check(http.HandlerFunc(okHandler))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, sessionRequest("u1", "s1"))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	v.err = nil
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, sessionRequest("u1", "s1"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 2, v.calls)
+}
+
+func TestSessionGuard_ForgetRechecksSession(t *testing.T) {
+	v := &stubValidator{}
+	g := NewSessionGuard(t.Context(), v, time.Minute)
+	h := g.Check(http.HandlerFunc(okHandler))
+	h.ServeHTTP(httptest.NewRecorder(), sessionRequest("u1", "s1"))
+
+	g.Forget("u1", "s1")
+	v.err = fmt.Errorf("session revoked: %w", domain.ErrUnauthorized)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, sessionRequest("u1", "s1"))
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, 2, v.calls)
+}
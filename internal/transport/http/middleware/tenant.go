@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+)
+
+// TenantHeader names the tenant of requests made without a bearer token, such
+// as login and registration, when TENANT_MODE=jwt.
+const TenantHeader = "X-Tenant-ID"
+
+// Tenant resolves the tenant of every request and stores it in the context,
+// where the repositories pick it up (see package tenant). With
+// tenant.ModeSubdomain it is the first label of the Host below baseDomain;
+// with tenant.ModeJWT it is the tenant claim of a valid bearer token, or
+// TenantHeader when there is none. Requests without a valid tenant are
+// rejected, so no handler can reach DynamoDB unscoped.
+func Tenant(mode, baseDomain string, provider *jwtinfra.Provider) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var id string
+			if mode == tenant.ModeSubdomain {
+				var ok bool
+				if id, ok = tenant.FromHost(r.Host, baseDomain); !ok {
+					writeJSONError(w, http.StatusNotFound, "unknown tenant")
+					return
+				}
+			} else {
+				id = r.Header.Get(TenantHeader)
+				if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+					// An invalid token is left to Auth; public routes ignore it.
+					if claims, err := provider.Verify(bearer); err == nil {
+						if id != "" && id != claims.Tenant {
+							writeJSONError(w, http.StatusForbidden, "token was issued for another tenant")
+							return
+						}
+						id = claims.Tenant
+					}
+				}
+				if !tenant.Valid(id) {
+					writeJSONError(w, http.StatusBadRequest, "missing or invalid "+TenantHeader+" header")
+					return
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), id)))
+		})
+	}
+}
+
+// TenantMatch rejects callers whose tenant differs from the one the request
+// resolved to: the tenant claim of a bearer token, the tenant of a client
+// certificate principal or the tenant an API key was created in. It must run
+// after Auth and Tenant.
+func TenantMatch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		id, _ := tenant.FromContext(r.Context())
+		if !ok || claims.Tenant == "" || claims.Tenant != id {
+			writeJSONError(w, http.StatusUnauthorized, "token was issued for another tenant")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
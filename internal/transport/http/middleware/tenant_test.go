@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-api-nosql/internal/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveTenant(mw func(http.Handler) http.Handler, req *http.Request) (int, string) {
+	var got string
+	rr := httptest.NewRecorder()
+	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = tenant.FromContext(r.Context())
+	})).ServeHTTP(rr, req)
+	return rr.Code, got
+}
+
+func TestTenant_Subdomain(t *testing.T) {
+	mw := Tenant(tenant.ModeSubdomain, "api.example.com", nil)
+
+	code, got := serveTenant(mw, httptest.NewRequest(http.MethodGet, "http://acme.api.example.com:8080/", nil))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "acme", got)
+
+	code, _ = serveTenant(mw, httptest.NewRequest(http.MethodGet, "http://api.example.com/", nil))
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = serveTenant(mw, httptest.NewRequest(http.MethodGet, "http://a.b.api.example.com/", nil))
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestTenant_JWT(t *testing.T) {
+	p := newTestProvider(t)
+	mw := Tenant(tenant.ModeJWT, "", p)
+	signed, err := p.Sign(tenant.WithID(context.Background(), "acme"), "u1", "dev1", "user", "sess1")
+	require.NoError(t, err)
+
+	// Before login the header names the tenant.
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(TenantHeader, "globex")
+	code, got := serveTenant(mw, req)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "globex", got)
+
+	// A valid token's claim wins and must agree with the header.
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	code, got = serveTenant(mw, req)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "acme", got)
+
+	req.Header.Set(TenantHeader, "globex")
+	code, _ = serveTenant(mw, req)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = serveTenant(mw, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestTenantMatch(t *testing.T) {
+	serve := func(claimTenant, id string) int {
+		ctx := context.WithValue(context.Background(), claimsKey, &jwtinfra.Claims{UserID: "u1", Tenant: claimTenant})
+		ctx = tenant.WithID(ctx, id)
+		rr := httptest.NewRecorder()
+		TenantMatch(http.HandlerFunc(okHandler)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, serve("acme", "acme"))
+	assert.Equal(t, http.StatusUnauthorized, serve("acme", "globex"))
+	assert.Equal(t, http.StatusUnauthorized, serve("", "acme"))
+}
+
+func TestTenantMatch_CertificatesAndAPIKeysStayInTheirTenant(t *testing.T) {
+	callers := map[string]context.Context{
+		"client certificate": context.WithValue(context.Background(), clientCertKey, true),
+		"API key":            context.WithValue(context.Background(), apiKeyKey, "k1"),
+	}
+	for name, base := range callers {
+		serve := func(claimTenant, id string) int {
+			ctx := context.WithValue(base, claimsKey, &jwtinfra.Claims{UserID: "svc", Role: "Admin", Tenant: claimTenant})
+			rr := httptest.NewRecorder()
+			TenantMatch(http.HandlerFunc(okHandler)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(tenant.WithID(ctx, id)))
+			return rr.Code
+		}
+		assert.Equal(t, http.StatusOK, serve("acme", "acme"), name)
+		assert.Equal(t, http.StatusUnauthorized, serve("acme", "globex"), name)
+		assert.Equal(t, http.StatusUnauthorized, serve("", "globex"), name)
+	}
+}
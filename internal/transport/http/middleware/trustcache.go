@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-api-nosql/internal/pkg/lifecycle"
+)
+
+// trustCache remembers keys whose check passed, for ttl, so a middleware can
+// skip repeating a lookup on every request. A zero ttl remembers nothing.
+type trustCache struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	until map[string]time.Time // key -> trusted until
+}
+
+// newTrustCache creates a cache and, when ttl is positive, a background
+// cleanup named name that runs until ctx is cancelled.
+func newTrustCache(ctx context.Context, name string, ttl time.Duration) *trustCache {
+	c := &trustCache{ttl: ttl, until: make(map[string]time.Time)}
+	if ttl > 0 {
+		lifecycle.Go(ctx, name, c.cleanup)
+	}
+	return c
+}
+
+func (c *trustCache) has(key string) bool {
+	if c.ttl <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Before(c.until[key])
+}
+
+func (c *trustCache) add(key string) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.until[key] = time.Now().Add(c.ttl)
+	c.mu.Unlock()
+}
+
+func (c *trustCache) remove(key string) {
+	c.mu.Lock()
+	delete(c.until, key)
+	c.mu.Unlock()
+}
+
+// cleanup drops expired entries every ttl until ctx is cancelled.
+func (c *trustCache) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			c.mu.Lock()
+			for k, until := range c.until {
+				if now.After(until) {
+					delete(c.until, k)
+				}
+			}
+			c.mu.Unlock()
+		}
+	}
+}
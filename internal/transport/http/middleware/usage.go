@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+)
+
+// UsageMeter counts a caller's requests and body bytes against a daily quota.
+type UsageMeter interface {
+	Admit(ctx context.Context, userID, role string) error
+	RecordBytes(ctx context.Context, userID string, in, out int64) error
+}
+
+// Metering counts every authenticated request and its request and response
+// body bytes, and answers 429 once the caller's daily quota is used up. It
+// must run after Auth. When the meter itself fails, the request is served
+// unmetered: an outage of the counter store does not take the API down.
+func Metering(m UsageMeter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			err := m.Admit(r.Context(), claims.UserID, claims.Role)
+			if errors.Is(err, domain.ErrTooMany) {
+				w.Header().Set("Retry-After", strconv.Itoa(secondsUntilMidnight(time.Now())))
+				writeJSONError(w, http.StatusTooManyRequests, "daily usage quota exceeded")
+				return
+			}
+			if err != nil {
+				slog.Warn("usage metering failed", "user_id", claims.UserID, "error", err)
+			}
+			body := &countingBody{ReadCloser: r.Body}
+			r.Body = body
+			cw := &countingWriter{ResponseWriter: w}
+			next.ServeHTTP(cw, r)
+			if body.n == 0 && cw.n == 0 {
+				return
+			}
+			// The response is sent; a client that hangs up still used the bytes.
+			if err := m.RecordBytes(context.WithoutCancel(r.Context()), claims.UserID, body.n, cw.n); err != nil {
+				slog.Warn("usage metering failed", "user_id", claims.UserID, "error", err)
+			}
+		})
+	}
+}
+
+// secondsUntilMidnight is the wait until the UTC day after now, when quotas reset.
+func secondsUntilMidnight(now time.Time) int {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return int(midnight.Sub(now).Seconds()) + 1
+}
+
+// countingBody counts the request body bytes the handler reads.
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// countingWriter counts the response body bytes written.
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *countingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
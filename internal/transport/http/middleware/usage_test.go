@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-api-nosql/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMeter struct {
+	admitErr error
+	in, out  int64
+}
+
+func (m *stubMeter) Admit(context.Context, string, string) error { return m.admitErr }
+
+func (m *stubMeter) RecordBytes(_ context.Context, _ string, in, out int64) error {
+	m.in, m.out = m.in+in, m.out+out
+	return nil
+}
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.Copy(w, r.Body)
+}
+
+func TestMetering_CountsBodyBytes(t *testing.T) {
+	m := &stubMeter{}
+	req := sessionRequest("u1", "s1")
+	req.Body = io.NopCloser(strings.NewReader("hello"))
+
+	rr := httptest.NewRecorder()
+	Metering(m)(http.HandlerFunc(echoHandler)).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(5), m.in)
+	assert.Equal(t, int64(5), m.out)
+}
+
+func TestMetering_QuotaExceededIs429(t *testing.T) {
+	m := &stubMeter{admitErr: fmt.Errorf("daily usage quota exceeded: %w", domain.ErrTooMany)}
+
+	rr := httptest.NewRecorder()
+	Metering(m)(http.HandlerFunc(okHandler)).ServeHTTP(rr, sessionRequest("u1", "s1"))
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+}
+
+func TestMetering_MeterFailureServesRequest(t *testing.T) {
+	m := &stubMeter{admitErr: errors.New("dynamodb down")}
+
+	rr := httptest.NewRecorder()
+	Metering(m)(http.HandlerFunc(okHandler)).ServeHTTP(rr, sessionRequest("u1", "s1"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestSecondsUntilMidnight(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+
+	require.Equal(t, 61, secondsUntilMidnight(now))
+}
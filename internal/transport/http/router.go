@@ -2,25 +2,27 @@ package http
 
 import (
 	"context"
+	_ "embed"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	dynamodbsdk "github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/go-api-nosql/internal/application/auth"
-	"github.com/go-api-nosql/internal/application/device"
-	fileapp "github.com/go-api-nosql/internal/application/file"
-	"github.com/go-api-nosql/internal/application/notification"
 	"github.com/go-api-nosql/internal/application/session"
-	"github.com/go-api-nosql/internal/application/status"
 	"github.com/go-api-nosql/internal/application/user"
 	"github.com/go-api-nosql/internal/config"
-	"github.com/go-api-nosql/internal/domain"
-	googleinfra "github.com/go-api-nosql/internal/infrastructure/google"
+	"github.com/go-api-nosql/internal/infrastructure/dynamo"
 	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
 	"github.com/go-api-nosql/internal/infrastructure/smtp"
 	"github.com/go-api-nosql/internal/infrastructure/sns"
+	"github.com/go-api-nosql/internal/pkg/accesslog"
+	"github.com/go-api-nosql/internal/pkg/chaos"
+	"github.com/go-api-nosql/internal/pkg/dbcost"
+	"github.com/go-api-nosql/internal/transport/http/adminui"
+	"github.com/go-api-nosql/internal/transport/http/consoleui"
 	"github.com/go-api-nosql/internal/transport/http/handler"
 	appmiddleware "github.com/go-api-nosql/internal/transport/http/middleware"
 	"github.com/go-chi/chi/v5"
@@ -36,160 +38,546 @@ type Deps struct {
 	StatusRepo       StatusRepository
 	DeviceRepo       DeviceRepository
 	NotificationRepo NotificationRepository
+	TemplateRepo     NotificationTemplateRepository
+	MessageRepo      MessageRepository
+	ActivityRepo     ActivityRepository
+	RoleRepo         RoleRepository
+	PlanRepo         PlanRepository
+	AuditRepo        AuditRepository
 	FileRepo         FileRepository
 	VerificationRepo VerificationRepository
 	AppVersionRepo   AppVersionRepository
+	RateLimitRepo    RateLimitRepository
+	ApprovalRepo     ApprovalRepository
+	BroadcastRepo    BroadcastRepository
+	SettingsRepo     SettingsRepository
+	LaunchRepo       LaunchRepository
+	PasskeyRepo      PasskeyRepository
+	OAuthClientRepo  OAuthClientRepository
+	APIKeyRepo       APIKeyRepository
+	UsageRepo        UsageRepository // nil unless FEATURE_USAGE_METERING is on
+	SearchIndex      SearchIndex     // nil disables /v1/search
+	UserStream       UserStream
+	FileStream       FileStream
+	BackupStatus     BackupStatusReader
 	DynamoClient     *dynamodbsdk.Client
+	DynamoCosts      *dbcost.Ledger // capacity consumed per route; nil unless DYNAMO_COST_TRACKING is on
 	S3Store          ObjectStore
 	Mailer           smtp.Mailer
 	SMSSender        sns.SMSSender
+	PushSender       sns.PushSender // nil disables mobile push
 	JWTProvider      *jwtinfra.Provider
+	Outbox           *smtp.Outbox      // captured mail for GET /dev/emails; nil unless MAIL_PROVIDER=capture or the dev console is on
+	Chaos            *chaos.Controller // fault-injection rules; nil unless CHAOS_INJECTION is on outside production
+	AccessLog        *accesslog.Logger // request log sink; nil when ACCESS_LOG_SINK=off
+
+	// Optional deployment hooks for login and registration; see DEVELOPMENT.md.
+	PreLoginHooks     []session.PreLoginHook
+	PostLoginHooks    []session.PostLoginHook
+	PreRegisterHooks  []user.PreRegisterHook
+	PostRegisterHooks []user.PostRegisterHook
+
+	Extensions Extensions
 }
 
 // dynamoPinger adapts *dynamodb.Client to the handler.dbPinger interface.
 type dynamoPinger struct{ client *dynamodbsdk.Client }
 
 func (p *dynamoPinger) Ping(ctx context.Context) error {
-	_, err := p.client.ListTables(ctx, &dynamodbsdk.ListTablesInput{Limit: aws.Int32(1)})
-	return err
+	return dynamo.Ping(ctx, p.client)
+}
+
+// defaultRoutePolicy maps admin-only routes to the Admin role. Override it with
+// ROUTE_POLICY_FILE to change authorization rules without a code change.
+//
+//go:embed route_policy.json
+var defaultRoutePolicy []byte
+
+// loadRoutePolicy returns the policy from cfg.RoutePolicyFile, or the embedded default.
+func loadRoutePolicy(cfg *config.Config) (*appmiddleware.RoutePolicy, error) {
+	var (
+		policy *appmiddleware.RoutePolicy
+		err    error
+	)
+	if cfg.RoutePolicyFile != "" {
+		policy, err = appmiddleware.LoadRoutePolicy(cfg.RoutePolicyFile)
+	} else {
+		policy, err = appmiddleware.ParseRoutePolicy(defaultRoutePolicy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("route policy: %w", err)
+	}
+	return policy, nil
+}
+
+// authMiddleware returns the authentication for protected routes: bearer JWTs,
+// plus API keys when apiKeys is set and mapped client certificates when the
+// mTLS listener is enabled.
+func authMiddleware(cfg *config.Config, jwt *jwtinfra.Provider, apiKeys appmiddleware.APIKeyVerifier) (func(http.Handler) http.Handler, error) {
+	authMw := appmiddleware.Auth(jwt)
+	if apiKeys != nil {
+		authMw = appmiddleware.APIKeyAuth(apiKeys, authMw)
+	}
+	if cfg.MTLSPort == "" {
+		return authMw, nil
+	}
+	principals, err := appmiddleware.LoadCertPrincipals(cfg.MTLSPrincipalsFile, cfg.TenantMode != "")
+	if err != nil {
+		return nil, fmt.Errorf("mTLS principals: %w", err)
+	}
+	return appmiddleware.ClientCert(principals, authMw), nil
+}
+
+// errNoRateLimitRepo is returned when RATE_LIMIT_BACKEND=dynamo has no table to use.
+var errNoRateLimitRepo = errors.New("RATE_LIMIT_BACKEND=dynamo requires a rate limit repository")
+
+// rateLimiter is satisfied by both the in-memory and the DynamoDB-backed limiters.
+type rateLimiter interface {
+	Limit(next http.Handler) http.Handler
+	Inspect(ctx context.Context, key string) (*appmiddleware.RateLimitState, error)
+	Reset(ctx context.Context, key string) error
 }
 
-// googleVerifierAdapter adapts *googleinfra.Verifier to session.googleVerifier.
-type googleVerifierAdapter struct{ v *googleinfra.Verifier }
+// rateLimit is a sustained rate in requests per second and the burst allowed
+// above it.
+type rateLimit struct {
+	rate  rate.Limit
+	burst int
+}
 
-func (a *googleVerifierAdapter) Verify(ctx context.Context, token string) (*session.GooglePayload, error) {
-	p, err := a.v.Verify(ctx, token)
+// newRateLimiter picks the limiter implementation from cfg.RateLimitBackend.
+// The shared limiter uses a sliding window of burst/rate seconds holding at most
+// burst requests, which matches the token bucket's sustained rate. Clients in
+// RATE_LIMIT_EXEMPT are not limited; the others are counted per
+// RATE_LIMIT_IPV4_PREFIX / RATE_LIMIT_IPV6_PREFIX range. Client IPs are taken
+// from X-Forwarded-For only behind TRUSTED_PROXIES.
+func newRateLimiter(ctx context.Context, cfg *config.Config, deps *Deps, limit rateLimit) (rateLimiter, error) {
+	exempt, err := appmiddleware.ParseIPAllowlist(cfg.RateLimitExempt)
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_EXEMPT: %w", err)
+	}
+	proxies, err := trustedProxies(cfg)
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := appmiddleware.NewIPBuckets(cfg.RateLimitIPv4Prefix, cfg.RateLimitIPv6Prefix)
 	if err != nil {
 		return nil, err
 	}
-	return &session.GooglePayload{
-		Sub:           p.Sub,
-		Email:         p.Email,
-		EmailVerified: p.EmailVerified,
-		FirstName:     p.FirstName,
-		LastName:      p.LastName,
-	}, nil
+	if cfg.RateLimitBackend == "dynamo" {
+		if deps.RateLimitRepo == nil {
+			return nil, errNoRateLimitRepo
+		}
+		window := time.Duration(float64(limit.burst) / float64(limit.rate) * float64(time.Second))
+		return appmiddleware.NewSlidingWindowLimiter(deps.RateLimitRepo, limit.burst, window).Exempt(exempt).Buckets(buckets).TrustProxies(proxies), nil
+	}
+	return appmiddleware.NewRateLimiter(ctx, limit.rate, limit.burst).Exempt(exempt).Buckets(buckets).TrustProxies(proxies), nil
 }
 
-// NewRouter builds and returns the application router.
-func NewRouter(ctx context.Context, cfg *config.Config, deps *Deps) http.Handler {
+// trustedProxies parses TRUSTED_PROXIES, the proxies whose forwarding headers
+// are believed.
+func trustedProxies(cfg *config.Config) (appmiddleware.IPAllowlist, error) {
+	proxies, err := appmiddleware.ParseIPAllowlist(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+	return proxies, nil
+}
+
+// newReplayGuard returns the nonce check for sensitive routes, or a pass-through
+// when replay protection is off. Nonces are shared across replicas through the
+// rate-limit table when RATE_LIMIT_BACKEND=dynamo.
+func newReplayGuard(ctx context.Context, cfg *config.Config, deps *Deps) (func(http.Handler) http.Handler, error) {
+	if !cfg.ReplayProtection {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+	if cfg.RateLimitBackend == "dynamo" {
+		if deps.RateLimitRepo == nil {
+			return nil, errNoRateLimitRepo
+		}
+		return appmiddleware.NewReplayGuard(ctx, deps.RateLimitRepo, cfg.ReplayWindow).Check, nil
+	}
+	return appmiddleware.NewReplayGuard(ctx, nil, cfg.ReplayWindow).Check, nil
+}
+
+// concurrencyLimit caps the requests served at once by the routes it wraps,
+// except those matching exempt (which may be nil), or passes through when
+// limit is not positive.
+func concurrencyLimit(limit int, wait time.Duration, exempt func(*http.Request) bool) func(http.Handler) http.Handler {
+	if limit <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return appmiddleware.NewConcurrencyLimiter(limit, wait).Except(exempt).Limit
+}
+
+// isHealthCheck matches the /v1/health-check routes, which the API-wide
+// concurrency limit never queues so probes keep answering while busy.
+func isHealthCheck(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/v1/health-check/")
+}
+
+// newTenantMiddleware returns the tenant resolution for TENANT_MODE, or a
+// pass-through when the API is single-tenant.
+func newTenantMiddleware(cfg *config.Config, jwt *jwtinfra.Provider) func(http.Handler) http.Handler {
+	if cfg.TenantMode == "" {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return appmiddleware.Tenant(cfg.TenantMode, cfg.TenantBaseDomain, jwt)
+}
+
+// bearerChain is the checks the authenticated routes apply to a bearer
+// token, for public routes that also serve signed-in callers.
+func bearerChain(cfg *config.Config, authMw func(http.Handler) http.Handler, guard *appmiddleware.SessionGuard, policy *appmiddleware.RoutePolicy) []func(http.Handler) http.Handler {
+	chain := []func(http.Handler) http.Handler{authMw}
+	if cfg.TenantMode != "" {
+		chain = append(chain, appmiddleware.TenantMatch)
+	}
+	return append(chain, guard.Check, policy.Enforce)
+}
+
+// NewRouter builds and returns the application router for services built on
+// deps (see internal/app). It fails when deps.JWTProvider is missing or a
+// configured policy, principals file, display name format, Gravatar mode or
+// rate limit backend is unusable.
+func NewRouter(ctx context.Context, cfg *config.Config, deps *Deps, svc *Services) (http.Handler, error) {
+	if deps.JWTProvider == nil {
+		return nil, errors.New("router: a JWT provider is required")
+	}
+	authMw, err := authMiddleware(cfg, deps.JWTProvider, svc.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := loadRoutePolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := handler.SetNameFormat(cfg.DisplayNameFormat); err != nil {
+		return nil, err
+	}
+	if err := handler.SetGravatar(cfg.Gravatar); err != nil {
+		return nil, err
+	}
+	// 5 requests/second, burst of 10 — applied to sensitive public endpoints.
+	sensitiveRL, err := newRateLimiter(ctx, cfg, deps, rateLimit{rate: 5, burst: 10})
+	if err != nil {
+		return nil, err
+	}
+	replayGuard, err := newReplayGuard(ctx, cfg, deps)
+	if err != nil {
+		return nil, err
+	}
+	proxies, err := trustedProxies(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	r := chi.NewRouter()
-	r.Use(appmiddleware.RequestLogger)
-	r.Use(chimiddleware.Recoverer)
 	r.Use(chimiddleware.RequestID)
+	if deps.AccessLog != nil {
+		r.Use(appmiddleware.RequestLogger(deps.AccessLog))
+	}
+	r.Use(chimiddleware.Recoverer)
+	r.Use(concurrencyLimit(cfg.MaxConcurrentRequests, cfg.ConcurrencyQueueTimeout, isHealthCheck))
+	r.Use(appmiddleware.ClientInfo(cfg.CountryHeader, proxies))
+	if cfg.ErrorDetailsEnabled() {
+		r.Use(appmiddleware.ErrorDetails)
+	}
+	if deps.DynamoCosts != nil {
+		r.Use(appmiddleware.DynamoCost(deps.DynamoCosts, cfg.AppEnv == "development"))
+	}
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   cfg.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", appmiddleware.NonceHeader, appmiddleware.TimestampHeader, appmiddleware.TenantHeader},
 		AllowCredentials: false, // Bearer token auth; cookies not used
 		MaxAge:           300,
 	}))
-
-	if deps.JWTProvider == nil {
-		log.Fatal("JWT provider is required but was not initialized; check RSA key files")
+	ext := deps.Extensions
+	r.Use(ext.Middleware...)
+	if deps.Chaos != nil {
+		r.Use(appmiddleware.Chaos(deps.Chaos))
 	}
-	if cfg.GoogleClientID == "" {
-		log.Fatal("GOOGLE_CLIENT_ID is required but not set; add it to your environment")
+	if svc.ReadOnly != nil {
+		r.Use(appmiddleware.ReadOnly(svc.ReadOnly))
 	}
-	authMw := appmiddleware.Auth(deps.JWTProvider)
 
-	// 5 requests/second, burst of 10 — applied to sensitive public endpoints.
-	sensitiveRL := appmiddleware.NewRateLimiter(ctx, rate.Limit(5), 10)
-
-	refreshDur := time.Duration(cfg.RefreshTokenExpiryDays) * 24 * time.Hour
-	sessionSvc := session.NewService(session.ServiceDeps{
-		SessionRepo:     deps.SessionRepo,
-		UserRepo:        deps.UserRepo,
-		DeviceRepo:      deps.DeviceRepo,
-		JWTProvider:     deps.JWTProvider,
-		GoogleVerifier:  &googleVerifierAdapter{v: googleinfra.NewVerifier(cfg.GoogleClientID)},
-		RefreshTokenDur: refreshDur,
-	})
-	userSvc := user.NewService(user.ServiceDeps{
-		UserRepo:        deps.UserRepo,
-		SessionRepo:     deps.SessionRepo,
-		DeviceRepo:      deps.DeviceRepo,
-		JWTProvider:     deps.JWTProvider,
-		RefreshTokenDur: refreshDur,
-	})
-	statusSvc := status.NewService(deps.StatusRepo)
-	deviceSvc := device.NewService(deps.DeviceRepo, deps.AppVersionRepo)
-	notifSvc := notification.NewService(deps.NotificationRepo)
-	fileSvc := fileapp.NewService(deps.S3Store, deps.FileRepo)
-	authSvc := auth.NewService(auth.ServiceDeps{
-		VerificationRepo: deps.VerificationRepo,
-		UserRepo:         deps.UserRepo,
-		SessionRepo:      deps.SessionRepo,
-		DeviceRepo:       deps.DeviceRepo,
-		Mailer:           deps.Mailer,
-		SMSSender:        deps.SMSSender,
-		JWTProvider:      deps.JWTProvider,
-		RefreshTokenDur:  refreshDur,
-	})
+	features := cfg.Features
+	sessionGuard := appmiddleware.NewSessionGuard(ctx, svc.Session, cfg.SessionCheckTTL)
+	tenantMw := newTenantMiddleware(cfg, deps.JWTProvider)
 
-	healthH := handler.NewHealthHandler(&dynamoPinger{deps.DynamoClient})
-	sessionH := handler.NewSessionHandler(sessionSvc)
-	userH := handler.NewUserHandler(userSvc)
-	statusH := handler.NewStatusHandler(statusSvc)
-	deviceH := handler.NewDeviceHandler(deviceSvc)
-	notifH := handler.NewNotificationHandler(notifSvc)
-	fileH := handler.NewFileHandler(fileSvc)
-	pwH := handler.NewPasswordRecoveryHandler(authSvc)
-	emailH := handler.NewEmailConfirmHandler(authSvc)
-	phoneH := handler.NewPhoneConfirmHandler(authSvc)
+	healthH := handler.NewHealthHandler(&dynamoPinger{deps.DynamoClient}, svc.Backup)
+	sessionH := handler.NewSessionHandler(svc.Session)
+	userH := handler.NewUserHandler(svc.User, svc.Approval)
+	statusH := handler.NewStatusHandler(svc.Status)
+	appVersionH := handler.NewAppVersionHandler(svc.AppVersion)
+	roleH := handler.NewRoleHandler(svc.Role)
+	planH := handler.NewPlanHandler(svc.Plan)
+	deviceH := handler.NewDeviceHandler(svc.Device)
+	notifH := handler.NewNotificationHandler(svc.Notification)
+	templateH := handler.NewNotificationTemplateHandler(svc.Template)
+	messageH := handler.NewMessageHandler(svc.Message)
+	fileH := handler.NewFileHandler(svc.File)
+	activityH := handler.NewActivityHandler(svc.Activity)
+	pwH := handler.NewPasswordRecoveryHandler(svc.Auth)
+	emailH := handler.NewEmailConfirmHandler(svc.Auth)
+	phoneH := handler.NewPhoneConfirmHandler(svc.Auth)
+	deviceVerifyH := handler.NewDeviceVerificationHandler(svc.Auth)
+	rateLimitH := handler.NewRateLimitHandler(sensitiveRL)
+	exportH := handler.NewExportHandler(svc.User, svc.Audit)
+	overviewH := handler.NewOverviewHandler(svc.Overview)
+	adminSessionH := handler.NewAdminSessionHandler(svc.Session, sessionGuard)
+	retentionH := handler.NewRetentionHandler(svc.Retention)
+	uploadLimit := concurrencyLimit(cfg.MaxConcurrentUploads, cfg.ConcurrencyQueueTimeout, nil)
+	exportLimit := concurrencyLimit(cfg.MaxConcurrentExports, cfg.ConcurrencyQueueTimeout, nil)
+
+	if features.AdminUI {
+		r.Get("/admin", http.RedirectHandler("/admin/", http.StatusMovedPermanently).ServeHTTP)
+		r.Handle("/admin/*", http.StripPrefix("/admin", adminui.Handler()))
+	}
+
+	if svc.DevConsole != nil {
+		log.Printf("WARN: dev console enabled at /dev/console; it mints tokens without authentication")
+		devH := handler.NewDevConsoleHandler(svc.DevConsole)
+		r.Get("/dev/console", http.RedirectHandler("/dev/console/", http.StatusMovedPermanently).ServeHTTP)
+		r.Handle("/dev/console/*", http.StripPrefix("/dev/console", consoleui.Handler()))
+		r.With(tenantMw).Post("/dev/api/users", devH.SeedUser)
+		r.With(tenantMw).Post("/dev/api/tokens", devH.MintToken)
+	}
+	if deps.Outbox != nil && cfg.AppEnv != "production" {
+		r.Get("/dev/emails", handler.NewDevEmailsHandler(deps.Outbox).List)
+	}
 
 	r.Route("/v1", func(r chi.Router) {
 		// ── Public routes (no auth) ──────────────────────────────────────────
+		// Health checks serve probes that know no tenant; every other route
+		// acts for one tenant when TENANT_MODE is set.
 		r.Get("/health-check/{action}", healthH.Ping)
 		r.Post("/health-check/{action}", healthH.Ping)
-		r.Get("/roles", handler.ListRoles)
-		r.With(sensitiveRL.Limit).Post("/sessions/login", sessionH.Login)
-		r.With(sensitiveRL.Limit).Post("/sessions/google", sessionH.GoogleLogin)
-		r.Post("/sessions/refresh", sessionH.Refresh)
-		r.With(sensitiveRL.Limit).Post("/users", userH.Register)
-		r.With(sensitiveRL.Limit).Post("/password-recovery/{action}", pwH.Action)
-
-		// ── Authenticated routes ─────────────────────────────────────────────
+		// Stripe knows no tenant; subscriptions name theirs in metadata.
+		if svc.Billing != nil {
+			r.Post("/webhooks/stripe", handler.NewBillingHandler(svc.Billing).StripeWebhook)
+		}
 		r.Group(func(r chi.Router) {
-			r.Use(authMw)
-
-			r.Get("/sessions", sessionH.GetCurrent)
-			r.Post("/sessions/logout", sessionH.Logout)
-
-			// Any authenticated user
-			r.Get("/users/{id}", userH.Get)
-			r.Put("/users/{id}", userH.Update)
-			r.Post("/users/me/password", userH.ChangePassword)
-			r.Get("/statuses", statusH.List)
-			r.Get("/statuses/{id}", statusH.Get)
-			r.Get("/devices", deviceH.List)
-			r.Put("/devices/version", deviceH.CheckVersion)
-			r.Get("/devices/{id}", deviceH.Get)
-			r.Put("/devices/{id}", deviceH.Update)
-			r.Delete("/devices/{id}", deviceH.Delete)
-			r.Get("/notifications", notifH.ListUnread)
-			r.Put("/notifications/{id}", notifH.MarkAsRead)
-			r.Post("/files/s3", fileH.Upload)
-			r.Post("/files/s3/base64", fileH.UploadBase64)
-			r.Get("/files/s3/base64/{id}", fileH.GetBase64)
-			r.Get("/files/s3/{id}", fileH.Download)
-			r.Delete("/files/s3/{id}", fileH.Delete)
-			r.With(sensitiveRL.Limit).Post("/confirm-email/{action}", emailH.Action)
-			r.With(sensitiveRL.Limit).Post("/confirm-phone/{action}", phoneH.Action)
-
-			// Admin-only routes
+			r.Use(tenantMw)
+
+			r.With(sensitiveRL.Limit).Post("/sessions/login", sessionH.Login)
+			if features.GoogleAuth {
+				r.With(sensitiveRL.Limit).Post("/sessions/google", sessionH.GoogleLogin)
+			}
+			r.Post("/sessions/refresh", sessionH.Refresh)
+			r.With(sensitiveRL.Limit).Post("/sessions/silent-refresh", sessionH.SilentRefresh)
+			r.With(sensitiveRL.Limit).Post("/sessions/approve", sessionH.ApproveLogin)
+			if svc.Passkey != nil {
+				r.With(sensitiveRL.Limit).Post("/sessions/webauthn/challenge", handler.NewPasskeyHandler(svc.Passkey).BeginLogin)
+				r.With(sensitiveRL.Limit).Post("/sessions/webauthn", sessionH.PasskeyLogin)
+			}
+			if svc.OAuth != nil {
+				oauthH := handler.NewOAuthHandler(svc.OAuth)
+				r.With(sensitiveRL.Limit).Post("/oauth/token", oauthH.Token)
+				// Admins call these with their bearer token instead of client
+				// credentials; the route policy limits that to the Admin role.
+				asAdmin := appmiddleware.WhenBearer(bearerChain(cfg, authMw, sessionGuard, policy)...)
+				r.With(sensitiveRL.Limit, asAdmin).Post("/oauth/introspect", oauthH.Introspect)
+				r.With(sensitiveRL.Limit, asAdmin).Post("/oauth/revoke", oauthH.Revoke)
+			}
+			if features.SelfRegistration {
+				r.With(sensitiveRL.Limit).Post("/users", userH.Register)
+			}
+			r.With(sensitiveRL.Limit).Get("/public/users/{username}", userH.GetPublic)
+			r.With(sensitiveRL.Limit).Post("/password-recovery/{action}", pwH.Action)
+			if ext.Routes != nil {
+				ext.Routes(r)
+			}
+
+			// ── Authenticated routes ─────────────────────────────────────────
+			// Role requirements come from the route policy (see route_policy.json).
 			r.Group(func(r chi.Router) {
-				r.Use(appmiddleware.RequireRole(domain.RoleAdmin))
+				r.Use(authMw)
+				if cfg.TenantMode != "" {
+					r.Use(appmiddleware.TenantMatch)
+				}
+				r.Use(sessionGuard.Check)
+				r.Use(policy.Enforce)
+				if cfg.ProfileRequired {
+					r.Use(appmiddleware.NewProfileGate(ctx, svc.User, cfg.SessionCheckTTL).Check)
+				}
+				if svc.Usage != nil {
+					r.Use(appmiddleware.Metering(svc.Usage))
+				}
+				r.Use(ext.AuthMiddleware...)
+
+				r.Get("/sessions", sessionH.GetCurrent)
+				r.Get("/sessions/active", sessionH.ListActive)
+				r.Post("/sessions/logout", sessionH.Logout)
+
+				// Any authenticated user
+				r.Get("/users/{id}", userH.Get)
+				r.Put("/users/{id}", userH.Update)
+				r.With(replayGuard).Post("/users/me/password", userH.ChangePassword)
+				r.Get("/users/me/activity", activityH.ListMine)
+				if svc.Usage != nil {
+					r.Get("/users/me/usage", handler.NewUsageHandler(svc.Usage).Me)
+				}
+				if svc.Billing != nil {
+					r.Post("/users/me/billing-portal", handler.NewBillingHandler(svc.Billing).Portal)
+				}
+				if svc.Passkey != nil {
+					passkeyH := handler.NewPasskeyHandler(svc.Passkey)
+					r.Post("/users/me/passkeys/challenge", passkeyH.BeginRegistration)
+					r.Post("/users/me/passkeys", passkeyH.Register)
+					r.Get("/users/me/passkeys", passkeyH.List)
+					r.Delete("/users/me/passkeys/{id}", passkeyH.Delete)
+				}
+				if svc.OAuth != nil {
+					oauthH := handler.NewOAuthHandler(svc.OAuth)
+					r.Get("/oauth/authorize", oauthH.Consent)
+					r.Post("/oauth/authorize", oauthH.Authorize)
+				}
+				r.Get("/statuses", statusH.List)
+				r.Get("/statuses/{id}", statusH.Get)
+				r.Get("/devices", deviceH.List)
+				r.Put("/devices/version", deviceH.CheckVersion)
+				r.Get("/devices/{id}", deviceH.Get)
+				r.Put("/devices/{id}", deviceH.Update)
+				r.Post("/devices/{id}/token", deviceH.RotateToken)
+				r.Delete("/devices/{id}", deviceH.Delete)
+				if features.Notifications {
+					r.Get("/notifications", notifH.ListUnread)
+					r.Delete("/notifications", notifH.DismissAll)
+					r.Put("/notifications/{id}", notifH.MarkAsRead)
+					r.Delete("/notifications/{id}", notifH.Dismiss)
+					r.Post("/notifications/{id}/receipts", notifH.RecordReceipt)
+				}
+				r.Post("/messages", messageH.Send)
+				r.Get("/messages/unread", messageH.Unread)
+				r.Get("/messages/{userID}", messageH.List)
+				r.Put("/messages/{userID}/read", messageH.MarkRead)
+				if features.Files {
+					r.With(uploadLimit).Post("/files/s3", fileH.Upload)
+					r.With(uploadLimit).Post("/files/s3/base64", fileH.UploadBase64)
+					r.Get("/files/s3/base64/{id}", fileH.GetBase64)
+					r.Get("/files/s3/{id}", fileH.Download)
+					r.Delete("/files/s3/{id}", fileH.Delete)
+				}
+				r.With(sensitiveRL.Limit).Post("/confirm-email/{action}", emailH.Action)
+				r.With(sensitiveRL.Limit).Post("/device-verification/{action}", deviceVerifyH.Action)
+				if features.PhoneConfirmation {
+					r.With(sensitiveRL.Limit).Post("/confirm-phone/{action}", phoneH.Action)
+				}
 
+				// Admin-only by default policy
 				r.Get("/users", userH.List)
-				r.Delete("/users/{id}", userH.Delete)
+				r.With(replayGuard).Delete("/users/{id}", userH.Delete)
+				r.With(replayGuard).Put("/admin/users/{id}/role", userH.ChangeRole)
+				r.Get("/admin/users/{id}/overview", overviewH.Get)
+				r.Get("/admin/users/{id}/sessions", adminSessionH.List)
+				r.Delete("/admin/users/{id}/sessions", adminSessionH.Terminate)
+				r.Delete("/admin/users/{id}/sessions/{session_id}", adminSessionH.Terminate)
+				r.Post("/admin/users", userH.Provision)
+				r.Post("/admin/users/bulk", userH.Bulk)
+				r.With(exportLimit).Get("/admin/export/users.csv", exportH.Users)
+				r.With(exportLimit).Get("/admin/export/audit.csv", exportH.Audit)
 
 				r.Post("/statuses", statusH.Create)
 				r.Put("/statuses/{id}", statusH.Update)
 				r.Delete("/statuses/{id}", statusH.Delete)
+				r.Get("/roles", roleH.List)
+				r.Post("/roles", roleH.Create)
+				r.Get("/roles/{name}", roleH.Get)
+				r.Put("/roles/{name}", roleH.Update)
+				r.With(replayGuard).Delete("/roles/{name}", roleH.Delete)
+				r.Get("/admin/plans", planH.List)
+				r.Post("/admin/plans", planH.Create)
+				r.Get("/admin/plans/{name}", planH.Get)
+				r.Put("/admin/plans/{name}", planH.Update)
+				r.With(replayGuard).Delete("/admin/plans/{name}", planH.Delete)
+				r.Put("/admin/users/{id}/plan", planH.Assign)
+
+				if svc.Search != nil {
+					r.Get("/search", handler.NewSearchHandler(svc.Search).Search)
+				}
+
+				r.Get("/admin/app-versions", appVersionH.List)
+				r.Post("/admin/app-versions", appVersionH.Create)
+				r.Put("/admin/app-versions/{id}", appVersionH.Update)
+
+				if deps.Chaos != nil {
+					chaosH := handler.NewChaosHandler(deps.Chaos)
+					r.Get("/admin/chaos", chaosH.Get)
+					r.Put("/admin/chaos", chaosH.Put)
+					r.Delete("/admin/chaos", chaosH.Clear)
+				}
+
+				if svc.Approval != nil {
+					approvalH := handler.NewApprovalHandler(svc.Approval)
+					r.Get("/admin/approvals", approvalH.List)
+					r.Post("/admin/approvals/{id}/approve", approvalH.Approve)
+					r.Post("/admin/approvals/{id}/reject", approvalH.Reject)
+				}
+
+				if svc.ReadOnly != nil {
+					readOnlyH := handler.NewReadOnlyHandler(svc.ReadOnly)
+					r.Get("/admin/read-only", readOnlyH.Get)
+					r.Put("/admin/read-only", readOnlyH.Put)
+				}
+				if svc.Launch != nil {
+					launchH := handler.NewLaunchHandler(svc.Launch)
+					r.Get("/admin/launch/allowlist", launchH.List)
+					r.Post("/admin/launch/allowlist", launchH.Create)
+					r.Delete("/admin/launch/allowlist/{kind}/{value}", launchH.Delete)
+					r.Get("/admin/launch/stats", launchH.Stats)
+				}
+
+				r.Get("/admin/retention/report", retentionH.Report)
+				if svc.Backup != nil {
+					r.Get("/admin/backups", handler.NewBackupHandler(svc.Backup).Status)
+				}
+				if deps.DynamoCosts != nil {
+					costH := handler.NewDynamoCostHandler(deps.DynamoCosts)
+					r.Get("/admin/dynamo-costs", costH.List)
+					r.Delete("/admin/dynamo-costs", costH.Reset)
+				}
+				r.Get("/admin/rate-limits", rateLimitH.Inspect)
+				r.Delete("/admin/rate-limits/{key}", rateLimitH.Reset)
+				if features.Notifications {
+					r.Get("/admin/notifications/{id}/stats", notifH.Stats)
+					r.Post("/admin/notifications", notifH.Create)
+					r.Put("/admin/notifications/{id}", notifH.Update)
+					r.Delete("/admin/notifications/{id}", notifH.Cancel)
+
+					r.Get("/admin/notification-templates", templateH.List)
+					r.Post("/admin/notification-templates", templateH.Create)
+					r.Get("/admin/notification-templates/{name}", templateH.Get)
+					r.Put("/admin/notification-templates/{name}", templateH.Update)
+					r.Delete("/admin/notification-templates/{name}", templateH.Delete)
+				}
+				if svc.Broadcast != nil {
+					broadcastH := handler.NewBroadcastHandler(svc.Broadcast)
+					r.Get("/admin/broadcasts", broadcastH.List)
+					r.Post("/admin/broadcasts", broadcastH.Create)
+					r.Post("/admin/broadcasts/preview", broadcastH.Preview)
+					r.Get("/admin/broadcasts/{id}", broadcastH.Get)
+					r.Post("/admin/broadcasts/{id}/cancel", broadcastH.Cancel)
+				}
+				if svc.OAuth != nil {
+					oauthH := handler.NewOAuthHandler(svc.OAuth)
+					r.Get("/admin/oauth/clients", oauthH.ListClients)
+					r.Post("/admin/oauth/clients", oauthH.CreateClient)
+					r.Delete("/admin/oauth/clients/{id}", oauthH.DeleteClient)
+				}
+				if svc.APIKey != nil {
+					apiKeyH := handler.NewAPIKeyHandler(svc.APIKey)
+					r.Get("/admin/api-keys", apiKeyH.List)
+					r.Post("/admin/api-keys", apiKeyH.Create)
+					r.Delete("/admin/api-keys/{id}", apiKeyH.Revoke)
+				}
+
+				if ext.AuthRoutes != nil {
+					ext.AuthRoutes(r)
+				}
 			})
 		})
 	})
 
-	return r
+	return r, nil
 }
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-api-nosql/internal/application/apikey"
+	"github.com/go-api-nosql/internal/application/approval"
+	"github.com/go-api-nosql/internal/application/backup"
+	"github.com/go-api-nosql/internal/application/broadcast"
+	"github.com/go-api-nosql/internal/application/launch"
+	"github.com/go-api-nosql/internal/application/oauth"
+	"github.com/go-api-nosql/internal/application/readonly"
+	"github.com/go-api-nosql/internal/config"
+	"github.com/go-api-nosql/internal/domain"
+	jwtinfra "github.com/go-api-nosql/internal/infrastructure/jwt"
+	"github.com/go-api-nosql/internal/pkg/chaos"
+	"github.com/go-api-nosql/internal/pkg/dbcost"
+	appmiddleware "github.com/go-api-nosql/internal/transport/http/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRouter_DegradedStart(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	cases := []struct {
+		name string
+		cfg  config.Config
+		deps Deps
+		want string
+	}{
+		{"no JWT provider", config.Config{}, Deps{}, "JWT provider"},
+		{"unreadable route policy", config.Config{RoutePolicyFile: missing}, Deps{JWTProvider: &jwtinfra.Provider{}}, "route policy"},
+		{"unreadable mTLS principals", config.Config{MTLSPort: "8443", MTLSPrincipalsFile: missing}, Deps{JWTProvider: &jwtinfra.Provider{}}, "mTLS principals"},
+		{"shared rate limits without a table", config.Config{RateLimitBackend: "dynamo"}, Deps{JWTProvider: &jwtinfra.Provider{}}, "RATE_LIMIT_BACKEND"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewRouter(context.Background(), &tc.cfg, &tc.deps, &Services{})
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+			assert.Nil(t, h)
+		})
+	}
+}
+
+// TestDefaultRoutePolicy_CoversAdminRoutes walks the router and requires an
+// explicit Admin rule for every admin route, so the implicit fallback in
+// RoutePolicy never has to catch one of ours.
+func TestDefaultRoutePolicy_CoversAdminRoutes(t *testing.T) {
+	cfg := config.Config{Features: config.Features{Notifications: true, OAuthServer: true, APIKeys: true}}
+	deps := Deps{JWTProvider: &jwtinfra.Provider{}, Chaos: chaos.NewController(), DynamoCosts: dbcost.NewLedger()}
+	// Services are never called; embedding the interfaces only mounts the
+	// optional admin routes.
+	svc := Services{
+		Broadcast: struct{ broadcast.Service }{},
+		Approval:  struct{ approval.Service }{},
+		ReadOnly:  struct{ readonly.Service }{},
+		Launch:    struct{ launch.Service }{},
+		Backup:    struct{ backup.Service }{},
+		OAuth:     struct{ oauth.Service }{},
+		APIKey:    struct{ apikey.Service }{},
+	}
+	h, err := NewRouter(context.Background(), &cfg, &deps, &svc)
+	require.NoError(t, err)
+	policy, err := loadRoutePolicy(&cfg)
+	require.NoError(t, err)
+
+	var admin int
+	err = chi.Walk(h.(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, "/v1/admin/") {
+			return nil
+		}
+		admin++
+		covered := slices.ContainsFunc(policy.Rules, func(rule appmiddleware.PolicyRule) bool {
+			return rule.Pattern == route && (rule.Method == "*" || rule.Method == method) && slices.Contains(rule.Roles, domain.RoleAdmin)
+		})
+		assert.True(t, covered, "%s %s has no Admin rule in route_policy.json", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, admin)
+}
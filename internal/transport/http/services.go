@@ -0,0 +1,65 @@
+package http
+
+import (
+	"github.com/go-api-nosql/internal/application/activity"
+	"github.com/go-api-nosql/internal/application/apikey"
+	"github.com/go-api-nosql/internal/application/approval"
+	"github.com/go-api-nosql/internal/application/appversion"
+	"github.com/go-api-nosql/internal/application/audit"
+	"github.com/go-api-nosql/internal/application/auth"
+	"github.com/go-api-nosql/internal/application/backup"
+	"github.com/go-api-nosql/internal/application/billing"
+	"github.com/go-api-nosql/internal/application/broadcast"
+	"github.com/go-api-nosql/internal/application/devconsole"
+	"github.com/go-api-nosql/internal/application/device"
+	fileapp "github.com/go-api-nosql/internal/application/file"
+	"github.com/go-api-nosql/internal/application/launch"
+	"github.com/go-api-nosql/internal/application/message"
+	"github.com/go-api-nosql/internal/application/notification"
+	"github.com/go-api-nosql/internal/application/oauth"
+	"github.com/go-api-nosql/internal/application/overview"
+	"github.com/go-api-nosql/internal/application/passkey"
+	"github.com/go-api-nosql/internal/application/plan"
+	"github.com/go-api-nosql/internal/application/readonly"
+	"github.com/go-api-nosql/internal/application/retention"
+	"github.com/go-api-nosql/internal/application/role"
+	"github.com/go-api-nosql/internal/application/search"
+	"github.com/go-api-nosql/internal/application/session"
+	"github.com/go-api-nosql/internal/application/status"
+	"github.com/go-api-nosql/internal/application/template"
+	"github.com/go-api-nosql/internal/application/usage"
+	"github.com/go-api-nosql/internal/application/user"
+)
+
+// Services holds the application services the router exposes. They are built
+// by the composition root in internal/app.
+type Services struct {
+	Activity     activity.Service
+	Audit        audit.Service
+	Session      session.Service
+	User         user.Service
+	Status       status.Service
+	Role         role.Service
+	Plan         plan.Service
+	Device       device.Service
+	Template     template.Service
+	Notification notification.Service // nil when notifications are disabled
+	Broadcast    broadcast.Service    // nil when notifications are disabled
+	Message      message.Service
+	File         fileapp.Service // nil when file uploads are disabled
+	Overview     overview.Service
+	Auth         auth.Service
+	Search       search.Service // nil without a search backend
+	AppVersion   appversion.Service
+	DevConsole   devconsole.Service // nil unless the dev console is enabled
+	Approval     approval.Service   // nil unless APPROVALS_REQUIRED is on
+	Retention    retention.Service
+	ReadOnly     readonly.Service
+	Launch       launch.Service
+	Backup       backup.Service  // nil without a backup status reader
+	Usage        usage.Service   // nil unless FEATURE_USAGE_METERING is on
+	Billing      billing.Service // nil unless FEATURE_STRIPE_BILLING is on
+	Passkey      passkey.Service // nil unless FEATURE_PASSKEYS is on
+	OAuth        oauth.Service   // nil unless FEATURE_OAUTH_SERVER is on
+	APIKey       apikey.Service  // nil unless FEATURE_API_KEYS is on
+}
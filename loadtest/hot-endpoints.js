@@ -0,0 +1,97 @@
+// k6 scenarios for the hot endpoints: login, refresh, user get and file
+// download. Seed first (make loadtest does both):
+//
+//   go run ./cmd/loadseed -out loadtest/seed.json
+//   k6 run -e BASE_URL=http://localhost:3000 loadtest/hot-endpoints.js
+//
+// The thresholds are the latency baselines documented in DEVELOPMENT.md; a
+// run that breaks one exits non-zero.
+import http from 'k6/http';
+import { check, sleep } from 'k6';
+
+const BASE = (__ENV.BASE_URL || 'http://localhost:3000') + '/v1';
+const DURATION = __ENV.DURATION || '1m';
+const seed = JSON.parse(open(__ENV.SEED || './seed.json'));
+
+// Login shares the per-IP sensitive limiter (5/s, burst 10), so a single
+// load generator cannot push it harder without measuring 429s instead.
+const LOGIN_RATE = 4;
+// Every refresh VU rotates its own session; keep the VU total under the
+// number of sessions opened in setup().
+const SESSIONS = Math.min(seed.users.length, 20);
+
+function rate(exec, perSecond, maxVUs) {
+  return {
+    executor: 'constant-arrival-rate',
+    exec,
+    rate: perSecond,
+    timeUnit: '1s',
+    duration: DURATION,
+    preAllocatedVUs: Math.ceil(maxVUs / 2),
+    maxVUs,
+  };
+}
+
+export const options = {
+  scenarios: {
+    login: rate('login', LOGIN_RATE, 4),
+    refresh: rate('refresh', 20, 8),
+    user_get: rate('userGet', 100, 4),
+    ...(seed.file_id ? { file_download: rate('fileDownload', 30, 4) } : {}),
+  },
+  thresholds: {
+    'http_req_duration{scenario:login}': ['p(95)<300'],
+    'http_req_duration{scenario:refresh}': ['p(95)<100'],
+    'http_req_duration{scenario:user_get}': ['p(95)<50'],
+    'http_req_duration{scenario:file_download}': ['p(95)<150'],
+    http_req_failed: ['rate<0.01'],
+  },
+};
+
+function signIn(user) {
+  const res = http.post(`${BASE}/sessions/login`,
+    JSON.stringify({ username: user.username, password: seed.password }),
+    { headers: { 'Content-Type': 'application/json' } });
+  check(res, { 'login 200': (r) => r.status === 200 });
+  return res.status === 200 ? res.json() : null;
+}
+
+export function setup() {
+  const sessions = [];
+  for (let i = 0; i < SESSIONS; i++) {
+    const body = signIn(seed.users[i]);
+    if (!body) throw new Error(`setup login failed for ${seed.users[i].username}`);
+    sessions.push({ id: seed.users[i].id, bearer: body.access_token, refresh: body.refresh_token });
+    sleep(0.25); // stay under the login limiter
+  }
+  return { sessions };
+}
+
+const auth = (s) => ({ headers: { Authorization: `Bearer ${s.bearer}` } });
+const pick = (data) => data.sessions[Math.floor(Math.random() * data.sessions.length)];
+
+export function login() {
+  signIn(seed.users[Math.floor(Math.random() * seed.users.length)]);
+}
+
+// own is this VU's session. VU ids are unique across scenarios, so with at
+// most SESSIONS VUs in total no two VUs rotate the same refresh token.
+let own;
+
+export function refresh(data) {
+  if (!own) own = data.sessions[(__VU - 1) % data.sessions.length];
+  const res = http.post(`${BASE}/sessions/refresh`, JSON.stringify({ refresh_token: own.refresh }),
+    { headers: { 'Content-Type': 'application/json' } });
+  if (check(res, { 'refresh 200': (r) => r.status === 200 })) own.refresh = res.json().refresh_token;
+}
+
+export function userGet(data) {
+  const s = pick(data);
+  const res = http.get(`${BASE}/users/${s.id}`, auth(s));
+  check(res, { 'user get 200': (r) => r.status === 200 });
+}
+
+export function fileDownload(data) {
+  const res = http.get(`${BASE}/files/s3/${seed.file_id}`, auth(pick(data)));
+  check(res, { 'download 200': (r) => r.status === 200 });
+}